@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"sysmetrics-mcp/internal/archive"
+	"sysmetrics-mcp/internal/availability"
+	"sysmetrics-mcp/internal/bench"
+	"sysmetrics-mcp/internal/config"
+	"sysmetrics-mcp/internal/history"
+)
+
+// Archive subcommands
+const (
+	commandExport = "export-history"
+	commandImport = "import-history"
+)
+
+// archiveFlags registers the flags of export-history or import-history and returns the
+// archive path they set
+func archiveFlags(command string) *string {
+	path := new(string)
+	if command == commandExport {
+		flag.StringVar(path, "output", "", "Archive file to create (default: sysmetrics-archive-<timestamp>.jsonl.gz in the current directory)")
+	} else {
+		flag.StringVar(path, "input", "", "Archive file to import")
+	}
+	return path
+}
+
+// runArchiveCommand exports or imports a portable archive and returns the exit code. It
+// opens the --history-db store directly, so the server may keep running.
+func runArchiveCommand(command string, cfg *config.Config, path string) int {
+	ctx := context.Background()
+	if cfg.HistoryDB == "" {
+		fmt.Fprintf(os.Stderr, "Error: %s requires --history-db\n", command)
+		return 1
+	}
+	if command == commandImport && path == "" {
+		fmt.Fprintf(os.Stderr, "Error: %s requires --input\n", command)
+		return 1
+	}
+	store, err := history.Open(cfg.HistoryDB, cfg.HistoryRetention)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to open history database: %v\n", err)
+		return 1
+	}
+	defer store.Close()
+
+	stores := archive.Stores{History: store}
+	if p := bench.DefaultStorePath(); p != "" {
+		stores.Baselines = bench.NewStore(p)
+	}
+	if p := availability.DefaultPath(); p != "" {
+		stores.Ledger = availability.NewLedger(p)
+	}
+
+	if command == commandExport {
+		if path == "" {
+			path = "sysmetrics-archive-" + time.Now().UTC().Format("20060102-150405") + ".jsonl.gz"
+		}
+		hostname, _ := os.Hostname()
+		counts, err := archive.WriteFile(ctx, path, stores, archive.Manifest{Hostname: hostname, ServerVersion: config.ServerVersion})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Exported %d history rows, %d snapshots, %d baselines, and %d boots to %s\n",
+			counts.Rows, counts.Snapshots, counts.Baselines, counts.Boots, path)
+		return 0
+	}
+
+	summary, err := archive.ReadFile(ctx, path, stores)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	imported := summary.Imported
+	fmt.Printf("Imported %d history rows, %d snapshots, %d baselines, and %d boots from %s (%s, %s)\n",
+		imported.Rows, imported.Snapshots, imported.Baselines, imported.Boots, path,
+		summary.Manifest.Hostname, summary.Manifest.Created.Format(time.RFC3339))
+	return 0
+}
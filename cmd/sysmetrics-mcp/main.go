@@ -2,26 +2,120 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"log/slog"
+	"net"
 	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
 
+	"sysmetrics-mcp/internal/audit"
 	"sysmetrics-mcp/internal/config"
+	"sysmetrics-mcp/internal/exporter"
+	"sysmetrics-mcp/internal/geoip"
+	"sysmetrics-mcp/internal/grpcapi"
 	"sysmetrics-mcp/internal/handlers"
+	"sysmetrics-mcp/internal/history"
+	"sysmetrics-mcp/internal/locale"
+	"sysmetrics-mcp/internal/logging"
 
+	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
 func main() {
 	var cfg config.Config
+	var showVersion bool
 
 	// Parse CLI flags
 	flag.StringVar(&cfg.TempUnit, "temp-unit", "celsius", "Temperature unit: celsius, fahrenheit, or kelvin")
-	flag.IntVar(&cfg.MaxProcesses, "max-processes", 10, "Maximum number of processes to list")
+	flag.IntVar(&cfg.MaxProcesses, "max-processes", config.DefaultMaxProcesses, "Default number of processes to list")
+	flag.IntVar(&cfg.MaxProcessesCap, "max-processes-cap", config.DefaultMaxProcessesCap, "Upper bound for process list limits (at most 1000)")
+	flag.IntVar(&cfg.MaxResponseBytes, "max-response-bytes", config.DefaultMaxResponseBytes, "Maximum tool result size in bytes before lists are truncated (0 = unlimited)")
+	flag.IntVar(&cfg.RateLimit, "rate-limit", 0, "Maximum calls per tool per minute (0 = unlimited)")
+	flag.DurationVar(&cfg.CacheTTL, "cache-ttl", 0, "Reuse results of read-only tools called with identical arguments for this long (0 = off)")
+	flag.StringVar(&cfg.CacheToolTTLsStr, "cache-tool-ttls", "", "Comma-separated per-tool cache TTLs overriding --cache-ttl, as tool=duration (e.g. get_process_list=2s,get_network_connections=2s; 0 disables caching for that tool)")
+	flag.DurationVar(&cfg.ToolTimeout, "tool-timeout", 0, "End read-only tool calls that run longer than this with an error (0 = off)")
+	flag.StringVar(&cfg.Locale, "locale", locale.Default, "Locale for human-readable fields: "+strings.Join(locale.Names(), ", "))
 	flag.StringVar(&cfg.MountPointsStr, "mount-points", "", "Comma-separated mount points to monitor (empty = all)")
+	flag.StringVar(&cfg.LogDir, "log-dir", "/var/log", "Log directory analyzed by get_log_growth")
 	flag.StringVar(&cfg.InterfacesStr, "interfaces", "", "Comma-separated interfaces to monitor (empty = all)")
 	flag.BoolVar(&cfg.EnableGPU, "enable-gpu", true, "Attempt to read GPU metrics if available")
-	flag.Parse()
+	flag.StringVar(&cfg.SudoAllowlistStr, "sudo-allowlist", "", "Comma-separated commands the server may run via non-interactive sudo (e.g. smartctl,docker)")
+	flag.StringVar(&cfg.GeoIPDB, "geoip-db", "", "Path to a GeoLite2/DB-IP Country or City MMDB file for enriching remote addresses")
+	flag.StringVar(&cfg.ASNDB, "asn-db", "", "Path to a GeoLite2/DB-IP ASN MMDB file for enriching remote addresses")
+	flag.StringVar(&cfg.NUTAddr, "nut-addr", config.DefaultNUTAddr, "Network UPS Tools upsd address (empty = disabled)")
+	flag.StringVar(&cfg.ApcupsdAddr, "apcupsd-addr", config.DefaultApcupsdAddr, "apcupsd NIS address (empty = disabled)")
+	flag.StringVar(&cfg.Zigbee2MQTTURL, "zigbee2mqtt-url", "", "Zigbee2MQTT frontend WebSocket URL, e.g. ws://localhost:8080/api (empty = disabled)")
+	flag.StringVar(&cfg.ZWaveJSURL, "zwave-js-url", "", "zwave-js-server WebSocket URL, e.g. ws://localhost:3000 (empty = disabled)")
+	flag.BoolVar(&cfg.UpdateOffline, "update-offline", false, "Never contact GitHub for update checks; report only the cached release")
+	flag.BoolVar(&cfg.AllowSelfUpdate, "allow-self-update", false, "Register the apply_update tool, which replaces this binary with the latest release")
+	flag.BoolVar(&cfg.AllowServiceControl, "allow-service-control", false, "Register the control_service tool, which starts, stops, and restarts systemd units in --service-control-allowlist")
+	flag.StringVar(&cfg.ServiceControlAllowlistStr, "service-control-allowlist", "", "Comma-separated systemd units control_service may act on (e.g. nginx,docker)")
+	flag.BoolVar(&cfg.AllowProcessControl, "allow-process-control", false, "Register the manage_process tool, which signals and renices processes")
+	flag.BoolVar(&cfg.ProcessControlAllowRoot, "process-control-allow-root", false, "Let manage_process act on root-owned processes")
+	flag.StringVar(&cfg.ProcessControlDenyUsersStr, "process-control-deny-users", "", "Comma-separated users whose processes manage_process never touches")
+	flag.StringVar(&cfg.ProcessControlDenyPIDsStr, "process-control-deny-pids", "", "Comma-separated PIDs manage_process never touches, in addition to PID 1 and the server")
+	flag.BoolVar(&cfg.AllowFSSnapshots, "allow-fs-snapshots", false, "Register the create_fs_snapshot tool, which creates ZFS, Btrfs, and LVM snapshots")
+	flag.StringVar(&cfg.HistoryDB, "history-db", "", "Path to a SQLite file for persistent metrics history (empty = disabled)")
+	flag.DurationVar(&cfg.HistoryRetention, "history-retention", config.DefaultHistoryRetention, "How long to keep metrics history; raw samples are kept for 24h, then 1-minute rollups for 7 days, then 5-minute rollups")
+	flag.DurationVar(&cfg.SampleInterval, "sample-interval", config.DefaultSampleInterval, "Background sampling interval for metrics history")
+	flag.StringVar(&cfg.SnapshotSchedule, "snapshot-schedule", config.DefaultSnapshotSchedule, "Cron schedule (minute hour day month weekday, or @hourly/@daily) for health snapshots stored with --history-db (empty = disabled)")
+	flag.StringVar(&cfg.SyntheticChecksStr, "synthetic-checks", "", "Comma-separated recurring checks stored in history, as type:target[@interval] with type http, tcp, ping, or dns (e.g. http:https://nas.lan/health@30s,tcp:nas.lan:445,ping:192.168.1.1@10s)")
+	flag.StringVar(&cfg.WatchProcessesStr, "watch-processes", "", "Comma-separated process names whose starts, stops, and restarts are recorded in the event journal (requires --history-db)")
+	flag.StringVar(&cfg.WatchServicesStr, "watch-services", "", "Comma-separated systemd units whose state changes and restarts are recorded for get_service_flaps (requires --history-db)")
+	flag.StringVar(&cfg.ExportURL, "export-url", "", "InfluxDB write or Prometheus remote-write URL to push sampled metrics to (empty = disabled)")
+	flag.StringVar(&cfg.ExportFormat, "export-format", config.ExportFormatInflux, "Export protocol: influx (line protocol) or prometheus (remote-write)")
+	flag.StringVar(&cfg.ExportToken, "export-token", "", "Auth token for the export endpoint (default: $SYSMETRICS_EXPORT_TOKEN)")
+	flag.StringVar(&cfg.GRPCAddr, "grpc-addr", "", "Serve metrics and read-only tools over gRPC on this host:port, e.g. 127.0.0.1:50051 (empty = disabled)")
+	flag.BoolVar(&cfg.GRPCOnly, "grpc-only", false, "Serve only the gRPC API, without MCP on stdio, until interrupted (requires --grpc-addr)")
+	flag.StringVar(&cfg.GRPCToken, "grpc-token", "", "Bearer token gRPC clients must send (default: $SYSMETRICS_GRPC_TOKEN)")
+	flag.StringVar(&cfg.FleetHostsStr, "fleet-hosts", "", "Comma-separated host:port gRPC addresses of other agents for compare_hosts and find_outlier_hosts (empty = disabled)")
+	flag.StringVar(&cfg.FleetToken, "fleet-token", "", "Bearer token sent to --fleet-hosts agents (default: $SYSMETRICS_FLEET_TOKEN)")
+	flag.DurationVar(&cfg.ExportInterval, "export-interval", config.DefaultExportInterval, "How often queued samples are pushed to the export endpoint")
+	flag.StringVar(&cfg.LogLevel, "log-level", logging.DefaultLevel, "Log level: debug, info, warn, or error")
+	flag.StringVar(&cfg.LogFile, "log-file", "", "Append JSON logs to this file instead of text logs on stderr")
+	flag.StringVar(&cfg.AuditLog, "audit-log", "", "Append every tool call to this JSON-lines file (empty = keep the last 1000 calls in memory)")
+	flag.IntVar(&cfg.AuditLogMaxMB, "audit-log-max-mb", config.DefaultAuditLogMaxMB, "Rotate the audit log once it reaches this size in MB")
+	flag.IntVar(&cfg.AuditLogBackups, "audit-log-backups", config.DefaultAuditLogBackups, "Number of rotated audit log files to keep")
+	flag.StringVar(&cfg.ToolProfile, "tool-profile", config.ProfileFull, "Base set of tools to register: full, private (no process, connection, or audit details), or minimal (core host metrics)")
+	flag.StringVar(&cfg.RedactStr, "redact", "", "Comma-separated identifiers to redact from tool results: ip, hostname, user, cmdline")
+	flag.StringVar(&cfg.RedactPatternsStr, "redact-patterns", "", "Comma-separated regular expressions redacted from command lines in tool results (e.g. --password[= ]\\S+)")
+	flag.StringVar(&cfg.RedactMode, "redact-mode", config.RedactMask, "How redacted values are replaced: mask (a fixed marker) or hash (a short salted hash, consistent until restart)")
+	flag.StringVar(&cfg.EnableToolsStr, "enable-tools", "", "Comma-separated tools to register in addition to the profile")
+	flag.StringVar(&cfg.DisableToolsStr, "disable-tools", "", "Comma-separated tools never to register (e.g. get_process_list,get_network_connections)")
+	flag.StringVar(&cfg.Once, "once", "", "Run this tool once, print its result to stdout, and exit without serving MCP")
+	flag.StringVar(&cfg.OnceArgsStr, "args", "", "JSON object of arguments for --once, e.g. '{\"limit\": 5}'")
+	flag.BoolVar(&showVersion, "version", false, "Print the server version and exit")
+
+	// Subcommands accept the server flags too; install-service bakes them into the unit
+	args := os.Args[1:]
+	var command string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+	var archivePath *string
+	serviceCommand := command
+	if command == commandExport || command == commandImport {
+		archivePath, serviceCommand = archiveFlags(command), ""
+	}
+	svc, dryRun, err := serviceFlags(serviceCommand)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		os.Exit(1)
+	}
+	_ = flag.CommandLine.Parse(args)
+
+	if showVersion {
+		fmt.Println(config.ServerName, config.ServerVersion)
+		return
+	}
 
 	// Validate and parse comma-separated lists
 	if err := cfg.Validate(); err != nil {
@@ -29,19 +123,247 @@ func main() {
 		os.Exit(1)
 	}
 
+	switch {
+	case archivePath != nil:
+		os.Exit(runArchiveCommand(command, &cfg, *archivePath))
+	case command != "":
+		os.Exit(runServiceCommand(command, &cfg, *svc, *dryRun))
+	}
+
+	// Stdout carries the MCP protocol, so logs go to stderr or --log-file
+	logger, err := logging.New(logging.Options{
+		Level: cfg.LogLevel,
+		File:  cfg.LogFile,
+		Attrs: []slog.Attr{slog.String("version", config.ServerVersion)},
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Create MCP server
 	s := server.NewMCPServer(
-		"sysmetrics-mcp",
-		"1.0.0",
+		config.ServerName,
+		config.ServerVersion,
 	)
 
-	// Create handler manager and register tools
+	// Load optional offline GeoIP/ASN databases (kept open for the life of the process)
+	geo, err := geoip.Open(cfg.GeoIPDB, cfg.ASNDB)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Create handler manager, probe capabilities, and register tools
 	hm := handlers.NewHandlerManager(&cfg)
+	hm.SetLogger(logger.Logger)
+
+	// Open the optional file-backed audit trail of tool calls
+	var auditLog *audit.Log
+	if cfg.AuditLog != "" {
+		auditLog, err = audit.Open(audit.Options{
+			Path:     cfg.AuditLog,
+			MaxBytes: int64(cfg.AuditLogMaxMB) << 20,
+			Backups:  cfg.AuditLogBackups,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+			os.Exit(1)
+		}
+		hm.SetAuditLog(auditLog)
+	}
+	hm.SetGeoIP(geo)
+
+	// Open the optional metrics history store written by the background sampler
+	var store *history.Store
+	if cfg.HistoryDB != "" {
+		store, err = history.Open(cfg.HistoryDB, cfg.HistoryRetention)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+			os.Exit(1)
+		}
+		hm.SetHistory(store)
+	}
+
+	// Create the optional remote-write exporter fed by the background sampler
+	var exp *exporter.Exporter
+	if cfg.ExportURL != "" {
+		if cfg.ExportToken == "" {
+			cfg.ExportToken = os.Getenv("SYSMETRICS_EXPORT_TOKEN")
+		}
+		hostname, _ := os.Hostname()
+		exp, err = exporter.New(exporter.Options{
+			URL:      cfg.ExportURL,
+			Format:   cfg.ExportFormat,
+			Token:    cfg.ExportToken,
+			Interval: cfg.ExportInterval,
+			Hostname: hostname,
+			Logger:   logger.Logger,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+			os.Exit(1)
+		}
+		hm.SetExporter(exp)
+	}
+
+	// Connect the optional fleet of agents queried by the fleet tools
+	if len(cfg.FleetHosts) > 0 {
+		if cfg.FleetToken == "" {
+			cfg.FleetToken = os.Getenv("SYSMETRICS_FLEET_TOKEN")
+		}
+		agents := make([]*grpcapi.Client, len(cfg.FleetHosts))
+		for i, addr := range cfg.FleetHosts {
+			agents[i], err = grpcapi.NewClient(addr, cfg.FleetToken)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		hm.SetFleet(agents)
+	}
+
 	hm.RegisterTools(s)
+	if unknown := hm.UnknownToolFilters(); len(unknown) > 0 {
+		fmt.Fprintf(os.Stderr, "Configuration error: unknown tools in --enable-tools/--disable-tools/--cache-tool-ttls: %s\n", strings.Join(unknown, ", "))
+		os.Exit(1)
+	}
 
-	// Start server via stdio
-	if err := server.ServeStdio(s); err != nil {
+	// One-shot mode: run a single tool without background workers or MCP
+	if cfg.Once != "" {
+		code := runOnce(hm, cfg.Once, cfg.OnceArgs)
+		if store != nil {
+			_ = store.Close()
+		}
+		if auditLog != nil {
+			_ = auditLog.Close()
+		}
+		_ = logger.Close()
+		os.Exit(code)
+	}
+
+	// Bind the optional gRPC API, which serves the registered tools
+	var grpcServer *grpcapi.Server
+	if cfg.GRPCAddr != "" {
+		if cfg.GRPCToken == "" {
+			cfg.GRPCToken = os.Getenv("SYSMETRICS_GRPC_TOKEN")
+		}
+		grpcServer, err = grpcapi.Listen(grpcapi.Options{
+			Addr:    cfg.GRPCAddr,
+			Token:   cfg.GRPCToken,
+			Backend: hm,
+			Logger:  logger.Logger,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+			os.Exit(1)
+		}
+		if host, _, _ := net.SplitHostPort(cfg.GRPCAddr); cfg.GRPCToken == "" && !isLoopback(host) {
+			logger.Warn("gRPC API is reachable from the network without --grpc-token", "addr", cfg.GRPCAddr)
+		}
+	}
+
+	// Background workers run for as long as the server does
+	ctx, cancel := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	if exp != nil {
+		workers.Go(func() { exp.Run(ctx) })
+	}
+	if grpcServer != nil {
+		workers.Go(func() {
+			if err := grpcServer.Run(ctx); err != nil {
+				logger.Error("gRPC API stopped", "error", err)
+			}
+		})
+	}
+
+	// Sample for the history store and exporter (a no-op when neither is configured)
+	workers.Go(func() { hm.RunSampler(ctx, cfg.SampleInterval) })
+
+	// Store health snapshots for get_health_report (a no-op without --history-db)
+	workers.Go(func() { hm.RunSnapshotScheduler(ctx) })
+
+	// Run the recurring synthetic checks (a no-op without --synthetic-checks)
+	workers.Go(func() { hm.RunSyntheticChecks(ctx) })
+
+	// Journal alerts, throttling, failed units, OOM kills, and restarts (a no-op without --history-db)
+	workers.Go(func() { hm.RunEventJournal(ctx) })
+
+	// Record state changes and restarts of the watched units (a no-op without --watch-services)
+	workers.Go(func() { hm.RunServiceWatch(ctx) })
+
+	// Keep the availability ledger's record of this boot current
+	workers.Go(func() { hm.RunAvailabilityTracker(ctx) })
+
+	// Persist per-tool call counts and latencies for get_usage_stats
+	workers.Go(func() { hm.RunUsageTracker(ctx) })
+
+	// Start server via stdio, or wait for a signal when only gRPC is served
+	logger.Info("server starting", "history", cfg.HistoryDB != "", "export", cfg.ExportURL != "", "grpc", cfg.GRPCAddr)
+	if cfg.GRPCOnly {
+		sigCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		<-sigCtx.Done()
+		stop()
+	} else {
+		err = server.ServeStdio(s)
+	}
+	cancel()
+	workers.Wait()
+	if store != nil {
+		_ = store.Close()
+	}
+	if auditLog != nil {
+		_ = auditLog.Close()
+	}
+	if err != nil {
+		logger.Error("server stopped", "error", err)
+		_ = logger.Close()
 		fmt.Fprintf(os.Stderr, "Server error: %v\n", err)
 		os.Exit(1)
 	}
+	logger.Info("server stopped")
+	_ = logger.Close()
+}
+
+// runOnce runs one tool, prints its text result to stdout (JSON indented for reading), and
+// returns the process exit code: 1 when the tool is unknown or reports an error
+func runOnce(hm *handlers.HandlerManager, name string, arguments map[string]interface{}) int {
+	if arguments == nil {
+		arguments = map[string]interface{}{}
+	}
+	res, err := hm.CallTool(context.Background(), "cli", name, arguments)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v (registered tools: %s)\n", err, strings.Join(hm.Tools(), ", "))
+		return 1
+	}
+
+	out := os.Stdout
+	if res.IsError {
+		out = os.Stderr
+	}
+	for _, content := range res.Content {
+		text, ok := content.(mcp.TextContent)
+		if !ok {
+			continue
+		}
+		var indented bytes.Buffer
+		if json.Indent(&indented, []byte(text.Text), "", "  ") == nil {
+			fmt.Fprintln(out, indented.String())
+		} else {
+			fmt.Fprintln(out, text.Text)
+		}
+	}
+	if res.IsError {
+		return 1
+	}
+	return 0
+}
+
+// isLoopback reports whether host names only the local machine
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
 }
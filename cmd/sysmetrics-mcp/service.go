@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"sysmetrics-mcp/internal/capabilities"
+	"sysmetrics-mcp/internal/config"
+	"sysmetrics-mcp/internal/systemd"
+)
+
+// Service management subcommands
+const (
+	commandInstall   = "install-service"
+	commandUninstall = "uninstall-service"
+)
+
+// installOnlyFlags configure install-service itself and are never passed to the service
+var installOnlyFlags = map[string]bool{
+	"service-name": true, "unit-dir": true, "service-user": true, "no-enable": true, "dry-run": true,
+	"version": true, "once": true, "args": true, "grpc-only": true,
+}
+
+// secretFlags move to the unit's root-only environment file, keyed by the variable the
+// server reads them from
+var secretFlags = map[string]string{
+	"grpc-token":   "SYSMETRICS_GRPC_TOKEN",
+	"export-token": "SYSMETRICS_EXPORT_TOKEN",
+	"fleet-token":  "SYSMETRICS_FLEET_TOKEN",
+}
+
+// pathFlags name files; they are made absolute since the service does not run in the
+// installer's working directory
+var pathFlags = map[string]bool{
+	"history-db": true, "audit-log": true, "log-file": true, "geoip-db": true, "asn-db": true,
+}
+
+// serviceFlags registers the flags of a subcommand. It returns an error for an unknown
+// command; with no command it registers nothing.
+func serviceFlags(command string) (*systemd.Options, *bool, error) {
+	opts := &systemd.Options{Name: systemd.DefaultName, UnitDir: systemd.DefaultUnitDir, EnvDir: systemd.DefaultEnvDir}
+	dryRun := new(bool)
+	switch command {
+	case "":
+		return opts, dryRun, nil
+	case commandInstall:
+		flag.StringVar(&opts.User, "service-user", "", "Run the service as this existing user instead of a transient dynamic user")
+		flag.BoolVar(&opts.NoEnable, "no-enable", false, "Write the unit without enabling and starting it")
+		flag.BoolVar(dryRun, "dry-run", false, "Print the unit instead of installing it")
+	case commandUninstall, "uninstall":
+	default:
+		return opts, dryRun, fmt.Errorf("unknown command: %s (expected %s, %s, %s, or %s)", command, commandInstall, commandUninstall, commandExport, commandImport)
+	}
+	flag.StringVar(&opts.Name, "service-name", systemd.DefaultName, "Name of the systemd unit")
+	flag.StringVar(&opts.UnitDir, "unit-dir", systemd.DefaultUnitDir, "Directory for the unit file")
+	return opts, dryRun, nil
+}
+
+// runServiceCommand installs or removes the systemd service and returns the exit code
+func runServiceCommand(command string, cfg *config.Config, opts systemd.Options, dryRun bool) int {
+	ctx := context.Background()
+	if runtime.GOOS != "linux" {
+		fmt.Fprintf(os.Stderr, "Error: %s requires Linux with systemd\n", command)
+		return 1
+	}
+	if !dryRun && !capabilities.IsRoot() {
+		fmt.Fprintf(os.Stderr, "Error: %s must run as root\n", command)
+		return 1
+	}
+
+	if command != commandInstall {
+		if err := systemd.Uninstall(ctx, opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Removed %s.service\n", opts.Name)
+		return 0
+	}
+
+	binary, err := os.Executable()
+	if err == nil {
+		binary, err = filepath.EvalSymlinks(binary)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to locate this binary: %v\n", err)
+		return 1
+	}
+	opts.Binary = binary
+	for _, path := range []*string{&cfg.HistoryDB, &cfg.AuditLog, &cfg.LogFile} {
+		*path = absPath(*path)
+	}
+	opts.Config = cfg
+	opts.Caps = capabilities.Detect()
+	opts.Args, opts.Env = serviceArgs()
+
+	var unit systemd.Unit
+	if dryRun {
+		unit = systemd.Render(opts)
+		fmt.Print(unit.Text)
+	} else if unit, err = systemd.Install(ctx, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	for _, w := range unit.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+	}
+	switch {
+	case dryRun:
+		if unit.EnvFile != "" {
+			fmt.Fprintf(os.Stderr, "Tokens would be written to %s (mode 0600)\n", filepath.Join(opts.EnvDir, opts.Name))
+		}
+	case opts.NoEnable:
+		fmt.Printf("Installed %s.service; start it with: systemctl enable --now %s\n", opts.Name, opts.Name)
+	default:
+		fmt.Printf("Installed and started %s.service; check it with: systemctl status %s\n", opts.Name, opts.Name)
+	}
+	return 0
+}
+
+// serviceArgs turns the server flags given on the command line into ExecStart arguments,
+// moving tokens (from flags or the environment) into the environment file
+func serviceArgs() ([]string, map[string]string) {
+	var args []string
+	env := map[string]string{}
+	flag.Visit(func(f *flag.Flag) {
+		value := f.Value.String()
+		switch {
+		case installOnlyFlags[f.Name]:
+		case secretFlags[f.Name] != "":
+			env[secretFlags[f.Name]] = value
+		default:
+			if pathFlags[f.Name] {
+				value = absPath(value)
+			}
+			args = append(args, "--"+f.Name+"="+value)
+		}
+	})
+	for _, name := range secretFlags {
+		if _, ok := env[name]; !ok && os.Getenv(name) != "" {
+			env[name] = os.Getenv(name)
+		}
+	}
+	return args, env
+}
+
+// absPath makes a non-empty path absolute, leaving it unchanged on error
+func absPath(path string) string {
+	if path == "" {
+		return path
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
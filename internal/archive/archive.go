@@ -0,0 +1,315 @@
+// Package archive moves the server's accumulated data (metrics history, health snapshots,
+// benchmark baselines, and the boot ledger) between hosts as one gzip-compressed JSON
+// lines file, so it survives an SD card reimage or a move to new hardware.
+package archive
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"sysmetrics-mcp/internal/availability"
+	"sysmetrics-mcp/internal/bench"
+	"sysmetrics-mcp/internal/history"
+)
+
+// Format and Version identify the archive layout; Read rejects newer versions
+const (
+	Format  = "sysmetrics-mcp-archive"
+	Version = 1
+)
+
+// importBatch is how many history rows are stored per transaction during Read
+const importBatch = 5000
+
+// Record types, one per line after the manifest
+const (
+	recordManifest = "manifest"
+	recordRow      = "row"
+	recordSnapshot = "snapshot"
+	recordBaseline = "baseline"
+	recordBoot     = "boot"
+	recordEnd      = "end"
+)
+
+// Stores are the data an archive is written from or read into; nil stores are skipped
+type Stores struct {
+	History   *history.Store
+	Baselines *bench.Store
+	Ledger    *availability.Ledger
+}
+
+// Manifest describes an archive; it is the first record
+type Manifest struct {
+	Format        string    `json:"format"`
+	Version       int       `json:"version"`
+	Created       time.Time `json:"created"`
+	Hostname      string    `json:"hostname,omitempty"`
+	ServerVersion string    `json:"server_version,omitempty"`
+}
+
+// Counts are the records of each kind, written as the last record so a truncated archive
+// is detected
+type Counts struct {
+	Rows      int `json:"rows"`
+	Snapshots int `json:"snapshots"`
+	Baselines int `json:"baselines"`
+	Boots     int `json:"boots"`
+}
+
+// Summary reports what Read found and what it added. Records already present are skipped.
+type Summary struct {
+	Manifest Manifest `json:"manifest"`
+	Archived Counts   `json:"archived"`
+	Imported Counts   `json:"imported"`
+}
+
+// record is one line of an archive
+type record struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// snapshotRecord is a health snapshot; its data is kept as JSON rather than a string
+type snapshotRecord struct {
+	Time time.Time       `json:"time"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Write streams every record from stores to w and returns the counts written
+func Write(ctx context.Context, w io.Writer, stores Stores, manifest Manifest) (Counts, error) {
+	var counts Counts
+	zw := gzip.NewWriter(w)
+	bw := bufio.NewWriter(zw)
+	enc := json.NewEncoder(bw)
+	put := func(kind string, v any) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		return enc.Encode(record{Type: kind, Data: data})
+	}
+
+	manifest.Format, manifest.Version = Format, Version
+	if manifest.Created.IsZero() {
+		manifest.Created = time.Now().UTC()
+	}
+	if err := put(recordManifest, manifest); err != nil {
+		return counts, err
+	}
+
+	if stores.History != nil {
+		if err := stores.History.ExportRows(ctx, func(r history.Row) error {
+			counts.Rows++
+			return put(recordRow, r)
+		}); err != nil {
+			return counts, err
+		}
+		snapshots, err := stores.History.AllSnapshots(ctx)
+		if err != nil {
+			return counts, err
+		}
+		for _, s := range snapshots {
+			if err := put(recordSnapshot, snapshotRecord{Time: s.Time, Data: s.Data}); err != nil {
+				return counts, err
+			}
+			counts.Snapshots++
+		}
+	}
+	if stores.Baselines != nil {
+		reports, err := stores.Baselines.All()
+		if err != nil {
+			return counts, fmt.Errorf("failed to read baselines: %w", err)
+		}
+		for _, r := range reports {
+			if err := put(recordBaseline, r); err != nil {
+				return counts, err
+			}
+			counts.Baselines++
+		}
+	}
+	if stores.Ledger != nil {
+		boots, err := stores.Ledger.Boots()
+		if err != nil {
+			return counts, fmt.Errorf("failed to read the availability ledger: %w", err)
+		}
+		for _, b := range boots {
+			if err := put(recordBoot, b); err != nil {
+				return counts, err
+			}
+			counts.Boots++
+		}
+	}
+
+	if err := put(recordEnd, counts); err != nil {
+		return counts, err
+	}
+	if err := bw.Flush(); err != nil {
+		return counts, err
+	}
+	return counts, zw.Close()
+}
+
+// Read imports an archive into stores. Records for a nil store are counted but not
+// imported. Imports are idempotent, so an interrupted import can simply be repeated.
+func Read(ctx context.Context, r io.Reader, stores Stores) (Summary, error) {
+	var summary Summary
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return summary, fmt.Errorf("not a sysmetrics-mcp archive: %w", err)
+	}
+	defer zr.Close()
+	dec := json.NewDecoder(zr)
+
+	var rows []history.Row
+	flushRows := func() error {
+		if len(rows) == 0 || stores.History == nil {
+			rows = rows[:0]
+			return nil
+		}
+		n, err := stores.History.ImportRows(ctx, rows)
+		summary.Imported.Rows += n
+		rows = rows[:0]
+		return err
+	}
+	var snapshots []history.Snapshot
+	var reports []bench.Report
+	var boots []availability.Boot
+
+	first, ended := true, false
+	for {
+		var rec record
+		if err := dec.Decode(&rec); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return summary, fmt.Errorf("corrupt archive: %w", err)
+		}
+		if ended {
+			return summary, errors.New("corrupt archive: records after the end marker")
+		}
+		if first != (rec.Type == recordManifest) {
+			return summary, errors.New("not a sysmetrics-mcp archive: missing manifest")
+		}
+		first = false
+
+		var err error
+		switch rec.Type {
+		case recordManifest:
+			if err = json.Unmarshal(rec.Data, &summary.Manifest); err == nil {
+				if summary.Manifest.Format != Format {
+					return summary, errors.New("not a sysmetrics-mcp archive")
+				}
+				if summary.Manifest.Version > Version {
+					return summary, fmt.Errorf("archive version %d is newer than this server supports (%d)", summary.Manifest.Version, Version)
+				}
+			}
+		case recordRow:
+			var row history.Row
+			if err = json.Unmarshal(rec.Data, &row); err == nil {
+				summary.Archived.Rows++
+				rows = append(rows, row)
+				if len(rows) >= importBatch {
+					err = flushRows()
+				}
+			}
+		case recordSnapshot:
+			var s snapshotRecord
+			if err = json.Unmarshal(rec.Data, &s); err == nil {
+				summary.Archived.Snapshots++
+				snapshots = append(snapshots, history.Snapshot{Time: s.Time, Data: s.Data})
+			}
+		case recordBaseline:
+			var b bench.Report
+			if err = json.Unmarshal(rec.Data, &b); err == nil {
+				summary.Archived.Baselines++
+				reports = append(reports, b)
+			}
+		case recordBoot:
+			var b availability.Boot
+			if err = json.Unmarshal(rec.Data, &b); err == nil {
+				summary.Archived.Boots++
+				boots = append(boots, b)
+			}
+		case recordEnd:
+			var counts Counts
+			if err = json.Unmarshal(rec.Data, &counts); err == nil && counts != summary.Archived {
+				err = fmt.Errorf("archive lists %+v but holds %+v", counts, summary.Archived)
+			}
+			ended = true
+		}
+		// Unknown record types from newer minor changes are skipped
+		if err != nil {
+			return summary, fmt.Errorf("corrupt archive: %w", err)
+		}
+	}
+	if !ended {
+		return summary, errors.New("corrupt archive: truncated before the end marker")
+	}
+
+	if err := flushRows(); err != nil {
+		return summary, err
+	}
+	if stores.History != nil && len(snapshots) > 0 {
+		n, err := stores.History.ImportSnapshots(ctx, snapshots)
+		summary.Imported.Snapshots = n
+		if err != nil {
+			return summary, err
+		}
+	}
+	if stores.Baselines != nil && len(reports) > 0 {
+		n, err := stores.Baselines.Import(reports)
+		summary.Imported.Baselines = n
+		if err != nil {
+			return summary, fmt.Errorf("failed to import baselines: %w", err)
+		}
+	}
+	if stores.Ledger != nil && len(boots) > 0 {
+		known, err := stores.Ledger.Boots()
+		if err != nil {
+			return summary, fmt.Errorf("failed to read the availability ledger: %w", err)
+		}
+		if err := stores.Ledger.Merge(boots); err != nil {
+			return summary, fmt.Errorf("failed to import boots: %w", err)
+		}
+		if merged, err := stores.Ledger.Boots(); err == nil {
+			summary.Imported.Boots = len(merged) - len(known)
+		}
+	}
+	return summary, nil
+}
+
+// WriteFile writes an archive to a new file, refusing to overwrite an existing one
+func WriteFile(ctx context.Context, path string, stores Stores, manifest Manifest) (Counts, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return Counts{}, err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600) //nolint:gosec // G304: the caller chooses where to write
+	if err != nil {
+		return Counts{}, err
+	}
+	counts, err := Write(ctx, f, stores, manifest)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+	}
+	return counts, err
+}
+
+// ReadFile imports the archive at path
+func ReadFile(ctx context.Context, path string, stores Stores) (Summary, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: the caller chooses what to import
+	if err != nil {
+		return Summary{}, err
+	}
+	defer f.Close()
+	return Read(ctx, f, stores)
+}
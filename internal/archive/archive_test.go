@@ -0,0 +1,117 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"sysmetrics-mcp/internal/availability"
+	"sysmetrics-mcp/internal/bench"
+	"sysmetrics-mcp/internal/history"
+)
+
+func openStores(t *testing.T) Stores {
+	t.Helper()
+	dir := t.TempDir()
+	h, err := history.Open(filepath.Join(dir, "history.db"), 0)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	t.Cleanup(func() { _ = h.Close() })
+	return Stores{
+		History:   h,
+		Baselines: bench.NewStore(filepath.Join(dir, "baselines.json")),
+		Ledger:    availability.NewLedger(filepath.Join(dir, "availability.json")),
+	}
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	src := openStores(t)
+	now := time.Now().Truncate(time.Second)
+	if err := src.History.Write(ctx, []history.Sample{
+		{Time: now, Metric: "cpu_percent", Value: 5},
+		{Time: now.Add(-time.Minute), Metric: "cpu_percent", Value: 7},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.History.WriteSnapshot(ctx, now, []byte(`{"status":"warning"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.Baselines.Save(bench.Report{Name: "before-upgrade", Time: now}); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.Ledger.Heartbeat(now.Add(-time.Hour), now); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	counts, err := Write(ctx, &buf, src, Manifest{Hostname: "pi"})
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	want := Counts{Rows: 2, Snapshots: 1, Baselines: 1, Boots: 1}
+	if counts != want {
+		t.Errorf("Write() counts = %+v, want %+v", counts, want)
+	}
+
+	dst := openStores(t)
+	summary, err := Read(ctx, bytes.NewReader(buf.Bytes()), dst)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if summary.Archived != want || summary.Imported != want || summary.Manifest.Hostname != "pi" || summary.Manifest.Version != Version {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+	if _, ok, _ := dst.Baselines.Get("before-upgrade"); !ok {
+		t.Error("Expected the baseline to be imported")
+	}
+
+	// A second import adds nothing
+	summary, err = Read(ctx, bytes.NewReader(buf.Bytes()), dst)
+	if err != nil || summary.Imported != (Counts{}) {
+		t.Errorf("Expected a repeated import to add nothing, got %+v (err %v)", summary.Imported, err)
+	}
+}
+
+func TestReadRejectsBadArchives(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	if _, err := Write(ctx, &buf, Stores{}, Manifest{}); err != nil {
+		t.Fatal(err)
+	}
+	full := buf.Bytes()
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"not gzip", []byte("hello"), "not a sysmetrics-mcp archive"},
+		{"truncated", full[:len(full)/2], "corrupt archive"},
+	}
+	for _, tt := range tests {
+		if _, err := Read(ctx, bytes.NewReader(tt.data), Stores{}); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: Read() error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+	if _, err := Read(ctx, bytes.NewReader(full), Stores{}); err != nil {
+		t.Errorf("Expected an empty archive to read cleanly, got %v", err)
+	}
+}
+
+func TestWriteFileRefusesOverwrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archive.jsonl.gz")
+	if _, err := WriteFile(context.Background(), path, Stores{}, Manifest{}); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if _, err := WriteFile(context.Background(), path, Stores{}, Manifest{}); err == nil {
+		t.Error("Expected WriteFile to refuse an existing file")
+	}
+	if _, err := ReadFile(context.Background(), path, Stores{}); err != nil {
+		t.Errorf("ReadFile failed: %v", err)
+	}
+}
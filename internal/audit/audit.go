@@ -0,0 +1,247 @@
+// Package audit records tool invocations to an in-memory ring and, optionally, a
+// size-rotated JSON-lines file so operators can review what clients queried.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Defaults for the audit log
+const (
+	DefaultMaxBytes      = 10 << 20
+	DefaultBackups       = 3
+	DefaultMemoryEntries = 1000
+)
+
+// Entry is one recorded tool call
+type Entry struct {
+	Time        time.Time `json:"time"`
+	Tool        string    `json:"tool"`
+	Args        string    `json:"args,omitempty"`
+	Caller      string    `json:"caller,omitempty"`
+	Session     string    `json:"session,omitempty"`
+	DurationMS  int64     `json:"duration_ms"`
+	ResultBytes int       `json:"result_bytes"`
+	IsError     bool      `json:"is_error,omitempty"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// Filter selects entries returned by Recent
+type Filter struct {
+	Tool       string
+	Since      time.Time
+	ErrorsOnly bool
+	Limit      int
+}
+
+func (f Filter) match(e Entry) bool {
+	return (f.Tool == "" || e.Tool == f.Tool) && !e.Time.Before(f.Since) && (!f.ErrorsOnly || e.IsError)
+}
+
+// Options configures a Log
+type Options struct {
+	// Path of the log file; empty keeps entries in memory only
+	Path string
+	// MaxBytes rotates the file once it would grow past this size
+	MaxBytes int64
+	// Backups is how many rotated files (path.1, path.2, ...) are kept
+	Backups int
+	// MemoryEntries bounds the in-memory ring used when there is no file
+	MemoryEntries int
+}
+
+// Log is an append-only audit trail
+type Log struct {
+	opts Options
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+	ring []Entry
+	next int
+}
+
+// Open creates a Log, opening (or creating) the file when a path is set
+func Open(opts Options) (*Log, error) {
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = DefaultMaxBytes
+	}
+	if opts.Backups < 0 {
+		opts.Backups = 0
+	}
+	if opts.MemoryEntries <= 0 {
+		opts.MemoryEntries = DefaultMemoryEntries
+	}
+
+	l := &Log{opts: opts}
+	if opts.Path != "" {
+		if err := l.openFile(); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// Path returns the log file path, or "" for a memory-only log
+func (l *Log) Path() string {
+	return l.opts.Path
+}
+
+// openFile opens the log file for appending; callers hold mu or own l exclusively
+func (l *Log) openFile() error {
+	f, err := os.OpenFile(filepath.Clean(l.opts.Path), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log %s: %w", l.opts.Path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to open audit log %s: %w", l.opts.Path, err)
+	}
+	l.file, l.size = f, info.Size()
+	return nil
+}
+
+// Record appends an entry
+func (l *Log) Record(e Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.ring) < l.opts.MemoryEntries {
+		l.ring = append(l.ring, e)
+	} else {
+		l.ring[l.next] = e
+	}
+	l.next = (l.next + 1) % l.opts.MemoryEntries
+
+	if l.file == nil {
+		return nil
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	line = append(line, '\n')
+	if l.size > 0 && l.size+int64(len(line)) > l.opts.MaxBytes {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := l.file.Write(line)
+	l.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// rotate shifts path.N-1 to path.N, ..., path to path.1 and starts a new file
+func (l *Log) rotate() error {
+	_ = l.file.Close()
+	l.file = nil
+
+	path := l.opts.Path
+	if l.opts.Backups == 0 {
+		_ = os.Remove(path)
+	} else {
+		for i := l.opts.Backups - 1; i >= 1; i-- {
+			_ = os.Rename(fmt.Sprintf("%s.%d", path, i), fmt.Sprintf("%s.%d", path, i+1))
+		}
+		if err := os.Rename(path, path+".1"); err != nil {
+			return fmt.Errorf("failed to rotate audit log: %w", err)
+		}
+	}
+	return l.openFile()
+}
+
+// Recent returns matching entries newest first, limited to f.Limit, along with how many
+// entries matched in total. File-backed logs are read from disk, so entries from before
+// a restart are included.
+func (l *Log) Recent(f Filter) ([]Entry, int, error) {
+	var entries []Entry
+	l.mu.Lock()
+	if l.file == nil {
+		// The ring holds entries oldest first starting at next once it has wrapped
+		for i := range l.ring {
+			e := l.ring[(l.next+i)%len(l.ring)]
+			if f.match(e) {
+				entries = append(entries, e)
+			}
+		}
+		l.mu.Unlock()
+	} else {
+		l.mu.Unlock()
+		var err error
+		if entries, err = l.readFiles(f); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	total := len(entries)
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	if f.Limit > 0 && len(entries) > f.Limit {
+		entries = entries[:f.Limit]
+	}
+	if entries == nil {
+		entries = []Entry{}
+	}
+	return entries, total, nil
+}
+
+// readFiles reads the rotated files and the current file, oldest first
+func (l *Log) readFiles(f Filter) ([]Entry, error) {
+	paths := make([]string, 0, l.opts.Backups+1)
+	for i := l.opts.Backups; i >= 1; i-- {
+		paths = append(paths, fmt.Sprintf("%s.%d", l.opts.Path, i))
+	}
+	paths = append(paths, l.opts.Path)
+
+	var entries []Entry
+	for _, path := range paths {
+		file, err := os.Open(filepath.Clean(path))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read audit log: %w", err)
+		}
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 64*1024), 1<<20)
+		for scanner.Scan() {
+			var e Entry
+			// Skip lines torn by a crash mid-write
+			if json.Unmarshal(scanner.Bytes(), &e) != nil {
+				continue
+			}
+			if f.match(e) {
+				entries = append(entries, e)
+			}
+		}
+		err = scanner.Err()
+		_ = file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read audit log: %w", err)
+		}
+	}
+	return entries, nil
+}
+
+// Close closes the log file, if any
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
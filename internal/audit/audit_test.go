@@ -0,0 +1,102 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testEntry(i int, tool string) Entry {
+	return Entry{
+		Time:       time.Unix(1700000000+int64(i), 0).UTC(),
+		Tool:       tool,
+		Args:       fmt.Sprintf(`{"n":%d}`, i),
+		DurationMS: int64(i),
+		IsError:    i%5 == 0,
+	}
+}
+
+func TestMemoryLog(t *testing.T) {
+	l, err := Open(Options{MemoryEntries: 3})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	for i := 1; i <= 5; i++ {
+		if err := l.Record(testEntry(i, "get_cpu_metrics")); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+
+	entries, total, err := l.Recent(Filter{})
+	if err != nil {
+		t.Fatalf("Recent failed: %v", err)
+	}
+	if total != 3 || entries[0].DurationMS != 5 || entries[2].DurationMS != 3 {
+		t.Errorf("Expected the newest 3 entries newest first, got %+v", entries)
+	}
+
+	entries, _, _ = l.Recent(Filter{ErrorsOnly: true})
+	if len(entries) != 1 || entries[0].DurationMS != 5 {
+		t.Errorf("Expected only the failed call, got %+v", entries)
+	}
+}
+
+func TestFileLogRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l, err := Open(Options{Path: path, MaxBytes: 400, Backups: 2})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	for i := 1; i <= 20; i++ {
+		tool := "get_cpu_metrics"
+		if i%2 == 0 {
+			tool = "get_process_list"
+		}
+		if err := l.Record(testEntry(i, tool)); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	for _, p := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(p)
+		if err != nil {
+			t.Fatalf("Expected %s to exist: %v", p, err)
+		}
+		if info.Size() > 400 {
+			t.Errorf("Expected %s to stay within MaxBytes, got %d bytes", p, info.Size())
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Error("Expected only 2 backups to be kept")
+	}
+
+	// Reopening reads history back from disk, across the rotated files
+	l, err = Open(Options{Path: path, MaxBytes: 400, Backups: 2})
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	defer l.Close()
+	entries, total, err := l.Recent(Filter{Tool: "get_process_list", Limit: 2})
+	if err != nil {
+		t.Fatalf("Recent failed: %v", err)
+	}
+	if len(entries) != 2 || total < 3 || entries[0].DurationMS != 20 || entries[1].DurationMS != 18 {
+		t.Errorf("Expected the newest get_process_list calls first, got %d/%+v", total, entries)
+	}
+
+	entries, _, _ = l.Recent(Filter{Since: time.Unix(1700000019, 0)})
+	if len(entries) != 2 {
+		t.Errorf("Expected 2 entries since the cutoff, got %+v", entries)
+	}
+}
+
+func TestOpenInvalidPath(t *testing.T) {
+	if _, err := Open(Options{Path: filepath.Join(t.TempDir(), "missing", "audit.log")}); err == nil {
+		t.Error("Expected an error for an unwritable path")
+	}
+}
@@ -0,0 +1,272 @@
+// Package availability keeps a persistent ledger of boots and computes uptime percentages
+// and reboot history from it.
+package availability
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// sameBootTolerance is how far apart two start times may be and still describe the same
+// boot; journal timestamps and the kernel boot time differ by a few seconds, more on
+// boards without a real-time clock
+const sameBootTolerance = 2 * time.Minute
+
+// maxBoots bounds the ledger; a year of daily reboots fits
+const maxBoots = 400
+
+// Boot is one boot of the host. LastSeen is the last time the host was known to be up,
+// so the gap to the next boot's Start is downtime.
+type Boot struct {
+	Start    time.Time `json:"start"`
+	LastSeen time.Time `json:"last_seen"`
+	// EndReason is how the boot ended, filled in by the next boot; Unexpected marks
+	// panics, watchdog resets, and power loss
+	EndReason  string `json:"end_reason,omitempty"`
+	Unexpected bool   `json:"unexpected,omitempty"`
+}
+
+// Ledger keeps boots in a JSON file, oldest first
+type Ledger struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewLedger returns a Ledger backed by path; the file is created on first write
+func NewLedger(path string) *Ledger {
+	return &Ledger{path: path}
+}
+
+// DefaultPath returns the ledger file under the user config directory, or "" if it cannot
+// be determined
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "sysmetrics-mcp", "availability.json")
+}
+
+// Path returns the backing file
+func (l *Ledger) Path() string {
+	return l.path
+}
+
+// Boots returns the recorded boots, oldest first
+func (l *Ledger) Boots() ([]Boot, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.load()
+}
+
+// Heartbeat records that the boot which started at start is still up at now
+func (l *Ledger) Heartbeat(start, now time.Time) error {
+	return l.update(func(boots []Boot) []Boot {
+		return upsert(boots, Boot{Start: start, LastSeen: now})
+	})
+}
+
+// Merge adds boots known from elsewhere, such as the journal, extending the last-seen
+// time of boots already recorded
+func (l *Ledger) Merge(known []Boot) error {
+	return l.update(func(boots []Boot) []Boot {
+		for _, b := range known {
+			boots = upsert(boots, b)
+		}
+		return boots
+	})
+}
+
+// SetEndReason records how the boot before the one starting at next ended. It returns
+// false when the ledger has no such boot.
+func (l *Ledger) SetEndReason(next time.Time, reason string, unexpected bool) (bool, error) {
+	found := false
+	err := l.update(func(boots []Boot) []Boot {
+		for i := len(boots) - 1; i > 0; i-- {
+			if sameBoot(boots[i].Start, next) {
+				boots[i-1].EndReason, boots[i-1].Unexpected = reason, unexpected
+				found = true
+				break
+			}
+		}
+		return boots
+	})
+	return found, err
+}
+
+// upsert extends the matching boot or inserts b in start order
+func upsert(boots []Boot, b Boot) []Boot {
+	for i := range boots {
+		if sameBoot(boots[i].Start, b.Start) {
+			if b.LastSeen.After(boots[i].LastSeen) {
+				boots[i].LastSeen = b.LastSeen
+			}
+			if boots[i].EndReason == "" && b.EndReason != "" {
+				boots[i].EndReason, boots[i].Unexpected = b.EndReason, b.Unexpected
+			}
+			return boots
+		}
+	}
+	if b.LastSeen.Before(b.Start) {
+		b.LastSeen = b.Start
+	}
+	boots = append(boots, b)
+	sort.Slice(boots, func(i, j int) bool { return boots[i].Start.Before(boots[j].Start) })
+	return boots
+}
+
+// sameBoot reports whether two start times describe the same boot
+func sameBoot(a, b time.Time) bool {
+	d := a.Sub(b)
+	return d > -sameBootTolerance && d < sameBootTolerance
+}
+
+// update applies fn to the stored boots and writes the result atomically
+func (l *Ledger) update(fn func([]Boot) []Boot) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	boots, err := l.load()
+	if err != nil {
+		return err
+	}
+	boots = fn(boots)
+	if len(boots) > maxBoots {
+		boots = boots[len(boots)-maxBoots:]
+	}
+	data, err := json.MarshalIndent(boots, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return err
+	}
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, l.path)
+}
+
+// load reads the ledger; a missing file is an empty ledger
+func (l *Ledger) load() ([]Boot, error) {
+	if l.path == "" {
+		return nil, errors.New("no availability ledger location is available")
+	}
+	data, err := os.ReadFile(filepath.Clean(l.path))
+	if errors.Is(err, os.ErrNotExist) {
+		return []Boot{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var boots []Boot
+	if err := json.Unmarshal(data, &boots); err != nil {
+		return nil, fmt.Errorf("corrupt availability ledger %s: %w", l.path, err)
+	}
+	return boots, nil
+}
+
+// Window is availability over one period ending now. Only the part of the period covered
+// by the ledger is counted, so TrackedSeconds may be shorter than the period.
+type Window struct {
+	Days              int      `json:"days"`
+	TrackedSeconds    int64    `json:"tracked_seconds"`
+	UptimeSeconds     int64    `json:"uptime_seconds"`
+	DowntimeSeconds   int64    `json:"downtime_seconds"`
+	UptimePercent     *float64 `json:"uptime_percent"`
+	Reboots           int      `json:"reboots"`
+	UnexpectedReboots int      `json:"unexpected_reboots"`
+}
+
+// Availability computes uptime over the days before now. The last boot is treated as up
+// until now.
+func Availability(boots []Boot, now time.Time, days int) Window {
+	w := Window{Days: days}
+	if len(boots) == 0 {
+		return w
+	}
+	from := now.Add(-time.Duration(days) * 24 * time.Hour)
+	if boots[0].Start.After(from) {
+		from = boots[0].Start
+	}
+	if !now.After(from) {
+		return w
+	}
+
+	var up time.Duration
+	for i, b := range boots {
+		end := b.LastSeen
+		if i == len(boots)-1 {
+			end = now
+		}
+		up += overlap(b.Start, end, from, now)
+		// Each boot after the first marks a reboot; the end reason is stored on the boot before
+		if i > 0 && b.Start.After(from) && !b.Start.After(now) {
+			w.Reboots++
+			if boots[i-1].Unexpected {
+				w.UnexpectedReboots++
+			}
+		}
+	}
+	tracked := now.Sub(from)
+	if up > tracked {
+		up = tracked
+	}
+	w.TrackedSeconds = int64(tracked / time.Second)
+	w.UptimeSeconds = int64(up / time.Second)
+	w.DowntimeSeconds = w.TrackedSeconds - w.UptimeSeconds
+	pct := math.Round(float64(up)/float64(tracked)*100000) / 1000
+	w.UptimePercent = &pct
+	return w
+}
+
+// overlap returns how much of [start, end] falls within [from, to]
+func overlap(start, end, from, to time.Time) time.Duration {
+	if start.Before(from) {
+		start = from
+	}
+	if end.After(to) {
+		end = to
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// Reboot is one transition between boots
+type Reboot struct {
+	Time time.Time `json:"time"`
+	// PreviousLastSeen is the last time the previous boot was known to be up
+	PreviousLastSeen time.Time     `json:"previous_last_seen"`
+	Downtime         time.Duration `json:"-"`
+	EndReason        string        `json:"end_reason,omitempty"`
+	Unexpected       bool          `json:"unexpected"`
+}
+
+// Reboots lists reboots newest first, at most limit
+func Reboots(boots []Boot, limit int) []Reboot {
+	reboots := []Reboot{}
+	for i := len(boots) - 1; i > 0 && len(reboots) < limit; i-- {
+		prev := boots[i-1]
+		downtime := boots[i].Start.Sub(prev.LastSeen)
+		if downtime < 0 {
+			downtime = 0
+		}
+		reboots = append(reboots, Reboot{
+			Time:             boots[i].Start,
+			PreviousLastSeen: prev.LastSeen,
+			Downtime:         downtime,
+			EndReason:        prev.EndReason,
+			Unexpected:       prev.Unexpected,
+		})
+	}
+	return reboots
+}
@@ -0,0 +1,105 @@
+package availability
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLedger(t *testing.T) {
+	l := NewLedger(filepath.Join(t.TempDir(), "sub", "availability.json"))
+	if boots, err := l.Boots(); err != nil || len(boots) != 0 {
+		t.Fatalf("Boots() on a new ledger = %v, %v", boots, err)
+	}
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	second := base.Add(48 * time.Hour)
+	if err := l.Heartbeat(second, second.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	// A later heartbeat a few seconds off the recorded start is the same boot
+	if err := l.Heartbeat(second.Add(3*time.Second), second.Add(2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	// The journal knows an earlier boot
+	if err := l.Merge([]Boot{{Start: base, LastSeen: base.Add(47 * time.Hour)}}); err != nil {
+		t.Fatal(err)
+	}
+	found, err := l.SetEndReason(second, "unclean_shutdown", true)
+	if err != nil || !found {
+		t.Fatalf("SetEndReason() = %v, %v", found, err)
+	}
+	if found, _ := l.SetEndReason(base, "clean_reboot", false); found {
+		t.Error("Expected no boot before the first one")
+	}
+
+	boots, err := l.Boots()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(boots) != 2 {
+		t.Fatalf("Expected 2 boots, got %+v", boots)
+	}
+	if !boots[0].Start.Equal(base) || !boots[0].Unexpected || boots[0].EndReason != "unclean_shutdown" {
+		t.Errorf("Unexpected first boot: %+v", boots[0])
+	}
+	if !boots[1].LastSeen.Equal(second.Add(2 * time.Hour)) {
+		t.Errorf("Expected the second boot's last heartbeat to be kept, got %+v", boots[1])
+	}
+}
+
+func TestAvailability(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	boots := []Boot{
+		// Ends with one hour of downtime, 10 days ago
+		{Start: now.Add(-20 * 24 * time.Hour), LastSeen: now.Add(-10*24*time.Hour - time.Hour), Unexpected: true, EndReason: "unclean_shutdown"},
+		{Start: now.Add(-10 * 24 * time.Hour), LastSeen: now.Add(-2 * 24 * time.Hour), EndReason: "clean_reboot"},
+		{Start: now.Add(-2 * 24 * time.Hour), LastSeen: now.Add(-time.Hour)},
+	}
+
+	week := Availability(boots, now, 7)
+	if week.TrackedSeconds != 7*24*3600 || week.DowntimeSeconds != 0 || *week.UptimePercent != 100 {
+		t.Errorf("Unexpected 7-day window: %+v", week)
+	}
+	if week.Reboots != 1 || week.UnexpectedReboots != 0 {
+		t.Errorf("7-day reboots = %d (%d unexpected), want 1 (0)", week.Reboots, week.UnexpectedReboots)
+	}
+
+	// Only the 20 tracked days of the 30-day window count
+	month := Availability(boots, now, 30)
+	if month.TrackedSeconds != 20*24*3600 || month.DowntimeSeconds != 3600 {
+		t.Errorf("Unexpected 30-day window: %+v", month)
+	}
+	if month.Reboots != 2 || month.UnexpectedReboots != 1 {
+		t.Errorf("30-day reboots = %d (%d unexpected), want 2 (1)", month.Reboots, month.UnexpectedReboots)
+	}
+	if want := 99.792; *month.UptimePercent != want {
+		t.Errorf("30-day uptime = %v, want %v", *month.UptimePercent, want)
+	}
+
+	if w := Availability(nil, now, 7); w.UptimePercent != nil {
+		t.Errorf("Expected no percentage without boots, got %v", *w.UptimePercent)
+	}
+}
+
+func TestReboots(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	boots := []Boot{
+		{Start: now.Add(-48 * time.Hour), LastSeen: now.Add(-25 * time.Hour), EndReason: "kernel_panic", Unexpected: true},
+		{Start: now.Add(-24 * time.Hour), LastSeen: now.Add(-time.Hour)},
+		{Start: now, LastSeen: now},
+	}
+	reboots := Reboots(boots, 10)
+	if len(reboots) != 2 {
+		t.Fatalf("Expected 2 reboots, got %+v", reboots)
+	}
+	if !reboots[0].Time.Equal(now) || reboots[0].Downtime != time.Hour || reboots[0].Unexpected {
+		t.Errorf("Unexpected newest reboot: %+v", reboots[0])
+	}
+	if reboots[1].EndReason != "kernel_panic" || !reboots[1].Unexpected {
+		t.Errorf("Unexpected oldest reboot: %+v", reboots[1])
+	}
+	if got := Reboots(boots, 1); len(got) != 1 {
+		t.Errorf("Expected the limit to apply, got %d reboots", len(got))
+	}
+}
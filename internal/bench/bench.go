@@ -0,0 +1,229 @@
+// Package bench runs short CPU, memory, and disk micro-benchmarks and keeps named
+// baselines so later runs can be compared after a hardware change.
+package bench
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Benchmark sizes.
+const (
+	cpuBlockSize    = 64 << 10
+	syncWriteSize   = 4 << 10
+	syncWriteCount  = 32
+	diskChunkSize   = 1 << 20
+	bytesPerMB      = 1 << 20
+	defaultMemoryMB = 64
+)
+
+// CPUResult is SHA-256 hashing throughput on one core and on all cores
+type CPUResult struct {
+	Threads            int     `json:"threads"`
+	SingleThreadMBps   float64 `json:"single_thread_mb_per_sec"`
+	MultiThreadMBps    float64 `json:"multi_thread_mb_per_sec,omitempty"`
+	MultiThreadScaling float64 `json:"multi_thread_scaling,omitempty"`
+	Partial            bool    `json:"partial,omitempty"`
+}
+
+// MemoryResult is large-buffer copy bandwidth
+type MemoryResult struct {
+	BufferMB int     `json:"buffer_mb"`
+	CopyMBps float64 `json:"copy_mb_per_sec"`
+	Partial  bool    `json:"partial,omitempty"`
+}
+
+// DiskResult is sequential throughput and synchronous small-write latency of a directory's
+// filesystem. Reads come straight after the writes and are usually served from page cache.
+type DiskResult struct {
+	Dir                string  `json:"dir"`
+	FileMB             int     `json:"file_mb"`
+	WriteMBps          float64 `json:"write_mb_per_sec"`
+	ReadMBps           float64 `json:"read_mb_per_sec,omitempty"`
+	SyncWriteLatencyMS float64 `json:"sync_write_latency_ms,omitempty"`
+	Partial            bool    `json:"partial,omitempty"`
+}
+
+// Cancelling the context stops a benchmark early. The phases measured until then are
+// returned with Partial set, together with the cancellation cause as the error.
+
+// CPU hashes for d on a single goroutine and then for d on threads goroutines
+func CPU(ctx context.Context, d time.Duration, threads int) (CPUResult, error) {
+	if threads < 1 {
+		threads = runtime.NumCPU()
+	}
+	r := CPUResult{Threads: threads}
+	single, err := hashThroughput(ctx, d, 1)
+	r.SingleThreadMBps = round2(single)
+	if err != nil {
+		r.Partial = true
+		return r, err
+	}
+	multi, err := hashThroughput(ctx, d, threads)
+	r.MultiThreadMBps = round2(multi)
+	if single > 0 {
+		r.MultiThreadScaling = round2(multi / single)
+	}
+	if err != nil {
+		r.Partial = true
+		return r, err
+	}
+	return r, nil
+}
+
+// hashThroughput returns MB/s hashed by workers goroutines over d, or over the time until
+// ctx was cancelled together with the cause
+func hashThroughput(ctx context.Context, d time.Duration, workers int) (float64, error) {
+	block := make([]byte, cpuBlockSize)
+	if _, err := rand.Read(block); err != nil {
+		return 0, err
+	}
+	var total atomic.Int64
+	var wg sync.WaitGroup
+	deadline := time.Now().Add(d)
+	start := time.Now()
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h := sha256.New()
+			var n int64
+			for ctx.Err() == nil && time.Now().Before(deadline) {
+				for j := 0; j < 16; j++ {
+					h.Write(block)
+				}
+				n += 16 * cpuBlockSize
+			}
+			total.Add(n)
+		}()
+	}
+	wg.Wait()
+	rate := float64(total.Load()) / bytesPerMB / time.Since(start).Seconds()
+	if ctx.Err() != nil {
+		return rate, context.Cause(ctx)
+	}
+	return rate, nil
+}
+
+// Memory copies a bufferMB buffer back and forth for d
+func Memory(ctx context.Context, d time.Duration, bufferMB int) (MemoryResult, error) {
+	if bufferMB < 1 {
+		bufferMB = defaultMemoryMB
+	}
+	src := make([]byte, bufferMB*bytesPerMB)
+	dst := make([]byte, len(src))
+	for i := range src {
+		src[i] = byte(i)
+	}
+	var copied int64
+	deadline := time.Now().Add(d)
+	start := time.Now()
+	var err error
+	for time.Now().Before(deadline) {
+		if ctx.Err() != nil {
+			err = context.Cause(ctx)
+			break
+		}
+		copy(dst, src)
+		src, dst = dst, src
+		copied += int64(len(src))
+	}
+	return MemoryResult{
+		BufferMB: bufferMB,
+		CopyMBps: round2(float64(copied) / bytesPerMB / time.Since(start).Seconds()),
+		Partial:  err != nil,
+	}, err
+}
+
+// Disk writes a fileMB test file in dir with a final fsync, reads it back, times a series of
+// fsynced 4 KiB writes, and removes the file
+func Disk(ctx context.Context, dir string, fileMB int) (result DiskResult, err error) {
+	if fileMB < 1 {
+		return DiskResult{}, errors.New("file size must be at least 1 MB")
+	}
+	f, err := os.CreateTemp(dir, ".sysmetrics-bench-*")
+	if err != nil {
+		return DiskResult{}, err
+	}
+	path := f.Name()
+	defer func() {
+		if cerr := f.Close(); cerr != nil && !errors.Is(cerr, os.ErrClosed) && err == nil {
+			err = cerr
+		}
+		_ = os.Remove(path)
+	}()
+
+	result = DiskResult{Dir: filepath.Clean(dir), FileMB: fileMB}
+	chunk := make([]byte, diskChunkSize)
+	if _, err := rand.Read(chunk); err != nil {
+		return DiskResult{}, err
+	}
+
+	// aborted returns the phases measured so far
+	aborted := func() (DiskResult, error) {
+		result.Partial = true
+		return result, context.Cause(ctx)
+	}
+
+	start := time.Now()
+	for i := 0; i < fileMB; i++ {
+		if ctx.Err() != nil {
+			return aborted()
+		}
+		if _, err := f.Write(chunk); err != nil {
+			return DiskResult{}, fmt.Errorf("write: %w", err)
+		}
+	}
+	if err := f.Sync(); err != nil {
+		return DiskResult{}, fmt.Errorf("fsync: %w", err)
+	}
+	result.WriteMBps = round2(float64(fileMB) / time.Since(start).Seconds())
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return DiskResult{}, err
+	}
+	start = time.Now()
+	for {
+		if ctx.Err() != nil {
+			return aborted()
+		}
+		if _, err := f.Read(chunk); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return DiskResult{}, fmt.Errorf("read: %w", err)
+		}
+	}
+	result.ReadMBps = round2(float64(fileMB) / time.Since(start).Seconds())
+
+	small := chunk[:syncWriteSize]
+	start = time.Now()
+	for i := 0; i < syncWriteCount; i++ {
+		if ctx.Err() != nil {
+			return aborted()
+		}
+		if _, err := f.WriteAt(small, int64(i*syncWriteSize)); err != nil {
+			return DiskResult{}, fmt.Errorf("write: %w", err)
+		}
+		if err := f.Sync(); err != nil {
+			return DiskResult{}, fmt.Errorf("fsync: %w", err)
+		}
+	}
+	result.SyncWriteLatencyMS = round2(float64(time.Since(start).Microseconds()) / 1000 / syncWriteCount)
+	return result, nil
+}
+
+// round2 rounds to two decimals for compact output
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,141 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBenchmarks(t *testing.T) {
+	ctx := context.Background()
+
+	cpu, err := CPU(ctx, 50*time.Millisecond, 2)
+	if err != nil {
+		t.Fatalf("CPU() error = %v", err)
+	}
+	if cpu.SingleThreadMBps <= 0 || cpu.MultiThreadMBps <= 0 || cpu.Threads != 2 {
+		t.Errorf("Unexpected CPU result: %+v", cpu)
+	}
+
+	memory, err := Memory(ctx, 50*time.Millisecond, 4)
+	if err != nil {
+		t.Fatalf("Memory() error = %v", err)
+	}
+	if memory.CopyMBps <= 0 {
+		t.Errorf("Unexpected memory result: %+v", memory)
+	}
+
+	dir := t.TempDir()
+	disk, err := Disk(ctx, dir, 2)
+	if err != nil {
+		t.Fatalf("Disk() error = %v", err)
+	}
+	if disk.WriteMBps <= 0 || disk.ReadMBps <= 0 || disk.SyncWriteLatencyMS <= 0 {
+		t.Errorf("Unexpected disk result: %+v", disk)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Expected the test file to be removed, found %d entries", len(entries))
+	}
+}
+
+func TestBenchmarksCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := CPU(ctx, time.Second, 1); err == nil {
+		t.Error("Expected CPU() to fail on a cancelled context")
+	}
+	if _, err := Disk(ctx, t.TempDir(), 1); err == nil {
+		t.Error("Expected Disk() to fail on a cancelled context")
+	}
+
+	// An abort mid-run keeps what was measured and reports the cause
+	cause := errors.New("too hot")
+	ctx, cancelCause := context.WithCancelCause(context.Background())
+	time.AfterFunc(50*time.Millisecond, func() { cancelCause(cause) })
+	r, err := CPU(ctx, time.Second, 1)
+	if !errors.Is(err, cause) {
+		t.Fatalf("CPU() error = %v, want %v", err, cause)
+	}
+	if !r.Partial || r.SingleThreadMBps <= 0 || r.MultiThreadMBps != 0 {
+		t.Errorf("Expected a partial single-thread result, got %+v", r)
+	}
+	if m := (Report{CPU: &r}).Metrics(); len(m) != 0 {
+		t.Errorf("Expected partial results to be left out of comparisons, got %v", m)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	temp := func(v float64) *float64 { return &v }
+	base := Report{
+		CPU:          &CPUResult{SingleThreadMBps: 100, MultiThreadMBps: 400},
+		Disk:         &DiskResult{WriteMBps: 50, ReadMBps: 200, SyncWriteLatencyMS: 4},
+		SensorsAfter: Sensors{CPUTempC: temp(80)},
+	}
+	cur := Report{
+		CPU:          &CPUResult{SingleThreadMBps: 102, MultiThreadMBps: 300},
+		Disk:         &DiskResult{WriteMBps: 400, ReadMBps: 200, SyncWriteLatencyMS: 1},
+		SensorsAfter: Sensors{CPUTempC: temp(60)},
+	}
+
+	want := map[string]string{
+		"cpu_single_thread_mb_per_sec": "unchanged",
+		"cpu_multi_thread_mb_per_sec":  "worse",
+		"disk_write_mb_per_sec":        "better",
+		"disk_read_mb_per_sec":         "unchanged",
+		"disk_sync_write_latency_ms":   "better",
+		"cpu_temp_after_celsius":       "better",
+	}
+	changes := Compare(base, cur)
+	if len(changes) != len(want) {
+		t.Fatalf("Expected %d changes, got %+v", len(want), changes)
+	}
+	for _, c := range changes {
+		if c.Verdict != want[c.Metric] {
+			t.Errorf("%s: verdict = %q, want %q", c.Metric, c.Verdict, want[c.Metric])
+		}
+	}
+}
+
+func TestStore(t *testing.T) {
+	s := NewStore(filepath.Join(t.TempDir(), "sub", "baselines.json"))
+
+	if names, err := s.Names(); err != nil || len(names) != 0 {
+		t.Fatalf("Names() on a new store = %v, %v", names, err)
+	}
+	if err := s.Save(Report{}); err == nil {
+		t.Error("Expected Save() without a name to fail")
+	}
+
+	now := time.Now().UTC()
+	for i, name := range []string{"stock", "new-ssd"} {
+		r := Report{Name: name, Time: now.Add(time.Duration(i) * time.Minute), Memory: &MemoryResult{CopyMBps: float64(i + 1)}}
+		if err := s.Save(r); err != nil {
+			t.Fatalf("Save(%s) error = %v", name, err)
+		}
+	}
+
+	names, err := s.Names()
+	if err != nil || len(names) != 2 || names[0] != "stock" {
+		t.Errorf("Names() = %v, %v", names, err)
+	}
+	r, ok, err := s.Get("new-ssd")
+	if err != nil || !ok || r.Memory.CopyMBps != 2 {
+		t.Errorf("Get(new-ssd) = %+v, %v, %v", r, ok, err)
+	}
+	if _, ok, _ := s.Get("missing"); ok {
+		t.Error("Expected Get(missing) to report not found")
+	}
+
+	// Import adds new names only
+	added, err := s.Import([]Report{{Name: "stock", Time: now, Memory: &MemoryResult{CopyMBps: 9}}, {Name: "old-card", Time: now.Add(-time.Hour)}})
+	if err != nil || added != 1 {
+		t.Fatalf("Import() = %d, %v", added, err)
+	}
+	all, err := s.All()
+	if err != nil || len(all) != 3 || all[0].Name != "old-card" || all[1].Memory.CopyMBps != 1 {
+		t.Errorf("All() = %+v, %v", all, err)
+	}
+}
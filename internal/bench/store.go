@@ -0,0 +1,255 @@
+package bench
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Sensors is a thermal snapshot taken around a run; cooling changes show up here
+type Sensors struct {
+	CPUTempC   *float64 `json:"cpu_temp_celsius,omitempty"`
+	CPUFreqMHz float64  `json:"cpu_freq_mhz,omitempty"`
+	Throttled  *bool    `json:"throttled,omitempty"`
+}
+
+// Report is one benchmark run; sections that were skipped are nil
+type Report struct {
+	Name          string        `json:"name,omitempty"`
+	Time          time.Time     `json:"time"`
+	Hostname      string        `json:"hostname,omitempty"`
+	CPU           *CPUResult    `json:"cpu,omitempty"`
+	Memory        *MemoryResult `json:"memory,omitempty"`
+	Disk          *DiskResult   `json:"disk,omitempty"`
+	SensorsBefore Sensors       `json:"sensors_before"`
+	SensorsAfter  Sensors       `json:"sensors_after"`
+}
+
+// lowerIsBetter marks metrics where a decrease is an improvement
+var lowerIsBetter = map[string]bool{
+	"disk_sync_write_latency_ms": true,
+	"cpu_temp_after_celsius":     true,
+	"cpu_temp_rise_celsius":      true,
+}
+
+// Metrics flattens a report into comparable values keyed by metric name. Partial results
+// from an aborted run are left out.
+func (r Report) Metrics() map[string]float64 {
+	m := map[string]float64{}
+	if r.CPU != nil && !r.CPU.Partial {
+		m["cpu_single_thread_mb_per_sec"] = r.CPU.SingleThreadMBps
+		m["cpu_multi_thread_mb_per_sec"] = r.CPU.MultiThreadMBps
+	}
+	if r.Memory != nil && !r.Memory.Partial {
+		m["memory_copy_mb_per_sec"] = r.Memory.CopyMBps
+	}
+	if r.Disk != nil && !r.Disk.Partial {
+		m["disk_write_mb_per_sec"] = r.Disk.WriteMBps
+		m["disk_read_mb_per_sec"] = r.Disk.ReadMBps
+		m["disk_sync_write_latency_ms"] = r.Disk.SyncWriteLatencyMS
+	}
+	if r.SensorsAfter.CPUTempC != nil {
+		m["cpu_temp_after_celsius"] = *r.SensorsAfter.CPUTempC
+		if r.SensorsBefore.CPUTempC != nil {
+			m["cpu_temp_rise_celsius"] = round2(*r.SensorsAfter.CPUTempC - *r.SensorsBefore.CPUTempC)
+		}
+	}
+	return m
+}
+
+// Change is the difference in one metric between a baseline and a later run
+type Change struct {
+	Metric        string  `json:"metric"`
+	Baseline      float64 `json:"baseline"`
+	Current       float64 `json:"current"`
+	ChangePercent float64 `json:"change_percent"`
+	Verdict       string  `json:"verdict"`
+}
+
+// noiseThresholdPercent is the change below which a difference is reported as unchanged;
+// short runs on a busy host easily vary by a few percent
+const noiseThresholdPercent = 5
+
+// Compare reports per-metric changes from base to cur for metrics present in both
+func Compare(base, cur Report) []Change {
+	baseMetrics, curMetrics := base.Metrics(), cur.Metrics()
+	changes := []Change{}
+	for name, b := range baseMetrics {
+		c, ok := curMetrics[name]
+		if !ok {
+			continue
+		}
+		ch := Change{Metric: name, Baseline: b, Current: c, Verdict: "unchanged"}
+		if b != 0 {
+			ch.ChangePercent = round2((c - b) / b * 100)
+		}
+		// Temperatures are compared in degrees, not percent
+		delta := ch.ChangePercent
+		threshold := float64(noiseThresholdPercent)
+		if name == "cpu_temp_after_celsius" || name == "cpu_temp_rise_celsius" {
+			delta, threshold = c-b, 2
+		}
+		improved := delta > 0
+		if lowerIsBetter[name] {
+			improved = delta < 0
+		}
+		switch {
+		case delta > -threshold && delta < threshold:
+		case improved:
+			ch.Verdict = "better"
+		default:
+			ch.Verdict = "worse"
+		}
+		changes = append(changes, ch)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Metric < changes[j].Metric })
+	return changes
+}
+
+// Store keeps named baselines in a JSON file
+type Store struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewStore returns a Store backed by path; the file is created on first Save
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// DefaultStorePath returns the baseline file under the user config directory, or "" if it
+// cannot be determined
+func DefaultStorePath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "sysmetrics-mcp", "baselines.json")
+}
+
+// Path returns the backing file
+func (s *Store) Path() string {
+	return s.path
+}
+
+// load reads all baselines; a missing file is an empty store
+func (s *Store) load() (map[string]Report, error) {
+	baselines := map[string]Report{}
+	if s.path == "" {
+		return nil, errors.New("no baseline file location is available")
+	}
+	data, err := os.ReadFile(filepath.Clean(s.path))
+	if errors.Is(err, os.ErrNotExist) {
+		return baselines, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &baselines); err != nil {
+		return nil, fmt.Errorf("corrupt baseline file %s: %w", s.path, err)
+	}
+	return baselines, nil
+}
+
+// Get returns the named baseline
+func (s *Store) Get(name string) (Report, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	baselines, err := s.load()
+	if err != nil {
+		return Report{}, false, err
+	}
+	r, ok := baselines[name]
+	return r, ok, nil
+}
+
+// Names lists stored baselines, oldest first
+func (s *Store) Names() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	baselines, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(baselines))
+	for name := range baselines {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return baselines[names[i]].Time.Before(baselines[names[j]].Time) })
+	return names, nil
+}
+
+// All returns every stored baseline, oldest first
+func (s *Store) All() ([]Report, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	baselines, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	reports := make([]Report, 0, len(baselines))
+	for _, r := range baselines {
+		reports = append(reports, r)
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Time.Before(reports[j].Time) })
+	return reports, nil
+}
+
+// Save stores r under r.Name, replacing any baseline with that name
+func (s *Store) Save(r Report) error {
+	if r.Name == "" {
+		return errors.New("baseline name is required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	baselines, err := s.load()
+	if err != nil {
+		return err
+	}
+	baselines[r.Name] = r
+	return s.write(baselines)
+}
+
+// Import adds the named reports whose names are not stored yet and returns how many were
+// added; existing baselines are kept
+func (s *Store) Import(reports []Report) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	baselines, err := s.load()
+	if err != nil {
+		return 0, err
+	}
+	added := 0
+	for _, r := range reports {
+		if _, ok := baselines[r.Name]; ok || r.Name == "" {
+			continue
+		}
+		baselines[r.Name] = r
+		added++
+	}
+	if added == 0 {
+		return 0, nil
+	}
+	return added, s.write(baselines)
+}
+
+// write replaces the baseline file atomically
+func (s *Store) write(baselines map[string]Report) error {
+	data, err := json.MarshalIndent(baselines, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
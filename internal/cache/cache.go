@@ -0,0 +1,122 @@
+// Package cache keeps recent values for a short time so bursts of identical requests
+// share one computation.
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrAbandoned is returned to callers waiting on a compute function that panicked
+var ErrAbandoned = errors.New("cache: shared computation did not complete")
+
+// Cache maps keys to values that expire after a per-entry TTL. Concurrent Do calls for
+// the same key run the compute function once and share its result.
+type Cache[V any] struct {
+	mu       sync.Mutex
+	entries  map[string]entry[V]
+	inflight map[string]*call[V]
+	now      func() time.Time
+}
+
+// entry is a stored value and when it stops being served
+type entry[V any] struct {
+	value   V
+	expires time.Time
+}
+
+// call is a computation other callers of the same key wait on
+type call[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// New returns an empty cache
+func New[V any]() *Cache[V] {
+	return &Cache[V]{
+		entries:  map[string]entry[V]{},
+		inflight: map[string]*call[V]{},
+		now:      time.Now,
+	}
+}
+
+// Get returns the value stored under key if it has not expired
+func (c *Cache[V]) Get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || !c.now().Before(e.expires) {
+		var zero V
+		return zero, false
+	}
+	return e.value, true
+}
+
+// Set stores value under key for ttl, and drops expired entries so the cache stays as
+// small as the set of recently used keys
+func (c *Cache[V]) Set(key string, value V, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(key, value, ttl)
+}
+
+func (c *Cache[V]) set(key string, value V, ttl time.Duration) {
+	now := c.now()
+	for k, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = entry[V]{value: value, expires: now.Add(ttl)}
+}
+
+// Len returns the number of entries, including expired ones not yet dropped
+func (c *Cache[V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// Do returns the value stored under key, or runs compute and stores its value for ttl.
+// Callers arriving while compute runs wait for it, or until their ctx is done, and get
+// the same value and error. shared reports whether the value came from the cache or from
+// another caller's compute. A value is not stored when compute returns an error or store
+// is false, so failures are retried by the next call.
+func (c *Cache[V]) Do(ctx context.Context, key string, ttl time.Duration, compute func() (value V, store bool, err error)) (value V, shared bool, err error) {
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok && c.now().Before(e.expires) {
+		c.mu.Unlock()
+		return e.value, true, nil
+	}
+	if cl, ok := c.inflight[key]; ok {
+		c.mu.Unlock()
+		select {
+		case <-cl.done:
+			return cl.value, true, cl.err
+		case <-ctx.Done():
+			var zero V
+			return zero, true, ctx.Err()
+		}
+	}
+	cl := &call[V]{done: make(chan struct{})}
+	c.inflight[key] = cl
+	c.mu.Unlock()
+
+	// Waiters are released even if compute panics
+	cl.err = ErrAbandoned
+	store := false
+	defer func() {
+		c.mu.Lock()
+		delete(c.inflight, key)
+		if store && cl.err == nil {
+			c.set(key, cl.value, ttl)
+		}
+		c.mu.Unlock()
+		close(cl.done)
+	}()
+	cl.value, store, cl.err = compute()
+	return cl.value, false, cl.err
+}
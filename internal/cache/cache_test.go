@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCacheExpiry(t *testing.T) {
+	now := time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)
+	c := New[int]()
+	c.now = func() time.Time { return now }
+
+	c.Set("a", 1, 2*time.Second)
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("Get = %v, %v; want 1, true", v, ok)
+	}
+	now = now.Add(2 * time.Second)
+	if _, ok := c.Get("a"); ok {
+		t.Error("Expected the entry to expire after its TTL")
+	}
+	// Storing another key drops the expired one
+	c.Set("b", 2, time.Second)
+	if c.Len() != 1 {
+		t.Errorf("Len = %d, want 1", c.Len())
+	}
+}
+
+func TestCacheDo(t *testing.T) {
+	c := New[string]()
+	ctx := context.Background()
+	runs := 0
+	compute := func(value string, store bool, err error) func() (string, bool, error) {
+		return func() (string, bool, error) {
+			runs++
+			return value, store, err
+		}
+	}
+
+	if v, shared, err := c.Do(ctx, "k", time.Minute, compute("x", true, nil)); v != "x" || shared || err != nil {
+		t.Fatalf("Do = %q, %v, %v", v, shared, err)
+	}
+	if v, shared, _ := c.Do(ctx, "k", time.Minute, compute("y", true, nil)); v != "x" || !shared {
+		t.Errorf("Expected the cached value, got %q, %v", v, shared)
+	}
+
+	// Errors and values the caller declines to store are computed again
+	failed := errors.New("failed")
+	for i := 0; i < 2; i++ {
+		if _, _, err := c.Do(ctx, "err", time.Minute, compute("", true, failed)); !errors.Is(err, failed) {
+			t.Errorf("Expected the compute error, got %v", err)
+		}
+		_, _, _ = c.Do(ctx, "nostore", time.Minute, compute("z", false, nil))
+	}
+	if runs != 5 {
+		t.Errorf("compute ran %d times, want 5", runs)
+	}
+}
+
+func TestCacheDoCoalesces(t *testing.T) {
+	c := New[int]()
+	release := make(chan struct{})
+	var runs atomic.Int32
+	compute := func() (int, bool, error) {
+		runs.Add(1)
+		<-release
+		return 42, true, nil
+	}
+
+	var wg sync.WaitGroup
+	values := make([]int, 5)
+	for i := range values {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			values[i], _, _ = c.Do(context.Background(), "k", time.Minute, compute)
+		}()
+	}
+	// Let the callers queue up behind the first compute
+	for {
+		c.mu.Lock()
+		waiting := len(c.inflight)
+		c.mu.Unlock()
+		if waiting == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if runs.Load() != 1 {
+		t.Errorf("compute ran %d times, want 1", runs.Load())
+	}
+	for _, v := range values {
+		if v != 42 {
+			t.Errorf("Expected every caller to get 42, got %v", values)
+			break
+		}
+	}
+}
+
+func TestCacheDoWaiterCancelled(t *testing.T) {
+	c := New[int]()
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go func() {
+		_, _, _ = c.Do(context.Background(), "k", time.Minute, func() (int, bool, error) {
+			close(started)
+			<-release
+			return 1, true, nil
+		})
+	}()
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := c.Do(ctx, "k", time.Minute, func() (int, bool, error) { return 2, true, nil }); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancelled waiter to return its context error, got %v", err)
+	}
+	close(release)
+}
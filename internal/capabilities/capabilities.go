@@ -0,0 +1,124 @@
+// Package capabilities probes the host for optional tooling that some MCP tools depend on.
+package capabilities
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+
+	"sysmetrics-mcp/internal/cgroups"
+)
+
+// Capabilities records which optional system features were detected at startup
+type Capabilities struct {
+	Systemd      bool `json:"systemd"`
+	WindowsSCM   bool `json:"windows_scm"`
+	Launchd      bool `json:"launchd"`
+	OpenRC       bool `json:"openrc"`
+	Runit        bool `json:"runit"`
+	InitScripts  bool `json:"init_scripts"`
+	Powermetrics bool `json:"powermetrics"`
+	Vcgencmd     bool `json:"vcgencmd"`
+	RpiEeprom    bool `json:"rpi_eeprom_update"`
+	RpiBoot      bool `json:"rpi_bootloader"`
+	FwPrintenv   bool `json:"fw_printenv"`
+	DockerCLI    bool `json:"docker_cli"`
+	DockerSocket bool `json:"docker_socket"`
+	Podman       bool `json:"podman"`
+	Nerdctl      bool `json:"nerdctl"`
+	Crictl       bool `json:"crictl"`
+	K3s          bool `json:"k3s"`
+	Libvirt      bool `json:"libvirt"`
+	Proxmox      bool `json:"proxmox"`
+	NvidiaSMI    bool `json:"nvidia_smi"`
+	Smartctl     bool `json:"smartctl"`
+	MDRaid       bool `json:"md_raid"`
+	Mdadm        bool `json:"mdadm"`
+	Zpool        bool `json:"zpool"`
+	Btrfs        bool `json:"btrfs"`
+	LVM          bool `json:"lvm"`
+	DeviceMapper bool `json:"device_mapper"`
+	NFSServer    bool `json:"nfs_server"`
+	Smbstatus    bool `json:"smbstatus"`
+	SS           bool `json:"ss"`
+	ALSA         bool `json:"alsa"`
+	Pactl        bool `json:"pactl"`
+	DRM          bool `json:"drm"`
+	USB          bool `json:"usb"`
+	PCI          bool `json:"pci"`
+	Iw           bool `json:"iw"`
+	Wireless     bool `json:"wireless"`
+	Pstore       bool `json:"pstore"`
+	Apt          bool `json:"apt"`
+	Dnf          bool `json:"dnf"`
+	Pacman       bool `json:"pacman"`
+	CgroupV2     bool `json:"cgroup_v2"`
+	CPUFreq      bool `json:"cpufreq"`
+	SSHD         bool `json:"sshd"`
+	Fail2ban     bool `json:"fail2ban"`
+}
+
+// Detect probes the host for optional capabilities
+func Detect() Capabilities {
+	return Capabilities{
+		// Same check as sd_booted(3): the directory only exists when systemd is PID 1
+		Systemd:      pathExists("/run/systemd/system") && commandExists("systemctl"),
+		WindowsSCM:   runtime.GOOS == "windows",
+		Launchd:      runtime.GOOS == "darwin" && commandExists("launchctl"),
+		OpenRC:       runtime.GOOS == "linux" && pathExists("/run/openrc") && commandExists("rc-service"),
+		Runit:        runtime.GOOS == "linux" && commandExists("sv") && (pathExists("/var/service") || pathExists("/etc/service")),
+		InitScripts:  runtime.GOOS == "linux" && pathExists("/etc/init.d"),
+		Powermetrics: runtime.GOOS == "darwin" && commandExists("powermetrics"),
+		Vcgencmd:     commandExists("vcgencmd"),
+		RpiEeprom:    runtime.GOOS == "linux" && commandExists("rpi-eeprom-update"),
+		RpiBoot:      pathExists("/proc/device-tree/chosen/bootloader"),
+		FwPrintenv:   runtime.GOOS == "linux" && commandExists("fw_printenv"),
+		DockerCLI:    commandExists("docker"),
+		DockerSocket: pathExists("/var/run/docker.sock"),
+		Podman:       commandExists("podman"),
+		Nerdctl:      commandExists("nerdctl"),
+		Crictl:       commandExists("crictl"),
+		K3s:          commandExists("k3s"),
+		Libvirt:      runtime.GOOS == "linux" && commandExists("virsh") && (pathExists("/run/libvirt/libvirt-sock") || pathExists("/run/libvirt/virtqemud-sock")),
+		// /etc/pve is the cluster filesystem every Proxmox VE node mounts
+		Proxmox:      runtime.GOOS == "linux" && pathExists("/etc/pve") && commandExists("pvesh"),
+		NvidiaSMI:    commandExists("nvidia-smi"),
+		Smartctl:     commandExists("smartctl"),
+		MDRaid:       pathExists("/proc/mdstat"),
+		Mdadm:        runtime.GOOS == "linux" && commandExists("mdadm"),
+		Zpool:        commandExists("zpool"),
+		Btrfs:        runtime.GOOS == "linux" && commandExists("btrfs"),
+		LVM:          runtime.GOOS == "linux" && commandExists("lvm"),
+		DeviceMapper: pathExists("/sys/module/dm_mod"),
+		NFSServer:    pathExists("/proc/net/rpc/nfsd"),
+		Smbstatus:    commandExists("smbstatus"),
+		SS:           runtime.GOOS == "linux" && commandExists("ss"),
+		ALSA:         pathExists("/proc/asound/cards"),
+		Pactl:        commandExists("pactl"),
+		DRM:          pathExists("/sys/class/drm"),
+		USB:          pathExists("/sys/bus/usb/devices"),
+		PCI:          pathExists("/sys/bus/pci/devices"),
+		Iw:           runtime.GOOS == "linux" && commandExists("iw"),
+		Wireless:     pathExists("/proc/net/wireless"),
+		Pstore:       pathExists("/sys/fs/pstore") || pathExists("/var/lib/systemd/pstore"),
+		Apt:          runtime.GOOS == "linux" && commandExists("apt"),
+		Dnf:          runtime.GOOS == "linux" && commandExists("dnf"),
+		Pacman:       runtime.GOOS == "linux" && commandExists("pacman"),
+		CgroupV2:     cgroups.IsV2(),
+		CPUFreq:      pathExists("/sys/devices/system/cpu/cpu0/cpufreq"),
+		SSHD:         runtime.GOOS == "linux" && pathExists("/etc/ssh/sshd_config"),
+		Fail2ban:     runtime.GOOS == "linux" && commandExists("fail2ban-client"),
+	}
+}
+
+// commandExists reports whether an executable is available in PATH
+func commandExists(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+// pathExists reports whether a filesystem path exists
+func pathExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
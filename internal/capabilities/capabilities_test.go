@@ -0,0 +1,31 @@
+package capabilities
+
+import "testing"
+
+func TestCommandExists(t *testing.T) {
+	if !commandExists("sh") {
+		t.Error("Expected sh to be found in PATH")
+	}
+	if commandExists("definitely-not-a-real-command-12345") {
+		t.Error("Expected nonexistent command to not be found")
+	}
+}
+
+func TestPathExists(t *testing.T) {
+	if !pathExists(t.TempDir()) {
+		t.Error("Expected temp dir to exist")
+	}
+	if pathExists("/nonexistent/path/12345") {
+		t.Error("Expected nonexistent path to not exist")
+	}
+}
+
+func TestCheckReadFile(t *testing.T) {
+	check := checkReadFile("test", "/nonexistent/path/12345", "guidance")
+	if check.Accessible {
+		t.Error("Expected nonexistent file to be inaccessible")
+	}
+	if check.Guidance != "" {
+		t.Errorf("Expected no guidance for a missing file, got %q", check.Guidance)
+	}
+}
@@ -0,0 +1,215 @@
+package capabilities
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// PermissionCheck describes whether a collector can access a privileged resource
+type PermissionCheck struct {
+	Collector  string `json:"collector"`
+	Resource   string `json:"resource"`
+	Accessible bool   `json:"accessible"`
+	Detail     string `json:"detail,omitempty"`
+	Guidance   string `json:"guidance,omitempty"`
+}
+
+// IsRoot reports whether the server is running with an effective UID of 0
+func IsRoot() bool {
+	return os.Geteuid() == 0
+}
+
+// CheckPermissions probes the resources used by collectors that commonly need elevated privileges.
+// Checks are skipped for resources that do not exist on this host.
+func CheckPermissions(caps Capabilities) []PermissionCheck {
+	root := IsRoot()
+	var checks []PermissionCheck
+
+	// Other users' /proc/<pid>/fd entries: FD usage and connection PID resolution
+	checks = append(checks, checkReadDir("fd_usage", "/proc/1/fd",
+		"run as root or grant CAP_DAC_READ_SEARCH and CAP_SYS_PTRACE: sudo setcap cap_dac_read_search,cap_sys_ptrace+ep $(which sysmetrics-mcp)"))
+
+	// Kernel ring buffer restrictions
+	if data, err := os.ReadFile(filepath.Clean("/proc/sys/kernel/dmesg_restrict")); err == nil {
+		restricted := strings.TrimSpace(string(data)) == "1"
+		check := PermissionCheck{
+			Collector:  "kernel_log",
+			Resource:   "/proc/sys/kernel/dmesg_restrict",
+			Accessible: !restricted || root,
+		}
+		if !check.Accessible {
+			check.Detail = "dmesg_restrict is enabled"
+			check.Guidance = "run as root, grant CAP_SYSLOG, or set kernel.dmesg_restrict=0"
+		}
+		checks = append(checks, check)
+	}
+
+	// DMI tables used by dmidecode
+	if pathExists("/sys/firmware/dmi/tables/DMI") {
+		checks = append(checks, checkReadFile("dmi", "/sys/firmware/dmi/tables/DMI",
+			"run as root or add dmidecode to --sudo-allowlist"))
+	}
+
+	// Docker daemon socket
+	if caps.DockerSocket {
+		checks = append(checks, checkUnixSocket("docker", "/var/run/docker.sock",
+			"add the server user to the docker group: sudo usermod -aG docker $USER"))
+	}
+
+	// libvirt daemon socket used by virsh
+	if caps.Libvirt {
+		socket := "/run/libvirt/libvirt-sock"
+		if !pathExists(socket) {
+			socket = "/run/libvirt/virtqemud-sock"
+		}
+		checks = append(checks, checkUnixSocket("libvirt", socket,
+			"add the server user to the libvirt group: sudo usermod -aG libvirt $USER"))
+	}
+
+	// Raspberry Pi VideoCore interface used by vcgencmd
+	if caps.Vcgencmd && pathExists("/dev/vchiq") {
+		checks = append(checks, checkOpenRW("gpu", "/dev/vchiq",
+			"add the server user to the video group: sudo usermod -aG video $USER"))
+	}
+
+	// Active display modes are only exposed through debugfs
+	if caps.DRM && pathExists("/sys/kernel/debug") {
+		checks = append(checks, checkReadDir("display", "/sys/kernel/debug/dri",
+			"run as root to report the current resolution and refresh rate of each display"))
+	}
+
+	// Kernel crash records saved by pstore are readable by root only
+	if caps.Pstore && pathExists("/sys/fs/pstore") {
+		checks = append(checks, checkReadDir("crash_logs", "/sys/fs/pstore",
+			"run as root to read kernel crash records saved by pstore"))
+	}
+
+	// SMART data requires raw device access
+	if caps.Smartctl {
+		check := PermissionCheck{
+			Collector:  "smart",
+			Resource:   "smartctl",
+			Accessible: root,
+		}
+		if !root {
+			check.Detail = "smartctl requires raw block device access"
+			check.Guidance = "run as root or add smartctl to --sudo-allowlist"
+		}
+		checks = append(checks, check)
+	}
+
+	// mdadm --detail opens the array block devices
+	if caps.Mdadm && caps.MDRaid {
+		check := PermissionCheck{
+			Collector:  "raid_detail",
+			Resource:   "mdadm",
+			Accessible: root,
+		}
+		if !root {
+			check.Detail = "mdadm --detail requires read access to the /dev/md* block devices"
+			check.Guidance = "run as root or add mdadm to --sudo-allowlist"
+		}
+		checks = append(checks, check)
+	}
+
+	// btrfs device stats and scrub status use ioctls that need CAP_SYS_ADMIN
+	if caps.Btrfs {
+		check := PermissionCheck{
+			Collector:  "btrfs",
+			Resource:   "btrfs",
+			Accessible: root,
+		}
+		if !root {
+			check.Detail = "btrfs device stats and scrub status require CAP_SYS_ADMIN"
+			check.Guidance = "run as root or add btrfs to --sudo-allowlist"
+		}
+		checks = append(checks, check)
+	}
+
+	// lvm scans every block device and takes locks under /run/lock/lvm
+	if caps.LVM {
+		check := PermissionCheck{
+			Collector:  "lvm",
+			Resource:   "lvm",
+			Accessible: root,
+		}
+		if !root {
+			check.Detail = "lvm reporting commands require read access to the block devices"
+			check.Guidance = "run as root or add lvm to --sudo-allowlist"
+		}
+		checks = append(checks, check)
+	}
+
+	// Samba keeps its session and lock databases readable by root only
+	if caps.Smbstatus {
+		check := PermissionCheck{
+			Collector:  "samba",
+			Resource:   "smbstatus",
+			Accessible: root,
+		}
+		if !root {
+			check.Detail = "smbstatus requires root to read the Samba session and lock databases"
+			check.Guidance = "run as root or add smbstatus to --sudo-allowlist"
+		}
+		checks = append(checks, check)
+	}
+
+	return checks
+}
+
+// checkReadDir verifies that a directory can be listed
+func checkReadDir(collector, path, guidance string) PermissionCheck {
+	check := PermissionCheck{Collector: collector, Resource: path}
+	f, err := os.Open(filepath.Clean(path))
+	if err == nil {
+		_, err = f.Readdirnames(1)
+		_ = f.Close()
+	}
+	return finishCheck(check, err, guidance)
+}
+
+// checkReadFile verifies that a file can be opened for reading
+func checkReadFile(collector, path, guidance string) PermissionCheck {
+	check := PermissionCheck{Collector: collector, Resource: path}
+	f, err := os.Open(filepath.Clean(path))
+	if err == nil {
+		_ = f.Close()
+	}
+	return finishCheck(check, err, guidance)
+}
+
+// checkOpenRW verifies that a device node can be opened for reading and writing
+func checkOpenRW(collector, path, guidance string) PermissionCheck {
+	check := PermissionCheck{Collector: collector, Resource: path}
+	f, err := os.OpenFile(filepath.Clean(path), os.O_RDWR, 0)
+	if err == nil {
+		_ = f.Close()
+	}
+	return finishCheck(check, err, guidance)
+}
+
+// checkUnixSocket verifies that a unix socket accepts connections
+func checkUnixSocket(collector, path, guidance string) PermissionCheck {
+	check := PermissionCheck{Collector: collector, Resource: path}
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err == nil {
+		_ = conn.Close()
+	}
+	return finishCheck(check, err, guidance)
+}
+
+// finishCheck fills in the accessibility result and guidance for a check
+func finishCheck(check PermissionCheck, err error, guidance string) PermissionCheck {
+	if err == nil {
+		check.Accessible = true
+		return check
+	}
+	check.Detail = err.Error()
+	if os.IsPermission(err) || strings.Contains(err.Error(), "permission denied") {
+		check.Guidance = guidance
+	}
+	return check
+}
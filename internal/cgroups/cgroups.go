@@ -0,0 +1,177 @@
+// Package cgroups reads resource usage from the unified cgroups v2 hierarchy.
+package cgroups
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Root is the mount point of the unified cgroup hierarchy
+const Root = "/sys/fs/cgroup"
+
+// IOStat holds per-device counters from io.stat
+type IOStat struct {
+	Device     string `json:"device"`
+	ReadBytes  uint64 `json:"read_bytes"`
+	WriteBytes uint64 `json:"write_bytes"`
+	ReadIOs    uint64 `json:"read_ios"`
+	WriteIOs   uint64 `json:"write_ios"`
+}
+
+// Stats holds the resource usage of a single cgroup
+type Stats struct {
+	CPUUsageUsec     uint64            `json:"cpu_usage_usec"`
+	CPUUserUsec      uint64            `json:"cpu_user_usec"`
+	CPUSystemUsec    uint64            `json:"cpu_system_usec"`
+	CPUThrottledUsec uint64            `json:"cpu_throttled_usec"`
+	CPUNrThrottled   uint64            `json:"cpu_nr_throttled"`
+	MemoryCurrent    uint64            `json:"memory_current_bytes"`
+	MemoryMax        uint64            `json:"memory_max_bytes,omitempty"`
+	MemoryStat       map[string]uint64 `json:"memory_stat,omitempty"`
+	IO               []IOStat          `json:"io"`
+}
+
+// memoryStatKeys are the memory.stat entries worth reporting; the full file has ~40 keys
+var memoryStatKeys = []string{"anon", "file", "kernel", "shmem", "sock", "file_dirty", "file_writeback", "pgfault", "pgmajfault", "oom_kill"}
+
+// IsV2 reports whether the host uses the unified cgroups v2 hierarchy
+func IsV2() bool {
+	_, err := os.Stat(filepath.Join(Root, "cgroup.controllers"))
+	return err == nil
+}
+
+// ReadStats reads cpu.stat, memory.current, memory.max, memory.stat, and io.stat from a cgroup directory.
+// Only cpu.stat and memory.current are required; the other files depend on enabled controllers.
+func ReadStats(dir string) (Stats, error) {
+	var stats Stats
+
+	cpuData, err := os.ReadFile(filepath.Join(filepath.Clean(dir), "cpu.stat"))
+	if err != nil {
+		return stats, fmt.Errorf("failed to read cpu.stat in %s: %w", dir, err)
+	}
+	cpuStat := parseFlatKeyed(string(cpuData))
+	stats.CPUUsageUsec = cpuStat["usage_usec"]
+	stats.CPUUserUsec = cpuStat["user_usec"]
+	stats.CPUSystemUsec = cpuStat["system_usec"]
+	stats.CPUThrottledUsec = cpuStat["throttled_usec"]
+	stats.CPUNrThrottled = cpuStat["nr_throttled"]
+
+	memCurrent, err := readUint(filepath.Join(dir, "memory.current"))
+	if err != nil {
+		return stats, fmt.Errorf("failed to read memory.current in %s: %w", dir, err)
+	}
+	stats.MemoryCurrent = memCurrent
+
+	// memory.max is "max" when unlimited, which leaves MemoryMax at zero
+	if memMax, err := readUint(filepath.Join(dir, "memory.max")); err == nil {
+		stats.MemoryMax = memMax
+	}
+
+	if data, err := os.ReadFile(filepath.Join(filepath.Clean(dir), "memory.stat")); err == nil {
+		all := parseFlatKeyed(string(data))
+		stats.MemoryStat = make(map[string]uint64)
+		for _, k := range memoryStatKeys {
+			if v, ok := all[k]; ok {
+				stats.MemoryStat[k] = v
+			}
+		}
+	}
+
+	stats.IO = []IOStat{}
+	if data, err := os.ReadFile(filepath.Join(filepath.Clean(dir), "io.stat")); err == nil {
+		stats.IO = parseIOStat(string(data))
+	}
+
+	return stats, nil
+}
+
+// FindContainerDir locates the cgroup directory of a container by its full ID, trying the
+// layouts used by Docker, Podman (rootful and rootless), and containerd with both the
+// systemd and cgroupfs drivers
+func FindContainerDir(id string) (string, bool) {
+	patterns := []string{
+		"system.slice/docker-%s.scope",
+		"docker/%s",
+		"machine.slice/libpod-%s.scope",
+		"machine.slice/libpod-%s.scope/container",
+		"user.slice/user-*.slice/user@*.service/user.slice/libpod-%s.scope",
+		"user.slice/user-*.slice/user@*.service/user.slice/libpod-%s.scope/container",
+		"system.slice/nerdctl-%s.scope",
+		"default/%s",
+		"k8s.io/%s",
+	}
+
+	for _, p := range patterns {
+		matches, err := filepath.Glob(filepath.Join(Root, fmt.Sprintf(p, id)))
+		if err != nil {
+			continue
+		}
+		for _, m := range matches {
+			if _, err := os.Stat(filepath.Join(m, "cpu.stat")); err == nil {
+				return m, true
+			}
+		}
+	}
+	return "", false
+}
+
+// parseFlatKeyed parses the "key value" per-line format used by cpu.stat and memory.stat
+func parseFlatKeyed(data string) map[string]uint64 {
+	result := make(map[string]uint64)
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		if v, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+			result[fields[0]] = v
+		}
+	}
+	return result
+}
+
+// parseIOStat parses io.stat lines of the form "8:0 rbytes=1 wbytes=2 rios=3 wios=4 ..."
+func parseIOStat(data string) []IOStat {
+	stats := []IOStat{}
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		s := IOStat{Device: fields[0]}
+		for _, kv := range fields[1:] {
+			parts := strings.SplitN(kv, "=", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			v, err := strconv.ParseUint(parts[1], 10, 64)
+			if err != nil {
+				continue
+			}
+			switch parts[0] {
+			case "rbytes":
+				s.ReadBytes = v
+			case "wbytes":
+				s.WriteBytes = v
+			case "rios":
+				s.ReadIOs = v
+			case "wios":
+				s.WriteIOs = v
+			}
+		}
+		stats = append(stats, s)
+	}
+	return stats
+}
+
+// readUint reads a single unsigned integer from a cgroup interface file
+func readUint(path string) (uint64, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
@@ -0,0 +1,58 @@
+package cgroups
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseIOStat(t *testing.T) {
+	input := "8:0 rbytes=1024 wbytes=2048 rios=3 wios=4 dbytes=0 dios=0\n259:0 rbytes=10 wbytes=20 rios=1 wios=2\n"
+	got := parseIOStat(input)
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 devices, got %d", len(got))
+	}
+	want := IOStat{Device: "8:0", ReadBytes: 1024, WriteBytes: 2048, ReadIOs: 3, WriteIOs: 4}
+	if got[0] != want {
+		t.Errorf("parseIOStat()[0] = %+v; want %+v", got[0], want)
+	}
+}
+
+func TestReadStats(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"cpu.stat":       "usage_usec 5000\nuser_usec 3000\nsystem_usec 2000\nnr_periods 0\nnr_throttled 1\nthrottled_usec 100\n",
+		"memory.current": "1048576\n",
+		"memory.max":     "max\n",
+		"memory.stat":    "anon 4096\nfile 8192\nunreported_key 1\n",
+		"io.stat":        "8:0 rbytes=1 wbytes=2 rios=3 wios=4\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to write fixture %s: %v", name, err)
+		}
+	}
+
+	stats, err := ReadStats(dir)
+	if err != nil {
+		t.Fatalf("ReadStats() error = %v", err)
+	}
+	if stats.CPUUsageUsec != 5000 || stats.CPUNrThrottled != 1 || stats.CPUThrottledUsec != 100 {
+		t.Errorf("Unexpected CPU stats: %+v", stats)
+	}
+	if stats.MemoryCurrent != 1048576 || stats.MemoryMax != 0 {
+		t.Errorf("Unexpected memory stats: current=%d max=%d", stats.MemoryCurrent, stats.MemoryMax)
+	}
+	if _, ok := stats.MemoryStat["unreported_key"]; ok || stats.MemoryStat["anon"] != 4096 {
+		t.Errorf("Unexpected memory.stat selection: %v", stats.MemoryStat)
+	}
+	if len(stats.IO) != 1 {
+		t.Errorf("Expected 1 IO device, got %d", len(stats.IO))
+	}
+}
+
+func TestReadStatsMissingFiles(t *testing.T) {
+	if _, err := ReadStats(t.TempDir()); err == nil {
+		t.Error("Expected error for a directory without cgroup files")
+	}
+}
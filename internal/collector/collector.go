@@ -0,0 +1,128 @@
+// Package collector defines metric collectors and the registry that get_metric_snapshot,
+// the history sampler, the exporters, and the gRPC API read flat metrics from, so a
+// collector is written once and formatted by each consumer.
+package collector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Descriptor documents one metric a collector produces
+type Descriptor struct {
+	Metric string   `json:"metric"`
+	Help   string   `json:"help"`
+	Unit   string   `json:"unit,omitempty"`
+	Labels []string `json:"labels,omitempty"`
+}
+
+// Metric is one collected value
+type Metric struct {
+	Name   string            `json:"metric"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  float64           `json:"value"`
+}
+
+// Collector gathers one source of metrics
+type Collector interface {
+	// Name identifies the collector, e.g. cpu or disk
+	Name() string
+	// Describe lists the metrics Collect may return
+	Describe() []Descriptor
+	// Collect reads current values. It should honor ctx and may return partial metrics
+	// with an error.
+	Collect(ctx context.Context) ([]Metric, error)
+}
+
+// funcCollector adapts a function to Collector
+type funcCollector struct {
+	name        string
+	descriptors []Descriptor
+	collect     func(ctx context.Context) ([]Metric, error)
+}
+
+// New returns a Collector that runs collect
+func New(name string, descriptors []Descriptor, collect func(ctx context.Context) ([]Metric, error)) Collector {
+	return funcCollector{name: name, descriptors: descriptors, collect: collect}
+}
+
+func (c funcCollector) Name() string                                  { return c.name }
+func (c funcCollector) Describe() []Descriptor                        { return c.descriptors }
+func (c funcCollector) Collect(ctx context.Context) ([]Metric, error) { return c.collect(ctx) }
+
+// Registry holds collectors in registration order
+type Registry struct {
+	mu         sync.RWMutex
+	collectors []Collector
+}
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds c; names must be unique
+func (r *Registry) Register(c Collector) error {
+	if c.Name() == "" {
+		return fmt.Errorf("collector has no name")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.collectors {
+		if existing.Name() == c.Name() {
+			return fmt.Errorf("collector %q is already registered", c.Name())
+		}
+	}
+	r.collectors = append(r.collectors, c)
+	return nil
+}
+
+// Collectors returns the registered collectors in registration order
+func (r *Registry) Collectors() []Collector {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]Collector{}, r.collectors...)
+}
+
+// Get returns the collector registered under name
+func (r *Registry) Get(name string) (Collector, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, c := range r.collectors {
+		if c.Name() == name {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// Result is what one collector returned
+type Result struct {
+	Collector string
+	Metrics   []Metric
+	Err       error
+}
+
+// Collect runs the named collectors, or all when names is empty, concurrently and returns
+// their results in registration order. Unknown names are ignored.
+func (r *Registry) Collect(ctx context.Context, names ...string) []Result {
+	var selected []Collector
+	for _, c := range r.Collectors() {
+		if len(names) == 0 || slices.Contains(names, c.Name()) {
+			selected = append(selected, c)
+		}
+	}
+
+	results := make([]Result, len(selected))
+	var wg sync.WaitGroup
+	for i, c := range selected {
+		wg.Go(func() {
+			metrics, err := c.Collect(ctx)
+			results[i] = Result{Collector: c.Name(), Metrics: metrics, Err: err}
+		})
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,50 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	failed := errors.New("sensor missing")
+	for _, c := range []Collector{
+		New("cpu", []Descriptor{{Metric: "cpu_percent", Help: "CPU usage", Unit: "percent"}}, func(ctx context.Context) ([]Metric, error) {
+			return []Metric{{Name: "cpu_percent", Value: 12.5}}, nil
+		}),
+		New("disk", nil, func(ctx context.Context) ([]Metric, error) {
+			return []Metric{{Name: "disk_used_percent", Labels: map[string]string{"mount": "/"}, Value: 40}}, failed
+		}),
+		New("temperature", nil, func(ctx context.Context) ([]Metric, error) {
+			return nil, failed
+		}),
+	} {
+		if err := r.Register(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Register(New("cpu", nil, nil)); err == nil {
+		t.Error("Expected registering a duplicate name to fail")
+	}
+	if err := r.Register(New("", nil, nil)); err == nil {
+		t.Error("Expected registering an unnamed collector to fail")
+	}
+
+	// Results keep registration order, and partial metrics survive an error
+	results := r.Collect(context.Background())
+	if len(results) != 3 || results[0].Collector != "cpu" || results[1].Collector != "disk" || results[2].Collector != "temperature" {
+		t.Fatalf("Unexpected results: %+v", results)
+	}
+	if len(results[1].Metrics) != 1 || !errors.Is(results[1].Err, failed) || results[0].Err != nil {
+		t.Errorf("Unexpected disk result: %+v", results[1])
+	}
+
+	only := r.Collect(context.Background(), "temperature", "unknown")
+	if len(only) != 1 || only[0].Collector != "temperature" {
+		t.Errorf("Expected only the temperature collector, got %+v", only)
+	}
+	if c, ok := r.Get("cpu"); !ok || c.Describe()[0].Unit != "percent" {
+		t.Errorf("Expected the cpu collector with its descriptors")
+	}
+}
@@ -2,12 +2,30 @@
 package config
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
+
+	"sysmetrics-mcp/internal/locale"
+	"sysmetrics-mcp/internal/logging"
+	"sysmetrics-mcp/internal/schedule"
+)
+
+// Server identity constants.
+const (
+	ServerName    = "sysmetrics-mcp"
+	ServerVersion = "1.0.0"
 )
 
 // Temperature unit constants.
@@ -17,15 +35,188 @@ const (
 	UnitKelvin     = "kelvin"
 )
 
+// Process listing limits.
+const (
+	DefaultMaxProcesses    = 10
+	DefaultMaxProcessesCap = 50
+	MaxProcessesCapLimit   = 1000
+)
+
+// Default UPS daemon addresses.
+const (
+	DefaultNUTAddr     = "localhost:3493"
+	DefaultApcupsdAddr = "localhost:3551"
+)
+
+// Response size budget limits.
+const (
+	DefaultMaxResponseBytes = 128 * 1024
+	MinMaxResponseBytes     = 4096
+)
+
+// Metrics history defaults.
+const (
+	DefaultSampleInterval   = time.Minute
+	MinSampleInterval       = time.Second
+	DefaultHistoryRetention = 90 * 24 * time.Hour
+	DefaultSnapshotSchedule = "0 * * * *"
+)
+
+// Synthetic check types and interval bounds.
+const (
+	SyntheticHTTP            = "http"
+	SyntheticTCP             = "tcp"
+	SyntheticPing            = "ping"
+	SyntheticDNS             = "dns"
+	DefaultSyntheticInterval = time.Minute
+	MinSyntheticInterval     = 5 * time.Second
+)
+
+// maxSyntheticChecks bounds --synthetic-checks; each check runs in its own goroutine
+const maxSyntheticChecks = 50
+
+// syntheticHostRe restricts ping and DNS targets to hostnames and IP literals, so a target
+// is never mistaken for a ping option
+var syntheticHostRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9.:_-]*$`)
+
+// SyntheticCheck is one recurring probe from --synthetic-checks
+type SyntheticCheck struct {
+	Type     string
+	Target   string
+	Interval time.Duration
+}
+
+// Name identifies the check in results and history labels, e.g. tcp:nas.lan:445
+func (c SyntheticCheck) Name() string {
+	return c.Type + ":" + c.Target
+}
+
+// Tool profiles select the base set of registered tools.
+const (
+	ProfileFull    = "full"
+	ProfilePrivate = "private"
+	ProfileMinimal = "minimal"
+)
+
+// minimalTools are the core host metrics registered by the minimal profile
+var minimalTools = []string{
+	"get_server_info", "self_test", "get_system_info", "get_cpu_metrics", "get_memory_metrics", "get_disk_metrics",
+	"get_network_metrics", "get_thermal_status", "get_system_health", "get_metric_snapshot",
+}
+
+// privacySensitiveTools expose what users run, who they talk to, where they connect, or
+// what was queried; the private profile leaves them out. Add new tools that report process,
+// user, or network identity here.
+var privacySensitiveTools = []string{
+	"get_process_list", "get_network_connections", "get_network_top_processes", "get_listening_ports",
+	"get_fd_usage", "get_audit_log", "get_docker_metrics", "get_container_metrics", "get_k8s_metrics", "get_vm_metrics", "get_proxmox_guests",
+	"get_fileserver_status", "get_audio_status", "diagnose_service", "get_oom_events",
+	"get_ssh_security", "audit_exposed_services", "get_scheduled_jobs", "get_docker_disk_usage",
+	"get_events", "correlate_events", "get_usage_stats", "get_crash_logs", "get_wifi_status", "get_log_growth",
+}
+
+// Output redaction kinds and modes.
+const (
+	RedactIP       = "ip"
+	RedactHostname = "hostname"
+	RedactUser     = "user"
+	RedactCmdline  = "cmdline"
+	RedactMask     = "mask"
+	RedactHash     = "hash"
+)
+
+// RedactKinds lists the values accepted by --redact
+var RedactKinds = []string{RedactIP, RedactHostname, RedactUser, RedactCmdline}
+
+// Audit log defaults.
+const (
+	DefaultAuditLogMaxMB   = 10
+	DefaultAuditLogBackups = 3
+)
+
+// Metrics export defaults.
+const (
+	ExportFormatInflux     = "influx"
+	ExportFormatPrometheus = "prometheus"
+	DefaultExportInterval  = 30 * time.Second
+)
+
 // Config holds the server configuration from CLI args
 type Config struct {
-	TempUnit       string
-	MaxProcesses   int
-	MountPoints    []string
-	Interfaces     []string
-	EnableGPU      bool
-	MountPointsStr string
-	InterfacesStr  string
+	TempUnit                   string
+	MaxProcesses               int
+	MaxProcessesCap            int
+	MountPoints                []string
+	Interfaces                 []string
+	EnableGPU                  bool
+	MountPointsStr             string
+	LogDir                     string
+	InterfacesStr              string
+	SudoAllowlist              []string
+	SudoAllowlistStr           string
+	GeoIPDB                    string
+	ASNDB                      string
+	NUTAddr                    string
+	ApcupsdAddr                string
+	Zigbee2MQTTURL             string
+	ZWaveJSURL                 string
+	UpdateOffline              bool
+	AllowSelfUpdate            bool
+	AllowServiceControl        bool
+	ServiceControlAllowlistStr string
+	ServiceControlAllowlist    []string
+	AllowProcessControl        bool
+	ProcessControlAllowRoot    bool
+	ProcessControlDenyUsersStr string
+	ProcessControlDenyUsers    []string
+	ProcessControlDenyPIDsStr  string
+	ProcessControlDenyPIDs     []int32
+	AllowFSSnapshots           bool
+	HistoryDB                  string
+	HistoryRetention           time.Duration
+	SampleInterval             time.Duration
+	SnapshotSchedule           string
+	ExportURL                  string
+	ExportFormat               string
+	ExportToken                string
+	GRPCAddr                   string
+	GRPCOnly                   bool
+	GRPCToken                  string
+	FleetHostsStr              string
+	FleetHosts                 []string
+	FleetToken                 string
+	SyntheticChecksStr         string
+	SyntheticChecks            []SyntheticCheck
+	WatchProcessesStr          string
+	WatchProcesses             []string
+	WatchServicesStr           string
+	WatchServices              []string
+	Once                       string
+	OnceArgsStr                string
+	OnceArgs                   map[string]interface{}
+	ExportInterval             time.Duration
+	MaxResponseBytes           int
+	RateLimit                  int
+	CacheTTL                   time.Duration
+	CacheToolTTLsStr           string
+	CacheToolTTLs              map[string]time.Duration
+	ToolTimeout                time.Duration
+	Locale                     string
+	LogLevel                   string
+	LogFile                    string
+	AuditLog                   string
+	AuditLogMaxMB              int
+	AuditLogBackups            int
+	RedactStr                  string
+	Redact                     []string
+	RedactMode                 string
+	RedactPatternsStr          string
+	RedactPatterns             []*regexp.Regexp
+	ToolProfile                string
+	EnableToolsStr             string
+	EnableTools                []string
+	DisableToolsStr            string
+	DisableTools               []string
 }
 
 // Validate checks the configuration and parses string lists
@@ -36,12 +227,20 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid temp-unit: %s (must be celsius, fahrenheit, or kelvin)", c.TempUnit)
 	}
 
+	// Validate max processes cap
+	if c.MaxProcessesCap < 1 {
+		c.MaxProcessesCap = DefaultMaxProcessesCap
+	}
+	if c.MaxProcessesCap > MaxProcessesCapLimit {
+		c.MaxProcessesCap = MaxProcessesCapLimit
+	}
+
 	// Validate max processes
 	if c.MaxProcesses < 1 {
-		c.MaxProcesses = 10
+		c.MaxProcesses = DefaultMaxProcesses
 	}
-	if c.MaxProcesses > 50 {
-		c.MaxProcesses = 50
+	if c.MaxProcesses > c.MaxProcessesCap {
+		c.MaxProcesses = c.MaxProcessesCap
 	}
 
 	// Parse mount points
@@ -54,9 +253,336 @@ func (c *Config) Validate() error {
 		c.Interfaces = SplitAndTrim(c.InterfacesStr)
 	}
 
+	// Parse sudo allowlist
+	if c.SudoAllowlistStr != "" {
+		c.SudoAllowlist = SplitAndTrim(c.SudoAllowlistStr)
+		for _, cmd := range c.SudoAllowlist {
+			if strings.ContainsAny(cmd, "/ ") {
+				return fmt.Errorf("invalid sudo-allowlist entry: %q (must be a bare command name)", cmd)
+			}
+		}
+	}
+
+	// Parse the service control allowlist; control_service acts only on these units
+	if c.ServiceControlAllowlistStr != "" {
+		c.ServiceControlAllowlist = SplitAndTrim(c.ServiceControlAllowlistStr)
+		for _, unit := range c.ServiceControlAllowlist {
+			if !validUnitName(unit) {
+				return fmt.Errorf("invalid service-control-allowlist entry: %q (must be a systemd unit name)", unit)
+			}
+		}
+	}
+	if c.AllowServiceControl && len(c.ServiceControlAllowlist) == 0 {
+		return fmt.Errorf("allow-service-control requires service-control-allowlist")
+	}
+
+	// Parse users whose processes manage_process must never touch
+	if c.ProcessControlDenyUsersStr != "" {
+		c.ProcessControlDenyUsers = SplitAndTrim(c.ProcessControlDenyUsersStr)
+		for _, u := range c.ProcessControlDenyUsers {
+			if strings.ContainsAny(u, "/ ") {
+				return fmt.Errorf("invalid process-control-deny-users entry: %q (must be a user name)", u)
+			}
+		}
+	}
+
+	// Parse PIDs manage_process must never touch, such as a database started by hand
+	c.ProcessControlDenyPIDs = nil
+	for _, s := range SplitAndTrim(c.ProcessControlDenyPIDsStr) {
+		pid, err := strconv.ParseInt(s, 10, 32)
+		if err != nil || pid < 1 {
+			return fmt.Errorf("invalid process-control-deny-pids entry: %q (must be a positive PID)", s)
+		}
+		c.ProcessControlDenyPIDs = append(c.ProcessControlDenyPIDs, int32(pid))
+	}
+
+	// Validate smart-home coordinator URLs
+	for _, flagURL := range []struct{ name, value string }{
+		{"zigbee2mqtt-url", c.Zigbee2MQTTURL},
+		{"zwave-js-url", c.ZWaveJSURL},
+	} {
+		if flagURL.value == "" {
+			continue
+		}
+		if u, err := url.Parse(flagURL.value); err != nil || (u.Scheme != "ws" && u.Scheme != "wss") || u.Host == "" {
+			return fmt.Errorf("invalid %s: %q (must be a ws:// or wss:// URL)", flagURL.name, flagURL.value)
+		}
+	}
+
+	// Validate the locale used for human-readable fields
+	if _, err := locale.New(c.Locale); err != nil {
+		return fmt.Errorf("invalid locale: %w", err)
+	}
+
+	// Validate the log level
+	if _, err := logging.ParseLevel(c.LogLevel); err != nil {
+		return fmt.Errorf("invalid log-level: %w", err)
+	}
+
+	// Validate the tool profile and allow/deny lists
+	c.ToolProfile = strings.ToLower(strings.TrimSpace(c.ToolProfile))
+	switch c.ToolProfile {
+	case "":
+		c.ToolProfile = ProfileFull
+	case ProfileFull, ProfilePrivate, ProfileMinimal:
+	default:
+		return fmt.Errorf("invalid tool-profile: %s (must be full, private, or minimal)", c.ToolProfile)
+	}
+	if c.EnableToolsStr != "" {
+		c.EnableTools = SplitAndTrim(c.EnableToolsStr)
+	}
+	if c.DisableToolsStr != "" {
+		c.DisableTools = SplitAndTrim(c.DisableToolsStr)
+	}
+	for _, name := range c.EnableTools {
+		if slices.Contains(c.DisableTools, name) {
+			return fmt.Errorf("invalid tool lists: %s is in both enable-tools and disable-tools", name)
+		}
+	}
+
+	// Parse output redaction
+	if c.RedactStr != "" {
+		c.Redact = SplitAndTrim(strings.ToLower(c.RedactStr))
+		for _, kind := range c.Redact {
+			if !slices.Contains(RedactKinds, kind) {
+				return fmt.Errorf("invalid redact entry: %q (must be %s)", kind, strings.Join(RedactKinds, ", "))
+			}
+		}
+	}
+	if c.RedactPatternsStr != "" {
+		for _, pattern := range SplitAndTrim(c.RedactPatternsStr) {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("invalid redact-patterns entry: %q: %w", pattern, err)
+			}
+			c.RedactPatterns = append(c.RedactPatterns, re)
+		}
+	}
+	c.RedactMode = strings.ToLower(strings.TrimSpace(c.RedactMode))
+	switch c.RedactMode {
+	case "":
+		c.RedactMode = RedactMask
+	case RedactMask, RedactHash:
+	default:
+		return fmt.Errorf("invalid redact-mode: %s (must be mask or hash)", c.RedactMode)
+	}
+
+	// Validate audit log rotation
+	if c.AuditLogMaxMB <= 0 {
+		c.AuditLogMaxMB = DefaultAuditLogMaxMB
+	}
+	if c.AuditLogBackups < 0 {
+		return fmt.Errorf("invalid audit-log-backups: %d (must be 0 or more)", c.AuditLogBackups)
+	}
+
+	// Validate the response size budget (0 disables it)
+	if c.MaxResponseBytes < 0 || (c.MaxResponseBytes > 0 && c.MaxResponseBytes < MinMaxResponseBytes) {
+		return fmt.Errorf("invalid max-response-bytes: %d (must be 0 or at least %d)", c.MaxResponseBytes, MinMaxResponseBytes)
+	}
+
+	// Validate the rate limit and result cache (0 disables each)
+	if c.RateLimit < 0 {
+		return fmt.Errorf("invalid rate-limit: %d (must be 0 or more)", c.RateLimit)
+	}
+	if c.CacheTTL < 0 {
+		return fmt.Errorf("invalid cache-ttl: %s (must be 0 or more)", c.CacheTTL)
+	}
+	if c.CacheToolTTLsStr != "" {
+		c.CacheToolTTLs = map[string]time.Duration{}
+		for _, entry := range SplitAndTrim(c.CacheToolTTLsStr) {
+			name, ttl, ok := strings.Cut(entry, "=")
+			d, err := time.ParseDuration(ttl)
+			if !ok || name == "" || err != nil || d < 0 {
+				return fmt.Errorf("invalid cache-tool-ttls entry: %q (must be tool=duration, e.g. get_process_list=2s)", entry)
+			}
+			c.CacheToolTTLs[name] = d
+		}
+	}
+	if c.ToolTimeout < 0 {
+		return fmt.Errorf("invalid tool-timeout: %s (must be 0 or more)", c.ToolTimeout)
+	}
+
+	// Validate metrics history settings
+	if c.SampleInterval <= 0 {
+		c.SampleInterval = DefaultSampleInterval
+	}
+	if c.SampleInterval < MinSampleInterval {
+		return fmt.Errorf("invalid sample-interval: %s (must be at least %s)", c.SampleInterval, MinSampleInterval)
+	}
+	if c.HistoryRetention <= 0 {
+		c.HistoryRetention = DefaultHistoryRetention
+	}
+	if c.HistoryRetention < c.SampleInterval {
+		return fmt.Errorf("invalid history-retention: %s (must be at least the sample interval %s)", c.HistoryRetention, c.SampleInterval)
+	}
+	if c.SnapshotSchedule != "" {
+		if _, err := schedule.Parse(c.SnapshotSchedule); err != nil {
+			return fmt.Errorf("invalid snapshot-schedule: %w", err)
+		}
+	}
+
+	// Validate the metrics exporter
+	if c.ExportURL != "" {
+		c.ExportFormat = strings.ToLower(c.ExportFormat)
+		if c.ExportFormat == "" {
+			c.ExportFormat = ExportFormatInflux
+		}
+		if c.ExportFormat != ExportFormatInflux && c.ExportFormat != ExportFormatPrometheus {
+			return fmt.Errorf("invalid export-format: %s (must be influx or prometheus)", c.ExportFormat)
+		}
+		if u, err := url.Parse(c.ExportURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid export-url: %q (must be an http:// or https:// URL)", c.ExportURL)
+		}
+		if c.ExportInterval <= 0 {
+			c.ExportInterval = DefaultExportInterval
+		}
+	}
+
+	// Validate the gRPC listen address
+	if c.GRPCAddr != "" {
+		if _, port, err := net.SplitHostPort(c.GRPCAddr); err != nil || port == "" {
+			return fmt.Errorf("invalid grpc-addr: %q (must be host:port, e.g. 127.0.0.1:50051)", c.GRPCAddr)
+		}
+	}
+	if c.GRPCOnly && c.GRPCAddr == "" {
+		return fmt.Errorf("grpc-only requires grpc-addr")
+	}
+
+	// Parse the fleet agents the fleet tools query over gRPC
+	if c.FleetHostsStr != "" {
+		c.FleetHosts = SplitAndTrim(c.FleetHostsStr)
+		for _, host := range c.FleetHosts {
+			if _, port, err := net.SplitHostPort(host); err != nil || port == "" {
+				return fmt.Errorf("invalid fleet-hosts entry: %q (must be host:port, e.g. pi-2.lan:50051)", host)
+			}
+		}
+	}
+
+	// Parse the synthetic checks run in the background; results go to the history store
+	if c.SyntheticChecksStr != "" {
+		if c.HistoryDB == "" {
+			return fmt.Errorf("synthetic-checks requires history-db")
+		}
+		entries := SplitAndTrim(c.SyntheticChecksStr)
+		if len(entries) > maxSyntheticChecks {
+			return fmt.Errorf("invalid synthetic-checks: %d entries (at most %d)", len(entries), maxSyntheticChecks)
+		}
+		seen := map[string]bool{}
+		for _, entry := range entries {
+			check, err := ParseSyntheticCheck(entry)
+			if err != nil {
+				return fmt.Errorf("invalid synthetic-checks entry: %q (%v)", entry, err)
+			}
+			if !seen[check.Name()] {
+				seen[check.Name()] = true
+				c.SyntheticChecks = append(c.SyntheticChecks, check)
+			}
+		}
+	}
+
+	// Parse the processes whose restarts are recorded in the event journal
+	if c.WatchProcessesStr != "" {
+		if c.HistoryDB == "" {
+			return fmt.Errorf("watch-processes requires history-db")
+		}
+		seen := map[string]bool{}
+		for _, name := range SplitAndTrim(c.WatchProcessesStr) {
+			if strings.ContainsAny(name, "/ ") {
+				return fmt.Errorf("invalid watch-processes entry: %q (must be a process name, e.g. nginx)", name)
+			}
+			if !seen[name] {
+				seen[name] = true
+				c.WatchProcesses = append(c.WatchProcesses, name)
+			}
+		}
+	}
+
+	// Parse the systemd units watched for restart loops
+	if c.WatchServicesStr != "" {
+		if c.HistoryDB == "" {
+			return fmt.Errorf("watch-services requires history-db")
+		}
+		seen := map[string]bool{}
+		for _, name := range SplitAndTrim(c.WatchServicesStr) {
+			if strings.ContainsAny(name, "/ *?[") || strings.HasPrefix(name, "-") {
+				return fmt.Errorf("invalid watch-services entry: %q (must be a unit name, e.g. nginx or zigbee2mqtt.service)", name)
+			}
+			if !strings.Contains(name, ".") {
+				name += ".service"
+			}
+			if !seen[name] {
+				seen[name] = true
+				c.WatchServices = append(c.WatchServices, name)
+			}
+		}
+	}
+
+	// Validate one-shot mode and parse its tool arguments
+	if c.OnceArgsStr != "" {
+		if c.Once == "" {
+			return fmt.Errorf("args requires once")
+		}
+		if err := json.Unmarshal([]byte(c.OnceArgsStr), &c.OnceArgs); err != nil {
+			return fmt.Errorf("invalid args: %q (must be a JSON object, e.g. {\"limit\": 5})", c.OnceArgsStr)
+		}
+	}
+	if c.Once != "" && c.GRPCOnly {
+		return fmt.Errorf("once cannot be combined with grpc-only")
+	}
+
 	return nil
 }
 
+// ParseSyntheticCheck parses a type:target[@interval] entry such as
+// http:https://nas.lan/health@30s, tcp:nas.lan:445, ping:192.168.1.1@10s, or dns:example.com@5m
+func ParseSyntheticCheck(entry string) (SyntheticCheck, error) {
+	kind, target, ok := strings.Cut(entry, ":")
+	if !ok || target == "" {
+		return SyntheticCheck{}, fmt.Errorf("must be type:target[@interval]")
+	}
+	check := SyntheticCheck{Type: strings.ToLower(kind), Target: target, Interval: DefaultSyntheticInterval}
+	// The last @ starts the interval only if it parses as one, so URLs with user info still work
+	if i := strings.LastIndex(target, "@"); i >= 0 {
+		if d, err := time.ParseDuration(target[i+1:]); err == nil {
+			check.Target, check.Interval = target[:i], d
+		}
+	}
+	if check.Interval < MinSyntheticInterval {
+		return check, fmt.Errorf("interval must be at least %s", MinSyntheticInterval)
+	}
+
+	switch check.Type {
+	case SyntheticHTTP:
+		if u, err := url.Parse(check.Target); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return check, fmt.Errorf("target must be an http:// or https:// URL")
+		}
+	case SyntheticTCP:
+		if host, port, err := net.SplitHostPort(check.Target); err != nil || host == "" || port == "" {
+			return check, fmt.Errorf("target must be host:port")
+		}
+	case SyntheticPing, SyntheticDNS:
+		if !syntheticHostRe.MatchString(check.Target) {
+			return check, fmt.Errorf("target must be a hostname or IP address")
+		}
+	default:
+		return check, fmt.Errorf("type must be http, tcp, ping, or dns")
+	}
+	return check, nil
+}
+
+// ClampProcessLimit bounds a requested row limit to the configured cap, falling back to
+// the configured default when the cap has not been set (e.g. in tests)
+func (c *Config) ClampProcessLimit(limit int) int {
+	maxRows := c.MaxProcessesCap
+	if maxRows < 1 {
+		maxRows = DefaultMaxProcessesCap
+	}
+	if limit > maxRows {
+		return maxRows
+	}
+	return limit
+}
+
 // SplitAndTrim splits a comma-separated string and trims whitespace
 func SplitAndTrim(s string) []string {
 	parts := strings.Split(s, ",")
@@ -90,6 +616,11 @@ func ConvertTemperature(celsius float64, unit string) map[string]float64 {
 
 // GetRaspberryPiTemp reads CPU temperature from Pi thermal zone
 func GetRaspberryPiTemp() (float64, bool) {
+	// Raspberry Pi interfaces only exist on Linux
+	if runtime.GOOS != "linux" {
+		return 0, false
+	}
+
 	// Try different thermal zone paths
 	paths := []string{
 		"/sys/class/thermal/thermal_zone0/temp",
@@ -113,8 +644,13 @@ func GetRaspberryPiTemp() (float64, bool) {
 }
 
 // GetRaspberryPiGPUTemp reads GPU temperature using vcgencmd
-func GetRaspberryPiGPUTemp() (float64, bool) {
-	cmd := exec.Command("vcgencmd", "measure_temp")
+func GetRaspberryPiGPUTemp(ctx context.Context) (float64, bool) {
+	// Raspberry Pi interfaces only exist on Linux
+	if runtime.GOOS != "linux" {
+		return 0, false
+	}
+
+	cmd := exec.CommandContext(ctx, "vcgencmd", "measure_temp")
 	output, err := cmd.Output()
 	if err != nil {
 		return 0, false
@@ -139,8 +675,13 @@ func GetRaspberryPiGPUTemp() (float64, bool) {
 }
 
 // GetThrottledStatus reads Pi throttling status
-func GetThrottledStatus() (map[string]interface{}, bool) {
-	cmd := exec.Command("vcgencmd", "get_throttled")
+func GetThrottledStatus(ctx context.Context) (map[string]interface{}, bool) {
+	// Raspberry Pi interfaces only exist on Linux
+	if runtime.GOOS != "linux" {
+		return nil, false
+	}
+
+	cmd := exec.CommandContext(ctx, "vcgencmd", "get_throttled")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, false
@@ -185,3 +726,51 @@ func BytesToHuman(bytes uint64) string {
 	}
 	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
+
+// optInTools change the host and are only registered when their flag is set
+var optInTools = map[string]struct {
+	flag string
+	set  func(*Config) bool
+}{
+	"manage_process":     {"--allow-process-control", func(c *Config) bool { return c.AllowProcessControl }},
+	"control_service":    {"--allow-service-control", func(c *Config) bool { return c.AllowServiceControl }},
+	"create_fs_snapshot": {"--allow-fs-snapshots", func(c *Config) bool { return c.AllowFSSnapshots }},
+	"apply_update":       {"--allow-self-update", func(c *Config) bool { return c.AllowSelfUpdate }},
+}
+
+// ToolEnabled reports whether a tool may be registered under its opt-in flag, the tool
+// profile, and the enable/disable lists, with the reason when it may not. Explicit lists
+// override the profile but not an unset opt-in flag.
+func (c *Config) ToolEnabled(name string) (bool, string) {
+	if optIn, ok := optInTools[name]; ok && !optIn.set(c) {
+		return false, optIn.flag + " is not set"
+	}
+	switch {
+	case slices.Contains(c.DisableTools, name):
+		return false, "disabled by --disable-tools"
+	case slices.Contains(c.EnableTools, name):
+		return true, ""
+	case c.ToolProfile == ProfileMinimal && !slices.Contains(minimalTools, name):
+		return false, "not in the minimal tool profile"
+	case c.ToolProfile == ProfilePrivate && slices.Contains(privacySensitiveTools, name):
+		return false, "privacy-sensitive tool excluded by the private tool profile"
+	}
+	return true, ""
+}
+
+// validUnitName reports whether name is a plausible systemd unit name, e.g. nginx or
+// docker.service. Template instances (getty@tty1) are allowed; paths and options are not.
+func validUnitName(name string) bool {
+	if name == "" || strings.HasPrefix(name, "-") || strings.HasPrefix(name, ".") {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case strings.ContainsRune(":_.@-\\", r):
+		default:
+			return false
+		}
+	}
+	return true
+}
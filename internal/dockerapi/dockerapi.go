@@ -0,0 +1,281 @@
+// Package dockerapi is a minimal client for the Docker Engine API over its unix socket,
+// covering the container details the docker CLI's ps and stats output leave out.
+package dockerapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultSocket is where dockerd listens unless configured otherwise
+const DefaultSocket = "/var/run/docker.sock"
+
+// requestTimeout bounds a single API request. A one-shot stats call returns at once,
+// but a daemon under load can take a few seconds to answer.
+const requestTimeout = 10 * time.Second
+
+// diskUsageTimeout bounds a disk usage request, for which the daemon walks every
+// container layer and volume
+const diskUsageTimeout = 60 * time.Second
+
+// Client talks to dockerd over a unix socket
+type Client struct {
+	http *http.Client
+}
+
+// New returns a client for the daemon listening on socket
+func New(socket string) *Client {
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		},
+	}
+	return &Client{http: &http.Client{Transport: transport}}
+}
+
+// Port is a published or exposed container port
+type Port struct {
+	IP          string `json:"IP,omitempty"`
+	PrivatePort int    `json:"PrivatePort"`
+	PublicPort  int    `json:"PublicPort,omitempty"`
+	Type        string `json:"Type"`
+}
+
+// Container is an entry of the container list
+type Container struct {
+	ID    string   `json:"Id"`
+	Names []string `json:"Names"`
+	State string   `json:"State"`
+	Ports []Port   `json:"Ports"`
+}
+
+// HealthLog is the result of one health check run
+type HealthLog struct {
+	Start    time.Time `json:"Start"`
+	End      time.Time `json:"End"`
+	ExitCode int       `json:"ExitCode"`
+	Output   string    `json:"Output"`
+}
+
+// Health is a container's health check state. Status is "starting", "healthy", or
+// "unhealthy".
+type Health struct {
+	Status        string      `json:"Status"`
+	FailingStreak int         `json:"FailingStreak"`
+	Log           []HealthLog `json:"Log"`
+}
+
+// State is a container's run state
+type State struct {
+	Status    string  `json:"Status"`
+	Running   bool    `json:"Running"`
+	OOMKilled bool    `json:"OOMKilled"`
+	ExitCode  int     `json:"ExitCode"`
+	StartedAt string  `json:"StartedAt"`
+	Health    *Health `json:"Health"`
+}
+
+// Inspection is the part of a container's inspect output this package reads
+type Inspection struct {
+	ID           string `json:"Id"`
+	RestartCount int    `json:"RestartCount"`
+	State        State  `json:"State"`
+}
+
+// NetworkStats are one interface's counters
+type NetworkStats struct {
+	RxBytes uint64 `json:"rx_bytes"`
+	TxBytes uint64 `json:"tx_bytes"`
+}
+
+// BlkioEntry is one row of a blkio counter list
+type BlkioEntry struct {
+	Major uint64 `json:"major"`
+	Minor uint64 `json:"minor"`
+	Op    string `json:"op"`
+	Value uint64 `json:"value"`
+}
+
+// Stats is the part of a container's stats this package reads
+type Stats struct {
+	Networks   map[string]NetworkStats `json:"networks"`
+	BlkioStats struct {
+		IOServiceBytesRecursive []BlkioEntry `json:"io_service_bytes_recursive"`
+	} `json:"blkio_stats"`
+}
+
+// NetworkTotals sums received and transmitted bytes across the container's interfaces
+func (s Stats) NetworkTotals() (rx, tx uint64) {
+	for _, n := range s.Networks {
+		rx += n.RxBytes
+		tx += n.TxBytes
+	}
+	return rx, tx
+}
+
+// BlockIO sums bytes read and written across the container's block devices. cgroups v1
+// reports capitalized ops and a Total row, cgroups v2 lowercase ops only.
+func (s Stats) BlockIO() (read, write uint64) {
+	for _, e := range s.BlkioStats.IOServiceBytesRecursive {
+		switch strings.ToLower(e.Op) {
+		case "read":
+			read += e.Value
+		case "write":
+			write += e.Value
+		}
+	}
+	return read, write
+}
+
+// ImageUsage is an image's entry in the disk usage report. SharedSize is the part of
+// Size in layers shared with other images, or -1 when the daemon did not compute it.
+type ImageUsage struct {
+	ID         string   `json:"Id"`
+	RepoTags   []string `json:"RepoTags"`
+	Created    int64    `json:"Created"`
+	Size       int64    `json:"Size"`
+	SharedSize int64    `json:"SharedSize"`
+	Containers int64    `json:"Containers"`
+}
+
+// Dangling reports whether the image has no tag, as left behind when a tag moves to a
+// newer build
+func (i ImageUsage) Dangling() bool {
+	for _, tag := range i.RepoTags {
+		if tag != "<none>:<none>" {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainerUsage is a container's entry in the disk usage report. SizeRw is the size
+// of its writable layer.
+type ContainerUsage struct {
+	ID     string   `json:"Id"`
+	Names  []string `json:"Names"`
+	Image  string   `json:"Image"`
+	State  string   `json:"State"`
+	SizeRw int64    `json:"SizeRw"`
+}
+
+// VolumeUsage is a volume's entry in the disk usage report. Size and RefCount are -1
+// when the daemon did not compute them.
+type VolumeUsage struct {
+	Name      string `json:"Name"`
+	Driver    string `json:"Driver"`
+	UsageData *struct {
+		Size     int64 `json:"Size"`
+		RefCount int64 `json:"RefCount"`
+	} `json:"UsageData"`
+}
+
+// BuildCacheUsage is a build cache record's entry in the disk usage report
+type BuildCacheUsage struct {
+	ID     string `json:"ID"`
+	Type   string `json:"Type"`
+	Size   int64  `json:"Size"`
+	InUse  bool   `json:"InUse"`
+	Shared bool   `json:"Shared"`
+}
+
+// DiskUsage is the daemon's disk usage report, as summarized by docker system df
+type DiskUsage struct {
+	LayersSize int64             `json:"LayersSize"`
+	Images     []ImageUsage      `json:"Images"`
+	Containers []ContainerUsage  `json:"Containers"`
+	Volumes    []VolumeUsage     `json:"Volumes"`
+	BuildCache []BuildCacheUsage `json:"BuildCache"`
+}
+
+// Info is the part of the daemon's system info this package reads
+type Info struct {
+	DockerRootDir string `json:"DockerRootDir"`
+	Driver        string `json:"Driver"`
+}
+
+// Containers lists containers, including stopped ones when all is set
+func (c *Client) Containers(ctx context.Context, all bool) ([]Container, error) {
+	path := "/containers/json"
+	if all {
+		path += "?all=1"
+	}
+	var containers []Container
+	if err := c.get(ctx, path, &containers); err != nil {
+		return nil, err
+	}
+	return containers, nil
+}
+
+// Inspect returns a container's restart count and state
+func (c *Client) Inspect(ctx context.Context, id string) (Inspection, error) {
+	var inspection Inspection
+	err := c.get(ctx, "/containers/"+url.PathEscape(id)+"/json", &inspection)
+	return inspection, err
+}
+
+// Stats returns a single stats sample for a running container
+func (c *Client) Stats(ctx context.Context, id string) (Stats, error) {
+	var stats Stats
+	err := c.get(ctx, "/containers/"+url.PathEscape(id)+"/stats?stream=false&one-shot=true", &stats)
+	return stats, err
+}
+
+// DiskUsage returns the space used by images, containers, volumes, and the build cache
+func (c *Client) DiskUsage(ctx context.Context) (DiskUsage, error) {
+	ctx, cancel := context.WithTimeout(ctx, diskUsageTimeout)
+	defer cancel()
+	var usage DiskUsage
+	err := c.get(ctx, "/system/df", &usage)
+	return usage, err
+}
+
+// Info returns the daemon's storage driver and data root
+func (c *Client) Info(ctx context.Context) (Info, error) {
+	var info Info
+	err := c.get(ctx, "/info", &info)
+	return info, err
+}
+
+// get decodes the JSON response to a GET request. Errors carry the daemon's message.
+// Requests without a deadline of their own are bounded by requestTimeout.
+func (c *Client) get(ctx context.Context, path string, v interface{}) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, requestTimeout)
+		defer cancel()
+	}
+	// The host is ignored by the unix socket dialer
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://docker"+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("docker API %s: %s", resp.Status, apiErr.Message)
+		}
+		return fmt.Errorf("docker API %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode docker API response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package dockerapi
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// serveAPI serves canned responses keyed by request path on a unix socket
+func serveAPI(t *testing.T, responses map[string]string) string {
+	t.Helper()
+	socket := filepath.Join(t.TempDir(), "docker.sock")
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.RequestURI()]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `{"message":"No such container: `+strings.TrimPrefix(r.URL.Path, "/containers/")+`"}`)
+			return
+		}
+		_, _ = io.WriteString(w, body)
+	})}
+	go func() { _ = srv.Serve(ln) }()
+	t.Cleanup(func() { _ = srv.Close() })
+	return socket
+}
+
+func TestClient(t *testing.T) {
+	socket := serveAPI(t, map[string]string{
+		"/containers/json?all=1": `[{"Id":"abc","Names":["/web"],"State":"running",
+			"Ports":[{"IP":"0.0.0.0","PrivatePort":80,"PublicPort":8080,"Type":"tcp"},{"PrivatePort":443,"Type":"tcp"}]}]`,
+		"/containers/abc/json": `{"Id":"abc","RestartCount":3,"State":{"Status":"running","Running":true,
+			"Health":{"Status":"unhealthy","FailingStreak":2,"Log":[{"ExitCode":1,"Output":"connection refused\n"}]}}}`,
+		"/containers/abc/stats?stream=false&one-shot=true": `{
+			"networks":{"eth0":{"rx_bytes":1000,"tx_bytes":200},"eth1":{"rx_bytes":24,"tx_bytes":6}},
+			"blkio_stats":{"io_service_bytes_recursive":[
+				{"major":8,"minor":0,"op":"Read","value":4096},{"major":8,"minor":0,"op":"Write","value":512},
+				{"major":8,"minor":0,"op":"Total","value":4608},{"major":8,"minor":16,"op":"read","value":100}]}}`,
+	})
+	c := New(socket)
+	ctx := context.Background()
+
+	containers, err := c.Containers(ctx, true)
+	if err != nil || len(containers) != 1 || len(containers[0].Ports) != 2 || containers[0].Ports[0].PublicPort != 8080 {
+		t.Fatalf("Containers() = %+v, %v", containers, err)
+	}
+
+	inspection, err := c.Inspect(ctx, "abc")
+	if err != nil || inspection.RestartCount != 3 || inspection.State.Health == nil || inspection.State.Health.FailingStreak != 2 {
+		t.Fatalf("Inspect() = %+v, %v", inspection, err)
+	}
+
+	stats, err := c.Stats(ctx, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rx, tx := stats.NetworkTotals(); rx != 1024 || tx != 206 {
+		t.Errorf("NetworkTotals() = %d, %d", rx, tx)
+	}
+	if read, write := stats.BlockIO(); read != 4196 || write != 512 {
+		t.Errorf("BlockIO() = %d, %d", read, write)
+	}
+
+	if _, err := c.Inspect(ctx, "gone"); err == nil || !strings.Contains(err.Error(), "No such container: gone") {
+		t.Errorf("Expected the daemon's error message, got %v", err)
+	}
+}
+
+func TestClientNoDaemon(t *testing.T) {
+	c := New(filepath.Join(t.TempDir(), "missing.sock"))
+	if _, err := c.Containers(context.Background(), false); err == nil {
+		t.Error("Expected an error without a daemon")
+	}
+}
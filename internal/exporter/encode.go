@@ -0,0 +1,128 @@
+package exporter
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sysmetrics-mcp/internal/history"
+)
+
+// Line protocol escaping: tag keys and values escape commas, equals signs, and spaces;
+// measurements only commas and spaces
+var (
+	influxTagEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+	influxMeasurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `)
+)
+
+// encodeInflux renders samples in InfluxDB line protocol with nanosecond timestamps,
+// one line per sample: <prefix><metric>,host=...,<labels> value=<v> <ts>
+func encodeInflux(samples []history.Sample, hostname string) []byte {
+	var b strings.Builder
+	for _, s := range samples {
+		b.WriteString(influxMeasurementEscaper.Replace(metricPrefix + s.Metric))
+		for _, l := range seriesLabels(s, hostname) {
+			b.WriteByte(',')
+			b.WriteString(influxTagEscaper.Replace(l.name))
+			b.WriteByte('=')
+			b.WriteString(influxTagEscaper.Replace(l.value))
+		}
+		b.WriteString(" value=")
+		b.WriteString(strconv.FormatFloat(s.Value, 'g', -1, 64))
+		b.WriteByte(' ')
+		b.WriteString(strconv.FormatInt(s.Time.UnixNano(), 10))
+		b.WriteByte('\n')
+	}
+	return []byte(b.String())
+}
+
+// label is a Prometheus/Influx label pair
+type label struct {
+	name, value string
+}
+
+// seriesLabels returns the host label plus the sample's labels sorted by name. Empty
+// values are skipped since neither protocol accepts them.
+func seriesLabels(s history.Sample, hostname string) []label {
+	labels := make([]label, 0, len(s.Labels)+1)
+	if hostname != "" {
+		labels = append(labels, label{"host", hostname})
+	}
+	for k, v := range s.Labels {
+		if k == "host" || v == "" {
+			continue
+		}
+		labels = append(labels, label{k, v})
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].name < labels[j].name })
+	return labels
+}
+
+// encodePrometheus renders samples as an uncompressed Prometheus remote-write
+// WriteRequest protobuf, grouping samples of the same series into one TimeSeries.
+//
+//	message WriteRequest { repeated TimeSeries timeseries = 1; }
+//	message TimeSeries   { repeated Label labels = 1; repeated Sample samples = 2; }
+//	message Label        { string name = 1; string value = 2; }
+//	message Sample       { double value = 1; int64 timestamp = 2; }
+func encodePrometheus(samples []history.Sample, hostname string) []byte {
+	type series struct {
+		labels  []label
+		samples []history.Sample
+	}
+	var order []string
+	bySeries := map[string]*series{}
+	for _, s := range samples {
+		key := s.Metric + "{" + history.FormatLabels(s.Labels) + "}"
+		ts, ok := bySeries[key]
+		if !ok {
+			// "__name__" sorts before any lowercase label name, keeping labels ordered
+			labels := append([]label{{"__name__", metricPrefix + s.Metric}}, seriesLabels(s, hostname)...)
+			ts = &series{labels: labels}
+			bySeries[key] = ts
+			order = append(order, key)
+		}
+		ts.samples = append(ts.samples, s)
+	}
+
+	var req []byte
+	for _, key := range order {
+		ts := bySeries[key]
+		var msg []byte
+		for _, l := range ts.labels {
+			var lb []byte
+			lb = appendString(lb, 1, l.name)
+			lb = appendString(lb, 2, l.value)
+			msg = appendBytes(msg, 1, lb)
+		}
+		for _, s := range ts.samples {
+			var sb []byte
+			sb = protowire(sb, 1, 1)
+			sb = binary.LittleEndian.AppendUint64(sb, math.Float64bits(s.Value))
+			sb = protowire(sb, 2, 0)
+			sb = binary.AppendUvarint(sb, uint64(s.Time.UnixMilli())) //nolint:gosec // G115: int64 varints are encoded as two's complement
+			msg = appendBytes(msg, 2, sb)
+		}
+		req = appendBytes(req, 1, msg)
+	}
+	return req
+}
+
+// protowire appends a protobuf field tag
+func protowire(b []byte, field, wireType uint64) []byte {
+	return binary.AppendUvarint(b, field<<3|wireType)
+}
+
+// appendBytes appends a length-delimited protobuf field
+func appendBytes(b []byte, field uint64, v []byte) []byte {
+	b = protowire(b, field, 2)
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+// appendString appends a protobuf string field
+func appendString(b []byte, field uint64, v string) []byte {
+	return appendBytes(b, field, []byte(v))
+}
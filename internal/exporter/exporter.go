@@ -0,0 +1,281 @@
+// Package exporter pushes sampled metrics to a time-series database using InfluxDB line
+// protocol or Prometheus remote-write, so the server can double as a lightweight agent.
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"sysmetrics-mcp/internal/history"
+
+	"github.com/golang/snappy"
+)
+
+// Supported export formats.
+const (
+	FormatInflux     = "influx"
+	FormatPrometheus = "prometheus"
+)
+
+// Batching and retry limits
+const (
+	maxBatchSamples   = 1000
+	maxPendingSamples = 50000
+	maxAttempts       = 3
+	initialBackoff    = time.Second
+	requestTimeout    = 10 * time.Second
+	finalFlushTimeout = 5 * time.Second
+)
+
+// metricPrefix namespaces exported metric names
+const metricPrefix = "sysmetrics_"
+
+// Options configures an Exporter
+type Options struct {
+	URL      string
+	Format   string
+	Token    string
+	Interval time.Duration
+	Hostname string
+	// Logger, if set, receives failed pushes
+	Logger *slog.Logger
+}
+
+// Stats reports the exporter's delivery counters
+type Stats struct {
+	Format      string `json:"format"`
+	URL         string `json:"url"`
+	Pending     int    `json:"pending_samples"`
+	Sent        uint64 `json:"sent_samples"`
+	Dropped     uint64 `json:"dropped_samples"`
+	Failures    uint64 `json:"failed_requests"`
+	LastSuccess string `json:"last_success,omitempty"`
+	LastError   string `json:"last_error,omitempty"`
+}
+
+// permanentError marks a rejected batch that retrying will not fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Exporter queues samples and pushes them in batches on an interval
+type Exporter struct {
+	opts   Options
+	client *http.Client
+
+	mu      sync.Mutex
+	pending []history.Sample
+	stats   Stats
+}
+
+// New validates the options and creates an Exporter
+func New(opts Options) (*Exporter, error) {
+	if opts.Format != FormatInflux && opts.Format != FormatPrometheus {
+		return nil, fmt.Errorf("invalid export format: %s (must be influx or prometheus)", opts.Format)
+	}
+	u, err := url.Parse(opts.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid export URL: %q (must be an http:// or https:// URL)", opts.URL)
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = 30 * time.Second
+	}
+
+	return &Exporter{
+		opts:   opts,
+		client: &http.Client{Timeout: requestTimeout},
+		stats:  Stats{Format: opts.Format, URL: RedactURL(opts.URL)},
+	}, nil
+}
+
+// Write queues samples for the next push. When the endpoint has been unreachable long
+// enough to fill the queue, the oldest samples are dropped.
+func (e *Exporter) Write(ctx context.Context, samples []history.Sample) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.pending = append(e.pending, samples...)
+	if over := len(e.pending) - maxPendingSamples; over > 0 {
+		e.pending = append([]history.Sample(nil), e.pending[over:]...)
+		e.stats.Dropped += uint64(over)
+	}
+	return nil
+}
+
+// Run pushes queued samples every interval until ctx is cancelled, then makes a final
+// best-effort push
+func (e *Exporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), finalFlushTimeout)
+			e.logFlush(e.Flush(flushCtx))
+			cancel()
+			return
+		case <-ticker.C:
+			e.logFlush(e.Flush(ctx))
+		}
+	}
+}
+
+// Flush pushes queued samples in batches of at most maxBatchSamples. A batch that still
+// fails after retries stays queued for the next flush, except when the endpoint rejected
+// it outright, in which case it is dropped.
+func (e *Exporter) Flush(ctx context.Context) error {
+	for {
+		e.mu.Lock()
+		n := len(e.pending)
+		if n > maxBatchSamples {
+			n = maxBatchSamples
+		}
+		batch := append([]history.Sample(nil), e.pending[:n]...)
+		e.mu.Unlock()
+
+		if len(batch) == 0 {
+			return nil
+		}
+
+		err := e.send(ctx, batch)
+		var perm *permanentError
+
+		e.mu.Lock()
+		switch {
+		case err == nil:
+			e.pending = e.pending[len(batch):]
+			e.stats.Sent += uint64(len(batch))
+			e.stats.LastSuccess = time.Now().UTC().Format(time.RFC3339)
+		case errors.As(err, &perm):
+			e.pending = e.pending[len(batch):]
+			e.stats.Dropped += uint64(len(batch))
+			e.stats.LastError = err.Error()
+		default:
+			e.stats.LastError = err.Error()
+		}
+		e.mu.Unlock()
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// logFlush logs a failed push
+func (e *Exporter) logFlush(err error) {
+	if err != nil && e.opts.Logger != nil {
+		e.opts.Logger.Warn("metrics export failed", "url", e.stats.URL, "error", err)
+	}
+}
+
+// Stats returns a snapshot of the delivery counters
+func (e *Exporter) Stats() Stats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	stats := e.stats
+	stats.Pending = len(e.pending)
+	return stats
+}
+
+// send encodes and posts one batch, retrying transient failures with exponential backoff
+func (e *Exporter) send(ctx context.Context, batch []history.Sample) error {
+	var body []byte
+	if e.opts.Format == FormatPrometheus {
+		body = snappy.Encode(nil, encodePrometheus(batch, e.opts.Hostname))
+	} else {
+		body = encodeInflux(batch, e.opts.Hostname)
+	}
+
+	backoff := initialBackoff
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		err = e.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+
+		e.mu.Lock()
+		e.stats.Failures++
+		e.mu.Unlock()
+
+		var perm *permanentError
+		if errors.As(err, &perm) || attempt == maxAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	return err
+}
+
+// post sends one request; 4xx responses other than 429 are permanent failures
+func (e *Exporter) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.opts.URL, bytes.NewReader(body))
+	if err != nil {
+		return &permanentError{fmt.Errorf("failed to build export request: %w", err)}
+	}
+
+	if e.opts.Format == FormatPrometheus {
+		req.Header.Set("Content-Type", "application/x-protobuf")
+		req.Header.Set("Content-Encoding", "snappy")
+		req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	} else {
+		req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	if e.opts.Token != "" {
+		// InfluxDB 2.x expects "Token"; remote-write receivers expect a bearer token
+		scheme := "Bearer "
+		if e.opts.Format == FormatInflux {
+			scheme = "Token "
+		}
+		req.Header.Set("Authorization", scheme+e.opts.Token)
+	}
+	if u, err := url.Parse(e.opts.URL); err == nil && u.User != nil {
+		password, _ := u.User.Password()
+		req.SetBasicAuth(u.User.Username(), password)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to push metrics to %s: %w", RedactURL(e.opts.URL), err)
+	}
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	err = fmt.Errorf("export endpoint %s returned %s: %s", RedactURL(e.opts.URL), resp.Status, bytes.TrimSpace(msg))
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+		return &permanentError{err}
+	}
+	return err
+}
+
+// RedactURL hides credentials and query parameters (e.g. InfluxDB 1.x u=/p=) in a URL
+func RedactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	if u.RawQuery != "" {
+		u.RawQuery = "redacted"
+	}
+	u.User = nil
+	return u.String()
+}
@@ -0,0 +1,171 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"sysmetrics-mcp/internal/history"
+
+	"github.com/golang/snappy"
+)
+
+var testSamples = []history.Sample{
+	{Time: time.Unix(1700000000, 0), Metric: "cpu_percent", Value: 12.5},
+	{Time: time.Unix(1700000000, 0), Metric: "disk_used_percent", Labels: map[string]string{"mount": "/mnt/my disk"}, Value: 40},
+}
+
+func TestEncodeInflux(t *testing.T) {
+	got := string(encodeInflux(testSamples, "pi"))
+	expected := "sysmetrics_cpu_percent,host=pi value=12.5 1700000000000000000\n" +
+		`sysmetrics_disk_used_percent,host=pi,mount=/mnt/my\ disk value=40 1700000000000000000` + "\n"
+	if got != expected {
+		t.Errorf("encodeInflux =\n%s\nexpected\n%s", got, expected)
+	}
+}
+
+func TestEncodePrometheus(t *testing.T) {
+	samples := []history.Sample{
+		{Time: time.UnixMilli(1000), Metric: "load1", Value: 1},
+		{Time: time.UnixMilli(2000), Metric: "load1", Value: 2},
+	}
+	got := encodePrometheus(samples, "")
+
+	// One TimeSeries holding the __name__ label and both samples
+	var label []byte
+	label = appendString(label, 1, "__name__")
+	label = appendString(label, 2, "sysmetrics_load1")
+	expected := []byte{0x0a}
+	series := appendBytes(nil, 1, label)
+	series = append(series, 0x12, 12, 0x09, 0, 0, 0, 0, 0, 0, 0xf0, 0x3f, 0x10, 0xe8, 0x07)
+	series = append(series, 0x12, 12, 0x09, 0, 0, 0, 0, 0, 0, 0x00, 0x40, 0x10, 0xd0, 0x0f)
+	expected = append(expected, byte(len(series)))
+	expected = append(expected, series...)
+
+	if !bytes.Equal(got, expected) {
+		t.Errorf("encodePrometheus =\n%x\nexpected\n%x", got, expected)
+	}
+}
+
+func TestExporterFlushInflux(t *testing.T) {
+	var body string
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		auth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	e, err := New(Options{URL: srv.URL + "/api/v2/write?bucket=metrics", Format: FormatInflux, Token: "secret", Hostname: "pi"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	_ = e.Write(context.Background(), testSamples)
+	if err := e.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	if strings.Count(body, "\n") != 2 || auth != "Token secret" {
+		t.Errorf("Unexpected request: auth=%q body=%q", auth, body)
+	}
+	stats := e.Stats()
+	if stats.Sent != 2 || stats.Pending != 0 || strings.Contains(stats.URL, "bucket=metrics") {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestExporterFlushPrometheus(t *testing.T) {
+	var decoded []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		if r.Header.Get("Content-Encoding") != "snappy" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		decoded, _ = snappy.Decode(nil, b)
+	}))
+	defer srv.Close()
+
+	e, err := New(Options{URL: srv.URL, Format: FormatPrometheus})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	_ = e.Write(context.Background(), testSamples)
+	if err := e.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if !bytes.Equal(decoded, encodePrometheus(testSamples, "")) {
+		t.Error("Expected the snappy-decoded body to match the encoded WriteRequest")
+	}
+}
+
+func TestExporterRetryAndReject(t *testing.T) {
+	var calls atomic.Int32
+	var status atomic.Int32
+	status.Store(http.StatusServiceUnavailable)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer srv.Close()
+
+	e, err := New(Options{URL: srv.URL, Format: FormatInflux})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	_ = e.Write(context.Background(), testSamples)
+
+	// A cancelled context stops the backoff after the first attempt and keeps the batch
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := e.Flush(ctx); err == nil {
+		t.Fatal("Expected flush to fail while the endpoint is unavailable")
+	}
+	if stats := e.Stats(); stats.Pending != 2 || stats.Dropped != 0 {
+		t.Errorf("Expected the batch to stay queued, got %+v", stats)
+	}
+
+	// A 400 is not retried and drops the batch
+	status.Store(http.StatusBadRequest)
+	calls.Store(0)
+	if err := e.Flush(context.Background()); err == nil {
+		t.Fatal("Expected flush to fail on a rejected batch")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("Expected a rejected batch not to be retried, got %d attempts", calls.Load())
+	}
+	if stats := e.Stats(); stats.Pending != 0 || stats.Dropped != 2 {
+		t.Errorf("Expected the batch to be dropped, got %+v", stats)
+	}
+}
+
+func TestExporterQueueBound(t *testing.T) {
+	e, err := New(Options{URL: "http://localhost:8428/api/v1/write", Format: FormatPrometheus})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	batch := make([]history.Sample, maxPendingSamples)
+	_ = e.Write(context.Background(), batch)
+	_ = e.Write(context.Background(), testSamples)
+
+	if stats := e.Stats(); stats.Pending != maxPendingSamples || stats.Dropped != uint64(len(testSamples)) {
+		t.Errorf("Expected the oldest samples to be dropped, got pending=%d dropped=%d", stats.Pending, stats.Dropped)
+	}
+}
+
+func TestNewValidation(t *testing.T) {
+	if _, err := New(Options{URL: "http://localhost:8086/write", Format: "graphite"}); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+	if _, err := New(Options{URL: "localhost:8086", Format: FormatInflux}); err == nil {
+		t.Error("Expected an error for a URL without scheme")
+	}
+}
@@ -0,0 +1,122 @@
+// Package geoip enriches IP addresses with country and ASN data from user-supplied MMDB files.
+package geoip
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// Info holds the location and network owner of a single address
+type Info struct {
+	CountryCode string `json:"country_code,omitempty"`
+	Country     string `json:"country,omitempty"`
+	City        string `json:"city,omitempty"`
+	ASN         uint   `json:"asn,omitempty"`
+	ASOrg       string `json:"as_org,omitempty"`
+}
+
+// cityRecord matches the GeoLite2/DB-IP Country and City database layout
+type cityRecord struct {
+	Country struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"country"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+}
+
+// asnRecord matches the GeoLite2/DB-IP ASN database layout
+type asnRecord struct {
+	Number uint   `maxminddb:"autonomous_system_number"`
+	Org    string `maxminddb:"autonomous_system_organization"`
+}
+
+// DB wraps the optional country/city and ASN databases. A nil *DB performs no lookups.
+type DB struct {
+	location *maxminddb.Reader
+	asn      *maxminddb.Reader
+}
+
+// Open loads the given MMDB files; either path may be empty. It returns a nil *DB when
+// both paths are empty.
+func Open(locationPath, asnPath string) (*DB, error) {
+	if locationPath == "" && asnPath == "" {
+		return nil, nil
+	}
+
+	db := &DB{}
+	var err error
+	if locationPath != "" {
+		if db.location, err = maxminddb.Open(filepath.Clean(locationPath)); err != nil {
+			return nil, fmt.Errorf("failed to open GeoIP database %s: %w", locationPath, err)
+		}
+	}
+	if asnPath != "" {
+		if db.asn, err = maxminddb.Open(filepath.Clean(asnPath)); err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("failed to open ASN database %s: %w", asnPath, err)
+		}
+	}
+	return db, nil
+}
+
+// Close releases the underlying database files
+func (d *DB) Close() error {
+	if d == nil {
+		return nil
+	}
+	var errs []error
+	if d.location != nil {
+		errs = append(errs, d.location.Close())
+	}
+	if d.asn != nil {
+		errs = append(errs, d.asn.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// Lookup returns what the databases know about addr. Private, loopback, and other
+// non-public addresses are never looked up.
+func (d *DB) Lookup(addr string) (Info, bool) {
+	if d == nil {
+		return Info{}, false
+	}
+	ip := net.ParseIP(addr)
+	if !IsPublic(ip) {
+		return Info{}, false
+	}
+
+	var info Info
+	found := false
+	if d.location != nil {
+		var rec cityRecord
+		if err := d.location.Lookup(ip, &rec); err == nil && rec.Country.ISOCode != "" {
+			info.CountryCode = rec.Country.ISOCode
+			info.Country = rec.Country.Names["en"]
+			info.City = rec.City.Names["en"]
+			found = true
+		}
+	}
+	if d.asn != nil {
+		var rec asnRecord
+		if err := d.asn.Lookup(ip, &rec); err == nil && rec.Number != 0 {
+			info.ASN = rec.Number
+			info.ASOrg = rec.Org
+			found = true
+		}
+	}
+	return info, found
+}
+
+// IsPublic reports whether ip is a globally routable unicast address
+func IsPublic(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	return ip.IsGlobalUnicast() && !ip.IsPrivate()
+}
@@ -0,0 +1,54 @@
+package geoip
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpen(t *testing.T) {
+	db, err := Open("", "")
+	if err != nil || db != nil {
+		t.Errorf("Expected nil DB and no error for empty paths, got %v, %v", db, err)
+	}
+
+	if _, err := Open(filepath.Join(t.TempDir(), "missing.mmdb"), ""); err == nil {
+		t.Error("Expected error for missing database file")
+	}
+}
+
+func TestNilDBLookup(t *testing.T) {
+	var db *DB
+	if _, ok := db.Lookup("8.8.8.8"); ok {
+		t.Error("Expected nil DB lookup to find nothing")
+	}
+	if err := db.Close(); err != nil {
+		t.Errorf("Expected nil DB close to succeed, got %v", err)
+	}
+}
+
+func TestIsPublic(t *testing.T) {
+	tests := []struct {
+		addr     string
+		expected bool
+	}{
+		{"8.8.8.8", true},
+		{"2606:4700:4700::1111", true},
+		{"192.168.1.10", false},
+		{"10.0.0.1", false},
+		{"127.0.0.1", false},
+		{"169.254.1.1", false},
+		{"fe80::1", false},
+		{"::1", false},
+		{"0.0.0.0", false},
+		{"not-an-ip", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			if got := IsPublic(net.ParseIP(tt.addr)); got != tt.expected {
+				t.Errorf("IsPublic(%s) = %v, expected %v", tt.addr, got, tt.expected)
+			}
+		})
+	}
+}
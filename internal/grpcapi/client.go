@@ -0,0 +1,87 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// defaultCallTimeout bounds one unary call when the context has no deadline
+const defaultCallTimeout = 10 * time.Second
+
+// Client calls the SysMetrics service of another agent, as fleet tools do
+type Client struct {
+	addr string
+	conn *grpc.ClientConn
+	api  SysMetricsClient
+}
+
+// bearerToken sends a token as "authorization: Bearer <token>" metadata. The API runs
+// without TLS, so the token is allowed over plaintext connections.
+type bearerToken string
+
+func (t bearerToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{authorizationKey: "Bearer " + string(t)}, nil
+}
+
+func (t bearerToken) RequireTransportSecurity() bool { return false }
+
+// NewClient returns a client for the agent at addr (host:port). token, if set, is sent as
+// a bearer token. The connection is made on the first call.
+func NewClient(addr, token string) (*Client, error) {
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	if token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerToken(token)))
+	}
+	conn, err := grpc.NewClient(addr, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid agent address %q: %w", addr, err)
+	}
+	return &Client{addr: addr, conn: conn, api: NewSysMetricsClient(conn)}, nil
+}
+
+// Addr returns the agent's address
+func (c *Client) Addr() string {
+	return c.addr
+}
+
+// Close closes the connection to the agent
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// GetInfo describes the agent and reports its clock. sent and received bracket the call
+// for ClockOffset.
+func (c *Client) GetInfo(ctx context.Context) (info *GetInfoResponse, sent, received time.Time, err error) {
+	ctx, cancel := withCallTimeout(ctx)
+	defer cancel()
+	sent = time.Now()
+	info, err = c.api.GetInfo(ctx, &GetInfoRequest{})
+	received = time.Now()
+	if err != nil {
+		return nil, sent, received, fmt.Errorf("%s: %w", c.addr, err)
+	}
+	return info, sent, received, nil
+}
+
+// GetSamples takes one snapshot of the agent's metrics, limited to metrics if given
+func (c *Client) GetSamples(ctx context.Context, metrics []string) ([]*Sample, error) {
+	ctx, cancel := withCallTimeout(ctx)
+	defer cancel()
+	resp, err := c.api.GetSamples(ctx, &GetSamplesRequest{Metrics: metrics})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", c.addr, err)
+	}
+	return resp.GetSamples(), nil
+}
+
+// withCallTimeout applies defaultCallTimeout when ctx has no deadline
+func withCallTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, defaultCallTimeout)
+}
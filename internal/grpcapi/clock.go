@@ -0,0 +1,18 @@
+package grpcapi
+
+import "time"
+
+// ClockOffset estimates how far an agent's clock runs ahead of the local clock from one
+// GetInfo call, as NTP does: the agent's ServerTimeMS is compared with the midpoint of the
+// local send and receive times. The estimate is accurate to within half the round trip,
+// so callers comparing hosts should take the sample with the smallest round trip.
+func ClockOffset(sent, received time.Time, serverTimeMS int64) (offset, roundTrip time.Duration) {
+	roundTrip = received.Sub(sent)
+	midpoint := sent.Add(roundTrip / 2)
+	return time.UnixMilli(serverTimeMS).Sub(midpoint), roundTrip
+}
+
+// Normalize maps a timestamp taken on an agent's clock onto the local clock
+func Normalize(ts time.Time, offset time.Duration) time.Time {
+	return ts.Add(-offset)
+}
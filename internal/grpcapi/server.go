@@ -0,0 +1,262 @@
+// Package grpcapi serves the metrics collectors and read-only MCP tools over gRPC, so
+// dashboards and fleet controllers can use the same binary without speaking MCP. The
+// messages and service stubs are generated from sysmetrics.proto.
+package grpcapi
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative sysmetrics.proto
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"sysmetrics-mcp/internal/config"
+	"sysmetrics-mcp/internal/history"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// ServiceName is the fully qualified gRPC service name
+const ServiceName = "sysmetrics.v1.SysMetrics"
+
+// Server limits
+const (
+	defaultWatchInterval = 10 * time.Second
+	minWatchInterval     = time.Second
+	shutdownTimeout      = 5 * time.Second
+	authorizationKey     = "authorization"
+)
+
+// Backend provides the metrics and tools the API serves
+type Backend interface {
+	// CollectSamples takes one snapshot of the core host metrics
+	CollectSamples(ctx context.Context) []history.Sample
+	// Tools lists the registered MCP tools
+	Tools() []string
+	// ReadOnly reports whether a tool leaves the host and server as they were. Tools that
+	// do not stay reachable over MCP only, where the client asks the user before calling them.
+	ReadOnly(name string) bool
+	// CallTool runs a registered tool through the MCP middleware chain
+	CallTool(ctx context.Context, caller, name string, arguments map[string]interface{}) (*mcp.CallToolResult, error)
+}
+
+// Options configures a Server
+type Options struct {
+	Addr string
+	// Token, if set, must be sent as "authorization: Bearer <token>" metadata
+	Token   string
+	Backend Backend
+	// Logger, if set, receives request failures
+	Logger *slog.Logger
+}
+
+// Server is a gRPC server bound to its address
+type Server struct {
+	opts     Options
+	listener net.Listener
+	grpc     *grpc.Server
+}
+
+// service implements the SysMetrics RPCs
+type service struct {
+	UnimplementedSysMetricsServer
+	backend Backend
+}
+
+// Listen binds the server's address; call Run to serve
+func Listen(opts Options) (*Server, error) {
+	if opts.Backend == nil {
+		return nil, errors.New("grpcapi: a backend is required")
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	ln, err := net.Listen("tcp", opts.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", opts.Addr, err)
+	}
+	s := &Server{opts: opts, listener: ln}
+	s.grpc = grpc.NewServer(grpc.UnaryInterceptor(s.unaryInterceptor), grpc.StreamInterceptor(s.streamInterceptor))
+	RegisterSysMetricsServer(s.grpc, &service{backend: opts.Backend})
+	return s, nil
+}
+
+// Addr returns the bound address
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Run serves until ctx is cancelled, then stops accepting calls and waits briefly for
+// running ones
+func (s *Server) Run(ctx context.Context) error {
+	errc := make(chan error, 1)
+	go func() { errc <- s.grpc.Serve(s.listener) }()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	stopped := make(chan struct{})
+	go func() {
+		s.grpc.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(shutdownTimeout):
+		// Streams such as WatchSamples only end when their connection closes
+		s.grpc.Stop()
+	}
+	return <-errc
+}
+
+// unaryInterceptor authorizes a unary call and logs its failure
+func (s *Server) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, s.logFailure(info.FullMethod, err)
+	}
+	resp, err := handler(ctx, req)
+	return resp, s.logFailure(info.FullMethod, err)
+}
+
+// streamInterceptor authorizes a streaming call and logs its failure
+func (s *Server) streamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := s.authorize(ss.Context()); err != nil {
+		return s.logFailure(info.FullMethod, err)
+	}
+	return s.logFailure(info.FullMethod, handler(srv, ss))
+}
+
+// authorize checks the bearer token when one is configured
+func (s *Server) authorize(ctx context.Context) error {
+	if s.opts.Token == "" {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get(authorizationKey) {
+		got, ok := strings.CutPrefix(value, "Bearer ")
+		if ok && subtle.ConstantTimeCompare([]byte(got), []byte(s.opts.Token)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "missing or invalid bearer token")
+}
+
+// logFailure logs a failed call and returns err
+func (s *Server) logFailure(method string, err error) error {
+	if err != nil {
+		s.opts.Logger.Warn("grpc call failed", "method", method, "code", status.Code(err), "error", err)
+	}
+	return err
+}
+
+// GetInfo describes this server and lists the tools CallTool accepts
+func (svc *service) GetInfo(ctx context.Context, req *GetInfoRequest) (*GetInfoResponse, error) {
+	hostname, _ := os.Hostname()
+	return &GetInfoResponse{
+		Name:         config.ServerName,
+		Version:      config.ServerVersion,
+		Hostname:     hostname,
+		Tools:        svc.remoteTools(),
+		ServerTimeMs: time.Now().UnixMilli(),
+	}, nil
+}
+
+// GetSamples takes one snapshot of the requested metrics
+func (svc *service) GetSamples(ctx context.Context, req *GetSamplesRequest) (*GetSamplesResponse, error) {
+	return svc.snapshot(ctx, req.GetMetrics()), nil
+}
+
+// WatchSamples sends a snapshot every interval until the client cancels
+func (svc *service) WatchSamples(req *WatchSamplesRequest, stream grpc.ServerStreamingServer[GetSamplesResponse]) error {
+	interval := defaultWatchInterval
+	if req.GetIntervalSeconds() > 0 {
+		interval = max(time.Duration(req.GetIntervalSeconds())*time.Second, minWatchInterval)
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	ctx := stream.Context()
+	for {
+		if err := stream.Send(svc.snapshot(ctx, req.GetMetrics())); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// CallTool runs a read-only tool for a remote caller
+func (svc *service) CallTool(ctx context.Context, req *CallToolRequest) (*CallToolResponse, error) {
+	if !slices.Contains(svc.backend.Tools(), req.GetTool()) {
+		return nil, status.Errorf(codes.NotFound, "unknown or unregistered tool %q", req.GetTool())
+	}
+	if !svc.backend.ReadOnly(req.GetTool()) {
+		return nil, status.Errorf(codes.PermissionDenied, "%s changes the host and is only available over MCP", req.GetTool())
+	}
+	args := map[string]interface{}{}
+	if strings.TrimSpace(req.GetArgumentsJson()) != "" {
+		if err := json.Unmarshal([]byte(req.GetArgumentsJson()), &args); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "arguments_json must be a JSON object: %v", err)
+		}
+	}
+	caller := "grpc"
+	if p, ok := peer.FromContext(ctx); ok {
+		caller = "grpc/" + p.Addr.String()
+	}
+	result, err := svc.backend.CallTool(ctx, caller, req.GetTool(), args)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%s failed: %v", req.GetTool(), err)
+	}
+	var text strings.Builder
+	for _, c := range result.Content {
+		if t, ok := c.(mcp.TextContent); ok {
+			text.WriteString(t.Text)
+		}
+	}
+	return &CallToolResponse{Result: text.String(), IsError: result.IsError}, nil
+}
+
+// remoteTools lists the tools CallTool accepts
+func (svc *service) remoteTools() []string {
+	var tools []string
+	for _, name := range svc.backend.Tools() {
+		if svc.backend.ReadOnly(name) {
+			tools = append(tools, name)
+		}
+	}
+	return tools
+}
+
+// snapshot collects samples, keeping only the requested metrics
+func (svc *service) snapshot(ctx context.Context, metrics []string) *GetSamplesResponse {
+	resp := &GetSamplesResponse{}
+	for _, sample := range svc.backend.CollectSamples(ctx) {
+		if len(metrics) > 0 && !slices.Contains(metrics, sample.Metric) {
+			continue
+		}
+		resp.Samples = append(resp.Samples, &Sample{
+			Metric:      sample.Metric,
+			Labels:      sample.Labels,
+			Value:       sample.Value,
+			TimestampMs: sample.Time.UnixMilli(),
+		})
+	}
+	return resp
+}
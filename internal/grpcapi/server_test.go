@@ -0,0 +1,232 @@
+package grpcapi
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"sysmetrics-mcp/internal/history"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// fakeBackend serves fixed samples and echoes tool arguments
+type fakeBackend struct {
+	caller string
+}
+
+func (b *fakeBackend) CollectSamples(ctx context.Context) []history.Sample {
+	now := time.UnixMilli(1700000000000)
+	return []history.Sample{
+		{Time: now, Metric: "cpu_percent", Value: 12.5},
+		{Time: now, Metric: "disk_used_percent", Labels: map[string]string{"mount": "/"}, Value: 40},
+	}
+}
+
+func (b *fakeBackend) Tools() []string {
+	return []string{"get_cpu_info", "manage_process"}
+}
+
+func (b *fakeBackend) ReadOnly(name string) bool {
+	return name != "manage_process"
+}
+
+func (b *fakeBackend) CallTool(ctx context.Context, caller, name string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	b.caller = caller
+	if arguments["fail"] == true {
+		return mcp.NewToolResultError("Failed to read CPU info"), nil
+	}
+	return mcp.NewToolResultText(`{"ok":true}`), nil
+}
+
+// startServer runs a server on a free loopback port until the test ends
+func startServer(t *testing.T, token string) (*Server, *fakeBackend) {
+	t.Helper()
+	backend := &fakeBackend{}
+	s, err := Listen(Options{Addr: "127.0.0.1:0", Token: token, Backend: backend})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	})
+	return s, backend
+}
+
+// dial connects a generated client to s, sending token if set
+func dial(t *testing.T, s *Server, token string) (SysMetricsClient, *grpc.ClientConn) {
+	t.Helper()
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	if token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerToken(token)))
+	}
+	conn, err := grpc.NewClient(s.Addr().String(), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return NewSysMetricsClient(conn), conn
+}
+
+func TestServerUnaryCalls(t *testing.T) {
+	s, backend := startServer(t, "")
+	client, _ := dial(t, s, "")
+	ctx := context.Background()
+
+	sent := time.Now()
+	info, err := client.GetInfo(ctx, &GetInfoRequest{})
+	received := time.Now()
+	if err != nil {
+		t.Fatalf("GetInfo() error = %v", err)
+	}
+	if info.GetName() == "" || len(info.GetTools()) != 1 || info.GetTools()[0] != "get_cpu_info" {
+		t.Errorf("Unexpected info: %v", info)
+	}
+	// Same clock on both ends: the offset is within the round trip
+	if offset, rtt := ClockOffset(sent, received, info.GetServerTimeMs()); offset.Abs() > rtt/2+time.Millisecond {
+		t.Errorf("ClockOffset() = %v with round trip %v, expected about 0", offset, rtt)
+	}
+
+	samples, err := client.GetSamples(ctx, &GetSamplesRequest{Metrics: []string{"disk_used_percent"}})
+	if err != nil {
+		t.Fatalf("GetSamples() error = %v", err)
+	}
+	if len(samples.GetSamples()) != 1 {
+		t.Fatalf("Expected 1 filtered sample, got %v", samples.GetSamples())
+	}
+	if got := samples.GetSamples()[0]; got.GetValue() != 40 || got.GetLabels()["mount"] != "/" || got.GetTimestampMs() != 1700000000000 {
+		t.Errorf("Unexpected sample: %v", got)
+	}
+
+	result, err := client.CallTool(ctx, &CallToolRequest{Tool: "get_cpu_info", ArgumentsJson: `{"per_cpu":true}`})
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	if result.GetResult() != `{"ok":true}` || result.GetIsError() {
+		t.Errorf("Unexpected tool result: %v", result)
+	}
+	if !strings.HasPrefix(backend.caller, "grpc/127.0.0.1:") {
+		t.Errorf("Expected the caller to name the gRPC peer, got %q", backend.caller)
+	}
+
+	result, err = client.CallTool(ctx, &CallToolRequest{Tool: "get_cpu_info", ArgumentsJson: `{"fail":true}`})
+	if err != nil || !result.GetIsError() {
+		t.Errorf("Expected a tool error to be returned as is_error, got %v, %v", result, err)
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	s, _ := startServer(t, "secret")
+	ctx := context.Background()
+	authorized, conn := dial(t, s, "secret")
+	anonymous, _ := dial(t, s, "")
+	wrong, _ := dial(t, s, "guess")
+
+	tests := []struct {
+		name string
+		call func() error
+		want codes.Code
+	}{
+		{"missing token", func() error { _, err := anonymous.GetInfo(ctx, &GetInfoRequest{}); return err }, codes.Unauthenticated},
+		{"wrong token", func() error { _, err := wrong.GetInfo(ctx, &GetInfoRequest{}); return err }, codes.Unauthenticated},
+		{"unknown method", func() error {
+			return conn.Invoke(ctx, "/"+ServiceName+"/Reboot", &GetInfoRequest{}, &GetInfoResponse{})
+		}, codes.Unimplemented},
+		{"denied tool", func() error {
+			_, err := authorized.CallTool(ctx, &CallToolRequest{Tool: "manage_process"})
+			return err
+		}, codes.PermissionDenied},
+		{"unknown tool", func() error {
+			_, err := authorized.CallTool(ctx, &CallToolRequest{Tool: "get_nothing"})
+			return err
+		}, codes.NotFound},
+		{"bad arguments", func() error {
+			_, err := authorized.CallTool(ctx, &CallToolRequest{Tool: "get_cpu_info", ArgumentsJson: "[1]"})
+			return err
+		}, codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := status.Code(tt.call()); got != tt.want {
+				t.Errorf("status = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServerWatchSamples(t *testing.T) {
+	s, _ := startServer(t, "")
+	client, _ := dial(t, s, "")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stream, err := client.WatchSamples(ctx, &WatchSamplesRequest{Metrics: []string{"cpu_percent"}, IntervalSeconds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		resp, err := stream.Recv()
+		if err != nil || len(resp.GetSamples()) != 1 || resp.GetSamples()[0].GetMetric() != "cpu_percent" {
+			t.Fatalf("Unexpected snapshot %d: %v, %v", i, resp, err)
+		}
+	}
+}
+
+func TestClient(t *testing.T) {
+	s, _ := startServer(t, "secret")
+	ctx := context.Background()
+
+	c, err := NewClient(s.Addr().String(), "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	info, sent, received, err := c.GetInfo(ctx)
+	if err != nil || info.GetServerTimeMs() == 0 || received.Before(sent) {
+		t.Fatalf("GetInfo() = %v, %v", info, err)
+	}
+	samples, err := c.GetSamples(ctx, []string{"disk_used_percent"})
+	if err != nil || len(samples) != 1 || samples[0].GetLabels()["mount"] != "/" || samples[0].GetValue() != 40 {
+		t.Errorf("GetSamples() = %v, %v", samples, err)
+	}
+
+	guess, err := NewClient(s.Addr().String(), "guess")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer guess.Close()
+	if _, err := guess.GetSamples(ctx, nil); status.Code(err) != codes.Unauthenticated || !strings.Contains(err.Error(), s.Addr().String()) {
+		t.Errorf("Expected an unauthenticated error naming the agent, got %v", err)
+	}
+}
+
+func TestClockOffset(t *testing.T) {
+	sent := time.UnixMilli(1700000000000)
+	received := sent.Add(200 * time.Millisecond)
+
+	// The agent answered at the midpoint by its clock, which runs 5s fast
+	offset, rtt := ClockOffset(sent, received, sent.Add(100*time.Millisecond+5*time.Second).UnixMilli())
+	if offset != 5*time.Second || rtt != 200*time.Millisecond {
+		t.Errorf("ClockOffset() = %v, %v; want 5s, 200ms", offset, rtt)
+	}
+	agentTime := time.UnixMilli(1700000060000)
+	if got := Normalize(agentTime, offset); !got.Equal(agentTime.Add(-5 * time.Second)) {
+		t.Errorf("Normalize() = %v, want 5s earlier", got)
+	}
+
+	// A slow clock gives a negative offset
+	if offset, _ := ClockOffset(sent, received, sent.Add(-time.Minute).UnixMilli()); offset != -time.Minute-100*time.Millisecond {
+		t.Errorf("ClockOffset() = %v for a slow agent", offset)
+	}
+}
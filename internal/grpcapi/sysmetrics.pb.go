@@ -0,0 +1,566 @@
+// SysMetrics gRPC API, served by sysmetrics-mcp with --grpc-addr.
+//
+// sysmetrics.pb.go and sysmetrics_grpc.pb.go are generated from this file with
+// go generate ./internal/grpcapi. Use it with any gRPC client, e.g.:
+//
+//   grpcurl -plaintext -proto sysmetrics.proto 127.0.0.1:50051 sysmetrics.v1.SysMetrics/GetSamples
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: sysmetrics.proto
+
+package grpcapi
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetInfoRequest) Reset() {
+	*x = GetInfoRequest{}
+	mi := &file_sysmetrics_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetInfoRequest) ProtoMessage() {}
+
+func (x *GetInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sysmetrics_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetInfoRequest) Descriptor() ([]byte, []int) {
+	return file_sysmetrics_proto_rawDescGZIP(), []int{0}
+}
+
+type GetInfoResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Name     string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Version  string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Hostname string                 `protobuf:"bytes,3,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	Tools    []string               `protobuf:"bytes,4,rep,name=tools,proto3" json:"tools,omitempty"`
+	// The agent's wall clock when it built the response, in Unix milliseconds. Compare it
+	// with the midpoint of the call to measure clock skew.
+	ServerTimeMs  int64 `protobuf:"varint,5,opt,name=server_time_ms,json=serverTimeMs,proto3" json:"server_time_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetInfoResponse) Reset() {
+	*x = GetInfoResponse{}
+	mi := &file_sysmetrics_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetInfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetInfoResponse) ProtoMessage() {}
+
+func (x *GetInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sysmetrics_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetInfoResponse.ProtoReflect.Descriptor instead.
+func (*GetInfoResponse) Descriptor() ([]byte, []int) {
+	return file_sysmetrics_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetInfoResponse) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *GetInfoResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *GetInfoResponse) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *GetInfoResponse) GetTools() []string {
+	if x != nil {
+		return x.Tools
+	}
+	return nil
+}
+
+func (x *GetInfoResponse) GetServerTimeMs() int64 {
+	if x != nil {
+		return x.ServerTimeMs
+	}
+	return 0
+}
+
+type GetSamplesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Metric names to return, e.g. cpu_percent; empty returns all.
+	Metrics       []string `protobuf:"bytes,1,rep,name=metrics,proto3" json:"metrics,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSamplesRequest) Reset() {
+	*x = GetSamplesRequest{}
+	mi := &file_sysmetrics_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSamplesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSamplesRequest) ProtoMessage() {}
+
+func (x *GetSamplesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sysmetrics_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSamplesRequest.ProtoReflect.Descriptor instead.
+func (*GetSamplesRequest) Descriptor() ([]byte, []int) {
+	return file_sysmetrics_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetSamplesRequest) GetMetrics() []string {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+type Sample struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Metric        string                 `protobuf:"bytes,1,opt,name=metric,proto3" json:"metric,omitempty"`
+	Labels        map[string]string      `protobuf:"bytes,2,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Value         float64                `protobuf:"fixed64,3,opt,name=value,proto3" json:"value,omitempty"`
+	TimestampMs   int64                  `protobuf:"varint,4,opt,name=timestamp_ms,json=timestampMs,proto3" json:"timestamp_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Sample) Reset() {
+	*x = Sample{}
+	mi := &file_sysmetrics_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Sample) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Sample) ProtoMessage() {}
+
+func (x *Sample) ProtoReflect() protoreflect.Message {
+	mi := &file_sysmetrics_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Sample.ProtoReflect.Descriptor instead.
+func (*Sample) Descriptor() ([]byte, []int) {
+	return file_sysmetrics_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Sample) GetMetric() string {
+	if x != nil {
+		return x.Metric
+	}
+	return ""
+}
+
+func (x *Sample) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Sample) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *Sample) GetTimestampMs() int64 {
+	if x != nil {
+		return x.TimestampMs
+	}
+	return 0
+}
+
+type GetSamplesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Samples       []*Sample              `protobuf:"bytes,1,rep,name=samples,proto3" json:"samples,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSamplesResponse) Reset() {
+	*x = GetSamplesResponse{}
+	mi := &file_sysmetrics_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSamplesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSamplesResponse) ProtoMessage() {}
+
+func (x *GetSamplesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sysmetrics_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSamplesResponse.ProtoReflect.Descriptor instead.
+func (*GetSamplesResponse) Descriptor() ([]byte, []int) {
+	return file_sysmetrics_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetSamplesResponse) GetSamples() []*Sample {
+	if x != nil {
+		return x.Samples
+	}
+	return nil
+}
+
+type WatchSamplesRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Metrics []string               `protobuf:"bytes,1,rep,name=metrics,proto3" json:"metrics,omitempty"`
+	// Seconds between snapshots (default 10, minimum 1).
+	IntervalSeconds uint32 `protobuf:"varint,2,opt,name=interval_seconds,json=intervalSeconds,proto3" json:"interval_seconds,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *WatchSamplesRequest) Reset() {
+	*x = WatchSamplesRequest{}
+	mi := &file_sysmetrics_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchSamplesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchSamplesRequest) ProtoMessage() {}
+
+func (x *WatchSamplesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sysmetrics_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchSamplesRequest.ProtoReflect.Descriptor instead.
+func (*WatchSamplesRequest) Descriptor() ([]byte, []int) {
+	return file_sysmetrics_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *WatchSamplesRequest) GetMetrics() []string {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+func (x *WatchSamplesRequest) GetIntervalSeconds() uint32 {
+	if x != nil {
+		return x.IntervalSeconds
+	}
+	return 0
+}
+
+type CallToolRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Tool  string                 `protobuf:"bytes,1,opt,name=tool,proto3" json:"tool,omitempty"`
+	// Tool arguments as a JSON object; empty for none.
+	ArgumentsJson string `protobuf:"bytes,2,opt,name=arguments_json,json=argumentsJson,proto3" json:"arguments_json,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CallToolRequest) Reset() {
+	*x = CallToolRequest{}
+	mi := &file_sysmetrics_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CallToolRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CallToolRequest) ProtoMessage() {}
+
+func (x *CallToolRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sysmetrics_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CallToolRequest.ProtoReflect.Descriptor instead.
+func (*CallToolRequest) Descriptor() ([]byte, []int) {
+	return file_sysmetrics_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CallToolRequest) GetTool() string {
+	if x != nil {
+		return x.Tool
+	}
+	return ""
+}
+
+func (x *CallToolRequest) GetArgumentsJson() string {
+	if x != nil {
+		return x.ArgumentsJson
+	}
+	return ""
+}
+
+type CallToolResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The tool's JSON result, or its error message when is_error is set.
+	Result        string `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	IsError       bool   `protobuf:"varint,2,opt,name=is_error,json=isError,proto3" json:"is_error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CallToolResponse) Reset() {
+	*x = CallToolResponse{}
+	mi := &file_sysmetrics_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CallToolResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CallToolResponse) ProtoMessage() {}
+
+func (x *CallToolResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sysmetrics_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CallToolResponse.ProtoReflect.Descriptor instead.
+func (*CallToolResponse) Descriptor() ([]byte, []int) {
+	return file_sysmetrics_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CallToolResponse) GetResult() string {
+	if x != nil {
+		return x.Result
+	}
+	return ""
+}
+
+func (x *CallToolResponse) GetIsError() bool {
+	if x != nil {
+		return x.IsError
+	}
+	return false
+}
+
+var File_sysmetrics_proto protoreflect.FileDescriptor
+
+const file_sysmetrics_proto_rawDesc = "" +
+	"\n" +
+	"\x10sysmetrics.proto\x12\rsysmetrics.v1\"\x10\n" +
+	"\x0eGetInfoRequest\"\x97\x01\n" +
+	"\x0fGetInfoResponse\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x1a\n" +
+	"\bhostname\x18\x03 \x01(\tR\bhostname\x12\x14\n" +
+	"\x05tools\x18\x04 \x03(\tR\x05tools\x12$\n" +
+	"\x0eserver_time_ms\x18\x05 \x01(\x03R\fserverTimeMs\"-\n" +
+	"\x11GetSamplesRequest\x12\x18\n" +
+	"\ametrics\x18\x01 \x03(\tR\ametrics\"\xcf\x01\n" +
+	"\x06Sample\x12\x16\n" +
+	"\x06metric\x18\x01 \x01(\tR\x06metric\x129\n" +
+	"\x06labels\x18\x02 \x03(\v2!.sysmetrics.v1.Sample.LabelsEntryR\x06labels\x12\x14\n" +
+	"\x05value\x18\x03 \x01(\x01R\x05value\x12!\n" +
+	"\ftimestamp_ms\x18\x04 \x01(\x03R\vtimestampMs\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"E\n" +
+	"\x12GetSamplesResponse\x12/\n" +
+	"\asamples\x18\x01 \x03(\v2\x15.sysmetrics.v1.SampleR\asamples\"Z\n" +
+	"\x13WatchSamplesRequest\x12\x18\n" +
+	"\ametrics\x18\x01 \x03(\tR\ametrics\x12)\n" +
+	"\x10interval_seconds\x18\x02 \x01(\rR\x0fintervalSeconds\"L\n" +
+	"\x0fCallToolRequest\x12\x12\n" +
+	"\x04tool\x18\x01 \x01(\tR\x04tool\x12%\n" +
+	"\x0earguments_json\x18\x02 \x01(\tR\rargumentsJson\"E\n" +
+	"\x10CallToolResponse\x12\x16\n" +
+	"\x06result\x18\x01 \x01(\tR\x06result\x12\x19\n" +
+	"\bis_error\x18\x02 \x01(\bR\aisError2\xcf\x02\n" +
+	"\n" +
+	"SysMetrics\x12H\n" +
+	"\aGetInfo\x12\x1d.sysmetrics.v1.GetInfoRequest\x1a\x1e.sysmetrics.v1.GetInfoResponse\x12Q\n" +
+	"\n" +
+	"GetSamples\x12 .sysmetrics.v1.GetSamplesRequest\x1a!.sysmetrics.v1.GetSamplesResponse\x12W\n" +
+	"\fWatchSamples\x12\".sysmetrics.v1.WatchSamplesRequest\x1a!.sysmetrics.v1.GetSamplesResponse0\x01\x12K\n" +
+	"\bCallTool\x12\x1e.sysmetrics.v1.CallToolRequest\x1a\x1f.sysmetrics.v1.CallToolResponseB!Z\x1fsysmetrics-mcp/internal/grpcapib\x06proto3"
+
+var (
+	file_sysmetrics_proto_rawDescOnce sync.Once
+	file_sysmetrics_proto_rawDescData []byte
+)
+
+func file_sysmetrics_proto_rawDescGZIP() []byte {
+	file_sysmetrics_proto_rawDescOnce.Do(func() {
+		file_sysmetrics_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_sysmetrics_proto_rawDesc), len(file_sysmetrics_proto_rawDesc)))
+	})
+	return file_sysmetrics_proto_rawDescData
+}
+
+var file_sysmetrics_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_sysmetrics_proto_goTypes = []any{
+	(*GetInfoRequest)(nil),      // 0: sysmetrics.v1.GetInfoRequest
+	(*GetInfoResponse)(nil),     // 1: sysmetrics.v1.GetInfoResponse
+	(*GetSamplesRequest)(nil),   // 2: sysmetrics.v1.GetSamplesRequest
+	(*Sample)(nil),              // 3: sysmetrics.v1.Sample
+	(*GetSamplesResponse)(nil),  // 4: sysmetrics.v1.GetSamplesResponse
+	(*WatchSamplesRequest)(nil), // 5: sysmetrics.v1.WatchSamplesRequest
+	(*CallToolRequest)(nil),     // 6: sysmetrics.v1.CallToolRequest
+	(*CallToolResponse)(nil),    // 7: sysmetrics.v1.CallToolResponse
+	nil,                         // 8: sysmetrics.v1.Sample.LabelsEntry
+}
+var file_sysmetrics_proto_depIdxs = []int32{
+	8, // 0: sysmetrics.v1.Sample.labels:type_name -> sysmetrics.v1.Sample.LabelsEntry
+	3, // 1: sysmetrics.v1.GetSamplesResponse.samples:type_name -> sysmetrics.v1.Sample
+	0, // 2: sysmetrics.v1.SysMetrics.GetInfo:input_type -> sysmetrics.v1.GetInfoRequest
+	2, // 3: sysmetrics.v1.SysMetrics.GetSamples:input_type -> sysmetrics.v1.GetSamplesRequest
+	5, // 4: sysmetrics.v1.SysMetrics.WatchSamples:input_type -> sysmetrics.v1.WatchSamplesRequest
+	6, // 5: sysmetrics.v1.SysMetrics.CallTool:input_type -> sysmetrics.v1.CallToolRequest
+	1, // 6: sysmetrics.v1.SysMetrics.GetInfo:output_type -> sysmetrics.v1.GetInfoResponse
+	4, // 7: sysmetrics.v1.SysMetrics.GetSamples:output_type -> sysmetrics.v1.GetSamplesResponse
+	4, // 8: sysmetrics.v1.SysMetrics.WatchSamples:output_type -> sysmetrics.v1.GetSamplesResponse
+	7, // 9: sysmetrics.v1.SysMetrics.CallTool:output_type -> sysmetrics.v1.CallToolResponse
+	6, // [6:10] is the sub-list for method output_type
+	2, // [2:6] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_sysmetrics_proto_init() }
+func file_sysmetrics_proto_init() {
+	if File_sysmetrics_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_sysmetrics_proto_rawDesc), len(file_sysmetrics_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_sysmetrics_proto_goTypes,
+		DependencyIndexes: file_sysmetrics_proto_depIdxs,
+		MessageInfos:      file_sysmetrics_proto_msgTypes,
+	}.Build()
+	File_sysmetrics_proto = out.File
+	file_sysmetrics_proto_goTypes = nil
+	file_sysmetrics_proto_depIdxs = nil
+}
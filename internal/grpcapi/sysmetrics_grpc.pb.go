@@ -0,0 +1,254 @@
+// SysMetrics gRPC API, served by sysmetrics-mcp with --grpc-addr.
+//
+// sysmetrics.pb.go and sysmetrics_grpc.pb.go are generated from this file with
+// go generate ./internal/grpcapi. Use it with any gRPC client, e.g.:
+//
+//   grpcurl -plaintext -proto sysmetrics.proto 127.0.0.1:50051 sysmetrics.v1.SysMetrics/GetSamples
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: sysmetrics.proto
+
+package grpcapi
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	SysMetrics_GetInfo_FullMethodName      = "/sysmetrics.v1.SysMetrics/GetInfo"
+	SysMetrics_GetSamples_FullMethodName   = "/sysmetrics.v1.SysMetrics/GetSamples"
+	SysMetrics_WatchSamples_FullMethodName = "/sysmetrics.v1.SysMetrics/WatchSamples"
+	SysMetrics_CallTool_FullMethodName     = "/sysmetrics.v1.SysMetrics/CallTool"
+)
+
+// SysMetricsClient is the client API for SysMetrics service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SysMetricsClient interface {
+	// GetInfo returns the server version and the tools CallTool accepts.
+	GetInfo(ctx context.Context, in *GetInfoRequest, opts ...grpc.CallOption) (*GetInfoResponse, error)
+	// GetSamples takes one snapshot of the core host metrics recorded by the sampler.
+	GetSamples(ctx context.Context, in *GetSamplesRequest, opts ...grpc.CallOption) (*GetSamplesResponse, error)
+	// WatchSamples streams a snapshot every interval until the client cancels.
+	WatchSamples(ctx context.Context, in *WatchSamplesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[GetSamplesResponse], error)
+	// CallTool runs a read-only MCP tool and returns its JSON result.
+	CallTool(ctx context.Context, in *CallToolRequest, opts ...grpc.CallOption) (*CallToolResponse, error)
+}
+
+type sysMetricsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSysMetricsClient(cc grpc.ClientConnInterface) SysMetricsClient {
+	return &sysMetricsClient{cc}
+}
+
+func (c *sysMetricsClient) GetInfo(ctx context.Context, in *GetInfoRequest, opts ...grpc.CallOption) (*GetInfoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetInfoResponse)
+	err := c.cc.Invoke(ctx, SysMetrics_GetInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sysMetricsClient) GetSamples(ctx context.Context, in *GetSamplesRequest, opts ...grpc.CallOption) (*GetSamplesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetSamplesResponse)
+	err := c.cc.Invoke(ctx, SysMetrics_GetSamples_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sysMetricsClient) WatchSamples(ctx context.Context, in *WatchSamplesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[GetSamplesResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SysMetrics_ServiceDesc.Streams[0], SysMetrics_WatchSamples_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchSamplesRequest, GetSamplesResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SysMetrics_WatchSamplesClient = grpc.ServerStreamingClient[GetSamplesResponse]
+
+func (c *sysMetricsClient) CallTool(ctx context.Context, in *CallToolRequest, opts ...grpc.CallOption) (*CallToolResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CallToolResponse)
+	err := c.cc.Invoke(ctx, SysMetrics_CallTool_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SysMetricsServer is the server API for SysMetrics service.
+// All implementations must embed UnimplementedSysMetricsServer
+// for forward compatibility.
+type SysMetricsServer interface {
+	// GetInfo returns the server version and the tools CallTool accepts.
+	GetInfo(context.Context, *GetInfoRequest) (*GetInfoResponse, error)
+	// GetSamples takes one snapshot of the core host metrics recorded by the sampler.
+	GetSamples(context.Context, *GetSamplesRequest) (*GetSamplesResponse, error)
+	// WatchSamples streams a snapshot every interval until the client cancels.
+	WatchSamples(*WatchSamplesRequest, grpc.ServerStreamingServer[GetSamplesResponse]) error
+	// CallTool runs a read-only MCP tool and returns its JSON result.
+	CallTool(context.Context, *CallToolRequest) (*CallToolResponse, error)
+	mustEmbedUnimplementedSysMetricsServer()
+}
+
+// UnimplementedSysMetricsServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSysMetricsServer struct{}
+
+func (UnimplementedSysMetricsServer) GetInfo(context.Context, *GetInfoRequest) (*GetInfoResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetInfo not implemented")
+}
+func (UnimplementedSysMetricsServer) GetSamples(context.Context, *GetSamplesRequest) (*GetSamplesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetSamples not implemented")
+}
+func (UnimplementedSysMetricsServer) WatchSamples(*WatchSamplesRequest, grpc.ServerStreamingServer[GetSamplesResponse]) error {
+	return status.Error(codes.Unimplemented, "method WatchSamples not implemented")
+}
+func (UnimplementedSysMetricsServer) CallTool(context.Context, *CallToolRequest) (*CallToolResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CallTool not implemented")
+}
+func (UnimplementedSysMetricsServer) mustEmbedUnimplementedSysMetricsServer() {}
+func (UnimplementedSysMetricsServer) testEmbeddedByValue()                    {}
+
+// UnsafeSysMetricsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SysMetricsServer will
+// result in compilation errors.
+type UnsafeSysMetricsServer interface {
+	mustEmbedUnimplementedSysMetricsServer()
+}
+
+func RegisterSysMetricsServer(s grpc.ServiceRegistrar, srv SysMetricsServer) {
+	// If the following call panics, it indicates UnimplementedSysMetricsServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SysMetrics_ServiceDesc, srv)
+}
+
+func _SysMetrics_GetInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SysMetricsServer).GetInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SysMetrics_GetInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SysMetricsServer).GetInfo(ctx, req.(*GetInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SysMetrics_GetSamples_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSamplesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SysMetricsServer).GetSamples(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SysMetrics_GetSamples_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SysMetricsServer).GetSamples(ctx, req.(*GetSamplesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SysMetrics_WatchSamples_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchSamplesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SysMetricsServer).WatchSamples(m, &grpc.GenericServerStream[WatchSamplesRequest, GetSamplesResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SysMetrics_WatchSamplesServer = grpc.ServerStreamingServer[GetSamplesResponse]
+
+func _SysMetrics_CallTool_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CallToolRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SysMetricsServer).CallTool(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SysMetrics_CallTool_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SysMetricsServer).CallTool(ctx, req.(*CallToolRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SysMetrics_ServiceDesc is the grpc.ServiceDesc for SysMetrics service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SysMetrics_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "sysmetrics.v1.SysMetrics",
+	HandlerType: (*SysMetricsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetInfo",
+			Handler:    _SysMetrics_GetInfo_Handler,
+		},
+		{
+			MethodName: "GetSamples",
+			Handler:    _SysMetrics_GetSamples_Handler,
+		},
+		{
+			MethodName: "CallTool",
+			Handler:    _SysMetrics_CallTool_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchSamples",
+			Handler:       _SysMetrics_WatchSamples_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "sysmetrics.proto",
+}
@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"sysmetrics-mcp/internal/history"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Anomaly detection defaults
+const (
+	defaultAnomalyBaseline   = 24 * time.Hour
+	defaultAnomalyWindow     = 15 * time.Minute
+	defaultZScoreThreshold   = 3.0
+	defaultPercentileCutoff  = 99.0
+	minAnomalyBaselineSample = 10
+)
+
+// Anomaly detection methods
+const (
+	anomalyMethodZScore     = "zscore"
+	anomalyMethodPercentile = "percentile"
+)
+
+// counterMetrics are cumulative counters; they are compared as per-second rates
+var counterMetrics = map[string]bool{
+	"net_bytes_sent": true,
+	"net_bytes_recv": true,
+}
+
+// anomalyDetector decides whether a value is abnormal relative to a baseline
+type anomalyDetector struct {
+	method    string
+	threshold float64
+}
+
+// anomaly is the evaluation of one series against its baseline
+type anomaly struct {
+	Metric          string                 `json:"metric"`
+	Labels          string                 `json:"labels,omitempty"`
+	Unit            string                 `json:"unit,omitempty"`
+	Current         float64                `json:"current"`
+	Abnormal        bool                   `json:"abnormal"`
+	Direction       string                 `json:"direction,omitempty"`
+	ZScore          float64                `json:"z_score"`
+	PercentileRank  float64                `json:"percentile_rank"`
+	Since           string                 `json:"since,omitempty"`
+	DurationSeconds int64                  `json:"duration_seconds,omitempty"`
+	DurationHuman   string                 `json:"duration_human,omitempty"`
+	Baseline        map[string]interface{} `json:"baseline"`
+}
+
+// HandleDetectAnomalies compares the latest value of each stored series with its rolling
+// baseline and reports which metrics are abnormal and since when
+func (h *HandlerManager) HandleDetectAnomalies(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if h.history == nil {
+		return mcp.NewToolResultError("Metrics history is disabled (start the server with --history-db)"), nil
+	}
+
+	var metric string
+	baselineDur := defaultAnomalyBaseline
+	window := defaultAnomalyWindow
+	detector := anomalyDetector{method: anomalyMethodZScore}
+	includeNormal := false
+
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if m, ok := args["metric"].(string); ok {
+			metric = strings.TrimSpace(m)
+		}
+		if b, ok := args["baseline"].(string); ok && b != "" {
+			d, err := parseHistoryDuration(b)
+			if err != nil || d <= 0 {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid baseline: %q (use a duration such as 6h, 24h, or 7d)", b)), nil
+			}
+			baselineDur = d
+		}
+		if w, ok := args["window"].(string); ok && w != "" {
+			d, err := parseHistoryDuration(w)
+			if err != nil || d <= 0 {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid window: %q (use a duration such as 5m or 15m)", w)), nil
+			}
+			window = d
+		}
+		if m, ok := args["method"].(string); ok && m != "" {
+			m = strings.ToLower(strings.TrimSpace(m))
+			if m != anomalyMethodZScore && m != anomalyMethodPercentile {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid method: %q (must be zscore or percentile)", m)), nil
+			}
+			detector.method = m
+		}
+		if t, ok := args["threshold"].(float64); ok {
+			detector.threshold = t
+		}
+		if inc, ok := args["include_normal"].(bool); ok {
+			includeNormal = inc
+		}
+	}
+
+	switch {
+	case detector.threshold == 0 && detector.method == anomalyMethodZScore:
+		detector.threshold = defaultZScoreThreshold
+	case detector.threshold == 0:
+		detector.threshold = defaultPercentileCutoff
+	case detector.threshold < 0 || (detector.method == anomalyMethodPercentile && (detector.threshold <= 50 || detector.threshold >= 100)):
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid threshold: %v (z-score must be positive; percentile must be between 50 and 100)", detector.threshold)), nil
+	}
+
+	// The window must hold at least a couple of samples to say anything about "now"
+	if minWindow := 2 * h.cfg.SampleInterval; window < minWindow {
+		window = minWindow
+	}
+
+	now := time.Now()
+	windowStart := now.Add(-window)
+	series, err := h.history.Raw(ctx, metric, windowStart.Add(-baselineDur), now)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read metrics history: %v", err)), nil
+	}
+
+	anomalies := []anomaly{}
+	normal := []anomaly{}
+	insufficient := []string{}
+	for _, s := range series {
+		a, ok := h.evaluateSeries(s, windowStart, now, detector)
+		if !ok {
+			insufficient = append(insufficient, seriesName(s.Metric, s.Labels))
+			continue
+		}
+		if a.Abnormal {
+			anomalies = append(anomalies, a)
+		} else {
+			normal = append(normal, a)
+		}
+	}
+	sort.Slice(anomalies, func(i, j int) bool {
+		return math.Abs(anomalies[i].ZScore) > math.Abs(anomalies[j].ZScore)
+	})
+
+	result := map[string]interface{}{
+		"method":         detector.method,
+		"threshold":      detector.threshold,
+		"baseline":       baselineDur.String(),
+		"window":         window.String(),
+		"checked_series": len(anomalies) + len(normal),
+		"anomaly_count":  len(anomalies),
+		"anomalies":      anomalies,
+	}
+	if includeNormal {
+		result["normal"] = normal
+	}
+	if len(insufficient) > 0 {
+		result["insufficient_history"] = insufficient
+	}
+	if len(series) == 0 {
+		result["note"] = "No samples in range; the sampler needs to run for a while before baselines exist"
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// evaluateSeries splits a series into a baseline (before windowStart) and a recent window,
+// and judges the latest recent value. ok is false when either part has too few samples.
+func (h *HandlerManager) evaluateSeries(s history.RawSeries, windowStart, now time.Time, d anomalyDetector) (a anomaly, ok bool) {
+	points := s.Points
+	a = anomaly{Metric: s.Metric, Labels: s.Labels}
+	if counterMetrics[s.Metric] {
+		points = history.Rates(points)
+		a.Unit = "per_second"
+	}
+
+	split := sort.Search(len(points), func(i int) bool { return !points[i].Time.Before(windowStart) })
+	if split < minAnomalyBaselineSample || split == len(points) {
+		return a, false
+	}
+	baseline := history.NewBaseline(points[:split])
+	current := points[len(points)-1].Value
+	minStdDev := anomalyMinStdDev(s.Metric, baseline.Mean)
+
+	a.Current = round2(current)
+	a.ZScore = round2(baseline.ZScore(current, minStdDev))
+	a.PercentileRank = round2(baseline.PercentileRank(current))
+	a.Baseline = map[string]interface{}{
+		"samples": baseline.Samples,
+		"mean":    round2(baseline.Mean),
+		"stddev":  round2(baseline.StdDev),
+		"min":     round2(baseline.Min),
+		"max":     round2(baseline.Max),
+		"p50":     round2(baseline.Percentile(50)),
+	}
+	if d.method == anomalyMethodPercentile {
+		a.Baseline["low"] = round2(baseline.Percentile(100 - d.threshold))
+		a.Baseline["high"] = round2(baseline.Percentile(d.threshold))
+	}
+
+	direction := d.direction(baseline, current, minStdDev)
+	if direction == "" {
+		return a, true
+	}
+	a.Abnormal = true
+	a.Direction = direction
+
+	// Walk back while values stay abnormal in the same direction to find the onset
+	since := points[len(points)-1].Time
+	for i := len(points) - 2; i >= 0; i-- {
+		if d.direction(baseline, points[i].Value, minStdDev) != direction {
+			break
+		}
+		since = points[i].Time
+	}
+	dur := now.Sub(since).Truncate(time.Second)
+	a.Since = since.UTC().Format(time.RFC3339)
+	a.DurationSeconds = int64(dur / time.Second)
+	a.DurationHuman = h.human.Duration(dur)
+	return a, true
+}
+
+// direction returns "high" or "low" when v is abnormal relative to the baseline, else ""
+func (d anomalyDetector) direction(b history.Baseline, v, minStdDev float64) string {
+	if d.method == anomalyMethodPercentile {
+		switch {
+		case v > b.Percentile(d.threshold) && v-b.Mean > minStdDev:
+			return "high"
+		case v < b.Percentile(100-d.threshold) && b.Mean-v > minStdDev:
+			return "low"
+		}
+		return ""
+	}
+
+	z := b.ZScore(v, minStdDev)
+	switch {
+	case z >= d.threshold:
+		return "high"
+	case z <= -d.threshold:
+		return "low"
+	}
+	return ""
+}
+
+// anomalyMinStdDev is the smallest deviation treated as meaningful for a metric, so a flat
+// baseline (swap at 0%, an idle load average) does not flag every tiny wobble
+func anomalyMinStdDev(metric string, mean float64) float64 {
+	if strings.HasSuffix(metric, "_percent") || strings.HasSuffix(metric, "_celsius") {
+		return 1
+	}
+	return math.Max(0.05*math.Abs(mean), 0.01)
+}
+
+// round2 rounds to two decimals for compact output
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// seriesName renders a metric with its labels, e.g. disk_used_percent{mount=/}
+func seriesName(metric, labels string) string {
+	if labels == "" {
+		return metric
+	}
+	return metric + "{" + labels + "}"
+}
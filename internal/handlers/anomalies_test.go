@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"sysmetrics-mcp/internal/history"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleDetectAnomalies(t *testing.T) {
+	h := newHistoryTestManager(t)
+	ctx := context.Background()
+
+	// Two hours of steady CPU and memory, then CPU jumps for the last five minutes
+	now := time.Now().Truncate(time.Minute)
+	spikeStart := now.Add(-4 * time.Minute)
+	var samples []history.Sample
+	for ts := now.Add(-2 * time.Hour); !ts.After(now); ts = ts.Add(time.Minute) {
+		cpu := 10 + float64(ts.Minute()%3)
+		if !ts.Before(spikeStart) {
+			cpu = 95
+		}
+		samples = append(samples,
+			history.Sample{Time: ts, Metric: "cpu_percent", Value: cpu},
+			history.Sample{Time: ts, Metric: "memory_used_percent", Value: 40 + float64(ts.Minute()%2)},
+			history.Sample{Time: ts, Metric: "net_bytes_recv", Labels: map[string]string{"interface": "eth0"}, Value: float64(ts.Unix()-now.Unix()) * 1000})
+	}
+	if err := h.history.Write(ctx, samples); err != nil {
+		t.Fatalf("Failed to write samples: %v", err)
+	}
+
+	for _, method := range []string{anomalyMethodZScore, anomalyMethodPercentile} {
+		t.Run(method, func(t *testing.T) {
+			req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]interface{}{
+				"baseline": "2h", "window": "10m", "method": method, "include_normal": true,
+			}}}
+			res, err := h.HandleDetectAnomalies(ctx, req)
+			checkToolResult(t, res, err, []string{"method", "threshold", "checked_series", "anomalies", "normal"})
+
+			var result struct {
+				Checked   int       `json:"checked_series"`
+				Anomalies []anomaly `json:"anomalies"`
+				Normal    []anomaly `json:"normal"`
+			}
+			if err := json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &result); err != nil {
+				t.Fatalf("Failed to unmarshal result: %v", err)
+			}
+			if result.Checked != 3 {
+				t.Errorf("Expected 3 checked series, got %d", result.Checked)
+			}
+			if len(result.Anomalies) != 1 || result.Anomalies[0].Metric != "cpu_percent" {
+				t.Fatalf("Expected only cpu_percent to be abnormal, got %+v", result.Anomalies)
+			}
+			a := result.Anomalies[0]
+			if a.Direction != "high" || a.Since != spikeStart.UTC().Format(time.RFC3339) {
+				t.Errorf("Expected a high anomaly since %s, got %+v", spikeStart.UTC().Format(time.RFC3339), a)
+			}
+			for _, n := range result.Normal {
+				if n.Metric == "net_bytes_recv" && (n.Unit != "per_second" || n.Current != 1000) {
+					t.Errorf("Expected net_bytes_recv as a 1000/s rate, got %+v", n)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleDetectAnomaliesInvalidArgs(t *testing.T) {
+	h := newHistoryTestManager(t)
+
+	for _, args := range []map[string]interface{}{
+		{"baseline": "forever"},
+		{"window": "0s"},
+		{"method": "magic"},
+		{"method": "percentile", "threshold": 40.0},
+		{"threshold": -2.0},
+	} {
+		req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}}
+		res, err := h.HandleDetectAnomalies(context.Background(), req)
+		if err != nil || res == nil || !res.IsError {
+			t.Errorf("Expected a tool error for %v, got %+v (err %v)", args, res, err)
+		}
+	}
+}
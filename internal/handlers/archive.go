@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sysmetrics-mcp/internal/archive"
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// archiveStores is the data export_history and import_history move; baselines and the
+// ledger are left out where there is no user config directory to keep them in
+func (h *HandlerManager) archiveStores() archive.Stores {
+	stores := archive.Stores{History: h.history}
+	if h.baselines.Path() != "" {
+		stores.Baselines = h.baselines
+	}
+	if h.ledger.Path() != "" {
+		stores.Ledger = h.ledger
+	}
+	return stores
+}
+
+// archivePath resolves a path argument; relative paths are taken from the history
+// database directory
+func (h *HandlerManager) archivePath(path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(filepath.Dir(h.history.Path()), path)
+}
+
+// HandleExportHistory writes metrics history, health snapshots, benchmark baselines, and
+// the availability ledger to a compressed archive file
+func (h *HandlerManager) HandleExportHistory(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if h.history == nil {
+		return mcp.NewToolResultError("Metrics history is disabled (start the server with --history-db)"), nil
+	}
+	path := "sysmetrics-archive-" + time.Now().UTC().Format("20060102-150405") + ".jsonl.gz"
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if p, ok := args["path"].(string); ok && strings.TrimSpace(p) != "" {
+			path = strings.TrimSpace(p)
+		}
+	}
+	path = h.archivePath(path)
+
+	hostname, _ := os.Hostname()
+	counts, err := archive.WriteFile(ctx, path, h.archiveStores(), archive.Manifest{Hostname: hostname, ServerVersion: config.ServerVersion})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to export history: %v", err)), nil
+	}
+	result := map[string]interface{}{
+		"file":     path,
+		"exported": counts,
+	}
+	if info, err := os.Stat(path); err == nil {
+		result["size_bytes"] = info.Size()
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// HandleImportHistory merges an archive written by export_history into this server's
+// history, baselines, and availability ledger, skipping what is already stored
+func (h *HandlerManager) HandleImportHistory(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if h.history == nil {
+		return mcp.NewToolResultError("Metrics history is disabled (start the server with --history-db)"), nil
+	}
+	var path string
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if p, ok := args["path"].(string); ok {
+			path = strings.TrimSpace(p)
+		}
+	}
+	if path == "" {
+		return mcp.NewToolResultError("path is required"), nil
+	}
+	path = h.archivePath(path)
+
+	summary, err := archive.ReadFile(ctx, path, h.archiveStores())
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to import history: %v", err)), nil
+	}
+
+	jsonBytes, err := json.Marshal(map[string]interface{}{
+		"file":     path,
+		"source":   summary.Manifest,
+		"archived": summary.Archived,
+		"imported": summary.Imported,
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
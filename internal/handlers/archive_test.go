@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sysmetrics-mcp/internal/availability"
+	"sysmetrics-mcp/internal/bench"
+	"sysmetrics-mcp/internal/history"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleExportImportHistory(t *testing.T) {
+	ctx := context.Background()
+	src := newHistoryTestManager(t)
+	src.baselines = bench.NewStore(filepath.Join(t.TempDir(), "baselines.json"))
+	src.ledger = availability.NewLedger("")
+	if err := src.history.Write(ctx, []history.Sample{{Time: time.Now(), Metric: "cpu_percent", Value: 12}}); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := src.HandleExportHistory(ctx, mcp.CallToolRequest{})
+	checkToolResult(t, res, err, []string{"file", "exported", "size_bytes"})
+	var exported struct {
+		File     string `json:"file"`
+		Exported struct {
+			Rows int `json:"rows"`
+		} `json:"exported"`
+	}
+	if err := json.Unmarshal([]byte(resultText(res)), &exported); err != nil {
+		t.Fatal(err)
+	}
+	if exported.Exported.Rows != 1 || filepath.Dir(exported.File) != filepath.Dir(src.history.Path()) {
+		t.Errorf("Expected one row exported next to the history database, got %+v", exported)
+	}
+
+	// The same file is never overwritten
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"path": exported.File}
+	if res, err := src.HandleExportHistory(ctx, req); err != nil || !res.IsError {
+		t.Errorf("Expected an error exporting over an existing file, got %v", resultText(res))
+	}
+
+	dst := newHistoryTestManager(t)
+	dst.baselines = bench.NewStore(filepath.Join(t.TempDir(), "baselines.json"))
+	dst.ledger = availability.NewLedger("")
+	res, err = dst.HandleImportHistory(ctx, req)
+	checkToolResult(t, res, err, []string{"file", "source", "archived", "imported"})
+	metrics, err := dst.history.Metrics(ctx)
+	if err != nil || len(metrics) != 1 {
+		t.Errorf("Expected the imported series, got %+v (err %v)", metrics, err)
+	}
+
+	if res, err := dst.HandleImportHistory(ctx, mcp.CallToolRequest{}); err != nil || !res.IsError {
+		t.Error("Expected an error without a path")
+	}
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// alsaRoot is the procfs directory describing ALSA sound cards
+const alsaRoot = "/proc/asound"
+
+// alsaCardRe matches the first line of each card in /proc/asound/cards:
+// " 0 [Headphones     ]: bcm2835_headpho - bcm2835 Headphones"
+var alsaCardRe = regexp.MustCompile(`^\s*(\d+)\s+\[(\S+)\s*\]:\s+(\S+)\s+-\s+(.*)$`)
+
+// pactlPercentRe matches the per-channel percentages in pactl volume output
+var pactlPercentRe = regexp.MustCompile(`(\d+)%`)
+
+// alsaCard is a single ALSA sound card
+type alsaCard struct {
+	Index             int    `json:"index"`
+	ID                string `json:"id"`
+	Driver            string `json:"driver"`
+	Name              string `json:"name"`
+	LongName          string `json:"long_name,omitempty"`
+	RunningSubstreams int    `json:"running_substreams"`
+}
+
+// audioStream is a single client playing to a sink or recording from a source
+type audioStream struct {
+	Application string `json:"application,omitempty"`
+	Media       string `json:"media,omitempty"`
+	Corked      bool   `json:"corked"`
+}
+
+// HandleGetAudioStatus returns ALSA sound cards and the PipeWire/PulseAudio defaults and streams
+func (h *HandlerManager) HandleGetAudioStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	result := map[string]interface{}{}
+
+	alsa := map[string]interface{}{
+		"available": false,
+	}
+	if cards, err := readALSACards(alsaRoot); err == nil {
+		alsa["available"] = true
+		alsa["cards"] = cards
+	} else {
+		alsa["error"] = fmt.Sprintf("ALSA not available: %v", err)
+	}
+	result["alsa"] = alsa
+
+	if h.caps.Pactl {
+		result["sound_server"] = collectSoundServer(ctx)
+	} else {
+		result["sound_server"] = map[string]interface{}{
+			"available": false,
+			"error":     "pactl not found in PATH (install pulseaudio-utils for PipeWire or PulseAudio)",
+		}
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// readALSACards parses <root>/cards and counts running PCM substreams per card
+func readALSACards(root string) ([]alsaCard, error) {
+	data, err := os.ReadFile(filepath.Join(filepath.Clean(root), "cards"))
+	if err != nil {
+		return nil, err
+	}
+
+	cards := []alsaCard{}
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		m := alsaCardRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		index, _ := strconv.Atoi(m[1])
+		card := alsaCard{Index: index, ID: m[2], Driver: m[3], Name: strings.TrimSpace(m[4])}
+		if i+1 < len(lines) && !alsaCardRe.MatchString(lines[i+1]) {
+			card.LongName = strings.TrimSpace(lines[i+1])
+		}
+
+		// Each open substream reports "state: RUNNING" while audio is flowing
+		statusFiles, _ := filepath.Glob(filepath.Join(root, fmt.Sprintf("card%d", index), "pcm*", "sub*", "status"))
+		for _, f := range statusFiles {
+			if status, err := os.ReadFile(filepath.Clean(f)); err == nil && strings.Contains(string(status), "state: RUNNING") {
+				card.RunningSubstreams++
+			}
+		}
+		cards = append(cards, card)
+	}
+	return cards, nil
+}
+
+// collectSoundServer queries the PipeWire or PulseAudio server through pactl
+func collectSoundServer(ctx context.Context) map[string]interface{} {
+	server := map[string]interface{}{
+		"available": false,
+	}
+
+	infoOut, err := runPactl(ctx, "info")
+	if err != nil {
+		server["error"] = fmt.Sprintf("pactl info failed (is a user sound server running?): %v", err)
+		return server
+	}
+	info := parsePactlInfo(infoOut)
+
+	server["available"] = true
+	server["server_name"] = info["Server Name"]
+	server["server_version"] = info["Server Version"]
+	server["pipewire"] = strings.Contains(info["Server Name"], "PipeWire")
+
+	for _, dev := range []struct {
+		key, infoKey, kind, streams string
+	}{
+		{"default_sink", "Default Sink", "sink", "sink-inputs"},
+		{"default_source", "Default Source", "source", "source-outputs"},
+	} {
+		name := info[dev.infoKey]
+		if name == "" {
+			continue
+		}
+		device := map[string]interface{}{
+			"name": name,
+		}
+		if out, err := runPactl(ctx, "get-"+dev.kind+"-volume", name); err == nil {
+			if volume, ok := parsePactlVolume(out); ok {
+				device["volume_percent"] = volume
+			}
+		}
+		if out, err := runPactl(ctx, "get-"+dev.kind+"-mute", name); err == nil {
+			device["muted"] = strings.Contains(out, "Mute: yes")
+		}
+		server[dev.key] = device
+
+		if out, err := runPactl(ctx, "list", dev.streams); err == nil {
+			streams := parsePactlStreams(out)
+			server[strings.ReplaceAll(dev.streams, "-", "_")] = streams
+		}
+	}
+	return server
+}
+
+// parsePactlInfo parses `Key: value` lines from `pactl info`
+func parsePactlInfo(output string) map[string]string {
+	info := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		info[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return info
+}
+
+// parsePactlVolume averages the channel percentages from `pactl get-sink-volume` output
+func parsePactlVolume(output string) (float64, bool) {
+	// Only the first line holds channel volumes; "balance" follows on the next
+	firstLine, _, _ := strings.Cut(output, "\n")
+	matches := pactlPercentRe.FindAllStringSubmatch(firstLine, -1)
+	if len(matches) == 0 {
+		return 0, false
+	}
+	total := 0.0
+	for _, m := range matches {
+		v, _ := strconv.ParseFloat(m[1], 64)
+		total += v
+	}
+	return total / float64(len(matches)), true
+}
+
+// parsePactlStreams parses `pactl list sink-inputs` or `pactl list source-outputs` output
+func parsePactlStreams(output string) []audioStream {
+	streams := []audioStream{}
+	var current *audioStream
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "Sink Input #") || strings.HasPrefix(line, "Source Output #"):
+			streams = append(streams, audioStream{})
+			current = &streams[len(streams)-1]
+		case current == nil:
+			continue
+		case strings.HasPrefix(trimmed, "Corked:"):
+			current.Corked = strings.TrimSpace(strings.TrimPrefix(trimmed, "Corked:")) == "yes"
+		case strings.HasPrefix(trimmed, "application.name = "):
+			current.Application = strings.Trim(strings.TrimPrefix(trimmed, "application.name = "), `"`)
+		case strings.HasPrefix(trimmed, "media.name = "):
+			current.Media = strings.Trim(strings.TrimPrefix(trimmed, "media.name = "), `"`)
+		}
+	}
+	return streams
+}
+
+// runPactl runs pactl with a C locale so its output can be parsed
+func runPactl(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "pactl", args...) //nolint:gosec // G204: callers pass fixed subcommands and server-reported device names
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	out, err := cmd.Output()
+	return string(out), err
+}
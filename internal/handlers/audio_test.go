@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestReadALSACards(t *testing.T) {
+	root := t.TempDir()
+	writeSysfsFiles(t, root, map[string]string{
+		"cards": " 0 [vc4hdmi0       ]: vc4-hdmi - vc4-hdmi-0\n" +
+			"                      vc4-hdmi-0\n" +
+			" 1 [Headphones     ]: bcm2835_headpho - bcm2835 Headphones\n" +
+			"                      bcm2835 Headphones",
+	})
+	writeSysfsFiles(t, filepath.Join(root, "card1", "pcm0p", "sub0"), map[string]string{"status": "state: RUNNING\nowner_pid   : 812"})
+	writeSysfsFiles(t, filepath.Join(root, "card1", "pcm0p", "sub1"), map[string]string{"status": "closed"})
+
+	cards, err := readALSACards(root)
+	if err != nil {
+		t.Fatalf("readALSACards failed: %v", err)
+	}
+	if len(cards) != 2 {
+		t.Fatalf("Expected 2 cards, got %d", len(cards))
+	}
+	if cards[0].ID != "vc4hdmi0" || cards[0].Driver != "vc4-hdmi" || cards[0].RunningSubstreams != 0 {
+		t.Errorf("Unexpected card 0: %+v", cards[0])
+	}
+	if cards[1].Index != 1 || cards[1].Name != "bcm2835 Headphones" || cards[1].LongName != "bcm2835 Headphones" || cards[1].RunningSubstreams != 1 {
+		t.Errorf("Unexpected card 1: %+v", cards[1])
+	}
+
+	if _, err := readALSACards(filepath.Join(root, "missing")); err == nil {
+		t.Error("Expected error for missing cards file")
+	}
+}
+
+func TestParsePactlVolume(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected float64
+		ok       bool
+	}{
+		{"stereo", "Volume: front-left: 65536 / 100% / 0.00 dB,   front-right: 32768 /  50% / -18.06 dB\n        balance -0.50\n", 75, true},
+		{"mono", "Volume: mono: 45875 /  70% / -9.29 dB\n", 70, true},
+		{"empty", "", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parsePactlVolume(tt.output)
+			if ok != tt.ok || got != tt.expected {
+				t.Errorf("parsePactlVolume() = %v, %v; expected %v, %v", got, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestParsePactlInfoAndStreams(t *testing.T) {
+	info := parsePactlInfo("Server String: /run/user/1000/pulse/native\n" +
+		"Server Name: PulseAudio (on PipeWire 0.3.65)\n" +
+		"Default Sink: alsa_output.platform-bcm2835_audio.stereo-fallback\n")
+	if info["Server Name"] != "PulseAudio (on PipeWire 0.3.65)" || info["Default Sink"] != "alsa_output.platform-bcm2835_audio.stereo-fallback" {
+		t.Errorf("Unexpected info: %v", info)
+	}
+
+	streams := parsePactlStreams(`Sink Input #42
+	Driver: protocol-native.c
+	Corked: no
+	Properties:
+		media.name = "Playback Stream"
+		application.name = "Music Player Daemon"
+Sink Input #43
+	Corked: yes
+	Properties:
+		application.name = "Firefox"
+`)
+	if len(streams) != 2 {
+		t.Fatalf("Expected 2 streams, got %d", len(streams))
+	}
+	if streams[0].Application != "Music Player Daemon" || streams[0].Media != "Playback Stream" || streams[0].Corked {
+		t.Errorf("Unexpected stream 0: %+v", streams[0])
+	}
+	if streams[1].Application != "Firefox" || !streams[1].Corked {
+		t.Errorf("Unexpected stream 1: %+v", streams[1])
+	}
+}
+
+func TestHandleGetAudioStatus(t *testing.T) {
+	h := NewHandlerManager(&config.Config{})
+	req := mcp.CallToolRequest{}
+	res, err := h.HandleGetAudioStatus(context.Background(), req)
+	checkToolResult(t, res, err, []string{"alsa", "sound_server"})
+}
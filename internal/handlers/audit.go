@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"sysmetrics-mcp/internal/audit"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Audit log limits
+const (
+	maxAuditArgsBytes   = 1024
+	defaultAuditLimit   = 50
+	maxAuditLimit       = 1000
+	defaultAuditLogSpan = 24 * time.Hour
+)
+
+// SetAuditLog replaces the audit trail; a nil log is ignored
+func (h *HandlerManager) SetAuditLog(log *audit.Log) {
+	if log != nil {
+		h.audit = log
+	}
+}
+
+// auditTool wraps a tool handler so every call is recorded in the audit trail with its
+// arguments, calling client, duration, and result size
+func (h *HandlerManager) auditTool(name string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		result, err := handler(ctx, request)
+
+		entry := audit.Entry{
+			Time:       start.UTC(),
+			Tool:       name,
+			DurationMS: time.Since(start).Milliseconds(),
+		}
+		if args := request.Params.Arguments; args != nil {
+			if data, mErr := json.Marshal(args); mErr == nil && string(data) != "{}" {
+				entry.Args = truncateString(string(data), maxAuditArgsBytes)
+			}
+		}
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			entry.Session = session.SessionID()
+			if withInfo, ok := session.(server.SessionWithClientInfo); ok {
+				if info := withInfo.GetClientInfo(); info.Name != "" {
+					entry.Caller = info.Name + "/" + info.Version
+				}
+			}
+		} else if caller, ok := ctx.Value(callerKey{}).(string); ok {
+			entry.Caller = caller
+		}
+		text := resultText(result)
+		entry.ResultBytes = len(text)
+		switch {
+		case err != nil:
+			entry.IsError, entry.Error = true, truncateString(err.Error(), maxLoggedError)
+		case result != nil && result.IsError:
+			entry.IsError, entry.Error = true, truncateString(text, maxLoggedError)
+		}
+
+		if recErr := h.audit.Record(entry); recErr != nil {
+			h.logger.Warn("audit log write failed", "tool", name, "error", recErr)
+		}
+		if h.toolTraits[name].action != "" {
+			h.journalAction(ctx, name, entry.Args, entry.Error)
+		}
+		return result, err
+	}
+}
+
+// HandleGetAuditLog returns recent tool calls from the audit trail, newest first
+func (h *HandlerManager) HandleGetAuditLog(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filter := audit.Filter{Limit: defaultAuditLimit}
+	span := defaultAuditLogSpan
+
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if l, ok := args["limit"].(float64); ok && l > 0 {
+			filter.Limit = int(l)
+		}
+		if t, ok := args["tool"].(string); ok {
+			filter.Tool = t
+		}
+		if s, ok := args["since"].(string); ok && s != "" {
+			d, err := parseHistoryDuration(s)
+			if err != nil || d <= 0 {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid since: %q (use a duration such as 30m, 6h, or 7d)", s)), nil
+			}
+			span = d
+		}
+		if e, ok := args["errors_only"].(bool); ok {
+			filter.ErrorsOnly = e
+		}
+	}
+	if filter.Limit > maxAuditLimit {
+		filter.Limit = maxAuditLimit
+	}
+	filter.Since = time.Now().Add(-span)
+
+	entries, total, err := h.audit.Recent(filter)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read audit log: %v", err)), nil
+	}
+
+	// Per-tool call counts over the matched entries
+	counts := map[string]int{}
+	for _, e := range entries {
+		counts[e.Tool]++
+	}
+
+	result := map[string]interface{}{
+		"since":         filter.Since.UTC().Format(time.RFC3339),
+		"total_matched": total,
+		"returned":      len(entries),
+		"calls_by_tool": counts,
+		"entries":       entries,
+	}
+	if path := h.audit.Path(); path != "" {
+		result["file"] = path
+	} else {
+		result["note"] = "Audit entries are kept in memory only and reset on restart; set --audit-log to persist them"
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// truncateString shortens s to at most n bytes without splitting a character, marking the cut
+func truncateString(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return strings.ToValidUTF8(s[:n], "") + "..."
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"sysmetrics-mcp/internal/audit"
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestAuditToolAndGetAuditLog(t *testing.T) {
+	h := NewHandlerManager(&config.Config{})
+	ok := h.auditTool("get_process_list", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(`{"processes":[]}`), nil
+	})
+	failing := h.auditTool("get_disk_metrics", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultError("Failed to get partitions"), nil
+	})
+
+	ctx := context.Background()
+	long := strings.Repeat("x", 2*maxAuditArgsBytes)
+	_, _ = ok(ctx, mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]interface{}{"limit": 5, "user": long}}})
+	_, _ = failing(ctx, mcp.CallToolRequest{})
+	_, _ = ok(ctx, mcp.CallToolRequest{})
+
+	res, err := h.HandleGetAuditLog(ctx, mcp.CallToolRequest{})
+	checkToolResult(t, res, err, []string{"since", "total_matched", "returned", "calls_by_tool", "entries", "note"})
+
+	var result struct {
+		Total   int            `json:"total_matched"`
+		Counts  map[string]int `json:"calls_by_tool"`
+		Entries []audit.Entry  `json:"entries"`
+	}
+	if err := json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &result); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+	if result.Total != 3 || result.Counts["get_process_list"] != 2 {
+		t.Errorf("Unexpected audit summary: %+v", result)
+	}
+	first := result.Entries[2]
+	if first.Tool != "get_process_list" || len(first.Args) > maxAuditArgsBytes+3 || !strings.HasPrefix(first.Args, `{"limit":5`) {
+		t.Errorf("Expected the oldest entry with truncated args, got %+v", first)
+	}
+	if first.ResultBytes != len(`{"processes":[]}`) {
+		t.Errorf("Expected the result size to be recorded, got %d", first.ResultBytes)
+	}
+
+	req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]interface{}{"errors_only": true}}}
+	res, err = h.HandleGetAuditLog(ctx, req)
+	checkToolResult(t, res, err, []string{"entries"})
+	if err := json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &result); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+	if len(result.Entries) != 1 || result.Entries[0].Error != "Failed to get partitions" {
+		t.Errorf("Expected only the failed call, got %+v", result.Entries)
+	}
+
+	req.Params.Arguments = map[string]interface{}{"since": "soon"}
+	if res, err := h.HandleGetAuditLog(ctx, req); err != nil || !res.IsError {
+		t.Errorf("Expected a tool error for an invalid since, got %+v", res)
+	}
+}
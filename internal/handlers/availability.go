@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"time"
+
+	"sysmetrics-mcp/internal/availability"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/shirou/gopsutil/v3/host"
+)
+
+// Availability tracking settings
+const (
+	availabilityHeartbeat = time.Minute
+	defaultRebootLimit    = 20
+	maxRebootLimit        = 200
+)
+
+// availabilityWindows are the periods get_availability reports, in days
+var availabilityWindows = []int{7, 30, 90}
+
+// unexpectedBootReasons are previous-boot endings nobody asked for
+var unexpectedBootReasons = map[string]bool{
+	bootReasonKernelPanic:     true,
+	bootReasonWatchdog:        true,
+	bootReasonUncleanShutdown: true,
+}
+
+// RunAvailabilityTracker records the current boot in the availability ledger, classifies
+// how the previous boot ended, and refreshes the boot's last-seen time every minute until
+// ctx is cancelled. It returns immediately when get_availability is not registered.
+func (h *HandlerManager) RunAvailabilityTracker(ctx context.Context) {
+	if !contains(h.registered, "get_availability") {
+		return
+	}
+	start, err := h.trackBoot(ctx)
+	if err != nil {
+		h.logger.Warn("availability tracking disabled", "error", err)
+		return
+	}
+
+	ticker := time.NewTicker(availabilityHeartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// A final heartbeat narrows the gap when the server stops for a shutdown
+			_ = h.ledger.Heartbeat(start, time.Now())
+			return
+		case now := <-ticker.C:
+			if err := h.ledger.Heartbeat(start, now); err != nil {
+				h.logger.Warn("availability heartbeat failed", "error", err)
+			}
+		}
+	}
+}
+
+// trackBoot records the current boot once per process: it imports boots from the journal,
+// adds a heartbeat, and stores how the previous boot ended. It returns the boot start time.
+func (h *HandlerManager) trackBoot(ctx context.Context) (time.Time, error) {
+	h.availabilityOnce.Do(func() {
+		bootTime, err := host.BootTimeWithContext(ctx)
+		if err != nil {
+			h.availabilityErr = fmt.Errorf("failed to read boot time: %w", err)
+			return
+		}
+		//nolint:gosec // G115: boot time is a unix timestamp well within int64
+		h.bootStart = time.Unix(int64(bootTime), 0)
+
+		if spans := h.journalBootSpans(ctx); len(spans) > 0 {
+			if err := h.ledger.Merge(spans); err != nil {
+				h.availabilityErr = err
+				return
+			}
+		}
+		if err := h.ledger.Heartbeat(h.bootStart, time.Now()); err != nil {
+			h.availabilityErr = err
+			return
+		}
+		if reason, _ := h.bootHistory(ctx); reason.Reason != bootReasonUnknown {
+			if _, err := h.ledger.SetEndReason(h.bootStart, reason.Reason, unexpectedBootReasons[reason.Reason]); err != nil {
+				h.availabilityErr = err
+			}
+		}
+	})
+	return h.bootStart, h.availabilityErr
+}
+
+// journalBootSpans returns past boots from the persistent journal, so the ledger starts
+// with history instead of only the boots seen by this server
+func (h *HandlerManager) journalBootSpans(ctx context.Context) []availability.Boot {
+	if runtime.GOOS != "linux" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, bootTimeout)
+	defer cancel()
+	out, err := h.privilegedCommand(ctx, "journalctl", "--list-boots", "--no-pager", "-o", "json").Output()
+	if err != nil {
+		return nil
+	}
+	boots, err := parseJournalBoots(out)
+	if err != nil {
+		return nil
+	}
+	var spans []availability.Boot
+	for _, b := range boots {
+		// The current boot is recorded from the kernel boot time instead
+		if b.Index == 0 || b.FirstEntry <= 0 {
+			continue
+		}
+		spans = append(spans, availability.Boot{Start: time.UnixMicro(b.FirstEntry), LastSeen: time.UnixMicro(b.LastEntry)})
+	}
+	return spans
+}
+
+// HandleGetAvailability reports uptime percentages over 7, 30, and 90 days and the reboot
+// history from the availability ledger
+func (h *HandlerManager) HandleGetAvailability(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	limit := defaultRebootLimit
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if l, ok := args["limit"].(float64); ok && l > 0 {
+			limit = int(l)
+			if limit > maxRebootLimit {
+				limit = maxRebootLimit
+			}
+		}
+	}
+
+	start, err := h.trackBoot(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to record the current boot: %v", err)), nil
+	}
+	now := time.Now()
+	if err := h.ledger.Heartbeat(start, now); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to update the availability ledger: %v", err)), nil
+	}
+	boots, err := h.ledger.Boots()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read the availability ledger: %v", err)), nil
+	}
+
+	windows := []map[string]interface{}{}
+	for _, days := range availabilityWindows {
+		w := availability.Availability(boots, now, days)
+		windows = append(windows, map[string]interface{}{
+			"days":               w.Days,
+			"uptime_percent":     w.UptimePercent,
+			"tracked_seconds":    w.TrackedSeconds,
+			"uptime_seconds":     w.UptimeSeconds,
+			"downtime_seconds":   w.DowntimeSeconds,
+			"downtime_human":     h.human.Duration(time.Duration(w.DowntimeSeconds) * time.Second),
+			"reboots":            w.Reboots,
+			"unexpected_reboots": w.UnexpectedReboots,
+		})
+	}
+
+	reboots := []map[string]interface{}{}
+	for _, r := range availability.Reboots(boots, limit) {
+		entry := map[string]interface{}{
+			"time":               r.Time.UTC().Format(time.RFC3339),
+			"previous_last_seen": r.PreviousLastSeen.UTC().Format(time.RFC3339),
+			"downtime_seconds":   int64(r.Downtime / time.Second),
+			"downtime_human":     h.human.Duration(r.Downtime.Truncate(time.Second)),
+			"unexpected":         r.Unexpected,
+		}
+		if r.EndReason != "" {
+			entry["end_reason"] = r.EndReason
+		}
+		reboots = append(reboots, entry)
+	}
+
+	uptime := now.Sub(start).Truncate(time.Second)
+	result := map[string]interface{}{
+		"ledger_path":        h.ledger.Path(),
+		"tracking_since":     boots[0].Start.UTC().Format(time.RFC3339),
+		"current_boot_start": start.UTC().Format(time.RFC3339),
+		"current_uptime":     h.human.Duration(uptime),
+		"windows":            windows,
+		"reboots":            reboots,
+	}
+	longest := time.Duration(availabilityWindows[len(availabilityWindows)-1]) * 24 * time.Hour
+	if tracked := now.Sub(boots[0].Start); tracked < longest {
+		result["note"] = fmt.Sprintf("Tracking began %s ago; percentages cover only the tracked part of each window. Downtime between boots is measured from the last time the host was seen up, which is only accurate while this server or the persistent journal was running.", h.human.Duration(tracked.Truncate(time.Second)))
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
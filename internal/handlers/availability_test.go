@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sysmetrics-mcp/internal/availability"
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleGetAvailability(t *testing.T) {
+	h := NewHandlerManager(&config.Config{})
+	h.ledger = availability.NewLedger(filepath.Join(t.TempDir(), "availability.json"))
+
+	// A boot from last month that ended in a panic
+	old := time.Now().Add(-30 * 24 * time.Hour)
+	if err := h.ledger.Merge([]availability.Boot{{Start: old, LastSeen: old.Add(time.Hour), EndReason: bootReasonKernelPanic, Unexpected: true}}); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := h.HandleGetAvailability(context.Background(), mcp.CallToolRequest{})
+	checkToolResult(t, res, err, []string{"tracking_since", "current_boot_start", "windows", "reboots", "ledger_path"})
+
+	var data struct {
+		Windows []struct {
+			Days          int      `json:"days"`
+			UptimePercent *float64 `json:"uptime_percent"`
+		} `json:"windows"`
+		Reboots []struct {
+			EndReason  string `json:"end_reason"`
+			Unexpected bool   `json:"unexpected"`
+		} `json:"reboots"`
+	}
+	if err := json.Unmarshal([]byte(resultText(res)), &data); err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Windows) != 3 || data.Windows[0].Days != 7 || data.Windows[0].UptimePercent == nil {
+		t.Errorf("Unexpected windows: %+v", data.Windows)
+	}
+	if len(data.Reboots) == 0 || data.Reboots[len(data.Reboots)-1].EndReason != bootReasonKernelPanic || !data.Reboots[len(data.Reboots)-1].Unexpected {
+		t.Errorf("Expected the panic reboot in the history, got %+v", data.Reboots)
+	}
+
+	boots, err := h.ledger.Boots()
+	if err != nil || len(boots) < 2 {
+		t.Errorf("Expected the current boot to be recorded, got %+v, %v", boots, err)
+	}
+}
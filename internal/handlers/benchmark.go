@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"sysmetrics-mcp/internal/bench"
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Benchmark limits
+const (
+	defaultBenchSeconds = 3
+	maxBenchSeconds     = 30
+	defaultBenchDiskMB  = 64
+	maxBenchDiskMB      = 1024
+)
+
+// Benchmark sections
+const (
+	benchCPU    = "cpu"
+	benchMemory = "memory"
+	benchDisk   = "disk"
+)
+
+// cpuFreqPath is the current frequency of the first core, in kHz
+var cpuFreqPath = "/sys/devices/system/cpu/cpu0/cpufreq/scaling_cur_freq"
+
+// HandleRunSystemBaseline runs the CPU, memory, and disk micro-benchmarks with a sensor
+// snapshot before and after, optionally saving the run as a named baseline and comparing
+// it with an earlier one
+func (h *HandlerManager) HandleRunSystemBaseline(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var name, compareTo, diskDir string
+	tests := []string{benchCPU, benchMemory, benchDisk}
+	seconds := defaultBenchSeconds
+	diskMB := defaultBenchDiskMB
+	listOnly := false
+	var maxTempC float64
+
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if n, ok := args["name"].(string); ok {
+			name = strings.TrimSpace(n)
+		}
+		if c, ok := args["compare_to"].(string); ok {
+			compareTo = strings.TrimSpace(c)
+		}
+		if d, ok := args["disk_dir"].(string); ok {
+			diskDir = strings.TrimSpace(d)
+		}
+		if t, ok := args["tests"].(string); ok && t != "" {
+			tests = config.SplitAndTrim(strings.ToLower(t))
+			for _, test := range tests {
+				if test != benchCPU && test != benchMemory && test != benchDisk {
+					return mcp.NewToolResultError(fmt.Sprintf("Invalid test: %q (must be cpu, memory, or disk)", test)), nil
+				}
+			}
+		}
+		if s, ok := args["duration_seconds"].(float64); ok && s > 0 {
+			seconds = int(s)
+			if seconds > maxBenchSeconds {
+				seconds = maxBenchSeconds
+			}
+		}
+		if mb, ok := args["disk_mb"].(float64); ok && mb > 0 {
+			diskMB = int(mb)
+			if diskMB > maxBenchDiskMB {
+				diskMB = maxBenchDiskMB
+			}
+		}
+		if l, ok := args["list"].(bool); ok {
+			listOnly = l
+		}
+		if m, ok := args["max_temp_celsius"].(float64); ok && m > 0 {
+			maxTempC = m
+		}
+	}
+
+	if listOnly {
+		names, err := h.baselines.Names()
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to read baselines: %v", err)), nil
+		}
+		jsonBytes, err := json.Marshal(map[string]interface{}{"baselines": names, "file": h.baselines.Path()})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+		}
+		return mcp.NewToolResultText(string(jsonBytes)), nil
+	}
+
+	// Look the comparison baseline up first so a typo fails before the host is put under load
+	var base bench.Report
+	if compareTo != "" {
+		var found bool
+		var err error
+		base, found, err = h.baselines.Get(compareTo)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to read baselines: %v", err)), nil
+		}
+		if !found {
+			names, _ := h.baselines.Names()
+			return mcp.NewToolResultError(fmt.Sprintf("No baseline named %q (stored: %s)", compareTo, strings.Join(names, ", "))), nil
+		}
+	}
+
+	if diskDir == "" && contains(tests, benchDisk) {
+		diskDir = os.TempDir()
+		if path := h.baselines.Path(); path != "" {
+			if err := os.MkdirAll(filepath.Dir(path), 0o700); err == nil {
+				diskDir = filepath.Dir(path)
+			}
+		}
+	}
+
+	// Two concurrent runs would measure each other
+	if !h.benchMu.TryLock() {
+		return mcp.NewToolResultError("A benchmark is already running"), nil
+	}
+	defer h.benchMu.Unlock()
+
+	// The thermal guard cancels the run when the CPU nears its critical trip point
+	guard := newThermalGuard(thermalZonePath, maxTempC)
+	if err := guard.tooHot(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	runCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	stopGuard := guard.watch(runCtx, cancel)
+
+	hostname, _ := os.Hostname()
+	report := bench.Report{Name: name, Time: time.Now().UTC(), Hostname: hostname}
+	report.SensorsBefore = h.sensorSnapshot(ctx)
+	duration := time.Duration(seconds) * time.Second
+	errs := map[string]string{}
+
+	// Sections keep partial results when the run is aborted; later sections are skipped
+	if contains(tests, benchCPU) && runCtx.Err() == nil {
+		r, err := bench.CPU(runCtx, duration, 0)
+		if err != nil {
+			errs[benchCPU] = err.Error()
+		}
+		if err == nil || r.Partial {
+			report.CPU = &r
+		}
+	}
+	if contains(tests, benchMemory) && runCtx.Err() == nil {
+		r, err := bench.Memory(runCtx, duration, 0)
+		if err != nil {
+			errs[benchMemory] = err.Error()
+		}
+		if err == nil || r.Partial {
+			report.Memory = &r
+		}
+	}
+	if contains(tests, benchDisk) && runCtx.Err() == nil {
+		r, err := bench.Disk(runCtx, diskDir, diskMB)
+		if err != nil {
+			errs[benchDisk] = err.Error()
+		}
+		if err == nil || r.Partial {
+			report.Disk = &r
+		}
+	}
+	stopGuard()
+	report.SensorsAfter = h.sensorSnapshot(ctx)
+
+	result := map[string]interface{}{
+		"report":         report,
+		"elapsed_ms":     time.Since(report.Time).Milliseconds(),
+		"thermal_guard":  guard.report(),
+		"disk_read_note": "Reads follow the writes and are usually served from page cache; compare write and sync latency for storage changes",
+	}
+	if aborted, reason := guard.wasAborted(); aborted {
+		result["aborted"] = true
+		result["abort_reason"] = reason
+	}
+	if len(errs) > 0 {
+		result["errors"] = errs
+	}
+	if compareTo != "" {
+		result["compared_to"] = compareTo
+		result["baseline_time"] = base.Time.Format(time.RFC3339)
+		result["changes"] = bench.Compare(base, report)
+	}
+	if name != "" {
+		if len(errs) > 0 {
+			result["saved"] = false
+			result["save_error"] = "not saved because some benchmarks failed or were aborted"
+		} else if err := h.baselines.Save(report); err != nil {
+			result["saved"] = false
+			result["save_error"] = err.Error()
+		} else {
+			result["saved"] = true
+			result["file"] = h.baselines.Path()
+		}
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// sensorSnapshot reads CPU temperature, frequency, and (on a Pi) throttling state
+func (h *HandlerManager) sensorSnapshot(ctx context.Context) bench.Sensors {
+	var s bench.Sensors
+	if t, ok := config.GetRaspberryPiTemp(); ok {
+		s.CPUTempC = &t
+	}
+	if data, err := os.ReadFile(filepath.Clean(cpuFreqPath)); err == nil {
+		if khz, err := strconv.ParseFloat(strings.TrimSpace(string(data)), 64); err == nil {
+			s.CPUFreqMHz = round2(khz / 1000)
+		}
+	}
+	if h.cfg.EnableGPU && h.caps.Vcgencmd {
+		if status, ok := config.GetThrottledStatus(ctx); ok {
+			throttled, _ := status["currently_throttled"].(bool)
+			s.Throttled = &throttled
+		}
+	}
+	return s
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"sysmetrics-mcp/internal/bench"
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleRunSystemBaseline(t *testing.T) {
+	h := NewHandlerManager(&config.Config{})
+	h.baselines = bench.NewStore(filepath.Join(t.TempDir(), "baselines.json"))
+
+	run := func(args map[string]interface{}) *mcp.CallToolResult {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = args
+		res, err := h.HandleRunSystemBaseline(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Handler returned error: %v", err)
+		}
+		return res
+	}
+
+	res := run(map[string]interface{}{"name": "stock", "tests": "memory,disk", "duration_seconds": 1.0, "disk_mb": 1.0})
+	checkToolResult(t, res, nil, []string{"report", "saved", "file"})
+
+	res = run(map[string]interface{}{"compare_to": "stock", "tests": "memory", "duration_seconds": 1.0})
+	checkToolResult(t, res, nil, []string{"report", "changes", "compared_to"})
+	var compared struct {
+		Changes []bench.Change `json:"changes"`
+	}
+	if err := json.Unmarshal([]byte(resultText(res)), &compared); err != nil {
+		t.Fatalf("Failed to decode result: %v", err)
+	}
+	if len(compared.Changes) != 1 || compared.Changes[0].Metric != "memory_copy_mb_per_sec" {
+		t.Errorf("Expected a memory comparison only, got %+v", compared.Changes)
+	}
+
+	res = run(map[string]interface{}{"list": true})
+	checkToolResult(t, res, nil, []string{"baselines", "file"})
+
+	for _, args := range []map[string]interface{}{
+		{"compare_to": "missing"},
+		{"tests": "gpu"},
+	} {
+		if res := run(args); !res.IsError {
+			t.Errorf("Expected an error for %v, got %s", args, resultText(res))
+		}
+	}
+}
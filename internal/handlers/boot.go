@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Boot reasons reported by get_system_info.
+const (
+	bootReasonKernelPanic     = "kernel_panic"
+	bootReasonWatchdog        = "watchdog_reset"
+	bootReasonCleanReboot     = "clean_reboot"
+	bootReasonCleanShutdown   = "clean_shutdown"
+	bootReasonUncleanShutdown = "unclean_shutdown"
+	bootReasonUnknown         = "unknown"
+)
+
+// Kernel crash record locations: the live pstore filesystem and the archive that
+// systemd-pstore moves records into on boot
+var pstoreDirs = []string{"/sys/fs/pstore", "/var/lib/systemd/pstore"}
+
+// watchdogBootStatus is the boot status of the first hardware watchdog
+const watchdogBootStatus = "/sys/class/watchdog/watchdog0/bootstatus"
+
+// wdiofCardReset is the WDIOF_CARDRESET bit: the last reboot was caused by the watchdog
+const wdiofCardReset = 0x20
+
+// bootTimeout bounds the journalctl calls used to inspect the previous boot
+const bootTimeout = 5 * time.Second
+
+// Journal messages logged at the very end of an orderly shutdown or reboot
+var (
+	rebootMarkers   = []string{"Reached target System Reboot", "Reached target Reboot", "systemd-reboot.service", "Rebooting."}
+	poweroffMarkers = []string{"Reached target System Power Off", "Reached target Power-Off", "systemd-poweroff.service", "Powering off.", "System is powering down", "Reached target System Halt"}
+	shutdownMarkers = []string{"Reached target System Shutdown", "Journal stopped", "systemd-shutdown", "Shutting down."}
+)
+
+// previousBoot is the time span of the boot before the current one
+type previousBoot struct {
+	Start           string `json:"start"`
+	End             string `json:"end"`
+	DurationSeconds int64  `json:"duration_seconds"`
+	DurationHuman   string `json:"duration_human"`
+}
+
+// bootReason explains how the previous boot ended
+type bootReason struct {
+	Reason   string   `json:"reason"`
+	Evidence []string `json:"evidence,omitempty"`
+	Note     string   `json:"note,omitempty"`
+}
+
+// journalBoot is one entry of journalctl --list-boots
+type journalBoot struct {
+	Index      int   `json:"index"`
+	FirstEntry int64 `json:"first_entry"`
+	LastEntry  int64 `json:"last_entry"`
+}
+
+// bootHistory returns the cached boot history, which cannot change until the next boot.
+// Results that found nothing are not cached, so a transient journal failure is retried.
+func (h *HandlerManager) bootHistory(ctx context.Context) (bootReason, *previousBoot) {
+	h.bootMu.Lock()
+	defer h.bootMu.Unlock()
+	if h.bootCached {
+		return h.bootReason, h.prevBoot
+	}
+
+	reason, prev := h.getBootHistory(ctx)
+	if reason.Reason != bootReasonUnknown || prev != nil {
+		h.bootReason, h.prevBoot, h.bootCached = reason, prev, true
+	}
+	return reason, prev
+}
+
+// getBootHistory determines how the previous boot ended and how long it lasted, using
+// pstore crash records, the watchdog boot status, and the persistent journal
+func (h *HandlerManager) getBootHistory(ctx context.Context) (bootReason, *previousBoot) {
+	if runtime.GOOS != "linux" {
+		return bootReason{Reason: bootReasonUnknown, Note: "boot reason detection is only supported on Linux"}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, bootTimeout)
+	defer cancel()
+
+	var prev *previousBoot
+	var prevStart time.Time
+	if out, err := h.privilegedCommand(ctx, "journalctl", "--list-boots", "--no-pager", "-o", "json").Output(); err == nil {
+		if boots, err := parseJournalBoots(out); err == nil {
+			if b, ok := findBoot(boots, -1); ok {
+				prevStart = time.UnixMicro(b.FirstEntry)
+				end := time.UnixMicro(b.LastEntry)
+				d := end.Sub(prevStart).Truncate(time.Second)
+				prev = &previousBoot{
+					Start:           prevStart.UTC().Format(time.RFC3339),
+					End:             end.UTC().Format(time.RFC3339),
+					DurationSeconds: int64(d / time.Second),
+					DurationHuman:   h.human.Duration(d),
+				}
+			}
+		}
+	}
+
+	// A crash record written after the previous boot started means it ended in a panic.
+	// Without a journal only the live pstore is trusted, since the archive keeps old crashes.
+	for i, dir := range pstoreDirs {
+		if i > 0 && prevStart.IsZero() {
+			break
+		}
+		if records := pstoreCrashRecords(dir, prevStart); len(records) > 0 {
+			return bootReason{Reason: bootReasonKernelPanic, Evidence: records}, prev
+		}
+	}
+
+	if data, err := os.ReadFile(watchdogBootStatus); err == nil {
+		if status, err := strconv.ParseUint(strings.TrimSpace(string(data)), 0, 32); err == nil && status&wdiofCardReset != 0 {
+			return bootReason{Reason: bootReasonWatchdog, Evidence: []string{watchdogBootStatus + " = " + strings.TrimSpace(string(data))}}, prev
+		}
+	}
+
+	if prev == nil {
+		return bootReason{Reason: bootReasonUnknown, Note: "no previous boot in the journal; enable a persistent journal (Storage=persistent) to detect unclean shutdowns"}, nil
+	}
+
+	out, err := h.privilegedCommand(ctx, "journalctl", "-b", "-1", "-n", "50", "--no-pager", "-o", "cat").Output()
+	if err != nil {
+		return bootReason{Reason: bootReasonUnknown, Note: "failed to read the previous boot's journal"}, prev
+	}
+	return classifyShutdown(string(out)), prev
+}
+
+// parseJournalBoots parses journalctl --list-boots output in JSON or the classic table form
+func parseJournalBoots(out []byte) ([]journalBoot, error) {
+	var boots []journalBoot
+	if err := json.Unmarshal(out, &boots); err == nil {
+		return boots, nil
+	}
+
+	// "-1 0123abcd... Mon 2024-01-01 10:00:00 UTC—Tue 2024-01-02 11:00:00 UTC"
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		idx, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		stamps := journalTimestampRe.FindAllStringSubmatch(scanner.Text(), 2)
+		if len(stamps) != 2 {
+			continue
+		}
+		first, err1 := time.Parse("2006-01-02 15:04:05 MST", stamps[0][1])
+		last, err2 := time.Parse("2006-01-02 15:04:05 MST", stamps[1][1])
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		boots = append(boots, journalBoot{Index: idx, FirstEntry: first.UnixMicro(), LastEntry: last.UnixMicro()})
+	}
+	return boots, scanner.Err()
+}
+
+// journalTimestampRe matches the timestamps in journalctl's boot table
+var journalTimestampRe = regexp.MustCompile(`\w{3} (\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2} [A-Z][A-Za-z0-9+-]*)`)
+
+// findBoot returns the boot with the given journal index (0 = current, -1 = previous)
+func findBoot(boots []journalBoot, index int) (journalBoot, bool) {
+	for _, b := range boots {
+		if b.Index == index {
+			return b, true
+		}
+	}
+	return journalBoot{}, false
+}
+
+// classifyShutdown inspects the last journal messages of a boot for signs of an orderly
+// shutdown; a journal that simply stops points to power loss or a hard reset
+func classifyShutdown(tail string) bootReason {
+	var evidence []string
+	reason := ""
+	for _, line := range strings.Split(tail, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case containsAny(line, rebootMarkers):
+			reason = bootReasonCleanReboot
+			evidence = append(evidence, line)
+		case containsAny(line, poweroffMarkers):
+			if reason != bootReasonCleanReboot {
+				reason = bootReasonCleanShutdown
+			}
+			evidence = append(evidence, line)
+		case containsAny(line, shutdownMarkers):
+			if reason == "" {
+				reason = bootReasonCleanReboot
+			}
+			evidence = append(evidence, line)
+		}
+	}
+
+	if reason == "" {
+		return bootReason{
+			Reason: bootReasonUncleanShutdown,
+			Note:   "the previous boot's journal ends without shutdown messages, which points to power loss, a hard reset, or a hang",
+		}
+	}
+	if len(evidence) > 3 {
+		evidence = evidence[len(evidence)-3:]
+	}
+	return bootReason{Reason: reason, Evidence: evidence}
+}
+
+// containsAny reports whether s contains any of the substrings
+func containsAny(s string, subs []string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// pstoreCrashRecords lists dmesg crash records under dir modified at or after since,
+// newest first. Records in subdirectories (the systemd-pstore archive) are included.
+func pstoreCrashRecords(dir string, since time.Time) []string {
+	var paths []string
+	for _, f := range pstoreFiles(dir, []string{pstoreTypeDmesg}) {
+		if !f.modTime.Before(since) {
+			paths = append(paths, f.path)
+		}
+	}
+	if paths == nil {
+		paths = []string{}
+	}
+	return paths
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseJournalBoots(t *testing.T) {
+	jsonOut := []byte(`[{"index":-1,"boot_id":"aaa","first_entry":1700000000000000,"last_entry":1700086400000000},` +
+		`{"index":0,"boot_id":"bbb","first_entry":1700090000000000,"last_entry":1700090100000000}]`)
+	boots, err := parseJournalBoots(jsonOut)
+	if err != nil {
+		t.Fatalf("parseJournalBoots(json) failed: %v", err)
+	}
+	prev, ok := findBoot(boots, -1)
+	if !ok || prev.LastEntry-prev.FirstEntry != 86400*1e6 {
+		t.Errorf("Unexpected previous boot: %+v", prev)
+	}
+
+	tableOut := []byte("IDX BOOT ID                          FIRST ENTRY                 LAST ENTRY\n" +
+		" -1 0123456789abcdef0123456789abcdef Mon 2024-01-01 10:00:00 UTC Tue 2024-01-02 11:30:00 UTC\n" +
+		"  0 fedcba9876543210fedcba9876543210 Tue 2024-01-02 11:31:00 UTC Tue 2024-01-02 12:00:00 UTC\n")
+	boots, err = parseJournalBoots(tableOut)
+	if err != nil {
+		t.Fatalf("parseJournalBoots(table) failed: %v", err)
+	}
+	prev, ok = findBoot(boots, -1)
+	if !ok {
+		t.Fatalf("Expected a previous boot in %+v", boots)
+	}
+	if d := time.UnixMicro(prev.LastEntry).Sub(time.UnixMicro(prev.FirstEntry)); d != 25*time.Hour+30*time.Minute {
+		t.Errorf("Expected a 25h30m previous boot, got %s", d)
+	}
+}
+
+func TestClassifyShutdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		tail     string
+		expected string
+	}{
+		{"reboot", "Stopped target Graphical Interface.\nReached target System Reboot.\nShutting down.\nsystemd-shutdown[1]: Syncing filesystems", bootReasonCleanReboot},
+		{"poweroff", "Reached target System Power Off.\nShutting down.\nJournal stopped", bootReasonCleanShutdown},
+		{"power loss", "kernel: usb 1-1.3: new high-speed USB device\nsshd[812]: Accepted publickey for pi", bootReasonUncleanShutdown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := classifyShutdown(tt.tail)
+			if got.Reason != tt.expected {
+				t.Errorf("classifyShutdown = %+v, expected %s", got, tt.expected)
+			}
+			if tt.expected == bootReasonUncleanShutdown && got.Note == "" {
+				t.Error("Expected a note explaining the unclean shutdown")
+			}
+		})
+	}
+}
+
+func TestPstoreCrashRecords(t *testing.T) {
+	dir := t.TempDir()
+	oldRecord := filepath.Join(dir, "20240101000000", "dmesg-ramoops-0")
+	newRecord := filepath.Join(dir, "dmesg-efi-170000000001001")
+	for _, path := range []string{oldRecord, newRecord, filepath.Join(dir, "console-ramoops-0")} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("Kernel panic - not syncing"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	since := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(oldRecord, since.Add(-time.Hour), since.Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := pstoreCrashRecords(dir, since); len(got) != 1 || got[0] != newRecord {
+		t.Errorf("Expected only the recent dmesg record, got %v", got)
+	}
+	if got := pstoreCrashRecords(dir, time.Time{}); len(got) != 2 {
+		t.Errorf("Expected both dmesg records without a cutoff, got %v", got)
+	}
+	if got := pstoreCrashRecords(filepath.Join(dir, "missing"), time.Time{}); len(got) != 0 {
+		t.Errorf("Expected no records for a missing directory, got %v", got)
+	}
+}
@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/shirou/gopsutil/v3/host"
+)
+
+// bootDiagTimeout bounds reading the current boot's kernel log
+const bootDiagTimeout = 10 * time.Second
+
+// Failures listed per category by get_boot_diagnostics
+const (
+	defaultBootDiagLimit = 20
+	maxBootDiagLimit     = 200
+)
+
+// Boot failure categories
+const (
+	bootFailProbe    = "probe"
+	bootFailDeferred = "deferred_probe"
+	bootFailFirmware = "firmware"
+	bootFailModule   = "module"
+)
+
+// bootFailurePattern recognizes one kind of kernel log failure. The named groups
+// device, driver, firmware, module, and error fill the matching bootFailure fields.
+type bootFailurePattern struct {
+	kind string
+	re   *regexp.Regexp
+}
+
+// bootFailurePatterns are checked in order; the first match classifies a line
+var bootFailurePatterns = []bootFailurePattern{
+	// Driver core, since 5.x: "i2c 1-0050: probe with driver at24 failed with error -121"
+	{bootFailProbe, regexp.MustCompile(`^\S+ (?P<device>\S+): probe with driver (?P<driver>\S+) failed with error (?P<error>-?\d+)`)},
+	// Driver core, older kernels: "at24: probe of 1-0050 failed with error -121"
+	{bootFailProbe, regexp.MustCompile(`^(?P<driver>\S+): probe of (?P<device>\S+) failed with error (?P<error>-?\d+)`)},
+	// "platform soc:sound: deferred probe pending: snd-soc-dummy: ..."
+	{bootFailDeferred, regexp.MustCompile(`^\S+ (?P<device>\S+): deferred probe pending`)},
+	{bootFailFirmware, regexp.MustCompile(`^(?:(?P<driver>[\w-]+) (?P<device>\S+): )?Direct firmware load for (?P<firmware>\S+) failed with error (?P<error>-?\d+)`)},
+	{bootFailFirmware, regexp.MustCompile(`^(?:(?P<driver>[\w-]+) (?P<device>\S+): )?firmware: failed to load (?P<firmware>\S+) \((?P<error>-?\d+)\)`)},
+	// systemd-modules-load
+	{bootFailModule, regexp.MustCompile(`^Failed to find module '(?P<module>[^']+)'`)},
+	{bootFailModule, regexp.MustCompile(`^Failed to insert module '(?P<module>[^']+)'`)},
+	{bootFailModule, regexp.MustCompile(`^(?P<module>[\w-]+): Unknown symbol \S+`)},
+	{bootFailModule, regexp.MustCompile(`^(?P<module>[\w-]+): disagrees about version of symbol`)},
+	{bootFailModule, regexp.MustCompile(`^Loading of unsigned module is rejected`)},
+	// Driver-specific wording: "brcmfmac: brcmf_sdio_probe: ... failed", "sdhci-iproc: probe failed"
+	{bootFailProbe, regexp.MustCompile(`(?i)^(?P<driver>[\w-]+): .*\bprobe\b.*\bfail`)},
+	{bootFailFirmware, regexp.MustCompile(`(?i)failed to (?:load|request) firmware`)},
+}
+
+// dmesgLineRe splits a dmesg line into its optional "<level>", seconds since boot, and message
+var dmesgLineRe = regexp.MustCompile(`^(?:<(\d+)>)?\[\s*(\d+\.\d+)\]\s?(.*)$`)
+
+// errnoNames names the kernel error codes most often seen in probe and firmware failures
+var errnoNames = map[int]string{
+	-1: "EPERM", -2: "ENOENT", -5: "EIO", -6: "ENXIO", -12: "ENOMEM", -13: "EACCES",
+	-16: "EBUSY", -19: "ENODEV", -22: "EINVAL", -71: "EPROTO", -95: "EOPNOTSUPP",
+	-110: "ETIMEDOUT", -121: "EREMOTEIO", -517: "EPROBE_DEFER",
+}
+
+// errnoHints explain what an error code usually means for a device failing at boot
+var errnoHints = map[int]string{
+	-2:   "a file or resource was not found; for firmware, install the package that ships it (e.g. linux-firmware or firmware-brcm80211)",
+	-5:   "I/O error talking to the device; check wiring, power, and the bus speed",
+	-6:   "the device did not answer at its address; check that the board is seated and the address or overlay parameters are right",
+	-19:  "no such device; the hardware is absent or the device tree describes hardware that is not fitted",
+	-110: "the device timed out; check power and wiring",
+	-121: "no acknowledgement on the I2C bus; the HAT or sensor is missing, unpowered, or at a different address",
+	-517: "a dependency (clock, regulator, GPIO, or another driver) never appeared; often a missing or mismatched device tree overlay",
+}
+
+// bootFailure is one device, firmware, or module failure from the current boot's log
+type bootFailure struct {
+	Kind      string `json:"kind"`
+	Device    string `json:"device,omitempty"`
+	Driver    string `json:"driver,omitempty"`
+	Firmware  string `json:"firmware,omitempty"`
+	Module    string `json:"module,omitempty"`
+	Error     *int   `json:"error,omitempty"`
+	ErrorName string `json:"error_name,omitempty"`
+	Hint      string `json:"hint,omitempty"`
+	Message   string `json:"message"`
+	FirstSeen string `json:"first_seen,omitempty"`
+	Count     int    `json:"count"`
+}
+
+// HandleGetBootDiagnostics summarizes devices that failed to probe, firmware that failed
+// to load, and modules that could not be loaded during the current boot
+func (h *HandlerManager) HandleGetBootDiagnostics(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	limit := defaultBootDiagLimit
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if l, ok := args["limit"].(float64); ok && l > 0 {
+			limit = min(int(l), maxBootDiagLimit)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, bootDiagTimeout)
+	defer cancel()
+	entries, source, err := h.readBootKernelLog(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read the kernel log: %v (run the server as root, in the systemd-journal group, or with kernel.dmesg_restrict=0)", err)), nil
+	}
+
+	failures := classifyBootFailures(entries)
+	byKind := map[string][]bootFailure{
+		bootFailProbe: {}, bootFailDeferred: {}, bootFailFirmware: {}, bootFailModule: {},
+	}
+	for _, f := range failures {
+		byKind[f.Kind] = append(byKind[f.Kind], f)
+	}
+	counts := map[string]int{}
+	for kind, list := range byKind {
+		counts[kind] = len(list)
+		if len(list) > limit {
+			byKind[kind] = list[:limit]
+		}
+	}
+
+	result := map[string]interface{}{
+		"source":          source,
+		"lines_scanned":   len(entries),
+		"probe_failures":  byKind[bootFailProbe],
+		"deferred_probes": byKind[bootFailDeferred],
+		"firmware":        byKind[bootFailFirmware],
+		"modules":         byKind[bootFailModule],
+		"counts":          counts,
+		"total":           len(failures),
+	}
+	if bootTime, err := host.BootTimeWithContext(ctx); err == nil {
+		result["boot_time"] = time.Unix(int64(bootTime), 0).UTC().Format(time.RFC3339)
+	}
+	if len(failures) == 0 {
+		result["note"] = "No probe, firmware, or module failures in the current boot's kernel log"
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// readBootKernelLog returns the current boot's kernel messages, preferring the journal,
+// which also holds systemd-modules-load's errors, and falling back to dmesg
+func (h *HandlerManager) readBootKernelLog(ctx context.Context) ([]journalEntry, string, error) {
+	out, err := h.privilegedCommand(ctx, "journalctl", "-b", "0", "--no-pager", "-o", "json",
+		"_TRANSPORT=kernel", "+", "SYSLOG_IDENTIFIER=systemd-modules-load").Output()
+	if err == nil {
+		if entries := parseJournalEntries(out); len(entries) > 0 {
+			return entries, "journal", nil
+		}
+	}
+
+	out, err = h.privilegedCommand(ctx, "dmesg").Output()
+	if err != nil {
+		return nil, "", fmt.Errorf("dmesg failed: %v", err)
+	}
+	var boot time.Time
+	if bootTime, err := host.BootTimeWithContext(ctx); err == nil {
+		boot = time.Unix(int64(bootTime), 0)
+	}
+	return parseDmesg(string(out), boot), "dmesg", nil
+}
+
+// parseDmesg parses dmesg output into journal entries, converting seconds since boot to
+// wall-clock time when boot is known
+func parseDmesg(out string, boot time.Time) []journalEntry {
+	entries := []journalEntry{}
+	for _, line := range strings.Split(out, "\n") {
+		m := dmesgLineRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		e := journalEntry{Priority: 6, Message: truncateString(strings.TrimSpace(m[3]), journalMessageLimit)}
+		if p, err := strconv.Atoi(m[1]); err == nil {
+			e.Priority = p & 7
+		}
+		if secs, err := strconv.ParseFloat(m[2], 64); err == nil && !boot.IsZero() {
+			e.Time = boot.Add(time.Duration(secs * float64(time.Second))).UTC().Format(time.RFC3339)
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// classifyBootFailures matches log lines against bootFailurePatterns and merges repeats
+// of the same failure, in the order they first appeared
+func classifyBootFailures(entries []journalEntry) []bootFailure {
+	failures := []bootFailure{}
+	seen := map[string]int{}
+	for _, e := range entries {
+		f, ok := matchBootFailure(e.Message)
+		if !ok {
+			continue
+		}
+		// The firmware loader logs a missing file once per attempt, from more than one place
+		key := strings.Join([]string{f.Kind, f.Device, f.Driver, f.Firmware, f.Module}, "|")
+		switch {
+		case f.Firmware != "":
+			key = f.Kind + "|" + f.Firmware
+		case f.Device == "" && f.Driver == "" && f.Module == "":
+			key += "|" + f.Message
+		}
+		if i, ok := seen[key]; ok {
+			failures[i].Count++
+			continue
+		}
+		f.FirstSeen = e.Time
+		seen[key] = len(failures)
+		failures = append(failures, f)
+	}
+	return failures
+}
+
+// matchBootFailure classifies one log message
+func matchBootFailure(msg string) (bootFailure, bool) {
+	for _, p := range bootFailurePatterns {
+		m := p.re.FindStringSubmatch(msg)
+		if m == nil {
+			continue
+		}
+		f := bootFailure{Kind: p.kind, Message: msg, Count: 1}
+		for i, name := range p.re.SubexpNames() {
+			switch name {
+			case "device":
+				f.Device = m[i]
+			case "driver":
+				f.Driver = m[i]
+			case "firmware":
+				f.Firmware = m[i]
+			case "module":
+				f.Module = m[i]
+			case "error":
+				if code, err := strconv.Atoi(m[i]); err == nil {
+					if code > 0 {
+						code = -code
+					}
+					f.Error = &code
+					f.ErrorName = errnoNames[code]
+					f.Hint = errnoHints[code]
+				}
+			}
+		}
+		if f.Kind == bootFailDeferred {
+			f.Hint = errnoHints[-517]
+		}
+		return f, true
+	}
+	return bootFailure{}, false
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDmesg(t *testing.T) {
+	boot := time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)
+	out := "[    0.000000] Booting Linux on physical CPU 0x0\n<3>[   12.500000] at24: probe of 1-0050 failed with error -121\nnot a log line\n"
+	entries := parseDmesg(out, boot)
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %+v", entries)
+	}
+	if e := entries[1]; e.Priority != 3 || e.Time != "2024-05-02T12:00:12Z" || e.Message != "at24: probe of 1-0050 failed with error -121" {
+		t.Errorf("Unexpected entry: %+v", e)
+	}
+	if entries[0].Priority != 6 {
+		t.Errorf("Expected the default priority, got %d", entries[0].Priority)
+	}
+}
+
+func TestClassifyBootFailures(t *testing.T) {
+	messages := []string{
+		"i2c 1-0050: probe with driver at24 failed with error -121",
+		"at24: probe of 1-0051 failed with error -6",
+		"platform soc:sound: deferred probe pending: snd-soc-dummy: no codec",
+		"brcmfmac mmc1:0001:1: Direct firmware load for brcm/brcmfmac43455-sdio.txt failed with error -2",
+		"brcmfmac mmc1:0001:1: firmware: failed to load brcm/brcmfmac43455-sdio.txt (-2)",
+		"brcmfmac mmc1:0001:1: Direct firmware load for brcm/brcmfmac43455-sdio.txt failed with error -2",
+		"Failed to find module 'i2c-dev'",
+		"hello: Unknown symbol foo_bar (err -2)",
+		"sdhci-iproc: probe failed",
+		"usb 1-1: new high-speed USB device number 2 using xhci_hcd",
+	}
+	entries := make([]journalEntry, len(messages))
+	for i, m := range messages {
+		entries[i] = journalEntry{Message: m}
+	}
+	failures := classifyBootFailures(entries)
+	if len(failures) != 7 {
+		t.Fatalf("Expected 7 failures, got %+v", failures)
+	}
+
+	if f := failures[0]; f.Kind != bootFailProbe || f.Device != "1-0050" || f.Driver != "at24" || f.Error == nil || *f.Error != -121 || f.ErrorName != "EREMOTEIO" || f.Hint == "" {
+		t.Errorf("Unexpected probe failure: %+v", f)
+	}
+	if f := failures[1]; f.Device != "1-0051" || f.Driver != "at24" || f.ErrorName != "ENXIO" {
+		t.Errorf("Unexpected legacy probe failure: %+v", f)
+	}
+	if f := failures[2]; f.Kind != bootFailDeferred || f.Device != "soc:sound" || f.Hint == "" {
+		t.Errorf("Unexpected deferred probe: %+v", f)
+	}
+	if f := failures[3]; f.Kind != bootFailFirmware || f.Firmware != "brcm/brcmfmac43455-sdio.txt" || f.Count != 3 || f.ErrorName != "ENOENT" {
+		t.Errorf("Expected repeated firmware failures to merge, got %+v", f)
+	}
+	if f := failures[4]; f.Kind != bootFailModule || f.Module != "i2c-dev" {
+		t.Errorf("Unexpected module failure: %+v", f)
+	}
+	if f := failures[5]; f.Kind != bootFailModule || f.Module != "hello" {
+		t.Errorf("Unexpected unknown symbol failure: %+v", f)
+	}
+	if f := failures[6]; f.Kind != bootFailProbe || f.Driver != "sdhci-iproc" || f.Error != nil {
+		t.Errorf("Unexpected generic probe failure: %+v", f)
+	}
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Raspberry Pi boot files: the firmware partition holding autoboot.txt and staged EEPROM
+// updates, and the device tree node where the bootloader records how it booted
+var (
+	bootFirmwareDirs = []string{"/boot/firmware", "/boot"}
+	dtBootloaderPath = "/proc/device-tree/chosen/bootloader"
+)
+
+// kernelCmdlinePath holds the kernel command line, where A/B bootloaders pass the slot
+var kernelCmdlinePath = "/proc/cmdline"
+
+// Update framework U-Boot variables, reported verbatim when set
+var ubootSlotVars = []string{
+	// Mender
+	"mender_boot_part", "mender_uboot_root", "upgrade_available",
+	// RAUC
+	"BOOT_ORDER", "BOOT_A_LEFT", "BOOT_B_LEFT",
+	// SWUpdate
+	"ustate",
+	// U-Boot boot counting
+	"bootcount", "bootlimit", "altbootcmd",
+}
+
+// slotCmdlineParams are kernel parameters naming the booted slot
+var slotCmdlineParams = []string{"root", "rauc.slot", "mender.part"}
+
+// HandleGetBootSlots returns the A/B boot slot state: Raspberry Pi tryboot and
+// autoboot.txt, U-Boot update variables with boot counting, and staged firmware updates
+func (h *HandlerManager) HandleGetBootSlots(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	result := map[string]interface{}{}
+	warnings := []string{}
+
+	if data, err := os.ReadFile(kernelCmdlinePath); err == nil {
+		if params := parseSlotCmdline(string(data)); len(params) > 0 {
+			result["kernel_cmdline"] = params
+		}
+	}
+
+	if rpi, ok := readRPiBootSlots(bootFirmwareDirs, dtBootloaderPath); ok {
+		result["raspberry_pi"] = rpi
+		if rpi["tryboot"] == true {
+			warnings = append(warnings, "This is a tryboot: the firmware falls back to the default partition on the next reboot unless autoboot.txt is updated to commit this slot")
+		}
+		if staged, ok := rpi["staged_eeprom_update"].([]string); ok && len(staged) > 0 {
+			warnings = append(warnings, "A bootloader EEPROM update is staged and will be flashed on the next reboot")
+		}
+	}
+
+	if h.caps.FwPrintenv {
+		out, err := exec.CommandContext(ctx, "fw_printenv").Output()
+		if err != nil {
+			result["uboot_error"] = fmt.Sprintf("fw_printenv failed: %v", err)
+		} else {
+			uboot := parseUBootEnv(string(out))
+			result["uboot"] = uboot
+			warnings = append(warnings, ubootSlotWarnings(uboot)...)
+		}
+	}
+
+	if h.caps.RpiEeprom {
+		// rpi-eeprom-update exits non-zero when an update is available, so parse any output
+		out, _ := exec.CommandContext(ctx, "rpi-eeprom-update").Output()
+		if eeprom := parseEEPROMStatus(string(out)); len(eeprom) > 0 {
+			result["eeprom"] = eeprom
+		}
+	}
+	result["warnings"] = warnings
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// parseSlotCmdline picks the slot-identifying parameters out of the kernel command line
+func parseSlotCmdline(cmdline string) map[string]string {
+	params := map[string]string{}
+	for _, field := range strings.Fields(cmdline) {
+		key, value, ok := strings.Cut(field, "=")
+		if ok && contains(slotCmdlineParams, key) {
+			params[key] = value
+		}
+	}
+	return params
+}
+
+// readRPiBootSlots reports the partition the Pi bootloader booted, whether this is a
+// tryboot, the autoboot.txt A/B configuration, and any EEPROM update staged for the next boot
+func readRPiBootSlots(bootDirs []string, dtPath string) (map[string]interface{}, bool) {
+	rpi := map[string]interface{}{}
+	if n, ok := readDTUint32(filepath.Join(dtPath, "partition")); ok {
+		rpi["booted_partition"] = n
+	}
+	if n, ok := readDTUint32(filepath.Join(dtPath, "tryboot")); ok {
+		rpi["tryboot"] = n == 1
+	}
+	for _, dir := range bootDirs {
+		data, err := os.ReadFile(filepath.Join(dir, "autoboot.txt"))
+		if err != nil {
+			continue
+		}
+		rpi["autoboot"] = parseAutoboot(string(data))
+		rpi["autoboot_path"] = filepath.Join(dir, "autoboot.txt")
+		break
+	}
+	for _, dir := range bootDirs {
+		// rpi-eeprom-update -a stages the images with recovery.bin, which flashes them on the
+		// next boot and then renames itself to RECOVERY.000 so it runs only once
+		if _, err := os.Stat(filepath.Join(dir, "recovery.bin")); err != nil {
+			continue
+		}
+		var staged []string
+		for _, name := range []string{"pieeprom.upd", "vl805.bin"} {
+			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				staged = append(staged, name)
+			}
+		}
+		if len(staged) > 0 {
+			rpi["staged_eeprom_update"] = staged
+			break
+		}
+	}
+	return rpi, len(rpi) > 0
+}
+
+// readDTUint32 reads a device tree property holding one big-endian 32-bit cell
+func readDTUint32(path string) (uint32, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) != 4 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint32(data), true
+}
+
+// parseAutoboot parses autoboot.txt into its sections:
+//
+//	[all]
+//	tryboot_a_b=1
+//	boot_partition=2
+//	[tryboot]
+//	boot_partition=3
+func parseAutoboot(data string) map[string]map[string]string {
+	sections := map[string]map[string]string{}
+	section := "all"
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.Trim(line, "[]")
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		if sections[section] == nil {
+			sections[section] = map[string]string{}
+		}
+		sections[section][strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return sections
+}
+
+// parseUBootEnv keeps the A/B and boot counting variables from fw_printenv output
+func parseUBootEnv(out string) map[string]string {
+	env := map[string]string{}
+	for _, line := range strings.Split(out, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if ok && contains(ubootSlotVars, key) {
+			env[key] = value
+		}
+	}
+	return env
+}
+
+// ubootSlotWarnings flags an update awaiting commit and a boot counter at its limit,
+// which means the bootloader is about to roll back or already has
+func ubootSlotWarnings(env map[string]string) []string {
+	var warnings []string
+	if env["upgrade_available"] == "1" {
+		warnings = append(warnings, "An update is installed but not committed (upgrade_available=1); it rolls back if the device reboots before the update client commits it")
+	}
+	if env["ustate"] == "1" {
+		warnings = append(warnings, "SWUpdate installed an update that is still being tested (ustate=1)")
+	}
+	if env["ustate"] == "3" {
+		warnings = append(warnings, "SWUpdate reports the last update failed (ustate=3)")
+	}
+	count, err1 := strconv.Atoi(env["bootcount"])
+	limit, err2 := strconv.Atoi(env["bootlimit"])
+	if err1 == nil && err2 == nil && limit > 0 && count >= limit {
+		warnings = append(warnings, fmt.Sprintf("Boot counter %d has reached the limit of %d; the bootloader runs altbootcmd to roll back", count, limit))
+	}
+	for _, slot := range []string{"A", "B"} {
+		if left, ok := env["BOOT_"+slot+"_LEFT"]; ok && left == "0" {
+			warnings = append(warnings, fmt.Sprintf("RAUC slot %s has no boot attempts left and will be skipped", slot))
+		}
+	}
+	return warnings
+}
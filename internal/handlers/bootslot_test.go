@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestReadRPiBootSlots(t *testing.T) {
+	dir := t.TempDir()
+	boot := filepath.Join(dir, "boot")
+	dt := filepath.Join(dir, "bootloader")
+	writeSysfsFiles(t, boot, map[string]string{
+		"autoboot.txt": "[all]\ntryboot_a_b=1\nboot_partition=2\n# comment\n[tryboot]\nboot_partition=3",
+		"recovery.bin": "", "pieeprom.upd": "", "vl805.sig": "",
+	})
+	if err := os.MkdirAll(dt, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	for name, cell := range map[string][]byte{"partition": {0, 0, 0, 3}, "tryboot": {0, 0, 0, 1}} {
+		if err := os.WriteFile(filepath.Join(dt, name), cell, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	origDirs, origDT, origCmdline := bootFirmwareDirs, dtBootloaderPath, kernelCmdlinePath
+	defer func() { bootFirmwareDirs, dtBootloaderPath, kernelCmdlinePath = origDirs, origDT, origCmdline }()
+	bootFirmwareDirs = []string{filepath.Join(dir, "missing"), boot}
+	dtBootloaderPath = dt
+	kernelCmdlinePath = filepath.Join(dir, "cmdline")
+	if err := os.WriteFile(kernelCmdlinePath, []byte("console=tty1 root=/dev/mmcblk0p3 rootwait rauc.slot=B\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewHandlerManager(&config.Config{})
+	res, err := h.HandleGetBootSlots(context.Background(), mcp.CallToolRequest{})
+	checkToolResult(t, res, err, []string{"kernel_cmdline", "raspberry_pi", "warnings"})
+	var result struct {
+		Cmdline map[string]string `json:"kernel_cmdline"`
+		RPi     struct {
+			Partition int                          `json:"booted_partition"`
+			Tryboot   bool                         `json:"tryboot"`
+			Autoboot  map[string]map[string]string `json:"autoboot"`
+			Staged    []string                     `json:"staged_eeprom_update"`
+		} `json:"raspberry_pi"`
+		Warnings []string `json:"warnings"`
+	}
+	if err := json.Unmarshal([]byte(resultText(res)), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Cmdline["root"] != "/dev/mmcblk0p3" || result.Cmdline["rauc.slot"] != "B" || len(result.Cmdline) != 2 {
+		t.Errorf("Unexpected kernel parameters: %v", result.Cmdline)
+	}
+	if result.RPi.Partition != 3 || !result.RPi.Tryboot || result.RPi.Autoboot["all"]["boot_partition"] != "2" || result.RPi.Autoboot["tryboot"]["boot_partition"] != "3" {
+		t.Errorf("Unexpected Pi boot state: %+v", result.RPi)
+	}
+	if len(result.RPi.Staged) != 1 || result.RPi.Staged[0] != "pieeprom.upd" {
+		t.Errorf("Expected the staged EEPROM image, got %v", result.RPi.Staged)
+	}
+	if len(result.Warnings) != 2 || !strings.Contains(result.Warnings[0], "tryboot") {
+		t.Errorf("Expected tryboot and staged update warnings, got %v", result.Warnings)
+	}
+
+	// recovery.bin renames itself once the update is flashed
+	if err := os.Remove(filepath.Join(boot, "recovery.bin")); err != nil {
+		t.Fatal(err)
+	}
+	if rpi, _ := readRPiBootSlots(bootFirmwareDirs, dt); rpi["staged_eeprom_update"] != nil {
+		t.Errorf("Expected no staged update after flashing, got %v", rpi["staged_eeprom_update"])
+	}
+}
+
+func TestUBootSlotWarnings(t *testing.T) {
+	env := parseUBootEnv("arch=arm\nbootcount=3\nbootlimit=3\nupgrade_available=1\nmender_boot_part=3\nBOOT_ORDER=B A\nBOOT_A_LEFT=0\nBOOT_B_LEFT=3\n")
+	if env["BOOT_ORDER"] != "B A" || env["mender_boot_part"] != "3" || env["arch"] != "" {
+		t.Errorf("Unexpected U-Boot variables: %v", env)
+	}
+	warnings := ubootSlotWarnings(env)
+	if len(warnings) != 3 || !strings.Contains(warnings[0], "not committed") || !strings.Contains(warnings[1], "limit of 3") || !strings.Contains(warnings[2], "slot A") {
+		t.Errorf("Unexpected warnings: %v", warnings)
+	}
+	if w := ubootSlotWarnings(parseUBootEnv("bootcount=1\nbootlimit=3\nupgrade_available=0\n")); len(w) != 0 {
+		t.Errorf("Expected no warnings for a committed slot, got %v", w)
+	}
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// budgetMarkerReserve leaves room for the truncation marker added to a trimmed result
+const budgetMarkerReserve = 1024
+
+// narrowingParams are tool arguments that shrink a result, suggested when it is truncated
+var narrowingParams = []string{
+	"limit", "fields", "group_by", "status", "kind", "user", "name_pattern", "min_cpu", "min_memory",
+	"container_id", "runtime", "namespace", "interfaces", "interface", "mount_points", "devices",
+	"metric", "labels", "range", "bucket",
+}
+
+// truncation describes one list shortened to fit the response budget
+type truncation struct {
+	Field    string `json:"field"`
+	Original int    `json:"original_count"`
+	Returned int    `json:"returned_count"`
+}
+
+// budgetTool wraps a tool handler so that a result larger than --max-response-bytes has its
+// longest lists cut down, with a truncation marker and a hint naming the tool's filters
+func (h *HandlerManager) budgetTool(tool mcp.Tool, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	var filters []string
+	for _, name := range narrowingParams {
+		if _, ok := tool.InputSchema.Properties[name]; ok {
+			filters = append(filters, name)
+		}
+	}
+
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := handler(ctx, request)
+		budget := h.cfg.MaxResponseBytes
+		if err != nil || result == nil || result.IsError || budget <= 0 || len(result.Content) != 1 {
+			return result, err
+		}
+		text, ok := result.Content[0].(mcp.TextContent)
+		if !ok || len(text.Text) <= budget {
+			return result, err
+		}
+
+		h.truncatedResponses.Add(1)
+		return mcp.NewToolResultText(applyResponseBudget(text.Text, budget, filters)), nil
+	}
+}
+
+// applyResponseBudget shrinks a JSON object result to fit within budget bytes. Results
+// that are not JSON objects are cut at the byte limit.
+func applyResponseBudget(text string, budget int, filters []string) string {
+	hint := fmt.Sprintf("Result exceeded the %d-byte response budget (--max-response-bytes). ", budget)
+	if len(filters) > 0 {
+		hint += "Narrow the request with: " + strings.Join(filters, ", ") + "."
+	} else {
+		hint += "Request fewer items or raise --max-response-bytes."
+	}
+
+	// The ordered decoder keeps the tool's field order and exact numbers, such as byte
+	// counters beyond 2^53
+	data, err := decodeJSONObject(text)
+	if err != nil {
+		cut := budget - len(hint) - 32
+		if cut < 0 {
+			cut = 0
+		}
+		return strings.ToValidUTF8(text[:cut], "") + "\n[truncated] " + hint
+	}
+
+	target := budget - budgetMarkerReserve
+	if target < 0 {
+		target = 0
+	}
+	truncations := shrinkLists(data, target)
+	data.set("truncated", true)
+	data.set("truncation", map[string]interface{}{
+		"limit_bytes": budget,
+		"lists":       truncations,
+		"hint":        hint,
+	})
+
+	jsonBytes, err := json.Marshal(data)
+	if err != nil {
+		return text
+	}
+	return string(jsonBytes)
+}
+
+// listRef locates a list inside the decoded result so it can be shortened in place
+type listRef struct {
+	path   string
+	parent *jsonObject
+	key    string
+	items  []interface{}
+	size   int
+}
+
+// shrinkLists repeatedly shortens the largest list until the encoded object fits target
+// bytes or nothing is left to cut
+func shrinkLists(data *jsonObject, target int) []truncation {
+	lists := findLists(data, "", 0)
+	sort.SliceStable(lists, func(i, j int) bool { return lists[i].size > lists[j].size })
+
+	truncations := []truncation{}
+	for _, l := range lists {
+		if encodedSize(data) <= target {
+			break
+		}
+
+		// Binary search the longest prefix that still fits
+		lo, hi := 0, len(l.items)
+		for lo < hi {
+			mid := (lo + hi + 1) / 2
+			l.parent.values[l.key] = l.items[:mid]
+			if encodedSize(data) <= target {
+				lo = mid
+			} else {
+				hi = mid - 1
+			}
+		}
+		l.parent.values[l.key] = l.items[:lo]
+		truncations = append(truncations, truncation{Field: l.path, Original: len(l.items), Returned: lo})
+	}
+	return truncations
+}
+
+// findLists collects non-empty lists in nested objects up to three levels deep
+func findLists(obj *jsonObject, prefix string, depth int) []listRef {
+	var lists []listRef
+	for _, key := range obj.keys {
+		v := obj.values[key]
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		switch val := v.(type) {
+		case []interface{}:
+			if len(val) > 0 {
+				lists = append(lists, listRef{path: path, parent: obj, key: key, items: val, size: encodedSize(val)})
+			}
+		case *jsonObject:
+			if depth < 2 {
+				lists = append(lists, findLists(val, path, depth+1)...)
+			}
+		}
+	}
+	return lists
+}
+
+// encodedSize returns the JSON-encoded length of v
+func encodedSize(v interface{}) int {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return len(b)
+}
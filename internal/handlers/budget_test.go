@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestBudgetToolTruncatesLargestList(t *testing.T) {
+	h := NewHandlerManager(&config.Config{MaxResponseBytes: config.MinMaxResponseBytes})
+	tool := mcp.NewTool("big_list",
+		mcp.WithNumber("limit", mcp.Description("Row limit")),
+		mcp.WithString("temp_unit", mcp.Description("Not a narrowing parameter")))
+
+	rows := make([]map[string]interface{}, 500)
+	for i := range rows {
+		rows[i] = map[string]interface{}{"pid": i, "name": strings.Repeat("x", 20)}
+	}
+	handler := h.budgetTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		jsonBytes, _ := json.Marshal(map[string]interface{}{"processes": rows, "tags": []string{"a", "b"}, "total": len(rows)})
+		return mcp.NewToolResultText(string(jsonBytes)), nil
+	})
+
+	res, err := handler(context.Background(), mcp.CallToolRequest{})
+	checkToolResult(t, res, err, []string{"processes", "tags", "total", "truncated", "truncation"})
+
+	text := res.Content[0].(mcp.TextContent).Text
+	if len(text) > config.MinMaxResponseBytes {
+		t.Errorf("Expected result within %d bytes, got %d", config.MinMaxResponseBytes, len(text))
+	}
+
+	var result struct {
+		Processes  []interface{} `json:"processes"`
+		Tags       []string      `json:"tags"`
+		Truncated  bool          `json:"truncated"`
+		Truncation struct {
+			Lists []truncation `json:"lists"`
+			Hint  string       `json:"hint"`
+		} `json:"truncation"`
+	}
+	if err := json.Unmarshal([]byte(text), &result); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+	if !result.Truncated || len(result.Truncation.Lists) != 1 || result.Truncation.Lists[0].Field != "processes" {
+		t.Fatalf("Expected only processes to be truncated, got %+v", result.Truncation)
+	}
+	if got := result.Truncation.Lists[0]; got.Original != 500 || got.Returned != len(result.Processes) || got.Returned == 0 {
+		t.Errorf("Unexpected truncation counts: %+v (returned %d rows)", got, len(result.Processes))
+	}
+	if len(result.Tags) != 2 {
+		t.Errorf("Expected small lists to be left alone, got %v", result.Tags)
+	}
+	if !strings.Contains(result.Truncation.Hint, "limit") || strings.Contains(result.Truncation.Hint, "temp_unit") {
+		t.Errorf("Expected the hint to name only narrowing parameters, got %q", result.Truncation.Hint)
+	}
+	if h.truncatedResponses.Load() != 1 {
+		t.Errorf("Expected 1 truncated response, got %d", h.truncatedResponses.Load())
+	}
+}
+
+func TestBudgetToolPassthrough(t *testing.T) {
+	for _, budget := range []int{0, config.DefaultMaxResponseBytes} {
+		h := NewHandlerManager(&config.Config{MaxResponseBytes: budget})
+		original := mcp.NewToolResultText(`{"items":[1,2,3]}`)
+		handler := h.budgetTool(mcp.NewTool("small"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return original, nil
+		})
+
+		res, err := handler(context.Background(), mcp.CallToolRequest{})
+		if err != nil || res != original {
+			t.Errorf("Expected the result to pass through unchanged with budget %d", budget)
+		}
+	}
+}
+
+func TestApplyResponseBudgetNonJSON(t *testing.T) {
+	got := applyResponseBudget(strings.Repeat("é", 5000), config.MinMaxResponseBytes, nil)
+	if len(got) > config.MinMaxResponseBytes || !strings.Contains(got, "[truncated]") || !strings.Contains(got, "raise --max-response-bytes") {
+		t.Errorf("Unexpected non-JSON truncation (%d bytes): %q", len(got), got[len(got)-120:])
+	}
+}
+
+func TestApplyResponseBudgetKeepsNumbersAndOrder(t *testing.T) {
+	text := `{"total":18446744073709551615,"rows":[` + strings.Repeat(`"xxxxxxxxxxxxxxxxxxxx",`, 500) + `"x"],"bytes_sent":9007199254740993}`
+	got := applyResponseBudget(text, config.MinMaxResponseBytes, nil)
+	if !strings.HasPrefix(got, `{"total":18446744073709551615,"rows":[`) {
+		t.Errorf("Expected the first field and its exact value first, got %.60s", got)
+	}
+	if !strings.Contains(got, `],"bytes_sent":9007199254740993,"truncated":true,"truncation":`) {
+		t.Errorf("Expected exact counters in their original order, got ...%s", got[len(got)-300:])
+	}
+}
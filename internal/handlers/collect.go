@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"context"
+)
+
+// collectTask gathers one source of a multi-source tool and returns its result
+type collectTask func(ctx context.Context) (any, error)
+
+// collected is the outcome of a collectTask that returned in time
+type collected struct {
+	value any
+	err   error
+}
+
+// collectConcurrently runs collectors concurrently and waits until they all return or ctx
+// is done. It returns the outcome of each collector that returned, keyed by name.
+// Collectors still running when ctx is done are missing from the result. They keep
+// running in the background, as a stat on a hung mount cannot be interrupted, and what
+// they return later is dropped.
+func collectConcurrently(ctx context.Context, collectors map[string]collectTask) map[string]collected {
+	type outcome struct {
+		name string
+		collected
+	}
+	// Buffered so a collector that returns after the deadline never blocks
+	outcomes := make(chan outcome, len(collectors))
+	for name, collect := range collectors {
+		go func() {
+			value, err := collect(ctx)
+			outcomes <- outcome{name: name, collected: collected{value: value, err: err}}
+		}()
+	}
+
+	finished := make(map[string]collected, len(collectors))
+	for len(finished) < len(collectors) {
+		select {
+		case o := <-outcomes:
+			finished[o.name] = o.collected
+		case <-ctx.Done():
+			return finished
+		}
+	}
+	return finished
+}
+
+// collectedValue returns a finished collector's result as T. ok is false when the
+// collector did not finish, failed, or returned something else.
+func collectedValue[T any](finished map[string]collected, name string) (value T, ok bool) {
+	c, done := finished[name]
+	if !done || c.err != nil {
+		return value, false
+	}
+	value, ok = c.value.(T)
+	return value, ok
+}
+
+// unfinishedCollectors returns the collectors missing from finished, in sorted order
+func unfinishedCollectors(collectors map[string]collectTask, finished map[string]collected) []string {
+	var pending []string
+	for _, name := range sortedKeys(collectors) {
+		if _, ok := finished[name]; !ok {
+			pending = append(pending, name)
+		}
+	}
+	return pending
+}
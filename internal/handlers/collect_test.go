@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCollectConcurrently(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	release := make(chan struct{})
+
+	collectors := map[string]collectTask{
+		"fast":    func(context.Context) (any, error) { return 42, nil },
+		"failing": func(context.Context) (any, error) { return nil, errors.New("no data") },
+		// Blocks without honoring ctx, like a stat on a hung mount
+		"hung": func(context.Context) (any, error) {
+			<-release
+			return 7, nil
+		},
+	}
+	finished := collectConcurrently(ctx, collectors)
+	if v, ok := collectedValue[int](finished, "fast"); !ok || v != 42 {
+		t.Errorf("Expected fast to finish with 42, got %v", finished)
+	}
+	if finished["failing"].err == nil {
+		t.Error("Expected the failing collector's error")
+	}
+	if _, ok := collectedValue[int](finished, "failing"); ok {
+		t.Error("Expected no value from the failing collector")
+	}
+	if pending := unfinishedCollectors(collectors, finished); !slices.Equal(pending, []string{"hung"}) {
+		t.Errorf("Unfinished collectors = %v, expected [hung]", pending)
+	}
+
+	// A collector that returns after the deadline changes nothing the caller holds
+	close(release)
+	time.Sleep(10 * time.Millisecond)
+	if _, ok := finished["hung"]; ok {
+		t.Error("Expected the late collector to stay missing")
+	}
+	if _, ok := collectedValue[string](finished, "fast"); ok {
+		t.Error("Expected a value of another type to be refused")
+	}
+}
@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"sysmetrics-mcp/internal/collector"
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/load"
+	"github.com/shirou/gopsutil/v3/mem"
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+// errNoTemperature is returned by the temperature collector on hosts without a readable
+// CPU temperature
+var errNoTemperature = errors.New("no CPU temperature sensor")
+
+// Collectors returns the metric registry shared by get_metric_snapshot, the background
+// sampler, the gRPC API, and the exporters
+func (h *HandlerManager) Collectors() *collector.Registry {
+	return h.collectors
+}
+
+// coreCollectors are the flat host metrics every consumer of the registry gets. They and
+// the detail tools (get_cpu_metrics, get_memory_metrics, get_disk_metrics,
+// get_network_metrics, get_system_health) read the host through the same readers below;
+// the tools add the detail that does not fit a flat metric.
+func (h *HandlerManager) coreCollectors() []collector.Collector {
+	return []collector.Collector{
+		collector.New("cpu", []collector.Descriptor{
+			{Metric: "cpu_percent", Help: "CPU usage since the previous collection", Unit: "percent"},
+		}, func(ctx context.Context) ([]collector.Metric, error) {
+			percent, err := readCPUPercent(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return []collector.Metric{{Name: "cpu_percent", Value: percent}}, nil
+		}),
+		collector.New("load", []collector.Descriptor{
+			{Metric: "load1", Help: "1-minute load average"},
+			{Metric: "load5", Help: "5-minute load average"},
+			{Metric: "load15", Help: "15-minute load average"},
+		}, func(ctx context.Context) ([]collector.Metric, error) {
+			avg, err := load.AvgWithContext(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return []collector.Metric{
+				{Name: "load1", Value: avg.Load1},
+				{Name: "load5", Value: avg.Load5},
+				{Name: "load15", Value: avg.Load15},
+			}, nil
+		}),
+		collector.New("memory", []collector.Descriptor{
+			{Metric: "memory_used_percent", Help: "Share of RAM in use", Unit: "percent"},
+			{Metric: "swap_used_percent", Help: "Share of swap in use, when swap is configured", Unit: "percent"},
+		}, func(ctx context.Context) ([]collector.Metric, error) {
+			ram, swap, err := readMemory(ctx)
+			if err != nil {
+				return nil, err
+			}
+			metrics := []collector.Metric{{Name: "memory_used_percent", Value: ram.UsedPercent}}
+			if swap.Total > 0 {
+				metrics = append(metrics, collector.Metric{Name: "swap_used_percent", Value: swap.UsedPercent})
+			}
+			return metrics, nil
+		}),
+		collector.New("disk", []collector.Descriptor{
+			{Metric: "disk_used_percent", Help: "Share of each monitored filesystem in use", Unit: "percent", Labels: []string{"mount"}},
+		}, func(ctx context.Context) ([]collector.Metric, error) {
+			// --mount-points, or the root filesystem
+			mounts := h.cfg.MountPoints
+			if len(mounts) == 0 {
+				mounts = []string{systemRootPath()}
+			}
+			usages, err := readMountUsage(ctx, mounts)
+			var metrics []collector.Metric
+			for _, u := range usages {
+				metrics = append(metrics, collector.Metric{Name: "disk_used_percent", Labels: map[string]string{"mount": u.Path}, Value: u.UsedPercent})
+			}
+			return metrics, err
+		}),
+		collector.New("network", []collector.Descriptor{
+			{Metric: "net_bytes_sent", Help: "Bytes sent since boot", Unit: "bytes", Labels: []string{"interface"}},
+			{Metric: "net_bytes_recv", Help: "Bytes received since boot", Unit: "bytes", Labels: []string{"interface"}},
+		}, func(ctx context.Context) ([]collector.Metric, error) {
+			counters, err := readInterfaceCounters(ctx, h.cfg.Interfaces)
+			var metrics []collector.Metric
+			for _, io := range counters {
+				labels := map[string]string{"interface": io.Name}
+				metrics = append(metrics,
+					collector.Metric{Name: "net_bytes_sent", Labels: labels, Value: float64(io.BytesSent)},
+					collector.Metric{Name: "net_bytes_recv", Labels: labels, Value: float64(io.BytesRecv)},
+				)
+			}
+			return metrics, err
+		}),
+		collector.New("temperature", []collector.Descriptor{
+			{Metric: "cpu_temperature_celsius", Help: "CPU temperature", Unit: "celsius"},
+		}, func(ctx context.Context) ([]collector.Metric, error) {
+			temp, ok := config.GetRaspberryPiTemp()
+			if !ok {
+				return nil, errNoTemperature
+			}
+			return []collector.Metric{{Name: "cpu_temperature_celsius", Value: temp}}, nil
+		}),
+	}
+}
+
+// readCPUPercent returns CPU usage across all cores since the previous reading
+func readCPUPercent(ctx context.Context) (float64, error) {
+	percents, err := cpu.PercentWithContext(ctx, 0, false)
+	if err != nil {
+		return 0, err
+	}
+	if len(percents) == 0 {
+		return 0, errors.New("no CPU usage reported")
+	}
+	return percents[0], nil
+}
+
+// readMemory returns RAM and swap usage. Swap is reported as empty when it cannot be read.
+func readMemory(ctx context.Context) (*mem.VirtualMemoryStat, *mem.SwapMemoryStat, error) {
+	ram, err := mem.VirtualMemoryWithContext(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	swap, err := mem.SwapMemoryWithContext(ctx)
+	if err != nil {
+		swap = &mem.SwapMemoryStat{}
+	}
+	return ram, swap, nil
+}
+
+// readMountUsage returns the usage of each mount that can be read, in order, and the
+// errors of those that cannot
+func readMountUsage(ctx context.Context, mounts []string) ([]*disk.UsageStat, error) {
+	var usages []*disk.UsageStat
+	var errs []error
+	for _, mount := range mounts {
+		usage, err := disk.UsageWithContext(ctx, mount)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		usages = append(usages, usage)
+	}
+	return usages, errors.Join(errs...)
+}
+
+// readInterfaceCounters returns the counters of interfaces, or of every interface but
+// loopback when none are given
+func readInterfaceCounters(ctx context.Context, interfaces []string) ([]net.IOCountersStat, error) {
+	all, err := net.IOCountersWithContext(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	var counters []net.IOCountersStat
+	for _, io := range all {
+		if io.Name == "lo" && !contains(interfaces, "lo") {
+			continue
+		}
+		if len(interfaces) > 0 && !contains(interfaces, io.Name) {
+			continue
+		}
+		counters = append(counters, io)
+	}
+	return counters, nil
+}
+
+// HandleGetMetricSnapshot collects current values from the metric registry, the same
+// metrics the background sampler records and the exporters push
+func (h *HandlerManager) HandleGetMetricSnapshot(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var names []string
+	describe := false
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if c, ok := args["collectors"].(string); ok && c != "" {
+			names = config.SplitAndTrim(c)
+		}
+		if d, ok := args["describe"].(bool); ok {
+			describe = d
+		}
+	}
+	for _, name := range names {
+		if _, ok := h.collectors.Get(name); !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("Unknown collector %q (available: %s)", name, strings.Join(h.collectorNames(), ", "))), nil
+		}
+	}
+
+	collectors := []map[string]interface{}{}
+	total := 0
+	for _, r := range h.collectors.Collect(ctx, names...) {
+		c, _ := h.collectors.Get(r.Collector)
+		units := map[string]string{}
+		for _, d := range c.Describe() {
+			units[d.Metric] = d.Unit
+		}
+		metrics := []map[string]interface{}{}
+		for _, m := range r.Metrics {
+			metric := map[string]interface{}{"metric": m.Name, "value": round2(m.Value)}
+			if len(m.Labels) > 0 {
+				metric["labels"] = m.Labels
+			}
+			if unit := units[m.Name]; unit != "" {
+				metric["unit"] = unit
+			}
+			metrics = append(metrics, metric)
+		}
+		total += len(metrics)
+		entry := map[string]interface{}{"name": r.Collector, "metrics": metrics}
+		if r.Err != nil {
+			entry["error"] = r.Err.Error()
+		}
+		if describe {
+			entry["descriptors"] = c.Describe()
+		}
+		collectors = append(collectors, entry)
+	}
+
+	result := map[string]interface{}{
+		"collectors":    collectors,
+		"total_metrics": total,
+		"timestamp":     time.Now().Unix(),
+	}
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// collectorNames lists the registered collectors in registration order
+func (h *HandlerManager) collectorNames() []string {
+	var names []string
+	for _, c := range h.collectors.Collectors() {
+		names = append(names, c.Name())
+	}
+	return names
+}
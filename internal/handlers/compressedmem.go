@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Compressed memory sources
+var (
+	meminfoPath     = "/proc/meminfo"
+	procSwapsPath   = "/proc/swaps"
+	zswapParamsPath = "/sys/module/zswap/parameters"
+	// zswap pool statistics before Linux 5.19 added them to /proc/meminfo; readable by root
+	zswapDebugPath = "/sys/kernel/debug/zswap"
+)
+
+// zramDeviceRe matches zram block devices such as "zram0"
+var zramDeviceRe = regexp.MustCompile(`^zram\d+$`)
+
+// zramDevice is one zram block device. OrigBytes is the data stored, CompressedBytes its
+// compressed size, and MemUsedBytes the RAM it takes including allocator overhead.
+type zramDevice struct {
+	Device           string  `json:"device"`
+	Algorithm        string  `json:"algorithm,omitempty"`
+	DiskSizeBytes    uint64  `json:"disksize_bytes"`
+	OrigBytes        uint64  `json:"orig_data_bytes"`
+	CompressedBytes  uint64  `json:"compr_data_bytes"`
+	MemUsedBytes     uint64  `json:"mem_used_bytes"`
+	MemLimitBytes    uint64  `json:"mem_limit_bytes,omitempty"`
+	SamePages        uint64  `json:"same_pages"`
+	CompressionRatio float64 `json:"compression_ratio,omitempty"`
+	EffectiveRatio   float64 `json:"effective_ratio,omitempty"`
+	UsedAsSwap       bool    `json:"used_as_swap"`
+}
+
+// readZramDevices reads each zram device under blockRoot, marking those listed in the
+// active swap areas
+func readZramDevices(blockRoot string, swaps map[string]bool) []zramDevice {
+	devices := []zramDevice{}
+	entries, err := os.ReadDir(blockRoot)
+	if err != nil {
+		return devices
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if !zramDeviceRe.MatchString(name) {
+			continue
+		}
+		dir := filepath.Join(blockRoot, name)
+		dev := zramDevice{Device: name, UsedAsSwap: swaps["/dev/"+name]}
+		if s, err := readTrimmed(filepath.Join(dir, "disksize")); err == nil {
+			dev.DiskSizeBytes, _ = strconv.ParseUint(s, 10, 64)
+		}
+		// An unconfigured device has no size and stores nothing
+		if dev.DiskSizeBytes == 0 {
+			continue
+		}
+		if s, err := readTrimmed(filepath.Join(dir, "comp_algorithm")); err == nil {
+			dev.Algorithm = selectedOption(s)
+		}
+
+		// mm_stat (Linux 4.1+): orig_data_size compr_data_size mem_used_total mem_limit
+		// mem_used_max same_pages ...; older kernels have one file per field
+		if s, err := readTrimmed(filepath.Join(dir, "mm_stat")); err == nil {
+			f := parseUintFields(s)
+			if len(f) >= 4 {
+				dev.OrigBytes, dev.CompressedBytes, dev.MemUsedBytes, dev.MemLimitBytes = f[0], f[1], f[2], f[3]
+			}
+			if len(f) >= 6 {
+				dev.SamePages = f[5]
+			}
+		} else {
+			for file, v := range map[string]*uint64{
+				"orig_data_size": &dev.OrigBytes, "compr_data_size": &dev.CompressedBytes, "mem_used_total": &dev.MemUsedBytes,
+			} {
+				if s, err := readTrimmed(filepath.Join(dir, file)); err == nil {
+					*v, _ = strconv.ParseUint(s, 10, 64)
+				}
+			}
+		}
+		if dev.CompressedBytes > 0 {
+			dev.CompressionRatio = round2(float64(dev.OrigBytes) / float64(dev.CompressedBytes))
+		}
+		if dev.MemUsedBytes > 0 {
+			dev.EffectiveRatio = round2(float64(dev.OrigBytes) / float64(dev.MemUsedBytes))
+		}
+		devices = append(devices, dev)
+	}
+	return devices
+}
+
+// readSwapDevices returns the active swap areas from /proc/swaps
+func readSwapDevices(path string) map[string]bool {
+	swaps := map[string]bool{}
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return swaps
+	}
+	for i, line := range strings.Split(string(data), "\n") {
+		if fields := strings.Fields(line); i > 0 && len(fields) > 0 {
+			swaps[fields[0]] = true
+		}
+	}
+	return swaps
+}
+
+// readZswap reports whether zswap is enabled and how full its compressed pool is. Pool
+// usage comes from /proc/meminfo, or from debugfs on kernels before 5.19.
+func readZswap(paramsDir, meminfo, debugDir string, totalRAM uint64) (map[string]interface{}, bool) {
+	enabled, err := readTrimmed(filepath.Join(paramsDir, "enabled"))
+	if err != nil {
+		return nil, false
+	}
+	z := map[string]interface{}{"enabled": enabled == "Y" || enabled == "1"}
+	for _, p := range []string{"compressor", "zpool"} {
+		if s, err := readTrimmed(filepath.Join(paramsDir, p)); err == nil && s != "" {
+			z[p] = s
+		}
+	}
+	var limit uint64
+	if s, err := readTrimmed(filepath.Join(paramsDir, "max_pool_percent")); err == nil {
+		if pct, err := strconv.ParseUint(s, 10, 64); err == nil {
+			z["max_pool_percent"] = pct
+			limit = totalRAM * pct / 100
+			z["pool_limit_bytes"] = limit
+		}
+	}
+
+	pool, stored, ok := readMeminfoZswap(meminfo)
+	if !ok {
+		pool, stored, ok = readZswapDebug(debugDir)
+	}
+	if !ok {
+		z["pool_error"] = "pool usage needs Linux 5.19+ or root to read " + debugDir
+		return z, true
+	}
+	z["pool_bytes"] = pool
+	z["stored_bytes"] = stored
+	if pool > 0 {
+		z["compression_ratio"] = round2(float64(stored) / float64(pool))
+	}
+	if limit > 0 {
+		z["pool_limit_used_percent"] = round2(float64(pool) / float64(limit) * 100)
+	}
+	return z, true
+}
+
+// readMeminfoZswap returns the Zswap (compressed pool) and Zswapped (original data) sizes
+// from /proc/meminfo in bytes
+func readMeminfoZswap(path string) (uint64, uint64, bool) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return 0, 0, false
+	}
+	defer f.Close()
+	var pool, stored uint64
+	var havePool, haveStored bool
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+		kb, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch key {
+		case "Zswap":
+			pool, havePool = kb*1024, true
+		case "Zswapped":
+			stored, haveStored = kb*1024, true
+		}
+	}
+	return pool, stored, havePool && haveStored
+}
+
+// readZswapDebug returns the pool size and original data size from zswap's debugfs
+// statistics
+func readZswapDebug(dir string) (uint64, uint64, bool) {
+	poolStr, err := readTrimmed(filepath.Join(dir, "pool_total_size"))
+	if err != nil {
+		return 0, 0, false
+	}
+	pagesStr, err := readTrimmed(filepath.Join(dir, "stored_pages"))
+	if err != nil {
+		return 0, 0, false
+	}
+	pool, err1 := strconv.ParseUint(poolStr, 10, 64)
+	pages, err2 := strconv.ParseUint(pagesStr, 10, 64)
+	if err1 != nil || err2 != nil {
+		return 0, 0, false
+	}
+	return pool, pages * uint64(os.Getpagesize()), true
+}
+
+// selectedOption returns the bracketed choice of a sysfs option list such as
+// "lzo lzo-rle [lz4] zstd", or the whole value when there is no list
+func selectedOption(s string) string {
+	if i := strings.Index(s, "["); i >= 0 {
+		if j := strings.Index(s[i:], "]"); j > 0 {
+			return s[i+1 : i+j]
+		}
+	}
+	return s
+}
+
+// parseUintFields parses the leading unsigned integers of a whitespace-separated line
+func parseUintFields(s string) []uint64 {
+	values := []uint64{}
+	for _, f := range strings.Fields(s) {
+		v, err := strconv.ParseUint(f, 10, 64)
+		if err != nil {
+			break
+		}
+		values = append(values, v)
+	}
+	return values
+}
+
+// getCompressedMemory reports zram devices and the zswap pool with the RAM they save.
+// Swap used on zram is held compressed in RAM, so swap usage alone misstates headroom.
+func (h *HandlerManager) getCompressedMemory(totalRAM uint64) (map[string]interface{}, bool) {
+	zram := readZramDevices(sysBlockPath, readSwapDevices(procSwapsPath))
+	zswap, hasZswap := readZswap(zswapParamsPath, meminfoPath, zswapDebugPath, totalRAM)
+	if len(zram) == 0 && !hasZswap {
+		return nil, false
+	}
+
+	var saved, ramUsed uint64
+	for _, d := range zram {
+		ramUsed += d.MemUsedBytes
+		if d.OrigBytes > d.MemUsedBytes {
+			saved += d.OrigBytes - d.MemUsedBytes
+		}
+	}
+	result := map[string]interface{}{"zram": zram}
+	if hasZswap {
+		result["zswap"] = zswap
+		pool, _ := zswap["pool_bytes"].(uint64)
+		stored, _ := zswap["stored_bytes"].(uint64)
+		ramUsed += pool
+		if stored > pool {
+			saved += stored - pool
+		}
+	}
+	result["ram_used_bytes"] = ramUsed
+	result["ram_used_human"] = h.human.Bytes(ramUsed)
+	result["ram_saved_bytes"] = saved
+	result["ram_saved_human"] = h.human.Bytes(saved)
+	if len(zram) > 0 {
+		result["note"] = "Swap on zram lives in RAM: ram_used_bytes of memory holds the compressed pages, so zram swap usage is not memory freed to disk"
+	}
+	return result, true
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+)
+
+func TestReadZramDevices(t *testing.T) {
+	dir := t.TempDir()
+	writeSysfsFiles(t, filepath.Join(dir, "zram0"), map[string]string{
+		"disksize":       "536870912",
+		"comp_algorithm": "lzo lzo-rle [lz4] zstd",
+		"mm_stat":        "300000000 100000000 120000000        0 130000000     2048      0      0      0",
+	})
+	writeSysfsFiles(t, filepath.Join(dir, "zram1"), map[string]string{
+		"disksize":        "1048576",
+		"comp_algorithm":  "zstd",
+		"orig_data_size":  "4096",
+		"compr_data_size": "1024",
+		"mem_used_total":  "2048",
+	})
+	writeSysfsFiles(t, filepath.Join(dir, "zram2"), map[string]string{"disksize": "0"})
+	writeSysfsFiles(t, filepath.Join(dir, "sda"), map[string]string{"size": "100"})
+
+	devices := readZramDevices(dir, map[string]bool{"/dev/zram0": true})
+	if len(devices) != 2 {
+		t.Fatalf("Expected the two configured zram devices, got %+v", devices)
+	}
+	z := devices[0]
+	if z.Device != "zram0" || z.Algorithm != "lz4" || z.OrigBytes != 300000000 || z.MemUsedBytes != 120000000 ||
+		z.SamePages != 2048 || z.CompressionRatio != 3 || z.EffectiveRatio != 2.5 || !z.UsedAsSwap {
+		t.Errorf("Unexpected zram0: %+v", z)
+	}
+	if z := devices[1]; z.Algorithm != "zstd" || z.CompressionRatio != 4 || z.EffectiveRatio != 2 || z.UsedAsSwap {
+		t.Errorf("Expected the per-file fallback for zram1, got %+v", z)
+	}
+}
+
+func TestReadZswap(t *testing.T) {
+	dir := t.TempDir()
+	params := filepath.Join(dir, "params")
+	writeSysfsFiles(t, params, map[string]string{
+		"enabled": "Y", "compressor": "lz4", "zpool": "z3fold", "max_pool_percent": "20",
+	})
+	meminfo := filepath.Join(dir, "meminfo")
+	if err := os.WriteFile(meminfo, []byte("MemTotal:  1000000 kB\nZswap:  10240 kB\nZswapped:  40960 kB\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	z, ok := readZswap(params, meminfo, filepath.Join(dir, "debug"), 100<<20)
+	if !ok || z["enabled"] != true || z["compressor"] != "lz4" || z["pool_bytes"] != uint64(10<<20) ||
+		z["compression_ratio"] != 4.0 || z["pool_limit_used_percent"] != 50.0 {
+		t.Errorf("Unexpected zswap stats: %v", z)
+	}
+
+	// Before Linux 5.19 the pool size is only in debugfs
+	legacy := filepath.Join(dir, "meminfo.legacy")
+	if err := os.WriteFile(legacy, []byte("MemTotal:  1000000 kB\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	writeSysfsFiles(t, filepath.Join(dir, "debug"), map[string]string{"pool_total_size": "8192", "stored_pages": "4"})
+	z, _ = readZswap(params, legacy, filepath.Join(dir, "debug"), 100<<20)
+	if z["pool_bytes"] != uint64(8192) || z["stored_bytes"] != uint64(4*os.Getpagesize()) {
+		t.Errorf("Expected the debugfs pool statistics, got %v", z)
+	}
+	z, _ = readZswap(params, legacy, filepath.Join(dir, "missing"), 100<<20)
+	if _, ok := z["pool_error"]; !ok {
+		t.Errorf("Expected a pool_error without meminfo or debugfs statistics, got %v", z)
+	}
+
+	if _, ok := readZswap(filepath.Join(dir, "missing"), meminfo, "", 0); ok {
+		t.Error("Expected no zswap section without the zswap module")
+	}
+}
+
+func TestGetCompressedMemory(t *testing.T) {
+	dir := t.TempDir()
+	oldBlock, oldSwaps, oldParams := sysBlockPath, procSwapsPath, zswapParamsPath
+	t.Cleanup(func() { sysBlockPath, procSwapsPath, zswapParamsPath = oldBlock, oldSwaps, oldParams })
+	sysBlockPath, procSwapsPath, zswapParamsPath = filepath.Join(dir, "block"), filepath.Join(dir, "swaps"), filepath.Join(dir, "zswap")
+
+	h := NewHandlerManager(&config.Config{})
+	if _, ok := h.getCompressedMemory(1 << 30); ok {
+		t.Error("Expected no compressed memory section without zram or zswap")
+	}
+
+	writeSysfsFiles(t, filepath.Join(sysBlockPath, "zram0"), map[string]string{
+		"disksize": "1048576", "mm_stat": "3000 1000 1200 0 1200 0 0 0",
+	})
+	if err := os.WriteFile(procSwapsPath, []byte("Filename\tType\tSize\tUsed\tPriority\n/dev/zram0\tpartition\t1024\t3\t100\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	result, ok := h.getCompressedMemory(1 << 30)
+	if !ok || result["ram_saved_bytes"] != uint64(1800) || result["ram_used_bytes"] != uint64(1200) {
+		t.Errorf("Unexpected compressed memory: %v", result)
+	}
+	if devices, _ := result["zram"].([]zramDevice); len(devices) != 1 || !devices[0].UsedAsSwap {
+		t.Errorf("Expected zram0 as swap, got %v", result["zram"])
+	}
+}
@@ -0,0 +1,323 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	stdnet "net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+// Connection grouping mode constants.
+const (
+	groupByRemoteHost = "remote_host"
+	groupByRemotePort = "remote_port"
+)
+
+// connectionFields lists the columns get_network_connections can return
+var connectionFields = []string{"type", "status", "local_addr", "remote_addr", "pid", "geo"}
+
+// connectionHostGroupFields lists the columns returned when grouping by remote host
+var connectionHostGroupFields = []string{"remote_host", "hostname", "geo", "count", "states", "remote_ports"}
+
+// connectionPortGroupFields lists the columns returned when grouping by remote port
+var connectionPortGroupFields = []string{"remote_port", "count", "states", "remote_hosts"}
+
+// maxGroupPeers bounds the distinct ports or hosts reported for each connection group
+const maxGroupPeers = 20
+
+// reverseDNSTimeout bounds the total time spent resolving hostnames for one call
+const reverseDNSTimeout = 3 * time.Second
+
+// connectionGroup aggregates connections sharing a remote host or remote port
+type connectionGroup struct {
+	Host   string
+	Port   uint32
+	Count  int
+	States map[string]int
+	Hosts  []string
+	Ports  []uint32
+}
+
+// HandleGetNetworkConnections returns active network connections
+func (h *HandlerManager) HandleGetNetworkConnections(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	kind := kindAll
+	statusFilter := ""
+	groupBy := groupByNone
+	limit := 0
+	resolveDNS := false
+
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if k, ok := args["kind"].(string); ok && k != "" {
+			kind = strings.ToLower(k)
+		}
+		if s, ok := args["status"].(string); ok && s != "" {
+			statusFilter = strings.ToUpper(s)
+		}
+		if g, ok := args["group_by"].(string); ok && g != "" {
+			groupBy = strings.ToLower(g)
+		}
+		if l, ok := args["limit"].(float64); ok && l > 0 {
+			limit = h.cfg.ClampProcessLimit(int(l))
+		}
+		if r, ok := args["resolve_dns"].(bool); ok {
+			resolveDNS = r
+		}
+	}
+
+	// Validate kind parameter against known values
+	if kind != kindTCP && kind != kindUDP {
+		kind = kindAll
+	}
+
+	allowedFields := connectionFields
+	switch groupBy {
+	case groupByNone:
+	case groupByRemoteHost:
+		allowedFields = connectionHostGroupFields
+	case groupByRemotePort:
+		allowedFields = connectionPortGroupFields
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid group_by: %s (must be none, remote_host, or remote_port)", groupBy)), nil
+	}
+
+	fields, err := parseFieldsArg(request, allowedFields)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// Pages of single connections default to the process cap, as rows are small
+	if limit == 0 {
+		limit = h.cfg.MaxProcesses
+		if groupBy == groupByNone {
+			limit = h.cfg.MaxProcessesCap
+		}
+	}
+	args, _ := request.Params.Arguments.(map[string]interface{})
+	pg, err := parsePage("get_network_connections", args, limit)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	connections, err := net.ConnectionsWithContext(ctx, kind)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get network connections: %v", err)), nil
+	}
+
+	// Filter by status if specified
+	if statusFilter != "" {
+		filtered := connections[:0]
+		for _, c := range connections {
+			if c.Status == statusFilter {
+				filtered = append(filtered, c)
+			}
+		}
+		connections = filtered
+	}
+
+	result := map[string]interface{}{
+		"total": len(connections),
+		"kind":  kind,
+	}
+
+	if statusFilter != "" {
+		result["status_filter"] = statusFilter
+	}
+
+	if groupBy == groupByNone {
+		sortConnections(connections)
+		start, end := pg.bounds(len(connections))
+		connData := []map[string]interface{}{}
+		for _, c := range connections[start:end] {
+			connInfo := map[string]interface{}{
+				"type":       connTypeToString(c.Type),
+				"status":     c.Status,
+				"local_addr": fmt.Sprintf("%s:%d", c.Laddr.IP, c.Laddr.Port),
+				"pid":        c.Pid,
+			}
+
+			if c.Raddr.IP != "" {
+				connInfo["remote_addr"] = fmt.Sprintf("%s:%d", c.Raddr.IP, c.Raddr.Port)
+				if geo, ok := h.geo.Lookup(c.Raddr.IP); ok {
+					connInfo["geo"] = geo
+				}
+			} else {
+				connInfo["remote_addr"] = ""
+			}
+
+			connData = append(connData, projectFields(connInfo, fields))
+		}
+		result["connections"] = connData
+		result["shown"] = len(connData)
+		maps.Copy(result, pg.info(len(connections)))
+	} else {
+		groups, unconnected := groupConnections(connections, groupBy)
+
+		result["matched"] = len(groups)
+		maps.Copy(result, pg.info(len(groups)))
+		start, end := pg.bounds(len(groups))
+		groups = groups[start:end]
+
+		// Only resolve hostnames for the groups actually shown
+		var hostnames map[string]string
+		if groupBy == groupByRemoteHost && resolveDNS && wantField(fields, "hostname") {
+			hosts := make([]string, 0, len(groups))
+			for _, g := range groups {
+				hosts = append(hosts, g.Host)
+			}
+			hostnames = reverseLookup(ctx, hosts)
+		}
+
+		rows := make([]map[string]interface{}, 0, len(groups))
+		for _, g := range groups {
+			row := map[string]interface{}{
+				"count":  g.Count,
+				"states": g.States,
+			}
+			if groupBy == groupByRemoteHost {
+				row["remote_host"] = g.Host
+				row["remote_ports"] = g.Ports
+				if name, ok := hostnames[g.Host]; ok {
+					row["hostname"] = name
+				}
+				if geo, ok := h.geo.Lookup(g.Host); ok {
+					row["geo"] = geo
+				}
+			} else {
+				row["remote_port"] = g.Port
+				row["remote_hosts"] = g.Hosts
+			}
+			rows = append(rows, projectFields(row, fields))
+		}
+
+		result["groups"] = rows
+		result["shown"] = len(rows)
+		result["unconnected"] = unconnected
+		result["group_by"] = groupBy
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// sortConnections orders connections by PID, then local and remote address and type, so
+// pages of the list do not overlap
+func sortConnections(connections []net.ConnectionStat) {
+	sort.Slice(connections, func(i, j int) bool {
+		a, b := connections[i], connections[j]
+		switch {
+		case a.Pid != b.Pid:
+			return a.Pid < b.Pid
+		case a.Laddr.IP != b.Laddr.IP:
+			return a.Laddr.IP < b.Laddr.IP
+		case a.Laddr.Port != b.Laddr.Port:
+			return a.Laddr.Port < b.Laddr.Port
+		case a.Raddr.IP != b.Raddr.IP:
+			return a.Raddr.IP < b.Raddr.IP
+		case a.Raddr.Port != b.Raddr.Port:
+			return a.Raddr.Port < b.Raddr.Port
+		}
+		return a.Type < b.Type
+	})
+}
+
+// groupConnections aggregates connections by remote host or remote port, ordered by
+// descending count. Sockets without a remote peer (e.g. LISTEN) are only counted.
+func groupConnections(connections []net.ConnectionStat, groupBy string) (groups []connectionGroup, unconnected int) {
+	index := make(map[string]int)
+	hostSeen := make(map[string]map[string]bool)
+	portSeen := make(map[string]map[uint32]bool)
+
+	for _, c := range connections {
+		if c.Raddr.IP == "" {
+			unconnected++
+			continue
+		}
+
+		key := c.Raddr.IP
+		if groupBy == groupByRemotePort {
+			key = fmt.Sprintf("%d", c.Raddr.Port)
+		}
+
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, connectionGroup{States: make(map[string]int)})
+			hostSeen[key] = make(map[string]bool)
+			portSeen[key] = make(map[uint32]bool)
+		}
+		g := &groups[i]
+		g.Count++
+		status := c.Status
+		if status == "" || status == "NONE" {
+			status = "UNKNOWN"
+		}
+		g.States[status]++
+
+		if groupBy == groupByRemotePort {
+			g.Port = c.Raddr.Port
+			if !hostSeen[key][c.Raddr.IP] && len(g.Hosts) < maxGroupPeers {
+				hostSeen[key][c.Raddr.IP] = true
+				g.Hosts = append(g.Hosts, c.Raddr.IP)
+			}
+		} else {
+			g.Host = c.Raddr.IP
+			if !portSeen[key][c.Raddr.Port] && len(g.Ports) < maxGroupPeers {
+				portSeen[key][c.Raddr.Port] = true
+				g.Ports = append(g.Ports, c.Raddr.Port)
+			}
+		}
+	}
+
+	for i := range groups {
+		sort.Strings(groups[i].Hosts)
+		sort.Slice(groups[i].Ports, func(a, b int) bool { return groups[i].Ports[a] < groups[i].Ports[b] })
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Count != groups[j].Count {
+			return groups[i].Count > groups[j].Count
+		}
+		if groups[i].Host != groups[j].Host {
+			return groups[i].Host < groups[j].Host
+		}
+		return groups[i].Port < groups[j].Port
+	})
+	return groups, unconnected
+}
+
+// reverseLookup resolves PTR names for the given addresses concurrently under a shared
+// deadline. Addresses that fail to resolve are omitted from the result.
+func reverseLookup(ctx context.Context, addrs []string) map[string]string {
+	ctx, cancel := context.WithTimeout(ctx, reverseDNSTimeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	names := make(map[string]string, len(addrs))
+	for _, addr := range addrs {
+		wg.Add(1)
+		go func(addr string) {
+			defer wg.Done()
+			found, err := stdnet.DefaultResolver.LookupAddr(ctx, addr)
+			if err != nil || len(found) == 0 {
+				return
+			}
+			mu.Lock()
+			names[addr] = strings.TrimSuffix(found[0], ".")
+			mu.Unlock()
+		}(addr)
+	}
+	wg.Wait()
+	return names
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+func TestGroupConnections(t *testing.T) {
+	conn := func(status, ip string, port uint32) net.ConnectionStat {
+		return net.ConnectionStat{Status: status, Raddr: net.Addr{IP: ip, Port: port}}
+	}
+	connections := []net.ConnectionStat{
+		conn("ESTABLISHED", "10.0.0.5", 443),
+		conn("ESTABLISHED", "10.0.0.5", 443),
+		conn("TIME_WAIT", "10.0.0.5", 8443),
+		conn("ESTABLISHED", "192.168.1.2", 443),
+		conn("LISTEN", "", 0),
+	}
+
+	tests := []struct {
+		name     string
+		groupBy  string
+		expected int
+		check    func(t *testing.T, g connectionGroup)
+	}{
+		{
+			name:     "by remote host",
+			groupBy:  groupByRemoteHost,
+			expected: 2,
+			check: func(t *testing.T, g connectionGroup) {
+				if g.Host != "10.0.0.5" || g.Count != 3 {
+					t.Errorf("Unexpected top host group: %+v", g)
+				}
+				if g.States["ESTABLISHED"] != 2 || g.States["TIME_WAIT"] != 1 {
+					t.Errorf("Unexpected state counts: %v", g.States)
+				}
+				if len(g.Ports) != 2 || g.Ports[0] != 443 || g.Ports[1] != 8443 {
+					t.Errorf("Expected sorted distinct ports [443 8443], got %v", g.Ports)
+				}
+			},
+		},
+		{
+			name:     "by remote port",
+			groupBy:  groupByRemotePort,
+			expected: 2,
+			check: func(t *testing.T, g connectionGroup) {
+				if g.Port != 443 || g.Count != 3 {
+					t.Errorf("Unexpected top port group: %+v", g)
+				}
+				if len(g.Hosts) != 2 || g.Hosts[0] != "10.0.0.5" || g.Hosts[1] != "192.168.1.2" {
+					t.Errorf("Expected sorted distinct hosts, got %v", g.Hosts)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups, unconnected := groupConnections(connections, tt.groupBy)
+			if unconnected != 1 {
+				t.Errorf("Expected 1 unconnected socket, got %d", unconnected)
+			}
+			if len(groups) != tt.expected {
+				t.Fatalf("Expected %d groups, got %d", tt.expected, len(groups))
+			}
+			tt.check(t, groups[0])
+		})
+	}
+}
+
+func TestHandleGetNetworkConnectionsGrouped(t *testing.T) {
+	h := NewHandlerManager(&config.Config{MaxProcesses: 5})
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{
+				"group_by": "remote_port",
+				"fields":   "remote_port,count,states",
+			},
+		},
+	}
+	res, err := h.HandleGetNetworkConnections(context.Background(), req)
+	checkToolResult(t, res, err, []string{"groups", "matched", "shown", "unconnected", "group_by"})
+
+	req.Params.Arguments = map[string]interface{}{"group_by": "pid"}
+	res, err = h.HandleGetNetworkConnections(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if !res.IsError {
+		t.Error("Expected error result for unsupported group_by")
+	}
+}
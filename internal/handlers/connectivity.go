@@ -0,0 +1,379 @@
+package handlers
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	stdnet "net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Probe method constants.
+const (
+	probeICMP = "icmp"
+	probeTCP  = "tcp"
+)
+
+// Connectivity diagnosis constants.
+const (
+	diagnosisOK              = "ok"
+	diagnosisLocalNetwork    = "local_network_down"
+	diagnosisNoInternet      = "remote_hosts_unreachable"
+	diagnosisDNSFailing      = "dns_failing"
+	diagnosisPartialFailures = "partial_failures"
+)
+
+// Probe timeout bounds in milliseconds.
+const (
+	defaultProbeTimeoutMs = 2000
+	maxProbeTimeoutMs     = 10000
+)
+
+// maxConnectivityTargets bounds the number of hosts or names probed in one call
+const maxConnectivityTargets = 10
+
+// probeHostRe restricts probe targets to hostnames and IP literals so they cannot be
+// mistaken for command-line options by ping
+var probeHostRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9.:_-]*$`)
+
+// pingTimeRe extracts the round-trip time from ping output on Linux, macOS, and Windows
+var pingTimeRe = regexp.MustCompile(`time[=<]\s*([\d.]+)\s*ms`)
+
+// probeResult is the outcome of a single reachability or DNS probe
+type probeResult struct {
+	Target    string   `json:"target"`
+	Method    string   `json:"method"`
+	Success   bool     `json:"success"`
+	LatencyMs float64  `json:"latency_ms,omitempty"`
+	Addresses []string `json:"addresses,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// defaultGateway is the default IPv4 route of this host
+type defaultGateway struct {
+	Address   string `json:"address"`
+	Interface string `json:"interface,omitempty"`
+}
+
+// HandleCheckConnectivity probes hosts, DNS names, and the default gateway
+func (h *HandlerManager) HandleCheckConnectivity(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var hosts, dnsNames []string
+	method := probeICMP
+	timeoutMs := defaultProbeTimeoutMs
+	checkGateway := true
+
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if s, ok := args["hosts"].(string); ok && s != "" {
+			hosts = config.SplitAndTrim(s)
+		}
+		if s, ok := args["dns_names"].(string); ok && s != "" {
+			dnsNames = config.SplitAndTrim(s)
+		}
+		if m, ok := args["method"].(string); ok && m != "" {
+			method = strings.ToLower(m)
+		}
+		if t, ok := args["timeout_ms"].(float64); ok && t > 0 {
+			timeoutMs = int(t)
+			if timeoutMs > maxProbeTimeoutMs {
+				timeoutMs = maxProbeTimeoutMs
+			}
+		}
+		if g, ok := args["check_gateway"].(bool); ok {
+			checkGateway = g
+		}
+	}
+
+	if method != probeICMP && method != probeTCP {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid method: %s (must be icmp or tcp)", method)), nil
+	}
+	if len(hosts) > maxConnectivityTargets || len(dnsNames) > maxConnectivityTargets {
+		return mcp.NewToolResultError(fmt.Sprintf("At most %d hosts and %d DNS names may be probed per call", maxConnectivityTargets, maxConnectivityTargets)), nil
+	}
+	for _, target := range append(append([]string{}, hosts...), dnsNames...) {
+		if !probeHostRe.MatchString(target) {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid target: %q", target)), nil
+		}
+	}
+	timeout := time.Duration(timeoutMs) * time.Millisecond
+
+	// Probe the gateway alongside the requested hosts
+	var wg sync.WaitGroup
+	var gw defaultGateway
+	var gwErr error
+	var gatewayProbe *probeResult
+	if checkGateway {
+		gw, gwErr = findDefaultGateway(ctx)
+		if gwErr == nil {
+			gatewayProbe = &probeResult{}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				*gatewayProbe = probeHost(ctx, gw.Address, probeICMP, timeout)
+			}()
+		}
+	}
+
+	hostResults := make([]probeResult, len(hosts))
+	for i, host := range hosts {
+		wg.Add(1)
+		go func(i int, host string) {
+			defer wg.Done()
+			hostResults[i] = probeHost(ctx, host, method, timeout)
+		}(i, host)
+	}
+
+	dnsResults := make([]probeResult, len(dnsNames))
+	for i, name := range dnsNames {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			dnsResults[i] = probeDNS(ctx, name, timeout)
+		}(i, name)
+	}
+	wg.Wait()
+
+	result := map[string]interface{}{
+		"hosts":      hostResults,
+		"dns":        dnsResults,
+		"timeout_ms": timeoutMs,
+		"diagnosis":  diagnoseConnectivity(gatewayProbe, hostResults, dnsResults),
+	}
+
+	switch {
+	case !checkGateway:
+	case gwErr != nil:
+		result["gateway"] = map[string]interface{}{"available": false, "error": gwErr.Error()}
+	default:
+		result["gateway"] = map[string]interface{}{
+			"available": true,
+			"address":   gw.Address,
+			"interface": gw.Interface,
+			"probe":     gatewayProbe,
+		}
+	}
+
+	if servers := readNameservers("/etc/resolv.conf"); len(servers) > 0 {
+		result["nameservers"] = servers
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// probeHost checks reachability of a host with ICMP ping, or with a TCP connect when
+// method is tcp or the target includes a port
+func probeHost(ctx context.Context, target, method string, timeout time.Duration) probeResult {
+	res := probeResult{Target: target, Method: method}
+
+	if host, port, err := stdnet.SplitHostPort(target); err == nil || method == probeTCP {
+		if err != nil {
+			// Bare host with method tcp: default to HTTPS
+			host, port = target, "443"
+		}
+		res.Method = probeTCP
+		start := time.Now()
+		d := stdnet.Dialer{Timeout: timeout}
+		conn, err := d.DialContext(ctx, "tcp", stdnet.JoinHostPort(host, port))
+		if err != nil {
+			res.Error = err.Error()
+			return res
+		}
+		res.LatencyMs = float64(time.Since(start).Microseconds()) / 1000
+		_ = conn.Close()
+		res.Success = true
+		return res
+	}
+
+	latency, err := pingOnce(ctx, target, timeout)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.Success = true
+	res.LatencyMs = latency
+	return res
+}
+
+// pingOnce sends a single ICMP echo request using the system ping binary, which holds
+// the privileges needed for raw sockets
+func pingOnce(ctx context.Context, host string, timeout time.Duration) (float64, error) {
+	var args []string
+	switch runtime.GOOS {
+	case "windows":
+		args = []string{"-n", "1", "-w", strconv.FormatInt(timeout.Milliseconds(), 10), host}
+	case "darwin":
+		// macOS takes the overall timeout in whole seconds via -t
+		args = []string{"-c", "1", "-t", strconv.Itoa(int(timeout.Seconds()) + 1), host}
+	default:
+		args = []string{"-c", "1", "-W", strconv.Itoa(int(timeout.Seconds()) + 1), host}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout+time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "ping", args...).CombinedOutput() //nolint:gosec // G204: host is validated against probeHostRe
+	if err != nil {
+		return 0, fmt.Errorf("no reply from %s: %w", host, err)
+	}
+	latency, ok := parsePingTime(string(out))
+	if !ok {
+		return 0, fmt.Errorf("no reply from %s", host)
+	}
+	return latency, nil
+}
+
+// parsePingTime extracts the round-trip time in milliseconds from ping output
+func parsePingTime(output string) (float64, bool) {
+	m := pingTimeRe.FindStringSubmatch(output)
+	if m == nil {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// probeDNS resolves a name with the system resolver and records how long it took
+func probeDNS(ctx context.Context, name string, timeout time.Duration) probeResult {
+	res := probeResult{Target: name, Method: "dns"}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	addrs, err := stdnet.DefaultResolver.LookupHost(ctx, name)
+	res.LatencyMs = float64(time.Since(start).Microseconds()) / 1000
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.Success = true
+	res.Addresses = addrs
+	return res
+}
+
+// findDefaultGateway returns the default IPv4 route from /proc/net/route on Linux or
+// `route -n get default` on macOS
+func findDefaultGateway(ctx context.Context) (defaultGateway, error) {
+	switch runtime.GOOS {
+	case "linux":
+		data, err := os.ReadFile(filepath.Clean("/proc/net/route"))
+		if err != nil {
+			return defaultGateway{}, err
+		}
+		return parseProcNetRoute(string(data))
+	case "darwin":
+		out, err := exec.CommandContext(ctx, "route", "-n", "get", "default").Output()
+		if err != nil {
+			return defaultGateway{}, fmt.Errorf("route failed: %w", err)
+		}
+		return parseDarwinRoute(string(out))
+	default:
+		return defaultGateway{}, fmt.Errorf("default gateway detection is not supported on %s", runtime.GOOS)
+	}
+}
+
+// parseProcNetRoute finds the default route in /proc/net/route, whose addresses are
+// little-endian hex
+func parseProcNetRoute(data string) (defaultGateway, error) {
+	for _, line := range strings.Split(data, "\n")[1:] {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[1] != "00000000" {
+			continue
+		}
+		raw, err := hex.DecodeString(fields[2])
+		if err != nil || len(raw) != 4 {
+			continue
+		}
+		ip := make(stdnet.IP, 4)
+		binary.BigEndian.PutUint32(ip, binary.LittleEndian.Uint32(raw))
+		return defaultGateway{Address: ip.String(), Interface: fields[0]}, nil
+	}
+	return defaultGateway{}, fmt.Errorf("no default route")
+}
+
+// parseDarwinRoute parses `route -n get default` output
+func parseDarwinRoute(output string) (defaultGateway, error) {
+	var gw defaultGateway
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "gateway":
+			gw.Address = strings.TrimSpace(value)
+		case "interface":
+			gw.Interface = strings.TrimSpace(value)
+		}
+	}
+	if gw.Address == "" {
+		return gw, fmt.Errorf("no default route")
+	}
+	return gw, nil
+}
+
+// readNameservers returns the nameserver entries from a resolv.conf file
+func readNameservers(path string) []string {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil
+	}
+	var servers []string
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			servers = append(servers, fields[1])
+		}
+	}
+	return servers
+}
+
+// diagnoseConnectivity summarizes probe results into a single verdict that separates a
+// broken local link from unreachable remote hosts and failing DNS
+func diagnoseConnectivity(gateway *probeResult, hosts, dns []probeResult) string {
+	if gateway != nil && !gateway.Success && len(hosts) > 0 && countSuccesses(hosts) == 0 {
+		return diagnosisLocalNetwork
+	}
+	if len(hosts) > 0 && countSuccesses(hosts) == 0 {
+		return diagnosisNoInternet
+	}
+	if len(dns) > 0 && countSuccesses(dns) == 0 {
+		return diagnosisDNSFailing
+	}
+	if countSuccesses(hosts) < len(hosts) || countSuccesses(dns) < len(dns) {
+		return diagnosisPartialFailures
+	}
+	if gateway != nil && !gateway.Success && len(hosts) == 0 {
+		return diagnosisLocalNetwork
+	}
+	return diagnosisOK
+}
+
+// countSuccesses returns how many probes succeeded
+func countSuccesses(results []probeResult) int {
+	n := 0
+	for _, r := range results {
+		if r.Success {
+			n++
+		}
+	}
+	return n
+}
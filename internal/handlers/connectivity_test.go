@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	stdnet "net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestParseProcNetRoute(t *testing.T) {
+	data := "Iface\tDestination\tGateway \tFlags\tRefCnt\tUse\tMetric\tMask\t\tMTU\tWindow\tIRTT\n" +
+		"eth0\t0001A8C0\t00000000\t0001\t0\t0\t0\t00FFFFFF\t0\t0\t0\n" +
+		"eth0\t00000000\t0101A8C0\t0003\t0\t0\t100\t00000000\t0\t0\t0\n"
+
+	gw, err := parseProcNetRoute(data)
+	if err != nil {
+		t.Fatalf("parseProcNetRoute failed: %v", err)
+	}
+	if gw.Address != "192.168.1.1" || gw.Interface != "eth0" {
+		t.Errorf("Unexpected gateway: %+v", gw)
+	}
+
+	if _, err := parseProcNetRoute(data[:len(data)-len("eth0\t00000000\t0101A8C0\t0003\t0\t0\t100\t00000000\t0\t0\t0\n")]); err == nil {
+		t.Error("Expected error when no default route is present")
+	}
+}
+
+func TestParseDarwinRoute(t *testing.T) {
+	gw, err := parseDarwinRoute("   route to: default\ndestination: default\n       mask: default\n    gateway: 10.0.0.1\n  interface: en0\n")
+	if err != nil {
+		t.Fatalf("parseDarwinRoute failed: %v", err)
+	}
+	if gw.Address != "10.0.0.1" || gw.Interface != "en0" {
+		t.Errorf("Unexpected gateway: %+v", gw)
+	}
+}
+
+func TestParsePingTime(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected float64
+		ok       bool
+	}{
+		{"linux", "64 bytes from 1.1.1.1: icmp_seq=1 ttl=57 time=12.4 ms", 12.4, true},
+		{"windows", "Reply from 1.1.1.1: bytes=32 time<1ms TTL=57", 1, true},
+		{"windows_ms", "Reply from 1.1.1.1: bytes=32 time=14ms TTL=57", 14, true},
+		{"no_reply", "1 packets transmitted, 0 received, 100% packet loss", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parsePingTime(tt.output)
+			if ok != tt.ok || got != tt.expected {
+				t.Errorf("parsePingTime() = %v, %v; expected %v, %v", got, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestDiagnoseConnectivity(t *testing.T) {
+	ok := probeResult{Success: true}
+	fail := probeResult{}
+
+	tests := []struct {
+		name     string
+		gateway  *probeResult
+		hosts    []probeResult
+		dns      []probeResult
+		expected string
+	}{
+		{"all_ok", &ok, []probeResult{ok}, []probeResult{ok}, diagnosisOK},
+		{"gateway_down", &fail, []probeResult{fail}, nil, diagnosisLocalNetwork},
+		{"gateway_only_down", &fail, nil, nil, diagnosisLocalNetwork},
+		{"remote_down", &ok, []probeResult{fail, fail}, []probeResult{ok}, diagnosisNoInternet},
+		{"dns_down", &ok, []probeResult{ok}, []probeResult{fail}, diagnosisDNSFailing},
+		{"partial", nil, []probeResult{ok, fail}, []probeResult{ok}, diagnosisPartialFailures},
+		{"nothing_probed", nil, nil, nil, diagnosisOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diagnoseConnectivity(tt.gateway, tt.hosts, tt.dns); got != tt.expected {
+				t.Errorf("diagnoseConnectivity() = %s, expected %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestReadNameservers(t *testing.T) {
+	dir := t.TempDir()
+	writeSysfsFiles(t, dir, map[string]string{
+		"resolv.conf": "# Generated by NetworkManager\nsearch lan\nnameserver 192.168.1.1\nnameserver 2606:4700:4700::1111\n",
+	})
+
+	servers := readNameservers(filepath.Join(dir, "resolv.conf"))
+	if len(servers) != 2 || servers[0] != "192.168.1.1" || servers[1] != "2606:4700:4700::1111" {
+		t.Errorf("Unexpected nameservers: %v", servers)
+	}
+	if servers := readNameservers(filepath.Join(dir, "missing")); servers != nil {
+		t.Errorf("Expected nil for missing file, got %v", servers)
+	}
+}
+
+func TestProbeHostTCP(t *testing.T) {
+	ln, err := stdnet.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	addr := ln.Addr().String()
+
+	res := probeHost(context.Background(), addr, probeICMP, time.Second)
+	if !res.Success || res.Method != probeTCP {
+		t.Errorf("Expected successful TCP probe, got %+v", res)
+	}
+
+	// Once closed, the same port should refuse connections
+	_ = ln.Close()
+	res = probeHost(context.Background(), addr, probeTCP, time.Second)
+	if res.Success || res.Error == "" {
+		t.Errorf("Expected failed TCP probe, got %+v", res)
+	}
+}
+
+func TestHandleCheckConnectivity(t *testing.T) {
+	ln, err := stdnet.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+
+	h := NewHandlerManager(&config.Config{})
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{
+				"hosts":         ln.Addr().String(),
+				"dns_names":     "localhost",
+				"check_gateway": false,
+			},
+		},
+	}
+	res, err := h.HandleCheckConnectivity(context.Background(), req)
+	checkToolResult(t, res, err, []string{"hosts", "dns", "diagnosis", "timeout_ms"})
+
+	var result map[string]interface{}
+	if err := json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &result); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+	if result["diagnosis"] != diagnosisOK {
+		t.Errorf("Expected diagnosis %s, got %v", diagnosisOK, result["diagnosis"])
+	}
+	if _, ok := result["gateway"]; ok {
+		t.Error("Expected no gateway when check_gateway is false")
+	}
+}
+
+func TestHandleCheckConnectivityInvalidArgs(t *testing.T) {
+	h := NewHandlerManager(&config.Config{})
+
+	tests := []struct {
+		name string
+		args map[string]interface{}
+	}{
+		{"option_injection", map[string]interface{}{"hosts": "-f"}},
+		{"shell_chars", map[string]interface{}{"dns_names": "example.com;reboot"}},
+		{"bad_method", map[string]interface{}{"hosts": "1.1.1.1", "method": "udp"}},
+		{"too_many", map[string]interface{}{"hosts": "a,b,c,d,e,f,g,h,i,j,k"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: tt.args}}
+			res, err := h.HandleCheckConnectivity(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Handler returned error: %v", err)
+			}
+			if !res.IsError {
+				t.Error("Expected error result")
+			}
+		})
+	}
+}
@@ -0,0 +1,439 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"sysmetrics-mcp/internal/cgroups"
+	"sysmetrics-mcp/internal/dockerapi"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Container runtime name constants.
+const (
+	runtimeDocker     = "docker"
+	runtimePodman     = "podman"
+	runtimeContainerd = "containerd"
+)
+
+// dockerSocket is the Docker Engine API socket queried for the details the CLI leaves out
+var dockerSocket = dockerapi.DefaultSocket
+
+// k3sContainerdSocket is the containerd socket used by k3s instead of the default one
+const k3sContainerdSocket = "/run/k3s/containerd/containerd.sock"
+
+// containerRuntime describes how to query a Docker-CLI-compatible container runtime
+type containerRuntime struct {
+	name       string
+	binary     string
+	globalArgs []string
+}
+
+// containerFields lists the columns the container tools can return
+var containerFields = []string{
+	"container_id", "name", "image", "status", "running", "runtime",
+	"cpu_percent", "memory_usage", "memory_percent", "network_io", "block_io", "pids", "cgroup",
+	"health", "restart_count", "ports", "network_bytes", "block_io_bytes",
+}
+
+// containerStatsFields are the columns that require a `<runtime> stats` call
+var containerStatsFields = []string{"cpu_percent", "memory_usage", "memory_percent", "network_io", "block_io", "pids"}
+
+// containerAPIFields are the Docker columns read from the Engine API
+var containerAPIFields = []string{"health", "restart_count", "ports", "network_bytes", "block_io_bytes"}
+
+// containerAPIStatsFields are the Engine API columns that require a stats request
+var containerAPIStatsFields = []string{"network_bytes", "block_io_bytes"}
+
+// containerInfo is a single row of `<runtime> ps` output
+type containerInfo struct {
+	id      string
+	name    string
+	image   string
+	status  string
+	running bool
+}
+
+// containerStats is a single row of `<runtime> stats` output
+type containerStats struct {
+	cpuPerc  string
+	memUsage string
+	memPerc  string
+	netIO    string
+	blockIO  string
+	pids     string
+}
+
+// detectedRuntimes returns the container runtimes whose CLIs were found at startup
+func (h *HandlerManager) detectedRuntimes() []containerRuntime {
+	var runtimes []containerRuntime
+	if h.caps.DockerCLI {
+		runtimes = append(runtimes, containerRuntime{name: runtimeDocker, binary: "docker"})
+	}
+	if h.caps.Podman {
+		runtimes = append(runtimes, containerRuntime{name: runtimePodman, binary: "podman"})
+	}
+	if h.caps.Nerdctl {
+		rt := containerRuntime{name: runtimeContainerd, binary: "nerdctl"}
+		// k3s runs its own containerd with Kubernetes containers in the k8s.io namespace
+		if _, err := os.Stat(k3sContainerdSocket); err == nil {
+			rt.globalArgs = []string{"--address", k3sContainerdSocket, "--namespace", "k8s.io"}
+		}
+		runtimes = append(runtimes, rt)
+	}
+	return runtimes
+}
+
+// HandleGetDockerMetrics returns Docker container metrics using the docker CLI.
+// This approach works with both cgroups v1 and v2 systems.
+func (h *HandlerManager) HandleGetDockerMetrics(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var containerFilter string
+
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if cid, ok := args["container_id"].(string); ok && cid != "" {
+			containerFilter = cid
+		}
+	}
+
+	fields, err := parseFieldsArg(request, containerFields)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// Verify docker is available
+	if _, err := exec.LookPath("docker"); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Docker CLI not found: %v", err)), nil
+	}
+
+	containerData, api, err := h.collectContainers(ctx, containerRuntime{name: runtimeDocker, binary: "docker"}, containerFilter, fields)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to list Docker containers: %v", err)), nil
+	}
+
+	result := map[string]interface{}{
+		"containers": containerData,
+		"total":      len(containerData),
+	}
+	if api != nil {
+		result["docker_api"] = api
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// HandleGetContainerMetrics returns container metrics across Docker, Podman, and containerd
+func (h *HandlerManager) HandleGetContainerMetrics(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var containerFilter, runtimeFilter string
+
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if cid, ok := args["container_id"].(string); ok && cid != "" {
+			containerFilter = cid
+		}
+		if rt, ok := args["runtime"].(string); ok && rt != "" {
+			runtimeFilter = strings.ToLower(rt)
+		}
+	}
+
+	fields, err := parseFieldsArg(request, containerFields)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	runtimes := h.detectedRuntimes()
+	if len(runtimes) == 0 {
+		return mcp.NewToolResultError("No container runtime CLI found (docker, podman, or nerdctl)"), nil
+	}
+
+	containerData := []map[string]interface{}{}
+	runtimeStatus := map[string]interface{}{}
+	for _, rt := range runtimes {
+		if runtimeFilter != "" && runtimeFilter != rt.name {
+			continue
+		}
+
+		// A failing runtime is reported without hiding the others
+		containers, api, err := h.collectContainers(ctx, rt, containerFilter, fields)
+		if err != nil {
+			runtimeStatus[rt.name] = map[string]interface{}{"available": false, "error": err.Error()}
+			continue
+		}
+		status := map[string]interface{}{"available": true, "containers": len(containers)}
+		if api != nil {
+			status["api"] = api
+		}
+		runtimeStatus[rt.name] = status
+		containerData = append(containerData, containers...)
+	}
+
+	result := map[string]interface{}{
+		"containers": containerData,
+		"total":      len(containerData),
+		"runtimes":   runtimeStatus,
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// collectContainers lists containers and their live stats for a single runtime. For
+// Docker, the columns in containerAPIFields are read from the Engine API, and api reports
+// whether that worked; it is nil when the API was not queried.
+func (h *HandlerManager) collectContainers(ctx context.Context, rt containerRuntime, containerFilter string, fields map[string]bool) (rows []map[string]interface{}, api map[string]interface{}, err error) {
+	// Get container list via ps
+	psArgs := append(append([]string{}, rt.globalArgs...),
+		"ps", "-a", "--no-trunc", "--format", "{{.ID}}|{{.Names}}|{{.Image}}|{{.Status}}|{{.State}}")
+	psOut, err := h.privilegedCommand(ctx, rt.binary, psArgs...).Output()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list %s containers: %w", rt.name, err)
+	}
+
+	containers := parseContainerList(string(psOut), containerFilter)
+
+	// Only fetch stats if we have containers and a stats column was requested
+	statsMap := make(map[string]containerStats)
+	if len(containers) > 0 && wantAnyField(fields, containerStatsFields) {
+		statsArgs := append(append([]string{}, rt.globalArgs...),
+			"stats", "--no-stream", "--format", "{{.ID}}|{{.CPUPerc}}|{{.MemUsage}}|{{.MemPerc}}|{{.NetIO}}|{{.BlockIO}}|{{.PIDs}}")
+		statsOut, err := h.privilegedCommand(ctx, rt.binary, statsArgs...).Output()
+		if err == nil {
+			statsMap = parseContainerStats(string(statsOut))
+		}
+	}
+
+	// The Engine API adds what docker ps and docker stats do not print
+	var details map[string]map[string]interface{}
+	if rt.name == runtimeDocker && h.caps.DockerSocket && len(containers) > 0 && wantAnyField(fields, containerAPIFields) {
+		details, err = dockerAPIDetails(ctx, dockerapi.New(dockerSocket), containers, fields)
+		api = map[string]interface{}{"available": err == nil}
+		if err != nil {
+			api["error"] = err.Error()
+		}
+	}
+
+	// Build result
+	containerData := []map[string]interface{}{}
+	for _, c := range containers {
+		cInfo := map[string]interface{}{
+			"container_id": c.id,
+			"name":         c.name,
+			"image":        c.image,
+			"status":       c.status,
+			"running":      c.running,
+			"runtime":      rt.name,
+		}
+
+		if stats, ok := lookupContainerStats(statsMap, c.id); ok {
+			cInfo["cpu_percent"] = stats.cpuPerc
+			cInfo["memory_usage"] = stats.memUsage
+			cInfo["memory_percent"] = stats.memPerc
+			cInfo["network_io"] = stats.netIO
+			cInfo["block_io"] = stats.blockIO
+			cInfo["pids"] = stats.pids
+		}
+		for k, v := range details[c.id] {
+			cInfo[k] = v
+		}
+
+		// On cgroups v2 hosts, add raw counters read directly from the container's cgroup
+		if h.caps.CgroupV2 && c.running && wantField(fields, "cgroup") {
+			if dir, ok := cgroups.FindContainerDir(c.id); ok {
+				if cgStats, err := cgroups.ReadStats(dir); err == nil {
+					cInfo["cgroup"] = cgStats
+				}
+			}
+		}
+
+		containerData = append(containerData, projectFields(cInfo, fields))
+	}
+
+	return containerData, api, nil
+}
+
+// wantAnyField reports whether any of names was requested
+func wantAnyField(fields map[string]bool, names []string) bool {
+	for _, f := range names {
+		if wantField(fields, f) {
+			return true
+		}
+	}
+	return false
+}
+
+// dockerAPIDetails reads health, restart count, ports, and network and block I/O byte
+// counters for each container from the Docker Engine API, keyed by full container ID.
+// Stats are only requested for running containers, concurrently, as each one-shot sample
+// can take the daemon a moment.
+func dockerAPIDetails(ctx context.Context, client *dockerapi.Client, containers []containerInfo, fields map[string]bool) (map[string]map[string]interface{}, error) {
+	list, err := client.Containers(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	ports := make(map[string][]dockerapi.Port, len(list))
+	for _, c := range list {
+		ports[c.ID] = c.Ports
+	}
+
+	wantInspect := wantField(fields, "health") || wantField(fields, "restart_count")
+	wantStats := wantAnyField(fields, containerAPIStatsFields)
+
+	details := make(map[string]map[string]interface{}, len(containers))
+	var wg sync.WaitGroup
+	for _, c := range containers {
+		d := map[string]interface{}{}
+		details[c.id] = d
+		if p, ok := ports[c.id]; ok {
+			d["ports"] = formatDockerPorts(p)
+		}
+		if !wantInspect && (!wantStats || !c.running) {
+			continue
+		}
+
+		wg.Add(1)
+		go func(c containerInfo) {
+			defer wg.Done()
+			var inspection dockerapi.Inspection
+			var inspectErr, statsErr error
+			var stats dockerapi.Stats
+			if wantInspect {
+				inspection, inspectErr = client.Inspect(ctx, c.id)
+			}
+			if wantStats && c.running {
+				stats, statsErr = client.Stats(ctx, c.id)
+			}
+
+			// Each goroutine only writes its own container's map
+			if wantInspect && inspectErr == nil {
+				d["restart_count"] = inspection.RestartCount
+				d["health"] = dockerHealth(inspection.State)
+			}
+			if wantStats && c.running && statsErr == nil {
+				rx, tx := stats.NetworkTotals()
+				read, write := stats.BlockIO()
+				d["network_bytes"] = map[string]uint64{"rx": rx, "tx": tx}
+				d["block_io_bytes"] = map[string]uint64{"read": read, "write": write}
+			}
+		}(c)
+	}
+	wg.Wait()
+	return details, nil
+}
+
+// dockerHealth summarizes a container's health check. Containers without a health
+// check report "none".
+func dockerHealth(state dockerapi.State) map[string]interface{} {
+	if state.Health == nil || state.Health.Status == "" {
+		return map[string]interface{}{"status": "none", "oom_killed": state.OOMKilled}
+	}
+	health := map[string]interface{}{
+		"status":         state.Health.Status,
+		"failing_streak": state.Health.FailingStreak,
+		"oom_killed":     state.OOMKilled,
+	}
+	if n := len(state.Health.Log); n > 0 {
+		last := state.Health.Log[n-1]
+		health["last_exit_code"] = last.ExitCode
+		health["last_output"] = strings.TrimSpace(last.Output)
+		if !last.End.IsZero() {
+			health["last_check"] = last.End.UTC().Format(time.RFC3339)
+		}
+	}
+	return health
+}
+
+// formatDockerPorts formats port mappings as docker ps prints them, e.g.
+// "0.0.0.0:8080->80/tcp" for a published port and "443/tcp" for an exposed one
+func formatDockerPorts(ports []dockerapi.Port) []string {
+	out := make([]string, 0, len(ports))
+	for _, p := range ports {
+		if p.PublicPort == 0 {
+			out = append(out, fmt.Sprintf("%d/%s", p.PrivatePort, p.Type))
+			continue
+		}
+		ip := p.IP
+		if strings.Contains(ip, ":") {
+			ip = "[" + ip + "]"
+		}
+		out = append(out, fmt.Sprintf("%s:%d->%d/%s", ip, p.PublicPort, p.PrivatePort, p.Type))
+	}
+	sort.Strings(out)
+	return out
+}
+
+// parseContainerList parses pipe-delimited ps output, applying an optional ID/name filter
+func parseContainerList(output, containerFilter string) []containerInfo {
+	var containers []containerInfo
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if line == "" {
+			continue
+		}
+		cols := strings.SplitN(line, "|", 5)
+		if len(cols) != 5 {
+			continue
+		}
+		c := containerInfo{
+			id:      cols[0],
+			name:    cols[1],
+			image:   cols[2],
+			status:  cols[3],
+			running: strings.EqualFold(cols[4], "running"),
+		}
+		// Client-side filtering by container ID or name
+		if containerFilter != "" && c.id != containerFilter && c.name != containerFilter &&
+			!strings.HasPrefix(c.id, containerFilter) {
+			continue
+		}
+		containers = append(containers, c)
+	}
+	return containers
+}
+
+// parseContainerStats parses pipe-delimited stats output keyed by container ID
+func parseContainerStats(output string) map[string]containerStats {
+	statsMap := make(map[string]containerStats)
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if line == "" {
+			continue
+		}
+		cols := strings.SplitN(line, "|", 7)
+		if len(cols) != 7 {
+			continue
+		}
+		statsMap[cols[0]] = containerStats{
+			cpuPerc:  strings.TrimSpace(cols[1]),
+			memUsage: strings.TrimSpace(cols[2]),
+			memPerc:  strings.TrimSpace(cols[3]),
+			netIO:    strings.TrimSpace(cols[4]),
+			blockIO:  strings.TrimSpace(cols[5]),
+			pids:     strings.TrimSpace(cols[6]),
+		}
+	}
+	return statsMap
+}
+
+// lookupContainerStats finds stats for a container by full ID, falling back to the
+// 12-character short ID that some runtimes print in stats output
+func lookupContainerStats(statsMap map[string]containerStats, id string) (containerStats, bool) {
+	if stats, ok := statsMap[id]; ok {
+		return stats, true
+	}
+	if len(id) > 12 {
+		stats, ok := statsMap[id[:12]]
+		return stats, ok
+	}
+	return containerStats{}, false
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+	"sysmetrics-mcp/internal/dockerapi"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestParseContainerList(t *testing.T) {
+	output := "abc123def456789|web|nginx:latest|Up 2 hours|running\n" +
+		"fff000111222333|db|postgres:16|Exited (0) 3 days ago|exited\n" +
+		"malformed line\n"
+
+	tests := []struct {
+		name   string
+		filter string
+		want   int
+	}{
+		{"No filter", "", 2},
+		{"Filter by name", "db", 1},
+		{"Filter by ID prefix", "abc123", 1},
+		{"Filter no match", "nope", 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := parseContainerList(output, tc.filter)
+			if len(got) != tc.want {
+				t.Errorf("parseContainerList(filter=%q) returned %d containers; want %d", tc.filter, len(got), tc.want)
+			}
+		})
+	}
+
+	all := parseContainerList(output, "")
+	if !all[0].running || all[1].running {
+		t.Errorf("Unexpected running states: %+v", all)
+	}
+}
+
+func TestLookupContainerStats(t *testing.T) {
+	statsMap := parseContainerStats("abc123def456|1.5%|10MiB / 1GiB|1.0%|1kB / 2kB|0B / 0B|3\n")
+
+	if _, ok := lookupContainerStats(statsMap, "abc123def456789abcdef"); !ok {
+		t.Error("Expected stats lookup by short ID prefix to succeed")
+	}
+	if _, ok := lookupContainerStats(statsMap, "zzz"); ok {
+		t.Error("Expected stats lookup for unknown ID to fail")
+	}
+}
+
+func TestFormatDockerPorts(t *testing.T) {
+	got := formatDockerPorts([]dockerapi.Port{
+		{IP: "0.0.0.0", PrivatePort: 80, PublicPort: 8080, Type: "tcp"},
+		{IP: "::", PrivatePort: 80, PublicPort: 8080, Type: "tcp"},
+		{PrivatePort: 53, Type: "udp"},
+	})
+	expected := []string{"0.0.0.0:8080->80/tcp", "53/udp", "[::]:8080->80/tcp"}
+	if !slices.Equal(got, expected) {
+		t.Errorf("formatDockerPorts() = %v, expected %v", got, expected)
+	}
+}
+
+func TestDockerHealth(t *testing.T) {
+	if h := dockerHealth(dockerapi.State{}); h["status"] != "none" {
+		t.Errorf("Expected no health check, got %v", h)
+	}
+	h := dockerHealth(dockerapi.State{Health: &dockerapi.Health{Status: "unhealthy", FailingStreak: 4,
+		Log: []dockerapi.HealthLog{{ExitCode: 0}, {ExitCode: 1, Output: "curl: (7) refused\n"}}}})
+	if h["status"] != "unhealthy" || h["failing_streak"] != 4 || h["last_exit_code"] != 1 || h["last_output"] != "curl: (7) refused" {
+		t.Errorf("Unexpected health summary: %v", h)
+	}
+}
+
+func TestHandleGetContainerMetrics(t *testing.T) {
+	h := NewHandlerManager(&config.Config{})
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{},
+		},
+	}
+	res, err := h.HandleGetContainerMetrics(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if len(h.detectedRuntimes()) == 0 {
+		// No runtimes in CI: the tool must report a graceful error
+		if !res.IsError {
+			t.Error("Expected error result when no container runtime is available")
+		}
+		return
+	}
+	checkToolResult(t, res, err, []string{"containers", "total", "runtimes"})
+}
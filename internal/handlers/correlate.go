@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"sysmetrics-mcp/internal/history"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// correlate_events defaults and bounds
+const (
+	defaultCorrelationWindow = 15 * time.Minute
+	maxCorrelationWindow     = 6 * time.Hour
+	defaultCorrelationLookup = 24 * time.Hour
+	minCorrelationSamples    = 3
+)
+
+// correlationAnchor is the moment (or range) being investigated
+type correlationAnchor struct {
+	Start time.Time      `json:"start"`
+	End   time.Time      `json:"end"`
+	Event *history.Event `json:"event,omitempty"`
+}
+
+// metricChange is a series that moved away from its baseline around the anchor
+type metricChange struct {
+	Metric        string  `json:"metric"`
+	Labels        string  `json:"labels,omitempty"`
+	Unit          string  `json:"unit,omitempty"`
+	Direction     string  `json:"direction"`
+	BaselineMean  float64 `json:"baseline_mean"`
+	Peak          float64 `json:"peak"`
+	PeakTime      string  `json:"peak_time"`
+	ZScore        float64 `json:"z_score"`
+	StartedAt     string  `json:"started_at"`
+	OffsetSeconds int64   `json:"offset_seconds"`
+	Relation      string  `json:"relation"`
+	started       time.Time
+}
+
+// correlatedEvent is a journaled event near the anchor
+type correlatedEvent struct {
+	history.Event
+	OffsetSeconds int64  `json:"offset_seconds"`
+	Relation      string `json:"relation"`
+}
+
+// timelineEntry is one line of the merged timeline
+type timelineEntry struct {
+	Time        time.Time `json:"time"`
+	Kind        string    `json:"kind"`
+	Description string    `json:"description"`
+}
+
+// HandleCorrelateEvents gathers the metric changes and other events around an event or a
+// time range, with each placed relative to it, so causes and effects can be read together
+func (h *HandlerManager) HandleCorrelateEvents(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if h.history == nil {
+		return mcp.NewToolResultError("Metrics history is disabled (start the server with --history-db)"), nil
+	}
+
+	var at, until time.Time
+	var category, subject string
+	window := defaultCorrelationWindow
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		for key, dst := range map[string]*time.Time{"time": &at, "until": &until} {
+			if s, ok := args[key].(string); ok && s != "" {
+				t, err := time.Parse(time.RFC3339, strings.TrimSpace(s))
+				if err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("Invalid %s: %q (use an RFC 3339 timestamp such as 2026-01-02T15:04:05Z)", key, s)), nil
+				}
+				*dst = t
+			}
+		}
+		if c, ok := args["category"].(string); ok && c != "" {
+			category = strings.TrimSpace(c)
+			if !contains(history.EventCategories, category) {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid category: %q (use %s)", category, strings.Join(history.EventCategories, ", "))), nil
+			}
+		}
+		if s, ok := args["subject"].(string); ok {
+			subject = strings.TrimSpace(s)
+		}
+		if w, ok := args["window"].(string); ok && w != "" {
+			d, err := parseHistoryDuration(w)
+			if err != nil || d <= 0 || d > maxCorrelationWindow {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid window: %q (use a duration such as 5m or 30m, at most 6h)", w)), nil
+			}
+			window = d
+		}
+	}
+	if !until.IsZero() && at.IsZero() {
+		return mcp.NewToolResultError("until requires time (the start of the range)"), nil
+	}
+	if !until.IsZero() && until.Before(at) {
+		return mcp.NewToolResultError("until must not be before time"), nil
+	}
+
+	anchor, err := h.correlationAnchor(ctx, at, until, category, subject)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	from, to := anchor.Start.Add(-window), anchor.End.Add(window)
+
+	nearby, err := h.history.Events(ctx, history.EventQuery{Since: from, Until: to})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read events: %v", err)), nil
+	}
+	events := []correlatedEvent{}
+	for i := len(nearby) - 1; i >= 0; i-- {
+		e := nearby[i]
+		if anchor.Event != nil && sameEvent(e, *anchor.Event) {
+			continue
+		}
+		offset, relation := h.relation(anchor, e.Time)
+		events = append(events, correlatedEvent{Event: e, OffsetSeconds: int64(offset / time.Second), Relation: relation})
+	}
+
+	// Each series is judged against the window just before the correlation window
+	series, err := h.history.Raw(ctx, "", from.Add(-window), to)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read metrics history: %v", err)), nil
+	}
+	changes := []metricChange{}
+	for _, s := range series {
+		if c, ok := h.detectChange(s, from, anchor); ok {
+			changes = append(changes, c)
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].started.Before(changes[j].started) })
+
+	result := map[string]interface{}{
+		"anchor":         anchor,
+		"window":         window.String(),
+		"events":         events,
+		"metric_changes": changes,
+		"timeline":       h.correlationTimeline(anchor, events, changes),
+	}
+	if len(series) == 0 {
+		result["note"] = "No metrics history around the anchor"
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// correlationAnchor resolves the arguments to a range or an event. With a category (and
+// optional subject) it is the newest matching event at or before time; with neither it is
+// the newest warning or critical event of the last day.
+func (h *HandlerManager) correlationAnchor(ctx context.Context, at, until time.Time, category, subject string) (correlationAnchor, error) {
+	if category == "" && subject == "" && !at.IsZero() {
+		return correlationAnchor{Start: at, End: cmpTime(until, at)}, nil
+	}
+
+	end := cmpTime(at, time.Now())
+	q := history.EventQuery{Since: end.Add(-defaultCorrelationLookup), Until: end}
+	if category != "" {
+		q.Categories = []string{category}
+	} else if subject == "" {
+		q.MinSeverity = history.SeverityWarning
+	}
+	events, err := h.history.Events(ctx, q)
+	if err != nil {
+		return correlationAnchor{}, fmt.Errorf("failed to read events: %w", err)
+	}
+	for _, e := range events {
+		if subject == "" || e.Subject == subject {
+			return correlationAnchor{Start: e.Time, End: e.Time, Event: &e}, nil
+		}
+	}
+	return correlationAnchor{}, fmt.Errorf("no matching event in the %s before %s; pass time for a specific moment", h.human.Duration(defaultCorrelationLookup), end.UTC().Format(time.RFC3339))
+}
+
+// cmpTime returns t, or fallback when t is zero
+func cmpTime(t, fallback time.Time) time.Time {
+	if t.IsZero() {
+		return fallback
+	}
+	return t
+}
+
+// relation returns how far t lies from the anchor (negative before its start, positive
+// after its end, zero within it) and describes it, e.g. "3 minutes before"
+func (h *HandlerManager) relation(a correlationAnchor, t time.Time) (time.Duration, string) {
+	var offset time.Duration
+	switch {
+	case t.Before(a.Start):
+		offset = t.Sub(a.Start)
+	case t.After(a.End):
+		offset = t.Sub(a.End)
+	case a.End.After(a.Start):
+		return 0, "during the range"
+	}
+	switch {
+	case offset <= -time.Minute:
+		return offset, h.human.Duration(offset.Truncate(time.Second)) + " before"
+	case offset >= time.Minute:
+		return offset, h.human.Duration(offset.Truncate(time.Second)) + " after"
+	}
+	return offset, "within a minute"
+}
+
+// sameEvent reports whether two journal entries are the same event
+func sameEvent(a, b history.Event) bool {
+	return a.Time.Equal(b.Time) && a.Category == b.Category && a.Subject == b.Subject && a.Message == b.Message
+}
+
+// detectChange compares a series inside the correlation window (from onwards) with its
+// baseline just before it. ok is true when the furthest value in the window is at least
+// the anomaly z-score threshold away; the change starts at the first such value.
+func (h *HandlerManager) detectChange(s history.RawSeries, from time.Time, anchor correlationAnchor) (c metricChange, ok bool) {
+	points := s.Points
+	c = metricChange{Metric: s.Metric, Labels: s.Labels}
+	if counterMetrics[s.Metric] {
+		points = history.Rates(points)
+		c.Unit = "per_second"
+	}
+	split := sort.Search(len(points), func(i int) bool { return !points[i].Time.Before(from) })
+	if split < minCorrelationSamples || split == len(points) {
+		return c, false
+	}
+	baseline := history.NewBaseline(points[:split])
+	minStdDev := anomalyMinStdDev(s.Metric, baseline.Mean)
+
+	peak := points[split]
+	for _, p := range points[split:] {
+		if math.Abs(p.Value-baseline.Mean) > math.Abs(peak.Value-baseline.Mean) {
+			peak = p
+		}
+	}
+	z := baseline.ZScore(peak.Value, minStdDev)
+	if math.Abs(z) < defaultZScoreThreshold {
+		return c, false
+	}
+
+	c.Direction = "rose"
+	if z < 0 {
+		c.Direction = "fell"
+	}
+	for _, p := range points[split:] {
+		if pz := baseline.ZScore(p.Value, minStdDev); math.Abs(pz) >= defaultZScoreThreshold && (pz < 0) == (z < 0) {
+			c.started = p.Time
+			break
+		}
+	}
+	offset, relation := h.relation(anchor, c.started)
+	c.BaselineMean = round2(baseline.Mean)
+	c.Peak = round2(peak.Value)
+	c.PeakTime = peak.Time.UTC().Format(time.RFC3339)
+	c.ZScore = round2(z)
+	c.StartedAt = c.started.UTC().Format(time.RFC3339)
+	c.OffsetSeconds = int64(offset / time.Second)
+	c.Relation = relation
+	return c, true
+}
+
+// correlationTimeline merges the anchor, events, and metric changes into one
+// chronological list of sentences
+func (h *HandlerManager) correlationTimeline(anchor correlationAnchor, events []correlatedEvent, changes []metricChange) []timelineEntry {
+	timeline := []timelineEntry{}
+	if anchor.Event != nil {
+		timeline = append(timeline, timelineEntry{Time: anchor.Start, Kind: "anchor", Description: anchor.Event.Message})
+	} else {
+		timeline = append(timeline, timelineEntry{Time: anchor.Start, Kind: "anchor", Description: "Start of the range"})
+		if anchor.End.After(anchor.Start) {
+			timeline = append(timeline, timelineEntry{Time: anchor.End, Kind: "anchor", Description: "End of the range"})
+		}
+	}
+	for _, e := range events {
+		timeline = append(timeline, timelineEntry{Time: e.Time, Kind: e.Category, Description: fmt.Sprintf("%s (%s)", e.Message, e.Relation)})
+	}
+	for _, c := range changes {
+		timeline = append(timeline, timelineEntry{Time: c.started, Kind: "metric", Description: fmt.Sprintf("%s %s from %v to %v (%s)",
+			seriesName(c.Metric, c.Labels), c.Direction, c.BaselineMean, c.Peak, c.Relation)})
+	}
+	sort.SliceStable(timeline, func(i, j int) bool { return timeline[i].Time.Before(timeline[j].Time) })
+	return timeline
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"sysmetrics-mcp/internal/history"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleCorrelateEvents(t *testing.T) {
+	h := newHistoryTestManager(t)
+	ctx := context.Background()
+	anchor := time.Now().Add(-time.Hour).Truncate(time.Minute)
+
+	// CPU is steady around 10% until a compile starts 3 minutes before throttling; memory stays flat
+	var samples []history.Sample
+	for i := -45; i <= 15; i++ {
+		cpu := 10.0 + float64(i%2)
+		if i >= -3 {
+			cpu = 90
+		}
+		ts := anchor.Add(time.Duration(i) * time.Minute)
+		samples = append(samples,
+			history.Sample{Time: ts, Metric: "cpu_percent", Value: cpu},
+			history.Sample{Time: ts, Metric: "memory_used_percent", Value: 50})
+	}
+	if err := h.history.Write(ctx, samples); err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range []history.Event{
+		{Time: anchor.Add(-2 * time.Hour), Category: history.EventReboot, Severity: history.SeverityInfo, Message: "System booted"},
+		{Time: anchor, Category: history.EventThrottle, Severity: history.SeverityWarning, Subject: "throttled", Message: "CPU is being throttled"},
+		{Time: anchor.Add(2 * time.Minute), Category: history.EventAlert, Severity: history.SeverityWarning, Subject: "temperature", Message: "Temperature is high (>70°C)"},
+	} {
+		h.recordEvent(ctx, e)
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"category": "throttle"}
+	res, err := h.HandleCorrelateEvents(ctx, req)
+	checkToolResult(t, res, err, []string{"anchor", "window", "events", "metric_changes", "timeline"})
+	var result struct {
+		Anchor   correlationAnchor `json:"anchor"`
+		Events   []correlatedEvent `json:"events"`
+		Changes  []metricChange    `json:"metric_changes"`
+		Timeline []timelineEntry   `json:"timeline"`
+	}
+	if err := json.Unmarshal([]byte(resultText(res)), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Anchor.Event == nil || result.Anchor.Event.Category != history.EventThrottle || !result.Anchor.Start.Equal(anchor) {
+		t.Fatalf("Expected the throttle event as anchor, got %+v", result.Anchor)
+	}
+	if len(result.Events) != 1 || result.Events[0].Subject != "temperature" || result.Events[0].OffsetSeconds != 120 || result.Events[0].Relation != "2 minutes after" {
+		t.Errorf("Expected only the temperature alert nearby, got %+v", result.Events)
+	}
+	if len(result.Changes) != 1 {
+		t.Fatalf("Expected only the CPU change, got %+v", result.Changes)
+	}
+	if c := result.Changes[0]; c.Metric != "cpu_percent" || c.Direction != "rose" || c.Peak != 90 || c.OffsetSeconds != -180 || c.Relation != "3 minutes before" {
+		t.Errorf("Unexpected CPU change: %+v", c)
+	}
+	if len(result.Timeline) != 3 || result.Timeline[0].Kind != "metric" || result.Timeline[1].Kind != "anchor" || result.Timeline[2].Kind != history.EventAlert {
+		t.Errorf("Unexpected timeline: %+v", result.Timeline)
+	}
+
+	// A range anchor places what happened inside it "during the range"
+	req.Params.Arguments = map[string]interface{}{
+		"time":   anchor.Add(-5 * time.Minute).Format(time.RFC3339),
+		"until":  anchor.Add(5 * time.Minute).Format(time.RFC3339),
+		"window": "10m",
+	}
+	res, err = h.HandleCorrelateEvents(ctx, req)
+	checkToolResult(t, res, err, []string{"anchor"})
+	result.Events, result.Changes = nil, nil
+	if err := json.Unmarshal([]byte(resultText(res)), &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Events) != 2 || result.Events[0].Relation != "during the range" || len(result.Changes) != 1 || result.Changes[0].OffsetSeconds != 0 {
+		t.Errorf("Unexpected range correlation: %+v %+v", result.Events, result.Changes)
+	}
+
+	for _, args := range []map[string]interface{}{
+		{"category": "disk"},
+		{"time": "yesterday"},
+		{"until": anchor.Format(time.RFC3339)},
+		{"window": "1d"},
+		{"category": "oom_kill"},
+	} {
+		req.Params.Arguments = args
+		if res, err := h.HandleCorrelateEvents(ctx, req); err != nil || !res.IsError {
+			t.Errorf("Expected an error for %v", args)
+		}
+	}
+}
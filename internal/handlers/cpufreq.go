@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// cpuSysPath holds the cpuN directories, each with a cpufreq link to its scaling policy
+var cpuSysPath = "/sys/devices/system/cpu"
+
+// clockTicksPerSecond is USER_HZ, the unit of cpufreq time_in_state
+const clockTicksPerSecond = 100
+
+// coreFreq is the frequency scaling state of one core, in MHz
+type coreFreq struct {
+	CPU      int    `json:"cpu"`
+	CurMHz   int64  `json:"cur_mhz"`
+	MinMHz   int64  `json:"min_mhz"`
+	MaxMHz   int64  `json:"max_mhz"`
+	Governor string `json:"governor,omitempty"`
+}
+
+// freqResidency is the time a policy spent at one frequency since boot
+type freqResidency struct {
+	MHz     int64   `json:"mhz"`
+	Seconds float64 `json:"seconds"`
+	Percent float64 `json:"percent"`
+}
+
+// cpuFreqPolicy is one cpufreq policy: the cores that share a clock and its limits
+type cpuFreqPolicy struct {
+	Policy             string          `json:"policy"`
+	CPUs               []int           `json:"cpus"`
+	Driver             string          `json:"driver,omitempty"`
+	Governor           string          `json:"governor,omitempty"`
+	AvailableGovernors []string        `json:"available_governors,omitempty"`
+	CurMHz             int64           `json:"cur_mhz"`
+	MinMHz             int64           `json:"min_mhz"`
+	MaxMHz             int64           `json:"max_mhz"`
+	HardwareMinMHz     int64           `json:"hardware_min_mhz"`
+	HardwareMaxMHz     int64           `json:"hardware_max_mhz"`
+	Capped             bool            `json:"capped"`
+	Transitions        *int64          `json:"transitions,omitempty"`
+	TimeInState        []freqResidency `json:"time_in_state,omitempty"`
+}
+
+// readKHzAsMHz reads a cpufreq file in kHz and converts it to MHz
+func readKHzAsMHz(path string) int64 {
+	s, err := readTrimmed(path)
+	if err != nil {
+		return 0
+	}
+	khz, _ := strconv.ParseInt(s, 10, 64)
+	return khz / 1000
+}
+
+// cpuFreqDirs maps each CPU number with frequency scaling to its cpufreq directory
+func cpuFreqDirs(root string) (map[int]string, error) {
+	matches, err := filepath.Glob(filepath.Join(root, "cpu[0-9]*", "cpufreq"))
+	if err != nil {
+		return nil, err
+	}
+	dirs := map[int]string{}
+	for _, dir := range matches {
+		n, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(filepath.Dir(dir)), "cpu"))
+		if err != nil {
+			continue
+		}
+		dirs[n] = dir
+	}
+	if len(dirs) == 0 {
+		return nil, fmt.Errorf("no cpufreq interface under %s", root)
+	}
+	return dirs, nil
+}
+
+// readCoreFreqs returns the current frequency, limits, and governor of every core
+func readCoreFreqs(root string) ([]coreFreq, error) {
+	dirs, err := cpuFreqDirs(root)
+	if err != nil {
+		return nil, err
+	}
+	cores := make([]coreFreq, 0, len(dirs))
+	for n, dir := range dirs {
+		c := coreFreq{
+			CPU:    n,
+			CurMHz: readKHzAsMHz(filepath.Join(dir, "scaling_cur_freq")),
+			MinMHz: readKHzAsMHz(filepath.Join(dir, "scaling_min_freq")),
+			MaxMHz: readKHzAsMHz(filepath.Join(dir, "scaling_max_freq")),
+		}
+		c.Governor, _ = readTrimmed(filepath.Join(dir, "scaling_governor"))
+		cores = append(cores, c)
+	}
+	sort.Slice(cores, func(i, j int) bool { return cores[i].CPU < cores[j].CPU })
+	return cores, nil
+}
+
+// readCPUFreqPolicies groups cores by the policy they share and reads each policy once,
+// including its time-in-state residency when the kernel keeps cpufreq statistics
+func readCPUFreqPolicies(root string) ([]cpuFreqPolicy, error) {
+	dirs, err := cpuFreqDirs(root)
+	if err != nil {
+		return nil, err
+	}
+	byDir := map[string]*cpuFreqPolicy{}
+	for n, dir := range dirs {
+		// cpuN/cpufreq is a symlink to the shared policyN directory
+		resolved, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			resolved = dir
+		}
+		if p, ok := byDir[resolved]; ok {
+			p.CPUs = append(p.CPUs, n)
+			continue
+		}
+		p := &cpuFreqPolicy{Policy: filepath.Base(resolved), CPUs: []int{n}}
+		if p.Policy == "cpufreq" {
+			p.Policy = fmt.Sprintf("cpu%d", n)
+		}
+		p.Driver, _ = readTrimmed(filepath.Join(resolved, "scaling_driver"))
+		p.Governor, _ = readTrimmed(filepath.Join(resolved, "scaling_governor"))
+		if s, err := readTrimmed(filepath.Join(resolved, "scaling_available_governors")); err == nil {
+			p.AvailableGovernors = strings.Fields(s)
+		}
+		p.CurMHz = readKHzAsMHz(filepath.Join(resolved, "scaling_cur_freq"))
+		p.MinMHz = readKHzAsMHz(filepath.Join(resolved, "scaling_min_freq"))
+		p.MaxMHz = readKHzAsMHz(filepath.Join(resolved, "scaling_max_freq"))
+		p.HardwareMinMHz = readKHzAsMHz(filepath.Join(resolved, "cpuinfo_min_freq"))
+		p.HardwareMaxMHz = readKHzAsMHz(filepath.Join(resolved, "cpuinfo_max_freq"))
+		p.Capped = p.HardwareMaxMHz > 0 && p.MaxMHz > 0 && p.MaxMHz < p.HardwareMaxMHz
+		if s, err := readTrimmed(filepath.Join(resolved, "stats", "total_trans")); err == nil {
+			if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+				p.Transitions = &n
+			}
+		}
+		if data, err := os.ReadFile(filepath.Join(resolved, "stats", "time_in_state")); err == nil {
+			p.TimeInState = parseTimeInState(string(data))
+		}
+		byDir[resolved] = p
+	}
+
+	policies := make([]cpuFreqPolicy, 0, len(byDir))
+	for _, p := range byDir {
+		sort.Ints(p.CPUs)
+		policies = append(policies, *p)
+	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].CPUs[0] < policies[j].CPUs[0] })
+	return policies, nil
+}
+
+// parseTimeInState parses cpufreq stats/time_in_state lines of "<kHz> <ticks>", dropping
+// frequencies never used
+func parseTimeInState(data string) []freqResidency {
+	var states []freqResidency
+	var total float64
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		khz, err1 := strconv.ParseInt(fields[0], 10, 64)
+		ticks, err2 := strconv.ParseFloat(fields[1], 64)
+		if err1 != nil || err2 != nil || ticks == 0 {
+			continue
+		}
+		states = append(states, freqResidency{MHz: khz / 1000, Seconds: ticks / clockTicksPerSecond})
+		total += ticks / clockTicksPerSecond
+	}
+	for i := range states {
+		states[i].Percent = round2(100 * states[i].Seconds / total)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].MHz > states[j].MHz })
+	return states
+}
+
+// HandleGetCPUFreq returns the cpufreq policies with their governors, frequency limits,
+// and time-in-state residency
+func (h *HandlerManager) HandleGetCPUFreq(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	policies, err := readCPUFreqPolicies(cpuSysPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read CPU frequency scaling: %v", err)), nil
+	}
+	cores, err := readCoreFreqs(cpuSysPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read CPU frequency scaling: %v", err)), nil
+	}
+
+	warnings := []string{}
+	for _, p := range policies {
+		if p.Capped {
+			warnings = append(warnings, fmt.Sprintf("Policy %s is capped at %d MHz below its %d MHz hardware maximum", p.Policy, p.MaxMHz, p.HardwareMaxMHz))
+		}
+	}
+
+	jsonBytes, err := json.Marshal(map[string]interface{}{
+		"policies": policies,
+		"cores":    cores,
+		"warnings": warnings,
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestParseTimeInState(t *testing.T) {
+	states := parseTimeInState("600000 3000\n1000000 0\n1500000 1000\n")
+	if len(states) != 2 || states[0].MHz != 1500 || states[0].Seconds != 10 || states[0].Percent != 25 || states[1].Percent != 75 {
+		t.Errorf("Unexpected residency: %+v", states)
+	}
+}
+
+func TestHandleGetCPUFreq(t *testing.T) {
+	root := t.TempDir()
+	orig := cpuSysPath
+	defer func() { cpuSysPath = orig }()
+	cpuSysPath = root
+
+	// A big.LITTLE layout: cpu0 and cpu1 share policy0, cpu2 has its own capped policy
+	policies := map[string]map[string]string{
+		"policy0": {
+			"scaling_cur_freq": "600000", "scaling_min_freq": "600000", "scaling_max_freq": "1800000",
+			"cpuinfo_min_freq": "600000", "cpuinfo_max_freq": "1800000", "scaling_governor": "ondemand",
+			"scaling_driver": "cpufreq-dt", "scaling_available_governors": "conservative ondemand userspace powersave performance schedutil",
+			"stats/total_trans": "42", "stats/time_in_state": "600000 9000\n1800000 1000\n",
+		},
+		"policy2": {
+			"scaling_cur_freq": "2000000", "scaling_min_freq": "500000", "scaling_max_freq": "2000000",
+			"cpuinfo_min_freq": "500000", "cpuinfo_max_freq": "2400000", "scaling_governor": "performance",
+		},
+	}
+	for policy, files := range policies {
+		for name, value := range files {
+			path := filepath.Join(root, "cpufreq", policy, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(value+"\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	for cpu, policy := range map[string]string{"cpu0": "policy0", "cpu1": "policy0", "cpu2": "policy2"} {
+		if err := os.MkdirAll(filepath.Join(root, cpu), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(filepath.Join("..", "cpufreq", policy), filepath.Join(root, cpu, "cpufreq")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h := NewHandlerManager(&config.Config{})
+	res, err := h.HandleGetCPUFreq(context.Background(), mcp.CallToolRequest{})
+	checkToolResult(t, res, err, []string{"policies", "cores", "warnings"})
+	var result struct {
+		Policies []cpuFreqPolicy `json:"policies"`
+		Cores    []coreFreq      `json:"cores"`
+		Warnings []string        `json:"warnings"`
+	}
+	if err := json.Unmarshal([]byte(resultText(res)), &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Policies) != 2 || result.Policies[0].Policy != "policy0" || len(result.Policies[0].CPUs) != 2 {
+		t.Fatalf("Expected two policies, got %+v", result.Policies)
+	}
+	little, big := result.Policies[0], result.Policies[1]
+	if little.Capped || little.Transitions == nil || *little.Transitions != 42 || len(little.TimeInState) != 2 || little.TimeInState[1].Percent != 90 || len(little.AvailableGovernors) != 6 {
+		t.Errorf("Unexpected little policy: %+v", little)
+	}
+	if !big.Capped || big.HardwareMaxMHz != 2400 || big.TimeInState != nil {
+		t.Errorf("Unexpected big policy: %+v", big)
+	}
+	if len(result.Cores) != 3 || result.Cores[1].Governor != "ondemand" || result.Cores[2].CurMHz != 2000 {
+		t.Errorf("Unexpected cores: %+v", result.Cores)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "policy2") {
+		t.Errorf("Expected a cap warning for policy2, got %v", result.Warnings)
+	}
+
+	res, err = h.HandleGetCPUMetrics(context.Background(), mcp.CallToolRequest{})
+	checkToolResult(t, res, err, []string{"frequency"})
+
+	cpuSysPath = filepath.Join(root, "missing")
+	if res, err := h.HandleGetCPUFreq(context.Background(), mcp.CallToolRequest{}); err != nil || !res.IsError {
+		t.Error("Expected an error without cpufreq")
+	}
+}
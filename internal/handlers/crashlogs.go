@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// pstore record types, from the file name prefix ("dmesg-ramoops-0", "console-efi-...")
+const (
+	pstoreTypeDmesg   = "dmesg"
+	pstoreTypeConsole = "console"
+	pstoreTypePmsg    = "pmsg"
+	pstoreTypeFtrace  = "ftrace"
+	pstoreTypeMCE     = "mce"
+)
+
+var pstoreAllTypes = []string{pstoreTypeDmesg, pstoreTypeConsole, pstoreTypePmsg, pstoreTypeFtrace, pstoreTypeMCE}
+
+// pstoreBackendParam names the active pstore backend (ramoops, efi_pstore, erst, ...)
+const pstoreBackendParam = "/sys/module/pstore/parameters/backend"
+
+// Crash log limits
+const (
+	defaultCrashLogLimit = 5
+	maxCrashLogLimit     = 50
+	defaultCrashLogLines = 50
+	maxCrashLogLines     = 500
+	maxCrashRecordBytes  = 1 << 20
+)
+
+// pstoreHeaderRe matches the header the kernel writes at the top of a dmesg record
+var pstoreHeaderRe = regexp.MustCompile(`^(Panic|Oops|Emergency|Restart|Halt|Poweroff|Unknown)#(\d+) Part(\d+)`)
+
+// crashMarkers are kernel messages that identify why a crash record was written
+var crashMarkers = []string{
+	"Kernel panic - not syncing", "Unable to handle kernel", "BUG: ", "Oops", "general protection fault",
+	"watchdog: BUG: soft lockup", "hard LOCKUP", "blocked for more than", "Out of memory", "Internal error:",
+}
+
+// dmesgPrefixRe strips the "<0>[  123.456789] " prefix from kernel log lines
+var dmesgPrefixRe = regexp.MustCompile(`^(<\d+>)?\[\s*\d+\.\d+\]\s*`)
+
+// pstoreFile is one record file found in a pstore directory
+type pstoreFile struct {
+	path    string
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+// crashRecord is one pstore record as reported by get_crash_logs
+type crashRecord struct {
+	Path       string   `json:"path"`
+	Source     string   `json:"source"`
+	Type       string   `json:"type"`
+	Backend    string   `json:"backend,omitempty"`
+	Time       string   `json:"time"`
+	SizeBytes  int64    `json:"size_bytes"`
+	Reason     string   `json:"reason,omitempty"`
+	Part       string   `json:"part,omitempty"`
+	Summary    string   `json:"summary,omitempty"`
+	Compressed bool     `json:"compressed,omitempty"`
+	Lines      []string `json:"lines,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// HandleGetCrashLogs returns kernel panic and oops logs saved by pstore during previous boots
+func (h *HandlerManager) HandleGetCrashLogs(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	limit := defaultCrashLogLimit
+	lines := defaultCrashLogLines
+	types := []string{pstoreTypeDmesg}
+
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if l, ok := args["limit"].(float64); ok && l > 0 {
+			limit = int(l)
+		}
+		if l, ok := args["lines"].(float64); ok && l >= 0 {
+			lines = int(l)
+		}
+		if all, ok := args["include_all"].(bool); ok && all {
+			types = pstoreAllTypes
+		}
+	}
+	if limit > maxCrashLogLimit {
+		limit = maxCrashLogLimit
+	}
+	if lines > maxCrashLogLines {
+		lines = maxCrashLogLines
+	}
+
+	records := []crashRecord{}
+	var denied []string
+	for i, dir := range pstoreDirs {
+		source := "live"
+		if i > 0 {
+			source = "archive"
+		}
+		if _, err := os.ReadDir(dir); errors.Is(err, fs.ErrPermission) {
+			denied = append(denied, dir)
+			continue
+		}
+		for _, f := range pstoreFiles(dir, types) {
+			records = append(records, readCrashRecord(f, source, lines))
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Time > records[j].Time })
+	total := len(records)
+	if len(records) > limit {
+		records = records[:limit]
+	}
+
+	result := map[string]interface{}{
+		"total_records": total,
+		"records":       records,
+		"directories":   pstoreDirs,
+	}
+	if data, err := os.ReadFile(pstoreBackendParam); err == nil {
+		if backend := strings.TrimSpace(string(data)); backend != "" && backend != "(null)" {
+			result["backend"] = backend
+		}
+	}
+	if len(denied) > 0 {
+		result["permission_denied"] = denied
+		result["note"] = "pstore is readable by root only; run the server as root to read crash records"
+	} else if total == 0 {
+		if _, ok := result["backend"]; ok {
+			result["note"] = "No crash records saved; the previous boots did not panic or oops, or the records were already cleared"
+		} else {
+			result["note"] = "No crash records and no pstore backend; enable ramoops (e.g. dtoverlay=ramoops on a Raspberry Pi) or EFI pstore to keep panic logs across reboots"
+		}
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// pstoreFiles lists record files of the given types under dir, newest first. Records in
+// subdirectories (the systemd-pstore archive groups them per boot) are included.
+func pstoreFiles(dir string, types []string) []pstoreFile {
+	var files []pstoreFile
+	_ = filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if !contains(types, pstoreRecordType(d.Name())) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		files = append(files, pstoreFile{path: path, name: d.Name(), size: info.Size(), modTime: info.ModTime()})
+		return nil
+	})
+
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.After(files[j].modTime) })
+	return files
+}
+
+// pstoreRecordType returns the record type from a file name such as "dmesg-efi-1700000000001"
+func pstoreRecordType(name string) string {
+	t, _, _ := strings.Cut(name, "-")
+	return t
+}
+
+// pstoreBackend returns the backend from a file name such as "dmesg-ramoops-0"
+func pstoreBackend(name string) string {
+	parts := strings.SplitN(name, "-", 3)
+	if len(parts) < 2 {
+		return ""
+	}
+	return strings.TrimSuffix(parts[1], ".enc.z")
+}
+
+// readCrashRecord reads a record file and extracts its header, the line explaining the
+// crash, and the last n lines
+func readCrashRecord(f pstoreFile, source string, n int) crashRecord {
+	rec := crashRecord{
+		Path:       f.path,
+		Source:     source,
+		Type:       pstoreRecordType(f.name),
+		Backend:    pstoreBackend(f.name),
+		Time:       f.modTime.UTC().Format(time.RFC3339),
+		SizeBytes:  f.size,
+		Compressed: strings.HasSuffix(f.name, ".enc.z"),
+	}
+	if rec.Compressed {
+		// The kernel could not decompress the record; its content is not readable text
+		return rec
+	}
+
+	file, err := os.Open(filepath.Clean(f.path))
+	if err != nil {
+		rec.Error = err.Error()
+		return rec
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, maxCrashRecordBytes))
+	if err != nil {
+		rec.Error = err.Error()
+		return rec
+	}
+
+	all := strings.Split(strings.TrimRight(strings.ToValidUTF8(string(data), ""), "\n"), "\n")
+	body := all
+	if m := pstoreHeaderRe.FindStringSubmatch(all[0]); m != nil {
+		rec.Reason = strings.ToLower(m[1])
+		rec.Part = m[3]
+		body = all[1:]
+	}
+	rec.Summary = crashSummary(body)
+	if n > 0 {
+		if len(all) > n {
+			all = all[len(all)-n:]
+		}
+		rec.Lines = all
+	}
+	return rec
+}
+
+// crashSummary returns the first kernel message naming the cause of a crash
+func crashSummary(lines []string) string {
+	for _, line := range lines {
+		if containsAny(line, crashMarkers) {
+			return strings.TrimSpace(dmesgPrefixRe.ReplaceAllString(line, ""))
+		}
+	}
+	return ""
+}
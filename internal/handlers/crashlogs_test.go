@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const testPanicRecord = `Panic#1 Part1
+<6>[ 1021.440112] usb 1-1.3: USB disconnect, device number 4
+<0>[ 1022.118750] Kernel panic - not syncing: Fatal exception in interrupt
+<0>[ 1022.118790] CPU: 2 PID: 0 Comm: swapper/2 Tainted: G      D
+<0>[ 1022.118801] ---[ end Kernel panic - not syncing: Fatal exception in interrupt ]---
+`
+
+func TestHandleGetCrashLogs(t *testing.T) {
+	live, archive := t.TempDir(), t.TempDir()
+	orig := pstoreDirs
+	pstoreDirs = []string{live, archive}
+	t.Cleanup(func() { pstoreDirs = orig })
+
+	files := map[string]string{
+		filepath.Join(live, "dmesg-ramoops-0"):                        testPanicRecord,
+		filepath.Join(live, "console-ramoops-0"):                      "console output\n",
+		filepath.Join(archive, "20240101000000", "dmesg-efi-1700001"): "Oops#1 Part1\n<4>[ 5.0] Unable to handle kernel NULL pointer dereference\n",
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(filepath.Join(archive, "20240101000000", "dmesg-efi-1700001"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewHandlerManager(&config.Config{})
+	req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]interface{}{"lines": 2.0}}}
+	res, err := h.HandleGetCrashLogs(context.Background(), req)
+	checkToolResult(t, res, err, []string{"total_records", "records", "directories"})
+
+	var result struct {
+		Total   int           `json:"total_records"`
+		Records []crashRecord `json:"records"`
+	}
+	if err := json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &result); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+	if result.Total != 2 || len(result.Records) != 2 {
+		t.Fatalf("Expected the two dmesg records, got %+v", result.Records)
+	}
+
+	panicRec := result.Records[0]
+	if panicRec.Source != "live" || panicRec.Backend != "ramoops" || panicRec.Reason != "panic" || panicRec.Part != "1" {
+		t.Errorf("Unexpected live record: %+v", panicRec)
+	}
+	if panicRec.Summary != "Kernel panic - not syncing: Fatal exception in interrupt" {
+		t.Errorf("Unexpected summary: %q", panicRec.Summary)
+	}
+	if len(panicRec.Lines) != 2 || !strings.Contains(panicRec.Lines[1], "end Kernel panic") {
+		t.Errorf("Expected the last 2 lines, got %q", panicRec.Lines)
+	}
+
+	oopsRec := result.Records[1]
+	if oopsRec.Source != "archive" || oopsRec.Backend != "efi" || oopsRec.Reason != "oops" ||
+		!strings.HasPrefix(oopsRec.Summary, "Unable to handle kernel") {
+		t.Errorf("Unexpected archived record: %+v", oopsRec)
+	}
+
+	req.Params.Arguments = map[string]interface{}{"include_all": true, "limit": 1.0}
+	res, err = h.HandleGetCrashLogs(context.Background(), req)
+	checkToolResult(t, res, err, []string{"total_records", "records"})
+	if err := json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &result); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+	if result.Total != 3 || len(result.Records) != 1 {
+		t.Errorf("Expected 3 records with include_all limited to 1, got %d/%d", result.Total, len(result.Records))
+	}
+}
+
+func TestPstoreFileNames(t *testing.T) {
+	tests := []struct {
+		name, recordType, backend string
+	}{
+		{"dmesg-ramoops-0", "dmesg", "ramoops"},
+		{"dmesg-efi-170000000001001", "dmesg", "efi"},
+		{"dmesg-erst-6543210.enc.z", "dmesg", "erst"},
+		{"pmsg-ramoops-0", "pmsg", "ramoops"},
+	}
+	for _, tt := range tests {
+		if got := pstoreRecordType(tt.name); got != tt.recordType {
+			t.Errorf("pstoreRecordType(%s) = %s, expected %s", tt.name, got, tt.recordType)
+		}
+		if got := pstoreBackend(tt.name); got != tt.backend {
+			t.Errorf("pstoreBackend(%s) = %s, expected %s", tt.name, got, tt.backend)
+		}
+	}
+}
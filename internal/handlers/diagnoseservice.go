@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// diagnoseServiceTimeout bounds the systemctl and journalctl calls for one unit
+const diagnoseServiceTimeout = 10 * time.Second
+
+// Journal lines returned by diagnose_service
+const (
+	defaultDiagnoseLines = 50
+	maxDiagnoseLines     = 500
+)
+
+// journalMessageLimit caps each journal message, so one stack trace cannot fill the response
+const journalMessageLimit = 500
+
+// journalErrPriority is syslog's "err"; lower values are more severe
+const journalErrPriority = 3
+
+// systemdUnitRe is a unit name that cannot be mistaken for a command-line option
+var systemdUnitRe = regexp.MustCompile(`^[A-Za-z0-9@_:.\\][A-Za-z0-9@_:.\\-]*$`)
+
+// diagnoseProperties are the systemctl show properties diagnose_service reports
+var diagnoseProperties = []string{
+	"Id", "Description", "LoadState", "ActiveState", "SubState", "UnitFileState", "Result",
+	"MainPID", "NRestarts", "StateChangeTimestamp", "ActiveEnterTimestamp",
+	"ExecMainStatus", "ExecMainCode", "MemoryCurrent", "CPUUsageNSec", "TasksCurrent",
+	"IOReadBytes", "IOWriteBytes", "FragmentPath",
+}
+
+// journalEntry is one journal line of a unit
+type journalEntry struct {
+	Time     string `json:"time,omitempty"`
+	Priority int    `json:"priority"`
+	Message  string `json:"message"`
+}
+
+// HandleDiagnoseService returns a systemd unit's status, last state change, restart count,
+// cgroup resource usage, and recent journal lines in one response
+func (h *HandlerManager) HandleDiagnoseService(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var service string
+	lines := defaultDiagnoseLines
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if s, ok := args["service"].(string); ok {
+			service = strings.TrimSpace(s)
+		}
+		if l, ok := args["lines"].(float64); ok && l >= 0 {
+			lines = min(int(l), maxDiagnoseLines)
+		}
+	}
+
+	if service == "" {
+		return mcp.NewToolResultError("service is required"), nil
+	}
+	if !systemdUnitRe.MatchString(service) {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid unit name %q", service)), nil
+	}
+	unit := serviceUnitName(service)
+
+	ctx, cancel := context.WithTimeout(ctx, diagnoseServiceTimeout)
+	defer cancel()
+
+	//nolint:gosec // G204: unit is validated against systemdUnitRe and cannot start with a dash
+	out, err := exec.CommandContext(ctx, "systemctl", "show", unit,
+		"--property="+strings.Join(diagnoseProperties, ","), "--no-pager").Output()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to query %s: %v", unit, err)), nil
+	}
+	props := parseSystemctlShow(string(out))
+	if props["LoadState"] == "not-found" {
+		return mcp.NewToolResultError(fmt.Sprintf("Unit %s not found", unit)), nil
+	}
+
+	result, warnings := h.serviceDiagnosis(props, time.Now())
+	result["service"] = unit
+
+	if lines > 0 {
+		logs := map[string]interface{}{"requested": lines}
+		entries, errorCount, err := h.unitJournal(ctx, unit, lines, false)
+		switch {
+		case err != nil:
+			logs["available"] = false
+			logs["error"] = fmt.Sprintf("journalctl failed: %v", err)
+			warnings = append(warnings, "Could not read the journal; run the server as root or in the systemd-journal group")
+		case len(entries) == 0 && result["last_active_enter"] != nil:
+			// A unit that has run but has no visible lines usually means the journal is
+			// limited to the server user's own messages
+			logs["available"] = true
+			warnings = append(warnings, "No journal lines are visible for this unit; the server user may need to be in the systemd-journal group")
+		default:
+			logs["available"] = true
+		}
+		logs["entries"] = entries
+		logs["error_count"] = errorCount
+		if errorCount > 0 {
+			warnings = append(warnings, fmt.Sprintf("%d of the last %d journal lines are at error priority or worse", errorCount, len(entries)))
+		}
+		result["logs"] = logs
+	}
+	result["warnings"] = warnings
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// unitJournal returns a unit's last lines journal entries and how many are at error
+// priority or worse. With user set, unit is one of the server user's user units.
+func (h *HandlerManager) unitJournal(ctx context.Context, unit string, lines int, user bool) ([]journalEntry, int, error) {
+	var out []byte
+	var err error
+	if user {
+		out, err = exec.CommandContext(ctx, "journalctl", "--user-unit="+unit, "-n", strconv.Itoa(lines),
+			"--no-pager", "-o", "json").Output()
+	} else {
+		out, err = h.privilegedCommand(ctx, "journalctl", "--unit="+unit, "-n", strconv.Itoa(lines),
+			"--no-pager", "-o", "json").Output()
+	}
+	entries := parseJournalEntries(out)
+	errorCount := 0
+	for _, e := range entries {
+		if e.Priority <= journalErrPriority {
+			errorCount++
+		}
+	}
+	return entries, errorCount, err
+}
+
+// serviceDiagnosis turns systemctl show properties into the status, state change,
+// restart, and resource fields, with warnings for failed and restarting units
+func (h *HandlerManager) serviceDiagnosis(props map[string]string, now time.Time) (map[string]interface{}, []string) {
+	result := map[string]interface{}{
+		"description":  props["Description"],
+		"load_state":   props["LoadState"],
+		"active_state": props["ActiveState"],
+		"sub_state":    props["SubState"],
+	}
+	if v := props["UnitFileState"]; v != "" {
+		result["unit_file_state"] = v
+	}
+	if v := props["FragmentPath"]; v != "" {
+		result["unit_path"] = v
+	}
+	if v := props["Result"]; v != "" {
+		result["result"] = v
+	}
+	if pid, err := strconv.Atoi(props["MainPID"]); err == nil && pid > 0 {
+		result["main_pid"] = pid
+	}
+
+	if t, ok := parseSystemdTimestamp(props["StateChangeTimestamp"]); ok {
+		result["last_state_change"] = t.UTC().Format(time.RFC3339)
+		if age := now.Sub(t); age >= 0 {
+			age = age.Truncate(time.Second)
+			result["state_age_seconds"] = int64(age / time.Second)
+			result["state_age_human"] = h.human.Duration(age)
+		}
+	}
+	if t, ok := parseSystemdTimestamp(props["ActiveEnterTimestamp"]); ok {
+		result["last_active_enter"] = t.UTC().Format(time.RFC3339)
+	}
+
+	restarts, _ := strconv.Atoi(props["NRestarts"])
+	result["restarts"] = restarts
+	// ExecMainCode is the main process's CLD_* code: 1 exited with ExecMainStatus,
+	// 2 and 3 were killed by that signal
+	exitDetail := ""
+	if s, err := strconv.Atoi(props["ExecMainStatus"]); err == nil {
+		switch props["ExecMainCode"] {
+		case "1":
+			result["last_exit_status"] = s
+			exitDetail = fmt.Sprintf(", exit status %d", s)
+		case "2", "3":
+			result["last_exit_signal"] = s
+			exitDetail = fmt.Sprintf(", killed by signal %d", s)
+		}
+	}
+
+	resources := map[string]interface{}{}
+	if v, ok := parseSystemdUint(props["MemoryCurrent"]); ok {
+		resources["memory_bytes"] = v
+		resources["memory_human"] = h.human.Bytes(v)
+	}
+	if v, ok := parseSystemdUint(props["CPUUsageNSec"]); ok {
+		resources["cpu_seconds"] = round2(float64(v) / 1e9)
+	}
+	if v, ok := parseSystemdUint(props["TasksCurrent"]); ok {
+		resources["tasks"] = v
+	}
+	if v, ok := parseSystemdUint(props["IOReadBytes"]); ok {
+		resources["io_read_bytes"] = v
+	}
+	if v, ok := parseSystemdUint(props["IOWriteBytes"]); ok {
+		resources["io_write_bytes"] = v
+	}
+	result["resources"] = resources
+
+	warnings := []string{}
+	switch {
+	case props["ActiveState"] == "failed":
+		warnings = append(warnings, fmt.Sprintf("Unit has failed (result: %s%s)", props["Result"], exitDetail))
+	case props["SubState"] == "auto-restart":
+		warnings = append(warnings, "Unit is waiting to be restarted after exiting; it may be crash looping")
+	}
+	if props["Result"] == "oom-kill" {
+		warnings = append(warnings, "The kernel OOM killer stopped this unit; raise its MemoryMax or find the memory growth")
+	}
+	if restarts > 0 {
+		warnings = append(warnings, fmt.Sprintf("systemd has restarted this unit %d times since it was last started by hand", restarts))
+	}
+	return result, warnings
+}
+
+// parseSystemctlShow parses systemctl show's Key=Value lines
+func parseSystemctlShow(out string) map[string]string {
+	props := map[string]string{}
+	for _, line := range strings.Split(out, "\n") {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		props[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return props
+}
+
+// parseSystemdUint parses a systemctl numeric property; "[not set]" and the all-ones
+// sentinel that older systemd versions print mean the value is unavailable
+func parseSystemdUint(s string) (uint64, bool) {
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil || v == ^uint64(0) {
+		return 0, false
+	}
+	return v, true
+}
+
+// parseSystemdTimestamp parses a timestamp as systemctl show prints it, such as
+// "Thu 2024-05-02 12:00:00 UTC"; empty and "n/a" mean the event never happened
+func parseSystemdTimestamp(s string) (time.Time, bool) {
+	if s == "" || s == "n/a" {
+		return time.Time{}, false
+	}
+	// The zone is an abbreviation, which time.Parse only resolves for the local zone and UTC
+	t, err := time.ParseInLocation("Mon 2006-01-02 15:04:05 MST", s, time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// parseJournalEntries parses journalctl -o json output. MESSAGE is a byte array instead
+// of a string when it is not valid UTF-8.
+func parseJournalEntries(out []byte) []journalEntry {
+	entries := []journalEntry{}
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var raw struct {
+			Realtime string          `json:"__REALTIME_TIMESTAMP"`
+			Priority string          `json:"PRIORITY"`
+			Message  json.RawMessage `json:"MESSAGE"`
+		}
+		if err := json.Unmarshal([]byte(line), &raw); err != nil {
+			continue
+		}
+		e := journalEntry{Priority: 6}
+		if p, err := strconv.Atoi(raw.Priority); err == nil {
+			e.Priority = p
+		}
+		if us, err := strconv.ParseInt(raw.Realtime, 10, 64); err == nil {
+			e.Time = time.UnixMicro(us).UTC().Format(time.RFC3339)
+		}
+		var msg string
+		if err := json.Unmarshal(raw.Message, &msg); err != nil {
+			var b []byte
+			var ints []int
+			if json.Unmarshal(raw.Message, &ints) == nil {
+				for _, i := range ints {
+					b = append(b, byte(i))
+				}
+			}
+			msg = strings.ToValidUTF8(string(b), "?")
+		}
+		e.Message = truncateString(msg, journalMessageLimit)
+		entries = append(entries, e)
+	}
+	return entries
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestServiceDiagnosis(t *testing.T) {
+	h := NewHandlerManager(&config.Config{})
+	show := "Id=nginx.service\nDescription=A high performance web server\nLoadState=loaded\nActiveState=failed\nSubState=failed\n" +
+		"UnitFileState=enabled\nResult=oom-kill\nMainPID=0\nNRestarts=4\nStateChangeTimestamp=Thu 2024-05-02 12:00:00 UTC\n" +
+		"ActiveEnterTimestamp=Thu 2024-05-02 11:00:00 UTC\nExecMainStatus=9\nExecMainCode=2\nMemoryCurrent=[not set]\n" +
+		"CPUUsageNSec=1500000000\nTasksCurrent=18446744073709551615\nIOReadBytes=4096\n"
+	props := parseSystemctlShow(show)
+	now := time.Date(2024, 5, 2, 13, 0, 0, 0, time.UTC)
+	result, warnings := h.serviceDiagnosis(props, now)
+
+	if result["active_state"] != "failed" || result["restarts"] != 4 || result["last_exit_signal"] != 9 || result["main_pid"] != nil {
+		t.Errorf("Unexpected status: %v", result)
+	}
+	if result["last_state_change"] != "2024-05-02T12:00:00Z" || result["state_age_seconds"] != int64(3600) {
+		t.Errorf("Unexpected state change: %v", result)
+	}
+	resources := result["resources"].(map[string]interface{})
+	if resources["cpu_seconds"] != 1.5 || resources["io_read_bytes"] != uint64(4096) || resources["memory_bytes"] != nil || resources["tasks"] != nil {
+		t.Errorf("Unexpected resources: %v", resources)
+	}
+	joined := strings.Join(warnings, "\n")
+	for _, want := range []string{"killed by signal 9", "OOM killer", "restarted this unit 4 times"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("Expected a warning containing %q, got %v", want, warnings)
+		}
+	}
+}
+
+func TestParseJournalEntries(t *testing.T) {
+	out := `{"__REALTIME_TIMESTAMP":"1714651200000000","PRIORITY":"3","MESSAGE":"bind() to 0.0.0.0:80 failed"}
+{"__REALTIME_TIMESTAMP":"1714651201000000","PRIORITY":"6","MESSAGE":[104,105,255]}
+not json
+{"__REALTIME_TIMESTAMP":"1714651202000000","MESSAGE":null}
+`
+	entries := parseJournalEntries([]byte(out))
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %+v", entries)
+	}
+	if e := entries[0]; e.Priority != 3 || e.Time != "2024-05-02T12:00:00Z" || e.Message != "bind() to 0.0.0.0:80 failed" {
+		t.Errorf("Unexpected entry: %+v", e)
+	}
+	if e := entries[1]; e.Message != "hi?" {
+		t.Errorf("Expected a byte array message to be decoded, got %q", e.Message)
+	}
+	if e := entries[2]; e.Priority != 6 || e.Message != "" {
+		t.Errorf("Unexpected entry without priority: %+v", e)
+	}
+}
+
+func TestHandleDiagnoseServiceValidation(t *testing.T) {
+	h := NewHandlerManager(&config.Config{})
+	for _, service := range []string{"", "--help", "nginx; reboot", "../etc"} {
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]interface{}{"service": service}
+		res, err := h.HandleDiagnoseService(context.Background(), req)
+		if err != nil || !res.IsError {
+			t.Errorf("Expected %q to be rejected", service)
+		}
+	}
+}
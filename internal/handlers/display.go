@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// DRM sysfs and debugfs roots
+const (
+	drmRoot      = "/sys/class/drm"
+	driDebugRoot = "/sys/kernel/debug/dri"
+)
+
+// drmConnectorRe matches connector directories such as "card1-HDMI-A-1"
+var drmConnectorRe = regexp.MustCompile(`^(card\d+)-(.+)$`)
+
+// drmStateModeRe matches the active mode line of a CRTC in the debugfs atomic state:
+// `mode: "1920x1080": 60 148500 1920 2008 2052 2200 1080 1084 1089 1125 0x48 0x5`
+var drmStateModeRe = regexp.MustCompile(`mode:\s*"([^"]+)":\s*(\d+)`)
+
+// displayPowerRe matches `vcgencmd display_power` output, e.g. "display_power=1"
+var displayPowerRe = regexp.MustCompile(`display_power=(-?\d+)`)
+
+// displayConnector is a single DRM output connector such as HDMI-A-1 or DSI-1
+type displayConnector struct {
+	Card          string `json:"card"`
+	Name          string `json:"name"`
+	Status        string `json:"status"`
+	Enabled       bool   `json:"enabled"`
+	DPMS          string `json:"dpms,omitempty"`
+	Monitor       string `json:"monitor,omitempty"`
+	PreferredMode string `json:"preferred_mode,omitempty"`
+	ModeCount     int    `json:"mode_count"`
+	CurrentMode   string `json:"current_mode,omitempty"`
+	RefreshHz     int    `json:"refresh_hz,omitempty"`
+}
+
+// activeMode is the mode a CRTC is currently scanning out to a connector
+type activeMode struct {
+	Mode      string
+	RefreshHz int
+}
+
+// HandleGetDisplayStatus returns connected displays with their current mode and power state
+func (h *HandlerManager) HandleGetDisplayStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	result := map[string]interface{}{}
+
+	drm := map[string]interface{}{
+		"available": false,
+	}
+	if connectors, err := readDRMConnectors(drmRoot); err == nil {
+		// The active mode is only exposed through debugfs, which is readable by root
+		modes, modeErr := readDRMActiveModes(driDebugRoot)
+		connected := 0
+		for i := range connectors {
+			c := &connectors[i]
+			if c.Status == "connected" {
+				connected++
+			}
+			if m, ok := modes[c.Card+"/"+c.Name]; ok {
+				c.CurrentMode = m.Mode
+				c.RefreshHz = m.RefreshHz
+			}
+		}
+		drm["available"] = true
+		drm["connectors"] = connectors
+		drm["connected_count"] = connected
+		if modeErr != nil {
+			drm["current_mode_error"] = fmt.Sprintf("active modes unavailable (requires root to read %s): %v", driDebugRoot, modeErr)
+		}
+	} else {
+		drm["error"] = fmt.Sprintf("DRM not available: %v", err)
+	}
+	result["drm"] = drm
+
+	if fb, ok := readFramebufferSize("/sys/class/graphics/fb0/virtual_size"); ok {
+		result["framebuffer"] = fb
+	}
+
+	if h.caps.Vcgencmd {
+		result["display_power"] = readDisplayPower(ctx)
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// readDRMConnectors lists the output connectors under a DRM sysfs root
+func readDRMConnectors(root string) ([]displayConnector, error) {
+	entries, err := os.ReadDir(filepath.Clean(root))
+	if err != nil {
+		return nil, err
+	}
+
+	connectors := []displayConnector{}
+	for _, e := range entries {
+		m := drmConnectorRe.FindStringSubmatch(e.Name())
+		if m == nil {
+			continue
+		}
+		dir := filepath.Join(root, e.Name())
+		status, err := readTrimmed(filepath.Join(dir, "status"))
+		if err != nil {
+			continue
+		}
+		c := displayConnector{Card: m[1], Name: m[2], Status: status}
+		if enabled, err := readTrimmed(filepath.Join(dir, "enabled")); err == nil {
+			c.Enabled = enabled == "enabled"
+		}
+		c.DPMS, _ = readTrimmed(filepath.Join(dir, "dpms"))
+
+		// The first listed mode is the monitor's preferred mode
+		if modes, err := readTrimmed(filepath.Join(dir, "modes")); err == nil && modes != "" {
+			lines := strings.Split(modes, "\n")
+			c.PreferredMode = lines[0]
+			c.ModeCount = len(lines)
+		}
+		if edid, err := os.ReadFile(filepath.Clean(filepath.Join(dir, "edid"))); err == nil {
+			c.Monitor = parseEDIDMonitorName(edid)
+		}
+		connectors = append(connectors, c)
+	}
+
+	sort.Slice(connectors, func(i, j int) bool {
+		if connectors[i].Card != connectors[j].Card {
+			return connectors[i].Card < connectors[j].Card
+		}
+		return connectors[i].Name < connectors[j].Name
+	})
+	return connectors, nil
+}
+
+// readDRMActiveModes reads the atomic modeset state of each DRM device under a debugfs
+// root, keyed by "cardN/connector"
+func readDRMActiveModes(root string) (map[string]activeMode, error) {
+	stateFiles, err := filepath.Glob(filepath.Join(root, "*", "state"))
+	if err != nil {
+		return nil, err
+	}
+	if len(stateFiles) == 0 {
+		if _, err := os.ReadDir(filepath.Clean(root)); err != nil {
+			return nil, err
+		}
+	}
+
+	modes := make(map[string]activeMode)
+	var lastErr error
+	for _, f := range stateFiles {
+		data, err := os.ReadFile(filepath.Clean(f))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		card := "card" + filepath.Base(filepath.Dir(f))
+		for connector, mode := range parseDRMState(string(data)) {
+			modes[card+"/"+connector] = mode
+		}
+	}
+	if len(modes) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+	return modes, nil
+}
+
+// parseDRMState maps each connector bound to an active CRTC to that CRTC's mode
+func parseDRMState(data string) map[string]activeMode {
+	crtcModes := make(map[string]activeMode)
+	connectorCRTCs := make(map[string]string)
+
+	var section, name string
+	active := false
+	for _, line := range strings.Split(data, "\n") {
+		if !strings.HasPrefix(line, "\t") {
+			// Section headers look like "crtc[99]: crtc-3" or "connector[33]: HDMI-A-1"
+			kind, rest, ok := strings.Cut(line, ":")
+			if !ok {
+				section = ""
+				continue
+			}
+			section, _, _ = strings.Cut(kind, "[")
+			name = strings.TrimSpace(rest)
+			active = false
+			continue
+		}
+
+		field := strings.TrimSpace(line)
+		switch section {
+		case "crtc":
+			if field == "active=1" {
+				active = true
+			}
+			if m := drmStateModeRe.FindStringSubmatch(field); m != nil && active {
+				refresh, _ := strconv.Atoi(m[2])
+				crtcModes[name] = activeMode{Mode: m[1], RefreshHz: refresh}
+			}
+		case "connector":
+			if crtc, ok := strings.CutPrefix(field, "crtc="); ok && crtc != "(null)" {
+				connectorCRTCs[name] = crtc
+			}
+		}
+	}
+
+	modes := make(map[string]activeMode)
+	for connector, crtc := range connectorCRTCs {
+		if mode, ok := crtcModes[crtc]; ok {
+			modes[connector] = mode
+		}
+	}
+	return modes
+}
+
+// parseEDIDMonitorName returns the monitor name from an EDID display descriptor (tag 0xFC)
+func parseEDIDMonitorName(edid []byte) string {
+	if len(edid) < 128 {
+		return ""
+	}
+	for offset := 54; offset+18 <= 126; offset += 18 {
+		d := edid[offset : offset+18]
+		if d[0] != 0 || d[1] != 0 || d[2] != 0 || d[3] != 0xFC {
+			continue
+		}
+		name, _, _ := strings.Cut(string(d[5:]), "\n")
+		return strings.TrimSpace(name)
+	}
+	return ""
+}
+
+// readFramebufferSize reads a framebuffer virtual_size file ("1920,1080")
+func readFramebufferSize(path string) (map[string]int, bool) {
+	data, err := readTrimmed(path)
+	if err != nil {
+		return nil, false
+	}
+	w, hgt, ok := strings.Cut(data, ",")
+	if !ok {
+		return nil, false
+	}
+	width, err1 := strconv.Atoi(w)
+	height, err2 := strconv.Atoi(hgt)
+	if err1 != nil || err2 != nil {
+		return nil, false
+	}
+	return map[string]int{"width": width, "height": height}, true
+}
+
+// readDisplayPower queries the firmware display power state via vcgencmd
+func readDisplayPower(ctx context.Context) map[string]interface{} {
+	power := map[string]interface{}{
+		"available": false,
+	}
+	out, err := exec.CommandContext(ctx, "vcgencmd", "display_power").Output()
+	if err != nil {
+		power["error"] = fmt.Sprintf("vcgencmd display_power failed: %v", err)
+		return power
+	}
+	m := displayPowerRe.FindStringSubmatch(string(out))
+	if m == nil {
+		power["error"] = fmt.Sprintf("unexpected vcgencmd output: %s", strings.TrimSpace(string(out)))
+		return power
+	}
+	// Under the KMS driver the firmware no longer owns the display and reports -1
+	switch m[1] {
+	case "1":
+		power["available"] = true
+		power["on"] = true
+	case "0":
+		power["available"] = true
+		power["on"] = false
+	default:
+		power["error"] = "display power is managed by the KMS driver; see drm connector dpms"
+	}
+	return power
+}
+
+// readTrimmed reads a small sysfs file and trims surrounding whitespace
+func readTrimmed(path string) (string, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
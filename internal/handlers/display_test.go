@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// testEDID builds a 128-byte EDID block with a monitor name descriptor
+func testEDID(name string) []byte {
+	edid := make([]byte, 128)
+	d := edid[72:90]
+	d[3] = 0xFC
+	copy(d[5:], name+"\n")
+	for i := 5 + len(name) + 1; i < 18; i++ {
+		d[i] = ' '
+	}
+	return edid
+}
+
+func TestReadDRMConnectors(t *testing.T) {
+	root := t.TempDir()
+	writeSysfsFiles(t, filepath.Join(root, "card1-HDMI-A-1"), map[string]string{
+		"status":  "connected\n",
+		"enabled": "enabled\n",
+		"dpms":    "On\n",
+		"modes":   "1920x1080\n1920x1080\n1280x720\n",
+		"edid":    string(testEDID("DELL U2419H")),
+	})
+	writeSysfsFiles(t, filepath.Join(root, "card1-HDMI-A-2"), map[string]string{
+		"status":  "disconnected\n",
+		"enabled": "disabled\n",
+		"dpms":    "Off\n",
+		"modes":   "",
+	})
+	// Devices without a connector suffix must be ignored
+	writeSysfsFiles(t, filepath.Join(root, "card1"), map[string]string{"dev": "226:1"})
+
+	connectors, err := readDRMConnectors(root)
+	if err != nil {
+		t.Fatalf("readDRMConnectors failed: %v", err)
+	}
+	if len(connectors) != 2 {
+		t.Fatalf("Expected 2 connectors, got %d: %+v", len(connectors), connectors)
+	}
+	c := connectors[0]
+	if c.Card != "card1" || c.Name != "HDMI-A-1" || c.Status != "connected" || !c.Enabled || c.DPMS != "On" {
+		t.Errorf("Unexpected connector 0: %+v", c)
+	}
+	if c.PreferredMode != "1920x1080" || c.ModeCount != 3 || c.Monitor != "DELL U2419H" {
+		t.Errorf("Unexpected connector 0 modes/monitor: %+v", c)
+	}
+	if connectors[1].Enabled || connectors[1].ModeCount != 0 {
+		t.Errorf("Unexpected connector 1: %+v", connectors[1])
+	}
+
+	if _, err := readDRMConnectors(filepath.Join(root, "missing")); err == nil {
+		t.Error("Expected error for missing DRM root")
+	}
+}
+
+func TestParseDRMState(t *testing.T) {
+	state := "plane[31]: plane-0\n" +
+		"\tcrtc=crtc-3\n" +
+		"crtc[99]: crtc-3\n" +
+		"\tenable=1\n" +
+		"\tactive=1\n" +
+		"\tmode: \"1920x1080\": 60 148500 1920 2008 2052 2200 1080 1084 1089 1125 0x48 0x5\n" +
+		"crtc[104]: crtc-4\n" +
+		"\tenable=0\n" +
+		"\tactive=0\n" +
+		"\tmode: \"\": 0 0 0 0 0 0 0 0 0 0 0x0 0x0\n" +
+		"connector[33]: HDMI-A-1\n" +
+		"\tcrtc=crtc-3\n" +
+		"connector[41]: HDMI-A-2\n" +
+		"\tcrtc=(null)\n"
+
+	modes := parseDRMState(state)
+	if len(modes) != 1 {
+		t.Fatalf("Expected 1 active mode, got %v", modes)
+	}
+	if m := modes["HDMI-A-1"]; m.Mode != "1920x1080" || m.RefreshHz != 60 {
+		t.Errorf("Unexpected mode for HDMI-A-1: %+v", m)
+	}
+}
+
+func TestReadDRMActiveModes(t *testing.T) {
+	root := t.TempDir()
+	writeSysfsFiles(t, filepath.Join(root, "1"), map[string]string{
+		"state": "crtc[99]: crtc-3\n\tactive=1\n\tmode: \"1280x720\": 50 74250 1280 1720 1760 1980 720 725 730 750 0x40 0x5\n" +
+			"connector[33]: HDMI-A-1\n\tcrtc=crtc-3\n",
+	})
+
+	modes, err := readDRMActiveModes(root)
+	if err != nil {
+		t.Fatalf("readDRMActiveModes failed: %v", err)
+	}
+	if m := modes["card1/HDMI-A-1"]; m.Mode != "1280x720" || m.RefreshHz != 50 {
+		t.Errorf("Unexpected modes: %v", modes)
+	}
+
+	if _, err := readDRMActiveModes(filepath.Join(root, "missing")); err == nil {
+		t.Error("Expected error for missing debugfs root")
+	}
+}
+
+func TestParseEDIDMonitorName(t *testing.T) {
+	if got := parseEDIDMonitorName(testEDID("RPI TOUCH")); got != "RPI TOUCH" {
+		t.Errorf("Expected RPI TOUCH, got %q", got)
+	}
+	if got := parseEDIDMonitorName(make([]byte, 128)); got != "" {
+		t.Errorf("Expected empty name, got %q", got)
+	}
+	if got := parseEDIDMonitorName([]byte{0x00, 0xFF}); got != "" {
+		t.Errorf("Expected empty name for short EDID, got %q", got)
+	}
+}
+
+func TestReadFramebufferSize(t *testing.T) {
+	dir := t.TempDir()
+	writeSysfsFiles(t, dir, map[string]string{"virtual_size": "1920,1080\n", "bad": "garbage\n"})
+
+	fb, ok := readFramebufferSize(filepath.Join(dir, "virtual_size"))
+	if !ok || fb["width"] != 1920 || fb["height"] != 1080 {
+		t.Errorf("Unexpected framebuffer size: %v, %v", fb, ok)
+	}
+	if _, ok := readFramebufferSize(filepath.Join(dir, "bad")); ok {
+		t.Error("Expected failure for malformed virtual_size")
+	}
+}
+
+func TestHandleGetDisplayStatus(t *testing.T) {
+	h := NewHandlerManager(&config.Config{})
+	req := mcp.CallToolRequest{}
+	res, err := h.HandleGetDisplayStatus(context.Background(), req)
+	checkToolResult(t, res, err, []string{"drm"})
+}
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sysmetrics-mcp/internal/dockerapi"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+// Limits on the largest images and volumes listed by get_docker_disk_usage
+const (
+	defaultDockerDiskLimit = 10
+	maxDockerDiskLimit     = 100
+)
+
+// dockerDiskCategory is one row of the docker system df summary
+type dockerDiskCategory struct {
+	Total            int    `json:"total"`
+	Active           int    `json:"active"`
+	SizeBytes        int64  `json:"size_bytes"`
+	SizeHuman        string `json:"size_human"`
+	ReclaimableBytes int64  `json:"reclaimable_bytes"`
+	ReclaimableHuman string `json:"reclaimable_human"`
+}
+
+// dockerDiskItem is an image, container, or volume in the largest-items lists
+type dockerDiskItem struct {
+	Name      string `json:"name"`
+	SizeBytes int64  `json:"size_bytes"`
+	SizeHuman string `json:"size_human"`
+	InUse     bool   `json:"in_use"`
+}
+
+// HandleGetDockerDiskUsage reports the space Docker uses for images, container writable
+// layers, volumes, and the build cache, like docker system df, along with the largest
+// images and volumes, dangling images, and how full the filesystem holding Docker's data
+// root is
+func (h *HandlerManager) HandleGetDockerDiskUsage(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	limit := defaultDockerDiskLimit
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if l, ok := args["limit"].(float64); ok && l > 0 {
+			limit = min(int(l), maxDockerDiskLimit)
+		}
+	}
+
+	client := dockerapi.New(dockerSocket)
+	usage, err := client.DiskUsage(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to query Docker disk usage: %v (the server user needs access to %s, usually through the docker group)", err, dockerSocket)), nil
+	}
+
+	result := h.summarizeDockerDiskUsage(usage, limit)
+
+	// A full root filesystem on a container host is usually Docker's data root
+	if info, err := client.Info(ctx); err == nil && info.DockerRootDir != "" {
+		root := map[string]interface{}{
+			"path":           info.DockerRootDir,
+			"storage_driver": info.Driver,
+		}
+		if du, err := disk.UsageWithContext(ctx, filepath.Clean(info.DockerRootDir)); err == nil {
+			root["filesystem_total_bytes"] = du.Total
+			root["filesystem_free_bytes"] = du.Free
+			root["filesystem_used_percent"] = round2(du.UsedPercent)
+			if du.Total > 0 {
+				used := result["total_size_bytes"].(int64)
+				root["docker_percent_of_filesystem"] = round2(float64(used) / float64(du.Total) * 100)
+			}
+		}
+		result["data_root"] = root
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// summarizeDockerDiskUsage totals a disk usage report the way docker system df does.
+// Reclaimable space is what docker system prune -a --volumes would free: images without
+// containers, less layers shared with images in use; writable layers of stopped
+// containers; unreferenced volumes; and build cache records not in use.
+func (h *HandlerManager) summarizeDockerDiskUsage(usage dockerapi.DiskUsage, limit int) map[string]interface{} {
+	images := dockerDiskCategory{Total: len(usage.Images), SizeBytes: usage.LayersSize}
+	var inUse, danglingSize int64
+	dangling := 0
+	var imageItems []dockerDiskItem
+	for _, img := range usage.Images {
+		if img.Containers > 0 {
+			images.Active++
+			if img.Size >= 0 && img.SharedSize >= 0 {
+				inUse += img.Size - img.SharedSize
+			}
+		}
+		name := strings.TrimPrefix(img.ID, "sha256:")
+		if img.Dangling() {
+			dangling++
+			danglingSize += img.Size
+			if len(name) > 12 {
+				name = name[:12]
+			}
+			name = "<none>@" + name
+		} else {
+			name = strings.Join(img.RepoTags, ", ")
+		}
+		imageItems = append(imageItems, h.dockerDiskItem(name, img.Size, img.Containers > 0))
+	}
+	images.ReclaimableBytes = max(usage.LayersSize-inUse, 0)
+
+	containers := dockerDiskCategory{Total: len(usage.Containers)}
+	var containerItems []dockerDiskItem
+	for _, c := range usage.Containers {
+		running := c.State == "running"
+		if running {
+			containers.Active++
+		}
+		if c.SizeRw > 0 {
+			containers.SizeBytes += c.SizeRw
+			if !running {
+				containers.ReclaimableBytes += c.SizeRw
+			}
+		}
+		name := c.ID
+		if len(c.Names) > 0 {
+			name = strings.TrimPrefix(c.Names[0], "/")
+		}
+		containerItems = append(containerItems, h.dockerDiskItem(name, c.SizeRw, running))
+	}
+
+	volumes := dockerDiskCategory{Total: len(usage.Volumes)}
+	var volumeItems []dockerDiskItem
+	for _, v := range usage.Volumes {
+		var size, refs int64 = -1, -1
+		if v.UsageData != nil {
+			size, refs = v.UsageData.Size, v.UsageData.RefCount
+		}
+		if refs > 0 {
+			volumes.Active++
+		}
+		if size > 0 {
+			volumes.SizeBytes += size
+			if refs == 0 {
+				volumes.ReclaimableBytes += size
+			}
+		}
+		volumeItems = append(volumeItems, h.dockerDiskItem(v.Name, size, refs > 0))
+	}
+
+	// Shared build cache records are counted under the images they belong to
+	cache := dockerDiskCategory{Total: len(usage.BuildCache)}
+	for _, bc := range usage.BuildCache {
+		if bc.InUse {
+			cache.Active++
+		}
+		if bc.Shared {
+			continue
+		}
+		cache.SizeBytes += bc.Size
+		if !bc.InUse {
+			cache.ReclaimableBytes += bc.Size
+		}
+	}
+
+	categories := map[string]*dockerDiskCategory{
+		"images": &images, "containers": &containers, "volumes": &volumes, "build_cache": &cache,
+	}
+	var total, reclaimable int64
+	for _, c := range categories {
+		c.SizeHuman = h.human.Bytes(uint64(max(c.SizeBytes, 0)))
+		c.ReclaimableHuman = h.human.Bytes(uint64(max(c.ReclaimableBytes, 0)))
+		total += c.SizeBytes
+		reclaimable += c.ReclaimableBytes
+	}
+
+	return map[string]interface{}{
+		"images":      images,
+		"containers":  containers,
+		"volumes":     volumes,
+		"build_cache": cache,
+		"dangling_images": map[string]interface{}{
+			"count":      dangling,
+			"size_bytes": danglingSize,
+			"size_human": h.human.Bytes(uint64(max(danglingSize, 0))),
+		},
+		"total_size_bytes":        total,
+		"total_size_human":        h.human.Bytes(uint64(max(total, 0))),
+		"total_reclaimable_bytes": reclaimable,
+		"total_reclaimable_human": h.human.Bytes(uint64(max(reclaimable, 0))),
+		"largest_images":          largestDockerItems(imageItems, limit),
+		"largest_containers":      largestDockerItems(containerItems, limit),
+		"largest_volumes":         largestDockerItems(volumeItems, limit),
+	}
+}
+
+// dockerDiskItem builds a largest-items entry; negative sizes were not computed
+func (h *HandlerManager) dockerDiskItem(name string, size int64, inUse bool) dockerDiskItem {
+	size = max(size, 0)
+	return dockerDiskItem{Name: name, SizeBytes: size, SizeHuman: h.human.Bytes(uint64(size)), InUse: inUse}
+}
+
+// largestDockerItems returns up to limit items, largest first
+func largestDockerItems(items []dockerDiskItem, limit int) []dockerDiskItem {
+	sort.SliceStable(items, func(i, j int) bool { return items[i].SizeBytes > items[j].SizeBytes })
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	if items == nil {
+		items = []dockerDiskItem{}
+	}
+	return items
+}
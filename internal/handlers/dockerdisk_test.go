@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+	"sysmetrics-mcp/internal/dockerapi"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestSummarizeDockerDiskUsage(t *testing.T) {
+	var usage dockerapi.DiskUsage
+	fixture := `{
+		"LayersSize": 1000,
+		"Images": [
+			{"Id":"sha256:aaa","RepoTags":["nginx:latest"],"Size":600,"SharedSize":100,"Containers":1},
+			{"Id":"sha256:bbbbbbbbbbbbbbbbbbbb","RepoTags":["<none>:<none>"],"Size":300,"SharedSize":100,"Containers":0},
+			{"Id":"sha256:ccc","RepoTags":null,"Size":200,"SharedSize":0,"Containers":0}
+		],
+		"Containers": [
+			{"Id":"c1","Names":["/web"],"State":"running","SizeRw":50},
+			{"Id":"c2","Names":["/old"],"State":"exited","SizeRw":70}
+		],
+		"Volumes": [
+			{"Name":"data","UsageData":{"Size":400,"RefCount":1}},
+			{"Name":"orphan","UsageData":{"Size":250,"RefCount":0}},
+			{"Name":"unsized","UsageData":{"Size":-1,"RefCount":-1}}
+		],
+		"BuildCache": [
+			{"ID":"b1","Size":80,"InUse":false,"Shared":false},
+			{"ID":"b2","Size":20,"InUse":true,"Shared":false},
+			{"ID":"b3","Size":500,"InUse":false,"Shared":true}
+		]
+	}`
+	if err := json.Unmarshal([]byte(fixture), &usage); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewHandlerManager(&config.Config{})
+	result := h.summarizeDockerDiskUsage(usage, 2)
+
+	tests := []struct {
+		name string
+		got  dockerDiskCategory
+		want dockerDiskCategory
+	}{
+		{"images", result["images"].(dockerDiskCategory), dockerDiskCategory{Total: 3, Active: 1, SizeBytes: 1000, ReclaimableBytes: 500}},
+		{"containers", result["containers"].(dockerDiskCategory), dockerDiskCategory{Total: 2, Active: 1, SizeBytes: 120, ReclaimableBytes: 70}},
+		{"volumes", result["volumes"].(dockerDiskCategory), dockerDiskCategory{Total: 3, Active: 1, SizeBytes: 650, ReclaimableBytes: 250}},
+		{"build_cache", result["build_cache"].(dockerDiskCategory), dockerDiskCategory{Total: 3, Active: 1, SizeBytes: 100, ReclaimableBytes: 80}},
+	}
+	for _, tt := range tests {
+		if tt.got.Total != tt.want.Total || tt.got.Active != tt.want.Active ||
+			tt.got.SizeBytes != tt.want.SizeBytes || tt.got.ReclaimableBytes != tt.want.ReclaimableBytes {
+			t.Errorf("%s = %+v, expected %+v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	dangling := result["dangling_images"].(map[string]interface{})
+	if dangling["count"] != 2 || dangling["size_bytes"] != int64(500) {
+		t.Errorf("Unexpected dangling images: %v", dangling)
+	}
+	if result["total_size_bytes"] != int64(1870) || result["total_reclaimable_bytes"] != int64(900) {
+		t.Errorf("Unexpected totals: %v, %v", result["total_size_bytes"], result["total_reclaimable_bytes"])
+	}
+
+	images := result["largest_images"].([]dockerDiskItem)
+	if len(images) != 2 || images[0].Name != "nginx:latest" || images[1].Name != "<none>@bbbbbbbbbbbb" {
+		t.Errorf("Unexpected largest images: %+v", images)
+	}
+	if volumes := result["largest_volumes"].([]dockerDiskItem); volumes[1].Name != "orphan" || volumes[1].InUse {
+		t.Errorf("Unexpected largest volumes: %+v", volumes)
+	}
+}
+
+func TestHandleGetDockerDiskUsageNoDaemon(t *testing.T) {
+	oldSocket := dockerSocket
+	t.Cleanup(func() { dockerSocket = oldSocket })
+	dockerSocket = filepath.Join(t.TempDir(), "docker.sock")
+
+	h := NewHandlerManager(&config.Config{})
+	res, err := h.HandleGetDockerDiskUsage(context.Background(), mcp.CallToolRequest{})
+	if err != nil || !res.IsError {
+		t.Errorf("Expected an error result without a daemon, got %v, %v", res, err)
+	}
+}
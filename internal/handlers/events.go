@@ -0,0 +1,418 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"sysmetrics-mcp/internal/config"
+	"sysmetrics-mcp/internal/history"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/shirou/gopsutil/v3/host"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// eventPollInterval is how often RunEventJournal looks for new events
+const eventPollInterval = time.Minute
+
+// get_events defaults and bounds
+const (
+	defaultEventsRange = 24 * time.Hour
+	defaultEventsLimit = 100
+	maxEventsLimit     = 1000
+)
+
+// throttleFlags are the vcgencmd get_throttled conditions journaled when they start and end
+var throttleFlags = []struct {
+	key, flag, started, ended string
+}{
+	{"throttled", "currently_throttled", "CPU is being throttled", "CPU throttling ended"},
+	{"under_voltage", "under_voltage_now", "Under-voltage detected", "Under-voltage ended"},
+}
+
+// eventInputs is the state read by one poll. Nil maps and slices mean the source is
+// unavailable, so its state is left as it was.
+type eventInputs struct {
+	warnings    []string
+	throttle    map[string]bool
+	failedUnits []string
+	oomKills    *uint64
+	processes   map[string][]int32
+}
+
+// eventWatch is what RunEventJournal remembers between polls. The first poll only records
+// the current state, so conditions already present at startup are not journaled.
+type eventWatch struct {
+	primed    bool
+	alerts    map[string]string
+	throttle  map[string]time.Time
+	failed    map[string]bool
+	oomKills  *uint64
+	processes map[string][]int32
+}
+
+// RunEventJournal records notable events (alerts, throttling, failed units, OOM kills,
+// watched process restarts, and this boot) in the history database until ctx is
+// cancelled. It returns immediately when history is disabled.
+func (h *HandlerManager) RunEventJournal(ctx context.Context) {
+	if h.history == nil {
+		return
+	}
+	h.journalBoot(ctx)
+
+	w := &eventWatch{}
+	ticker := time.NewTicker(eventPollInterval)
+	defer ticker.Stop()
+	for {
+		for _, e := range w.update(time.Now(), h.readEventInputs(ctx)) {
+			h.recordEvent(ctx, e)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// recordEvent appends an event to the journal; it is a no-op without --history-db
+func (h *HandlerManager) recordEvent(ctx context.Context, e history.Event) {
+	if h.history == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if err := h.history.WriteEvent(ctx, e); err != nil {
+		h.logger.Warn("event journal write failed", "category", e.Category, "error", err)
+	}
+}
+
+// journalBoot records the current boot once, however often the server restarts during it,
+// with how the previous boot ended
+func (h *HandlerManager) journalBoot(ctx context.Context) {
+	bootTime, err := host.BootTimeWithContext(ctx)
+	if err != nil {
+		return
+	}
+	//nolint:gosec // G115: boot time is a unix timestamp well within int64
+	start := time.Unix(int64(bootTime), 0)
+	// The reported boot time can move by a second between reads
+	seen, err := h.history.Events(ctx, history.EventQuery{
+		Since: start.Add(-time.Minute), Until: time.Now(), Categories: []string{history.EventReboot}, Limit: 1,
+	})
+	if err != nil || len(seen) > 0 {
+		return
+	}
+
+	e := history.Event{Time: start, Category: history.EventReboot, Severity: history.SeverityInfo, Message: "System booted"}
+	if reason, _ := h.bootHistory(ctx); reason.Reason != bootReasonUnknown {
+		e.Message = fmt.Sprintf("System booted; the previous boot ended with %s", strings.ReplaceAll(reason.Reason, "_", " "))
+		e.Details = map[string]string{"previous_boot_end": reason.Reason}
+		if unexpectedBootReasons[reason.Reason] {
+			e.Severity = history.SeverityWarning
+		}
+	}
+	h.recordEvent(ctx, e)
+}
+
+// readEventInputs reads the state each event source is compared on
+func (h *HandlerManager) readEventInputs(ctx context.Context) eventInputs {
+	in := eventInputs{warnings: h.takeSnapshot(ctx, time.Now()).Warnings}
+	if in.warnings == nil {
+		in.warnings = []string{}
+	}
+	if h.caps.Vcgencmd {
+		if status, ok := config.GetThrottledStatus(ctx); ok {
+			in.throttle = map[string]bool{}
+			for _, f := range throttleFlags {
+				in.throttle[f.key], _ = status[f.flag].(bool)
+			}
+		}
+	}
+	if h.caps.Systemd {
+		//nolint:gosec // G204: fixed arguments
+		out, err := exec.CommandContext(ctx, "systemctl", "list-units", "--failed", "--no-legend", "--plain", "--no-pager").Output()
+		if err == nil {
+			in.failedUnits = parseFailedUnits(string(out))
+		}
+	}
+	if counters, err := readVMStat(vmstatPath); err == nil && counters.hasOOMKill {
+		in.oomKills = &counters.oomKills
+	}
+	if len(h.cfg.WatchProcesses) > 0 {
+		in.processes = watchedProcesses(ctx, h.cfg.WatchProcesses)
+	}
+	return in
+}
+
+// parseFailedUnits returns the unit names from `systemctl list-units --failed --plain`
+func parseFailedUnits(output string) []string {
+	units := []string{}
+	for _, line := range strings.Split(output, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			units = append(units, fields[0])
+		}
+	}
+	return units
+}
+
+// watchedProcesses returns the sorted PIDs of each watched process name
+func watchedProcesses(ctx context.Context, names []string) map[string][]int32 {
+	procs, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		return nil
+	}
+	pids := make(map[string][]int32, len(names))
+	for _, name := range names {
+		pids[name] = []int32{}
+	}
+	for _, p := range procs {
+		name, err := p.NameWithContext(ctx)
+		if err != nil {
+			continue
+		}
+		if list, ok := pids[name]; ok {
+			pids[name] = append(list, p.Pid)
+		}
+	}
+	for _, list := range pids {
+		sort.Slice(list, func(i, j int) bool { return list[i] < list[j] })
+	}
+	return pids
+}
+
+// update compares one poll with the previous one and returns the events in between
+func (w *eventWatch) update(now time.Time, in eventInputs) []history.Event {
+	if !w.primed {
+		w.primed = true
+		w.alerts = alertsBySubject(in.warnings)
+		w.throttle = map[string]time.Time{}
+		for key, on := range in.throttle {
+			if on {
+				w.throttle[key] = now
+			}
+		}
+		w.failed = map[string]bool{}
+		for _, u := range in.failedUnits {
+			w.failed[u] = true
+		}
+		w.oomKills = in.oomKills
+		w.processes = in.processes
+		return nil
+	}
+
+	events := []history.Event{}
+	add := func(category, severity, subject, message string, details map[string]string) {
+		events = append(events, history.Event{Time: now, Category: category, Severity: severity, Subject: subject, Message: message, Details: details})
+	}
+
+	if in.warnings != nil {
+		current := alertsBySubject(in.warnings)
+		for _, subject := range sortedKeys(current) {
+			if text := current[subject]; w.alerts[subject] != text {
+				severity := history.SeverityWarning
+				if strings.Contains(text, "critical") {
+					severity = history.SeverityCritical
+				}
+				add(history.EventAlert, severity, subject, text, nil)
+			}
+		}
+		for _, subject := range sortedKeys(w.alerts) {
+			if _, ok := current[subject]; !ok {
+				add(history.EventAlert, history.SeverityInfo, subject, "Resolved: "+w.alerts[subject], nil)
+			}
+		}
+		w.alerts = current
+	}
+
+	if in.throttle != nil {
+		for _, f := range throttleFlags {
+			since, was := w.throttle[f.key]
+			switch on := in.throttle[f.key]; {
+			case on && !was:
+				w.throttle[f.key] = now
+				add(history.EventThrottle, history.SeverityWarning, f.key, f.started, nil)
+			case !on && was:
+				delete(w.throttle, f.key)
+				duration := now.Sub(since).Truncate(time.Second)
+				add(history.EventThrottle, history.SeverityInfo, f.key, fmt.Sprintf("%s after %s", f.ended, duration),
+					map[string]string{"duration_seconds": strconv.FormatInt(int64(duration.Seconds()), 10)})
+			}
+		}
+	}
+
+	if in.failedUnits != nil {
+		current := map[string]bool{}
+		for _, u := range in.failedUnits {
+			current[u] = true
+			if !w.failed[u] {
+				add(history.EventServiceFailure, history.SeverityWarning, u, u+" failed", nil)
+			}
+		}
+		for _, u := range sortedKeys(w.failed) {
+			if !current[u] {
+				add(history.EventServiceFailure, history.SeverityInfo, u, u+" is no longer failed", nil)
+			}
+		}
+		w.failed = current
+	}
+
+	if in.oomKills != nil {
+		if w.oomKills != nil && *in.oomKills > *w.oomKills {
+			n := *in.oomKills - *w.oomKills
+			message := "1 process was killed by the OOM killer"
+			if n > 1 {
+				message = fmt.Sprintf("%d processes were killed by the OOM killer", n)
+			}
+			add(history.EventOOMKill, history.SeverityCritical, "", message, map[string]string{"count": strconv.FormatUint(n, 10)})
+		}
+		w.oomKills = in.oomKills
+	}
+
+	if in.processes != nil {
+		for _, name := range sortedKeys(in.processes) {
+			before, seen := w.processes[name]
+			after := in.processes[name]
+			details := map[string]string{"previous_pids": joinPIDs(before), "pids": joinPIDs(after)}
+			switch {
+			case !seen:
+			case len(before) > 0 && len(after) == 0:
+				add(history.EventProcessRestart, history.SeverityWarning, name, name+" stopped", details)
+			case len(before) == 0 && len(after) > 0:
+				add(history.EventProcessRestart, history.SeverityInfo, name, name+" started", details)
+			case len(after) > 0 && !sharesPID(before, after):
+				add(history.EventProcessRestart, history.SeverityWarning, name, name+" restarted", details)
+			}
+		}
+		w.processes = in.processes
+	}
+	return events
+}
+
+// alertsBySubject keys health warnings by what they are about (cpu, memory, disk), so a
+// warning that escalates to critical replaces the earlier one
+func alertsBySubject(warnings []string) map[string]string {
+	alerts := map[string]string{}
+	for _, w := range warnings {
+		subject, _, _ := strings.Cut(w, " ")
+		alerts[strings.ToLower(subject)] = w
+	}
+	return alerts
+}
+
+// sortedKeys returns a map's keys in order, so events from one poll are stored in a stable order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sharesPID reports whether any PID is in both lists
+func sharesPID(a, b []int32) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// joinPIDs formats PIDs as a comma-separated list
+func joinPIDs(pids []int32) string {
+	s := make([]string, len(pids))
+	for i, pid := range pids {
+		s[i] = strconv.Itoa(int(pid))
+	}
+	return strings.Join(s, ",")
+}
+
+// journalAction records a call to a state-changing tool
+func (h *HandlerManager) journalAction(ctx context.Context, name string, args string, errText string) {
+	e := history.Event{Category: history.EventAction, Severity: history.SeverityInfo, Subject: name, Message: name + " was called"}
+	e.Details = map[string]string{}
+	if args != "" {
+		e.Details["args"] = args
+	}
+	if errText != "" {
+		e.Severity = history.SeverityWarning
+		e.Message = name + " failed"
+		e.Details["error"] = errText
+	}
+	// Record the call even when the client has already gone away
+	h.recordEvent(context.WithoutCancel(ctx), e)
+}
+
+// HandleGetEvents returns the event journal as an incident timeline, newest first
+func (h *HandlerManager) HandleGetEvents(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if h.history == nil {
+		return mcp.NewToolResultError("Metrics history is disabled (start the server with --history-db)"), nil
+	}
+	rangeDur := defaultEventsRange
+	q := history.EventQuery{Limit: defaultEventsLimit}
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if r, ok := args["range"].(string); ok && r != "" {
+			d, err := parseHistoryDuration(r)
+			if err != nil || d <= 0 {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid range: %q (use a duration such as 6h, 24h, or 7d)", r)), nil
+			}
+			rangeDur = d
+		}
+		if c, ok := args["category"].(string); ok && c != "" {
+			for _, category := range config.SplitAndTrim(c) {
+				if !contains(history.EventCategories, category) {
+					return mcp.NewToolResultError(fmt.Sprintf("Invalid category: %q (use %s)", category, strings.Join(history.EventCategories, ", "))), nil
+				}
+				q.Categories = append(q.Categories, category)
+			}
+		}
+		if s, ok := args["severity"].(string); ok && s != "" {
+			if !history.ValidSeverity(s) {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid severity: %q (use info, warning, or critical)", s)), nil
+			}
+			q.MinSeverity = s
+		}
+		if l, ok := args["limit"].(float64); ok && l > 0 {
+			q.Limit = min(int(l), maxEventsLimit)
+		}
+	}
+
+	q.Until = time.Now()
+	q.Since = q.Until.Add(-rangeDur)
+	events, err := h.history.Events(ctx, q)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read events: %v", err)), nil
+	}
+	counts, err := h.history.EventCounts(ctx, q)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read events: %v", err)), nil
+	}
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+
+	jsonBytes, err := json.Marshal(map[string]interface{}{
+		"events":      events,
+		"count":       len(events),
+		"total":       total,
+		"by_category": counts,
+		"truncated":   total > len(events),
+		"range":       rangeDur.String(),
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
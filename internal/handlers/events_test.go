@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"sysmetrics-mcp/internal/history"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestEventWatchUpdate(t *testing.T) {
+	base := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	oom := func(n uint64) *uint64 { return &n }
+	w := &eventWatch{}
+
+	// Conditions present at startup are not journaled
+	events := w.update(base, eventInputs{
+		warnings:    []string{"Disk usage is high (>85%)"},
+		throttle:    map[string]bool{"throttled": false, "under_voltage": false},
+		failedUnits: []string{"backup.service"},
+		oomKills:    oom(3),
+		processes:   map[string][]int32{"nginx": {100, 101}, "mosquitto": {200}},
+	})
+	if len(events) != 0 {
+		t.Fatalf("Expected no events on the first poll, got %+v", events)
+	}
+
+	events = w.update(base.Add(time.Minute), eventInputs{
+		warnings:    []string{"Disk usage is critical (>95%)", "Memory usage is high (>85%)"},
+		throttle:    map[string]bool{"throttled": true, "under_voltage": false},
+		failedUnits: []string{"nginx.service"},
+		oomKills:    oom(5),
+		processes:   map[string][]int32{"nginx": {300}, "mosquitto": {}},
+	})
+	want := []struct{ category, severity, subject string }{
+		{history.EventAlert, history.SeverityCritical, "disk"},
+		{history.EventAlert, history.SeverityWarning, "memory"},
+		{history.EventThrottle, history.SeverityWarning, "throttled"},
+		{history.EventServiceFailure, history.SeverityWarning, "nginx.service"},
+		{history.EventServiceFailure, history.SeverityInfo, "backup.service"},
+		{history.EventOOMKill, history.SeverityCritical, ""},
+		{history.EventProcessRestart, history.SeverityWarning, "mosquitto"},
+		{history.EventProcessRestart, history.SeverityWarning, "nginx"},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("Expected %d events, got %+v", len(want), events)
+	}
+	for i, w := range want {
+		if e := events[i]; e.Category != w.category || e.Severity != w.severity || e.Subject != w.subject {
+			t.Errorf("Event %d = %+v, want %+v", i, e, w)
+		}
+	}
+	if events[5].Details["count"] != "2" || events[6].Message != "mosquitto stopped" || events[7].Message != "nginx restarted" {
+		t.Errorf("Unexpected event details: %+v", events[5:])
+	}
+
+	// Unavailable sources keep their state; throttling ends and the alerts resolve
+	events = w.update(base.Add(4*time.Minute), eventInputs{
+		warnings: []string{},
+		throttle: map[string]bool{"throttled": false, "under_voltage": false},
+	})
+	if len(events) != 3 || events[0].Message != "Resolved: Disk usage is critical (>95%)" || events[2].Details["duration_seconds"] != "180" {
+		t.Errorf("Unexpected events after recovery: %+v", events)
+	}
+}
+
+func TestParseFailedUnits(t *testing.T) {
+	out := "nginx.service loaded failed failed A high performance web server\nbackup.timer  loaded failed failed Nightly backup\n"
+	if got := parseFailedUnits(out); len(got) != 2 || got[0] != "nginx.service" || got[1] != "backup.timer" {
+		t.Errorf("Unexpected units: %v", got)
+	}
+}
+
+func TestHandleGetEvents(t *testing.T) {
+	h := newHistoryTestManager(t)
+	ctx := context.Background()
+	now := time.Now()
+	for _, e := range []history.Event{
+		{Time: now.Add(-48 * time.Hour), Category: history.EventReboot, Severity: history.SeverityInfo, Message: "System booted"},
+		{Time: now.Add(-time.Hour), Category: history.EventOOMKill, Severity: history.SeverityCritical, Message: "1 process was killed by the OOM killer"},
+		{Time: now.Add(-30 * time.Minute), Category: history.EventAlert, Severity: history.SeverityWarning, Subject: "memory", Message: "Memory usage is high (>85%)"},
+	} {
+		h.recordEvent(ctx, e)
+	}
+
+	// State-changing tools are journaled by the audit middleware
+	h.toolTraits["manage_process"] = toolTraits{action: "signals processes"}
+	handler := h.auditTool("manage_process", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultError("Process 42 not found"), nil
+	})
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"pid": 42, "action": "terminate"}
+	if _, err := handler(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := h.HandleGetEvents(ctx, mcp.CallToolRequest{})
+	checkToolResult(t, res, err, []string{"events", "count", "total", "by_category", "truncated", "range"})
+	var result struct {
+		Events     []history.Event `json:"events"`
+		Total      int             `json:"total"`
+		ByCategory map[string]int  `json:"by_category"`
+	}
+	if err := json.Unmarshal([]byte(resultText(res)), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 3 || len(result.Events) != 3 || result.ByCategory[history.EventAction] != 1 {
+		t.Fatalf("Expected 3 events in the last day, got %+v", result)
+	}
+	if a := result.Events[0]; a.Category != history.EventAction || a.Severity != history.SeverityWarning || a.Details["error"] == "" || a.Details["args"] == "" {
+		t.Errorf("Unexpected action event: %+v", a)
+	}
+
+	req = mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"range": "7d", "severity": "critical", "category": "oom_kill,reboot"}
+	res, err = h.HandleGetEvents(ctx, req)
+	checkToolResult(t, res, err, []string{"events"})
+	if err := json.Unmarshal([]byte(resultText(res)), &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Events) != 1 || result.Events[0].Category != history.EventOOMKill {
+		t.Errorf("Expected only the OOM kill, got %+v", result.Events)
+	}
+
+	for _, args := range []map[string]interface{}{{"category": "disk"}, {"severity": "fatal"}, {"range": "soon"}} {
+		req.Params.Arguments = args
+		if res, err := h.HandleGetEvents(ctx, req); err != nil || !res.IsError {
+			t.Errorf("Expected an error for %v", args)
+		}
+	}
+}
@@ -0,0 +1,342 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	stdnet "net"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+// Exposure severities
+const (
+	exposureInfo   = "info"
+	exposureLow    = "low"
+	exposureMedium = "medium"
+	exposureHigh   = "high"
+)
+
+// exposureLevels orders the severities from least to most severe
+var exposureLevels = []string{exposureInfo, exposureLow, exposureMedium, exposureHigh}
+
+// Networks a listening socket can be reached from, most exposed first
+const (
+	netPublic    = "public"
+	netVPN       = "vpn"
+	netPrivate   = "private"
+	netLinkLocal = "link_local"
+	netBridge    = "local_bridge"
+)
+
+// networkOrder ranks the networks for display
+var networkOrder = []string{netPublic, netVPN, netPrivate, netLinkLocal, netBridge}
+
+// fwBypassed marks ports Docker publishes with DNAT, which skips the INPUT chain
+const fwBypassed = "bypassed"
+
+// bridgeIfacePrefixes name virtual interfaces that only reach containers and VMs on this host
+var bridgeIfacePrefixes = []string{"docker", "br-", "virbr", "veth", "cni", "flannel", "cali", "lxcbr", "lxdbr", "podman", "vnet"}
+
+// vpnIfacePrefixes name overlay and VPN interfaces that reach only the VPN's peers
+var vpnIfacePrefixes = []string{"tailscale", "wg", "tun", "zt", "nebula"}
+
+// cgnatNet is the shared address space Tailscale assigns from
+var cgnatNet = &stdnet.IPNet{IP: stdnet.IPv4(100, 64, 0, 0), Mask: stdnet.CIDRMask(10, 32)}
+
+// riskyService is a service that should not be reachable beyond the host; unauthenticated
+// ones are high severity even on a private network
+type riskyService struct {
+	name            string
+	unauthenticated bool
+}
+
+// exposureRiskyPorts are services commonly attacked when reachable
+var exposureRiskyPorts = map[uint32]riskyService{
+	23: {"telnet", true}, 111: {"rpcbind", false}, 139: {"netbios", false}, 445: {"smb", false},
+	1883: {"mqtt", false}, 2375: {"docker api", true}, 2379: {"etcd", false}, 3306: {"mysql", false},
+	3389: {"rdp", false}, 5432: {"postgresql", false}, 5900: {"vnc", false}, 6379: {"redis", true},
+	8086: {"influxdb", false}, 9200: {"elasticsearch", true}, 10250: {"kubelet", false},
+	11211: {"memcached", true}, 27017: {"mongodb", true},
+}
+
+// exposureExpectedPorts are services usually meant to be reachable
+var exposureExpectedPorts = map[uint32]string{
+	22: "ssh", 53: "dns", 68: "dhcp client", 80: "http", 123: "ntp", 443: "https", 546: "dhcpv6 client", 5353: "mdns",
+}
+
+// hostAddr is one non-loopback interface address
+type hostAddr struct {
+	Interface string `json:"interface"`
+	Address   string `json:"address"`
+	Network   string `json:"network"`
+}
+
+// exposedService is a listening socket reachable from beyond the host
+type exposedService struct {
+	Protocol      string   `json:"protocol"`
+	Address       string   `json:"address"`
+	Port          uint32   `json:"port"`
+	Service       string   `json:"service,omitempty"`
+	PID           int32    `json:"pid,omitempty"`
+	Process       string   `json:"process,omitempty"`
+	User          string   `json:"user,omitempty"`
+	ReachableFrom []string `json:"reachable_from"`
+	Firewall      string   `json:"firewall"`
+	AllowedFrom   []string `json:"allowed_from,omitempty"`
+	Severity      string   `json:"severity"`
+	Reason        string   `json:"reason"`
+}
+
+// HandleAuditExposedServices cross-references listening sockets with interface addresses
+// and the firewall's input rules to report services reachable from other hosts
+func (h *HandlerManager) HandleAuditExposedServices(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	kind := kindAll
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if k, ok := args["kind"].(string); ok && (k == kindTCP || k == kindUDP) {
+			kind = k
+		}
+	}
+
+	connections, err := net.ConnectionsWithContext(ctx, kind)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get network connections: %v", err)), nil
+	}
+	ifaces, err := net.InterfacesWithContext(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get network interfaces: %v", err)), nil
+	}
+	addrs := hostAddrs(ifaces)
+
+	firewall := map[string]interface{}{}
+	v4, v6, source, fwErr := h.readFirewall(ctx)
+	if fwErr != nil {
+		firewall["error"] = fmt.Sprintf("%v (reading firewall rules needs root, or iptables-save, ip6tables-save, and nft in --sudo-allowlist)", fwErr)
+	} else {
+		firewall["source"] = source
+		firewall["ipv4_input_policy"] = v4.inputPolicy()
+		if v6 != nil {
+			firewall["ipv6_input_policy"] = v6.inputPolicy()
+		}
+	}
+
+	services := []exposedService{}
+	filtered := []map[string]interface{}{}
+	loopback := 0
+	owners := map[int32]processOwner{}
+	for _, sock := range filterListening(connections) {
+		networks := reachableNetworks(sock.Address, addrs)
+		if len(networks) == 0 {
+			loopback++
+			continue
+		}
+		owner, ok := owners[sock.PID]
+		if !ok && sock.PID != 0 {
+			owner = lookupProcessOwner(ctx, sock.PID)
+			owners[sock.PID] = owner
+		}
+
+		fw := firewallVerdict(sock, v4, v6)
+		if owner.Name == "docker-proxy" {
+			fw = fwResult{Verdict: fwBypassed}
+		}
+		if fw.Verdict == fwBlocked {
+			filtered = append(filtered, map[string]interface{}{
+				"protocol": sock.Type, "address": sock.Address, "port": sock.Port, "process": owner.Name,
+			})
+			continue
+		}
+		svc := exposedService{
+			Protocol: sock.Type, Address: sock.Address, Port: sock.Port, PID: sock.PID,
+			Process: owner.Name, User: owner.User, ReachableFrom: networks,
+			Firewall: fw.Verdict, AllowedFrom: fw.AllowedFrom,
+		}
+		svc.Service, svc.Severity, svc.Reason = assessExposure(sock.Port, networks, fw.Verdict)
+		services = append(services, svc)
+	}
+	sort.SliceStable(services, func(i, j int) bool {
+		a, b := slices.Index(exposureLevels, services[i].Severity), slices.Index(exposureLevels, services[j].Severity)
+		if a != b {
+			return a > b
+		}
+		return services[i].Port < services[j].Port
+	})
+
+	counts := map[string]int{exposureHigh: 0, exposureMedium: 0, exposureLow: 0, exposureInfo: 0}
+	for _, s := range services {
+		counts[s.Severity]++
+	}
+	result := map[string]interface{}{
+		"services":      services,
+		"filtered":      filtered,
+		"loopback_only": loopback,
+		"addresses":     addrs,
+		"firewall":      firewall,
+		"counts":        counts,
+	}
+	if fwErr != nil {
+		result["note"] = "Firewall rules could not be read, so every port bound beyond loopback is treated as reachable"
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// hostAddrs lists the non-loopback addresses of the host with the network each faces
+func hostAddrs(ifaces []net.InterfaceStat) []hostAddr {
+	addrs := []hostAddr{}
+	for _, iface := range ifaces {
+		for _, a := range iface.Addrs {
+			ip, _, err := stdnet.ParseCIDR(a.Addr)
+			if err != nil {
+				ip = stdnet.ParseIP(a.Addr)
+			}
+			if ip == nil || ip.IsLoopback() {
+				continue
+			}
+			addrs = append(addrs, hostAddr{Interface: iface.Name, Address: ip.String(), Network: classifyAddr(ip, iface.Name)})
+		}
+	}
+	return addrs
+}
+
+// classifyAddr names the network an address on an interface faces
+func classifyAddr(ip stdnet.IP, iface string) string {
+	hasPrefix := func(prefixes []string) bool {
+		for _, p := range prefixes {
+			if strings.HasPrefix(iface, p) {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case hasPrefix(bridgeIfacePrefixes):
+		return netBridge
+	case hasPrefix(vpnIfacePrefixes) || cgnatNet.Contains(ip):
+		return netVPN
+	case ip.IsLinkLocalUnicast():
+		return netLinkLocal
+	case ip.IsPrivate():
+		return netPrivate
+	}
+	return netPublic
+}
+
+// reachableNetworks returns the networks a socket bound to addr accepts connections from.
+// An IPv6 wildcard socket also accepts IPv4 unless net.ipv6.bindv6only is set.
+func reachableNetworks(addr string, addrs []hostAddr) []string {
+	seen := map[string]bool{}
+	ip := stdnet.ParseIP(addr)
+	switch {
+	case addr == "" || addr == "*" || (ip != nil && ip.IsUnspecified() && ip.To4() == nil):
+		for _, a := range addrs {
+			seen[a.Network] = true
+		}
+	case ip != nil && ip.IsUnspecified():
+		for _, a := range addrs {
+			if stdnet.ParseIP(a.Address).To4() != nil {
+				seen[a.Network] = true
+			}
+		}
+	case ip != nil && ip.IsLoopback():
+	case ip != nil:
+		network := classifyAddr(ip, "")
+		for _, a := range addrs {
+			if stdnet.ParseIP(a.Address).Equal(ip) {
+				network = a.Network
+			}
+		}
+		seen[network] = true
+	}
+	networks := []string{}
+	for _, n := range networkOrder {
+		if seen[n] {
+			networks = append(networks, n)
+		}
+	}
+	return networks
+}
+
+// firewallVerdict evaluates the input rules of the families a socket accepts. A wildcard
+// IPv6 socket is as exposed as the more permissive family.
+func firewallVerdict(sock listeningSocket, v4, v6 *fwRuleset) fwResult {
+	eval := func(rs *fwRuleset) fwResult {
+		if rs == nil {
+			return fwResult{Verdict: fwUnknown}
+		}
+		return rs.evaluate(sock.Type, sock.Port)
+	}
+	ip := stdnet.ParseIP(sock.Address)
+	switch {
+	case ip != nil && ip.To4() != nil:
+		return eval(v4)
+	case ip != nil && !ip.IsUnspecified():
+		return eval(v6)
+	}
+	a, b := eval(v4), eval(v6)
+	rank := map[string]int{fwBlocked: 0, fwRestricted: 1, fwUnknown: 2, fwAllowed: 3}
+	if rank[b.Verdict] > rank[a.Verdict] {
+		a, b = b, a
+	}
+	if a.Verdict == fwRestricted && b.Verdict == fwRestricted {
+		a.AllowedFrom = append(a.AllowedFrom, b.AllowedFrom...)
+	}
+	return a
+}
+
+// assessExposure names a port's service and rates the exposure. Unauthenticated data
+// stores are high anywhere, other commonly attacked services are medium on a private
+// network and high on a public one, and restrictive firewall rules lower the rating.
+func assessExposure(port uint32, networks []string, verdict string) (string, string, string) {
+	service := exposureExpectedPorts[port]
+	level := 2
+	var reasons []string
+	switch risky, ok := exposureRiskyPorts[port]; {
+	case ok && risky.unauthenticated:
+		service, level = risky.name, 3
+		reasons = append(reasons, fmt.Sprintf("%s is often deployed without authentication", risky.name))
+	case ok:
+		service = risky.name
+		reasons = append(reasons, fmt.Sprintf("%s is a common attack target", risky.name))
+	case service != "":
+		level = 1
+	}
+
+	public := contains(networks, netPublic)
+	onlyLocal := !public && !contains(networks, netPrivate) && !contains(networks, netVPN)
+	switch {
+	case onlyLocal:
+		level = 0
+		reasons = append(reasons, "only reachable from local container or VM bridges or link-local neighbours")
+	case public && port != 80 && port != 443:
+		level = min(level+1, 3)
+		reasons = append(reasons, "reachable on a public address")
+	case public:
+		reasons = append(reasons, "reachable on a public address")
+	default:
+		reasons = append(reasons, fmt.Sprintf("reachable from %s networks", strings.Join(networks, " and ")))
+	}
+
+	switch verdict {
+	case fwRestricted:
+		if level > 1 {
+			level--
+		}
+		reasons = append(reasons, "the firewall admits only some sources")
+	case fwAllowed:
+		reasons = append(reasons, "the firewall accepts it")
+	case fwBypassed:
+		reasons = append(reasons, "Docker publishes it with DNAT, which bypasses INPUT rules such as ufw; filter it in the DOCKER-USER chain")
+	case fwUnknown:
+		reasons = append(reasons, "firewall rules could not be read")
+	}
+	return service, exposureLevels[level], strings.Join(reasons, "; ")
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+func TestReachableNetworks(t *testing.T) {
+	addrs := hostAddrs([]net.InterfaceStat{
+		{Name: "lo", Addrs: net.InterfaceAddrList{{Addr: "127.0.0.1/8"}, {Addr: "::1/128"}}},
+		{Name: "eth0", Addrs: net.InterfaceAddrList{{Addr: "192.168.1.10/24"}, {Addr: "2001:db8::10/64"}, {Addr: "fe80::1/64"}}},
+		{Name: "docker0", Addrs: net.InterfaceAddrList{{Addr: "172.17.0.1/16"}}},
+		{Name: "tailscale0", Addrs: net.InterfaceAddrList{{Addr: "100.101.102.103/32"}}},
+	})
+	if len(addrs) != 5 {
+		t.Fatalf("Expected the 5 non-loopback addresses, got %+v", addrs)
+	}
+
+	tests := []struct {
+		addr     string
+		expected []string
+	}{
+		{"0.0.0.0", []string{netVPN, netPrivate, netBridge}},
+		{"::", []string{netPublic, netVPN, netPrivate, netLinkLocal, netBridge}},
+		{"192.168.1.10", []string{netPrivate}},
+		{"172.17.0.1", []string{netBridge}},
+		{"127.0.0.1", []string{}},
+		{"::1", []string{}},
+	}
+	for _, tt := range tests {
+		if got := reachableNetworks(tt.addr, addrs); !slices.Equal(got, tt.expected) {
+			t.Errorf("reachableNetworks(%q) = %v, expected %v", tt.addr, got, tt.expected)
+		}
+	}
+}
+
+func TestAssessExposure(t *testing.T) {
+	tests := []struct {
+		name     string
+		port     uint32
+		networks []string
+		verdict  string
+		expected string
+	}{
+		{"redis on the LAN", 6379, []string{netPrivate}, fwAllowed, exposureHigh},
+		{"postgres on the LAN", 5432, []string{netPrivate}, fwAllowed, exposureMedium},
+		{"postgres limited by the firewall", 5432, []string{netPrivate}, fwRestricted, exposureLow},
+		{"postgres on a public address", 5432, []string{netPublic, netPrivate}, fwAllowed, exposureHigh},
+		{"ssh on the LAN", 22, []string{netPrivate}, fwAllowed, exposureLow},
+		{"ssh on a public address", 22, []string{netPublic}, fwUnknown, exposureMedium},
+		{"https on a public address", 443, []string{netPublic}, fwAllowed, exposureLow},
+		{"unknown service", 8123, []string{netPrivate}, fwAllowed, exposureMedium},
+		{"container bridge only", 6379, []string{netBridge}, fwAllowed, exposureInfo},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, severity, reason := assessExposure(tt.port, tt.networks, tt.verdict)
+			if severity != tt.expected || reason == "" {
+				t.Errorf("assessExposure = %s (%q), expected %s", severity, reason, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFirewallVerdict(t *testing.T) {
+	v4 := parseIptablesSave("*filter\n:INPUT DROP [0:0]\n-A INPUT -p tcp --dport 22 -j ACCEPT\nCOMMIT\n")
+	v6 := parseIptablesSave("*filter\n:INPUT ACCEPT [0:0]\nCOMMIT\n")
+
+	if got := firewallVerdict(listeningSocket{Type: kindTCP, Address: "0.0.0.0", Port: 8080}, v4, v6); got.Verdict != fwBlocked {
+		t.Errorf("Expected an IPv4 socket to follow the IPv4 rules, got %+v", got)
+	}
+	if got := firewallVerdict(listeningSocket{Type: kindTCP, Address: "::", Port: 8080}, v4, v6); got.Verdict != fwAllowed {
+		t.Errorf("Expected a dual-stack socket to be as open as IPv6 allows, got %+v", got)
+	}
+	if got := firewallVerdict(listeningSocket{Type: kindTCP, Address: "2001:db8::10", Port: 8080}, v4, nil); got.Verdict != fwUnknown {
+		t.Errorf("Expected unknown without IPv6 rules, got %+v", got)
+	}
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// fileNrStat holds the system-wide file handle counters from /proc/sys/fs/file-nr
+type fileNrStat struct {
+	Allocated uint64
+	Unused    uint64
+	Max       uint64
+}
+
+// HandleGetFDUsage returns system-wide and per-process file descriptor usage
+func (h *HandlerManager) HandleGetFDUsage(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	limit := h.cfg.MaxProcesses
+
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if l, ok := args["limit"].(float64); ok && l > 0 {
+			limit = h.cfg.ClampProcessLimit(int(l))
+		}
+	}
+
+	result := map[string]interface{}{}
+
+	// System-wide file handle usage
+	systemInfo := map[string]interface{}{
+		"available": false,
+	}
+	if data, err := os.ReadFile(filepath.Clean(fileNrPath)); err == nil {
+		if stat, err := parseFileNr(string(data)); err == nil {
+			systemInfo["available"] = true
+			systemInfo["allocated"] = stat.Allocated
+			systemInfo["unused"] = stat.Unused
+			systemInfo["max"] = stat.Max
+			if stat.Max > 0 {
+				systemInfo["usage_percent"] = float64(stat.Allocated) / float64(stat.Max) * 100
+			}
+		}
+	}
+	if data, err := os.ReadFile(filepath.Clean("/proc/sys/fs/nr_open")); err == nil {
+		if nrOpen, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64); err == nil {
+			systemInfo["nr_open"] = nrOpen
+		}
+	}
+	result["system"] = systemInfo
+
+	// Limits applied to this server process
+	self, err := process.NewProcessWithContext(ctx, int32(os.Getpid())) //nolint:gosec // G115: PIDs fit in int32
+	if err == nil {
+		if soft, hard, ok := fdLimits(ctx, self); ok {
+			result["ulimit"] = map[string]interface{}{
+				"soft": soft,
+				"hard": hard,
+			}
+		}
+	}
+
+	// Per-process FD consumers
+	processes, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get processes: %v", err)), nil
+	}
+
+	type fdInfo struct {
+		PID          int32   `json:"pid"`
+		Name         string  `json:"name"`
+		NumFDs       int32   `json:"num_fds"`
+		SoftLimit    uint64  `json:"soft_limit,omitempty"`
+		UsagePercent float64 `json:"usage_percent,omitempty"`
+	}
+
+	fdList := []fdInfo{}
+	inaccessible := 0
+	for _, p := range processes {
+		// Stop walking the process table once the client gives up
+		if ctx.Err() != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("FD scan cancelled: %v", ctx.Err())), nil
+		}
+		numFDs, err := p.NumFDsWithContext(ctx)
+		if err != nil {
+			// Usually permission denied for processes owned by other users
+			inaccessible++
+			continue
+		}
+		fdList = append(fdList, fdInfo{PID: p.Pid, NumFDs: numFDs})
+	}
+
+	sort.Slice(fdList, func(i, j int) bool {
+		return fdList[i].NumFDs > fdList[j].NumFDs
+	})
+
+	if len(fdList) > limit {
+		fdList = fdList[:limit]
+	}
+
+	// Only resolve names and limits for the processes actually shown
+	procByPID := make(map[int32]*process.Process, len(processes))
+	for _, p := range processes {
+		procByPID[p.Pid] = p
+	}
+	for i := range fdList {
+		p := procByPID[fdList[i].PID]
+		fdList[i].Name, _ = p.NameWithContext(ctx)
+		if soft, _, ok := fdLimits(ctx, p); ok && soft > 0 {
+			fdList[i].SoftLimit = soft
+			fdList[i].UsagePercent = float64(fdList[i].NumFDs) / float64(soft) * 100
+		}
+	}
+
+	result["top_consumers"] = fdList
+	result["shown"] = len(fdList)
+	result["inaccessible_processes"] = inaccessible
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// parseFileNr parses the contents of /proc/sys/fs/file-nr
+func parseFileNr(data string) (fileNrStat, error) {
+	fields := strings.Fields(data)
+	if len(fields) != 3 {
+		return fileNrStat{}, fmt.Errorf("unexpected file-nr format: %q", strings.TrimSpace(data))
+	}
+
+	values := make([]uint64, 3)
+	for i, f := range fields {
+		v, err := strconv.ParseUint(f, 10, 64)
+		if err != nil {
+			return fileNrStat{}, fmt.Errorf("failed to parse file-nr field %q: %w", f, err)
+		}
+		values[i] = v
+	}
+
+	return fileNrStat{Allocated: values[0], Unused: values[1], Max: values[2]}, nil
+}
+
+// fdLimits returns the soft and hard RLIMIT_NOFILE values for a process
+func fdLimits(ctx context.Context, p *process.Process) (soft, hard uint64, ok bool) {
+	limits, err := p.RlimitWithContext(ctx)
+	if err != nil {
+		return 0, 0, false
+	}
+	for _, l := range limits {
+		if l.Resource == process.RLIMIT_NOFILE {
+			return l.Soft, l.Hard, true
+		}
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestParseFileNr(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    fileNrStat
+		wantErr bool
+	}{
+		{"Valid", "3264\t0\t9223372036854775807\n", fileNrStat{Allocated: 3264, Unused: 0, Max: 9223372036854775807}, false},
+		{"Too few fields", "3264 0", fileNrStat{}, true},
+		{"Non-numeric", "a b c", fileNrStat{}, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseFileNr(tc.input)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parseFileNr(%q) error = %v, wantErr %v", tc.input, err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("parseFileNr(%q) = %+v; want %+v", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestHandleGetFDUsage(t *testing.T) {
+	h := NewHandlerManager(&config.Config{MaxProcesses: 5})
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{},
+		},
+	}
+	res, err := h.HandleGetFDUsage(context.Background(), req)
+	checkToolResult(t, res, err, []string{"system", "top_consumers", "shown"})
+}
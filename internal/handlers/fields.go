@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// parseFieldsArg parses the optional comma-separated "fields" argument against the allowed set.
+// A nil result means the caller did not restrict fields and all of them should be returned.
+func parseFieldsArg(request mcp.CallToolRequest, allowed []string) (map[string]bool, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	fieldsStr, ok := args["fields"].(string)
+	if !ok || fieldsStr == "" {
+		return nil, nil
+	}
+
+	fields := make(map[string]bool)
+	for _, f := range config.SplitAndTrim(fieldsStr) {
+		f = strings.ToLower(f)
+		if !contains(allowed, f) {
+			return nil, fmt.Errorf("unknown field %q (valid fields: %s)", f, strings.Join(allowed, ", "))
+		}
+		fields[f] = true
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return fields, nil
+}
+
+// wantField reports whether a field was selected; a nil selection includes every field
+func wantField(fields map[string]bool, name string) bool {
+	return fields == nil || fields[name]
+}
+
+// projectFields returns row restricted to the selected fields; a nil selection returns row unchanged
+func projectFields(row map[string]interface{}, fields map[string]bool) map[string]interface{} {
+	if fields == nil {
+		return row
+	}
+	projected := make(map[string]interface{}, len(fields))
+	for k, v := range row {
+		if fields[k] {
+			projected[k] = v
+		}
+	}
+	return projected
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestParseFieldsArg(t *testing.T) {
+	allowed := []string{"pid", "name", "cpu_percent"}
+
+	tests := []struct {
+		name    string
+		args    interface{}
+		want    int
+		wantErr bool
+	}{
+		{"No arguments", nil, 0, false},
+		{"Empty fields", map[string]interface{}{"fields": ""}, 0, false},
+		{"Valid fields", map[string]interface{}{"fields": "pid, NAME"}, 2, false},
+		{"Unknown field", map[string]interface{}{"fields": "pid,bogus"}, 0, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: tc.args}}
+			fields, err := parseFieldsArg(req, allowed)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parseFieldsArg() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if len(fields) != tc.want {
+				t.Errorf("parseFieldsArg() returned %d fields; want %d", len(fields), tc.want)
+			}
+		})
+	}
+}
+
+func TestProjectFields(t *testing.T) {
+	row := map[string]interface{}{"pid": 1, "name": "init", "cpu_percent": 0.5}
+
+	if got := projectFields(row, nil); len(got) != 3 {
+		t.Errorf("Expected nil selection to keep all fields, got %v", got)
+	}
+	got := projectFields(row, map[string]bool{"pid": true})
+	if len(got) != 1 || got["pid"] != 1 {
+		t.Errorf("Expected only pid, got %v", got)
+	}
+}
+
+func TestHandleGetProcessListWithFields(t *testing.T) {
+	h := NewHandlerManager(&config.Config{MaxProcesses: 5})
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{
+				"fields":  "pid,name",
+				"sort_by": "pid",
+			},
+		},
+	}
+	res, err := h.HandleGetProcessList(context.Background(), req)
+	checkToolResult(t, res, err, []string{"processes", "total", "shown"})
+
+	var data struct {
+		Processes []map[string]interface{} `json:"processes"`
+	}
+	textContent := res.Content[0].(mcp.TextContent)
+	if parseErr := json.Unmarshal([]byte(textContent.Text), &data); parseErr != nil {
+		t.Fatalf("Failed to parse result: %v", parseErr)
+	}
+	for _, p := range data.Processes {
+		if len(p) != 2 {
+			t.Errorf("Expected only pid and name, got %v", p)
+		}
+	}
+}
+
+func TestHandleGetNetworkMetricsFields(t *testing.T) {
+	h := NewHandlerManager(&config.Config{})
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{"interfaces": "lo", "fields": "interface,bytes_recv"},
+		},
+	}
+	res, err := h.HandleGetNetworkMetrics(context.Background(), req)
+	checkToolResult(t, res, err, []string{"interfaces"})
+
+	var data struct {
+		Interfaces []map[string]interface{} `json:"interfaces"`
+	}
+	if err := json.Unmarshal([]byte(resultText(res)), &data); err != nil {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+	for _, iface := range data.Interfaces {
+		if _, ok := iface["bytes_recv"]; len(iface) != 2 || !ok {
+			t.Errorf("Expected only interface and bytes_recv, got %v", iface)
+		}
+	}
+
+	req.Params.Arguments = map[string]interface{}{"fields": "mtu"}
+	if res, _ := h.HandleGetNetworkMetrics(context.Background(), req); !res.IsError {
+		t.Error("Expected an error for an unknown field")
+	}
+}
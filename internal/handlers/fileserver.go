@@ -0,0 +1,420 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// nfsdStatsPath is the NFS server statistics file exported by the nfsd module
+const nfsdStatsPath = "/proc/net/rpc/nfsd"
+
+// nfsExportsPath lists the exports currently known to the kernel NFS server
+const nfsExportsPath = "/proc/fs/nfs/exports"
+
+// maxLockedFiles bounds the Samba locked-file list
+const maxLockedFiles = 50
+
+// nfsProcNames maps the per-version procedure counters in /proc/net/rpc/nfsd to names
+var nfsProcNames = map[string][]string{
+	"proc2": {"null", "getattr", "setattr", "root", "lookup", "readlink", "read", "writecache", "write",
+		"create", "remove", "rename", "link", "symlink", "mkdir", "rmdir", "readdir", "statfs"},
+	"proc3": {"null", "getattr", "setattr", "lookup", "access", "readlink", "read", "write", "create",
+		"mkdir", "symlink", "mknod", "remove", "rmdir", "rename", "link", "readdir", "readdirplus",
+		"fsstat", "fsinfo", "pathconf", "commit"},
+	"proc4ops": {"op0-unused", "op1-unused", "op2-future", "access", "close", "commit", "create",
+		"delegpurge", "delegreturn", "getattr", "getfh", "link", "lock", "lockt", "locku", "lookup",
+		"lookup_root", "nverify", "open", "openattr", "open_confirm", "open_downgrade", "putfh",
+		"putpubfh", "putrootfh", "read", "readdir", "readlink", "remove", "rename", "renew",
+		"restorefh", "savefh", "secinfo", "setattr", "setclientid", "setclientid_confirm", "verify",
+		"write", "release_lockowner", "backchannel_ctl", "bind_conn_to_session", "exchange_id",
+		"create_session", "destroy_session", "free_stateid", "get_dir_delegation", "getdeviceinfo",
+		"getdevicelist", "layoutcommit", "layoutget", "layoutreturn", "secinfo_no_name", "sequence",
+		"set_ssv", "test_stateid", "want_delegation", "destroy_clientid", "reclaim_complete",
+		"allocate", "copy", "copy_notify", "deallocate", "io_advise", "layouterror", "layoutstats",
+		"offload_cancel", "offload_status", "read_plus", "seek", "write_same", "clone", "getxattr",
+		"setxattr", "listxattrs", "removexattr"},
+}
+
+// nfsProcVersions maps /proc/net/rpc/nfsd procedure lines to protocol version labels
+var nfsProcVersions = map[string]string{"proc2": "v2", "proc3": "v3", "proc4ops": "v4"}
+
+// nfsdStats holds the counters parsed from /proc/net/rpc/nfsd
+type nfsdStats struct {
+	Threads     uint64
+	ReadBytes   uint64
+	WriteBytes  uint64
+	RPCCalls    uint64
+	RPCBadCalls uint64
+	NetPackets  uint64
+	NetUDP      uint64
+	NetTCP      uint64
+	NetTCPConns uint64
+	CacheHits   uint64
+	CacheMisses uint64
+	CacheNone   uint64
+	Ops         map[string]map[string]uint64
+}
+
+// nfsExport is a single exported path and the client it is exported to
+type nfsExport struct {
+	Path   string `json:"path"`
+	Client string `json:"client"`
+}
+
+// sambaSession is a single connected SMB client
+type sambaSession struct {
+	Username   string `json:"username"`
+	Group      string `json:"group,omitempty"`
+	Machine    string `json:"machine"`
+	Protocol   string `json:"protocol,omitempty"`
+	Encryption string `json:"encryption,omitempty"`
+}
+
+// sambaShare is a tree connection from a client to a share
+type sambaShare struct {
+	Service string `json:"service"`
+	Machine string `json:"machine"`
+}
+
+// sambaLockedFile is a file held open by an SMB client
+type sambaLockedFile struct {
+	SharePath string `json:"share_path"`
+	Name      string `json:"name"`
+	Opens     int    `json:"opens"`
+}
+
+// sambaStatus is the parsed state of the Samba server
+type sambaStatus struct {
+	Sessions    []sambaSession
+	Shares      []sambaShare
+	LockedFiles []sambaLockedFile
+}
+
+// smbstatusJSON is the subset of `smbstatus --json` output that is reported
+type smbstatusJSON struct {
+	Sessions map[string]struct {
+		Username       string `json:"username"`
+		Groupname      string `json:"groupname"`
+		RemoteMachine  string `json:"remote_machine"`
+		SessionDialect string `json:"session_dialect"`
+		Encryption     struct {
+			Cipher string `json:"cipher"`
+		} `json:"encryption"`
+	} `json:"sessions"`
+	Tcons map[string]struct {
+		Service string `json:"service"`
+		Machine string `json:"machine"`
+	} `json:"tcons"`
+	OpenFiles map[string]struct {
+		ServicePath string                     `json:"service_path"`
+		Filename    string                     `json:"filename"`
+		Opens       map[string]json.RawMessage `json:"opens"`
+	} `json:"open_files"`
+}
+
+// HandleGetFileserverStatus returns NFS server statistics and Samba sessions and locks
+func (h *HandlerManager) HandleGetFileserverStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	result := map[string]interface{}{
+		"nfs":   collectNFSStatus(),
+		"samba": h.collectSambaStatus(ctx),
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// collectNFSStatus reads kernel NFS server counters and exports
+func collectNFSStatus() map[string]interface{} {
+	nfs := map[string]interface{}{
+		"available": false,
+	}
+
+	data, err := os.ReadFile(filepath.Clean(nfsdStatsPath))
+	if err != nil {
+		nfs["error"] = "NFS server statistics not available (nfsd not loaded)"
+		return nfs
+	}
+	stats := parseNFSDStats(string(data))
+
+	nfs["available"] = true
+	nfs["threads"] = stats.Threads
+	nfs["io"] = map[string]interface{}{
+		"read_bytes":  stats.ReadBytes,
+		"write_bytes": stats.WriteBytes,
+	}
+	nfs["rpc"] = map[string]interface{}{
+		"calls":     stats.RPCCalls,
+		"bad_calls": stats.RPCBadCalls,
+	}
+	nfs["net"] = map[string]interface{}{
+		"packets":         stats.NetPackets,
+		"udp_packets":     stats.NetUDP,
+		"tcp_packets":     stats.NetTCP,
+		"tcp_connections": stats.NetTCPConns,
+	}
+	nfs["reply_cache"] = map[string]interface{}{
+		"hits":     stats.CacheHits,
+		"misses":   stats.CacheMisses,
+		"no_cache": stats.CacheNone,
+	}
+	nfs["ops"] = stats.Ops
+
+	if data, err := os.ReadFile(filepath.Clean(nfsExportsPath)); err == nil {
+		nfs["exports"] = parseNFSExports(string(data))
+	}
+	return nfs
+}
+
+// parseNFSDStats parses the contents of /proc/net/rpc/nfsd. Only non-zero procedure
+// counters are kept so idle protocol versions do not bloat the output.
+func parseNFSDStats(data string) nfsdStats {
+	stats := nfsdStats{Ops: make(map[string]map[string]uint64)}
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		values := make([]uint64, 0, len(fields)-1)
+		for _, f := range fields[1:] {
+			// Some counters (e.g. thread usage histograms) are floats and are ignored
+			v, err := strconv.ParseUint(f, 10, 64)
+			if err != nil {
+				v = 0
+			}
+			values = append(values, v)
+		}
+		at := func(i int) uint64 {
+			if i < len(values) {
+				return values[i]
+			}
+			return 0
+		}
+
+		switch fields[0] {
+		case "rc":
+			stats.CacheHits, stats.CacheMisses, stats.CacheNone = at(0), at(1), at(2)
+		case "io":
+			stats.ReadBytes, stats.WriteBytes = at(0), at(1)
+		case "th":
+			stats.Threads = at(0)
+		case "net":
+			stats.NetPackets, stats.NetUDP, stats.NetTCP, stats.NetTCPConns = at(0), at(1), at(2), at(3)
+		case "rpc":
+			stats.RPCCalls, stats.RPCBadCalls = at(0), at(1)
+		case "proc2", "proc3", "proc4ops":
+			// The first value is the number of counters that follow
+			names := nfsProcNames[fields[0]]
+			ops := make(map[string]uint64)
+			for i, v := range values[1:] {
+				if v == 0 {
+					continue
+				}
+				name := fmt.Sprintf("op%d", i)
+				if i < len(names) {
+					name = names[i]
+				}
+				ops[name] = v
+			}
+			if len(ops) > 0 {
+				stats.Ops[nfsProcVersions[fields[0]]] = ops
+			}
+		}
+	}
+	return stats
+}
+
+// parseNFSExports parses /proc/fs/nfs/exports into path/client pairs
+func parseNFSExports(data string) []nfsExport {
+	exports := []nfsExport{}
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		client, _, _ := strings.Cut(fields[1], "(")
+		exports = append(exports, nfsExport{Path: fields[0], Client: client})
+	}
+	return exports
+}
+
+// collectSambaStatus reports Samba sessions, shares, and locked files via smbstatus
+func (h *HandlerManager) collectSambaStatus(ctx context.Context) map[string]interface{} {
+	samba := map[string]interface{}{
+		"available": false,
+	}
+	if !h.caps.Smbstatus {
+		samba["error"] = "smbstatus not found in PATH"
+		return samba
+	}
+
+	var status sambaStatus
+
+	// Samba 4.16+ supports JSON output; older releases only print tables
+	if out, err := h.privilegedCommand(ctx, "smbstatus", "--json").Output(); err == nil {
+		status, err = parseSmbstatusJSON(out)
+		if err != nil {
+			samba["error"] = fmt.Sprintf("failed to parse smbstatus output: %v", err)
+			return samba
+		}
+		samba["source"] = "json"
+		samba["shares"] = status.Shares
+	} else {
+		sessionsOut, err := h.privilegedCommand(ctx, "smbstatus", "-b").Output()
+		if err != nil {
+			samba["error"] = fmt.Sprintf("smbstatus failed: %v", err)
+			return samba
+		}
+		status.Sessions = parseSmbstatusSessions(string(sessionsOut))
+		status.LockedFiles = []sambaLockedFile{}
+		if locksOut, err := h.privilegedCommand(ctx, "smbstatus", "-L").Output(); err == nil {
+			status.LockedFiles = parseSmbstatusLocks(string(locksOut))
+		}
+		samba["source"] = "text"
+	}
+
+	samba["available"] = true
+	samba["sessions"] = status.Sessions
+	samba["session_count"] = len(status.Sessions)
+	samba["locked_file_count"] = len(status.LockedFiles)
+	locked := status.LockedFiles
+	if len(locked) > maxLockedFiles {
+		locked = locked[:maxLockedFiles]
+	}
+	samba["locked_files"] = locked
+	return samba
+}
+
+// parseSmbstatusJSON parses `smbstatus --json` output
+func parseSmbstatusJSON(data []byte) (sambaStatus, error) {
+	var status smbstatusJSON
+	if err := json.Unmarshal(data, &status); err != nil {
+		return sambaStatus{}, err
+	}
+
+	sessions := []sambaSession{}
+	for _, s := range status.Sessions {
+		sessions = append(sessions, sambaSession{
+			Username:   s.Username,
+			Group:      s.Groupname,
+			Machine:    s.RemoteMachine,
+			Protocol:   s.SessionDialect,
+			Encryption: s.Encryption.Cipher,
+		})
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		if sessions[i].Username != sessions[j].Username {
+			return sessions[i].Username < sessions[j].Username
+		}
+		return sessions[i].Machine < sessions[j].Machine
+	})
+
+	shares := []sambaShare{}
+	for _, t := range status.Tcons {
+		shares = append(shares, sambaShare{Service: t.Service, Machine: t.Machine})
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].Service != shares[j].Service {
+			return shares[i].Service < shares[j].Service
+		}
+		return shares[i].Machine < shares[j].Machine
+	})
+
+	locked := []sambaLockedFile{}
+	for _, f := range status.OpenFiles {
+		locked = append(locked, sambaLockedFile{SharePath: f.ServicePath, Name: f.Filename, Opens: len(f.Opens)})
+	}
+	sortLockedFiles(locked)
+
+	return sambaStatus{Sessions: sessions, Shares: shares, LockedFiles: locked}, nil
+}
+
+// smbstatusTableRows returns the rows following the dashed separator of the first table
+// in smbstatus text output
+func smbstatusTableRows(output string) [][]string {
+	var rows [][]string
+	inTable := false
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "---") {
+			inTable = true
+			continue
+		}
+		if !inTable {
+			continue
+		}
+		if trimmed == "" {
+			break
+		}
+		rows = append(rows, strings.Fields(trimmed))
+	}
+	return rows
+}
+
+// parseSmbstatusSessions parses the session table printed by `smbstatus -b`
+func parseSmbstatusSessions(output string) []sambaSession {
+	sessions := []sambaSession{}
+	for _, fields := range smbstatusTableRows(output) {
+		// PID Username Group Machine [(ipv4:addr:port)] Protocol Encryption Signing
+		if len(fields) < 4 {
+			continue
+		}
+		s := sambaSession{Username: fields[1], Group: fields[2], Machine: fields[3]}
+		rest := fields[4:]
+		if len(rest) > 0 && strings.HasPrefix(rest[0], "(") {
+			rest = rest[1:]
+		}
+		if len(rest) > 0 {
+			s.Protocol = rest[0]
+		}
+		if len(rest) > 1 && rest[1] != "-" {
+			s.Encryption = rest[1]
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions
+}
+
+// parseSmbstatusLocks parses the locked files table printed by `smbstatus -L`
+func parseSmbstatusLocks(output string) []sambaLockedFile {
+	byFile := make(map[[2]string]int)
+	for _, fields := range smbstatusTableRows(output) {
+		// Pid User(ID) DenyMode Access R/W Oplock SharePath Name... Time (5 fields)
+		if len(fields) < 13 {
+			continue
+		}
+		key := [2]string{fields[6], strings.Join(fields[7:len(fields)-5], " ")}
+		byFile[key]++
+	}
+
+	locked := []sambaLockedFile{}
+	for key, opens := range byFile {
+		locked = append(locked, sambaLockedFile{SharePath: key[0], Name: key[1], Opens: opens})
+	}
+	sortLockedFiles(locked)
+	return locked
+}
+
+// sortLockedFiles orders locked files by share path and name
+func sortLockedFiles(locked []sambaLockedFile) {
+	sort.Slice(locked, func(i, j int) bool {
+		if locked[i].SharePath != locked[j].SharePath {
+			return locked[i].SharePath < locked[j].SharePath
+		}
+		return locked[i].Name < locked[j].Name
+	})
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestParseNFSDStats(t *testing.T) {
+	data := `rc 12 340 5
+fh 0 0 0 0 0
+io 1048576 2097152
+th 8 0 0.000 0.000 0.000 0.000 0.000 0.000 0.000 0.000 0.000 0.000
+ra 32 0 0 0 0 0 0 0 0 0 0 0
+net 400 0 400 3
+rpc 398 2 0 2 0
+proc3 22 1 50 0 20 30 0 100 40 0 0 0 0 0 0 0 0 0 5 0 0 0 0
+proc4 2 1 200
+proc4ops 72 0 0 0 10 0 0 0 0 0 25 0 0 0 0 0 7 0 0 0 0 0 0 60 0 0 15
+`
+
+	stats := parseNFSDStats(data)
+	if stats.Threads != 8 || stats.ReadBytes != 1048576 || stats.WriteBytes != 2097152 {
+		t.Errorf("Unexpected thread/io counters: %+v", stats)
+	}
+	if stats.RPCCalls != 398 || stats.RPCBadCalls != 2 || stats.NetTCPConns != 3 || stats.CacheMisses != 340 {
+		t.Errorf("Unexpected rpc/net/cache counters: %+v", stats)
+	}
+
+	v3 := stats.Ops["v3"]
+	if v3["getattr"] != 50 || v3["read"] != 100 || v3["write"] != 40 || v3["readdirplus"] != 5 {
+		t.Errorf("Unexpected v3 ops: %v", v3)
+	}
+	if _, ok := v3["setattr"]; ok {
+		t.Error("Expected zero counters to be omitted")
+	}
+
+	v4 := stats.Ops["v4"]
+	if v4["access"] != 10 || v4["getattr"] != 25 || v4["lookup"] != 7 || v4["putfh"] != 60 || v4["read"] != 15 {
+		t.Errorf("Unexpected v4 ops: %v", v4)
+	}
+	if _, ok := stats.Ops["v2"]; ok {
+		t.Error("Expected no v2 ops when the line is missing")
+	}
+}
+
+func TestParseNFSExports(t *testing.T) {
+	data := `# Version 1.1
+# Path Client(Flags) # IPs
+/srv/media	192.168.1.0/24(ro,root_squash,sync,wdelay,no_subtree_check)
+/srv/backup	nas-client.lan(rw,sync)
+`
+	exports := parseNFSExports(data)
+	if len(exports) != 2 {
+		t.Fatalf("Expected 2 exports, got %d", len(exports))
+	}
+	if exports[0].Path != "/srv/media" || exports[0].Client != "192.168.1.0/24" {
+		t.Errorf("Unexpected export: %+v", exports[0])
+	}
+}
+
+func TestParseSmbstatusJSON(t *testing.T) {
+	data := []byte(`{
+		"sessions": {
+			"3": {"username": "bob", "groupname": "users", "remote_machine": "192.168.1.20", "session_dialect": "SMB3_11", "encryption": {"cipher": "AES-128-GCM"}},
+			"1": {"username": "alice", "groupname": "users", "remote_machine": "192.168.1.10", "session_dialect": "SMB3_11", "encryption": {"cipher": ""}}
+		},
+		"tcons": {
+			"7": {"service": "media", "machine": "192.168.1.10"}
+		},
+		"open_files": {
+			"/srv/media/movie.mkv": {"service_path": "/srv/media", "filename": "movie.mkv", "opens": {"a": {}, "b": {}}}
+		}
+	}`)
+
+	status, err := parseSmbstatusJSON(data)
+	if err != nil {
+		t.Fatalf("parseSmbstatusJSON failed: %v", err)
+	}
+	if len(status.Sessions) != 2 || status.Sessions[0].Username != "alice" || status.Sessions[1].Encryption != "AES-128-GCM" {
+		t.Errorf("Unexpected sessions: %+v", status.Sessions)
+	}
+	if len(status.Shares) != 1 || status.Shares[0].Service != "media" {
+		t.Errorf("Unexpected shares: %+v", status.Shares)
+	}
+	if len(status.LockedFiles) != 1 || status.LockedFiles[0].Opens != 2 {
+		t.Errorf("Unexpected locked files: %+v", status.LockedFiles)
+	}
+
+	if _, err := parseSmbstatusJSON([]byte("not json")); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+}
+
+func TestParseSmbstatusText(t *testing.T) {
+	sessionsOut := `
+Samba version 4.13.13-Debian
+PID     Username     Group        Machine                                   Protocol Version  Encryption           Signing
+----------------------------------------------------------------------------------------------------------------------------------------
+12345   alice        users        192.168.1.10 (ipv4:192.168.1.10:51234)    SMB3_11           -                    partial(AES-128-CMAC)
+12400   bob          users        192.168.1.20 (ipv4:192.168.1.20:50000)    SMB3_02           AES-128-CCM          -
+
+`
+	sessions := parseSmbstatusSessions(sessionsOut)
+	if len(sessions) != 2 {
+		t.Fatalf("Expected 2 sessions, got %d", len(sessions))
+	}
+	if sessions[0].Machine != "192.168.1.10" || sessions[0].Protocol != "SMB3_11" || sessions[0].Encryption != "" {
+		t.Errorf("Unexpected session: %+v", sessions[0])
+	}
+	if sessions[1].Encryption != "AES-128-CCM" {
+		t.Errorf("Expected encryption for bob, got %+v", sessions[1])
+	}
+
+	locksOut := `
+Locked files:
+Pid          User(ID)   DenyMode   Access      R/W        Oplock           SharePath   Name   Time
+--------------------------------------------------------------------------------------------------
+12345        1000       DENY_NONE  0x120089    RDONLY     LEASE(RWH)       /srv/media   Movies/A Film.mkv   Sun Jan  7 10:00:00 2024
+12346        1000       DENY_NONE  0x120089    RDONLY     NONE             /srv/media   Movies/A Film.mkv   Sun Jan  7 10:01:00 2024
+12400        1001       DENY_WRITE 0x12019f    RDWR       NONE             /srv/docs    notes.txt   Sun Jan  7 11:00:00 2024
+
+`
+	locked := parseSmbstatusLocks(locksOut)
+	if len(locked) != 2 {
+		t.Fatalf("Expected 2 locked files, got %d", len(locked))
+	}
+	if locked[0].SharePath != "/srv/docs" || locked[1].Name != "Movies/A Film.mkv" || locked[1].Opens != 2 {
+		t.Errorf("Unexpected locked files: %+v", locked)
+	}
+
+	if got := parseSmbstatusLocks("No locked files\n"); len(got) != 0 {
+		t.Errorf("Expected no locked files, got %+v", got)
+	}
+}
+
+func TestHandleGetFileserverStatus(t *testing.T) {
+	h := NewHandlerManager(&config.Config{})
+	req := mcp.CallToolRequest{}
+	res, err := h.HandleGetFileserverStatus(context.Background(), req)
+	checkToolResult(t, res, err, []string{"nfs", "samba"})
+}
@@ -0,0 +1,594 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	stdnet "net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Firewall verdicts for new inbound connections to a port
+const (
+	fwAllowed    = "allowed"
+	fwRestricted = "restricted"
+	fwBlocked    = "blocked"
+	fwUnknown    = "unknown"
+)
+
+// fwMaxDepth bounds jumps between chains
+const fwMaxDepth = 16
+
+// fwPortRange is an inclusive destination port range
+type fwPortRange struct {
+	lo, hi uint32
+}
+
+// fwRule is the part of a firewall rule that decides inbound TCP or UDP connections.
+// Matches the evaluator cannot judge make the rule conditional.
+type fwRule struct {
+	protos      []string
+	ports       []fwPortRange
+	iface       string
+	ifaceNeg    bool
+	sources     []string
+	conditional bool
+	// established marks rules that only match existing connections
+	established bool
+	target      string
+}
+
+// fwChain is a chain with its rules; base chains have a policy
+type fwChain struct {
+	policy string
+	rules  []fwRule
+}
+
+// fwRuleset holds the input path of one address family: the base chains in the order
+// packets traverse them and the chains they jump to
+type fwRuleset struct {
+	base   []string
+	chains map[string]*fwChain
+}
+
+// fwResult is how the input rules treat new connections to one port
+type fwResult struct {
+	Verdict     string
+	AllowedFrom []string
+}
+
+// evaluate decides whether a new connection to port from a non-loopback interface is
+// accepted. Accept rules limited to some sources or interfaces do not end the walk; when
+// the port is otherwise dropped they make it restricted to those sources.
+func (rs *fwRuleset) evaluate(proto string, port uint32) fwResult {
+	var allowedFrom []string
+	for _, name := range rs.base {
+		chain := rs.chains[name]
+		if chain == nil {
+			continue
+		}
+		verdict := rs.walk(name, proto, port, &allowedFrom, 0)
+		if verdict == "" {
+			verdict = chain.policy
+		}
+		if verdict != "accept" {
+			if len(allowedFrom) > 0 {
+				return fwResult{Verdict: fwRestricted, AllowedFrom: allowedFrom}
+			}
+			return fwResult{Verdict: fwBlocked}
+		}
+	}
+	return fwResult{Verdict: fwAllowed}
+}
+
+// walk follows one chain, returning "accept" or "drop", or "" when the packet falls
+// through or returns to the calling chain
+func (rs *fwRuleset) walk(name, proto string, port uint32, allowedFrom *[]string, depth int) string {
+	chain := rs.chains[name]
+	if chain == nil || depth > fwMaxDepth {
+		return ""
+	}
+	for _, r := range chain.rules {
+		if r.established || !r.matches(proto, port) {
+			continue
+		}
+		// "! -i lo" covers every other interface, so it does not narrow the match
+		conditional := r.conditional || len(r.sources) > 0 || (r.iface != "" && !(r.ifaceNeg && r.iface == "lo"))
+		switch r.target {
+		case "accept":
+			if !conditional {
+				return "accept"
+			}
+			*allowedFrom = append(*allowedFrom, r.describeScope())
+		case "drop", "reject":
+			if !conditional {
+				return "drop"
+			}
+		case "return":
+			if !conditional {
+				return ""
+			}
+		case "":
+		default:
+			if v := rs.walk(r.target, proto, port, allowedFrom, depth+1); v != "" {
+				return v
+			}
+		}
+	}
+	return ""
+}
+
+// matches reports whether the rule can apply to a new connection to port
+func (r fwRule) matches(proto string, port uint32) bool {
+	if len(r.protos) > 0 && !contains(r.protos, proto) {
+		return false
+	}
+	if r.iface == "lo" && !r.ifaceNeg {
+		return false
+	}
+	if len(r.ports) == 0 {
+		return true
+	}
+	for _, p := range r.ports {
+		if port >= p.lo && port <= p.hi {
+			return true
+		}
+	}
+	return false
+}
+
+// describeScope summarizes who a conditional accept rule admits
+func (r fwRule) describeScope() string {
+	var parts []string
+	if len(r.sources) > 0 {
+		parts = append(parts, strings.Join(r.sources, ", "))
+	}
+	if r.iface != "" {
+		neg := ""
+		if r.ifaceNeg {
+			neg = "not "
+		}
+		parts = append(parts, "interface "+neg+r.iface)
+	}
+	if len(parts) == 0 {
+		return "some connections (rule has conditions that are not evaluated)"
+	}
+	return strings.Join(parts, " via ")
+}
+
+// readFirewall returns the IPv4 and IPv6 input rules; v6 is nil when only the IPv4 rules
+// could be read. iptables-save covers iptables, ufw, and iptables-nft; a native nftables
+// ruleset is read when iptables has no input filtering. Both need root or an entry in
+// --sudo-allowlist.
+func (h *HandlerManager) readFirewall(ctx context.Context) (v4, v6 *fwRuleset, source string, err error) {
+	out, iptErr := h.privilegedCommand(ctx, "iptables-save", "-t", "filter").Output()
+	if iptErr == nil {
+		v4 = parseIptablesSave(string(out))
+		if out, err := h.privilegedCommand(ctx, "ip6tables-save", "-t", "filter").Output(); err == nil {
+			v6 = parseIptablesSave(string(out))
+		}
+		if v4.filters() || (v6 != nil && v6.filters()) {
+			return v4, v6, "iptables", nil
+		}
+	}
+	out, nftErr := h.privilegedCommand(ctx, "nft", "-j", "list", "ruleset").Output()
+	if nftErr == nil {
+		var n4, n6 *fwRuleset
+		if n4, n6, nftErr = parseNftRuleset(out); nftErr == nil {
+			return n4, n6, "nftables", nil
+		}
+	}
+	if iptErr == nil {
+		// iptables-save ran and found no input filtering
+		return v4, v6, "iptables", nil
+	}
+	return nil, nil, "", fmt.Errorf("iptables-save: %v; nft: %v", iptErr, nftErr)
+}
+
+// filters reports whether the ruleset drops anything on input
+func (rs *fwRuleset) filters() bool {
+	for _, name := range rs.base {
+		if c := rs.chains[name]; c != nil && (c.policy != "accept" || len(c.rules) > 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// inputPolicy returns the policy of the first base input chain, or "" without one
+func (rs *fwRuleset) inputPolicy() string {
+	for _, name := range rs.base {
+		if c := rs.chains[name]; c != nil {
+			return c.policy
+		}
+	}
+	return ""
+}
+
+// parseIptablesSave parses the filter table's INPUT chain and the chains it reaches
+// from iptables-save output
+func parseIptablesSave(out string) *fwRuleset {
+	rs := &fwRuleset{chains: map[string]*fwChain{}}
+	inFilter := false
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "*filter":
+			inFilter = true
+		case strings.HasPrefix(line, "*"), line == "COMMIT":
+			inFilter = false
+		case !inFilter:
+		case strings.HasPrefix(line, ":"):
+			fields := strings.Fields(line[1:])
+			if len(fields) < 2 {
+				continue
+			}
+			policy := strings.ToLower(fields[1])
+			if policy == "-" {
+				policy = ""
+			}
+			rs.chains[fields[0]] = &fwChain{policy: policy}
+		case strings.HasPrefix(line, "-A "):
+			args := splitShellArgs(line)
+			if len(args) < 2 {
+				continue
+			}
+			chain := rs.chains[args[1]]
+			if chain == nil {
+				chain = &fwChain{}
+				rs.chains[args[1]] = chain
+			}
+			chain.rules = append(chain.rules, parseIptablesRule(args[2:]))
+		}
+	}
+	if _, ok := rs.chains["INPUT"]; ok {
+		rs.base = []string{"INPUT"}
+	}
+	return rs
+}
+
+// parseIptablesRule converts the arguments of one "-A" line
+func parseIptablesRule(args []string) fwRule {
+	var r fwRule
+	negate := false
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		next := func() string {
+			if i+1 < len(args) {
+				i++
+				return args[i]
+			}
+			return ""
+		}
+		if a == "!" {
+			negate = true
+			continue
+		}
+		switch a {
+		case "-p", "--protocol":
+			p := strings.ToLower(next())
+			if negate {
+				r.conditional = true
+			} else if p != "all" {
+				r.protos = []string{p}
+			}
+		case "--dport", "--destination-port", "--dports", "--destination-ports":
+			ports := parsePortList(next(), ":")
+			if negate || ports == nil {
+				r.conditional = true
+			} else {
+				r.ports = ports
+			}
+		case "-i", "--in-interface":
+			r.iface, r.ifaceNeg = next(), negate
+		case "-s", "--source":
+			if negate {
+				r.conditional = true
+				next()
+			} else {
+				r.sources = append(r.sources, strings.Split(next(), ",")...)
+			}
+		case "--state", "--ctstate":
+			states := strings.Split(strings.ToUpper(next()), ",")
+			if !negate && !contains(states, "NEW") {
+				r.established = true
+			}
+		case "-j", "--jump", "-g", "--goto":
+			t := next()
+			switch strings.ToUpper(t) {
+			case "ACCEPT", "DROP", "REJECT", "RETURN":
+				t = strings.ToLower(t)
+			}
+			r.target = t
+		case "-m", "--match", "--comment", "--log-prefix", "--reject-with", "--icmp-type", "--icmpv6-type", "--limit", "--limit-burst":
+			next()
+		case "-d", "--destination", "--src-type", "--dst-type", "--sport", "--sports", "--tcp-flags", "--syn",
+			"--name", "--rcheck", "--update", "--seconds", "--hitcount", "--set", "--uid-owner", "--mark":
+			// Conditions the evaluator does not judge; most take one value
+			r.conditional = true
+			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+				next()
+			}
+		}
+		negate = false
+	}
+	return r
+}
+
+// parseNftRuleset parses "nft -j list ruleset" into IPv4 and IPv6 input rules. inet
+// tables apply to both families.
+func parseNftRuleset(out []byte) (*fwRuleset, *fwRuleset, error) {
+	var doc struct {
+		Nftables []map[string]json.RawMessage `json:"nftables"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		return nil, nil, err
+	}
+	type baseChain struct {
+		key, family string
+		prio        int
+	}
+	chains := map[string]*fwChain{}
+	var bases []baseChain
+	for _, obj := range doc.Nftables {
+		if raw, ok := obj["chain"]; ok {
+			var c struct {
+				Family, Table, Name, Hook, Policy string
+				Prio                              int
+			}
+			if json.Unmarshal(raw, &c) != nil {
+				continue
+			}
+			key := c.Family + "/" + c.Table + "/" + c.Name
+			chains[key] = &fwChain{policy: c.Policy}
+			if c.Hook == "input" {
+				if chains[key].policy == "" {
+					chains[key].policy = "accept"
+				}
+				bases = append(bases, baseChain{key, c.Family, c.Prio})
+			}
+		}
+		if raw, ok := obj["rule"]; ok {
+			var r struct {
+				Family, Table, Chain string
+				Expr                 []map[string]json.RawMessage
+			}
+			if json.Unmarshal(raw, &r) != nil {
+				continue
+			}
+			prefix := r.Family + "/" + r.Table + "/"
+			c := chains[prefix+r.Chain]
+			if c == nil {
+				c = &fwChain{}
+				chains[prefix+r.Chain] = c
+			}
+			c.rules = append(c.rules, parseNftRule(r.Expr, prefix))
+		}
+	}
+	sort.SliceStable(bases, func(i, j int) bool { return bases[i].prio < bases[j].prio })
+
+	v4 := &fwRuleset{chains: chains}
+	v6 := &fwRuleset{chains: chains}
+	for _, b := range bases {
+		switch b.family {
+		case "ip":
+			v4.base = append(v4.base, b.key)
+		case "ip6":
+			v6.base = append(v6.base, b.key)
+		case "inet":
+			v4.base = append(v4.base, b.key)
+			v6.base = append(v6.base, b.key)
+		}
+	}
+	return v4, v6, nil
+}
+
+// parseNftRule converts one rule's expressions; chain names are qualified with prefix
+func parseNftRule(exprs []map[string]json.RawMessage, prefix string) fwRule {
+	var r fwRule
+	for _, e := range exprs {
+		for kind, raw := range e {
+			switch kind {
+			case "match":
+				parseNftMatch(raw, &r)
+			case "accept", "drop", "reject", "return":
+				r.target = kind
+			case "jump", "goto":
+				var t struct{ Target string }
+				if json.Unmarshal(raw, &t) == nil {
+					r.target = prefix + t.Target
+				}
+			case "counter", "log", "limit", "comment":
+			default:
+				r.conditional = true
+			}
+		}
+	}
+	return r
+}
+
+// parseNftMatch applies one match expression to the rule
+func parseNftMatch(raw json.RawMessage, r *fwRule) {
+	var m struct {
+		Op    string
+		Left  map[string]json.RawMessage
+		Right json.RawMessage
+	}
+	if json.Unmarshal(raw, &m) != nil {
+		r.conditional = true
+		return
+	}
+	neg := m.Op == "!="
+	var payload struct{ Protocol, Field string }
+	var meta struct{ Key string }
+	switch {
+	case json.Unmarshal(m.Left["payload"], &payload) == nil && payload.Field == "dport":
+		ports := nftPorts(m.Right, payload.Protocol)
+		if neg || ports == nil {
+			r.conditional = true
+			return
+		}
+		r.ports = ports
+		// "th dport" matches any transport protocol
+		if payload.Protocol != "th" {
+			r.protos = []string{payload.Protocol}
+		}
+	case json.Unmarshal(m.Left["payload"], &payload) == nil && payload.Field == "saddr":
+		if neg {
+			r.conditional = true
+			return
+		}
+		r.sources = append(r.sources, nftValues(m.Right)...)
+	case json.Unmarshal(m.Left["meta"], &meta) == nil && (meta.Key == "l4proto" || meta.Key == "nfproto"):
+		if meta.Key == "l4proto" && !neg {
+			r.protos = nftValues(m.Right)
+		}
+	case json.Unmarshal(m.Left["meta"], &meta) == nil && (meta.Key == "iifname" || meta.Key == "iif"):
+		if values := nftValues(m.Right); len(values) == 1 {
+			r.iface, r.ifaceNeg = values[0], neg
+		} else {
+			r.conditional = true
+		}
+	case json.Unmarshal(m.Left["ct"], &meta) == nil && meta.Key == "state":
+		if !neg && !contains(nftValues(m.Right), "new") {
+			r.established = true
+		}
+	default:
+		r.conditional = true
+	}
+}
+
+// nftValues flattens a match value, a set of values, or a prefix into strings
+func nftValues(raw json.RawMessage) []string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return []string{s}
+	}
+	var n float64
+	if json.Unmarshal(raw, &n) == nil {
+		return []string{strconv.FormatFloat(n, 'f', -1, 64)}
+	}
+	var list []json.RawMessage
+	if json.Unmarshal(raw, &list) == nil {
+		var values []string
+		for _, v := range list {
+			values = append(values, nftValues(v)...)
+		}
+		return values
+	}
+	var obj struct {
+		Set    []json.RawMessage
+		Prefix *struct {
+			Addr string
+			Len  int
+		}
+	}
+	if json.Unmarshal(raw, &obj) == nil {
+		if obj.Prefix != nil {
+			return []string{fmt.Sprintf("%s/%d", obj.Prefix.Addr, obj.Prefix.Len)}
+		}
+		var values []string
+		for _, v := range obj.Set {
+			values = append(values, nftValues(v)...)
+		}
+		return values
+	}
+	return nil
+}
+
+// nftPorts parses a dport match value: a port, service name, range, or set of them
+func nftPorts(raw json.RawMessage, proto string) []fwPortRange {
+	var obj struct {
+		Set   []json.RawMessage
+		Range []json.RawMessage
+	}
+	if json.Unmarshal(raw, &obj) == nil && (obj.Set != nil || obj.Range != nil) {
+		if len(obj.Range) == 2 {
+			lo, ok1 := nftPort(obj.Range[0], proto)
+			hi, ok2 := nftPort(obj.Range[1], proto)
+			if !ok1 || !ok2 {
+				return nil
+			}
+			return []fwPortRange{{lo, hi}}
+		}
+		var ports []fwPortRange
+		for _, v := range obj.Set {
+			p := nftPorts(v, proto)
+			if p == nil {
+				return nil
+			}
+			ports = append(ports, p...)
+		}
+		return ports
+	}
+	if p, ok := nftPort(raw, proto); ok {
+		return []fwPortRange{{p, p}}
+	}
+	return nil
+}
+
+// nftPort parses a single port number or service name
+func nftPort(raw json.RawMessage, proto string) (uint32, bool) {
+	var n uint32
+	if json.Unmarshal(raw, &n) == nil {
+		return n, true
+	}
+	var name string
+	if json.Unmarshal(raw, &name) == nil {
+		if p, err := stdnet.LookupPort(proto, name); err == nil {
+			return uint32(p), true
+		}
+	}
+	return 0, false
+}
+
+// parsePortList parses "22", "1000:2000", or "22,80,8000:8080" with sep between range
+// bounds; it returns nil for anything it cannot read
+func parsePortList(s, sep string) []fwPortRange {
+	var ports []fwPortRange
+	for _, part := range strings.Split(s, ",") {
+		lo, hi, isRange := strings.Cut(part, sep)
+		if !isRange {
+			hi = lo
+		}
+		l, err1 := strconv.ParseUint(lo, 10, 16)
+		h, err2 := strconv.ParseUint(hi, 10, 16)
+		if err1 != nil || err2 != nil {
+			return nil
+		}
+		ports = append(ports, fwPortRange{uint32(l), uint32(h)})
+	}
+	return ports
+}
+
+// splitShellArgs splits an iptables-save line on spaces, keeping double-quoted values
+// such as comments together
+func splitShellArgs(line string) []string {
+	var args []string
+	var cur strings.Builder
+	inQuote, escaped := false, false
+	for _, c := range line {
+		switch {
+		case escaped:
+			cur.WriteRune(c)
+			escaped = false
+		case c == '\\' && inQuote:
+			escaped = true
+		case c == '"':
+			inQuote = !inQuote
+		case c == ' ' && !inQuote:
+			if cur.Len() > 0 {
+				args = append(args, cur.String())
+				cur.Reset()
+			}
+		default:
+			cur.WriteRune(c)
+		}
+	}
+	if cur.Len() > 0 {
+		args = append(args, cur.String())
+	}
+	return args
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"testing"
+)
+
+func TestParseIptablesSave(t *testing.T) {
+	out := `# Generated by iptables-save v1.8.9 (nf_tables)
+*nat
+:PREROUTING ACCEPT [0:0]
+-A PREROUTING -p tcp --dport 80 -j DNAT --to-destination 10.0.0.2:80
+COMMIT
+*filter
+:INPUT DROP [0:0]
+:FORWARD DROP [0:0]
+:OUTPUT ACCEPT [0:0]
+:ufw-user-input - [0:0]
+-A INPUT -i lo -j ACCEPT
+-A INPUT -m conntrack --ctstate RELATED,ESTABLISHED -j ACCEPT
+-A INPUT -m conntrack --ctstate INVALID -j DROP
+-A INPUT -j ufw-user-input
+-A ufw-user-input -p tcp -m tcp --dport 22 -m comment --comment "\"allow ssh\"" -j ACCEPT
+-A ufw-user-input -p tcp -m multiport --dports 80,443,8000:8010 -j ACCEPT
+-A ufw-user-input -s 192.168.1.0/24 -p tcp -m tcp --dport 5432 -j ACCEPT
+-A ufw-user-input -p udp -m udp --dport 53 -j REJECT --reject-with icmp-port-unreachable
+-A ufw-user-input -p udp -j RETURN
+COMMIT
+`
+	rs := parseIptablesSave(out)
+	if rs.inputPolicy() != "drop" || !rs.filters() {
+		t.Fatalf("Expected a filtering INPUT chain, got %+v", rs)
+	}
+	tests := []struct {
+		proto   string
+		port    uint32
+		verdict string
+	}{
+		{kindTCP, 22, fwAllowed},
+		{kindTCP, 443, fwAllowed},
+		{kindTCP, 8005, fwAllowed},
+		{kindTCP, 5432, fwRestricted},
+		{kindTCP, 6379, fwBlocked},
+		{kindUDP, 53, fwBlocked},
+		{kindUDP, 5353, fwBlocked},
+	}
+	for _, tt := range tests {
+		if got := rs.evaluate(tt.proto, tt.port); got.Verdict != tt.verdict {
+			t.Errorf("%s/%d: got %+v, expected %s", tt.proto, tt.port, got, tt.verdict)
+		}
+	}
+	if got := rs.evaluate(kindTCP, 5432); len(got.AllowedFrom) != 1 || got.AllowedFrom[0] != "192.168.1.0/24" {
+		t.Errorf("Expected 5432 to be limited to the LAN, got %+v", got)
+	}
+
+	open := parseIptablesSave("*filter\n:INPUT ACCEPT [0:0]\nCOMMIT\n")
+	if open.filters() || open.evaluate(kindTCP, 6379).Verdict != fwAllowed {
+		t.Error("Expected an empty ACCEPT chain to allow everything")
+	}
+}
+
+func TestParseNftRuleset(t *testing.T) {
+	out := []byte(`{"nftables": [
+  {"metainfo": {"version": "1.0.6"}},
+  {"table": {"family": "inet", "name": "filter"}},
+  {"chain": {"family": "inet", "table": "filter", "name": "input", "type": "filter", "hook": "input", "prio": 0, "policy": "drop"}},
+  {"chain": {"family": "inet", "table": "filter", "name": "services"}},
+  {"rule": {"family": "inet", "table": "filter", "chain": "input", "expr": [
+    {"match": {"op": "==", "left": {"meta": {"key": "iifname"}}, "right": "lo"}}, {"accept": null}]}},
+  {"rule": {"family": "inet", "table": "filter", "chain": "input", "expr": [
+    {"match": {"op": "in", "left": {"ct": {"key": "state"}}, "right": ["established", "related"]}}, {"accept": null}]}},
+  {"rule": {"family": "inet", "table": "filter", "chain": "input", "expr": [{"jump": {"target": "services"}}]}},
+  {"rule": {"family": "inet", "table": "filter", "chain": "services", "expr": [
+    {"match": {"op": "==", "left": {"payload": {"protocol": "tcp", "field": "dport"}}, "right": {"set": [22, {"range": [8080, 8090]}]}}},
+    {"counter": {"packets": 0, "bytes": 0}}, {"accept": null}]}},
+  {"rule": {"family": "inet", "table": "filter", "chain": "services", "expr": [
+    {"match": {"op": "==", "left": {"payload": {"protocol": "ip", "field": "saddr"}}, "right": {"prefix": {"addr": "10.0.0.0", "len": 8}}}},
+    {"match": {"op": "==", "left": {"payload": {"protocol": "tcp", "field": "dport"}}, "right": 1883}}, {"accept": null}]}},
+  {"table": {"family": "ip6", "name": "extra"}},
+  {"chain": {"family": "ip6", "table": "extra", "name": "in", "type": "filter", "hook": "input", "prio": 10, "policy": "accept"}},
+  {"rule": {"family": "ip6", "table": "extra", "chain": "in", "expr": [
+    {"match": {"op": "==", "left": {"payload": {"protocol": "tcp", "field": "dport"}}, "right": 22}}, {"drop": null}]}}
+]}`)
+	v4, v6, err := parseNftRuleset(out)
+	if err != nil {
+		t.Fatalf("parseNftRuleset failed: %v", err)
+	}
+	if v4.inputPolicy() != "drop" || len(v6.base) != 2 {
+		t.Fatalf("Unexpected base chains: v4 %v, v6 %v", v4.base, v6.base)
+	}
+	if got := v4.evaluate(kindTCP, 22); got.Verdict != fwAllowed {
+		t.Errorf("Expected SSH allowed over IPv4, got %+v", got)
+	}
+	if got := v4.evaluate(kindTCP, 8085); got.Verdict != fwAllowed {
+		t.Errorf("Expected the port range allowed, got %+v", got)
+	}
+	if got := v4.evaluate(kindTCP, 1883); got.Verdict != fwRestricted || got.AllowedFrom[0] != "10.0.0.0/8" {
+		t.Errorf("Expected MQTT limited to 10.0.0.0/8, got %+v", got)
+	}
+	if got := v4.evaluate(kindUDP, 53); got.Verdict != fwBlocked {
+		t.Errorf("Expected DNS blocked, got %+v", got)
+	}
+	// The second IPv6 base chain drops SSH even though the inet chain accepts it
+	if got := v6.evaluate(kindTCP, 22); got.Verdict != fwBlocked {
+		t.Errorf("Expected SSH blocked over IPv6, got %+v", got)
+	}
+
+	if _, _, err := parseNftRuleset([]byte("not json")); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+}
+
+func TestParsePortList(t *testing.T) {
+	if got := parsePortList("22,80,8000:8010", ":"); len(got) != 3 || got[2] != (fwPortRange{8000, 8010}) {
+		t.Errorf("Unexpected ports: %v", got)
+	}
+	if got := parsePortList("ssh", ":"); got != nil {
+		t.Errorf("Expected nil for a service name, got %v", got)
+	}
+}
@@ -0,0 +1,383 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"sysmetrics-mcp/internal/grpcapi"
+	"sysmetrics-mcp/internal/history"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Fleet query defaults
+const (
+	fleetTimeout        = 5 * time.Second
+	fleetSkewWarning    = 2 * time.Second
+	defaultOutlierScore = 3.0
+	minOutlierHosts     = 3
+	outlierMinSpread    = 1.0
+	madToStdDev         = 1.4826
+	fleetLocalAddr      = "local"
+)
+
+// defaultOutlierMetrics are compared by find_outlier_hosts when no metrics are given.
+// Network counters are cumulative since boot, so they are left out.
+var defaultOutlierMetrics = []string{
+	"cpu_percent", "load1", "memory_used_percent", "swap_used_percent", "disk_used_percent", "cpu_temperature_celsius",
+}
+
+// fleetHost is one host's answer to a fleet query. Sample times are on the local clock.
+type fleetHost struct {
+	Name        string
+	Addr        string
+	ClockOffset time.Duration
+	Samples     []history.Sample
+	Err         error
+}
+
+// fleetValue is one host's value of a series in compare_hosts
+type fleetValue struct {
+	Host          string            `json:"host"`
+	Addr          string            `json:"addr"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	Value         float64           `json:"value"`
+	Time          time.Time         `json:"time"`
+	ClockOffsetMS int64             `json:"clock_offset_ms,omitempty"`
+}
+
+// fleetOutlier is a host whose value of a series is far from the fleet median
+type fleetOutlier struct {
+	Host        string            `json:"host"`
+	Metric      string            `json:"metric"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Value       float64           `json:"value"`
+	FleetMedian float64           `json:"fleet_median"`
+	Deviation   float64           `json:"deviation"`
+	Score       float64           `json:"score"`
+	Direction   string            `json:"direction"`
+}
+
+// SetFleet enables compare_hosts and find_outlier_hosts, which query these agents over
+// gRPC alongside this host
+func (h *HandlerManager) SetFleet(agents []*grpcapi.Client) {
+	h.fleet = agents
+}
+
+// pollFleet collects the given metrics (all when empty) from this host and every agent
+// concurrently. Agent timestamps are normalized onto the local clock.
+func (h *HandlerManager) pollFleet(ctx context.Context, metrics []string) []fleetHost {
+	hosts := make([]fleetHost, len(h.fleet)+1)
+	hostname, _ := os.Hostname()
+	hosts[0] = fleetHost{Name: hostname, Addr: fleetLocalAddr}
+	for _, s := range h.collectSamples(ctx, time.Now()) {
+		if len(metrics) == 0 || contains(metrics, s.Metric) {
+			hosts[0].Samples = append(hosts[0].Samples, s)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i, agent := range h.fleet {
+		wg.Go(func() {
+			hosts[i+1] = pollAgent(ctx, agent, metrics)
+		})
+	}
+	wg.Wait()
+
+	// Hosts cloned from one image often share a hostname; tell them apart by address
+	names := map[string]int{}
+	for _, host := range hosts {
+		names[host.Name]++
+	}
+	for i, host := range hosts {
+		if names[host.Name] > 1 {
+			hosts[i].Name = fmt.Sprintf("%s (%s)", host.Name, host.Addr)
+		}
+	}
+	return hosts
+}
+
+// pollAgent asks one agent for its clock and samples
+func pollAgent(ctx context.Context, agent *grpcapi.Client, metrics []string) fleetHost {
+	ctx, cancel := context.WithTimeout(ctx, fleetTimeout)
+	defer cancel()
+
+	host := fleetHost{Name: agent.Addr(), Addr: agent.Addr()}
+	info, sent, received, err := agent.GetInfo(ctx)
+	if err != nil {
+		host.Err = err
+		return host
+	}
+	if info.GetHostname() != "" {
+		host.Name = info.GetHostname()
+	}
+	if info.GetServerTimeMs() != 0 {
+		host.ClockOffset, _ = grpcapi.ClockOffset(sent, received, info.GetServerTimeMs())
+	}
+	samples, err := agent.GetSamples(ctx, metrics)
+	if err != nil {
+		host.Err = err
+		return host
+	}
+	for _, s := range samples {
+		host.Samples = append(host.Samples, history.Sample{
+			Time:   grpcapi.Normalize(time.UnixMilli(s.GetTimestampMs()), host.ClockOffset),
+			Metric: s.GetMetric(),
+			Labels: s.GetLabels(),
+			Value:  s.GetValue(),
+		})
+	}
+	return host
+}
+
+// fleetStatus summarizes which hosts answered and whose clocks are skewed
+func fleetStatus(hosts []fleetHost) (unreachable map[string]string, warnings []string) {
+	unreachable = map[string]string{}
+	for _, host := range hosts {
+		if host.Err != nil {
+			unreachable[host.Addr] = host.Err.Error()
+			continue
+		}
+		if host.ClockOffset.Abs() > fleetSkewWarning {
+			warnings = append(warnings, fmt.Sprintf("%s's clock is %s off; its timestamps were corrected", host.Name, host.ClockOffset.Round(time.Millisecond)))
+		}
+	}
+	return unreachable, warnings
+}
+
+// HandleCompareHosts reports one metric from every fleet host side by side, highest first
+func (h *HandlerManager) HandleCompareHosts(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var metric, labelsStr string
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if m, ok := args["metric"].(string); ok {
+			metric = strings.TrimSpace(m)
+		}
+		if l, ok := args["labels"].(string); ok {
+			labelsStr = l
+		}
+	}
+	if metric == "" {
+		return mcp.NewToolResultError("metric is required, e.g. cpu_percent or disk_used_percent"), nil
+	}
+	filter, err := history.ParseLabels(labelsStr)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid labels: %v", err)), nil
+	}
+
+	hosts := h.pollFleet(ctx, []string{metric})
+	values := []fleetValue{}
+	for _, host := range hosts {
+		for _, s := range host.Samples {
+			if s.Metric != metric || !labelsMatch(s.Labels, filter) {
+				continue
+			}
+			values = append(values, fleetValue{
+				Host:          host.Name,
+				Addr:          host.Addr,
+				Labels:        s.Labels,
+				Value:         round2(s.Value),
+				Time:          s.Time,
+				ClockOffsetMS: host.ClockOffset.Milliseconds(),
+			})
+		}
+	}
+	sort.SliceStable(values, func(i, j int) bool { return values[i].Value > values[j].Value })
+
+	unreachable, warnings := fleetStatus(hosts)
+	result := map[string]interface{}{
+		"metric": metric,
+		"hosts":  len(hosts),
+		"values": values,
+	}
+	if len(values) > 0 {
+		nums := make([]float64, len(values))
+		for i, v := range values {
+			nums[i] = v.Value
+		}
+		result["max"] = values[0]
+		result["min"] = values[len(values)-1]
+		result["median"] = round2(median(nums))
+	} else {
+		result["note"] = fmt.Sprintf("No host reported %s; sampled metrics include %s", metric, strings.Join(defaultOutlierMetrics, ", "))
+	}
+	if len(unreachable) > 0 {
+		result["unreachable"] = unreachable
+	}
+	if len(warnings) > 0 {
+		result["warnings"] = warnings
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// HandleFindOutlierHosts ranks the hosts whose metrics deviate most from the fleet median.
+// Deviation is a robust z-score: the distance from the median over 1.4826 times the
+// median absolute deviation, floored so a near-identical fleet does not flag tiny
+// differences.
+func (h *HandlerManager) HandleFindOutlierHosts(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	metrics := defaultOutlierMetrics
+	threshold := defaultOutlierScore
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if m, ok := args["metrics"].(string); ok && strings.TrimSpace(m) != "" {
+			metrics = nil
+			for _, name := range strings.Split(m, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					metrics = append(metrics, name)
+				}
+			}
+		}
+		if t, ok := args["threshold"].(float64); ok {
+			if t <= 0 {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid threshold: %v (must be greater than 0)", t)), nil
+			}
+			threshold = t
+		}
+	}
+
+	hosts := h.pollFleet(ctx, metrics)
+
+	// Group values by series so each mount or interface is compared with its namesakes
+	type seriesValue struct {
+		host  string
+		value float64
+	}
+	type seriesKey struct{ metric, labels string }
+	groups := map[seriesKey][]seriesValue{}
+	labelsOf := map[seriesKey]map[string]string{}
+	for _, host := range hosts {
+		for _, s := range host.Samples {
+			key := seriesKey{s.Metric, history.FormatLabels(s.Labels)}
+			groups[key] = append(groups[key], seriesValue{host.Name, s.Value})
+			labelsOf[key] = s.Labels
+		}
+	}
+
+	outliers := []fleetOutlier{}
+	compared := 0
+	for key, group := range groups {
+		if len(group) < minOutlierHosts {
+			continue
+		}
+		compared++
+		nums := make([]float64, len(group))
+		for i, v := range group {
+			nums[i] = v.value
+		}
+		mid := median(nums)
+		deviations := make([]float64, len(nums))
+		for i, v := range nums {
+			deviations[i] = math.Abs(v - mid)
+		}
+		spread := math.Max(madToStdDev*median(deviations), outlierMinSpread)
+		for _, v := range group {
+			score := (v.value - mid) / spread
+			if math.Abs(score) < threshold {
+				continue
+			}
+			direction := "above"
+			if score < 0 {
+				direction = "below"
+			}
+			outliers = append(outliers, fleetOutlier{
+				Host:        v.host,
+				Metric:      key.metric,
+				Labels:      labelsOf[key],
+				Value:       round2(v.value),
+				FleetMedian: round2(mid),
+				Deviation:   round2(v.value - mid),
+				Score:       round2(math.Abs(score)),
+				Direction:   direction,
+			})
+		}
+	}
+	sort.Slice(outliers, func(i, j int) bool {
+		if outliers[i].Score != outliers[j].Score {
+			return outliers[i].Score > outliers[j].Score
+		}
+		return outliers[i].Host < outliers[j].Host
+	})
+
+	// Rank hosts by their worst deviation
+	ranking := []map[string]interface{}{}
+	seen := map[string]bool{}
+	for _, o := range outliers {
+		if seen[o.Host] {
+			continue
+		}
+		seen[o.Host] = true
+		var series []string
+		for _, other := range outliers {
+			if other.Host == o.Host {
+				series = append(series, other.Metric+labelSuffix(other.Labels))
+			}
+		}
+		ranking = append(ranking, map[string]interface{}{"host": o.Host, "max_score": o.Score, "outlying": series})
+	}
+
+	unreachable, warnings := fleetStatus(hosts)
+	result := map[string]interface{}{
+		"hosts":           len(hosts),
+		"threshold":       threshold,
+		"series_compared": compared,
+		"outlier_hosts":   ranking,
+		"outliers":        outliers,
+	}
+	if compared == 0 {
+		result["note"] = fmt.Sprintf("Outliers need at least %d hosts reporting the same series", minOutlierHosts)
+	}
+	if len(unreachable) > 0 {
+		result["unreachable"] = unreachable
+	}
+	if len(warnings) > 0 {
+		result["warnings"] = warnings
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// labelsMatch reports whether labels contain every pair in filter
+func labelsMatch(labels, filter map[string]string) bool {
+	for k, v := range filter {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// labelSuffix formats labels as {k=v} for naming a series, or "" without labels
+func labelSuffix(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	return "{" + history.FormatLabels(labels) + "}"
+}
+
+// median returns the middle value of nums, averaging the middle two of an even count
+func median(nums []float64) float64 {
+	if len(nums) == 0 {
+		return 0
+	}
+	sorted := append([]float64{}, nums...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
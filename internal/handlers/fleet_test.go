@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"sysmetrics-mcp/internal/config"
+	"sysmetrics-mcp/internal/grpcapi"
+	"sysmetrics-mcp/internal/history"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// fleetAgent serves fixed samples over gRPC
+type fleetAgent struct {
+	samples []history.Sample
+}
+
+func (a *fleetAgent) CollectSamples(ctx context.Context) []history.Sample { return a.samples }
+func (a *fleetAgent) Tools() []string                                     { return nil }
+func (a *fleetAgent) ReadOnly(name string) bool                           { return true }
+func (a *fleetAgent) CallTool(ctx context.Context, caller, name string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	return mcp.NewToolResultError("not supported"), nil
+}
+
+// startFleet runs one agent per value of queue_depth and returns clients for them plus
+// one agent that is down
+func startFleet(t *testing.T, values ...float64) []*grpcapi.Client {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	var clients []*grpcapi.Client
+	for _, v := range values {
+		agent := &fleetAgent{samples: []history.Sample{
+			{Time: time.Now(), Metric: "queue_depth", Labels: map[string]string{"queue": "jobs"}, Value: v},
+		}}
+		s, err := grpcapi.Listen(grpcapi.Options{Addr: "127.0.0.1:0", Backend: agent})
+		if err != nil {
+			t.Fatal(err)
+		}
+		go func() { _ = s.Run(ctx) }()
+		clients = append(clients, newFleetClient(t, s.Addr().String()))
+	}
+
+	// A port that was just freed is refused
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	down := ln.Addr().String()
+	_ = ln.Close()
+	return append(clients, newFleetClient(t, down))
+}
+
+// newFleetClient returns a client for addr that is closed when the test ends
+func newFleetClient(t *testing.T, addr string) *grpcapi.Client {
+	t.Helper()
+	c, err := grpcapi.NewClient(addr, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func TestFleetToolsRegistration(t *testing.T) {
+	h := NewHandlerManager(&config.Config{})
+	h.RegisterTools(server.NewMCPServer("test", "1.0.0"))
+	if h.skippedTools["compare_hosts"] == "" || h.skippedTools["find_outlier_hosts"] == "" {
+		t.Error("Expected the fleet tools to be skipped without --fleet-hosts")
+	}
+
+	h = NewHandlerManager(&config.Config{})
+	h.SetFleet([]*grpcapi.Client{newFleetClient(t, "127.0.0.1:1")})
+	h.RegisterTools(server.NewMCPServer("test", "1.0.0"))
+	if !contains(h.Tools(), "compare_hosts") || !contains(h.Tools(), "find_outlier_hosts") {
+		t.Errorf("Expected the fleet tools among %v", h.Tools())
+	}
+}
+
+func TestHandleCompareHosts(t *testing.T) {
+	h := NewHandlerManager(&config.Config{})
+	h.SetFleet(startFleet(t, 3, 9, 5))
+	ctx := context.Background()
+
+	req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]interface{}{"metric": "queue_depth", "labels": "queue=jobs"}}}
+	res, err := h.HandleCompareHosts(ctx, req)
+	checkToolResult(t, res, err, []string{"metric", "hosts", "values", "min", "max", "median", "unreachable"})
+
+	var result struct {
+		Hosts       int               `json:"hosts"`
+		Values      []fleetValue      `json:"values"`
+		Median      float64           `json:"median"`
+		Unreachable map[string]string `json:"unreachable"`
+	}
+	if err := json.Unmarshal([]byte(resultText(res)), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Hosts != 5 || len(result.Values) != 3 || result.Values[0].Value != 9 || result.Values[2].Value != 3 || result.Median != 5 {
+		t.Errorf("Expected 3 values sorted high to low with median 5, got %+v", result)
+	}
+	if len(result.Unreachable) != 1 {
+		t.Errorf("Expected the stopped agent to be unreachable, got %v", result.Unreachable)
+	}
+	// Agents share this machine's hostname, so their addresses tell them apart
+	if result.Values[0].Host == result.Values[1].Host {
+		t.Errorf("Expected distinct host names, got %+v", result.Values)
+	}
+
+	req.Params.Arguments = map[string]interface{}{"metric": "queue_depth", "labels": "queue=other"}
+	res, err = h.HandleCompareHosts(ctx, req)
+	checkToolResult(t, res, err, []string{"values", "note"})
+
+	for _, args := range []map[string]interface{}{{}, {"metric": "queue_depth", "labels": "broken"}} {
+		req.Params.Arguments = args
+		res, _ = h.HandleCompareHosts(ctx, req)
+		if !res.IsError {
+			t.Errorf("Expected an error for %v", args)
+		}
+	}
+}
+
+func TestHandleFindOutlierHosts(t *testing.T) {
+	h := NewHandlerManager(&config.Config{})
+	h.SetFleet(startFleet(t, 10, 11, 10.5, 12, 50))
+	ctx := context.Background()
+
+	req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]interface{}{"metrics": "queue_depth"}}}
+	res, err := h.HandleFindOutlierHosts(ctx, req)
+	checkToolResult(t, res, err, []string{"hosts", "threshold", "series_compared", "outlier_hosts", "outliers"})
+
+	var result struct {
+		Compared int            `json:"series_compared"`
+		Outliers []fleetOutlier `json:"outliers"`
+	}
+	if err := json.Unmarshal([]byte(resultText(res)), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Compared != 1 || len(result.Outliers) != 1 {
+		t.Fatalf("Expected one outlier in one series, got %+v", result)
+	}
+	if o := result.Outliers[0]; o.Value != 50 || o.FleetMedian != 11 || o.Direction != "above" || o.Labels["queue"] != "jobs" {
+		t.Errorf("Unexpected outlier: %+v", o)
+	}
+
+	// Too few hosts to compare
+	h.SetFleet(startFleet(t, 1, 100))
+	res, err = h.HandleFindOutlierHosts(ctx, req)
+	checkToolResult(t, res, err, []string{"series_compared", "note"})
+
+	req.Params.Arguments = map[string]interface{}{"threshold": float64(-1)}
+	if res, _ = h.HandleFindOutlierHosts(ctx, req); !res.IsError {
+		t.Error("Expected an error for a negative threshold")
+	}
+}
+
+func TestMedian(t *testing.T) {
+	tests := []struct {
+		nums []float64
+		want float64
+	}{
+		{nil, 0},
+		{[]float64{7}, 7},
+		{[]float64{3, 1, 2}, 2},
+		{[]float64{4, 1, 3, 2}, 2.5},
+	}
+	for _, tt := range tests {
+		if got := median(tt.nums); got != tt.want {
+			t.Errorf("median(%v) = %v, want %v", tt.nums, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"sysmetrics-mcp/internal/history"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+// Disk forecast defaults
+const (
+	defaultForecastRange  = 7 * 24 * time.Hour
+	minForecastSamples    = 10
+	minForecastSpan       = time.Hour
+	forecastFlatPerDay    = 0.01
+	forecastHorizonDays   = 3650
+	forecastLowConfidence = 0.5
+	forecastModelAuto     = "auto"
+)
+
+// forecastTargets are the usage thresholds a forecast reports, in percent
+var forecastTargets = []float64{90, 100}
+
+// diskForecast is the usage forecast of one mount point
+type diskForecast struct {
+	Mount               string                 `json:"mount"`
+	CurrentPercent      float64                `json:"current_percent"`
+	Samples             int                    `json:"samples"`
+	ObservedSince       time.Time              `json:"observed_since"`
+	Model               string                 `json:"model"`
+	RSquared            float64                `json:"r_squared"`
+	Confidence          string                 `json:"confidence"`
+	Trend               string                 `json:"trend"`
+	GrowthPercentPerDay float64                `json:"growth_percent_per_day"`
+	GrowthBytesPerDay   string                 `json:"growth_per_day_human,omitempty"`
+	Thresholds          []forecastThreshold    `json:"thresholds"`
+	Alternative         map[string]interface{} `json:"alternative,omitempty"`
+}
+
+// forecastThreshold is when a mount is expected to reach one usage level
+type forecastThreshold struct {
+	Percent   float64    `json:"percent"`
+	Reached   bool       `json:"reached,omitempty"`
+	Days      *float64   `json:"days,omitempty"`
+	ETA       *time.Time `json:"eta,omitempty"`
+	DaysHuman string     `json:"days_human,omitempty"`
+}
+
+// HandleForecastDiskUsage fits a linear or exponential trend to each mount's sampled disk
+// usage and estimates the days until it reaches 90% and 100%
+func (h *HandlerManager) HandleForecastDiskUsage(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if h.history == nil {
+		return mcp.NewToolResultError("Metrics history is disabled (start the server with --history-db)"), nil
+	}
+
+	var mount string
+	rangeDur := defaultForecastRange
+	model := forecastModelAuto
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if m, ok := args["mount"].(string); ok {
+			mount = strings.TrimSpace(m)
+		}
+		if r, ok := args["range"].(string); ok && r != "" {
+			d, err := parseHistoryDuration(r)
+			if err != nil || d <= 0 {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid range: %q (use a duration such as 24h, 7d, or 30d)", r)), nil
+			}
+			rangeDur = d
+		}
+		if m, ok := args["model"].(string); ok && m != "" {
+			model = strings.ToLower(strings.TrimSpace(m))
+			if model != forecastModelAuto && model != history.TrendLinear && model != history.TrendExponential {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid model: %q (must be auto, linear, or exponential)", m)), nil
+			}
+		}
+	}
+
+	now := time.Now()
+	series, err := h.history.Raw(ctx, "disk_used_percent", now.Add(-rangeDur), now)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read metrics history: %v", err)), nil
+	}
+
+	forecasts := []diskForecast{}
+	insufficient := []string{}
+	for _, s := range series {
+		labels, err := history.ParseLabels(s.Labels)
+		if err != nil {
+			continue
+		}
+		m := labels["mount"]
+		if mount != "" && m != mount {
+			continue
+		}
+		f, ok := h.forecastMount(ctx, m, s.Points, model, now)
+		if !ok {
+			insufficient = append(insufficient, m)
+			continue
+		}
+		forecasts = append(forecasts, f)
+	}
+
+	result := map[string]interface{}{
+		"range":     rangeDur.String(),
+		"model":     model,
+		"forecasts": forecasts,
+	}
+	if len(insufficient) > 0 {
+		result["insufficient_history"] = insufficient
+		result["note"] = fmt.Sprintf("Forecasts need at least %d samples spanning %s", minForecastSamples, minForecastSpan)
+	}
+	if len(forecasts) == 0 && len(insufficient) == 0 {
+		if mount != "" {
+			result["note"] = fmt.Sprintf("No disk usage history for %s; only --mount-points (or the root filesystem) are sampled", mount)
+		} else {
+			result["note"] = "No disk usage history in range; the sampler needs to run for a while first"
+		}
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// forecastMount fits the requested model (or the better-fitting one for auto) to a mount's
+// usage. ok is false when there are too few samples or they span too little time.
+func (h *HandlerManager) forecastMount(ctx context.Context, mount string, points []history.Point, model string, now time.Time) (f diskForecast, ok bool) {
+	if len(points) < minForecastSamples || points[len(points)-1].Time.Sub(points[0].Time) < minForecastSpan {
+		return f, false
+	}
+	linear, linearOK := history.FitLinear(points)
+	exponential, exponentialOK := history.FitExponential(points)
+
+	var trend, other history.Trend
+	switch {
+	case model == history.TrendLinear && linearOK:
+		trend = linear
+	case model == history.TrendExponential && exponentialOK:
+		trend = exponential
+	case model == forecastModelAuto && linearOK && exponentialOK:
+		trend, other = linear, exponential
+		if exponential.RSquared > linear.RSquared {
+			trend, other = exponential, linear
+		}
+	case linearOK:
+		// An exponential fit needs every sample above zero
+		trend = linear
+	default:
+		return f, false
+	}
+
+	current := points[len(points)-1].Value
+	perDay := trend.RatePerDay(now)
+	f = diskForecast{
+		Mount:               mount,
+		CurrentPercent:      round2(current),
+		Samples:             len(points),
+		ObservedSince:       points[0].Time,
+		Model:               trend.Model,
+		RSquared:            round2(trend.RSquared),
+		Confidence:          "high",
+		Trend:               "growing",
+		GrowthPercentPerDay: round2(perDay),
+		Thresholds:          []forecastThreshold{},
+	}
+	if trend.RSquared < forecastLowConfidence {
+		f.Confidence = "low"
+	}
+	switch {
+	case math.Abs(perDay) < forecastFlatPerDay:
+		f.Trend = "flat"
+	case perDay < 0:
+		f.Trend = "shrinking"
+	}
+	if usage, err := disk.UsageWithContext(ctx, mount); err == nil && usage.Total > 0 {
+		//nolint:gosec // G115: a daily change is far below int64 range
+		f.GrowthBytesPerDay = h.signedBytes(int64(perDay / 100 * float64(usage.Total)))
+	}
+	if other.Model != "" {
+		f.Alternative = map[string]interface{}{"model": other.Model, "r_squared": round2(other.RSquared)}
+	}
+
+	for _, target := range forecastTargets {
+		th := forecastThreshold{Percent: target}
+		if current >= target {
+			th.Reached = true
+			f.Thresholds = append(f.Thresholds, th)
+			continue
+		}
+		if f.Trend == "growing" {
+			if eta, ok := trend.When(target); ok {
+				if eta.Before(now) {
+					eta = now
+				}
+				days := round2(eta.Sub(now).Hours() / 24)
+				if days <= forecastHorizonDays {
+					th.Days = &days
+					th.ETA = &eta
+					th.DaysHuman = h.human.Duration(eta.Sub(now))
+				}
+			}
+		}
+		f.Thresholds = append(f.Thresholds, th)
+	}
+	return f, true
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"sysmetrics-mcp/internal/history"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleForecastDiskUsage(t *testing.T) {
+	h := newHistoryTestManager(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	// /data grows 1 point per day from 80%; /flat holds steady; /new has two samples
+	var samples []history.Sample
+	for i := 0; i <= 48; i++ {
+		ts := now.Add(time.Duration(i-48) * time.Hour)
+		samples = append(samples,
+			history.Sample{Time: ts, Metric: "disk_used_percent", Labels: map[string]string{"mount": "/data"}, Value: 80 + float64(i)/24},
+			history.Sample{Time: ts, Metric: "disk_used_percent", Labels: map[string]string{"mount": "/flat"}, Value: 40},
+		)
+	}
+	samples = append(samples,
+		history.Sample{Time: now.Add(-time.Minute), Metric: "disk_used_percent", Labels: map[string]string{"mount": "/new"}, Value: 10},
+		history.Sample{Time: now, Metric: "disk_used_percent", Labels: map[string]string{"mount": "/new"}, Value: 11},
+	)
+	if err := h.history.Write(ctx, samples); err != nil {
+		t.Fatal(err)
+	}
+
+	req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]interface{}{"model": "linear"}}}
+	res, err := h.HandleForecastDiskUsage(ctx, req)
+	checkToolResult(t, res, err, []string{"range", "model", "forecasts", "insufficient_history"})
+
+	var result struct {
+		Forecasts    []diskForecast `json:"forecasts"`
+		Insufficient []string       `json:"insufficient_history"`
+	}
+	if err := json.Unmarshal([]byte(resultText(res)), &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Forecasts) != 2 || len(result.Insufficient) != 1 || result.Insufficient[0] != "/new" {
+		t.Fatalf("Expected forecasts for /data and /flat with /new insufficient, got %+v", result)
+	}
+
+	data := result.Forecasts[0]
+	if data.Mount != "/data" || data.Trend != "growing" || data.Model != history.TrendLinear || math.Abs(data.GrowthPercentPerDay-1) > 0.01 {
+		t.Errorf("Unexpected /data forecast: %+v", data)
+	}
+	// 82% now at 1 point per day: 8 days to 90% and 18 to full
+	if len(data.Thresholds) != 2 || data.Thresholds[0].Days == nil || math.Abs(*data.Thresholds[0].Days-8) > 0.05 ||
+		data.Thresholds[1].Days == nil || math.Abs(*data.Thresholds[1].Days-18) > 0.05 {
+		t.Errorf("Unexpected /data thresholds: %+v", data.Thresholds)
+	}
+
+	flat := result.Forecasts[1]
+	if flat.Trend != "flat" || flat.Thresholds[0].Days != nil {
+		t.Errorf("Expected no ETA for a flat mount, got %+v", flat)
+	}
+
+	req.Params.Arguments = map[string]interface{}{"mount": "/data"}
+	res, err = h.HandleForecastDiskUsage(ctx, req)
+	checkToolResult(t, res, err, []string{"forecasts"})
+	result.Forecasts = nil
+	if err := json.Unmarshal([]byte(resultText(res)), &result); err != nil || len(result.Forecasts) != 1 || result.Forecasts[0].Alternative == nil {
+		t.Errorf("Expected one auto forecast with an alternative model, got %+v", result.Forecasts)
+	}
+
+	for _, args := range []map[string]interface{}{{"range": "forever"}, {"model": "cubic"}} {
+		req.Params.Arguments = args
+		res, _ = h.HandleForecastDiskUsage(ctx, req)
+		if !res.IsError {
+			t.Errorf("Expected an error for %v", args)
+		}
+	}
+}
@@ -0,0 +1,440 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Output formats accepted by the format argument of every tool
+const (
+	formatJSON     = "json"
+	formatMarkdown = "markdown"
+	formatSummary  = "summary"
+)
+
+// outputFormats lists the values of the format argument
+var outputFormats = []string{formatJSON, formatMarkdown, formatSummary}
+
+// maxSummaryFacts bounds a summary; the remaining fields are only counted
+const maxSummaryFacts = 40
+
+// summaryListItems is how many entries of a list a summary names
+const summaryListItems = 3
+
+// labelKeys are the fields that name a list entry in a summary, in order of preference
+var labelKeys = []string{"name", "id", "mount", "mountpoint", "interface", "unit", "device", "tool", "metric", "path", "address", "pid"}
+
+// withFormatArg adds the format argument to a tool's schema, unless the tool renders
+// formats itself
+func withFormatArg(tool mcp.Tool) mcp.Tool {
+	if _, ok := tool.InputSchema.Properties["format"]; ok {
+		return tool
+	}
+	mcp.WithString("format",
+		mcp.Description("Output format: json (compact JSON), markdown (lists as tables), or summary (one paragraph); markdown and summary use far fewer tokens (default: json)"),
+		mcp.Enum(outputFormats...))(&tool)
+	return tool
+}
+
+// formatTool renders a tool's JSON result in the format the caller asked for. A tool with
+// a format argument of its own renders json and markdown itself and gets json when a
+// summary is asked for. It reads the tool's schema before addTool adds the shared format
+// argument.
+func (h *HandlerManager) formatTool(tool mcp.Tool, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	_, ownFormat := tool.InputSchema.Properties["format"]
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		format := formatJSON
+		args, _ := request.Params.Arguments.(map[string]interface{})
+		if f, ok := args["format"].(string); ok && f != "" {
+			format = strings.ToLower(f)
+		}
+		if !contains(outputFormats, format) {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid format: %q (must be %s)", format, strings.Join(outputFormats, ", "))), nil
+		}
+		if ownFormat {
+			if format != formatSummary {
+				return next(ctx, request)
+			}
+			args = maps.Clone(args)
+			args["format"] = formatJSON
+			request.Params.Arguments = args
+		}
+		if format == formatJSON {
+			return next(ctx, request)
+		}
+
+		result, err := next(ctx, request)
+		if err != nil || result == nil || result.IsError || len(result.Content) != 1 {
+			return result, err
+		}
+		text, ok := result.Content[0].(mcp.TextContent)
+		if !ok {
+			return result, nil
+		}
+		obj, err := decodeJSONObject(text.Text)
+		if err != nil {
+			return result, nil
+		}
+		if format == formatMarkdown {
+			return mcp.NewToolResultText(renderMarkdown(obj)), nil
+		}
+		return mcp.NewToolResultText(renderSummary(obj)), nil
+	}
+}
+
+// jsonObject is a decoded JSON object that keeps its key order, so rendered results list
+// fields in the order the tool wrote them
+type jsonObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// set stores value under key, appending key if the object does not have it yet
+func (o *jsonObject) set(key string, value interface{}) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// MarshalJSON encodes the object with its keys in their original order. Like the tools'
+// own output, strings are not HTML-escaped.
+func (o *jsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	buf.WriteByte('{')
+	for i, k := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := enc.Encode(k); err != nil {
+			return nil, err
+		}
+		buf.Truncate(buf.Len() - 1)
+		buf.WriteByte(':')
+		if err := enc.Encode(o.values[k]); err != nil {
+			return nil, err
+		}
+		buf.Truncate(buf.Len() - 1)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// decodeJSON decodes a result that is a single JSON value, with objects as *jsonObject.
+// Numbers are kept as json.Number so they render exactly as the tool wrote them.
+func decodeJSON(text string) (interface{}, error) {
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.UseNumber()
+	v, err := decodeOrdered(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("trailing data after the JSON value")
+	}
+	return v, nil
+}
+
+// decodeJSONObject decodes a result that is a single JSON object
+func decodeJSONObject(text string) (*jsonObject, error) {
+	v, err := decodeJSON(text)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := v.(*jsonObject)
+	if !ok {
+		return nil, errors.New("result is not a JSON object")
+	}
+	return obj, nil
+}
+
+// decodeOrdered decodes the next JSON value, with objects as *jsonObject and arrays as
+// []interface{}
+func decodeOrdered(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return tok, nil
+	}
+	switch delim {
+	case '{':
+		obj := &jsonObject{values: map[string]interface{}{}}
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, _ := keyTok.(string)
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			if _, dup := obj.values[key]; !dup {
+				obj.keys = append(obj.keys, key)
+			}
+			obj.values[key] = value
+		}
+		_, err := dec.Token()
+		return obj, err
+	case '[':
+		list := []interface{}{}
+		for dec.More() {
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		_, err := dec.Token()
+		return list, err
+	}
+	return nil, fmt.Errorf("unexpected %v", delim)
+}
+
+// isScalar reports whether v is a string, number, boolean, or null
+func isScalar(v interface{}) bool {
+	switch v.(type) {
+	case *jsonObject, []interface{}:
+		return false
+	}
+	return true
+}
+
+// inlineValue renders v on one line: objects as "key: value" pairs, lists as
+// comma-separated values, and numbers rounded to two decimal places
+func inlineValue(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case json.Number:
+		// Fractions are cut to two places; the exact values stay available as json
+		if strings.ContainsAny(t.String(), ".eE") {
+			if f, err := t.Float64(); err == nil {
+				return strconv.FormatFloat(round2(f), 'f', -1, 64)
+			}
+		}
+		return t.String()
+	case bool:
+		if t {
+			return "true"
+		}
+		return "false"
+	case *jsonObject:
+		parts := make([]string, 0, len(t.keys))
+		for _, k := range t.keys {
+			parts = append(parts, k+": "+inlineValue(t.values[k]))
+		}
+		return strings.Join(parts, ", ")
+	case []interface{}:
+		parts := make([]string, 0, len(t))
+		for _, item := range t {
+			if obj, ok := item.(*jsonObject); ok {
+				parts = append(parts, "("+inlineValue(obj)+")")
+			} else {
+				parts = append(parts, inlineValue(item))
+			}
+		}
+		return strings.Join(parts, ", ")
+	}
+	return fmt.Sprint(v)
+}
+
+// markdownList is a list rendered as its own section
+type markdownList struct {
+	title string
+	items []interface{}
+}
+
+// renderMarkdown renders an object as a bullet list of its fields, with nested objects
+// flattened to dotted names and lists of objects as tables
+func renderMarkdown(obj *jsonObject) string {
+	var b strings.Builder
+	var lists []markdownList
+	writeMarkdownFields(&b, "", obj, &lists)
+	for _, l := range lists {
+		fmt.Fprintf(&b, "\n### %s (%d)\n\n", l.title, len(l.items))
+		writeMarkdownTable(&b, l.items)
+	}
+	return strings.TrimSpace(b.String()) + "\n"
+}
+
+// writeMarkdownFields writes the scalar fields of obj as bullets and collects lists that
+// need a section of their own
+func writeMarkdownFields(b *strings.Builder, prefix string, obj *jsonObject, lists *[]markdownList) {
+	for _, k := range obj.keys {
+		name := prefix + k
+		switch v := obj.values[k].(type) {
+		case *jsonObject:
+			writeMarkdownFields(b, name+".", v, lists)
+		case []interface{}:
+			if len(v) == 0 {
+				fmt.Fprintf(b, "- **%s**: none\n", name)
+				continue
+			}
+			allScalar := true
+			for _, item := range v {
+				allScalar = allScalar && isScalar(item)
+			}
+			if allScalar {
+				fmt.Fprintf(b, "- **%s**: %s\n", name, inlineValue(v))
+				continue
+			}
+			*lists = append(*lists, markdownList{title: name, items: v})
+		case nil:
+		default:
+			fmt.Fprintf(b, "- **%s**: %s\n", name, inlineValue(v))
+		}
+	}
+}
+
+// writeMarkdownTable writes a list of objects as a table with a column for every key, in
+// order of first appearance. Entries that are not objects go in a single value column.
+func writeMarkdownTable(b *strings.Builder, items []interface{}) {
+	var columns []string
+	seen := map[string]bool{}
+	hasOther := false
+	for _, item := range items {
+		obj, ok := item.(*jsonObject)
+		if !ok {
+			hasOther = true
+			continue
+		}
+		for _, k := range obj.keys {
+			if !seen[k] {
+				seen[k] = true
+				columns = append(columns, k)
+			}
+		}
+	}
+	if hasOther {
+		columns = append(columns, "value")
+	}
+
+	writeRow := func(cells []string) {
+		b.WriteString("|")
+		for _, c := range cells {
+			b.WriteString(" " + c + " |")
+		}
+		b.WriteString("\n")
+	}
+	writeRow(columns)
+	separators := make([]string, len(columns))
+	for i := range separators {
+		separators[i] = "---"
+	}
+	writeRow(separators)
+	for _, item := range items {
+		cells := make([]string, len(columns))
+		if obj, ok := item.(*jsonObject); ok {
+			for i, c := range columns {
+				if v, ok := obj.values[c]; ok {
+					cells[i] = markdownCell(v)
+				}
+			}
+		} else {
+			cells[len(cells)-1] = markdownCell(item)
+		}
+		writeRow(cells)
+	}
+}
+
+// markdownCell renders v for a table cell, escaping characters that would break the row
+func markdownCell(v interface{}) string {
+	return strings.NewReplacer("|", `\|`, "\r", " ", "\n", " ").Replace(inlineValue(v))
+}
+
+// renderSummary renders an object as one paragraph: scalar fields as "name value" with
+// nested objects flattened to dotted names, and lists as a count naming their first
+// entries
+func renderSummary(obj *jsonObject) string {
+	var facts []string
+	collectSummaryFacts("", obj, &facts)
+	omitted := 0
+	if len(facts) > maxSummaryFacts {
+		omitted = len(facts) - maxSummaryFacts
+		facts = facts[:maxSummaryFacts]
+	}
+	var b strings.Builder
+	b.WriteString(strings.Join(facts, "; "))
+	if omitted > 0 {
+		fmt.Fprintf(&b, "; and %d more fields", omitted)
+	}
+	b.WriteString(".")
+	return b.String()
+}
+
+// collectSummaryFacts appends one fact per scalar field and list of obj
+func collectSummaryFacts(prefix string, obj *jsonObject, facts *[]string) {
+	for _, k := range obj.keys {
+		name := prefix + k
+		switch v := obj.values[k].(type) {
+		case *jsonObject:
+			collectSummaryFacts(name+".", v, facts)
+		case []interface{}:
+			*facts = append(*facts, summarizeList(name, v))
+		case nil:
+			// Absent values add nothing to a summary
+		default:
+			*facts = append(*facts, name+" "+inlineValue(v))
+		}
+	}
+}
+
+// summarizeList describes a list by its length and the labels of its first entries
+func summarizeList(name string, items []interface{}) string {
+	if len(items) == 0 {
+		return name + " none"
+	}
+	var labels []string
+	for _, item := range items[:min(len(items), summaryListItems)] {
+		if label := itemLabel(item); label != "" {
+			labels = append(labels, label)
+		}
+	}
+	fact := fmt.Sprintf("%s %d", name, len(items))
+	if len(labels) > 0 {
+		more := ""
+		if len(items) > len(labels) {
+			more = ", …"
+		}
+		fact += " (" + strings.Join(labels, ", ") + more + ")"
+	}
+	return fact
+}
+
+// itemLabel names a list entry: a scalar by its value, an object by its first labelKeys
+// field or else its first string field
+func itemLabel(item interface{}) string {
+	obj, ok := item.(*jsonObject)
+	if !ok {
+		return inlineValue(item)
+	}
+	for _, k := range labelKeys {
+		if v, ok := obj.values[k]; ok && isScalar(v) && v != nil {
+			return inlineValue(v)
+		}
+	}
+	for _, k := range obj.keys {
+		if s, ok := obj.values[k].(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// formatSample is a typical tool result: scalars, a nested object, a scalar list, and a
+// list of objects
+const formatSample = `{"total":3,"host":{"name":"pi","uptime":"2 days"},"tags":["a","b"],"empty":[],"missing":null,
+	"processes":[{"pid":10,"name":"nginx","cpu":1.456},{"pid":20,"name":"sshd|x","cpu":0,"status":"sleeping"},{"pid":30,"cpu":2}]}`
+
+func TestRenderMarkdown(t *testing.T) {
+	obj, err := decodeJSONObject(formatSample)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := renderMarkdown(obj)
+	want := `- **total**: 3
+- **host.name**: pi
+- **host.uptime**: 2 days
+- **tags**: a, b
+- **empty**: none
+
+### processes (3)
+
+| pid | name | cpu | status |
+| --- | --- | --- | --- |
+| 10 | nginx | 1.46 |  |
+| 20 | sshd\|x | 0 | sleeping |
+| 30 |  | 2 |  |
+`
+	if got != want {
+		t.Errorf("renderMarkdown =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderSummary(t *testing.T) {
+	obj, err := decodeJSONObject(formatSample)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "total 3; host.name pi; host.uptime 2 days; tags 2 (a, b); empty none; processes 3 (nginx, sshd|x, 30)."
+	if got := renderSummary(obj); got != want {
+		t.Errorf("renderSummary = %q, want %q", got, want)
+	}
+
+	if _, err := decodeJSONObject(`[1, 2]`); err == nil {
+		t.Error("Expected a JSON array to be rejected")
+	}
+	if _, err := decodeJSONObject(`{"a":1} trailing`); err == nil {
+		t.Error("Expected trailing data to be rejected")
+	}
+}
+
+func TestFormatTool(t *testing.T) {
+	h := NewHandlerManager(&config.Config{})
+	var gotFormat interface{}
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, _ := request.Params.Arguments.(map[string]interface{})
+		gotFormat = args["format"]
+		return mcp.NewToolResultText(formatSample), nil
+	}
+	call := func(tool mcp.Tool, format string) *mcp.CallToolResult {
+		t.Helper()
+		var request mcp.CallToolRequest
+		request.Params.Arguments = map[string]interface{}{"format": format}
+		res, err := h.formatTool(tool, handler)(context.Background(), request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	plain := mcp.NewTool("get_process_list")
+	if res := call(plain, "json"); resultText(res) != formatSample {
+		t.Errorf("Expected json to pass the result through, got %s", resultText(res))
+	}
+	if res := call(plain, "Markdown"); !strings.HasPrefix(resultText(res), "- **total**: 3") {
+		t.Errorf("Unexpected markdown result: %s", resultText(res))
+	}
+	if res := call(plain, "yaml"); !res.IsError {
+		t.Error("Expected an error for an unknown format")
+	}
+
+	// A tool with its own format argument renders markdown itself and gets json for a summary
+	own := mcp.NewTool("get_health_report", mcp.WithString("format"))
+	if res := call(own, "markdown"); resultText(res) != formatSample || gotFormat != "markdown" {
+		t.Errorf("Expected markdown to reach the tool, got %v and %s", gotFormat, resultText(res))
+	}
+	if res := call(own, "summary"); !strings.HasPrefix(resultText(res), "total 3;") || gotFormat != "json" {
+		t.Errorf("Expected a summary of the tool's json, got %v and %s", gotFormat, resultText(res))
+	}
+
+	if tool := withFormatArg(mcp.NewTool("get_cpu_info")); tool.InputSchema.Properties["format"] == nil {
+		t.Error("Expected the format argument in the tool schema")
+	}
+}
@@ -5,13 +5,30 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os/exec"
+	"log/slog"
+	"os"
 	"runtime"
-	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"sysmetrics-mcp/internal/audit"
+	"sysmetrics-mcp/internal/availability"
+	"sysmetrics-mcp/internal/bench"
+	"sysmetrics-mcp/internal/capabilities"
+	"sysmetrics-mcp/internal/collector"
 	"sysmetrics-mcp/internal/config"
+	"sysmetrics-mcp/internal/exporter"
+	"sysmetrics-mcp/internal/geoip"
+	"sysmetrics-mcp/internal/grpcapi"
+	"sysmetrics-mcp/internal/history"
+	"sysmetrics-mcp/internal/locale"
+	"sysmetrics-mcp/internal/logging"
+	"sysmetrics-mcp/internal/smarthome"
+	"sysmetrics-mcp/internal/update"
+	"sysmetrics-mcp/internal/ups"
+	"sysmetrics-mcp/internal/usage"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -21,7 +38,6 @@ import (
 	"github.com/shirou/gopsutil/v3/load"
 	"github.com/shirou/gopsutil/v3/mem"
 	"github.com/shirou/gopsutil/v3/net"
-	"github.com/shirou/gopsutil/v3/process"
 )
 
 // Health status constants.
@@ -40,98 +56,953 @@ const (
 
 // HandlerManager manages the MCP tool handlers
 type HandlerManager struct {
-	cfg *config.Config
+	cfg          *config.Config
+	caps         capabilities.Capabilities
+	services     serviceManager
+	registered   []string
+	skippedTools map[string]string
+	selfTests    map[string]selfTestTarget
+	toolHandlers map[string]server.ToolHandlerFunc
+	toolTraits   map[string]toolTraits
+	geo          *geoip.DB
+	updater      *update.Checker
+	logger       *slog.Logger
+	audit        *audit.Log
+	human        *locale.Formatter
+	redactor     *redactor
+	panics       atomic.Int64
+
+	truncatedResponses atomic.Int64
+	rateLimited        atomic.Int64
+	cacheHits          atomic.Int64
+	timedOut           atomic.Int64
+
+	bootMu     sync.Mutex
+	bootCached bool
+	bootReason bootReason
+	prevBoot   *previousBoot
+
+	baselines *bench.Store
+	benchMu   sync.Mutex
+
+	usageStats *usage.Tracker
+
+	ledger           *availability.Ledger
+	availabilityOnce sync.Once
+	availabilityErr  error
+	bootStart        time.Time
+
+	collectors    *collector.Registry
+	history       *history.Store
+	exporter      *exporter.Exporter
+	sampleWriters []history.Writer
+
+	fleet []*grpcapi.Client
+
+	hungMounts sync.Map
+
+	syntheticMu sync.Mutex
+	synthetic   map[string]*syntheticState
+
+	logGrowthMu   sync.Mutex
+	logGrowthPrev *logSnapshot
 }
 
-// NewHandlerManager creates a new HandlerManager
+// NewHandlerManager creates a new HandlerManager and probes host capabilities
 func NewHandlerManager(cfg *config.Config) *HandlerManager {
-	return &HandlerManager{cfg: cfg}
+	// On Windows, gopsutil derives load averages from the Processor Queue Length
+	// perf counter sampled in the background, so start sampling as early as possible
+	if runtime.GOOS == "windows" {
+		_, _ = load.AvgWithContext(context.Background())
+	}
+
+	// Config validation rejects unknown locales; fall back to the default otherwise (e.g. in tests)
+	human, err := locale.New(cfg.Locale)
+	if err != nil {
+		human, _ = locale.New(locale.Default)
+	}
+
+	// A memory-only audit log cannot fail to open; main swaps in a file-backed one
+	auditLog, _ := audit.Open(audit.Options{})
+
+	caps := capabilities.Detect()
+
+	// Stdout carries the MCP protocol, so diagnostics go to stderr
+	h := &HandlerManager{
+		cfg:          cfg,
+		caps:         caps,
+		services:     newServiceManager(caps),
+		skippedTools: make(map[string]string),
+		selfTests:    make(map[string]selfTestTarget),
+		toolHandlers: make(map[string]server.ToolHandlerFunc),
+		toolTraits:   make(map[string]toolTraits),
+		updater:      update.NewChecker(update.DefaultReleasesURL, update.DefaultCachePath()),
+		logger:       logging.NewWriter(os.Stderr, slog.LevelInfo),
+		audit:        auditLog,
+		baselines:    bench.NewStore(bench.DefaultStorePath()),
+		ledger:       availability.NewLedger(availability.DefaultPath()),
+		usageStats:   usage.NewTracker(usage.DefaultPath()),
+		human:        human,
+		redactor:     newRedactor(cfg),
+		collectors:   collector.NewRegistry(),
+	}
+	for _, c := range h.coreCollectors() {
+		// Core collector names are distinct, so registration cannot fail
+		_ = h.collectors.Register(c)
+	}
+	return h
+}
+
+// SetGeoIP enables GeoIP/ASN enrichment of remote addresses; a nil db disables it
+func (h *HandlerManager) SetGeoIP(db *geoip.DB) {
+	h.geo = db
+}
+
+// addTool registers a tool with the MCP server and records it as available, unless its
+// opt-in flag is unset or the tool profile or --disable-tools excludes it. Every call passes
+// through the middleware chain from toolMiddleware, which treats the tool by the traits in
+// opts.
+func (h *HandlerManager) addTool(s *server.MCPServer, tool mcp.Tool, handler server.ToolHandlerFunc, opts ...toolOption) {
+	if ok, reason := h.cfg.ToolEnabled(tool.Name); !ok {
+		h.skipTool(tool.Name, reason)
+		return
+	}
+	var traits toolTraits
+	for _, opt := range opts {
+		opt(&traits)
+	}
+	h.toolTraits[tool.Name] = traits
+	middleware := h.toolMiddleware()
+	h.toolHandlers[tool.Name] = chainTool(tool, handler, middleware)
+	// The shared format argument is added once the chain has seen the tool's own schema
+	tool = withFormatArg(tool)
+	s.AddTool(tool, h.toolHandlers[tool.Name])
+	h.registered = append(h.registered, tool.Name)
+	h.selfTests[tool.Name] = selfTestTarget{tool: tool, handler: chainTool(tool, handler, selfTestMiddleware(middleware))}
+}
+
+// skipTool records a tool that was not registered because a capability is missing
+func (h *HandlerManager) skipTool(name, reason string) {
+	h.skippedTools[name] = reason
+}
+
+// UnknownToolFilters returns names in --enable-tools, --disable-tools, or
+// --cache-tool-ttls that match no tool this server knows, registered or skipped. Call it
+// after RegisterTools.
+func (h *HandlerManager) UnknownToolFilters() []string {
+	var unknown []string
+	names := append(append([]string{}, h.cfg.EnableTools...), h.cfg.DisableTools...)
+	for _, name := range append(names, sortedKeys(h.cfg.CacheToolTTLs)...) {
+		if _, skipped := h.skippedTools[name]; !skipped && !contains(h.registered, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	return unknown
 }
 
-// RegisterTools registers all available tools with the MCP server
+// RegisterTools registers all tools whose required capabilities are available
 func (h *HandlerManager) RegisterTools(s *server.MCPServer) {
+	// Server info tool
+	h.addTool(s, mcp.NewTool("get_server_info",
+		mcp.WithDescription("Get server version, detected host capabilities, and the list of registered and skipped tools")),
+		h.HandleGetServerInfo, liveState)
+
+	// Self-test tool
+	h.addTool(s, mcp.NewTool("self_test",
+		mcp.WithDescription("Run every registered tool once with default arguments and a timeout, and report which work on this host, how long each takes, and any permission problems. A good first call after installation."),
+		mcp.WithString("tools", mcp.Description("Comma-separated tools to test (default: all registered tools)")),
+		mcp.WithNumber("timeout_seconds", mcp.Description("Per-tool timeout in seconds (default: 10, max: 60)"))),
+		h.HandleSelfTest, liveState, longRunning, skipSelfTest("this tool"))
+
+	// Audit trail tool
+	h.addTool(s, mcp.NewTool("get_audit_log",
+		mcp.WithDescription("Get recent tool calls made to this server (tool, arguments, client, duration, result size, errors), newest first"),
+		mcp.WithNumber("limit", mcp.Description("Maximum number of entries to return (default: 50, max: 1000)")),
+		mcp.WithString("tool", mcp.Description("Only include calls to this tool")),
+		mcp.WithString("since", mcp.Description("How far back to look, e.g. 30m, 6h, 7d (default: 24h)")),
+		mcp.WithBoolean("errors_only", mcp.Description("Only include calls that returned an error"))),
+		h.HandleGetAuditLog, liveState)
+
 	// System info tool
-	s.AddTool(mcp.NewTool("get_system_info",
+	h.addTool(s, mcp.NewTool("get_system_info",
 		mcp.WithDescription("Get system information including hostname, OS, uptime, and platform details")),
 		h.HandleGetSystemInfo)
 
 	// CPU metrics tool
-	s.AddTool(mcp.NewTool("get_cpu_metrics",
-		mcp.WithDescription("Get CPU usage, temperature, and load average"),
+	h.addTool(s, mcp.NewTool("get_cpu_metrics",
+		mcp.WithDescription("Get CPU usage, temperature, load average, and per-core frequency and governor"),
 		mcp.WithString("temp_unit", mcp.Description("Override temperature unit: celsius, fahrenheit, or kelvin"),
 			mcp.Enum(config.UnitCelsius, config.UnitFahrenheit, config.UnitKelvin))),
 		h.HandleGetCPUMetrics)
 
+	// CPU frequency scaling tool
+	if h.caps.CPUFreq {
+		h.addTool(s, mcp.NewTool("get_cpufreq",
+			mcp.WithDescription("Get CPU frequency scaling per policy and core: governor, driver, current/min/max and hardware frequency limits, caps below the hardware maximum, and time-in-state residency where the kernel keeps cpufreq statistics")),
+			h.HandleGetCPUFreq)
+	} else {
+		h.skipTool("get_cpufreq", "no cpufreq interface (/sys/devices/system/cpu/cpu0/cpufreq)")
+	}
+
 	// Memory metrics tool
-	s.AddTool(mcp.NewTool("get_memory_metrics",
-		mcp.WithDescription("Get memory usage statistics including RAM and swap")),
+	h.addTool(s, mcp.NewTool("get_memory_metrics",
+		mcp.WithDescription("Get memory usage statistics including RAM, swap with swap-in/out and major fault rates, zram/zswap compression, and page cache efficiency (reclaim churn, refaults, readahead) with an assessment of whether more RAM would help"),
+		mcp.WithNumber("sample_seconds", mcp.Description("Seconds to sample page cache churn and swap activity (default: 0 = since-boot averages only, max: 10)"))),
 		h.HandleGetMemoryMetrics)
 
 	// Disk metrics tool
-	s.AddTool(mcp.NewTool("get_disk_metrics",
+	h.addTool(s, mcp.NewTool("get_disk_metrics",
 		mcp.WithDescription("Get disk usage statistics for mount points"),
 		mcp.WithString("mount_points", mcp.Description("Comma-separated mount points to check (overrides config default)")),
 		mcp.WithBoolean("human_readable", mcp.Description("Include human-readable sizes alongside bytes"))),
 		h.HandleGetDiskMetrics)
 
 	// Network metrics tool
-	s.AddTool(mcp.NewTool("get_network_metrics",
+	h.addTool(s, mcp.NewTool("get_network_metrics",
 		mcp.WithDescription("Get network interface statistics"),
-		mcp.WithString("interfaces", mcp.Description("Comma-separated interface names to check (overrides config default)"))),
+		mcp.WithString("interfaces", mcp.Description("Comma-separated interface names to check (overrides config default)")),
+		mcp.WithString("fields", mcp.Description("Comma-separated columns to return: "+strings.Join(networkFields, ", ")+" (default: all)"))),
 		h.HandleGetNetworkMetrics)
 
 	// Process list tool
-	s.AddTool(mcp.NewTool("get_process_list",
+	h.addTool(s, mcp.NewTool("get_process_list",
 		mcp.WithDescription("Get list of running processes sorted by resource usage"),
-		mcp.WithNumber("limit", mcp.Description("Maximum number of processes to return (overrides config default)")),
+		mcp.WithNumber("limit", mcp.Description("Maximum number of processes to return per page (overrides config default, bounded by --max-processes-cap)")),
+		mcp.WithNumber("offset", mcp.Description("Number of processes or groups to skip (default: 0)")),
+		mcp.WithString("cursor", mcp.Description("next_cursor from the previous page, to continue the same query")),
 		mcp.WithString("sort_by", mcp.Description("Sort by: cpu, memory, or pid"),
-			mcp.Enum("cpu", "memory", "pid"))),
+			mcp.Enum(processSorts...)),
+		mcp.WithString("user", mcp.Description("Only include processes owned by this username")),
+		mcp.WithString("name_pattern", mcp.Description("Only include processes whose name matches this regular expression")),
+		mcp.WithNumber("min_cpu", mcp.Description("Only include processes using at least this CPU percent")),
+		mcp.WithNumber("min_memory", mcp.Description("Only include processes using at least this memory percent")),
+		mcp.WithString("group_by", mcp.Description("Aggregate processes sharing a name into one row with summed CPU/memory/RSS and an instance count"),
+			mcp.Enum(groupByNone, groupByName)),
+		mcp.WithString("fields", mcp.Description("Comma-separated columns to return: "+strings.Join(processFields, ", ")+
+			" (grouped: "+strings.Join(processGroupFields, ", ")+"; default: all)"))),
 		h.HandleGetProcessList)
 
 	// Thermal status tool
-	s.AddTool(mcp.NewTool("get_thermal_status",
-		mcp.WithDescription("Get thermal status including temperatures and throttling information"),
+	h.addTool(s, mcp.NewTool("get_thermal_status",
+		mcp.WithDescription("Get thermal status including CPU/GPU temperatures, every thermal zone and hwmon sensor with trip points, the hottest reading, fan speed and PWM duty cycle, and throttling information"),
 		mcp.WithString("temp_unit", mcp.Description("Override temperature unit: celsius, fahrenheit, or kelvin"),
 			mcp.Enum(config.UnitCelsius, config.UnitFahrenheit, config.UnitKelvin))),
 		h.HandleGetThermalStatus)
 
 	// Disk I/O metrics tool
-	s.AddTool(mcp.NewTool("get_disk_io_metrics",
+	h.addTool(s, mcp.NewTool("get_disk_io_metrics",
 		mcp.WithDescription("Get disk I/O statistics including read/write throughput, IOPS, and I/O time"),
 		mcp.WithString("devices", mcp.Description("Comma-separated device names to check (e.g. sda,nvme0n1)"))),
 		h.HandleGetDiskIOMetrics)
 
 	// System health tool
-	s.AddTool(mcp.NewTool("get_system_health",
+	h.addTool(s, mcp.NewTool("get_system_health",
 		mcp.WithDescription("Get an aggregated system health dashboard with CPU, memory, disk, and uptime in a single call")),
 		h.HandleGetSystemHealth)
 
+	// Metric registry snapshot tool
+	h.addTool(s, mcp.NewTool("get_metric_snapshot",
+		mcp.WithDescription("Get the current value of every metric in the collector registry (CPU, load, memory, disk, network, temperature) as flat metric/label/value rows, the same metrics recorded in history and pushed to exporters"),
+		mcp.WithString("collectors", mcp.Description("Comma-separated collectors to read (default: all), e.g. cpu,memory")),
+		mcp.WithBoolean("describe", mcp.Description("Include each collector's metric descriptions, units, and labels (default: false)"))),
+		h.HandleGetMetricSnapshot)
+
 	// Docker metrics tool
-	s.AddTool(mcp.NewTool("get_docker_metrics",
-		mcp.WithDescription("Get Docker container metrics including CPU, memory, network, and block I/O usage"),
-		mcp.WithString("container_id", mcp.Description("Optional container ID or name to filter results"))),
-		h.HandleGetDockerMetrics)
+	if h.caps.DockerCLI {
+		h.addTool(s, mcp.NewTool("get_docker_metrics",
+			mcp.WithDescription("Get Docker container metrics including CPU, memory, network, and block I/O usage"),
+			mcp.WithString("container_id", mcp.Description("Optional container ID or name to filter results")),
+			mcp.WithString("fields", mcp.Description("Comma-separated columns to return: "+strings.Join(containerFields, ", ")+" (default: all)"))),
+			h.HandleGetDockerMetrics)
+	} else {
+		h.skipTool("get_docker_metrics", "docker CLI not found in PATH")
+	}
+
+	// Log directory growth tool
+	if info, err := os.Stat(h.cfg.LogDir); err == nil && info.IsDir() {
+		h.addTool(s, mcp.NewTool("get_log_growth",
+			mcp.WithDescription("Find what is filling the log directory (--log-dir, default /var/log): journald's size and share, the largest files and logs counting rotated copies, and from the second call on each file's growth rate since the previous call, with hours until the filesystem is full"),
+			mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Maximum files and logs to list (default: %d, max: %d)", defaultLogGrowthLimit, maxLogGrowthLimit)))),
+			h.HandleGetLogGrowth)
+	} else {
+		h.skipTool("get_log_growth", "--log-dir not found")
+	}
+
+	// Docker disk usage tool
+	if h.caps.DockerSocket {
+		h.addTool(s, mcp.NewTool("get_docker_disk_usage",
+			mcp.WithDescription("Get disk space used by Docker images, container writable layers, volumes, and build cache (like docker system df), with reclaimable space, dangling images, and the largest images and volumes"),
+			mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Maximum images, containers, and volumes to list by size (default: %d, max: %d)", defaultDockerDiskLimit, maxDockerDiskLimit)))),
+			h.HandleGetDockerDiskUsage)
+	} else {
+		h.skipTool("get_docker_disk_usage", "Docker daemon socket not found")
+	}
+
+	// Unified container metrics tool
+	if len(h.detectedRuntimes()) > 0 {
+		h.addTool(s, mcp.NewTool("get_container_metrics",
+			mcp.WithDescription("Get container metrics across Docker, Podman, and containerd (nerdctl/k3s), with the runtime of each container"),
+			mcp.WithString("runtime", mcp.Description("Optional runtime filter: docker, podman, or containerd"),
+				mcp.Enum(runtimeDocker, runtimePodman, runtimeContainerd)),
+			mcp.WithString("container_id", mcp.Description("Optional container ID or name to filter results")),
+			mcp.WithString("fields", mcp.Description("Comma-separated columns to return: "+strings.Join(containerFields, ", ")+" (default: all)"))),
+			h.HandleGetContainerMetrics)
+	} else {
+		h.skipTool("get_container_metrics", "no container runtime CLI (docker, podman, nerdctl) found in PATH")
+	}
 
 	// Network connections tool
-	s.AddTool(mcp.NewTool("get_network_connections",
+	h.addTool(s, mcp.NewTool("get_network_connections",
 		mcp.WithDescription("Get active network connections with local/remote addresses, status, and owning PID"),
 		mcp.WithString("kind", mcp.Description("Connection type filter: tcp, udp, or all"),
 			mcp.Enum("tcp", "udp", "all")),
-		mcp.WithString("status", mcp.Description("Filter by connection status (e.g. LISTEN, ESTABLISHED)"))),
+		mcp.WithString("status", mcp.Description("Filter by connection status (e.g. LISTEN, ESTABLISHED)")),
+		mcp.WithString("group_by", mcp.Description("Summarize connections per remote host or remote port with counts per state (default: none)"),
+			mcp.Enum(groupByNone, groupByRemoteHost, groupByRemotePort)),
+		mcp.WithNumber("limit", mcp.Description("Maximum number of connections, or of groups when group_by is set, per page (default: --max-processes-cap for connections, --max-processes for groups; bounded by --max-processes-cap)")),
+		mcp.WithNumber("offset", mcp.Description("Number of connections or groups to skip (default: 0)")),
+		mcp.WithString("cursor", mcp.Description("next_cursor from the previous page, to continue the same query")),
+		mcp.WithBoolean("resolve_dns", mcp.Description("Add reverse DNS hostnames when grouping by remote_host (default: false)")),
+		mcp.WithString("fields", mcp.Description("Comma-separated columns to return: "+strings.Join(connectionFields, ", ")+
+			" (grouped by remote_host: "+strings.Join(connectionHostGroupFields, ", ")+
+			"; grouped by remote_port: "+strings.Join(connectionPortGroupFields, ", ")+") (default: all)"))),
 		h.HandleGetNetworkConnections)
 
 	// Service status tool
-	s.AddTool(mcp.NewTool("get_service_status",
-		mcp.WithDescription("Get systemd service status for specified services"),
-		mcp.WithString("services", mcp.Description("Comma-separated list of service names to check (required)"),
-			mcp.Required())),
-		h.HandleGetServiceStatus)
+	if h.services != nil {
+		h.addTool(s, mcp.NewTool("get_service_status",
+			mcp.WithDescription("Get service status for specified services (systemd, OpenRC, runit, or SysV init scripts on Linux, Service Control Manager on Windows, launchd on macOS). systemd units also report memory, CPU time, tasks, restart count, start time, and optionally their last journal lines."),
+			mcp.WithString("services", mcp.Description("Comma-separated list of service names to check (required); with systemd, glob patterns such as docker* or * match loaded units"),
+				mcp.Required()),
+			mcp.WithNumber("lines", mcp.Description("Journal lines to return per systemd unit (default: 0, max: 100)")),
+			mcp.WithBoolean("user", mcp.Description("Query the server user's systemd user units instead of system units (default: false)"))),
+			h.HandleGetServiceStatus)
+	} else {
+		h.skipTool("get_service_status", "no supported service manager detected (systemd, OpenRC, runit, SysV init scripts, Windows SCM, or launchd)")
+	}
+
+	// Service diagnosis tool
+	if h.caps.Systemd {
+		h.addTool(s, mcp.NewTool("diagnose_service",
+			mcp.WithDescription("Diagnose a systemd unit in one call: status, last state change, restart count, exit status, cgroup memory/CPU/task/IO usage, and its recent journal lines with an error count"),
+			mcp.WithString("service", mcp.Required(), mcp.Description("Unit name, e.g. nginx or docker.service")),
+			mcp.WithNumber("lines", mcp.Description("Journal lines to return (default: 50, max: 500; 0 skips the journal)"))),
+			h.HandleDiagnoseService)
+	} else {
+		h.skipTool("diagnose_service", "systemd not detected")
+	}
+
+	// Package updates tool
+	if h.caps.Apt || h.caps.Dnf || h.caps.Pacman {
+		h.addTool(s, mcp.NewTool("get_package_updates",
+			mcp.WithDescription("Get pending system package updates (apt, dnf, or pacman) with the total and security update counts, the top packages, package list age, and whether a reboot is required. Query-only: nothing is installed."),
+			mcp.WithNumber("limit", mcp.Description("Maximum number of packages to list (default: 20, max: 200)")),
+			mcp.WithBoolean("security_only", mcp.Description("Only list security updates"))),
+			h.HandleGetPackageUpdates)
+	} else {
+		h.skipTool("get_package_updates", "no supported package manager found (apt, dnf, or pacman)")
+	}
+
+	// Tool usage statistics tool
+	if h.usageStats.Path() != "" {
+		h.addTool(s, mcp.NewTool("get_usage_stats",
+			mcp.WithDescription("Get per-tool call counts, error rates, and latency percentiles (p50, p90, p99) kept across restarts, to find collectors that are slow or flaky on this host and tune caching or timeouts"),
+			mcp.WithString("tool", mcp.Description("Only report this tool")),
+			mcp.WithString("sort_by", mcp.Description("Order of the tools (default: calls)"), mcp.Enum(usageSorts...))),
+			h.HandleGetUsageStats, liveState)
+	} else {
+		h.skipTool("get_usage_stats", "no user config directory for the usage statistics file")
+	}
+
+	// Reboot status tool
+	if runtime.GOOS == "linux" {
+		h.addTool(s, mcp.NewTool("get_reboot_status",
+			mcp.WithDescription("Check whether updates require a reboot or service restarts: /var/run/reboot-required, running versus newest installed kernel, and services still using deleted (replaced) binaries or libraries")),
+			h.HandleGetRebootStatus)
+	} else {
+		h.skipTool("get_reboot_status", "requires Linux")
+	}
+
+	// Availability tool
+	if h.ledger.Path() != "" {
+		h.addTool(s, mcp.NewTool("get_availability",
+			mcp.WithDescription("Get uptime percentage over the last 7, 30, and 90 days and the reboot history with downtime and how each boot ended (clean reboot, kernel panic, watchdog reset, power loss), from a persistent availability ledger"),
+			mcp.WithNumber("limit", mcp.Description("Maximum number of reboots to list (default: 20, max: 200)"))),
+			h.HandleGetAvailability, skipSelfTest("writes the availability ledger"))
+	} else {
+		h.skipTool("get_availability", "no user config directory for the availability ledger")
+	}
+
+	// Process control tool
+	h.addTool(s, mcp.NewTool("manage_process",
+		mcp.WithDescription("Send SIGTERM or SIGKILL to a process or change its nice value. PID 1, kernel threads, the server and its client, root-owned processes (unless allowed), and denied users and PIDs are refused. Use dry_run to preview."),
+		mcp.WithNumber("pid", mcp.Required(), mcp.Description("Process ID")),
+		mcp.WithString("action", mcp.Required(), mcp.Description("Action to perform"), mcp.Enum(processActions...)),
+		mcp.WithNumber("nice", mcp.Description("New nice value for renice (-20 to 19)")),
+		mcp.WithBoolean("dry_run", mcp.Description("Check the safety guards and report the target without acting (default: false)")),
+		mcp.WithBoolean("confirm", mcp.Description("Must be true to act unless dry_run is set"))),
+		h.HandleManageProcess, hostAction("signals processes"))
+
+	// Benchmark tool
+	h.addTool(s, mcp.NewTool("run_system_baseline",
+		mcp.WithDescription("Run CPU, memory, and disk micro-benchmarks with a temperature/frequency snapshot, optionally save the run as a named baseline, and compare it with an earlier baseline to validate hardware changes (new SSD, cooling, overclock). Puts the host under load for several seconds and aborts with partial results if the CPU nears its critical temperature."),
+		mcp.WithString("name", mcp.Description("Save this run as a baseline under this name (replaces an existing one)")),
+		mcp.WithString("compare_to", mcp.Description("Name of a stored baseline to compare this run against")),
+		mcp.WithString("tests", mcp.Description("Comma-separated benchmarks to run: cpu, memory, disk (default: all)")),
+		mcp.WithNumber("duration_seconds", mcp.Description("Duration of each CPU and memory phase (default: 3, max: 30)")),
+		mcp.WithNumber("disk_mb", mcp.Description("Size of the disk test file in MB (default: 64, max: 1024)")),
+		mcp.WithString("disk_dir", mcp.Description("Directory on the filesystem to test (default: the baseline file's directory)")),
+		mcp.WithBoolean("list", mcp.Description("Only list stored baselines without running anything")),
+		mcp.WithNumber("max_temp_celsius", mcp.Description("Abort when the CPU reaches this temperature (default and ceiling: 10°C below the critical trip point, or 85°C if unknown)"))),
+		h.HandleRunSystemBaseline, sideEffect("puts the host under load"), longRunning)
+
+	// Service control tool
+	if h.caps.Systemd {
+		h.addTool(s, mcp.NewTool("control_service",
+			mcp.WithDescription("Start, stop, or restart a systemd unit from the server's allowlist, and report its state before and after"),
+			mcp.WithString("service", mcp.Required(), mcp.Description("Unit name, e.g. nginx or docker.service (must be in --service-control-allowlist)")),
+			mcp.WithString("action", mcp.Required(), mcp.Description("Action to perform"), mcp.Enum(serviceActions...)),
+			mcp.WithBoolean("confirm", mcp.Required(), mcp.Description("Must be true to perform the action"))),
+			h.HandleControlService, hostAction("starts and stops services"))
+	} else {
+		h.skipTool("control_service", "systemd not detected")
+	}
+
+	// File descriptor usage tool
+	h.addTool(s, mcp.NewTool("get_fd_usage",
+		mcp.WithDescription("Get system-wide file descriptor usage, top per-process FD consumers, and open file limits"),
+		mcp.WithNumber("limit", mcp.Description("Maximum number of processes to return (overrides config default)"))),
+		h.HandleGetFDUsage)
+
+	// Kernel counters tool
+	if runtime.GOOS == "linux" {
+		h.addTool(s, mcp.NewTool("get_kernel_stats",
+			mcp.WithDescription("Get kernel activity counters for performance analysis: context switches, interrupts, softirqs, and forks per second (sampled and since boot), runnable and blocked process counts, available entropy, and system-wide file handle usage"),
+			mcp.WithNumber("sample_seconds", mcp.Description("Seconds to sample the counters (default: 0 = since-boot averages only, max: 10)"))),
+			h.HandleGetKernelStats)
+	} else {
+		h.skipTool("get_kernel_stats", "requires Linux")
+	}
+
+	// Kubernetes metrics tool
+	if h.caps.Crictl || h.caps.K3s {
+		h.addTool(s, mcp.NewTool("get_k8s_metrics",
+			mcp.WithDescription("Get Kubernetes pods running on this node with CPU/memory usage, namespace, and restart counts (via the CRI)"),
+			mcp.WithString("namespace", mcp.Description("Optional namespace to filter pods"))),
+			h.HandleGetK8sMetrics)
+	} else {
+		h.skipTool("get_k8s_metrics", "neither crictl nor k3s found in PATH")
+	}
+
+	// Virtual machine metrics tool
+	if h.caps.Libvirt {
+		h.addTool(s, mcp.NewTool("get_vm_metrics",
+			mcp.WithDescription("Get libvirt virtual machines on this host with state, vCPU usage, memory balloon, and per-disk and per-interface I/O (via virsh domstats)"),
+			mcp.WithString("domain", mcp.Description("Optional domain name to filter results"))),
+			h.HandleGetVMMetrics)
+	} else {
+		h.skipTool("get_vm_metrics", "virsh or the libvirt daemon socket not found")
+	}
+
+	// Proxmox VE guests tool
+	if h.caps.Proxmox {
+		h.addTool(s, mcp.NewTool("get_proxmox_guests",
+			mcp.WithDescription("Get Proxmox VE virtual machines and containers on this node (or the whole cluster) with status, CPU, memory, disk, network I/O, and uptime (via pvesh)"),
+			mcp.WithString("type", mcp.Description("Optional guest type filter: qemu (VMs) or lxc (containers)"),
+				mcp.Enum(proxmoxTypeVM, proxmoxTypeCT)),
+			mcp.WithString("status", mcp.Description("Optional status filter, e.g. running or stopped")),
+			mcp.WithBoolean("all_nodes", mcp.Description("Include guests on other cluster nodes (default: false)")),
+			mcp.WithBoolean("include_templates", mcp.Description("Include VM and container templates (default: false)"))),
+			h.HandleGetProxmoxGuests)
+	} else {
+		h.skipTool("get_proxmox_guests", "not a Proxmox VE host (/etc/pve or pvesh not found)")
+	}
+
+	// Permission status tool
+	h.addTool(s, mcp.NewTool("get_permission_status",
+		mcp.WithDescription("Report which collectors are degraded due to missing privileges, with setcap/group/sudo guidance")),
+		h.HandleGetPermissionStatus)
+
+	// Power metrics tool
+	h.addTool(s, mcp.NewTool("get_power_metrics",
+		mcp.WithDescription("Get battery percentage, charge/discharge rate, AC status, and Raspberry Pi input voltage/undervoltage state")),
+		h.HandleGetPowerMetrics)
+
+	// UPS status tool
+	if h.cfg.NUTAddr != "" || h.cfg.ApcupsdAddr != "" {
+		h.addTool(s, mcp.NewTool("get_ups_status",
+			mcp.WithDescription("Get UPS battery charge, runtime remaining, load, and line voltage from Network UPS Tools (upsd) or apcupsd"),
+			mcp.WithString("backend", mcp.Description("UPS daemon to query: nut, apcupsd, or all (default: all)"),
+				mcp.Enum(ups.BackendNUT, ups.BackendApcupsd, kindAll)),
+			mcp.WithString("ups", mcp.Description("Optional UPS name to query (NUT ups name or apcupsd UPSNAME)"))),
+			h.HandleGetUPSStatus)
+	} else {
+		h.skipTool("get_ups_status", "both --nut-addr and --apcupsd-addr are empty")
+	}
+
+	// File server status tool
+	if h.caps.NFSServer || h.caps.Smbstatus {
+		h.addTool(s, mcp.NewTool("get_fileserver_status",
+			mcp.WithDescription("Get NFS server statistics (threads, I/O, per-operation counts, exports) and Samba sessions, shares, and locked files")),
+			h.HandleGetFileserverStatus)
+	} else {
+		h.skipTool("get_fileserver_status", "neither the NFS server (/proc/net/rpc/nfsd) nor smbstatus is available")
+	}
+
+	// Software RAID tool
+	if h.caps.MDRaid {
+		h.addTool(s, mcp.NewTool("get_raid_status",
+			mcp.WithDescription("Get Linux software RAID (md) array state: degraded arrays and faulty or missing members, resync/recovery progress with ETA, and mismatch counts from the last check; adds mdadm --detail when permitted"),
+			mcp.WithString("array", mcp.Description("Optional array to report, e.g. md0 (default: all arrays)"))),
+			h.HandleGetRAIDStatus)
+	} else {
+		h.skipTool("get_raid_status", "no software RAID support (/proc/mdstat)")
+	}
+
+	// ZFS and Btrfs pool tool
+	if h.caps.Zpool || h.caps.Btrfs {
+		h.addTool(s, mcp.NewTool("get_storage_pool_status",
+			mcp.WithDescription("Get ZFS pool and Btrfs filesystem health: pool state, per-device read/write/checksum errors, scrub or resilver progress and results, and capacity"),
+			mcp.WithString("pool", mcp.Description("Optional ZFS pool name or Btrfs mount point to report (default: all)"))),
+			h.HandleGetStoragePoolStatus)
+	} else {
+		h.skipTool("get_storage_pool_status", "neither zpool nor btrfs is installed")
+	}
+
+	// LVM and LUKS layout tool
+	if h.caps.LVM || h.caps.DeviceMapper {
+		h.addTool(s, mcp.NewTool("get_volume_layout",
+			mcp.WithDescription("Get the storage stack beneath the filesystems: LVM physical volumes, volume groups, and logical volumes with mount points and thin pool data/metadata usage, and LUKS-encrypted devices with whether they are open and what sits on them")),
+			h.HandleGetVolumeLayout)
+	} else {
+		h.skipTool("get_volume_layout", "neither lvm nor device-mapper is available")
+	}
+
+	// Snapshot inventory tool
+	if h.caps.Zpool || h.caps.Btrfs || h.caps.LVM {
+		h.addTool(s, mcp.NewTool("get_snapshots",
+			mcp.WithDescription("List ZFS, Btrfs, and LVM snapshots with the space each holds, creation time, and age, plus per-pool totals; warns when snapshots hold a large share of a pool or a classic LVM snapshot is nearly full"),
+			mcp.WithString("type", mcp.Description("Which snapshots to list: zfs, btrfs, lvm, or all (default: all)"),
+				mcp.Enum("zfs", "btrfs", "lvm", "all"))),
+			h.HandleGetSnapshots)
+	} else {
+		h.skipTool("get_snapshots", "none of zpool, btrfs, or lvm found in PATH")
+	}
+
+	// Snapshot creation tool
+	if h.caps.Zpool || h.caps.Btrfs || h.caps.LVM {
+		h.addTool(s, mcp.NewTool("create_fs_snapshot",
+			mcp.WithDescription("Create a ZFS, Btrfs (read-only, under <subvolume>/.snapshots), or LVM snapshot as a restore point before a risky change. Use dry_run to preview the command."),
+			mcp.WithString("type", mcp.Required(), mcp.Description("Snapshot backend"), mcp.Enum(snapshotZFS, snapshotBtrfs, snapshotLVM)),
+			mcp.WithString("target", mcp.Required(), mcp.Description("ZFS dataset (tank/home), Btrfs subvolume path (/home), or LVM volume (vg0/root)")),
+			mcp.WithString("name", mcp.Description("Snapshot name or label (default: sysmetrics-<UTC timestamp>)")),
+			mcp.WithNumber("size_percent", mcp.Description("Copy-on-write space for a classic (non-thin) LVM snapshot, as a percentage of the origin (default: 10)")),
+			mcp.WithBoolean("dry_run", mcp.Description("Validate the target and report the command without running it (default: false)")),
+			mcp.WithBoolean("confirm", mcp.Description("Must be true to create the snapshot unless dry_run is set"))),
+			h.HandleCreateFSSnapshot, hostAction("creates snapshots"))
+	} else {
+		h.skipTool("create_fs_snapshot", "none of zpool, btrfs, or lvm found in PATH")
+	}
+
+	// Network top talkers tool
+	h.addTool(s, mcp.NewTool("get_network_top_processes",
+		mcp.WithDescription("Get the processes with the most network connections and, on Linux with ss, estimated TCP bandwidth per process"),
+		mcp.WithNumber("limit", mcp.Description("Maximum number of processes (bounded by --max-processes-cap)")),
+		mcp.WithString("sort_by", mcp.Description("Sort by connections (default) or bandwidth"),
+			mcp.Enum("connections", "bandwidth")),
+		mcp.WithNumber("sample_seconds", mcp.Description("Bandwidth sampling interval in seconds, 0 to skip (default: 0, or 1 with sort_by=bandwidth; max: 10)"))),
+		h.HandleGetNetworkTopProcesses)
+
+	// Listening ports tool
+	h.addTool(s, mcp.NewTool("get_listening_ports",
+		mcp.WithDescription("Get listening TCP and bound UDP ports with owning process name, user, and binary path, flagging ports exposed on all interfaces"),
+		mcp.WithString("kind", mcp.Description("Socket type filter: tcp, udp, or all"),
+			mcp.Enum("tcp", "udp", "all")),
+		mcp.WithBoolean("check_exposure", mcp.Description("Flag ports bound to 0.0.0.0 or :: with an exposure note (default: true)")),
+		mcp.WithString("fields", mcp.Description("Comma-separated columns to return: "+strings.Join(listeningFields, ", ")+" (default: all)"))),
+		h.HandleGetListeningPorts)
+
+	// Exposed services audit tool
+	if runtime.GOOS == "linux" {
+		h.addTool(s, mcp.NewTool("audit_exposed_services",
+			mcp.WithDescription("Audit which listening services are actually reachable from other hosts: cross-references listening sockets with interface addresses (public, private, VPN, container bridge) and the iptables/nftables input rules, and rates each reachable service high, medium, low, or info"),
+			mcp.WithString("kind", mcp.Description("Socket type filter: tcp, udp, or all"),
+				mcp.Enum("tcp", "udp", "all"))),
+			h.HandleAuditExposedServices)
+	} else {
+		h.skipTool("audit_exposed_services", "requires Linux")
+	}
+
+	// Audio status tool
+	if h.caps.ALSA || h.caps.Pactl {
+		h.addTool(s, mcp.NewTool("get_audio_status",
+			mcp.WithDescription("Get ALSA sound cards and the PipeWire/PulseAudio default sink and source, volume, mute state, and active streams")),
+			h.HandleGetAudioStatus)
+	} else {
+		h.skipTool("get_audio_status", "no ALSA sound cards (/proc/asound) and pactl not found in PATH")
+	}
+
+	// Connectivity check tool
+	h.addTool(s, mcp.NewTool("check_connectivity",
+		mcp.WithDescription("Probe reachability of hosts (ICMP ping or TCP connect), time DNS lookups, and check the default gateway to tell a slow host from a network outage"),
+		mcp.WithString("hosts", mcp.Description("Comma-separated hosts to probe; use host:port for a TCP connect (default: none)")),
+		mcp.WithString("dns_names", mcp.Description("Comma-separated names to resolve with the system resolver (default: none)")),
+		mcp.WithString("method", mcp.Description("Probe method for hosts without a port: icmp (default) or tcp (port 443)"),
+			mcp.Enum(probeICMP, probeTCP)),
+		mcp.WithNumber("timeout_ms", mcp.Description("Per-probe timeout in milliseconds (default: 2000, max: 10000)")),
+		mcp.WithBoolean("check_gateway", mcp.Description("Detect and ping the default gateway (default: true)"))),
+		h.HandleCheckConnectivity)
+
+	// Raspberry Pi firmware status tool
+	if h.caps.Vcgencmd {
+		h.addTool(s, mcp.NewTool("get_rpi_status",
+			mcp.WithDescription("Get Raspberry Pi clocks (arm, core, v3d, emmc), core and SDRAM voltages, config.txt overclock settings, the ARM/GPU memory split, throttle flags, firmware version, and bootloader EEPROM update status")),
+			h.HandleGetRPiStatus)
+	} else {
+		h.skipTool("get_rpi_status", "vcgencmd not found in PATH")
+	}
+
+	// A/B boot slot tool
+	if h.caps.RpiBoot || h.caps.FwPrintenv {
+		h.addTool(s, mcp.NewTool("get_boot_slots",
+			mcp.WithDescription("Get A/B boot slot state for embedded update schemes: the booted slot from the kernel command line, Raspberry Pi tryboot and autoboot.txt partitions, U-Boot boot counter and Mender/RAUC/SWUpdate rollback variables via fw_printenv, and staged or pending bootloader EEPROM updates")),
+			h.HandleGetBootSlots)
+	} else {
+		h.skipTool("get_boot_slots", "no Raspberry Pi bootloader device tree node and fw_printenv not found in PATH")
+	}
+
+	// Display status tool
+	if h.caps.DRM || h.caps.Vcgencmd {
+		h.addTool(s, mcp.NewTool("get_display_status",
+			mcp.WithDescription("Get connected displays (HDMI, DSI, composite) with monitor name, current resolution and refresh rate, DPMS state, and Raspberry Pi display power")),
+			h.HandleGetDisplayStatus)
+	} else {
+		h.skipTool("get_display_status", "no DRM devices (/sys/class/drm) and vcgencmd not found in PATH")
+	}
+
+	// Hardware inventory tool
+	if h.caps.USB || h.caps.PCI {
+		h.addTool(s, mcp.NewTool("get_hardware_inventory",
+			mcp.WithDescription("List USB devices (bus, port, vendor/product, class, driver, negotiated speed, block devices) and PCI devices (class, driver, PCIe link speed and width), plus the Raspberry Pi HAT EEPROM when fitted; flags storage running at USB 2 speed on systems with USB 3 ports"),
+			mcp.WithString("bus", mcp.Description("Which bus to list: usb, pci, or all (default: all)"))),
+			h.HandleGetHardwareInventory)
+	} else {
+		h.skipTool("get_hardware_inventory", "no USB or PCI devices in sysfs (/sys/bus/usb, /sys/bus/pci)")
+	}
+
+	// HTTP endpoint check tool
+	h.addTool(s, mcp.NewTool("check_http_endpoints",
+		mcp.WithDescription("Check HTTP(S) endpoints for status code, latency, TLS certificate expiry, and redirect chain"),
+		mcp.WithString("urls", mcp.Required(), mcp.Description("Comma-separated http:// or https:// URLs to check (max 10)")),
+		mcp.WithString("method", mcp.Description("HTTP method: GET (default) or HEAD"),
+			mcp.Enum("GET", "HEAD")),
+		mcp.WithNumber("timeout_ms", mcp.Description("Per-request timeout in milliseconds, including redirects (default: 5000, max: 30000)")),
+		mcp.WithBoolean("follow_redirects", mcp.Description("Follow redirects and report the chain (default: true)")),
+		mcp.WithBoolean("skip_tls_verify", mcp.Description("Accept self-signed or otherwise invalid certificates (default: false)"))),
+		h.HandleCheckHTTPEndpoints)
+
+	// TLS certificate tool
+	h.addTool(s, mcp.NewTool("get_tls_cert_info",
+		mcp.WithDescription("Get issuer, SANs, and days until expiry of certificates served by TLS endpoints or stored in local PEM files, flagging those expiring soon"),
+		mcp.WithString("endpoints", mcp.Description("Comma-separated host:port pairs to connect to (port defaults to 443)")),
+		mcp.WithString("files", mcp.Description("Comma-separated paths to local PEM certificate files")),
+		mcp.WithNumber("warn_days", mcp.Description("Flag certificates expiring within this many days (default: 30)")),
+		mcp.WithNumber("timeout_ms", mcp.Description("Per-endpoint connection timeout in milliseconds (default: 2000, max: 10000)"))),
+		h.HandleGetTLSCertInfo, skipSelfTest("requires endpoints or files"))
+
+	// Smart-home coordinator tool
+	if h.cfg.Zigbee2MQTTURL != "" || h.cfg.ZWaveJSURL != "" {
+		h.addTool(s, mcp.NewTool("get_smarthome_status",
+			mcp.WithDescription("Get Zigbee2MQTT bridge state, device counts, and permit-join status and Z-Wave JS controller, node health, and inclusion state"),
+			mcp.WithString("backend", mcp.Description("Coordinator to query: zigbee2mqtt, zwave_js, or all (default: all)"),
+				mcp.Enum(smarthome.BackendZigbee2MQTT, smarthome.BackendZWaveJS, kindAll))),
+			h.HandleGetSmartHomeStatus)
+	} else {
+		h.skipTool("get_smarthome_status", "both --zigbee2mqtt-url and --zwave-js-url are empty")
+	}
+
+	// Self-update tools
+	h.addTool(s, mcp.NewTool("check_server_update",
+		mcp.WithDescription("Compare the running server version against the latest GitHub release and report whether an update is available"),
+		mcp.WithBoolean("force", mcp.Description("Bypass the one-hour release cache (ignored with --update-offline)"))),
+		h.HandleCheckServerUpdate, skipSelfTest("contacts GitHub"))
+
+	h.addTool(s, mcp.NewTool("apply_update",
+		mcp.WithDescription("Download the latest release, verify its checksum, and replace this server binary, keeping the previous binary for rollback. The server must be restarted afterwards."),
+		mcp.WithBoolean("confirm", mcp.Required(), mcp.Description("Must be true to replace the binary")),
+		mcp.WithBoolean("rollback", mcp.Description("Restore the binary replaced by the last update instead of installing a new one"))),
+		h.HandleApplyUpdate, hostAction("replaces the server binary"))
+
+	// Wi-Fi status tool
+	if h.caps.Iw || h.caps.Wireless {
+		h.addTool(s, mcp.NewTool("get_wifi_status",
+			mcp.WithDescription("Get Wi-Fi SSID, signal strength (dBm), link quality, bitrate, channel and band, and TX retries for wireless interfaces"),
+			mcp.WithString("interface", mcp.Description("Optional wireless interface name (e.g. wlan0)"))),
+			h.HandleGetWifiStatus)
+	} else {
+		h.skipTool("get_wifi_status", "neither iw nor /proc/net/wireless is available")
+	}
+
+	// Kernel crash log tool
+	if h.caps.Pstore {
+		h.addTool(s, mcp.NewTool("get_crash_logs",
+			mcp.WithDescription("Get kernel panic and oops logs saved by pstore (/sys/fs/pstore, /var/lib/systemd/pstore) during previous boots, newest first"),
+			mcp.WithNumber("limit", mcp.Description("Maximum number of records to return (default: 5, max: 50)")),
+			mcp.WithNumber("lines", mcp.Description("Number of trailing log lines per record, 0 for none (default: 50, max: 500)")),
+			mcp.WithBoolean("include_all", mcp.Description("Also include console, pmsg, ftrace, and mce records (default: dmesg crash records only)"))),
+			h.HandleGetCrashLogs)
+	} else {
+		h.skipTool("get_crash_logs", "no pstore filesystem (/sys/fs/pstore or /var/lib/systemd/pstore)")
+	}
+
+	// Boot device and driver failure tool
+	if runtime.GOOS == "linux" {
+		h.addTool(s, mcp.NewTool("get_boot_diagnostics",
+			mcp.WithDescription("Summarize the current boot's device and driver failures from the kernel log: devices that failed to probe or are stuck in deferred probe, firmware files that failed to load, and modules that could not be found or loaded, with the error code and a likely cause"),
+			mcp.WithNumber("limit", mcp.Description("Maximum failures listed per category (default: 20, max: 200)"))),
+			h.HandleGetBootDiagnostics)
+	} else {
+		h.skipTool("get_boot_diagnostics", "requires Linux")
+	}
+
+	// OOM kill tool
+	if runtime.GOOS == "linux" {
+		h.addTool(s, mcp.NewTool("get_oom_events",
+			mcp.WithDescription("Find recent OOM kills in the kernel log (and systemd-oomd kills): the victim process, its PID, UID, oom_score_adj, and memory use, the cgroup or unit it ran in, what triggered the kill, and the system or cgroup memory state at the time, newest first"),
+			mcp.WithString("since", mcp.Description("How far back to search, e.g. 6h or 7d (default: 7d)")),
+			mcp.WithNumber("limit", mcp.Description("Maximum number of events to return (default: 20, max: 100)"))),
+			h.HandleGetOOMEvents)
+	} else {
+		h.skipTool("get_oom_events", "requires Linux")
+	}
+
+	// SSH security posture tool
+	if h.caps.SSHD {
+		h.addTool(s, mcp.NewTool("get_ssh_security",
+			mcp.WithDescription("Summarize SSH exposure: failed login attempts from the journal or auth.log counted by source IP and username, successful logins after failures, addresses currently banned by fail2ban, and sshd configuration red flags such as password authentication or root login being allowed"),
+			mcp.WithString("since", mcp.Description("How far back to count login attempts, e.g. 6h or 7d (default: 24h)")),
+			mcp.WithNumber("limit", mcp.Description("Maximum source addresses and usernames listed (default: 10, max: 100)"))),
+			h.HandleGetSSHSecurity)
+	} else {
+		h.skipTool("get_ssh_security", "requires Linux with an OpenSSH server (/etc/ssh/sshd_config)")
+	}
+
+	// Scheduled jobs tool
+	if runtime.GOOS == "linux" {
+		h.addTool(s, mcp.NewTool("get_scheduled_jobs",
+			mcp.WithDescription("List scheduled jobs: systemd timers with their schedule, next and last run, and the result of the unit they activate, plus system and per-user crontab entries and cron.daily-style scripts. Timers whose last run failed are flagged."),
+			mcp.WithBoolean("failed_only", mcp.Description("Only return timers whose last run failed (default: false)"))),
+			h.HandleGetScheduledJobs)
+	} else {
+		h.skipTool("get_scheduled_jobs", "requires Linux")
+	}
+
+	// Metrics history tool
+	if h.history != nil {
+		h.addTool(s, mcp.NewTool("query_metrics",
+			mcp.WithDescription("Query persisted metrics history with min/max/avg per time bucket; omit metric to list stored series"),
+			mcp.WithString("metric", mcp.Description("Metric name, e.g. cpu_percent, memory_used_percent, disk_used_percent, load1 (omit to list stored series)")),
+			mcp.WithString("labels", mcp.Description("Optional series filter as key=value pairs, e.g. mount=/ or interface=eth0")),
+			mcp.WithString("range", mcp.Description("How far back to query, e.g. 30m, 6h, 7d (default: 1h)")),
+			mcp.WithString("bucket", mcp.Description("Aggregation bucket width, e.g. 1m, 15m, 1h (default: about 60 buckets over the range)"))),
+			h.HandleQueryMetrics)
+		h.addTool(s, mcp.NewTool("get_events",
+			mcp.WithDescription("Get the event journal as an incident timeline, newest first: health alerts raised and resolved, CPU throttling and under-voltage episodes, failed units, OOM kills, reboots, watched process restarts, watched service state changes and restarts, synthetic check outages, and calls to state-changing tools"),
+			mcp.WithString("range", mcp.Description("How far back to list events, e.g. 6h, 24h, 7d (default: 24h)")),
+			mcp.WithString("category", mcp.Description("Optional comma-separated categories: alert, throttle, service_failure, oom_kill, reboot, process_restart, action, synthetic, service_state (default: all)")),
+			mcp.WithString("severity", mcp.Description("Minimum severity: info, warning, or critical (default: info)")),
+			mcp.WithNumber("limit", mcp.Description("Maximum number of events (default: 100, at most 1000)"))),
+			h.HandleGetEvents)
+		h.addTool(s, mcp.NewTool("correlate_events",
+			mcp.WithDescription("Correlate an event or time range with the metric changes and other events around it, each placed relative to it (e.g. CPU rose 3 minutes before throttling started), as one merged timeline"),
+			mcp.WithString("time", mcp.Description("RFC 3339 timestamp to investigate, e.g. an event time from get_events; with category or subject, the newest matching event at or before it")),
+			mcp.WithString("until", mcp.Description("Optional RFC 3339 end of a range starting at time")),
+			mcp.WithString("category", mcp.Description("Anchor on the newest event of this category in the last day: alert, throttle, service_failure, oom_kill, reboot, process_restart, action, synthetic, service_state")),
+			mcp.WithString("subject", mcp.Description("Anchor on the newest event with this subject, e.g. a unit, process, or check name")),
+			mcp.WithString("window", mcp.Description("How far before and after the anchor to look, e.g. 5m, 30m (default: 15m, at most 6h)"))),
+			h.HandleCorrelateEvents)
+		h.addTool(s, mcp.NewTool("detect_anomalies",
+			mcp.WithDescription("Compare current metrics with their rolling baseline from history (z-score or percentile) and report which are abnormal and since when"),
+			mcp.WithString("metric", mcp.Description("Optional metric to check, e.g. cpu_percent (default: all stored metrics)")),
+			mcp.WithString("baseline", mcp.Description("Baseline period before the recent window, e.g. 6h, 24h, 7d (default: 24h)")),
+			mcp.WithString("window", mcp.Description("Recent window treated as current, e.g. 5m, 15m (default: 15m)")),
+			mcp.WithString("method", mcp.Description("Detection method: zscore or percentile (default: zscore)")),
+			mcp.WithNumber("threshold", mcp.Description("Z-score magnitude (default: 3) or upper percentile cutoff between 50 and 100 (default: 99)")),
+			mcp.WithBoolean("include_normal", mcp.Description("Also list series within their normal range (default: false)"))),
+			h.HandleDetectAnomalies)
+		h.addTool(s, mcp.NewTool("get_health_report",
+			mcp.WithDescription("Assemble a health report over a period (default: the last day) from scheduled snapshots and metrics history: metric peaks, alerts fired, and disk growth, as JSON or Markdown ready to summarize or email"),
+			mcp.WithString("period", mcp.Description("How far back the report covers, e.g. 12h, 24h, 7d (default: 24h)")),
+			mcp.WithString("format", mcp.Description("Output format: json, markdown (a formatted report), or summary (one paragraph) (default: json)"))),
+			h.HandleGetHealthReport)
+		h.addTool(s, mcp.NewTool("forecast_disk_usage",
+			mcp.WithDescription("Forecast per-mount disk usage from sampled history with a linear or exponential fit and estimate the days until 90% and 100% full"),
+			mcp.WithString("mount", mcp.Description("Optional mount point to forecast, e.g. / (default: every sampled mount)")),
+			mcp.WithString("range", mcp.Description("How much history to fit, e.g. 24h, 7d, 30d (default: 7d)")),
+			mcp.WithString("model", mcp.Description("Fit model: auto (best R²), linear, or exponential (default: auto)"))),
+			h.HandleForecastDiskUsage)
+		h.addTool(s, mcp.NewTool("export_history",
+			mcp.WithDescription("Export metrics history, health snapshots, benchmark baselines, and the availability ledger to a compressed portable archive, to keep the data across an SD card reimage or move it to a new device"),
+			mcp.WithString("path", mcp.Description("Archive file to create; relative paths are under the history database directory (default: sysmetrics-archive-<timestamp>.jsonl.gz). Existing files are never overwritten"))),
+			h.HandleExportHistory, sideEffect("writes an archive file"), longRunning)
+		h.addTool(s, mcp.NewTool("import_history",
+			mcp.WithDescription("Import an archive written by export_history, merging its metrics history, health snapshots, baselines, and boots with what this server already has; records already present are skipped"),
+			mcp.WithString("path", mcp.Required(), mcp.Description("Archive file to import; relative paths are under the history database directory"))),
+			h.HandleImportHistory, hostAction("merges an archive into the history"))
+	} else {
+		h.skipTool("query_metrics", "--history-db is not set")
+		h.skipTool("get_events", "--history-db is not set")
+		h.skipTool("correlate_events", "--history-db is not set")
+		h.skipTool("detect_anomalies", "--history-db is not set")
+		h.skipTool("get_health_report", "--history-db is not set")
+		h.skipTool("forecast_disk_usage", "--history-db is not set")
+		h.skipTool("export_history", "--history-db is not set")
+		h.skipTool("import_history", "--history-db is not set")
+	}
+
+	// Synthetic monitoring results (the checks run in the background and store their results in history)
+	if len(h.cfg.SyntheticChecks) > 0 && h.history != nil {
+		h.addTool(s, mcp.NewTool("get_synthetic_results",
+			mcp.WithDescription("Get the results of the recurring --synthetic-checks (HTTP, TCP, ping, DNS): current state and since when, uptime percentage over 24h, 7d, and 30d, average latency, and recent outages"),
+			mcp.WithString("check", mcp.Description("Optional check to report, by name (e.g. tcp:nas.lan:445) or target (default: all checks)")),
+			mcp.WithString("range", mcp.Description("How far back to list outages, e.g. 6h, 24h, 7d (default: 24h)"))),
+			h.HandleGetSyntheticResults)
+	} else {
+		h.skipTool("get_synthetic_results", "--synthetic-checks is not set")
+	}
+
+	// Service flap tool
+	switch {
+	case len(h.cfg.WatchServices) == 0:
+		h.skipTool("get_service_flaps", "--watch-services is not set")
+	case !h.caps.Systemd:
+		h.skipTool("get_service_flaps", "systemd not detected")
+	default:
+		h.addTool(s, mcp.NewTool("get_service_flaps",
+			mcp.WithDescription("Report restart loops in the --watch-services units: restarts, state changes, and failures over a range from the background watcher, the current state, and recent transitions, flagging units that are crash-looping even when they currently show as active"),
+			mcp.WithString("service", mcp.Description("Optional watched unit to report (default: all watched units)")),
+			mcp.WithString("range", mcp.Description("How far back to count, e.g. 30m, 6h, 7d (default: 1h)")),
+			mcp.WithNumber("threshold", mcp.Description("Restarts plus failures within the range that mark a unit as flapping (default: 3)"))),
+			h.HandleGetServiceFlaps)
+	}
+
+	// Fleet tools (query the --fleet-hosts agents over gRPC alongside this host)
+	if len(h.fleet) > 0 {
+		h.addTool(s, mcp.NewTool("compare_hosts",
+			mcp.WithDescription("Compare one current metric across this host and every --fleet-hosts agent side by side, highest first, with the fleet min, max, and median"),
+			mcp.WithString("metric", mcp.Required(), mcp.Description("Metric name, e.g. cpu_percent, memory_used_percent, disk_used_percent, load1, cpu_temperature_celsius")),
+			mcp.WithString("labels", mcp.Description("Optional series filter as key=value pairs, e.g. mount=/ or interface=eth0"))),
+			h.HandleCompareHosts)
+		h.addTool(s, mcp.NewTool("find_outlier_hosts",
+			mcp.WithDescription("Find the fleet hosts whose current metrics deviate most from the fleet median (robust z-score), ranked worst first"),
+			mcp.WithString("metrics", mcp.Description("Optional comma-separated metrics to compare (default: cpu_percent, load1, memory_used_percent, swap_used_percent, disk_used_percent, cpu_temperature_celsius)")),
+			mcp.WithNumber("threshold", mcp.Description("Robust z-score magnitude that marks an outlier (default: 3)"))),
+			h.HandleFindOutlierHosts)
+	} else {
+		h.skipTool("compare_hosts", "--fleet-hosts is not set")
+		h.skipTool("find_outlier_hosts", "--fleet-hosts is not set")
+	}
+
+	for name, reason := range h.skippedTools {
+		h.logger.Debug("tool skipped", "tool", name, "reason", reason)
+	}
+	h.logger.Info("tools registered", "registered", len(h.registered), "skipped", len(h.skippedTools))
+}
+
+// HandleGetServerInfo returns server metadata and capability detection results
+func (h *HandlerManager) HandleGetServerInfo(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	registered := h.registered
+	if registered == nil {
+		registered = []string{}
+	}
+
+	result := map[string]interface{}{
+		"name":             config.ServerName,
+		"version":          config.ServerVersion,
+		"go_version":       runtime.Version(),
+		"os":               runtime.GOOS,
+		"arch":             runtime.GOARCH,
+		"capabilities":     h.caps,
+		"registered_tools": registered,
+		"skipped_tools":    h.skippedTools,
+		"tool_profile":     h.cfg.ToolProfile,
+		"geoip_enabled":    h.geo != nil,
+		"recovered_panics": h.panics.Load(),
+		"locale":           h.human.Name(),
+		"history_enabled":  h.history != nil,
+		"response_budget": map[string]interface{}{
+			"max_bytes":       h.cfg.MaxResponseBytes,
+			"truncated_count": h.truncatedResponses.Load(),
+		},
+		"middleware": middlewareNames(h.toolMiddleware()),
+		"collectors": h.collectorNames(),
+	}
+	if h.cfg.RateLimit > 0 {
+		result["rate_limit"] = map[string]interface{}{
+			"calls_per_minute": h.cfg.RateLimit,
+			"rejected_count":   h.rateLimited.Load(),
+		}
+	}
+	if h.cachingEnabled() {
+		cacheInfo := map[string]interface{}{
+			"ttl_seconds": h.cfg.CacheTTL.Seconds(),
+			"hit_count":   h.cacheHits.Load(),
+		}
+		if len(h.cfg.CacheToolTTLs) > 0 {
+			toolTTLs := make(map[string]float64, len(h.cfg.CacheToolTTLs))
+			for name, ttl := range h.cfg.CacheToolTTLs {
+				toolTTLs[name] = ttl.Seconds()
+			}
+			cacheInfo["tool_ttl_seconds"] = toolTTLs
+		}
+		result["cache"] = cacheInfo
+	}
+	if h.cfg.ToolTimeout > 0 {
+		result["tool_timeout"] = map[string]interface{}{
+			"seconds":         h.cfg.ToolTimeout.Seconds(),
+			"timed_out_count": h.timedOut.Load(),
+		}
+	}
+	if h.exporter != nil {
+		result["exporter"] = h.exporter.Stats()
+	}
+	if h.redactor != nil {
+		result["redaction"] = map[string]interface{}{
+			"kinds":    h.cfg.Redact,
+			"patterns": len(h.cfg.RedactPatterns),
+			"mode":     h.cfg.RedactMode,
+		}
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
 }
 
 // HandleGetSystemInfo returns system information
 func (h *HandlerManager) HandleGetSystemInfo(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	info, err := host.Info()
-	if err != nil {
+	// Host info and the boot history, which reads the journal, are collected concurrently
+	type bootInfo struct {
+		reason   bootReason
+		previous *previousBoot
+	}
+	finished := collectConcurrently(ctx, map[string]collectTask{
+		"host": func(ctx context.Context) (any, error) {
+			return host.InfoWithContext(ctx)
+		},
+		"boot": func(ctx context.Context) (any, error) {
+			reason, prev := h.bootHistory(ctx)
+			return bootInfo{reason: reason, previous: prev}, nil
+		},
+	})
+	info, ok := collectedValue[*host.InfoStat](finished, "host")
+	if !ok {
+		err := finished["host"].err
+		if err == nil {
+			err = ctx.Err()
+		}
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to get system info: %v", err)), nil
 	}
 
@@ -149,7 +1020,7 @@ func (h *HandlerManager) HandleGetSystemInfo(ctx context.Context, request mcp.Ca
 		"kernel_version":   info.KernelVersion,
 		"kernel_arch":      info.KernelArch,
 		"uptime_seconds":   info.Uptime,
-		"uptime_human":     uptime.String(),
+		"uptime_human":     h.human.Duration(uptime),
 		// BootTime is unix timestamp (uint64). Standard unix time fits in int64 until year 2038+ (actually much later for 64-bit).
 		//nolint:gosec // G115: integer overflow conversion safe for standard unix timestamps
 		"boot_time":  time.Unix(int64(info.BootTime), 0).Format(time.RFC3339),
@@ -157,6 +1028,13 @@ func (h *HandlerManager) HandleGetSystemInfo(ctx context.Context, request mcp.Ca
 		"go_version": runtime.Version(),
 	}
 
+	if boot, ok := collectedValue[bootInfo](finished, "boot"); ok {
+		result["last_boot_reason"] = boot.reason
+		if boot.previous != nil {
+			result["previous_boot"] = boot.previous
+		}
+	}
+
 	jsonBytes, err := json.Marshal(result)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
@@ -175,25 +1053,25 @@ func (h *HandlerManager) HandleGetCPUMetrics(ctx context.Context, request mcp.Ca
 	}
 
 	// Get CPU usage
-	percentages, err := cpu.Percent(0, false)
+	usage, err := readCPUPercent(ctx)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to get CPU usage: %v", err)), nil
 	}
 
 	// Get per-CPU usage
-	perCPU, err := cpu.Percent(0, true)
+	perCPU, err := cpu.PercentWithContext(ctx, 0, true)
 	if err != nil {
 		perCPU = []float64{}
 	}
 
 	// Get CPU info
-	cpuInfo, err := cpu.Info()
+	cpuInfo, err := cpu.InfoWithContext(ctx)
 	if err != nil {
 		cpuInfo = []cpu.InfoStat{}
 	}
 
 	// Get load average
-	loadAvg, err := load.Avg()
+	loadAvg, err := load.AvgWithContext(ctx)
 	if err != nil {
 		loadAvg = &load.AvgStat{}
 	}
@@ -203,7 +1081,7 @@ func (h *HandlerManager) HandleGetCPUMetrics(ctx context.Context, request mcp.Ca
 	temps := config.ConvertTemperature(tempCelsius, tempUnit)
 
 	result := map[string]interface{}{
-		"usage_percent":         percentages[0],
+		"usage_percent":         usage,
 		"per_cpu_percent":       perCPU,
 		"core_count":            len(perCPU),
 		"physical_cores":        runtime.NumCPU(),
@@ -219,6 +1097,15 @@ func (h *HandlerManager) HandleGetCPUMetrics(ctx context.Context, request mcp.Ca
 		result["mhz"] = cpuInfo[0].Mhz
 	}
 
+	if runtime.GOOS == "windows" {
+		result["load_average_source"] = "processor_queue_length"
+	}
+
+	// Per-core frequency scaling, where the kernel exposes cpufreq
+	if cores, err := readCoreFreqs(cpuSysPath); err == nil {
+		result["frequency"] = cores
+	}
+
 	jsonBytes, err := json.Marshal(result)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
@@ -228,39 +1115,53 @@ func (h *HandlerManager) HandleGetCPUMetrics(ctx context.Context, request mcp.Ca
 
 // HandleGetMemoryMetrics returns memory metrics
 func (h *HandlerManager) HandleGetMemoryMetrics(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	memInfo, err := mem.VirtualMemory()
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get memory info: %v", err)), nil
+	sampleSeconds := defaultPageCacheSampleSeconds
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if s, ok := args["sample_seconds"].(float64); ok && s >= 0 {
+			sampleSeconds = int(s)
+			if sampleSeconds > maxPageCacheSampleSeconds {
+				sampleSeconds = maxPageCacheSampleSeconds
+			}
+		}
 	}
 
-	swapInfo, err := mem.SwapMemory()
+	memInfo, swapInfo, err := readMemory(ctx)
 	if err != nil {
-		swapInfo = &mem.SwapMemoryStat{}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get memory info: %v", err)), nil
 	}
 
+	swap := map[string]interface{}{
+		"total_bytes":   swapInfo.Total,
+		"total_human":   h.human.Bytes(swapInfo.Total),
+		"used_bytes":    swapInfo.Used,
+		"used_human":    h.human.Bytes(swapInfo.Used),
+		"free_bytes":    swapInfo.Free,
+		"free_human":    h.human.Bytes(swapInfo.Free),
+		"usage_percent": swapInfo.UsedPercent,
+	}
 	result := map[string]interface{}{
 		"ram": map[string]interface{}{
 			"total_bytes":     memInfo.Total,
-			"total_human":     config.BytesToHuman(memInfo.Total),
+			"total_human":     h.human.Bytes(memInfo.Total),
 			"available_bytes": memInfo.Available,
-			"available_human": config.BytesToHuman(memInfo.Available),
+			"available_human": h.human.Bytes(memInfo.Available),
 			"used_bytes":      memInfo.Used,
-			"used_human":      config.BytesToHuman(memInfo.Used),
+			"used_human":      h.human.Bytes(memInfo.Used),
 			"free_bytes":      memInfo.Free,
-			"free_human":      config.BytesToHuman(memInfo.Free),
+			"free_human":      h.human.Bytes(memInfo.Free),
 			"usage_percent":   memInfo.UsedPercent,
 			"buffers_bytes":   memInfo.Buffers,
 			"cached_bytes":    memInfo.Cached,
 		},
-		"swap": map[string]interface{}{
-			"total_bytes":   swapInfo.Total,
-			"total_human":   config.BytesToHuman(swapInfo.Total),
-			"used_bytes":    swapInfo.Used,
-			"used_human":    config.BytesToHuman(swapInfo.Used),
-			"free_bytes":    swapInfo.Free,
-			"free_human":    config.BytesToHuman(swapInfo.Free),
-			"usage_percent": swapInfo.UsedPercent,
-		},
+		"swap": swap,
+	}
+	pageCache, sample := h.getPageCacheMetrics(ctx, memInfo, sampleSeconds)
+	result["page_cache"] = pageCache
+	if sample != nil {
+		swap["activity"] = swapActivity(sample)
+	}
+	if compressed, ok := h.getCompressedMemory(memInfo.Total); ok {
+		result["compressed_memory"] = compressed
 	}
 
 	jsonBytes, err := json.Marshal(result)
@@ -287,23 +1188,53 @@ func (h *HandlerManager) HandleGetDiskMetrics(ctx context.Context, request mcp.C
 
 	// If no mount points specified, get all partitions
 	if len(mountPoints) == 0 {
-		partitions, err := disk.Partitions(false)
+		partitions, err := disk.PartitionsWithContext(ctx, false)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Failed to get disk partitions: %v", err)), nil
 		}
 		for _, p := range partitions {
-			// Skip special filesystems
-			if p.Fstype == "tmpfs" || p.Fstype == "devtmpfs" || p.Fstype == "squashfs" {
+			if skipPartition(p) {
 				continue
 			}
 			mountPoints = append(mountPoints, p.Mountpoint)
 		}
 	}
 
+	// Unresponsive network mounts would hang the usage query, so they are reported instead
+	problems := map[string]mountProblem{}
+	mountProblems := h.checkMounts(ctx)
+	for _, p := range mountProblems {
+		problems[p.MountPoint] = p
+	}
+
+	// An overlay root on tmpfs reports RAM usage for /, so say where writes really land
+	root, rootOK := readRootStorage(mountsPath)
+
+	var readable []string
+	for _, mp := range mountPoints {
+		if p, ok := problems[mp]; !ok || p.Problem == mountReadOnly {
+			readable = append(readable, mp)
+		}
+	}
+	// Mounts that cannot be read are left out
+	usages, _ := readMountUsage(ctx, readable)
+	usageByMount := make(map[string]*disk.UsageStat, len(usages))
+	for _, u := range usages {
+		usageByMount[u.Path] = u
+	}
+
 	diskData := []map[string]interface{}{}
 	for _, mp := range mountPoints {
-		usage, err := disk.Usage(mp)
-		if err != nil {
+		if p, ok := problems[mp]; ok && p.Problem != mountReadOnly {
+			diskData = append(diskData, map[string]interface{}{
+				"mount_point": mp,
+				"fstype":      p.Fstype,
+				"problem":     p.Problem,
+			})
+			continue
+		}
+		usage, ok := usageByMount[mp]
+		if !ok {
 			continue
 		}
 
@@ -316,10 +1247,21 @@ func (h *HandlerManager) HandleGetDiskMetrics(ctx context.Context, request mcp.C
 			"fstype":        usage.Fstype,
 		}
 
+		// APFS volumes share free space within their container, so totals are not additive
+		if usage.Fstype == "apfs" {
+			diskInfo["apfs_shared_container"] = true
+		}
+		if p, ok := problems[mp]; ok {
+			diskInfo["problem"] = p.Problem
+		}
+		if mp == "/" && rootOK && !root.Persistent {
+			diskInfo["volatile"] = true
+		}
+
 		if humanReadable {
-			diskInfo["total_human"] = config.BytesToHuman(usage.Total)
-			diskInfo["used_human"] = config.BytesToHuman(usage.Used)
-			diskInfo["free_human"] = config.BytesToHuman(usage.Free)
+			diskInfo["total_human"] = h.human.Bytes(usage.Total)
+			diskInfo["used_human"] = h.human.Bytes(usage.Used)
+			diskInfo["free_human"] = h.human.Bytes(usage.Free)
 		}
 
 		diskData = append(diskData, diskInfo)
@@ -328,6 +1270,12 @@ func (h *HandlerManager) HandleGetDiskMetrics(ctx context.Context, request mcp.C
 	result := map[string]interface{}{
 		"disks": diskData,
 	}
+	if len(mountProblems) > 0 {
+		result["mount_problems"] = mountProblems
+	}
+	if rootOK {
+		result["root_storage"] = root
+	}
 
 	jsonBytes, err := json.Marshal(result)
 	if err != nil {
@@ -336,6 +1284,12 @@ func (h *HandlerManager) HandleGetDiskMetrics(ctx context.Context, request mcp.C
 	return mcp.NewToolResultText(string(jsonBytes)), nil
 }
 
+// networkFields lists the columns get_network_metrics can return
+var networkFields = []string{
+	"interface", "bytes_sent", "bytes_recv", "packets_sent", "packets_recv",
+	"errors_in", "errors_out", "drops_in", "drops_out", "ip_addresses",
+}
+
 // HandleGetNetworkMetrics returns network metrics
 func (h *HandlerManager) HandleGetNetworkMetrics(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Get interfaces from args or config
@@ -347,16 +1301,23 @@ func (h *HandlerManager) HandleGetNetworkMetrics(ctx context.Context, request mc
 		}
 	}
 
-	// Get all network stats
-	netIO, err := net.IOCounters(true)
+	fields, err := parseFieldsArg(request, networkFields)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get network stats: %v", err)), nil
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	// Get interface addresses
-	interfacesList, err := net.Interfaces()
+	// Get the stats of the requested interfaces
+	netIO, err := readInterfaceCounters(ctx, interfaces)
 	if err != nil {
-		interfacesList = []net.InterfaceStat{}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get network stats: %v", err)), nil
+	}
+
+	// Get interface addresses, unless they were left out of fields
+	interfacesList := []net.InterfaceStat{}
+	if wantField(fields, "ip_addresses") {
+		if list, err := net.InterfacesWithContext(ctx); err == nil {
+			interfacesList = list
+		}
 	}
 
 	// Build interface address map
@@ -372,16 +1333,6 @@ func (h *HandlerManager) HandleGetNetworkMetrics(ctx context.Context, request mc
 	// Filter and format results
 	netData := []map[string]interface{}{}
 	for _, io := range netIO {
-		// Skip loopback by default unless explicitly requested
-		if io.Name == "lo" && !contains(interfaces, "lo") {
-			continue
-		}
-
-		// If specific interfaces requested, filter
-		if len(interfaces) > 0 && !contains(interfaces, io.Name) {
-			continue
-		}
-
 		netInfo := map[string]interface{}{
 			"interface":    io.Name,
 			"bytes_sent":   io.BytesSent,
@@ -395,7 +1346,7 @@ func (h *HandlerManager) HandleGetNetworkMetrics(ctx context.Context, request mc
 			"ip_addresses": addrMap[io.Name],
 		}
 
-		netData = append(netData, netInfo)
+		netData = append(netData, projectFields(netInfo, fields))
 	}
 
 	result := map[string]interface{}{
@@ -409,93 +1360,6 @@ func (h *HandlerManager) HandleGetNetworkMetrics(ctx context.Context, request mc
 	return mcp.NewToolResultText(string(jsonBytes)), nil
 }
 
-// HandleGetProcessList returns process list
-func (h *HandlerManager) HandleGetProcessList(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	limit := h.cfg.MaxProcesses
-	sortBy := "cpu"
-
-	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
-		if l, ok := args["limit"].(float64); ok && l > 0 {
-			limit = int(l)
-			if limit > 50 {
-				limit = 50
-			}
-		}
-		if s, ok := args["sort_by"].(string); ok && s != "" {
-			sortBy = strings.ToLower(s)
-		}
-	}
-
-	processes, err := process.Processes()
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get processes: %v", err)), nil
-	}
-
-	type procInfo struct {
-		PID        int32    `json:"pid"`
-		Name       string   `json:"name"`
-		CPU        float64  `json:"cpu_percent"`
-		Memory     float32  `json:"memory_percent"`
-		RSS        uint64   `json:"rss_bytes"`
-		Status     []string `json:"status"`
-		CreateTime int64    `json:"create_time"`
-	}
-
-	procList := []procInfo{}
-	for _, p := range processes {
-		name, _ := p.Name()
-		cpu, _ := p.CPUPercent()
-		mem, _ := p.MemoryPercent()
-		memInfo, _ := p.MemoryInfo()
-		status, _ := p.Status()
-		createTime, _ := p.CreateTime()
-
-		procList = append(procList, procInfo{
-			PID:        p.Pid,
-			Name:       name,
-			CPU:        cpu,
-			Memory:     mem,
-			RSS:        memInfo.RSS,
-			Status:     status,
-			CreateTime: createTime / 1000, // Convert from ms to seconds
-		})
-	}
-
-	// Sort based on criteria
-	switch sortBy {
-	case "memory":
-		sort.Slice(procList, func(i, j int) bool {
-			return procList[i].Memory > procList[j].Memory
-		})
-	case "pid":
-		sort.Slice(procList, func(i, j int) bool {
-			return procList[i].PID < procList[j].PID
-		})
-	default: // cpu
-		sort.Slice(procList, func(i, j int) bool {
-			return procList[i].CPU > procList[j].CPU
-		})
-	}
-
-	// Limit results
-	if len(procList) > limit {
-		procList = procList[:limit]
-	}
-
-	result := map[string]interface{}{
-		"processes": procList,
-		"total":     len(processes),
-		"shown":     len(procList),
-		"sort_by":   sortBy,
-	}
-
-	jsonBytes, err := json.Marshal(result)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
-	}
-	return mcp.NewToolResultText(string(jsonBytes)), nil
-}
-
 // HandleGetThermalStatus returns thermal status
 func (h *HandlerManager) HandleGetThermalStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	tempUnit := h.cfg.TempUnit
@@ -513,14 +1377,14 @@ func (h *HandlerManager) HandleGetThermalStatus(ctx context.Context, request mcp
 	var gpuTempC float64
 	var hasGPUTemp bool
 	if h.cfg.EnableGPU {
-		gpuTempC, hasGPUTemp = config.GetRaspberryPiGPUTemp()
+		gpuTempC, hasGPUTemp = config.GetRaspberryPiGPUTemp(ctx)
 	}
 
 	// Get throttling status (Pi-specific)
 	var throttleStatus map[string]interface{}
 	hasThrottleStatus := false
 	if h.cfg.EnableGPU {
-		throttleStatus, hasThrottleStatus = config.GetThrottledStatus()
+		throttleStatus, hasThrottleStatus = config.GetThrottledStatus(ctx)
 	}
 
 	result := map[string]interface{}{
@@ -547,7 +1411,60 @@ func (h *HandlerManager) HandleGetThermalStatus(ctx context.Context, request mcp
 	if hasThrottleStatus {
 		result["throttling"].(map[string]interface{})["status"] = throttleStatus
 	} else {
-		result["platform"] = "generic_linux"
+		result["platform"] = "generic_" + runtime.GOOS
+	}
+
+	// Every thermal zone, hwmon sensor, and fan, e.g. per-core and NVMe temperatures on a PC
+	// or the Pi 5 active cooler
+	if runtime.GOOS == "linux" {
+		zones := readThermalZones(thermalClassPath)
+		sensors := readHwmonSensors(hwmonClassPath)
+		result["thermal_zones"] = zones
+		result["hwmon_sensors"] = sensors
+		fans := readHwmonFans(hwmonClassPath)
+		result["fans"] = fans
+		if warnings := fanWarnings(fans); len(warnings) > 0 {
+			result["warnings"] = warnings
+		}
+		if spot, ok := hotSpot(zones, sensors); ok {
+			spot["converted"] = config.ConvertTemperature(spot["celsius"].(float64), tempUnit)
+			result["hot_spot"] = spot
+		}
+	}
+
+	// macOS: SMC temperatures, fans, and thermal pressure via powermetrics
+	if runtime.GOOS == "darwin" {
+		result["platform"] = "macos"
+		if h.caps.Powermetrics {
+			if mac, err := h.readMacThermal(ctx); err == nil {
+				if mac.HasCPUTemp {
+					result["cpu_temperature"] = map[string]interface{}{
+						"available": true,
+						"celsius":   mac.CPUTempC,
+						"converted": config.ConvertTemperature(mac.CPUTempC, tempUnit),
+						"unit":      tempUnit,
+					}
+				}
+				if mac.HasGPUTemp {
+					result["gpu_temperature"] = map[string]interface{}{
+						"available": true,
+						"celsius":   mac.GPUTempC,
+						"converted": config.ConvertTemperature(mac.GPUTempC, tempUnit),
+					}
+				}
+				if len(mac.FanRPM) > 0 {
+					result["fans_rpm"] = mac.FanRPM
+				}
+				if mac.HasThermalStatus {
+					result["throttling"] = map[string]interface{}{
+						"available":        true,
+						"thermal_pressure": mac.ThermalPressure,
+					}
+				}
+			} else {
+				result["powermetrics_error"] = fmt.Sprintf("powermetrics failed (requires root or --sudo-allowlist=powermetrics): %v", err)
+			}
+		}
 	}
 
 	jsonBytes, err := json.Marshal(result)
@@ -567,7 +1484,7 @@ func (h *HandlerManager) HandleGetDiskIOMetrics(ctx context.Context, request mcp
 		}
 	}
 
-	ioCounters, err := disk.IOCounters()
+	ioCounters, err := disk.IOCountersWithContext(ctx)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to get disk I/O stats: %v", err)), nil
 	}
@@ -584,9 +1501,9 @@ func (h *HandlerManager) HandleGetDiskIOMetrics(ctx context.Context, request mcp
 			"read_count":   io.ReadCount,
 			"write_count":  io.WriteCount,
 			"read_bytes":   io.ReadBytes,
-			"read_human":   config.BytesToHuman(io.ReadBytes),
+			"read_human":   h.human.Bytes(io.ReadBytes),
 			"write_bytes":  io.WriteBytes,
-			"write_human":  config.BytesToHuman(io.WriteBytes),
+			"write_human":  h.human.Bytes(io.WriteBytes),
 			"read_time":    io.ReadTime,
 			"write_time":   io.WriteTime,
 			"io_time":      io.IoTime,
@@ -607,73 +1524,90 @@ func (h *HandlerManager) HandleGetDiskIOMetrics(ctx context.Context, request mcp
 	return mcp.NewToolResultText(string(jsonBytes)), nil
 }
 
-// HandleGetSystemHealth returns an aggregated system health dashboard
+// healthCollectTimeout bounds get_system_health's collectors, so that a hung root
+// filesystem or network mount cannot stall the dashboard
+const healthCollectTimeout = 10 * time.Second
+
+// HandleGetSystemHealth returns an aggregated system health dashboard. CPU, load, memory,
+// root disk, host, and mount checks are collected concurrently. Memory, the root disk, and
+// host info are required; a CPU, load, or mount check that does not finish within
+// healthCollectTimeout, or before ctx is done, is reported as incomplete.
 func (h *HandlerManager) HandleGetSystemHealth(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	// CPU usage
-	cpuPercent, err := cpu.Percent(0, false)
-	if err != nil {
-		cpuPercent = []float64{0}
-	}
-	cpuUsage := cpuPercent[0]
+	ctx, cancel := context.WithTimeout(ctx, healthCollectTimeout)
+	defer cancel()
 
-	// Load average
-	loadAvg, err := load.Avg()
-	if err != nil {
-		loadAvg = &load.AvgStat{}
-	}
+	// Root disk (system drive on Windows)
+	rootPath := systemRootPath()
 
-	// Memory
-	memInfo, err := mem.VirtualMemory()
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get memory info: %v", err)), nil
+	collectors := map[string]collectTask{
+		"cpu": func(ctx context.Context) (any, error) {
+			return readCPUPercent(ctx)
+		},
+		"load": func(ctx context.Context) (any, error) {
+			return load.AvgWithContext(ctx)
+		},
+		"memory": func(ctx context.Context) (any, error) {
+			ram, _, err := readMemory(ctx)
+			return ram, err
+		},
+		"disk": func(ctx context.Context) (any, error) {
+			usages, err := readMountUsage(ctx, []string{rootPath})
+			if err != nil {
+				return nil, err
+			}
+			return usages[0], nil
+		},
+		"host": func(ctx context.Context) (any, error) {
+			return host.InfoWithContext(ctx)
+		},
+		// A filesystem remounted read-only or a hung network mount silently breaks services
+		"mounts": func(ctx context.Context) (any, error) {
+			return h.checkMounts(ctx), nil
+		},
 	}
-
-	// Root disk
-	rootDisk, err := disk.Usage("/")
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get root disk info: %v", err)), nil
+	finished := collectConcurrently(ctx, collectors)
+
+	// Memory, the root disk, and host info are required, whether they failed or did not
+	// finish in time
+	for _, name := range []string{"memory", "disk", "host"} {
+		c, ok := finished[name]
+		switch {
+		case !ok:
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get %s info: did not finish within %s", name, healthCollectTimeout)), nil
+		case c.err != nil:
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get %s info: %v", name, c.err)), nil
+		}
 	}
-
-	// Uptime
-	info, err := host.Info()
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get system info: %v", err)), nil
+	memInfo, _ := collectedValue[*mem.VirtualMemoryStat](finished, "memory")
+	rootDisk, _ := collectedValue[*disk.UsageStat](finished, "disk")
+	info, _ := collectedValue[*host.InfoStat](finished, "host")
+	// CPU and load fall back to zero
+	cpuUsage, _ := collectedValue[float64](finished, "cpu")
+	loadAvg, ok := collectedValue[*load.AvgStat](finished, "load")
+	if !ok {
+		loadAvg = &load.AvgStat{}
 	}
+	mountProblems, _ := collectedValue[[]mountProblem](finished, "mounts")
+
 	//nolint:gosec // G115: integer overflow conversion safe for reasonable uptimes
 	uptime := time.Duration(info.Uptime) * time.Second
 
-	// Determine overall status
-	status := statusHealthy
-	var warnings []string
+	status, warnings := assessHealth(cpuUsage, memInfo.UsedPercent, rootDisk.UsedPercent)
 
-	if cpuUsage > 95 {
+	if len(mountProblems) > 0 {
 		status = statusCritical
-		warnings = append(warnings, "CPU usage is critical (>95%)")
-	} else if cpuUsage > 80 {
-		if status != statusCritical {
-			status = statusWarning
-		}
-		warnings = append(warnings, "CPU usage is high (>80%)")
+		warnings = append(warnings, mountProblemWarnings(mountProblems)...)
 	}
 
-	if memInfo.UsedPercent > 95 {
-		status = statusCritical
-		warnings = append(warnings, "Memory usage is critical (>95%)")
-	} else if memInfo.UsedPercent > 85 {
-		if status != statusCritical {
-			status = statusWarning
-		}
-		warnings = append(warnings, "Memory usage is high (>85%)")
-	}
-
-	if rootDisk.UsedPercent > 95 {
-		status = statusCritical
-		warnings = append(warnings, "Disk usage is critical (>95%)")
-	} else if rootDisk.UsedPercent > 85 {
-		if status != statusCritical {
+	// A mount that does not answer is as bad as a full one
+	incomplete := unfinishedCollectors(collectors, finished)
+	for _, name := range incomplete {
+		warnings = append(warnings, fmt.Sprintf("The %s check did not finish in time", name))
+		if name == "mounts" {
+			status = statusCritical
+		} else if status != statusCritical {
 			status = statusWarning
 		}
-		warnings = append(warnings, "Disk usage is high (>85%)")
 	}
 
 	result := map[string]interface{}{
@@ -688,147 +1622,27 @@ func (h *HandlerManager) HandleGetSystemHealth(ctx context.Context, request mcp.
 		"memory": map[string]interface{}{
 			"usage_percent":   memInfo.UsedPercent,
 			"available_bytes": memInfo.Available,
-			"available_human": config.BytesToHuman(memInfo.Available),
-			"total_human":     config.BytesToHuman(memInfo.Total),
+			"available_human": h.human.Bytes(memInfo.Available),
+			"total_human":     h.human.Bytes(memInfo.Total),
 		},
 		"disk": map[string]interface{}{
-			"mount_point":   "/",
+			"mount_point":   rootPath,
 			"usage_percent": rootDisk.UsedPercent,
 			"free_bytes":    rootDisk.Free,
-			"free_human":    config.BytesToHuman(rootDisk.Free),
-			"total_human":   config.BytesToHuman(rootDisk.Total),
+			"free_human":    h.human.Bytes(rootDisk.Free),
+			"total_human":   h.human.Bytes(rootDisk.Total),
 		},
 		"uptime": map[string]interface{}{
 			"seconds": info.Uptime,
-			"human":   uptime.String(),
+			"human":   h.human.Duration(uptime),
 		},
 		"hostname": info.Hostname,
 	}
-
-	jsonBytes, err := json.Marshal(result)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	if len(incomplete) > 0 {
+		result["incomplete"] = incomplete
 	}
-	return mcp.NewToolResultText(string(jsonBytes)), nil
-}
-
-// HandleGetDockerMetrics returns Docker container metrics using the docker CLI.
-// This approach works with both cgroups v1 and v2 systems.
-func (h *HandlerManager) HandleGetDockerMetrics(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	var containerFilter string
-
-	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
-		if cid, ok := args["container_id"].(string); ok && cid != "" {
-			containerFilter = cid
-		}
-	}
-
-	// Verify docker is available
-	if _, err := exec.LookPath("docker"); err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Docker CLI not found: %v", err)), nil
-	}
-
-	// Get container list via docker ps
-	psArgs := []string{"ps", "-a", "--no-trunc", "--format", "{{.ID}}|{{.Names}}|{{.Image}}|{{.Status}}|{{.State}}"}
-	psOut, err := exec.CommandContext(ctx, "docker", psArgs...).Output()
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to list Docker containers: %v", err)), nil
-	}
-
-	// Parse container list
-	type containerInfo struct {
-		id      string
-		name    string
-		image   string
-		status  string
-		running bool
-	}
-	var containers []containerInfo
-	for _, line := range strings.Split(strings.TrimSpace(string(psOut)), "\n") {
-		if line == "" {
-			continue
-		}
-		cols := strings.SplitN(line, "|", 5)
-		if len(cols) != 5 {
-			continue
-		}
-		c := containerInfo{
-			id:      cols[0],
-			name:    cols[1],
-			image:   cols[2],
-			status:  cols[3],
-			running: strings.EqualFold(cols[4], "running"),
-		}
-		// Client-side filtering by container ID or name
-		if containerFilter != "" && c.id != containerFilter && c.name != containerFilter &&
-			!strings.HasPrefix(c.id, containerFilter) {
-			continue
-		}
-		containers = append(containers, c)
-	}
-
-	// Get live stats via docker stats for running containers
-	type statsInfo struct {
-		cpuPerc  string
-		memUsage string
-		memPerc  string
-		netIO    string
-		blockIO  string
-		pids     string
-	}
-	statsMap := make(map[string]statsInfo)
-
-	// Only fetch stats if we have containers
-	if len(containers) > 0 {
-		statsArgs := []string{"stats", "--no-stream", "--no-trunc", "--format", "{{.ID}}|{{.CPUPerc}}|{{.MemUsage}}|{{.MemPerc}}|{{.NetIO}}|{{.BlockIO}}|{{.PIDs}}"}
-		statsOut, err := exec.CommandContext(ctx, "docker", statsArgs...).Output()
-		if err == nil {
-			for _, line := range strings.Split(strings.TrimSpace(string(statsOut)), "\n") {
-				if line == "" {
-					continue
-				}
-				cols := strings.SplitN(line, "|", 7)
-				if len(cols) != 7 {
-					continue
-				}
-				statsMap[cols[0]] = statsInfo{
-					cpuPerc:  strings.TrimSpace(cols[1]),
-					memUsage: strings.TrimSpace(cols[2]),
-					memPerc:  strings.TrimSpace(cols[3]),
-					netIO:    strings.TrimSpace(cols[4]),
-					blockIO:  strings.TrimSpace(cols[5]),
-					pids:     strings.TrimSpace(cols[6]),
-				}
-			}
-		}
-	}
-
-	// Build result
-	containerData := []map[string]interface{}{}
-	for _, c := range containers {
-		cInfo := map[string]interface{}{
-			"container_id": c.id,
-			"name":         c.name,
-			"image":        c.image,
-			"status":       c.status,
-			"running":      c.running,
-		}
-
-		if stats, ok := statsMap[c.id]; ok {
-			cInfo["cpu_percent"] = stats.cpuPerc
-			cInfo["memory_usage"] = stats.memUsage
-			cInfo["memory_percent"] = stats.memPerc
-			cInfo["network_io"] = stats.netIO
-			cInfo["block_io"] = stats.blockIO
-			cInfo["pids"] = stats.pids
-		}
-
-		containerData = append(containerData, cInfo)
-	}
-
-	result := map[string]interface{}{
-		"containers": containerData,
-		"total":      len(containerData),
+	if len(mountProblems) > 0 {
+		result["mount_problems"] = mountProblems
 	}
 
 	jsonBytes, err := json.Marshal(result)
@@ -838,87 +1652,93 @@ func (h *HandlerManager) HandleGetDockerMetrics(ctx context.Context, request mcp
 	return mcp.NewToolResultText(string(jsonBytes)), nil
 }
 
-// HandleGetNetworkConnections returns active network connections
-func (h *HandlerManager) HandleGetNetworkConnections(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	kind := kindAll
-	statusFilter := ""
+// assessHealth grades CPU, memory, and root disk usage into an overall status and the
+// warnings behind it
+func assessHealth(cpuUsage, memPercent, diskPercent float64) (string, []string) {
+	status := statusHealthy
+	var warnings []string
 
-	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
-		if k, ok := args["kind"].(string); ok && k != "" {
-			kind = strings.ToLower(k)
-		}
-		if s, ok := args["status"].(string); ok && s != "" {
-			statusFilter = strings.ToUpper(s)
+	if cpuUsage > 95 {
+		status = statusCritical
+		warnings = append(warnings, "CPU usage is critical (>95%)")
+	} else if cpuUsage > 80 {
+		if status != statusCritical {
+			status = statusWarning
 		}
+		warnings = append(warnings, "CPU usage is high (>80%)")
 	}
 
-	// Validate kind parameter against known values
-	if kind != kindTCP && kind != kindUDP {
-		kind = kindAll
-	}
-
-	connections, err := net.Connections(kind)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get network connections: %v", err)), nil
-	}
-
-	connData := []map[string]interface{}{}
-	for _, c := range connections {
-		// Filter by status if specified
-		if statusFilter != "" && c.Status != statusFilter {
-			continue
-		}
-
-		connInfo := map[string]interface{}{
-			"type":       connTypeToString(c.Type),
-			"status":     c.Status,
-			"local_addr": fmt.Sprintf("%s:%d", c.Laddr.IP, c.Laddr.Port),
-			"pid":        c.Pid,
-		}
-
-		if c.Raddr.IP != "" {
-			connInfo["remote_addr"] = fmt.Sprintf("%s:%d", c.Raddr.IP, c.Raddr.Port)
-		} else {
-			connInfo["remote_addr"] = ""
+	if memPercent > 95 {
+		status = statusCritical
+		warnings = append(warnings, "Memory usage is critical (>95%)")
+	} else if memPercent > 85 {
+		if status != statusCritical {
+			status = statusWarning
 		}
-
-		connData = append(connData, connInfo)
-	}
-
-	result := map[string]interface{}{
-		"connections": connData,
-		"total":       len(connData),
-		"kind":        kind,
-	}
-
-	if statusFilter != "" {
-		result["status_filter"] = statusFilter
+		warnings = append(warnings, "Memory usage is high (>85%)")
 	}
 
-	jsonBytes, err := json.Marshal(result)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	if diskPercent > 95 {
+		status = statusCritical
+		warnings = append(warnings, "Disk usage is critical (>95%)")
+	} else if diskPercent > 85 {
+		if status != statusCritical {
+			status = statusWarning
+		}
+		warnings = append(warnings, "Disk usage is high (>85%)")
 	}
-	return mcp.NewToolResultText(string(jsonBytes)), nil
+	return status, warnings
 }
 
-// HandleGetServiceStatus returns systemd service status
+// maxServiceStatusLines caps the journal lines get_service_status returns per unit
+const maxServiceStatusLines = 100
+
+// HandleGetServiceStatus returns the status of each requested service, with resource
+// usage, restarts, and optionally recent journal lines for systemd units
 func (h *HandlerManager) HandleGetServiceStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	var services []string
+	lines := 0
+	user := false
 
 	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
 		if svcStr, ok := args["services"].(string); ok && svcStr != "" {
 			services = config.SplitAndTrim(svcStr)
 		}
+		if l, ok := args["lines"].(float64); ok && l > 0 {
+			lines = min(int(l), maxServiceStatusLines)
+		}
+		if u, ok := args["user"].(bool); ok {
+			user = u
+		}
 	}
 
 	if len(services) == 0 {
 		return mcp.NewToolResultError("services parameter is required"), nil
 	}
+	// Journal lines can reveal what users do; the private profile leaves them out as it
+	// does diagnose_service
+	if h.cfg.ToolProfile == config.ProfilePrivate {
+		lines = 0
+	}
+
+	if h.services == nil {
+		return mcp.NewToolResultError("No supported service manager detected"), nil
+	}
 
 	serviceData := []map[string]interface{}{}
-	for _, svc := range services {
-		svcInfo := getServiceInfo(svc)
+	for _, svcInfo := range h.services.Status(ctx, services, user) {
+		if v, ok := svcInfo["memory_bytes"].(uint64); ok {
+			svcInfo["memory_human"] = h.human.Bytes(v)
+		}
+		// Journal lines are only available for systemd units
+		if unit, ok := svcInfo["unit"].(string); ok && lines > 0 && systemdUnitRe.MatchString(unit) {
+			entries, errorCount, err := h.unitJournal(ctx, unit, lines, user)
+			logs := map[string]interface{}{"entries": entries, "error_count": errorCount}
+			if err != nil {
+				logs["error"] = fmt.Sprintf("journalctl failed: %v", err)
+			}
+			svcInfo["logs"] = logs
+		}
 		serviceData = append(serviceData, svcInfo)
 	}
 
@@ -934,56 +1754,30 @@ func (h *HandlerManager) HandleGetServiceStatus(ctx context.Context, request mcp
 	return mcp.NewToolResultText(string(jsonBytes)), nil
 }
 
-// getServiceInfo queries systemctl for service information
-func getServiceInfo(serviceName string) map[string]interface{} {
-	// Ensure service name ends with .service for consistency
-	unitName := serviceName
-	if !strings.HasSuffix(unitName, ".service") {
-		unitName += ".service"
+// skipPartition reports whether a partition is a pseudo or system-internal filesystem
+// that should be hidden when listing all disks
+func skipPartition(p disk.PartitionStat) bool {
+	switch p.Fstype {
+	case "tmpfs", "devtmpfs", "squashfs", "devfs", "autofs", "nullfs":
+		return true
 	}
-
-	properties := []string{"LoadState", "ActiveState", "SubState", "Description", "MainPID"}
-
-	result := map[string]interface{}{
-		"name": serviceName,
+	// macOS mounts APFS system volumes (Preboot, VM, Update, ...) under /System/Volumes;
+	// only the Data volume holds user data
+	if strings.HasPrefix(p.Mountpoint, "/System/Volumes/") && p.Mountpoint != "/System/Volumes/Data" {
+		return true
 	}
+	return false
+}
 
-	//nolint:gosec // G204: unitName is validated and suffixed with .service above
-	cmd := exec.Command("systemctl", "show", unitName,
-		"--property="+strings.Join(properties, ","),
-		"--no-pager")
-	output, err := cmd.Output()
-	if err != nil {
-		result["error"] = fmt.Sprintf("Failed to query service: %v", err)
-		result["available"] = false
-		return result
+// systemRootPath returns the path of the system volume: "/" on Unix, the system drive on Windows
+func systemRootPath() string {
+	if runtime.GOOS != "windows" {
+		return "/"
 	}
-
-	result["available"] = true
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	for _, line := range lines {
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
-			continue
-		}
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
-
-		switch key {
-		case "LoadState":
-			result["load_state"] = value
-		case "ActiveState":
-			result["active_state"] = value
-		case "SubState":
-			result["sub_state"] = value
-		case "Description":
-			result["description"] = value
-		case "MainPID":
-			result["main_pid"] = value
-		}
+	if drive := os.Getenv("SystemDrive"); drive != "" {
+		return drive + `\`
 	}
-
-	return result
+	return `C:\`
 }
 
 // connTypeToString converts a connection type uint32 to a human-readable string
@@ -4,12 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"runtime"
 	"strings"
 	"testing"
 
 	"sysmetrics-mcp/internal/config"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/shirou/gopsutil/v3/disk"
 )
 
 // Helper to check tool result
@@ -45,11 +48,81 @@ func checkToolResult(t *testing.T, res *mcp.CallToolResult, err error, expectedK
 	}
 }
 
+func TestRegisterToolsRespectsCapabilities(t *testing.T) {
+	h := NewHandlerManager(&config.Config{})
+	s := server.NewMCPServer("test", "0.0.0")
+	h.RegisterTools(s)
+
+	tools := s.ListTools()
+	if _, ok := tools["get_server_info"]; !ok {
+		t.Error("Expected get_server_info to always be registered")
+	}
+	for name := range h.skippedTools {
+		if _, ok := tools[name]; ok {
+			t.Errorf("Skipped tool %s should not be registered", name)
+		}
+	}
+	if len(tools) != len(h.registered) {
+		t.Errorf("Registered %d tools but recorded %d", len(tools), len(h.registered))
+	}
+}
+
+func TestRegisterToolsProfile(t *testing.T) {
+	cfg := &config.Config{TempUnit: "celsius", ToolProfile: "minimal", EnableToolsStr: "get_fd_usage", DisableToolsStr: "get_thermal_status,get_bogus"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	h := NewHandlerManager(cfg)
+	s := server.NewMCPServer("test", "0.0.0")
+	h.RegisterTools(s)
+
+	tools := s.ListTools()
+	for _, name := range []string{"get_cpu_metrics", "get_fd_usage"} {
+		if _, ok := tools[name]; !ok {
+			t.Errorf("Expected %s to be registered", name)
+		}
+	}
+	for _, name := range []string{"get_process_list", "get_thermal_status"} {
+		if _, ok := tools[name]; ok {
+			t.Errorf("Expected %s to be excluded", name)
+		}
+		if h.skippedTools[name] == "" {
+			t.Errorf("Expected a skip reason for %s", name)
+		}
+	}
+	if unknown := h.UnknownToolFilters(); len(unknown) != 1 || unknown[0] != "get_bogus" {
+		t.Errorf("Expected get_bogus to be reported as unknown, got %v", unknown)
+	}
+}
+
+func TestHandleGetServerInfo(t *testing.T) {
+	h := NewHandlerManager(&config.Config{})
+	req := mcp.CallToolRequest{}
+	res, err := h.HandleGetServerInfo(context.Background(), req)
+	checkToolResult(t, res, err, []string{"name", "version", "capabilities", "registered_tools", "skipped_tools"})
+}
+
 func TestHandleGetSystemInfo(t *testing.T) {
 	h := NewHandlerManager(&config.Config{})
 	req := mcp.CallToolRequest{}
 	res, err := h.HandleGetSystemInfo(context.Background(), req)
-	checkToolResult(t, res, err, []string{"hostname", "os", "platform", "uptime_seconds"})
+	checkToolResult(t, res, err, []string{"hostname", "os", "platform", "uptime_seconds", "uptime_human", "last_boot_reason"})
+}
+
+func TestHandleGetMemoryMetricsLocale(t *testing.T) {
+	h := NewHandlerManager(&config.Config{Locale: "de"})
+	res, err := h.HandleGetMemoryMetrics(context.Background(), mcp.CallToolRequest{})
+	checkToolResult(t, res, err, []string{"ram"})
+
+	var result struct {
+		RAM map[string]interface{} `json:"ram"`
+	}
+	if err := json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &result); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+	if total, _ := result.RAM["total_human"].(string); !strings.Contains(total, ",") {
+		t.Errorf("Expected a German decimal comma in total_human, got %q", total)
+	}
 }
 
 func TestHandleGetCPUMetrics(t *testing.T) {
@@ -72,6 +145,48 @@ func TestHandleGetMemoryMetrics(t *testing.T) {
 	checkToolResult(t, res, err, []string{"ram", "swap"})
 }
 
+func TestHandleGetProcessList(t *testing.T) {
+	h := NewHandlerManager(&config.Config{MaxProcesses: 5})
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{},
+		},
+	}
+	res, err := h.HandleGetProcessList(context.Background(), req)
+	checkToolResult(t, res, err, []string{"processes", "total", "shown", "sort_by"})
+}
+
+func TestHandleGetProcessListFilters(t *testing.T) {
+	h := NewHandlerManager(&config.Config{MaxProcesses: 5})
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{
+				"user": "nonexistent-user-12345",
+			},
+		},
+	}
+	res, err := h.HandleGetProcessList(context.Background(), req)
+	checkToolResult(t, res, err, []string{"processes", "matched"})
+
+	var data map[string]interface{}
+	textContent := res.Content[0].(mcp.TextContent)
+	if parseErr := json.Unmarshal([]byte(textContent.Text), &data); parseErr != nil {
+		t.Fatalf("Failed to parse result: %v", parseErr)
+	}
+	if matched := data["matched"].(float64); matched != 0 {
+		t.Errorf("Expected 0 processes for nonexistent user, got %v", matched)
+	}
+
+	req.Params.Arguments = map[string]interface{}{"name_pattern": "[invalid"}
+	res, err = h.HandleGetProcessList(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if !res.IsError {
+		t.Error("Expected error result for invalid name_pattern")
+	}
+}
+
 func TestHandleGetDiskIOMetrics(t *testing.T) {
 	h := NewHandlerManager(&config.Config{})
 	req := mcp.CallToolRequest{
@@ -250,6 +365,31 @@ func TestHandleGetServiceStatus(t *testing.T) {
 	checkToolResult(t, res, err, []string{"services", "total"})
 }
 
+func TestHandleGetServiceStatusPrivateProfile(t *testing.T) {
+	h := NewHandlerManager(&config.Config{ToolProfile: config.ProfilePrivate})
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{
+				"services": "ssh",
+				"lines":    float64(10),
+			},
+		},
+	}
+	res, err := h.HandleGetServiceStatus(context.Background(), req)
+	checkToolResult(t, res, err, []string{"services", "total"})
+	var result struct {
+		Services []map[string]interface{} `json:"services"`
+	}
+	if err := json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &result); err != nil {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+	for _, svc := range result.Services {
+		if _, ok := svc["logs"]; ok {
+			t.Errorf("Expected the private profile to leave out journal lines, got %v", svc)
+		}
+	}
+}
+
 func TestHandleGetServiceStatusMissing(t *testing.T) {
 	h := NewHandlerManager(&config.Config{})
 	req := mcp.CallToolRequest{
@@ -266,3 +406,32 @@ func TestHandleGetServiceStatusMissing(t *testing.T) {
 		t.Error("Expected error result when services parameter is missing")
 	}
 }
+
+func TestSystemRootPath(t *testing.T) {
+	got := systemRootPath()
+	if runtime.GOOS != "windows" && got != "/" {
+		t.Errorf("systemRootPath() = %s; want /", got)
+	}
+	if runtime.GOOS == "windows" && !strings.HasSuffix(got, `:\`) {
+		t.Errorf("systemRootPath() = %s; want a drive root", got)
+	}
+}
+
+func TestSkipPartition(t *testing.T) {
+	tests := []struct {
+		partition disk.PartitionStat
+		want      bool
+	}{
+		{disk.PartitionStat{Mountpoint: "/", Fstype: "ext4"}, false},
+		{disk.PartitionStat{Mountpoint: "/run", Fstype: "tmpfs"}, true},
+		{disk.PartitionStat{Mountpoint: "/dev", Fstype: "devfs"}, true},
+		{disk.PartitionStat{Mountpoint: "/System/Volumes/Data", Fstype: "apfs"}, false},
+		{disk.PartitionStat{Mountpoint: "/System/Volumes/Preboot", Fstype: "apfs"}, true},
+	}
+
+	for _, tc := range tests {
+		if got := skipPartition(tc.partition); got != tc.want {
+			t.Errorf("skipPartition(%s, %s) = %v; want %v", tc.partition.Mountpoint, tc.partition.Fstype, got, tc.want)
+		}
+	}
+}
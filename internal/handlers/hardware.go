@@ -0,0 +1,370 @@
+package handlers
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Device inventory sources; variables so tests can point them at fixtures
+var (
+	usbDevicesPath = "/sys/bus/usb/devices"
+	pciDevicesPath = "/sys/bus/pci/devices"
+	hatPath        = "/proc/device-tree/hat"
+	pciIDsPaths    = []string{"/usr/share/misc/pci.ids", "/usr/share/hwdata/pci.ids", "/usr/share/pci.ids"}
+)
+
+// usbClasses names the USB base classes that matter for "what is this device" questions
+var usbClasses = map[string]string{
+	"01": "audio",
+	"02": "communications",
+	"03": "hid",
+	"06": "image",
+	"07": "printer",
+	"08": "mass_storage",
+	"09": "hub",
+	"0a": "cdc_data",
+	"0e": "video",
+	"e0": "wireless",
+	"ef": "miscellaneous",
+	"ff": "vendor_specific",
+}
+
+// pciClasses names PCI classes by base class and subclass, falling back to the base class
+var pciClasses = map[string]string{
+	"0106": "sata_controller",
+	"0108": "nvme_controller",
+	"0200": "ethernet_controller",
+	"0280": "network_controller",
+	"0300": "vga_controller",
+	"0403": "audio_device",
+	"0604": "pci_bridge",
+	"0c03": "usb_controller",
+	"01":   "storage_controller",
+	"02":   "network_controller",
+	"03":   "display_controller",
+	"04":   "multimedia_controller",
+	"06":   "bridge",
+	"08":   "system_peripheral",
+	"0c":   "serial_bus_controller",
+}
+
+// usbDevice is one attached USB device (root hubs excluded)
+type usbDevice struct {
+	Port         string   `json:"port"`
+	Bus          int      `json:"bus"`
+	Device       int      `json:"device"`
+	VendorID     string   `json:"vendor_id"`
+	ProductID    string   `json:"product_id"`
+	Manufacturer string   `json:"manufacturer,omitempty"`
+	Product      string   `json:"product,omitempty"`
+	Class        string   `json:"class,omitempty"`
+	USBVersion   string   `json:"usb_version,omitempty"`
+	SpeedMbps    float64  `json:"speed_mbps"`
+	Speed        string   `json:"speed"`
+	Drivers      []string `json:"drivers"`
+	BlockDevices []string `json:"block_devices,omitempty"`
+}
+
+// pciDevice is one PCI function
+type pciDevice struct {
+	Address      string `json:"address"`
+	VendorID     string `json:"vendor_id"`
+	DeviceID     string `json:"device_id"`
+	Vendor       string `json:"vendor,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Class        string `json:"class"`
+	Driver       string `json:"driver,omitempty"`
+	LinkSpeed    string `json:"link_speed,omitempty"`
+	LinkWidth    string `json:"link_width,omitempty"`
+	MaxLinkSpeed string `json:"max_link_speed,omitempty"`
+	MaxLinkWidth string `json:"max_link_width,omitempty"`
+}
+
+// HandleGetHardwareInventory lists USB and PCI devices with their drivers and link speeds,
+// plus the Raspberry Pi HAT EEPROM when one is fitted
+func (h *HandlerManager) HandleGetHardwareInventory(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	bus := "all"
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if b, ok := args["bus"].(string); ok && b != "" {
+			bus = strings.ToLower(strings.TrimSpace(b))
+			if bus != "all" && bus != "usb" && bus != "pci" {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid bus: %q (must be usb, pci, or all)", b)), nil
+			}
+		}
+	}
+
+	result := map[string]interface{}{}
+	warnings := []string{}
+	if bus != "pci" {
+		devices, usb3Ports, err := readUSBDevices(usbDevicesPath)
+		if err != nil {
+			result["usb_error"] = err.Error()
+		} else {
+			result["usb_devices"] = devices
+			result["usb_count"] = len(devices)
+			result["usb3_ports"] = usb3Ports
+			warnings = append(warnings, usbLinkWarnings(devices, usb3Ports)...)
+		}
+	}
+	if bus != "usb" {
+		devices, err := readPCIDevices(pciDevicesPath)
+		if err != nil {
+			result["pci_error"] = err.Error()
+		} else {
+			result["pci_devices"] = devices
+			result["pci_count"] = len(devices)
+		}
+	}
+	if hat := readHATInfo(hatPath); hat != nil {
+		result["hat"] = hat
+	}
+	result["warnings"] = warnings
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// readUSBDevices reads every USB device under root, ordered by bus and port, and reports
+// whether any root hub runs at USB 3 speed. Root hubs (usbN) and interfaces (1-1:1.0) are
+// not listed; interfaces contribute their drivers and class.
+func readUSBDevices(root string) (devices []usbDevice, usb3Ports bool, err error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read USB devices: %w", err)
+	}
+	devices = []usbDevice{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, "usb") {
+			if s, err := readTrimmed(filepath.Join(root, name, "speed")); err == nil {
+				speed, _ := strconv.ParseFloat(s, 64)
+				usb3Ports = usb3Ports || speed >= 5000
+			}
+			continue
+		}
+		if strings.Contains(name, ":") {
+			continue
+		}
+		dir := filepath.Join(root, name)
+		d := usbDevice{Port: name, Drivers: []string{}}
+		d.VendorID, _ = readTrimmed(filepath.Join(dir, "idVendor"))
+		d.ProductID, _ = readTrimmed(filepath.Join(dir, "idProduct"))
+		d.Manufacturer, _ = readTrimmed(filepath.Join(dir, "manufacturer"))
+		d.Product, _ = readTrimmed(filepath.Join(dir, "product"))
+		d.USBVersion, _ = readTrimmed(filepath.Join(dir, "version"))
+		if s, err := readTrimmed(filepath.Join(dir, "busnum")); err == nil {
+			d.Bus, _ = strconv.Atoi(s)
+		}
+		if s, err := readTrimmed(filepath.Join(dir, "devnum")); err == nil {
+			d.Device, _ = strconv.Atoi(s)
+		}
+		if s, err := readTrimmed(filepath.Join(dir, "speed")); err == nil {
+			d.SpeedMbps, _ = strconv.ParseFloat(s, 64)
+		}
+		d.Speed = usbSpeedName(d.SpeedMbps)
+
+		// Composite devices declare their class per interface
+		classes := []string{}
+		if c, err := readTrimmed(filepath.Join(dir, "bDeviceClass")); err == nil && c != "00" {
+			classes = append(classes, c)
+		}
+		interfaces, _ := filepath.Glob(filepath.Join(dir, name+":*"))
+		for _, iface := range interfaces {
+			if len(classes) == 0 || classes[0] == "ef" {
+				if c, err := readTrimmed(filepath.Join(iface, "bInterfaceClass")); err == nil {
+					classes = append(classes, c)
+				}
+			}
+			if driver, err := os.Readlink(filepath.Join(iface, "driver")); err == nil && !contains(d.Drivers, filepath.Base(driver)) {
+				d.Drivers = append(d.Drivers, filepath.Base(driver))
+			}
+			blocks, _ := filepath.Glob(filepath.Join(iface, "host*", "target*", "*", "block", "*"))
+			for _, b := range blocks {
+				d.BlockDevices = append(d.BlockDevices, filepath.Base(b))
+			}
+		}
+		for _, c := range classes {
+			if className, ok := usbClasses[c]; ok && className != "miscellaneous" {
+				d.Class = className
+				break
+			}
+		}
+		sort.Strings(d.Drivers)
+		devices = append(devices, d)
+	}
+	sort.Slice(devices, func(i, j int) bool {
+		if devices[i].Bus != devices[j].Bus {
+			return devices[i].Bus < devices[j].Bus
+		}
+		return devices[i].Port < devices[j].Port
+	})
+	return devices, usb3Ports, nil
+}
+
+// usbSpeedName names a USB link speed in Mbit/s as reported by sysfs
+func usbSpeedName(mbps float64) string {
+	switch {
+	case mbps >= 20000:
+		return "USB 3.2 Gen 2x2 (20 Gbps)"
+	case mbps >= 10000:
+		return "USB 3.2 Gen 2 (10 Gbps)"
+	case mbps >= 5000:
+		return "USB 3 (5 Gbps)"
+	case mbps >= 480:
+		return "USB 2 high speed (480 Mbps)"
+	case mbps >= 12:
+		return "USB 1 full speed (12 Mbps)"
+	case mbps > 0:
+		return "USB 1 low speed (1.5 Mbps)"
+	}
+	return "unknown"
+}
+
+// usbLinkWarnings flags storage devices running below USB 3 speed on a system that has
+// USB 3 ports. USB 3 devices report a USB 2 version when they enumerate at high speed, so
+// the version only helps when a device claims USB 3 itself.
+func usbLinkWarnings(devices []usbDevice, usb3Ports bool) []string {
+	warnings := []string{}
+	if !usb3Ports {
+		return warnings
+	}
+	for _, d := range devices {
+		if d.SpeedMbps >= 5000 || d.Class != "mass_storage" {
+			continue
+		}
+		name := cmp.Or(d.Product, d.VendorID+":"+d.ProductID)
+		if version, err := strconv.ParseFloat(d.USBVersion, 64); err == nil && version >= 3 {
+			warnings = append(warnings, fmt.Sprintf("%s (port %s) supports USB %s but is connected at %s; use a USB 3 port or cable", name, d.Port, d.USBVersion, d.Speed))
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf("%s (port %s) is connected at %s although this system has USB 3 ports; a USB 3 drive in a USB 2 port, hub, or cable runs at a fraction of its speed", name, d.Port, d.Speed))
+	}
+	return warnings
+}
+
+// readPCIDevices reads every PCI function under root, ordered by address, with vendor and
+// device names from pci.ids when it is installed
+func readPCIDevices(root string) ([]pciDevice, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PCI devices: %w", err)
+	}
+	devices := []pciDevice{}
+	for _, entry := range entries {
+		dir := filepath.Join(root, entry.Name())
+		d := pciDevice{Address: entry.Name()}
+		vendor, _ := readTrimmed(filepath.Join(dir, "vendor"))
+		device, _ := readTrimmed(filepath.Join(dir, "device"))
+		d.VendorID = strings.TrimPrefix(vendor, "0x")
+		d.DeviceID = strings.TrimPrefix(device, "0x")
+		class, _ := readTrimmed(filepath.Join(dir, "class"))
+		d.Class = pciClassName(strings.TrimPrefix(class, "0x"))
+		if driver, err := os.Readlink(filepath.Join(dir, "driver")); err == nil {
+			d.Driver = filepath.Base(driver)
+		}
+		d.LinkSpeed, _ = readTrimmed(filepath.Join(dir, "current_link_speed"))
+		d.LinkWidth, _ = readTrimmed(filepath.Join(dir, "current_link_width"))
+		d.MaxLinkSpeed, _ = readTrimmed(filepath.Join(dir, "max_link_speed"))
+		d.MaxLinkWidth, _ = readTrimmed(filepath.Join(dir, "max_link_width"))
+		devices = append(devices, d)
+	}
+	sort.Slice(devices, func(i, j int) bool { return devices[i].Address < devices[j].Address })
+
+	for _, path := range pciIDsPaths {
+		if names, err := readPCINames(path, devices); err == nil {
+			for i := range devices {
+				devices[i].Vendor = names[devices[i].VendorID]
+				devices[i].Name = names[devices[i].VendorID+":"+devices[i].DeviceID]
+			}
+			break
+		}
+	}
+	return devices, nil
+}
+
+// pciClassName names a six-digit PCI class code such as "010802"
+func pciClassName(code string) string {
+	if len(code) < 4 {
+		return "unknown"
+	}
+	if name, ok := pciClasses[code[:4]]; ok {
+		return name
+	}
+	if name, ok := pciClasses[code[:2]]; ok {
+		return name
+	}
+	return "class_" + code[:4]
+}
+
+// readPCINames looks up the vendor ("8086") and device ("8086:1533") names of the given
+// devices in a pci.ids file. Device lines are tab-indented under their vendor.
+func readPCINames(path string, devices []pciDevice) (map[string]string, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	wanted := map[string]bool{}
+	for _, d := range devices {
+		wanted[d.VendorID] = true
+		wanted[d.VendorID+":"+d.DeviceID] = true
+	}
+	names := map[string]string{}
+	vendor := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "" || line[0] == '#' || strings.HasPrefix(line, "\t\t"):
+			continue
+		case line[0] == '\t':
+			if id, name, ok := strings.Cut(line[1:], "  "); ok && wanted[vendor+":"+id] {
+				names[vendor+":"+id] = name
+			}
+		case strings.HasPrefix(line, "C "):
+			// Class definitions follow the vendors
+			return names, nil
+		default:
+			id, name, _ := strings.Cut(line, "  ")
+			vendor = id
+			if wanted[id] {
+				names[id] = name
+			}
+		}
+	}
+	return names, scanner.Err()
+}
+
+// readHATInfo reads the Raspberry Pi HAT EEPROM as exposed by the firmware in the device
+// tree, or nil when no HAT is fitted
+func readHATInfo(root string) map[string]string {
+	hat := map[string]string{}
+	for _, key := range []string{"vendor", "product", "product_id", "product_ver", "uuid"} {
+		data, err := os.ReadFile(filepath.Join(root, key))
+		if err != nil {
+			continue
+		}
+		// Device tree strings are NUL-terminated
+		if value := strings.Trim(string(data), "\x00 \n"); value != "" {
+			hat[key] = value
+		}
+	}
+	if len(hat) == 0 {
+		return nil
+	}
+	return hat
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleGetHardwareInventory(t *testing.T) {
+	dir := t.TempDir()
+	origUSB, origPCI, origHAT, origIDs := usbDevicesPath, pciDevicesPath, hatPath, pciIDsPaths
+	defer func() { usbDevicesPath, pciDevicesPath, hatPath, pciIDsPaths = origUSB, origPCI, origHAT, origIDs }()
+	usbDevicesPath = filepath.Join(dir, "usb")
+	pciDevicesPath = filepath.Join(dir, "pci")
+	hatPath = filepath.Join(dir, "hat")
+	pciIDsPaths = []string{filepath.Join(dir, "missing.ids"), filepath.Join(dir, "pci.ids")}
+
+	writeSysfsFiles(t, usbDevicesPath, map[string]string{
+		"usb1/speed": "480",
+		"usb2/speed": "5000",
+		// A USB 3 SSD enclosure on the USB 3 bus
+		"2-1/idVendor": "174c", "2-1/idProduct": "55aa", "2-1/product": "ASM1153E", "2-1/version": " 3.10",
+		"2-1/busnum": "2", "2-1/devnum": "2", "2-1/speed": "5000", "2-1/bDeviceClass": "00",
+		"2-1/2-1:1.0/bInterfaceClass": "08", "2-1/2-1:1.0/driver": sysfsLink + "../../bus/drivers/uas",
+		"2-1/2-1:1.0/host0/target0:0:0/0:0:0:0/block/sda/size": "1000",
+		// A flash drive that enumerated at high speed
+		"1-1.3/idVendor": "0781", "1-1.3/idProduct": "5581", "1-1.3/product": "Ultra", "1-1.3/version": " 2.10",
+		"1-1.3/busnum": "1", "1-1.3/devnum": "4", "1-1.3/speed": "480", "1-1.3/bDeviceClass": "00",
+		"1-1.3/1-1.3:1.0/bInterfaceClass": "08", "1-1.3/1-1.3:1.0/driver": sysfsLink + "../../bus/drivers/usb-storage",
+		// A keyboard
+		"1-1.4/idVendor": "046d", "1-1.4/idProduct": "c31c", "1-1.4/version": " 1.10",
+		"1-1.4/busnum": "1", "1-1.4/devnum": "5", "1-1.4/speed": "1.5", "1-1.4/bDeviceClass": "00",
+		"1-1.4/1-1.4:1.0/bInterfaceClass": "03", "1-1.4/1-1.4:1.0/driver": sysfsLink + "../../bus/drivers/usbhid",
+		"1-1.4/1-1.4:1.1/bInterfaceClass": "03", "1-1.4/1-1.4:1.1/driver": sysfsLink + "../../bus/drivers/usbhid",
+	})
+	writeSysfsFiles(t, pciDevicesPath, map[string]string{
+		"0000:01:00.0/vendor": "0x1106", "0000:01:00.0/device": "0x3483", "0000:01:00.0/class": "0x0c0330",
+		"0000:01:00.0/driver": sysfsLink + "../../bus/drivers/xhci_hcd", "0000:01:00.0/current_link_speed": "5.0 GT/s PCIe", "0000:01:00.0/current_link_width": "1",
+		"0000:00:00.0/vendor": "0x14e4", "0000:00:00.0/device": "0x2711", "0000:00:00.0/class": "0x060400",
+	})
+	ids := "# comment\n1106  VIA Technologies, Inc.\n\t3483  VL805/806 xHCI USB 3.0 Controller\n\t\t1106 3483  VL805/806 xHCI USB 3.0 Controller\n14e4  Broadcom Inc. and subsidiaries\nC 0c  Serial bus controller\n"
+	if err := os.WriteFile(pciIDsPaths[1], []byte(ids), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	writeSysfsFiles(t, hatPath, map[string]string{"vendor": "Pimoroni Ltd.\x00", "product": "Fan SHIM\x00"})
+
+	h := NewHandlerManager(&config.Config{})
+	res, err := h.HandleGetHardwareInventory(context.Background(), mcp.CallToolRequest{})
+	checkToolResult(t, res, err, []string{"usb_devices", "usb_count", "usb3_ports", "pci_devices", "pci_count", "hat", "warnings"})
+	var result struct {
+		USB      []usbDevice       `json:"usb_devices"`
+		PCI      []pciDevice       `json:"pci_devices"`
+		HAT      map[string]string `json:"hat"`
+		Warnings []string          `json:"warnings"`
+	}
+	if err := json.Unmarshal([]byte(resultText(res)), &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.USB) != 3 || result.USB[0].Port != "1-1.3" || result.USB[2].Port != "2-1" {
+		t.Fatalf("Expected three USB devices by bus and port, got %+v", result.USB)
+	}
+	if ssd := result.USB[2]; ssd.Class != "mass_storage" || ssd.Speed != "USB 3 (5 Gbps)" || len(ssd.Drivers) != 1 || ssd.Drivers[0] != "uas" || len(ssd.BlockDevices) != 1 || ssd.BlockDevices[0] != "sda" {
+		t.Errorf("Unexpected SSD: %+v", ssd)
+	}
+	if kbd := result.USB[1]; kbd.Class != "hid" || len(kbd.Drivers) != 1 || kbd.SpeedMbps != 1.5 {
+		t.Errorf("Unexpected keyboard: %+v", kbd)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "Ultra (port 1-1.3)") {
+		t.Errorf("Expected a USB 2 speed warning for the flash drive, got %v", result.Warnings)
+	}
+	if len(result.PCI) != 2 || result.PCI[0].Class != "pci_bridge" || result.PCI[0].Vendor != "Broadcom Inc. and subsidiaries" || result.PCI[0].Name != "" {
+		t.Errorf("Unexpected PCI bridge: %+v", result.PCI)
+	}
+	if xhci := result.PCI[1]; xhci.Class != "usb_controller" || xhci.Driver != "xhci_hcd" || xhci.Name != "VL805/806 xHCI USB 3.0 Controller" || xhci.LinkWidth != "1" {
+		t.Errorf("Unexpected USB controller: %+v", xhci)
+	}
+	if result.HAT["product"] != "Fan SHIM" || result.HAT["vendor"] != "Pimoroni Ltd." {
+		t.Errorf("Unexpected HAT: %v", result.HAT)
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"bus": "firewire"}
+	if res, err := h.HandleGetHardwareInventory(context.Background(), req); err != nil || !res.IsError {
+		t.Error("Expected an error for an unknown bus")
+	}
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"sysmetrics-mcp/internal/history"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Metrics history query limits
+const (
+	defaultHistoryRange = time.Hour
+	maxHistoryBuckets   = 500
+	targetHistoryBucket = 60
+)
+
+// HandleQueryMetrics returns min/max/avg per time bucket for a stored metric, or lists the
+// stored series when no metric is given
+func (h *HandlerManager) HandleQueryMetrics(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if h.history == nil {
+		return mcp.NewToolResultError("Metrics history is disabled (start the server with --history-db)"), nil
+	}
+
+	var metric, labelsStr string
+	rangeDur := defaultHistoryRange
+	var bucket time.Duration
+
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if m, ok := args["metric"].(string); ok {
+			metric = strings.TrimSpace(m)
+		}
+		if l, ok := args["labels"].(string); ok {
+			labelsStr = l
+		}
+		if r, ok := args["range"].(string); ok && r != "" {
+			d, err := parseHistoryDuration(r)
+			if err != nil || d <= 0 {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid range: %q (use a duration such as 30m, 6h, or 7d)", r)), nil
+			}
+			rangeDur = d
+		}
+		if b, ok := args["bucket"].(string); ok && b != "" {
+			d, err := parseHistoryDuration(b)
+			if err != nil || d <= 0 {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid bucket: %q (use a duration such as 1m, 15m, or 1h)", b)), nil
+			}
+			bucket = d
+		}
+	}
+
+	if metric == "" {
+		metrics, err := h.history.Metrics(ctx)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to list metrics history: %v", err)), nil
+		}
+		tiers := []map[string]string{}
+		for _, tier := range h.history.Tiers() {
+			resolution := "raw"
+			if tier.Resolution > 0 {
+				resolution = tier.Resolution.String()
+			}
+			tiers = append(tiers, map[string]string{"resolution": resolution, "kept_for": tier.Retention.String()})
+		}
+		result := map[string]interface{}{
+			"metrics":         metrics,
+			"retention":       h.history.Retention().String(),
+			"tiers":           tiers,
+			"sample_interval": h.cfg.SampleInterval.String(),
+		}
+		jsonBytes, err := json.Marshal(result)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+		}
+		return mcp.NewToolResultText(string(jsonBytes)), nil
+	}
+
+	labels, err := history.ParseLabels(labelsStr)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid labels: %v", err)), nil
+	}
+
+	bucket, adjusted := historyBucket(rangeDur, bucket, h.cfg.SampleInterval)
+	until := time.Now()
+	series, err := h.history.Aggregate(ctx, history.Query{
+		Metric: metric,
+		Labels: history.FormatLabels(labels),
+		Since:  until.Add(-rangeDur),
+		Until:  until,
+		Bucket: bucket,
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to query metrics history: %v", err)), nil
+	}
+
+	result := map[string]interface{}{
+		"metric": metric,
+		"range":  rangeDur.String(),
+		"bucket": bucket.String(),
+		"series": series,
+	}
+	if adjusted {
+		result["bucket_adjusted"] = true
+	}
+	if len(series) == 0 {
+		result["note"] = "No samples in range; call query_metrics without a metric to list stored series"
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// historyBucket picks the bucket width for a range: the requested width if given, else
+// about targetHistoryBucket buckets. The width is never finer than the sample interval nor
+// allowed to yield more than maxHistoryBuckets buckets; adjusted reports a changed request.
+func historyBucket(rangeDur, requested, sampleInterval time.Duration) (bucket time.Duration, adjusted bool) {
+	bucket = requested
+	if bucket <= 0 {
+		bucket = rangeDur / targetHistoryBucket
+	}
+	if minBucket := rangeDur / maxHistoryBuckets; bucket < minBucket {
+		bucket = minBucket
+	}
+	if bucket < sampleInterval {
+		bucket = sampleInterval
+	}
+	bucket = bucket.Round(time.Second)
+	if bucket < time.Second {
+		bucket = time.Second
+	}
+	return bucket, requested > 0 && bucket != requested
+}
+
+// parseHistoryDuration parses a Go duration, additionally accepting a whole number of
+// days such as "7d"
+func parseHistoryDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sysmetrics-mcp/internal/config"
+	"sysmetrics-mcp/internal/history"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func newHistoryTestManager(t *testing.T) *HandlerManager {
+	t.Helper()
+	store, err := history.Open(filepath.Join(t.TempDir(), "history.db"), 0)
+	if err != nil {
+		t.Fatalf("Failed to open history store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	h := NewHandlerManager(&config.Config{SampleInterval: time.Minute})
+	h.SetHistory(store)
+	return h
+}
+
+func TestHandleQueryMetrics(t *testing.T) {
+	h := newHistoryTestManager(t)
+	ctx := context.Background()
+
+	samples := h.collectSamples(ctx, time.Now().Add(-time.Minute))
+	if len(samples) == 0 {
+		t.Fatal("Expected the sampler to collect at least one sample")
+	}
+	h.writeSamples(ctx, samples)
+
+	req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]interface{}{}}}
+	res, err := h.HandleQueryMetrics(ctx, req)
+	checkToolResult(t, res, err, []string{"metrics", "retention", "tiers", "sample_interval"})
+
+	req.Params.Arguments = map[string]interface{}{"metric": "memory_used_percent", "range": "1h"}
+	res, err = h.HandleQueryMetrics(ctx, req)
+	checkToolResult(t, res, err, []string{"metric", "range", "bucket", "series"})
+
+	var result struct {
+		Bucket string           `json:"bucket"`
+		Series []history.Series `json:"series"`
+	}
+	if err := json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &result); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+	if result.Bucket != "1m0s" {
+		t.Errorf("Expected a 1m bucket for a 1h range, got %s", result.Bucket)
+	}
+	if len(result.Series) != 1 || result.Series[0].Buckets[0].Count != 1 {
+		t.Errorf("Expected one memory series with one sample, got %+v", result.Series)
+	}
+}
+
+func TestHandleQueryMetricsInvalidArgs(t *testing.T) {
+	h := newHistoryTestManager(t)
+
+	for _, args := range []map[string]interface{}{
+		{"metric": "cpu_percent", "range": "yesterday"},
+		{"metric": "cpu_percent", "bucket": "-5m"},
+		{"metric": "cpu_percent", "labels": "mount"},
+	} {
+		req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}}
+		res, err := h.HandleQueryMetrics(context.Background(), req)
+		if err != nil || res == nil || !res.IsError {
+			t.Errorf("Expected a tool error for %v, got %+v (err %v)", args, res, err)
+		}
+	}
+}
+
+func TestHistoryBucket(t *testing.T) {
+	tests := []struct {
+		name           string
+		rangeDur       time.Duration
+		requested      time.Duration
+		sampleInterval time.Duration
+		expected       time.Duration
+		adjusted       bool
+	}{
+		{"auto", time.Hour, 0, time.Minute, time.Minute, false},
+		{"auto floors at sample interval", 10 * time.Minute, 0, time.Minute, time.Minute, false},
+		{"requested", 24 * time.Hour, time.Hour, time.Minute, time.Hour, false},
+		{"too many buckets", 7 * 24 * time.Hour, time.Minute, time.Minute, 7 * 24 * time.Hour / maxHistoryBuckets, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, adjusted := historyBucket(tt.rangeDur, tt.requested, tt.sampleInterval)
+			if got != tt.expected.Round(time.Second) || adjusted != tt.adjusted {
+				t.Errorf("historyBucket = %s, %v; expected %s, %v", got, adjusted, tt.expected, tt.adjusted)
+			}
+		})
+	}
+}
+
+func TestParseHistoryDuration(t *testing.T) {
+	if d, err := parseHistoryDuration("7d"); err != nil || d != 7*24*time.Hour {
+		t.Errorf("parseHistoryDuration(7d) = %s, %v", d, err)
+	}
+	if d, err := parseHistoryDuration("90m"); err != nil || d != 90*time.Minute {
+		t.Errorf("parseHistoryDuration(90m) = %s, %v", d, err)
+	}
+	if _, err := parseHistoryDuration("1.5d"); err == nil {
+		t.Error("Expected an error for fractional days")
+	}
+}
+
+func TestHandleGetMetricSnapshot(t *testing.T) {
+	h := NewHandlerManager(&config.Config{})
+	req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]interface{}{"collectors": "memory,load", "describe": true}}}
+	res, err := h.HandleGetMetricSnapshot(context.Background(), req)
+	checkToolResult(t, res, err, []string{"collectors", "total_metrics", "timestamp"})
+
+	var result struct {
+		Collectors []struct {
+			Name        string `json:"name"`
+			Metrics     []map[string]interface{}
+			Descriptors []map[string]interface{}
+		} `json:"collectors"`
+	}
+	if err := json.Unmarshal([]byte(resultText(res)), &result); err != nil {
+		t.Fatal(err)
+	}
+	// Collectors come back in registration order
+	if len(result.Collectors) != 2 || result.Collectors[0].Name != "load" || result.Collectors[1].Name != "memory" {
+		t.Fatalf("Unexpected collectors: %+v", result.Collectors)
+	}
+	if len(result.Collectors[1].Metrics) == 0 || result.Collectors[1].Metrics[0]["unit"] != "percent" || len(result.Collectors[1].Descriptors) != 2 {
+		t.Errorf("Unexpected memory collector: %+v", result.Collectors[1])
+	}
+
+	req.Params.Arguments = map[string]interface{}{"collectors": "gpu"}
+	if res, _ := h.HandleGetMetricSnapshot(context.Background(), req); !res.IsError {
+		t.Error("Expected an error for an unknown collector")
+	}
+}
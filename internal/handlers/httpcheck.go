@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// HTTP check limits
+const (
+	defaultHTTPTimeoutMs = 5000
+	maxHTTPTimeoutMs     = 30000
+	maxHTTPEndpoints     = 10
+	maxHTTPRedirects     = 10
+	maxHTTPBodyBytes     = 1 << 20
+)
+
+// httpHop is a single response in a redirect chain
+type httpHop struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code"`
+}
+
+// endpointResult is the outcome of checking a single HTTP endpoint
+type endpointResult struct {
+	URL        string    `json:"url"`
+	Success    bool      `json:"success"`
+	StatusCode int       `json:"status_code,omitempty"`
+	LatencyMs  float64   `json:"latency_ms,omitempty"`
+	FinalURL   string    `json:"final_url,omitempty"`
+	Redirects  []httpHop `json:"redirects,omitempty"`
+	TLS        *certInfo `json:"tls,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// hopRecorder is an http.RoundTripper that records the status of every response,
+// including redirects the client follows
+type hopRecorder struct {
+	next http.RoundTripper
+	mu   sync.Mutex
+	hops []httpHop
+}
+
+// RoundTrip performs the request and records its URL and status code
+func (r *hopRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.next.RoundTrip(req)
+	if err == nil {
+		r.mu.Lock()
+		r.hops = append(r.hops, httpHop{URL: req.URL.String(), StatusCode: resp.StatusCode})
+		r.mu.Unlock()
+	}
+	return resp, err
+}
+
+// HandleCheckHTTPEndpoints checks status, latency, TLS expiry, and redirects of HTTP endpoints
+func (h *HandlerManager) HandleCheckHTTPEndpoints(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var urls []string
+	method := http.MethodGet
+	timeoutMs := defaultHTTPTimeoutMs
+	followRedirects := true
+	skipVerify := false
+
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if s, ok := args["urls"].(string); ok && s != "" {
+			urls = config.SplitAndTrim(s)
+		}
+		if m, ok := args["method"].(string); ok && m != "" {
+			method = strings.ToUpper(m)
+		}
+		if t, ok := args["timeout_ms"].(float64); ok && t > 0 {
+			timeoutMs = int(t)
+			if timeoutMs > maxHTTPTimeoutMs {
+				timeoutMs = maxHTTPTimeoutMs
+			}
+		}
+		if f, ok := args["follow_redirects"].(bool); ok {
+			followRedirects = f
+		}
+		if s, ok := args["skip_tls_verify"].(bool); ok {
+			skipVerify = s
+		}
+	}
+
+	if len(urls) == 0 {
+		return mcp.NewToolResultError("urls is required"), nil
+	}
+	if len(urls) > maxHTTPEndpoints {
+		return mcp.NewToolResultError(fmt.Sprintf("At most %d URLs may be checked per call", maxHTTPEndpoints)), nil
+	}
+	if method != http.MethodGet && method != http.MethodHead {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid method: %s (must be GET or HEAD)", method)), nil
+	}
+	for _, raw := range urls {
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid URL: %q (must be an absolute http or https URL)", raw)), nil
+		}
+	}
+
+	opts := httpCheckOptions{
+		method:          method,
+		timeout:         time.Duration(timeoutMs) * time.Millisecond,
+		followRedirects: followRedirects,
+		skipVerify:      skipVerify,
+	}
+	results := make([]endpointResult, len(urls))
+	var wg sync.WaitGroup
+	for i, u := range urls {
+		wg.Add(1)
+		go func(i int, u string) {
+			defer wg.Done()
+			results[i] = checkHTTPEndpoint(ctx, u, opts)
+		}(i, u)
+	}
+	wg.Wait()
+
+	healthy := 0
+	for _, r := range results {
+		if r.Success {
+			healthy++
+		}
+	}
+
+	result := map[string]interface{}{
+		"endpoints":  results,
+		"total":      len(results),
+		"healthy":    healthy,
+		"timeout_ms": timeoutMs,
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// httpCheckOptions configures a single endpoint check
+type httpCheckOptions struct {
+	method          string
+	timeout         time.Duration
+	followRedirects bool
+	skipVerify      bool
+}
+
+// checkHTTPEndpoint requests a URL and records the status, latency, redirect chain,
+// and leaf certificate of the final response
+func checkHTTPEndpoint(ctx context.Context, target string, opts httpCheckOptions) endpointResult {
+	res := endpointResult{URL: target}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DisableKeepAlives = true
+	if opts.skipVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec // G402: opt-in for self-signed homelab services
+	}
+	recorder := &hopRecorder{next: transport}
+	client := &http.Client{
+		Transport: recorder,
+		Timeout:   opts.timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if !opts.followRedirects {
+				return http.ErrUseLastResponse
+			}
+			if len(via) >= maxHTTPRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxHTTPRedirects)
+			}
+			return nil
+		},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, opts.method, target, nil)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	req.Header.Set("User-Agent", config.ServerName+"/"+config.ServerVersion)
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	res.LatencyMs = float64(time.Since(start).Microseconds()) / 1000
+	if len(recorder.hops) > 1 {
+		res.Redirects = recorder.hops[:len(recorder.hops)-1]
+	}
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxHTTPBodyBytes))
+
+	res.StatusCode = resp.StatusCode
+	res.FinalURL = resp.Request.URL.String()
+	res.Success = resp.StatusCode < http.StatusBadRequest
+	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		res.TLS = newCertInfo(resp.TLS.PeerCertificates[0], time.Now())
+	}
+	return res
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestCheckHTTPEndpointRedirects(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/ok", http.StatusFound)
+	})
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	opts := httpCheckOptions{method: http.MethodGet, timeout: 5 * time.Second, followRedirects: true}
+	res := checkHTTPEndpoint(context.Background(), srv.URL+"/old", opts)
+	if !res.Success || res.StatusCode != http.StatusOK || res.FinalURL != srv.URL+"/ok" {
+		t.Fatalf("Unexpected result: %+v", res)
+	}
+	if len(res.Redirects) != 2 || res.Redirects[0].StatusCode != http.StatusMovedPermanently || res.Redirects[1].URL != srv.URL+"/new" {
+		t.Errorf("Unexpected redirect chain: %+v", res.Redirects)
+	}
+	if res.TLS != nil {
+		t.Errorf("Expected no TLS info for plain HTTP, got %+v", res.TLS)
+	}
+
+	opts.followRedirects = false
+	res = checkHTTPEndpoint(context.Background(), srv.URL+"/old", opts)
+	if res.StatusCode != http.StatusMovedPermanently || len(res.Redirects) != 0 || !res.Success {
+		t.Errorf("Unexpected result without following redirects: %+v", res)
+	}
+}
+
+func TestCheckHTTPEndpointStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	res := checkHTTPEndpoint(context.Background(), srv.URL, httpCheckOptions{method: http.MethodHead, timeout: 5 * time.Second})
+	if res.Success || res.StatusCode != http.StatusServiceUnavailable || res.Error != "" {
+		t.Errorf("Unexpected result: %+v", res)
+	}
+
+	srv.Close()
+	res = checkHTTPEndpoint(context.Background(), srv.URL, httpCheckOptions{method: http.MethodGet, timeout: time.Second})
+	if res.Success || res.Error == "" {
+		t.Errorf("Expected connection error, got %+v", res)
+	}
+}
+
+func TestCheckHTTPEndpointTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	opts := httpCheckOptions{method: http.MethodGet, timeout: 5 * time.Second}
+	res := checkHTTPEndpoint(context.Background(), srv.URL, opts)
+	if res.Success || res.Error == "" {
+		t.Errorf("Expected verification failure for self-signed certificate, got %+v", res)
+	}
+
+	opts.skipVerify = true
+	res = checkHTTPEndpoint(context.Background(), srv.URL, opts)
+	if !res.Success || res.TLS == nil {
+		t.Fatalf("Expected TLS info with skip_tls_verify, got %+v", res)
+	}
+	if res.TLS.DaysRemaining <= 0 || res.TLS.NotAfter == "" {
+		t.Errorf("Unexpected certificate info: %+v", res.TLS)
+	}
+}
+
+func TestHandleCheckHTTPEndpoints(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	h := NewHandlerManager(&config.Config{})
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{"urls": srv.URL + "," + srv.URL + "/health"},
+		},
+	}
+	res, err := h.HandleCheckHTTPEndpoints(context.Background(), req)
+	checkToolResult(t, res, err, []string{"endpoints", "total", "healthy"})
+
+	var result map[string]interface{}
+	if err := json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &result); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+	if result["healthy"] != float64(2) {
+		t.Errorf("Expected 2 healthy endpoints, got %v", result["healthy"])
+	}
+
+	for _, args := range []map[string]interface{}{
+		{},
+		{"urls": "ftp://example.com"},
+		{"urls": "example.com"},
+		{"urls": srv.URL, "method": "POST"},
+	} {
+		req.Params.Arguments = args
+		res, err := h.HandleCheckHTTPEndpoints(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Handler returned error: %v", err)
+		}
+		if !res.IsError {
+			t.Errorf("Expected error result for %v", args)
+		}
+	}
+}
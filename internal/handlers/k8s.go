@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// criUint64 is a CRI counter value which crictl may encode as a JSON string or number
+type criUint64 uint64
+
+// UnmarshalJSON accepts both quoted and unquoted integers
+func (v *criUint64) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "" || s == "null" {
+		*v = 0
+		return nil
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid CRI counter %q: %w", s, err)
+	}
+	*v = criUint64(n)
+	return nil
+}
+
+// criPodList mirrors the output of `crictl pods -o json`
+type criPodList struct {
+	Items []struct {
+		ID       string `json:"id"`
+		Metadata struct {
+			Name      string `json:"name"`
+			UID       string `json:"uid"`
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+		State string `json:"state"`
+	} `json:"items"`
+}
+
+// criContainerList mirrors the output of `crictl ps -a -o json`
+type criContainerList struct {
+	Containers []struct {
+		ID           string `json:"id"`
+		PodSandboxID string `json:"podSandboxId"`
+		Metadata     struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		State       string            `json:"state"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"containers"`
+}
+
+// criStatsList mirrors the output of `crictl stats -a -o json`
+type criStatsList struct {
+	Stats []struct {
+		Attributes struct {
+			ID string `json:"id"`
+		} `json:"attributes"`
+		CPU struct {
+			UsageNanoCores struct {
+				Value criUint64 `json:"value"`
+			} `json:"usageNanoCores"`
+		} `json:"cpu"`
+		Memory struct {
+			WorkingSetBytes struct {
+				Value criUint64 `json:"value"`
+			} `json:"workingSetBytes"`
+		} `json:"memory"`
+	} `json:"stats"`
+}
+
+// k8sPodInfo is the per-pod summary returned by get_k8s_metrics
+type k8sPodInfo struct {
+	Name          string  `json:"name"`
+	Namespace     string  `json:"namespace"`
+	UID           string  `json:"uid"`
+	State         string  `json:"state"`
+	Containers    int     `json:"containers"`
+	RunningCount  int     `json:"running_containers"`
+	Restarts      int     `json:"restarts"`
+	CPUMillicores float64 `json:"cpu_millicores"`
+	MemoryBytes   uint64  `json:"memory_working_set_bytes"`
+	MemoryHuman   string  `json:"memory_working_set_human"`
+}
+
+// HandleGetK8sMetrics returns pod metrics for the local Kubernetes node via the CRI
+func (h *HandlerManager) HandleGetK8sMetrics(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var namespaceFilter string
+
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if ns, ok := args["namespace"].(string); ok && ns != "" {
+			namespaceFilter = ns
+		}
+	}
+
+	podsOut, err := runCrictl(ctx, "pods", "-o", "json")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to list Kubernetes pods: %v", err)), nil
+	}
+	var pods criPodList
+	if err := json.Unmarshal(podsOut, &pods); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to parse pod list: %v", err)), nil
+	}
+
+	// Container and stats listings are best-effort; pods are still reported without them
+	var containers criContainerList
+	if out, err := runCrictl(ctx, "ps", "-a", "-o", "json"); err == nil {
+		_ = json.Unmarshal(out, &containers)
+	}
+	var stats criStatsList
+	if out, err := runCrictl(ctx, "stats", "-a", "-o", "json"); err == nil {
+		_ = json.Unmarshal(out, &stats)
+	}
+
+	podList := buildK8sPods(pods, containers, stats, namespaceFilter)
+
+	var totalCPU float64
+	var totalMem uint64
+	for i := range podList {
+		podList[i].MemoryHuman = h.human.Bytes(podList[i].MemoryBytes)
+		totalCPU += podList[i].CPUMillicores
+		totalMem += podList[i].MemoryBytes
+	}
+
+	result := map[string]interface{}{
+		"pods":                 podList,
+		"total":                len(podList),
+		"total_cpu_millicores": totalCPU,
+		"total_memory_bytes":   totalMem,
+		"total_memory_human":   h.human.Bytes(totalMem),
+		"stats_available":      len(stats.Stats) > 0,
+		"containers_available": len(containers.Containers) > 0,
+	}
+
+	if namespaceFilter != "" {
+		result["namespace_filter"] = namespaceFilter
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// buildK8sPods joins CRI pod, container, and stats listings into per-pod summaries
+func buildK8sPods(pods criPodList, containers criContainerList, stats criStatsList, namespaceFilter string) []k8sPodInfo {
+	podMap := make(map[string]*k8sPodInfo)
+	for _, p := range pods.Items {
+		if namespaceFilter != "" && p.Metadata.Namespace != namespaceFilter {
+			continue
+		}
+		podMap[p.ID] = &k8sPodInfo{
+			Name:      p.Metadata.Name,
+			Namespace: p.Metadata.Namespace,
+			UID:       p.Metadata.UID,
+			State:     p.State,
+		}
+	}
+
+	// Map container IDs to their pod so stats can be attributed
+	containerPod := make(map[string]string)
+	for _, c := range containers.Containers {
+		pod, ok := podMap[c.PodSandboxID]
+		if !ok {
+			continue
+		}
+		containerPod[c.ID] = c.PodSandboxID
+		pod.Containers++
+		if c.State == "CONTAINER_RUNNING" {
+			pod.RunningCount++
+		}
+		if rc, err := strconv.Atoi(c.Annotations["io.kubernetes.container.restartCount"]); err == nil {
+			pod.Restarts += rc
+		}
+	}
+
+	for _, s := range stats.Stats {
+		pod, ok := podMap[containerPod[s.Attributes.ID]]
+		if !ok {
+			continue
+		}
+		pod.CPUMillicores += float64(s.CPU.UsageNanoCores.Value) / 1e6
+		pod.MemoryBytes += uint64(s.Memory.WorkingSetBytes.Value)
+	}
+
+	podList := make([]k8sPodInfo, 0, len(podMap))
+	for _, p := range podMap {
+		podList = append(podList, *p)
+	}
+
+	sort.Slice(podList, func(i, j int) bool {
+		if podList[i].Namespace != podList[j].Namespace {
+			return podList[i].Namespace < podList[j].Namespace
+		}
+		return podList[i].Name < podList[j].Name
+	})
+
+	return podList
+}
+
+// runCrictl runs crictl directly, falling back to the copy bundled with k3s
+func runCrictl(ctx context.Context, args ...string) ([]byte, error) {
+	if _, err := exec.LookPath("crictl"); err == nil {
+		return exec.CommandContext(ctx, "crictl", args...).Output()
+	}
+	if _, err := exec.LookPath("k3s"); err == nil {
+		return exec.CommandContext(ctx, "k3s", append([]string{"crictl"}, args...)...).Output()
+	}
+	return nil, fmt.Errorf("neither crictl nor k3s found in PATH")
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBuildK8sPods(t *testing.T) {
+	podsJSON := `{"items":[
+		{"id":"pod1","metadata":{"name":"coredns-abc","uid":"u1","namespace":"kube-system"},"state":"SANDBOX_READY"},
+		{"id":"pod2","metadata":{"name":"web-123","uid":"u2","namespace":"default"},"state":"SANDBOX_READY"}]}`
+	containersJSON := `{"containers":[
+		{"id":"c1","podSandboxId":"pod1","metadata":{"name":"coredns"},"state":"CONTAINER_RUNNING","annotations":{"io.kubernetes.container.restartCount":"2"}},
+		{"id":"c2","podSandboxId":"pod2","metadata":{"name":"web"},"state":"CONTAINER_EXITED","annotations":{"io.kubernetes.container.restartCount":"5"}}]}`
+	statsJSON := `{"stats":[
+		{"attributes":{"id":"c1"},"cpu":{"usageNanoCores":{"value":"25000000"}},"memory":{"workingSetBytes":{"value":"1048576"}}},
+		{"attributes":{"id":"c2"},"cpu":{"usageNanoCores":{"value":3000000}},"memory":{"workingSetBytes":{"value":2048}}}]}`
+
+	var pods criPodList
+	var containers criContainerList
+	var stats criStatsList
+	for _, item := range []struct {
+		data string
+		dst  interface{}
+	}{{podsJSON, &pods}, {containersJSON, &containers}, {statsJSON, &stats}} {
+		if err := json.Unmarshal([]byte(item.data), item.dst); err != nil {
+			t.Fatalf("Failed to parse fixture: %v", err)
+		}
+	}
+
+	result := buildK8sPods(pods, containers, stats, "")
+	if len(result) != 2 {
+		t.Fatalf("Expected 2 pods, got %d", len(result))
+	}
+
+	// Sorted by namespace: default first
+	web := result[0]
+	if web.Name != "web-123" || web.Restarts != 5 || web.RunningCount != 0 || web.CPUMillicores != 3 {
+		t.Errorf("Unexpected web pod summary: %+v", web)
+	}
+	dns := result[1]
+	if dns.Restarts != 2 || dns.RunningCount != 1 || dns.CPUMillicores != 25 || dns.MemoryBytes != 1048576 {
+		t.Errorf("Unexpected coredns pod summary: %+v", dns)
+	}
+
+	filtered := buildK8sPods(pods, containers, stats, "kube-system")
+	if len(filtered) != 1 || filtered[0].Namespace != "kube-system" {
+		t.Errorf("Expected only kube-system pods, got %+v", filtered)
+	}
+}
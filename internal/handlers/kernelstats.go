@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/shirou/gopsutil/v3/host"
+)
+
+// Kernel counter sources (Linux)
+var (
+	procStatPath        = "/proc/stat"
+	entropyAvailPath    = "/proc/sys/kernel/random/entropy_avail"
+	entropyPoolSizePath = "/proc/sys/kernel/random/poolsize"
+	fileNrPath          = "/proc/sys/fs/file-nr"
+)
+
+// Kernel stats sampling limits. Sampling is opt-in; since-boot averages are always reported.
+const (
+	defaultKernelStatsSampleSeconds = 0
+	maxKernelStatsSampleSeconds     = 10
+)
+
+// Kernel stats warning thresholds
+const (
+	// lowEntropyBits is where reads from /dev/random blocked on kernels before 5.18
+	lowEntropyBits = 200
+	// fileHandleWarnPercent is the share of fs.file-max in use worth flagging
+	fileHandleWarnPercent = 80.0
+)
+
+// kernelCounters are the cumulative /proc/stat counters since boot
+type kernelCounters struct {
+	contextSwitches uint64
+	interrupts      uint64
+	softirqs        uint64
+	forks           uint64
+	procsRunning    uint64
+	procsBlocked    uint64
+}
+
+// readKernelCounters parses the ctxt, intr, softirq, processes, and procs_* lines of
+// /proc/stat; intr and softirq start with their totals
+func readKernelCounters(path string) (kernelCounters, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return kernelCounters{}, err
+	}
+	defer f.Close()
+
+	var c kernelCounters
+	found := false
+	scanner := bufio.NewScanner(f)
+	// The intr line lists every IRQ and can exceed the default token size
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		v, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch fields[0] {
+		case "ctxt":
+			c.contextSwitches, found = v, true
+		case "intr":
+			c.interrupts = v
+		case "softirq":
+			c.softirqs = v
+		case "processes":
+			c.forks = v
+		case "procs_running":
+			c.procsRunning = v
+		case "procs_blocked":
+			c.procsBlocked = v
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return kernelCounters{}, err
+	}
+	if !found {
+		return kernelCounters{}, fmt.Errorf("no ctxt line in %s", path)
+	}
+	return c, nil
+}
+
+// kernelRates converts counter increases over seconds into per-second rates
+func kernelRates(before, after kernelCounters, seconds float64) map[string]interface{} {
+	rate := func(b, a uint64) float64 {
+		if a < b || seconds <= 0 {
+			return 0
+		}
+		return round2(float64(a-b) / seconds)
+	}
+	return map[string]interface{}{
+		"context_switches_per_sec": rate(before.contextSwitches, after.contextSwitches),
+		"interrupts_per_sec":       rate(before.interrupts, after.interrupts),
+		"softirqs_per_sec":         rate(before.softirqs, after.softirqs),
+		"forks_per_sec":            rate(before.forks, after.forks),
+	}
+}
+
+// HandleGetKernelStats returns context switch, interrupt, softirq, and fork rates from
+// /proc/stat deltas, run queue counts, available entropy, and file handle usage
+func (h *HandlerManager) HandleGetKernelStats(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	sampleSeconds := defaultKernelStatsSampleSeconds
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if s, ok := args["sample_seconds"].(float64); ok && s >= 0 {
+			sampleSeconds = min(int(s), maxKernelStatsSampleSeconds)
+		}
+	}
+
+	before, err := readKernelCounters(procStatPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read kernel counters: %v", err)), nil
+	}
+	result := map[string]interface{}{
+		"totals": map[string]interface{}{
+			"context_switches": before.contextSwitches,
+			"interrupts":       before.interrupts,
+			"softirqs":         before.softirqs,
+			"forks":            before.forks,
+		},
+	}
+	if uptime, err := host.UptimeWithContext(ctx); err == nil && uptime > 0 {
+		result["since_boot"] = kernelRates(kernelCounters{}, before, float64(uptime))
+	}
+
+	now := before
+	if sampleSeconds > 0 {
+		start := time.Now()
+		select {
+		case <-ctx.Done():
+			return mcp.NewToolResultError(fmt.Sprintf("Sampling cancelled: %v", ctx.Err())), nil
+		case <-time.After(time.Duration(sampleSeconds) * time.Second):
+		}
+		if after, err := readKernelCounters(procStatPath); err == nil {
+			result["sampled"] = kernelRates(before, after, time.Since(start).Seconds())
+			result["sample_seconds"] = sampleSeconds
+			now = after
+		}
+	}
+	result["procs_running"] = now.procsRunning
+	result["procs_blocked"] = now.procsBlocked
+
+	warnings := []string{}
+	entropy := map[string]interface{}{"available": false}
+	if s, err := readTrimmed(entropyAvailPath); err == nil {
+		if bits, err := strconv.ParseUint(s, 10, 64); err == nil {
+			entropy["available"] = true
+			entropy["bits"] = bits
+			if s, err := readTrimmed(entropyPoolSizePath); err == nil {
+				if size, err := strconv.ParseUint(s, 10, 64); err == nil {
+					entropy["pool_size_bits"] = size
+				}
+			}
+			// Since Linux 5.18 the pool is always reported as a full 256 bits
+			if bits < lowEntropyBits {
+				warnings = append(warnings, fmt.Sprintf("Only %d bits of entropy available; on older kernels reads from /dev/random block, which can stall TLS and SSH key generation (install rng-tools or haveged)", bits))
+			}
+		}
+	}
+	result["entropy"] = entropy
+
+	handles := map[string]interface{}{"available": false}
+	if data, err := os.ReadFile(filepath.Clean(fileNrPath)); err == nil {
+		if stat, err := parseFileNr(string(data)); err == nil {
+			handles["available"] = true
+			handles["allocated"] = stat.Allocated
+			handles["unused"] = stat.Unused
+			handles["max"] = stat.Max
+			if stat.Max > 0 {
+				percent := round2(float64(stat.Allocated) / float64(stat.Max) * 100)
+				handles["usage_percent"] = percent
+				if percent >= fileHandleWarnPercent {
+					warnings = append(warnings, fmt.Sprintf("%.1f%% of the system file handle limit (fs.file-max %d) is in use", percent, stat.Max))
+				}
+			}
+		}
+	}
+	result["file_handles"] = handles
+	result["warnings"] = warnings
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
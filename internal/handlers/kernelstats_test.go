@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestReadKernelCounters(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "stat")
+	stat := "cpu  10 0 10 100 0 0 0 0 0 0\ncpu0 10 0 10 100 0 0 0 0 0 0\nintr 5000 0 9 0 " + strings.Repeat("0 ", 20000) + "\nctxt 123456\nbtime 1700000000\nprocesses 4321\nprocs_running 3\nprocs_blocked 1\nsoftirq 800 0 100 0 0\n"
+	if err := os.WriteFile(path, []byte(stat), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c, err := readKernelCounters(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.contextSwitches != 123456 || c.interrupts != 5000 || c.softirqs != 800 || c.forks != 4321 || c.procsRunning != 3 || c.procsBlocked != 1 {
+		t.Errorf("Unexpected counters: %+v", c)
+	}
+
+	rates := kernelRates(c, kernelCounters{contextSwitches: 124456, interrupts: 5500, softirqs: 800, forks: 4331}, 2)
+	if rates["context_switches_per_sec"] != 500.0 || rates["interrupts_per_sec"] != 250.0 || rates["softirqs_per_sec"] != 0.0 || rates["forks_per_sec"] != 5.0 {
+		t.Errorf("Unexpected rates: %v", rates)
+	}
+
+	if err := os.WriteFile(path, []byte("cpu 1 2 3\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readKernelCounters(path); err == nil {
+		t.Error("Expected an error without a ctxt line")
+	}
+}
+
+func TestHandleGetKernelStats(t *testing.T) {
+	dir := t.TempDir()
+	origStat, origAvail, origPool, origFileNr := procStatPath, entropyAvailPath, entropyPoolSizePath, fileNrPath
+	defer func() {
+		procStatPath, entropyAvailPath, entropyPoolSizePath, fileNrPath = origStat, origAvail, origPool, origFileNr
+	}()
+	procStatPath = filepath.Join(dir, "stat")
+	entropyAvailPath = filepath.Join(dir, "entropy_avail")
+	entropyPoolSizePath = filepath.Join(dir, "poolsize")
+	fileNrPath = filepath.Join(dir, "file-nr")
+	for path, content := range map[string]string{
+		procStatPath:        "intr 100\nctxt 2000\nprocesses 50\nprocs_running 2\nprocs_blocked 0\n",
+		entropyAvailPath:    "150\n",
+		entropyPoolSizePath: "4096\n",
+		fileNrPath:          "9000\t0\t10000\n",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h := NewHandlerManager(&config.Config{})
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"sample_seconds": float64(0)}
+	res, err := h.HandleGetKernelStats(context.Background(), req)
+	checkToolResult(t, res, err, []string{"totals", "procs_running", "procs_blocked", "entropy", "file_handles", "warnings"})
+	var result struct {
+		Totals   map[string]uint64      `json:"totals"`
+		Entropy  map[string]interface{} `json:"entropy"`
+		Handles  map[string]interface{} `json:"file_handles"`
+		Warnings []string               `json:"warnings"`
+		Sampled  map[string]float64     `json:"sampled"`
+	}
+	if err := json.Unmarshal([]byte(resultText(res)), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Totals["context_switches"] != 2000 || result.Totals["forks"] != 50 || result.Sampled != nil {
+		t.Errorf("Unexpected totals or sample: %+v", result)
+	}
+	if result.Entropy["bits"] != 150.0 || result.Entropy["pool_size_bits"] != 4096.0 || result.Handles["usage_percent"] != 90.0 {
+		t.Errorf("Unexpected entropy or handles: %v %v", result.Entropy, result.Handles)
+	}
+	if len(result.Warnings) != 2 {
+		t.Errorf("Expected low entropy and file handle warnings, got %v", result.Warnings)
+	}
+
+	procStatPath = filepath.Join(dir, "missing")
+	if res, err := h.HandleGetKernelStats(context.Background(), req); err != nil || !res.IsError {
+		t.Error("Expected an error without /proc/stat")
+	}
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	stdnet "net"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/shirou/gopsutil/v3/net"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// Listening socket bind scope constants.
+const (
+	scopeAllInterfaces = "all_interfaces"
+	scopeLoopback      = "loopback"
+	scopeAddress       = "address"
+)
+
+// listeningFields lists the columns get_listening_ports can return
+var listeningFields = []string{"type", "address", "port", "scope", "pid", "name", "user", "exe", "exposed", "note"}
+
+// listeningSocket is a single bound server socket and its owning process
+type listeningSocket struct {
+	Type    string
+	Address string
+	Port    uint32
+	PID     int32
+}
+
+// processOwner holds the identity of a socket's owning process
+type processOwner struct {
+	Name string
+	User string
+	Exe  string
+}
+
+// HandleGetListeningPorts returns listening sockets enriched with their owning process
+func (h *HandlerManager) HandleGetListeningPorts(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	kind := kindAll
+	checkExposure := true
+
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if k, ok := args["kind"].(string); ok && k != "" {
+			kind = strings.ToLower(k)
+		}
+		if c, ok := args["check_exposure"].(bool); ok {
+			checkExposure = c
+		}
+	}
+
+	// Validate kind parameter against known values
+	if kind != kindTCP && kind != kindUDP {
+		kind = kindAll
+	}
+
+	fields, err := parseFieldsArg(request, listeningFields)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	connections, err := net.ConnectionsWithContext(ctx, kind)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get network connections: %v", err)), nil
+	}
+
+	sockets := filterListening(connections)
+	needOwner := wantField(fields, "name") || wantField(fields, "user") || wantField(fields, "exe")
+
+	owners := make(map[int32]processOwner)
+	rows := make([]map[string]interface{}, 0, len(sockets))
+	exposedCount, unresolved := 0, 0
+	for _, sock := range sockets {
+		scope := bindScope(sock.Address)
+		row := map[string]interface{}{
+			"type":    sock.Type,
+			"address": sock.Address,
+			"port":    sock.Port,
+			"scope":   scope,
+			"pid":     sock.PID,
+		}
+
+		if sock.PID == 0 {
+			unresolved++
+		} else if needOwner {
+			owner, ok := owners[sock.PID]
+			if !ok {
+				owner = lookupProcessOwner(ctx, sock.PID)
+				owners[sock.PID] = owner
+			}
+			row["name"] = owner.Name
+			row["user"] = owner.User
+			row["exe"] = owner.Exe
+		}
+
+		if checkExposure {
+			exposed := scope == scopeAllInterfaces
+			row["exposed"] = exposed
+			if exposed {
+				exposedCount++
+				row["note"] = fmt.Sprintf("%s port %d is bound to all interfaces and reachable from any network this host is on unless firewalled", strings.ToUpper(sock.Type), sock.Port)
+			}
+		}
+
+		rows = append(rows, projectFields(row, fields))
+	}
+
+	result := map[string]interface{}{
+		"ports":      rows,
+		"total":      len(rows),
+		"unresolved": unresolved,
+		"kind":       kind,
+	}
+	if checkExposure {
+		result["exposed_count"] = exposedCount
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// filterListening returns TCP sockets in LISTEN state and UDP sockets without a peer,
+// de-duplicated per owning process and ordered by port
+func filterListening(connections []net.ConnectionStat) []listeningSocket {
+	seen := make(map[listeningSocket]bool)
+	var sockets []listeningSocket
+	for _, c := range connections {
+		connType := connTypeToString(c.Type)
+		switch connType {
+		case kindTCP:
+			if c.Status != "LISTEN" {
+				continue
+			}
+		case kindUDP:
+			if c.Raddr.IP != "" && c.Raddr.Port != 0 {
+				continue
+			}
+		default:
+			continue
+		}
+
+		sock := listeningSocket{Type: connType, Address: c.Laddr.IP, Port: c.Laddr.Port, PID: c.Pid}
+		if seen[sock] {
+			continue
+		}
+		seen[sock] = true
+		sockets = append(sockets, sock)
+	}
+
+	sort.Slice(sockets, func(i, j int) bool {
+		a, b := sockets[i], sockets[j]
+		if a.Port != b.Port {
+			return a.Port < b.Port
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if a.Address != b.Address {
+			return a.Address < b.Address
+		}
+		return a.PID < b.PID
+	})
+	return sockets
+}
+
+// bindScope classifies a listening address as all interfaces, loopback, or a specific address
+func bindScope(addr string) string {
+	if addr == "" || addr == "*" {
+		return scopeAllInterfaces
+	}
+	ip := stdnet.ParseIP(addr)
+	switch {
+	case ip == nil:
+		return scopeAddress
+	case ip.IsUnspecified():
+		return scopeAllInterfaces
+	case ip.IsLoopback():
+		return scopeLoopback
+	default:
+		return scopeAddress
+	}
+}
+
+// lookupProcessOwner resolves the name, user, and executable path of a process,
+// leaving fields empty when they cannot be read
+func lookupProcessOwner(ctx context.Context, pid int32) processOwner {
+	var owner processOwner
+	p, err := process.NewProcessWithContext(ctx, pid)
+	if err != nil {
+		return owner
+	}
+	owner.Name, _ = p.NameWithContext(ctx)
+	owner.User, _ = p.UsernameWithContext(ctx)
+	owner.Exe, _ = p.ExeWithContext(ctx)
+	return owner
+}
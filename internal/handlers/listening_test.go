@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+func TestFilterListening(t *testing.T) {
+	connections := []net.ConnectionStat{
+		{Type: 1, Status: "LISTEN", Laddr: net.Addr{IP: "0.0.0.0", Port: 22}, Pid: 100},
+		{Type: 1, Status: "LISTEN", Laddr: net.Addr{IP: "0.0.0.0", Port: 22}, Pid: 100},
+		{Type: 1, Status: "ESTABLISHED", Laddr: net.Addr{IP: "192.168.1.5", Port: 22}, Raddr: net.Addr{IP: "192.168.1.10", Port: 5000}, Pid: 101},
+		{Type: 1, Status: "LISTEN", Laddr: net.Addr{IP: "127.0.0.1", Port: 631}, Pid: 200},
+		{Type: 2, Status: "NONE", Laddr: net.Addr{IP: "0.0.0.0", Port: 53}, Pid: 300},
+		{Type: 2, Status: "NONE", Laddr: net.Addr{IP: "192.168.1.5", Port: 40000}, Raddr: net.Addr{IP: "1.1.1.1", Port: 53}, Pid: 300},
+	}
+
+	sockets := filterListening(connections)
+	expected := []listeningSocket{
+		{Type: kindTCP, Address: "0.0.0.0", Port: 22, PID: 100},
+		{Type: kindUDP, Address: "0.0.0.0", Port: 53, PID: 300},
+		{Type: kindTCP, Address: "127.0.0.1", Port: 631, PID: 200},
+	}
+	if len(sockets) != len(expected) {
+		t.Fatalf("Expected %d sockets, got %d: %+v", len(expected), len(sockets), sockets)
+	}
+	for i := range expected {
+		if sockets[i] != expected[i] {
+			t.Errorf("Socket %d: expected %+v, got %+v", i, expected[i], sockets[i])
+		}
+	}
+}
+
+func TestBindScope(t *testing.T) {
+	tests := []struct {
+		addr     string
+		expected string
+	}{
+		{"0.0.0.0", scopeAllInterfaces},
+		{"::", scopeAllInterfaces},
+		{"*", scopeAllInterfaces},
+		{"127.0.0.1", scopeLoopback},
+		{"::1", scopeLoopback},
+		{"192.168.1.5", scopeAddress},
+		{"fe80::1", scopeAddress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			if got := bindScope(tt.addr); got != tt.expected {
+				t.Errorf("bindScope(%s) = %s, expected %s", tt.addr, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHandleGetListeningPorts(t *testing.T) {
+	h := NewHandlerManager(&config.Config{})
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{},
+		},
+	}
+	res, err := h.HandleGetListeningPorts(context.Background(), req)
+	checkToolResult(t, res, err, []string{"ports", "total", "unresolved", "exposed_count"})
+
+	req.Params.Arguments = map[string]interface{}{"fields": "port,bogus"}
+	res, err = h.HandleGetListeningPorts(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if !res.IsError {
+		t.Error("Expected error result for unknown field")
+	}
+}
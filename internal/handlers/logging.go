@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// maxLoggedError bounds how much of a tool error message is logged
+const maxLoggedError = 512
+
+// SetLogger replaces the structured logger; a nil logger is ignored
+func (h *HandlerManager) SetLogger(logger *slog.Logger) {
+	if logger != nil {
+		h.logger = logger
+	}
+}
+
+// logTool wraps a tool handler so every call is logged with its duration and outcome:
+// arguments at debug level, completed calls at info, tool errors at warn, and handler
+// failures at error
+func (h *HandlerManager) logTool(name string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		h.logger.Debug("tool call", "tool", name, "args", request.Params.Arguments)
+
+		start := time.Now()
+		result, err := handler(ctx, request)
+		attrs := []any{"tool", name, "duration_ms", time.Since(start).Milliseconds()}
+
+		switch {
+		case err != nil:
+			h.logger.Error("tool failed", append(attrs, "error", err)...)
+		case result != nil && result.IsError:
+			h.logger.Warn("tool returned an error", append(attrs, "error", truncateString(resultText(result), maxLoggedError))...)
+		default:
+			h.logger.Info("tool call", append(attrs, "result_bytes", len(resultText(result)))...)
+		}
+		return result, err
+	}
+}
+
+// resultText concatenates the text content of a tool result
+func resultText(result *mcp.CallToolResult) string {
+	if result == nil {
+		return ""
+	}
+	var text string
+	for _, c := range result.Content {
+		if t, ok := c.(mcp.TextContent); ok {
+			text += t.Text
+		}
+	}
+	return text
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+	"sysmetrics-mcp/internal/logging"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestLogTool(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   *mcp.CallToolResult
+		err      error
+		expected []string
+	}{
+		{"success", mcp.NewToolResultText(`{"ok":true}`), nil, []string{"level=INFO", "tool=probe", "result_bytes=11", "duration_ms="}},
+		{"tool error", mcp.NewToolResultError("Invalid range"), nil, []string{"level=WARN", `error="Invalid range"`}},
+		{"handler error", nil, errors.New("boom"), []string{"level=ERROR", "error=boom"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			h := NewHandlerManager(&config.Config{})
+			h.SetLogger(logging.NewWriter(&logs, slog.LevelDebug))
+
+			handler := h.logTool("probe", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return tt.result, tt.err
+			})
+			req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]interface{}{"limit": 5}}}
+			res, err := handler(context.Background(), req)
+			if res != tt.result || !errors.Is(err, tt.err) {
+				t.Errorf("Expected the handler result to pass through unchanged")
+			}
+
+			out := logs.String()
+			if !strings.Contains(out, "level=DEBUG") || !strings.Contains(out, "args=map[limit:5]") {
+				t.Errorf("Expected the arguments at debug level, got %q", out)
+			}
+			for _, want := range tt.expected {
+				if !strings.Contains(out, want) {
+					t.Errorf("Expected %q in logs, got %q", want, out)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+// Limits on the files and logs listed by get_log_growth
+const (
+	defaultLogGrowthLimit = 10
+	maxLogGrowthLimit     = 100
+)
+
+// journaldRuntimeDir holds the volatile journal, used when /var/log/journal does not exist
+var journaldRuntimeDir = "/run/log/journal"
+
+// rotatedSuffixRe matches what logrotate and friends append to a rotated log: a
+// compression extension, a sequence number, or a date
+var rotatedSuffixRe = regexp.MustCompile(`(\.(gz|xz|bz2|zst|lz4))$|([.-](\d{1,3}|\d{8}|\d{10}))$`)
+
+// logSnapshot is the size of every file under the log directory at one time
+type logSnapshot struct {
+	at    time.Time
+	dir   string
+	total int64
+	sizes map[string]int64
+}
+
+// logFile is one file in the largest and fastest growing lists
+type logFile struct {
+	Path               string  `json:"path"`
+	SizeBytes          int64   `json:"size_bytes"`
+	SizeHuman          string  `json:"size_human"`
+	GrowthBytes        *int64  `json:"growth_bytes,omitempty"`
+	GrowthBytesPerHour float64 `json:"growth_bytes_per_hour,omitempty"`
+}
+
+// logFamily is a log together with its rotated copies, e.g. syslog, syslog.1, syslog.2.gz
+type logFamily struct {
+	Name      string `json:"name"`
+	Files     int    `json:"files"`
+	SizeBytes int64  `json:"size_bytes"`
+	SizeHuman string `json:"size_human"`
+}
+
+// HandleGetLogGrowth reports what fills the log directory: journald's share, the largest
+// files and logs counting their rotated copies, and, from the second call on, how fast
+// each file grew since the previous call
+func (h *HandlerManager) HandleGetLogGrowth(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	limit := defaultLogGrowthLimit
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if l, ok := args["limit"].(float64); ok && l > 0 {
+			limit = min(int(l), maxLogGrowthLimit)
+		}
+	}
+
+	dir := h.cfg.LogDir
+	snap, unreadable, err := scanLogDir(ctx, dir)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read log directory %s: %v", dir, err)), nil
+	}
+
+	h.logGrowthMu.Lock()
+	prev := h.logGrowthPrev
+	h.logGrowthPrev = snap
+	h.logGrowthMu.Unlock()
+	if prev != nil && prev.dir != dir {
+		prev = nil
+	}
+
+	result := h.summarizeLogGrowth(snap, prev, limit)
+	result["path"] = dir
+	result["unreadable"] = unreadable
+
+	// The volatile journal lives outside the log directory
+	journal := result["journald"].(map[string]interface{})
+	if journal["files"] == 0 {
+		if runtime, _, err := scanLogDir(ctx, journaldRuntimeDir); err == nil && len(runtime.sizes) > 0 {
+			journal["path"] = journaldRuntimeDir
+			journal["files"] = len(runtime.sizes)
+			journal["size_bytes"] = runtime.total
+			journal["size_human"] = h.human.Bytes(uint64(runtime.total))
+			journal["volatile"] = true
+		}
+	}
+
+	if du, err := disk.UsageWithContext(ctx, dir); err == nil {
+		fsInfo := map[string]interface{}{
+			"total_bytes":  du.Total,
+			"free_bytes":   du.Free,
+			"free_human":   h.human.Bytes(du.Free),
+			"used_percent": round2(du.UsedPercent),
+		}
+		if du.Total > 0 {
+			fsInfo["logs_percent"] = round2(float64(snap.total) / float64(du.Total) * 100)
+		}
+		if rate, ok := result["growth_bytes_per_hour"].(float64); ok && rate > 0 {
+			fsInfo["hours_until_full"] = round2(float64(du.Free) / rate)
+		}
+		result["filesystem"] = fsInfo
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// scanLogDir records the size of every regular file under dir, keyed by its path relative
+// to dir. Entries that cannot be read are counted and skipped.
+func scanLogDir(ctx context.Context, dir string) (*logSnapshot, int, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, 0, err
+	}
+	snap := &logSnapshot{at: time.Now(), dir: dir, sizes: map[string]int64{}}
+	unreadable := 0
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			unreadable++
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			unreadable++
+			return nil
+		}
+		rel, _ := filepath.Rel(dir, path)
+		snap.sizes[filepath.ToSlash(rel)] = info.Size()
+		snap.total += info.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return snap, unreadable, nil
+}
+
+// summarizeLogGrowth lists the largest files and logs in snap and, with a previous
+// snapshot of the same directory, growth since then. A file that shrank was rotated or
+// truncated, so its whole current size counts as new.
+func (h *HandlerManager) summarizeLogGrowth(snap, prev *logSnapshot, limit int) map[string]interface{} {
+	var elapsed time.Duration
+	if prev != nil {
+		elapsed = snap.at.Sub(prev.at)
+	}
+
+	files := make([]logFile, 0, len(snap.sizes))
+	families := map[string]*logFamily{}
+	var journalFiles int
+	var journalSize int64
+	for _, path := range slices.Sorted(maps.Keys(snap.sizes)) {
+		size := snap.sizes[path]
+		f := logFile{Path: path, SizeBytes: size, SizeHuman: h.human.Bytes(uint64(size))}
+		if prev != nil {
+			growth := size
+			if before, ok := prev.sizes[path]; ok && before <= size {
+				growth = size - before
+			}
+			f.GrowthBytes = &growth
+			if elapsed > 0 {
+				f.GrowthBytesPerHour = round2(float64(growth) / elapsed.Hours())
+			}
+		}
+		files = append(files, f)
+
+		if path == "journal" || strings.HasPrefix(path, "journal/") {
+			journalFiles++
+			journalSize += size
+			continue
+		}
+		name := logFamilyName(path)
+		fam, ok := families[name]
+		if !ok {
+			fam = &logFamily{Name: name}
+			families[name] = fam
+		}
+		fam.Files++
+		fam.SizeBytes += size
+	}
+
+	largest := append([]logFile{}, files...)
+	sort.SliceStable(largest, func(i, j int) bool { return largest[i].SizeBytes > largest[j].SizeBytes })
+
+	logs := make([]logFamily, 0, len(families))
+	for _, fam := range families {
+		fam.SizeHuman = h.human.Bytes(uint64(fam.SizeBytes))
+		logs = append(logs, *fam)
+	}
+	sort.Slice(logs, func(i, j int) bool {
+		if logs[i].SizeBytes != logs[j].SizeBytes {
+			return logs[i].SizeBytes > logs[j].SizeBytes
+		}
+		return logs[i].Name < logs[j].Name
+	})
+
+	journal := map[string]interface{}{
+		"path":       filepath.Join(snap.dir, "journal"),
+		"files":      journalFiles,
+		"size_bytes": journalSize,
+		"size_human": h.human.Bytes(uint64(journalSize)),
+	}
+	if snap.total > 0 {
+		journal["share_percent"] = round2(float64(journalSize) / float64(snap.total) * 100)
+	}
+
+	result := map[string]interface{}{
+		"total_bytes":   snap.total,
+		"total_human":   h.human.Bytes(uint64(snap.total)),
+		"files":         len(snap.sizes),
+		"journald":      journal,
+		"largest_files": largest[:min(limit, len(largest))],
+		"largest_logs":  logs[:min(limit, len(logs))],
+		"timestamp":     snap.at.Unix(),
+	}
+	if prev == nil {
+		result["baseline"] = true
+		return result
+	}
+
+	growing := []logFile{}
+	for _, f := range files {
+		if *f.GrowthBytes > 0 {
+			growing = append(growing, f)
+		}
+	}
+	sort.SliceStable(growing, func(i, j int) bool { return *growing[i].GrowthBytes > *growing[j].GrowthBytes })
+	result["fastest_growing"] = growing[:min(limit, len(growing))]
+	result["since_seconds"] = int64(elapsed.Seconds())
+	result["growth_bytes"] = snap.total - prev.total
+	if elapsed > 0 {
+		result["growth_bytes_per_hour"] = round2(float64(snap.total-prev.total) / elapsed.Hours())
+	}
+	return result
+}
+
+// logFamilyName strips rotation suffixes from a log path, so syslog.2.gz and
+// syslog-20240101 both count towards syslog
+func logFamilyName(path string) string {
+	for {
+		trimmed := rotatedSuffixRe.ReplaceAllString(path, "")
+		if trimmed == path || trimmed == "" {
+			return path
+		}
+		path = trimmed
+	}
+}
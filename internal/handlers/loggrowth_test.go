@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestLogFamilyName(t *testing.T) {
+	for path, want := range map[string]string{
+		"syslog":                     "syslog",
+		"syslog.1":                   "syslog",
+		"syslog.2.gz":                "syslog",
+		"nginx/access.log.14.gz":     "nginx/access.log",
+		"messages-20240101":          "messages",
+		"apt/history.log.1.xz":       "apt/history.log",
+		"Xorg.0.log":                 "Xorg.0.log",
+		"journal/abc/system.journal": "journal/abc/system.journal",
+	} {
+		if got := logFamilyName(path); got != want {
+			t.Errorf("logFamilyName(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestHandleGetLogGrowth(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, size int) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("syslog", 300)
+	write("syslog.1", 200)
+	write("syslog.2.gz", 100)
+	write("kern.log", 500)
+	write("journal/machine/system.journal", 1000)
+
+	h := NewHandlerManager(&config.Config{LogDir: dir})
+	call := func() map[string]interface{} {
+		t.Helper()
+		res, err := h.HandleGetLogGrowth(context.Background(), mcp.CallToolRequest{})
+		checkToolResult(t, res, err, []string{"total_bytes", "journald", "largest_files", "largest_logs"})
+		var result map[string]interface{}
+		if err := json.Unmarshal([]byte(resultText(res)), &result); err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	first := call()
+	if first["baseline"] != true || first["total_bytes"] != float64(2100) {
+		t.Errorf("Unexpected first call: %v", first)
+	}
+	if journal := first["journald"].(map[string]interface{}); journal["size_bytes"] != float64(1000) || journal["files"] != float64(1) {
+		t.Errorf("Unexpected journald usage: %v", journal)
+	}
+	logs := first["largest_logs"].([]interface{})
+	if top := logs[0].(map[string]interface{}); top["name"] != "syslog" || top["files"] != float64(3) || top["size_bytes"] != float64(600) {
+		t.Errorf("Expected syslog and its rotations first, got %v", top)
+	}
+
+	// kern.log grows and syslog is rotated and starts over
+	write("kern.log", 800)
+	write("syslog", 50)
+	second := call()
+	if second["baseline"] != nil || second["growth_bytes"] != float64(50) {
+		t.Errorf("Unexpected second call: %v", second)
+	}
+	growing := second["fastest_growing"].([]interface{})
+	if len(growing) != 2 {
+		t.Fatalf("Expected 2 growing files, got %v", growing)
+	}
+	if top := growing[0].(map[string]interface{}); top["path"] != "kern.log" || top["growth_bytes"] != float64(300) {
+		t.Errorf("Expected kern.log to grow fastest, got %v", top)
+	}
+	if rotated := growing[1].(map[string]interface{}); rotated["path"] != "syslog" || rotated["growth_bytes"] != float64(50) {
+		t.Errorf("Expected the rotated syslog to count as new, got %v", rotated)
+	}
+
+	h.cfg.LogDir = filepath.Join(dir, "missing")
+	res, err := h.HandleGetLogGrowth(context.Background(), mcp.CallToolRequest{})
+	if err != nil || !res.IsError {
+		t.Errorf("Expected an error result for a missing directory, got %v, %v", res, err)
+	}
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// macThermal holds the thermal readings parsed from powermetrics output
+type macThermal struct {
+	CPUTempC         float64
+	HasCPUTemp       bool
+	GPUTempC         float64
+	HasGPUTemp       bool
+	FanRPM           []float64
+	ThermalPressure  string
+	HasThermalStatus bool
+}
+
+var (
+	powermetricsCPUTemp  = regexp.MustCompile(`(?m)^CPU die temperature:\s*([\d.]+)\s*C`)
+	powermetricsGPUTemp  = regexp.MustCompile(`(?m)^GPU die temperature:\s*([\d.]+)\s*C`)
+	powermetricsFan      = regexp.MustCompile(`(?m)^Fan:\s*([\d.]+)\s*rpm`)
+	powermetricsPressure = regexp.MustCompile(`(?m)^Current pressure level:\s*(\S+)`)
+)
+
+// readMacThermal samples SMC and thermal pressure data once via powermetrics.
+// powermetrics requires root, so it honors the sudo allowlist.
+func (h *HandlerManager) readMacThermal(ctx context.Context) (macThermal, error) {
+	out, err := h.privilegedCommand(ctx, "powermetrics", "--samplers", "smc,thermal", "-n", "1", "-i", "1").Output()
+	if err != nil {
+		// Apple Silicon has no smc sampler; fall back to thermal pressure only
+		out, err = h.privilegedCommand(ctx, "powermetrics", "--samplers", "thermal", "-n", "1", "-i", "1").Output()
+		if err != nil {
+			return macThermal{}, err
+		}
+	}
+	return parsePowermetrics(string(out)), nil
+}
+
+// parsePowermetrics extracts temperatures, fan speeds, and thermal pressure from powermetrics text output
+func parsePowermetrics(output string) macThermal {
+	var t macThermal
+	if m := powermetricsCPUTemp.FindStringSubmatch(output); m != nil {
+		if v, err := strconv.ParseFloat(m[1], 64); err == nil {
+			t.CPUTempC, t.HasCPUTemp = v, true
+		}
+	}
+	if m := powermetricsGPUTemp.FindStringSubmatch(output); m != nil {
+		if v, err := strconv.ParseFloat(m[1], 64); err == nil {
+			t.GPUTempC, t.HasGPUTemp = v, true
+		}
+	}
+	for _, m := range powermetricsFan.FindAllStringSubmatch(output, -1) {
+		if v, err := strconv.ParseFloat(m[1], 64); err == nil {
+			t.FanRPM = append(t.FanRPM, v)
+		}
+	}
+	if m := powermetricsPressure.FindStringSubmatch(output); m != nil {
+		t.ThermalPressure, t.HasThermalStatus = m[1], true
+	}
+	return t
+}
+
+// parseLaunchctlList parses the `"Key" = value;` lines printed by `launchctl list <label>`
+func parseLaunchctlList(output string) map[string]string {
+	values := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, `"`) || !strings.HasSuffix(line, ";") {
+			continue
+		}
+		parts := strings.SplitN(strings.TrimSuffix(line, ";"), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key := strings.Trim(strings.TrimSpace(parts[0]), `"`)
+		value := strings.Trim(strings.TrimSpace(parts[1]), `"`)
+		values[key] = value
+	}
+	return values
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import "testing"
+
+func TestParsePowermetrics(t *testing.T) {
+	output := `**** SMC sensors ****
+
+CPU Thermal level: 0
+GPU Thermal level: 0
+Fan: 1798.92 rpm
+CPU die temperature: 52.31 C
+GPU die temperature: 47.00 C
+
+**** Thermal pressure ****
+
+Current pressure level: Nominal
+`
+	got := parsePowermetrics(output)
+	if !got.HasCPUTemp || got.CPUTempC != 52.31 {
+		t.Errorf("Unexpected CPU temperature: %+v", got)
+	}
+	if !got.HasGPUTemp || got.GPUTempC != 47 {
+		t.Errorf("Unexpected GPU temperature: %+v", got)
+	}
+	if len(got.FanRPM) != 1 || got.FanRPM[0] != 1798.92 {
+		t.Errorf("Unexpected fan speeds: %v", got.FanRPM)
+	}
+	if got.ThermalPressure != "Nominal" {
+		t.Errorf("Unexpected thermal pressure: %q", got.ThermalPressure)
+	}
+
+	// Apple Silicon only reports thermal pressure
+	got = parsePowermetrics("Current pressure level: Moderate\n")
+	if got.HasCPUTemp || got.ThermalPressure != "Moderate" {
+		t.Errorf("Unexpected Apple Silicon parse: %+v", got)
+	}
+}
+
+func TestParseLaunchctlList(t *testing.T) {
+	output := `{
+	"LimitLoadToSessionType" = "System";
+	"Label" = "com.openssh.sshd";
+	"OnDemand" = true;
+	"LastExitStatus" = 0;
+	"PID" = 412;
+	"Program" = "/usr/libexec/sshd-keygen-wrapper";
+};`
+	got := parseLaunchctlList(output)
+	if got["Label"] != "com.openssh.sshd" || got["PID"] != "412" || got["LastExitStatus"] != "0" {
+		t.Errorf("Unexpected launchctl parse: %v", got)
+	}
+}
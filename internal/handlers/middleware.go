@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"sysmetrics-mcp/internal/cache"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// toolMiddleware is one cross-cutting concern applied to every tool handler
+type toolMiddleware struct {
+	name string
+	// selfTest marks stages self_test also runs through; it skips logging and auditing so
+	// a test run does not flood either, and bypasses limits and caching so every tool runs
+	selfTest bool
+	wrap     func(tool mcp.Tool, next server.ToolHandlerFunc) server.ToolHandlerFunc
+}
+
+// toolTraits say how the cross-cutting concerns treat a tool. They are declared once, in
+// the tool's addTool call, and caching, timeouts, the event journal, self_test, and the gRPC
+// API all derive their special cases from them.
+type toolTraits struct {
+	// action says how the tool changes the host. Each call is journaled, and the tool is
+	// never cached, timed out, called by self_test, or served over gRPC.
+	action string
+	// sideEffect says what the tool leaves behind on the server, such as a file or load.
+	// The tool is never cached, called by self_test, or served over gRPC.
+	sideEffect string
+	// live tools report on the server itself, so a cached result would be stale
+	live bool
+	// longRunning tools bound their own run time, so --tool-timeout leaves them alone
+	longRunning bool
+	// noSelfTest says why self_test skips a tool that is otherwise safe to call
+	noSelfTest string
+}
+
+// toolOption declares one of a tool's traits in addTool
+type toolOption func(*toolTraits)
+
+// hostAction declares a tool that changes the host, described by what
+func hostAction(what string) toolOption {
+	return func(t *toolTraits) { t.action = what }
+}
+
+// sideEffect declares a tool that leaves what behind on the server
+func sideEffect(what string) toolOption {
+	return func(t *toolTraits) { t.sideEffect = what }
+}
+
+// liveState declares a tool that reports on the server itself
+func liveState(t *toolTraits) { t.live = true }
+
+// longRunning declares a tool that bounds its own run time
+func longRunning(t *toolTraits) { t.longRunning = true }
+
+// skipSelfTest declares a tool self_test does not call, with the reason
+func skipSelfTest(why string) toolOption {
+	return func(t *toolTraits) { t.noSelfTest = why }
+}
+
+// readOnly reports whether calling the tool leaves the host and server as they were
+func (t toolTraits) readOnly() bool {
+	return t.action == "" && t.sideEffect == ""
+}
+
+// cacheable reports whether a recent result may stand in for a new call
+func (t toolTraits) cacheable() bool {
+	return t.readOnly() && !t.live
+}
+
+// timed reports whether --tool-timeout applies. Abandoning an action would hide whether it
+// acted.
+func (t toolTraits) timed() bool {
+	return t.action == "" && !t.longRunning
+}
+
+// selfTestSkipReason returns why self_test does not call the tool, or "" if it does
+func (t toolTraits) selfTestSkipReason() string {
+	switch {
+	case t.action != "":
+		return t.action
+	case t.sideEffect != "":
+		return t.sideEffect
+	}
+	return t.noSelfTest
+}
+
+// toolMiddleware returns the enabled middleware, outermost first. Every tool call passes
+// through this chain, so a new cross-cutting concern is added here rather than in handlers.
+// There is no authentication stage: the stdio transport's only client is the process that
+// started the server.
+func (h *HandlerManager) toolMiddleware() []toolMiddleware {
+	chain := []toolMiddleware{
+		// Logging and auditing are outermost so rate-limited and cached calls are recorded too
+		{name: "logging", wrap: func(tool mcp.Tool, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+			return h.logTool(tool.Name, next)
+		}},
+		{name: "audit", wrap: func(tool mcp.Tool, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+			return h.auditTool(tool.Name, next)
+		}},
+	}
+	if h.cfg.RateLimit > 0 {
+		chain = append(chain, toolMiddleware{name: "rate_limit", wrap: h.rateLimitTool})
+	}
+	if h.cachingEnabled() {
+		chain = append(chain, toolMiddleware{name: "cache", wrap: h.cacheTool})
+	}
+	// Usage statistics sit inside the cache so they time the collector, not a cache hit
+	if h.usageEnabled() {
+		chain = append(chain, toolMiddleware{name: "usage", wrap: h.usageTool})
+	}
+	// The timeout sits inside usage statistics so a timed-out call is counted as the
+	// error it returns
+	if h.cfg.ToolTimeout > 0 {
+		chain = append(chain, toolMiddleware{name: "timeout", wrap: h.timeoutTool})
+	}
+	chain = append(chain,
+		toolMiddleware{name: "recover", selfTest: true, wrap: func(tool mcp.Tool, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+			return h.recoverTool(tool.Name, next)
+		}},
+		// Formatting runs outside the budget so lists are trimmed before they are rendered
+		toolMiddleware{name: "format", wrap: h.formatTool},
+		toolMiddleware{name: "budget", selfTest: true, wrap: h.budgetTool},
+	)
+	// Redaction runs inside the budget so a trimmed result is measured after rewriting,
+	// and before the cache so only redacted results are kept
+	if h.redactor != nil {
+		chain = append(chain, toolMiddleware{name: "redact", selfTest: true, wrap: h.redactTool})
+	}
+	return chain
+}
+
+// chainTool applies middleware to handler so the first entry runs first
+func chainTool(tool mcp.Tool, handler server.ToolHandlerFunc, middleware []toolMiddleware) server.ToolHandlerFunc {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i].wrap(tool, handler)
+	}
+	return handler
+}
+
+// selfTestMiddleware returns the stages of middleware that self_test runs through
+func selfTestMiddleware(middleware []toolMiddleware) []toolMiddleware {
+	var stages []toolMiddleware
+	for _, m := range middleware {
+		if m.selfTest {
+			stages = append(stages, m)
+		}
+	}
+	return stages
+}
+
+// middlewareNames lists the stages of middleware in call order
+func middlewareNames(middleware []toolMiddleware) []string {
+	names := make([]string, 0, len(middleware))
+	for _, m := range middleware {
+		names = append(names, m.name)
+	}
+	return names
+}
+
+// rateLimitTool rejects calls beyond --rate-limit per tool in any one-minute window, so a
+// looping client cannot keep a small board busy with expensive scans
+func (h *HandlerManager) rateLimitTool(tool mcp.Tool, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	var mu sync.Mutex
+	var calls []time.Time
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		now := time.Now()
+		mu.Lock()
+		cutoff := now.Add(-time.Minute)
+		kept := calls[:0]
+		for _, t := range calls {
+			if t.After(cutoff) {
+				kept = append(kept, t)
+			}
+		}
+		calls = kept
+		if len(calls) >= h.cfg.RateLimit {
+			retry := calls[0].Add(time.Minute).Sub(now).Round(time.Second)
+			mu.Unlock()
+			h.rateLimited.Add(1)
+			return mcp.NewToolResultError(fmt.Sprintf("Rate limit exceeded for %s: %d calls per minute (--rate-limit); retry in %s", tool.Name, h.cfg.RateLimit, retry)), nil
+		}
+		calls = append(calls, now)
+		mu.Unlock()
+		return next(ctx, request)
+	}
+}
+
+// cacheTTL returns how long results of tool are reused: its --cache-tool-ttls entry if it
+// has one, else --cache-ttl
+func (h *HandlerManager) cacheTTL(tool string) time.Duration {
+	if ttl, ok := h.cfg.CacheToolTTLs[tool]; ok {
+		return ttl
+	}
+	return h.cfg.CacheTTL
+}
+
+// cachingEnabled reports whether any tool has a cache TTL
+func (h *HandlerManager) cachingEnabled() bool {
+	if h.cfg.CacheTTL > 0 {
+		return true
+	}
+	for _, ttl := range h.cfg.CacheToolTTLs {
+		if ttl > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheTool returns a recent result for identical arguments instead of running the tool
+// again, and lets identical calls that arrive together share one run, so a burst of calls
+// scans /proc once. Errors are never cached, and state-changing tools are passed through.
+func (h *HandlerManager) cacheTool(tool mcp.Tool, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	ttl := h.cacheTTL(tool.Name)
+	if !h.toolTraits[tool.Name].cacheable() || ttl <= 0 {
+		return next
+	}
+	results := cache.New[*mcp.CallToolResult]()
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		key, err := json.Marshal(request.Params.Arguments)
+		if err != nil {
+			return next(ctx, request)
+		}
+		result, shared, err := results.Do(ctx, string(key), ttl, func() (*mcp.CallToolResult, bool, error) {
+			result, err := next(ctx, request)
+			return result, err == nil && result != nil && !result.IsError, err
+		})
+		switch {
+		case shared && err == nil:
+			h.cacheHits.Add(1)
+		case shared && ctx.Err() != nil:
+			// The client gave up while waiting on another caller's run
+			return mcp.NewToolResultError(fmt.Sprintf("%s was cancelled: %v", tool.Name, ctx.Err())), nil
+		}
+		return result, err
+	}
+}
+
+// timeoutTool ends a call that runs longer than --tool-timeout with an error. The
+// handler's context is cancelled so collectors that honor it stop early; one blocked in
+// a call that cannot be interrupted, such as a stat on a hung mount, finishes in the
+// background. Action tools are exempt, as abandoning one would hide whether it acted.
+func (h *HandlerManager) timeoutTool(tool mcp.Tool, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	if !h.toolTraits[tool.Name].timed() {
+		return next
+	}
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx, cancel := context.WithTimeout(ctx, h.cfg.ToolTimeout)
+		defer cancel()
+
+		type outcome struct {
+			result *mcp.CallToolResult
+			err    error
+		}
+		done := make(chan outcome, 1)
+		go func() {
+			result, err := next(ctx, request)
+			done <- outcome{result, err}
+		}()
+		select {
+		case o := <-done:
+			return o.result, o.err
+		case <-ctx.Done():
+			if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return mcp.NewToolResultError(fmt.Sprintf("%s was cancelled: %v", tool.Name, ctx.Err())), nil
+			}
+			h.timedOut.Add(1)
+			return mcp.NewToolResultError(fmt.Sprintf("%s did not finish within %s (--tool-timeout)", tool.Name, h.cfg.ToolTimeout)), nil
+		}
+	}
+}
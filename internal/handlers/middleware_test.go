@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"sysmetrics-mcp/internal/config"
+	"sysmetrics-mcp/internal/usage"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestChainToolOrder(t *testing.T) {
+	var order []string
+	stage := func(name string, selfTest bool) toolMiddleware {
+		return toolMiddleware{name: name, selfTest: selfTest, wrap: func(tool mcp.Tool, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+			return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				order = append(order, name)
+				return next(ctx, request)
+			}
+		}}
+	}
+	middleware := []toolMiddleware{stage("outer", false), stage("middle", true), stage("inner", true)}
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		order = append(order, "handler")
+		return mcp.NewToolResultText("{}"), nil
+	}
+
+	tool := mcp.NewTool("test_tool")
+	if _, err := chainTool(tool, handler, middleware)(context.Background(), mcp.CallToolRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(order, ","); got != "outer,middle,inner,handler" {
+		t.Errorf("Call order = %s", got)
+	}
+
+	order = nil
+	if _, err := chainTool(tool, handler, selfTestMiddleware(middleware))(context.Background(), mcp.CallToolRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(order, ","); got != "middle,inner,handler" {
+		t.Errorf("self_test call order = %s", got)
+	}
+}
+
+func TestToolMiddlewareStages(t *testing.T) {
+	h := NewHandlerManager(&config.Config{})
+	h.usageStats = usage.NewTracker("")
+	if got := strings.Join(middlewareNames(h.toolMiddleware()), ","); got != "logging,audit,recover,format,budget" {
+		t.Errorf("Default middleware = %s", got)
+	}
+
+	h = NewHandlerManager(&config.Config{RateLimit: 10, CacheTTL: time.Minute})
+	h.usageStats = usage.NewTracker(filepath.Join(t.TempDir(), "usage.json"))
+	if got := strings.Join(middlewareNames(h.toolMiddleware()), ","); got != "logging,audit,rate_limit,cache,usage,recover,format,budget" {
+		t.Errorf("Middleware with rate limit, cache, and usage statistics = %s", got)
+	}
+
+	h = NewHandlerManager(&config.Config{ToolTimeout: time.Minute})
+	h.usageStats = usage.NewTracker("")
+	if got := strings.Join(middlewareNames(h.toolMiddleware()), ","); got != "logging,audit,timeout,recover,format,budget" {
+		t.Errorf("Middleware with a tool timeout = %s", got)
+	}
+	if got := strings.Join(middlewareNames(selfTestMiddleware(h.toolMiddleware())), ","); got != "recover,budget" {
+		t.Errorf("self_test middleware = %s", got)
+	}
+
+	h = NewHandlerManager(&config.Config{Redact: []string{config.RedactIP}, DisableTools: []string{"get_usage_stats"}})
+	if got := strings.Join(middlewareNames(h.toolMiddleware()), ","); got != "logging,audit,recover,format,budget,redact" {
+		t.Errorf("Middleware with redaction = %s", got)
+	}
+}
+
+func TestRateLimitTool(t *testing.T) {
+	h := NewHandlerManager(&config.Config{RateLimit: 2})
+	calls := 0
+	limited := h.rateLimitTool(mcp.NewTool("test_tool"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls++
+		return mcp.NewToolResultText("{}"), nil
+	})
+
+	for i := 0; i < 3; i++ {
+		res, err := limited(context.Background(), mcp.CallToolRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if wantErr := i == 2; res.IsError != wantErr {
+			t.Errorf("Call %d: IsError = %v, want %v (%s)", i, res.IsError, wantErr, resultText(res))
+		}
+	}
+	if calls != 2 {
+		t.Errorf("Handler ran %d times, want 2", calls)
+	}
+	if h.rateLimited.Load() != 1 {
+		t.Errorf("rateLimited = %d, want 1", h.rateLimited.Load())
+	}
+}
+
+func TestCacheTool(t *testing.T) {
+	h := NewHandlerManager(&config.Config{CacheTTL: time.Minute})
+	calls := 0
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls++
+		if args, _ := request.Params.Arguments.(map[string]interface{}); args["fail"] == true {
+			return mcp.NewToolResultError("Failed"), nil
+		}
+		return mcp.NewToolResultText("{}"), nil
+	}
+	request := func(args map[string]interface{}) mcp.CallToolRequest {
+		var r mcp.CallToolRequest
+		r.Params.Arguments = args
+		return r
+	}
+
+	cached := h.cacheTool(mcp.NewTool("get_cpu_info"), handler)
+	for _, args := range []map[string]interface{}{
+		{"per_cpu": true}, {"per_cpu": true}, {"per_cpu": false}, {"fail": true}, {"fail": true},
+	} {
+		if _, err := cached(context.Background(), request(args)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The repeated per_cpu call is served from the cache; errors are not cached
+	if calls != 4 {
+		t.Errorf("Handler ran %d times, want 4", calls)
+	}
+	if h.cacheHits.Load() != 1 {
+		t.Errorf("cacheHits = %d, want 1", h.cacheHits.Load())
+	}
+
+	calls = 0
+	h.toolTraits["manage_process"] = toolTraits{action: "signals processes"}
+	uncached := h.cacheTool(mcp.NewTool("manage_process"), handler)
+	for i := 0; i < 2; i++ {
+		if _, err := uncached(context.Background(), request(nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("State-changing tool ran %d times, want 2", calls)
+	}
+}
+
+func TestCacheToolPerToolTTL(t *testing.T) {
+	h := NewHandlerManager(&config.Config{CacheToolTTLs: map[string]time.Duration{
+		"get_process_list":    time.Minute,
+		"get_container_stats": 0,
+	}})
+	if !h.cachingEnabled() {
+		t.Fatal("Expected a per-tool TTL to enable the cache stage")
+	}
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	slow := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls.Add(1)
+		<-release
+		return mcp.NewToolResultText("{}"), nil
+	}
+
+	// A burst of identical calls runs the collector once
+	cached := h.cacheTool(mcp.NewTool("get_process_list"), slow)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if res, err := cached(context.Background(), mcp.CallToolRequest{}); err != nil || res == nil || res.IsError {
+				t.Errorf("Unexpected result: %v, %v", res, err)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if calls.Load() != 1 || h.cacheHits.Load() != 3 {
+		t.Errorf("Handler ran %d times with %d cache hits, want 1 and 3", calls.Load(), h.cacheHits.Load())
+	}
+
+	// A zero TTL and tools without an entry or --cache-ttl are not cached
+	calls.Store(0)
+	for _, name := range []string{"get_container_stats", "get_cpu_info"} {
+		uncached := h.cacheTool(mcp.NewTool(name), slow)
+		for i := 0; i < 2; i++ {
+			_, _ = uncached(context.Background(), mcp.CallToolRequest{})
+		}
+	}
+	if calls.Load() != 4 {
+		t.Errorf("Uncached tools ran %d times, want 4", calls.Load())
+	}
+}
+
+func TestTimeoutTool(t *testing.T) {
+	h := NewHandlerManager(&config.Config{ToolTimeout: 50 * time.Millisecond})
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	// A handler that ignores its context, like one blocked on a hung mount
+	hung := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		<-release
+		return mcp.NewToolResultText("{}"), nil
+	}
+
+	start := time.Now()
+	res, err := h.timeoutTool(mcp.NewTool("get_disk_metrics"), hung)(context.Background(), mcp.CallToolRequest{})
+	if err != nil || !res.IsError || !strings.Contains(resultText(res), "--tool-timeout") {
+		t.Fatalf("Expected a timeout error, got %v, %v", res, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Timeout took %s", elapsed)
+	}
+	if h.timedOut.Load() != 1 {
+		t.Errorf("timedOut = %d, want 1", h.timedOut.Load())
+	}
+
+	fast := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			return mcp.NewToolResultError("No deadline"), nil
+		}
+		return mcp.NewToolResultText("{}"), nil
+	}
+	if res, _ := h.timeoutTool(mcp.NewTool("get_cpu_metrics"), fast)(context.Background(), mcp.CallToolRequest{}); res.IsError {
+		t.Errorf("Expected the handler to see the deadline: %s", resultText(res))
+	}
+	// Action tools are never abandoned mid-action
+	h.toolTraits["control_service"] = toolTraits{action: "starts and stops services"}
+	if res, _ := h.timeoutTool(mcp.NewTool("control_service"), fast)(context.Background(), mcp.CallToolRequest{}); !res.IsError {
+		t.Error("Expected action tools to run without the tool timeout")
+	}
+}
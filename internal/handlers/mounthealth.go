@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+// mountsPath lists the mounted filesystems with their current options (Linux)
+var mountsPath = "/proc/self/mounts"
+
+// fstabPath holds the configured mount options, which tell a deliberate read-only mount
+// from one the kernel remounted after errors
+var fstabPath = "/etc/fstab"
+
+// ext4SysfsPath has one directory per mounted ext4 device with its error counters
+var ext4SysfsPath = "/sys/fs/ext4"
+
+// statMount queries a mounted filesystem; network mounts are probed with it under a timeout
+var statMount = func(path string) error {
+	_, err := disk.Usage(path)
+	return err
+}
+
+// mountProbeTimeout bounds how long a network mount may take to answer before it is
+// reported unresponsive
+const mountProbeTimeout = 2 * time.Second
+
+// Mount problems
+const (
+	mountReadOnly     = "read_only"
+	mountStale        = "stale"
+	mountUnresponsive = "unresponsive"
+)
+
+// diskFilesystems are local filesystems that are normally mounted read-write
+var diskFilesystems = map[string]bool{
+	"ext2": true, "ext3": true, "ext4": true, "btrfs": true, "xfs": true, "f2fs": true,
+	"vfat": true, "exfat": true, "ntfs": true, "ntfs3": true,
+}
+
+// networkFilesystems can hang when their server goes away
+var networkFilesystems = map[string]bool{
+	"nfs": true, "nfs4": true, "cifs": true, "smb3": true, "smbfs": true,
+}
+
+// errMountUnresponsive reports a network mount that did not answer within the timeout
+var errMountUnresponsive = errors.New("did not respond")
+
+// mountEntry is one line of /proc/self/mounts or /etc/fstab
+type mountEntry struct {
+	Device     string
+	MountPoint string
+	Fstype     string
+	Options    []string
+}
+
+// mountProblem is a filesystem that the kernel remounted read-only, or a network mount
+// whose server does not answer
+type mountProblem struct {
+	MountPoint string `json:"mount_point"`
+	Device     string `json:"device"`
+	Fstype     string `json:"fstype"`
+	Problem    string `json:"problem"`
+	Detail     string `json:"detail"`
+}
+
+// checkMounts finds read-only remounts and stale or unresponsive network mounts. It
+// returns nothing where the mount table cannot be read (non-Linux hosts).
+func (h *HandlerManager) checkMounts(ctx context.Context) []mountProblem {
+	mounts, err := readMountTable(mountsPath)
+	if err != nil {
+		return nil
+	}
+	configured := map[string]mountEntry{}
+	if fstab, err := readMountTable(fstabPath); err == nil {
+		for _, m := range fstab {
+			configured[m.MountPoint] = m
+		}
+	}
+
+	var problems []mountProblem
+	for _, m := range mounts {
+		problem := mountProblem{MountPoint: m.MountPoint, Device: m.Device, Fstype: m.Fstype}
+		switch {
+		case networkFilesystems[m.Fstype]:
+			err := h.probeMount(ctx, m.MountPoint)
+			switch {
+			case err == nil:
+				continue
+			case errors.Is(err, errMountUnresponsive):
+				problem.Problem = mountUnresponsive
+				problem.Detail = fmt.Sprintf("No answer from %s within %s", m.Device, mountProbeTimeout)
+			case errors.Is(err, syscall.ESTALE):
+				problem.Problem = mountStale
+				problem.Detail = fmt.Sprintf("Stale file handle from %s; remount it", m.Device)
+			default:
+				problem.Problem = mountUnresponsive
+				problem.Detail = err.Error()
+			}
+
+		case diskFilesystems[m.Fstype] && contains(m.Options, "ro"):
+			errorsCount := ext4Errors(m)
+			fstab, listed := configured[m.MountPoint]
+			if errorsCount == 0 && (!listed || contains(fstab.Options, "ro")) {
+				// Read-only by configuration, or not configured at all (e.g. a read-only root overlay)
+				continue
+			}
+			problem.Problem = mountReadOnly
+			problem.Detail = "Mounted read-only although configured read-write; the kernel likely remounted it after I/O errors"
+			if errorsCount > 0 {
+				problem.Detail = fmt.Sprintf("Mounted read-only with %d filesystem errors recorded; the storage may be failing", errorsCount)
+			}
+
+		default:
+			continue
+		}
+		problems = append(problems, problem)
+	}
+	return problems
+}
+
+// probeMount stats a network mount without letting a hung server block the caller. A
+// probe that never returns keeps the mount marked as hung, so later calls do not pile up
+// blocked goroutines.
+func (h *HandlerManager) probeMount(ctx context.Context, mountPoint string) error {
+	if _, hung := h.hungMounts.LoadOrStore(mountPoint, true); hung {
+		return errMountUnresponsive
+	}
+	done := make(chan error, 1)
+	stat := statMount
+	go func() {
+		err := stat(mountPoint)
+		h.hungMounts.Delete(mountPoint)
+		done <- err
+	}()
+
+	timer := time.NewTimer(mountProbeTimeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return errMountUnresponsive
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ext4Errors returns the error count the kernel recorded for an ext4 filesystem, or 0
+func ext4Errors(m mountEntry) int {
+	if m.Fstype != "ext4" || !strings.HasPrefix(m.Device, "/dev/") {
+		return 0
+	}
+	dev := filepath.Base(m.Device)
+	if resolved, err := filepath.EvalSymlinks(m.Device); err == nil {
+		dev = filepath.Base(resolved)
+	}
+	data, err := os.ReadFile(filepath.Join(ext4SysfsPath, dev, "errors_count"))
+	if err != nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	return n
+}
+
+// readMountTable parses a mounts or fstab file, skipping comments
+func readMountTable(path string) ([]mountEntry, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: fixed system paths, overridden only in tests
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []mountEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		entries = append(entries, mountEntry{
+			Device:     unescapeMountField(fields[0]),
+			MountPoint: unescapeMountField(fields[1]),
+			Fstype:     fields[2],
+			Options:    strings.Split(fields[3], ","),
+		})
+	}
+	return entries, scanner.Err()
+}
+
+// unescapeMountField decodes the octal escapes (\040 for a space) used in mount tables
+func unescapeMountField(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+4 <= len(s) {
+			if n, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// mountProblemWarnings describes mount problems for get_system_health
+func mountProblemWarnings(problems []mountProblem) []string {
+	var warnings []string
+	for _, p := range problems {
+		switch p.Problem {
+		case mountReadOnly:
+			warnings = append(warnings, fmt.Sprintf("%s (%s) was remounted read-only", p.MountPoint, p.Device))
+		case mountStale:
+			warnings = append(warnings, fmt.Sprintf("%s (%s) is a stale %s mount", p.MountPoint, p.Device, p.Fstype))
+		default:
+			warnings = append(warnings, fmt.Sprintf("%s (%s) is an unresponsive %s mount", p.MountPoint, p.Device, p.Fstype))
+		}
+	}
+	return warnings
+}
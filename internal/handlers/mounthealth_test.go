@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestCheckMounts(t *testing.T) {
+	dir := t.TempDir()
+	origMounts, origFstab, origSysfs, origStat := mountsPath, fstabPath, ext4SysfsPath, statMount
+	defer func() { mountsPath, fstabPath, ext4SysfsPath, statMount = origMounts, origFstab, origSysfs, origStat }()
+	mountsPath = filepath.Join(dir, "mounts")
+	fstabPath = filepath.Join(dir, "fstab")
+	ext4SysfsPath = filepath.Join(dir, "ext4")
+
+	write := func(path, data string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(mountsPath, `/dev/mmcblk0p2 / ext4 ro,relatime,errors=remount-ro 0 0
+/dev/mmcblk0p1 /boot/firmware vfat ro,relatime 0 0
+/dev/sda1 /mnt/usb\040disk ext4 ro,relatime 0 0
+/dev/sdb1 /srv ext4 rw,relatime 0 0
+nas:/export/media /mnt/media nfs4 rw,hard 0 0
+nas:/export/old /mnt/old nfs4 rw,hard 0 0
+//nas/backup /mnt/backup cifs rw 0 0
+proc /proc proc rw 0 0
+`)
+	write(fstabPath, `# <file system> <mount point> <type> <options> <dump> <pass>
+PARTUUID=1-02 / ext4 defaults,noatime 0 1
+PARTUUID=1-01 /boot/firmware vfat ro,defaults 0 2
+`)
+	write(filepath.Join(ext4SysfsPath, "sda1", "errors_count"), "3\n")
+
+	statMount = func(path string) error {
+		switch path {
+		case "/mnt/old":
+			return &os.PathError{Op: "statfs", Path: path, Err: syscall.ESTALE}
+		case "/mnt/backup":
+			time.Sleep(mountProbeTimeout + 500*time.Millisecond)
+		}
+		return nil
+	}
+
+	h := NewHandlerManager(&config.Config{})
+	problems := h.checkMounts(context.Background())
+	got := map[string]string{}
+	for _, p := range problems {
+		got[p.MountPoint] = p.Problem
+	}
+	want := map[string]string{
+		"/":             mountReadOnly,
+		"/mnt/usb disk": mountReadOnly,
+		"/mnt/old":      mountStale,
+		"/mnt/backup":   mountUnresponsive,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("checkMounts() = %+v, want %v", problems, want)
+	}
+	for mp, problem := range want {
+		if got[mp] != problem {
+			t.Errorf("%s: problem = %q, want %q", mp, got[mp], problem)
+		}
+	}
+
+	// The hung probe is still running, so the next check answers at once
+	start := time.Now()
+	if err := h.probeMount(context.Background(), "/mnt/backup"); err != errMountUnresponsive || time.Since(start) > time.Second {
+		t.Errorf("Expected a hung mount to be reported without a new probe, got %v after %s", err, time.Since(start))
+	}
+
+	// get_system_health escalates to critical
+	res, err := h.HandleGetSystemHealth(context.Background(), mcp.CallToolRequest{})
+	checkToolResult(t, res, err, []string{"status", "mount_problems"})
+	var health struct {
+		Status   string   `json:"status"`
+		Warnings []string `json:"warnings"`
+	}
+	if err := json.Unmarshal([]byte(resultText(res)), &health); err != nil {
+		t.Fatal(err)
+	}
+	if health.Status != statusCritical || !strings.Contains(strings.Join(health.Warnings, "\n"), "/mnt/old (nas:/export/old) is a stale nfs4 mount") {
+		t.Errorf("Expected a critical status with mount warnings, got %+v", health)
+	}
+}
+
+func TestUnescapeMountField(t *testing.T) {
+	for in, want := range map[string]string{
+		`/mnt/usb\040disk`: "/mnt/usb disk",
+		`/plain`:           "/plain",
+		`/tab\011x`:        "/tab\tx",
+		`/trailing\04`:     `/trailing\04`,
+	} {
+		if got := unescapeMountField(in); got != want {
+			t.Errorf("unescapeMountField(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// oomTimeout bounds reading the kernel log for OOM kills
+const oomTimeout = 15 * time.Second
+
+// get_oom_events defaults
+const (
+	defaultOOMSince = 7 * 24 * time.Hour
+	defaultOOMLimit = 20
+	maxOOMLimit     = 100
+)
+
+// oomRepeatThreshold is how many kills of the same process in the window are worth a warning
+const oomRepeatThreshold = 3
+
+// Who killed the process
+const (
+	oomKillerKernel = "kernel"
+	oomKillerCgroup = "cgroup"
+	oomKillerOomd   = "systemd-oomd"
+)
+
+// Kernel OOM report lines, in the order the kernel logs them
+var (
+	// "python3 invoked oom-killer: gfp_mask=0x100cca(GFP_HIGHUSER_MOVABLE), order=0, oom_score_adj=0"
+	oomInvokedRe = regexp.MustCompile(`^(.+?) invoked oom-killer:`)
+	// Memory cgroup limit: "memory: usage 524288kB, limit 524288kB, failcnt 42"
+	oomMemcgUsageRe = regexp.MustCompile(`^memory: usage (\d+)kB, limit (\d+)kB`)
+	// Mem-Info page counts; the per-zone "free:1234kB" lines are skipped by requiring no unit
+	oomFreePagesRe = regexp.MustCompile(`(?:^|\s)free:(\d+)\s+free_pcp:`)
+	oomRAMPagesRe  = regexp.MustCompile(`^(\d+) pages RAM`)
+	oomSwapRe      = regexp.MustCompile(`^(Free|Total) swap\s*=\s*(\d+)kB`)
+	// Kernels before 4.19 logged the badness score before killing:
+	// "Out of memory: Kill process 1234 (python3) score 912 or sacrifice child"
+	oomScoreRe = regexp.MustCompile(`Kill process (\d+) \(.*\) score (\d+)`)
+	// "Out of memory: Killed process 1234 (python3) total-vm:123456kB, anon-rss:65432kB,
+	// file-rss:0kB, shmem-rss:0kB, UID:1000 pgtables:256kB oom_score_adj:0"
+	oomKilledRe      = regexp.MustCompile(`^(?:(Memory cgroup out of memory|Out of memory[^:]*): )?Killed process (\d+) \((.*)\) total-vm:(\d+)kB, anon-rss:(\d+)kB, file-rss:(\d+)kB(?:, shmem-rss:(\d+)kB)?`)
+	oomUIDRe         = regexp.MustCompile(`UID:(\d+)`)
+	oomScoreAdjRe    = regexp.MustCompile(`oom_score_adj:(-?\d+)`)
+	oomdKilledRe     = regexp.MustCompile(`^Killed (\S+) due to (.+)$`)
+	oomConstraintKey = "oom-kill:"
+)
+
+// oomVictim is the process an OOM killer chose
+type oomVictim struct {
+	PID           int    `json:"pid"`
+	Name          string `json:"name"`
+	UID           *int   `json:"uid,omitempty"`
+	Score         *int   `json:"score,omitempty"`
+	OOMScoreAdj   *int   `json:"oom_score_adj,omitempty"`
+	TotalVMBytes  uint64 `json:"total_vm_bytes"`
+	AnonRSSBytes  uint64 `json:"anon_rss_bytes"`
+	FileRSSBytes  uint64 `json:"file_rss_bytes"`
+	ShmemRSSBytes uint64 `json:"shmem_rss_bytes"`
+	RSSBytes      uint64 `json:"rss_bytes"`
+	RSSHuman      string `json:"rss_human"`
+}
+
+// oomMemory is the memory state the kernel logged with the kill
+type oomMemory struct {
+	TotalBytes       *uint64 `json:"total_bytes,omitempty"`
+	FreeBytes        *uint64 `json:"free_bytes,omitempty"`
+	SwapTotalBytes   *uint64 `json:"swap_total_bytes,omitempty"`
+	SwapFreeBytes    *uint64 `json:"swap_free_bytes,omitempty"`
+	CgroupUsageBytes *uint64 `json:"cgroup_usage_bytes,omitempty"`
+	CgroupLimitBytes *uint64 `json:"cgroup_limit_bytes,omitempty"`
+}
+
+// oomEvent is one OOM kill
+type oomEvent struct {
+	Time       string     `json:"time,omitempty"`
+	Killer     string     `json:"killer"`
+	Constraint string     `json:"constraint,omitempty"`
+	Cgroup     string     `json:"cgroup,omitempty"`
+	Unit       string     `json:"unit,omitempty"`
+	InvokedBy  string     `json:"invoked_by,omitempty"`
+	Victim     *oomVictim `json:"victim,omitempty"`
+	Memory     *oomMemory `json:"memory,omitempty"`
+	Reason     string     `json:"reason,omitempty"`
+	Message    string     `json:"message"`
+}
+
+// HandleGetOOMEvents returns recent OOM kills from the kernel log with the victim, its
+// memory use, and the memory state at the time
+func (h *HandlerManager) HandleGetOOMEvents(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	since := defaultOOMSince
+	limit := defaultOOMLimit
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if s, ok := args["since"].(string); ok && s != "" {
+			d, err := parseHistoryDuration(s)
+			if err != nil || d <= 0 {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid since: %q (use a duration such as 30m, 6h, or 7d)", s)), nil
+			}
+			since = d
+		}
+		if l, ok := args["limit"].(float64); ok && l > 0 {
+			limit = min(int(l), maxOOMLimit)
+		}
+	}
+	start := time.Now().Add(-since)
+
+	ctx, cancel := context.WithTimeout(ctx, oomTimeout)
+	defer cancel()
+	entries, source, err := h.readOOMLog(ctx, start)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read the kernel log: %v (run the server as root, in the systemd-journal group, or with kernel.dmesg_restrict=0)", err)), nil
+	}
+
+	events := []oomEvent{}
+	for _, e := range parseOOMEvents(entries, uint64(os.Getpagesize())) {
+		if t, err := time.Parse(time.RFC3339, e.Time); err == nil && t.Before(start) {
+			continue
+		}
+		if e.Victim != nil {
+			e.Victim.RSSHuman = h.human.Bytes(e.Victim.RSSBytes)
+		}
+		events = append(events, e)
+	}
+	// Newest first
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time > events[j].Time })
+
+	byProcess := map[string]int{}
+	for _, e := range events {
+		switch {
+		case e.Victim != nil:
+			byProcess[e.Victim.Name]++
+		case e.Unit != "":
+			byProcess[e.Unit]++
+		}
+	}
+	warnings := []string{}
+	names := make([]string, 0, len(byProcess))
+	for name := range byProcess {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if n := byProcess[name]; n >= oomRepeatThreshold {
+			warnings = append(warnings, fmt.Sprintf("%s was killed %d times; it may be leaking memory or need a higher limit", name, n))
+		}
+	}
+
+	total := len(events)
+	if len(events) > limit {
+		events = events[:limit]
+	}
+	result := map[string]interface{}{
+		"since":      start.UTC().Format(time.RFC3339),
+		"source":     source,
+		"total":      total,
+		"events":     events,
+		"by_process": byProcess,
+		"warnings":   warnings,
+	}
+	if counters, err := readVMStat(vmstatPath); err == nil && counters.hasOOMKill {
+		result["kills_since_boot"] = counters.oomKills
+	}
+	if source == "dmesg" {
+		result["note"] = "The journal is not readable, so only the current boot's kernel log was searched"
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// readOOMLog returns kernel and systemd-oomd messages since start from the journal, which
+// spans earlier boots, or the current boot's kernel log when that finds nothing
+func (h *HandlerManager) readOOMLog(ctx context.Context, start time.Time) ([]journalEntry, string, error) {
+	out, err := h.privilegedCommand(ctx, "journalctl", fmt.Sprintf("--since=@%d", start.Unix()), "--no-pager", "-o", "json",
+		"_TRANSPORT=kernel", "+", "SYSLOG_IDENTIFIER=systemd-oomd").Output()
+	if err == nil {
+		if entries := parseJournalEntries(out); len(entries) > 0 {
+			return entries, "journal", nil
+		}
+	}
+	return h.readBootKernelLog(ctx)
+}
+
+// parseOOMEvents assembles OOM kill reports from log lines. The kernel spreads one report
+// over many lines, from "invoked oom-killer" to "Killed process"; pageSize converts the
+// Mem-Info page counts to bytes.
+func parseOOMEvents(entries []journalEntry, pageSize uint64) []oomEvent {
+	events := []oomEvent{}
+	var pending *oomEvent
+	memory := func() *oomMemory {
+		if pending.Memory == nil {
+			pending.Memory = &oomMemory{}
+		}
+		return pending.Memory
+	}
+	for _, e := range entries {
+		msg := strings.TrimSpace(e.Message)
+		if m := oomdKilledRe.FindStringSubmatch(msg); m != nil {
+			events = append(events, oomEvent{
+				Time: e.Time, Killer: oomKillerOomd, Cgroup: m[1], Unit: unitFromCgroup(m[1]), Reason: m[2], Message: msg,
+			})
+			continue
+		}
+		if m := oomInvokedRe.FindStringSubmatch(msg); m != nil {
+			pending = &oomEvent{Time: e.Time, Killer: oomKillerKernel, InvokedBy: m[1]}
+			continue
+		}
+
+		m := oomKilledRe.FindStringSubmatch(msg)
+		if m == nil {
+			if pending != nil {
+				parseOOMContext(msg, pageSize, pending, memory)
+			}
+			continue
+		}
+		ev := oomEvent{Time: e.Time, Killer: oomKillerKernel}
+		if pending != nil {
+			ev = *pending
+		}
+		if strings.HasPrefix(m[1], "Memory cgroup") || (ev.Memory != nil && ev.Memory.CgroupLimitBytes != nil) {
+			ev.Killer = oomKillerCgroup
+		}
+		v := &oomVictim{Name: m[3]}
+		v.PID, _ = strconv.Atoi(m[2])
+		kb := func(s string) uint64 {
+			n, _ := strconv.ParseUint(s, 10, 64)
+			return n * 1024
+		}
+		v.TotalVMBytes, v.AnonRSSBytes, v.FileRSSBytes, v.ShmemRSSBytes = kb(m[4]), kb(m[5]), kb(m[6]), kb(m[7])
+		v.RSSBytes = v.AnonRSSBytes + v.FileRSSBytes + v.ShmemRSSBytes
+		if u := oomUIDRe.FindStringSubmatch(msg); u != nil {
+			uid, _ := strconv.Atoi(u[1])
+			v.UID = &uid
+		}
+		if a := oomScoreAdjRe.FindStringSubmatch(msg); a != nil {
+			adj, _ := strconv.Atoi(a[1])
+			v.OOMScoreAdj = &adj
+		}
+		if pending != nil && pending.Victim != nil && pending.Victim.PID == v.PID {
+			v.Score = pending.Victim.Score
+		}
+		ev.Victim = v
+		ev.Message = msg
+		if ev.Time == "" {
+			ev.Time = e.Time
+		}
+		events = append(events, ev)
+		pending = nil
+	}
+	return events
+}
+
+// parseOOMContext records the constraint, cgroup, memory state, or legacy score from one
+// line of an OOM report in progress
+func parseOOMContext(msg string, pageSize uint64, pending *oomEvent, memory func() *oomMemory) {
+	kb := func(s string) *uint64 {
+		n, _ := strconv.ParseUint(s, 10, 64)
+		n *= 1024
+		return &n
+	}
+	pages := func(s string) *uint64 {
+		n, _ := strconv.ParseUint(s, 10, 64)
+		n *= pageSize
+		return &n
+	}
+	switch {
+	case strings.HasPrefix(msg, oomConstraintKey):
+		// "oom-kill:constraint=CONSTRAINT_NONE,nodemask=(null),...,task_memcg=/system.slice/x.service,task=x,pid=1,uid=0"
+		for _, kv := range strings.Split(strings.TrimPrefix(msg, oomConstraintKey), ",") {
+			key, value, _ := strings.Cut(kv, "=")
+			switch key {
+			case "constraint":
+				pending.Constraint = value
+			case "task_memcg":
+				pending.Cgroup = value
+				pending.Unit = unitFromCgroup(value)
+			}
+		}
+	case oomMemcgUsageRe.MatchString(msg):
+		m := oomMemcgUsageRe.FindStringSubmatch(msg)
+		memory().CgroupUsageBytes, memory().CgroupLimitBytes = kb(m[1]), kb(m[2])
+	case oomRAMPagesRe.MatchString(msg):
+		memory().TotalBytes = pages(oomRAMPagesRe.FindStringSubmatch(msg)[1])
+	case oomSwapRe.MatchString(msg):
+		m := oomSwapRe.FindStringSubmatch(msg)
+		if m[1] == "Free" {
+			memory().SwapFreeBytes = kb(m[2])
+		} else {
+			memory().SwapTotalBytes = kb(m[2])
+		}
+	case oomScoreRe.MatchString(msg):
+		m := oomScoreRe.FindStringSubmatch(msg)
+		pid, _ := strconv.Atoi(m[1])
+		score, _ := strconv.Atoi(m[2])
+		pending.Victim = &oomVictim{PID: pid, Score: &score}
+	case oomFreePagesRe.MatchString(msg):
+		// The node-wide Mem-Info summary, which may arrive as one multi-line message
+		memory().FreeBytes = pages(oomFreePagesRe.FindStringSubmatch(msg)[1])
+	}
+}
+
+// unitFromCgroup returns the systemd unit at the end of a cgroup path such as
+// /system.slice/nginx.service, or "" for other cgroups
+func unitFromCgroup(path string) string {
+	name := path[strings.LastIndex(path, "/")+1:]
+	if strings.HasSuffix(name, ".service") || strings.HasSuffix(name, ".scope") {
+		return name
+	}
+	return ""
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"testing"
+)
+
+func TestParseOOMEvents(t *testing.T) {
+	messages := []string{
+		"python3 invoked oom-killer: gfp_mask=0x100cca(GFP_HIGHUSER_MOVABLE), order=0, oom_score_adj=0",
+		"CPU: 2 PID: 4321 Comm: python3 Not tainted 6.1.0-rpi7-rpi-v8 #1",
+		"Mem-Info:",
+		"active_anon:200000 inactive_anon:5000 isolated_anon:0\n active_file:100 inactive_file:50 isolated_file:0\n free:2048 free_pcp:10 free_cma:0",
+		"Node 0 DMA free:8192kB boost:0kB min:4096kB low:5120kB high:6144kB free_pcp:40kB",
+		"Free swap  = 0kB",
+		"Total swap = 102396kB",
+		"262144 pages RAM",
+		"oom-kill:constraint=CONSTRAINT_NONE,nodemask=(null),cpuset=/,mems_allowed=0,global_oom,task_memcg=/system.slice/worker.service,task=python3,pid=4321,uid=1000",
+		"Out of memory: Killed process 4321 (python3) total-vm:1048576kB, anon-rss:786432kB, file-rss:1024kB, shmem-rss:0kB, UID:1000 pgtables:2048kB oom_score_adj:0",
+		"node invoked oom-killer: gfp_mask=0xcc0(GFP_KERNEL), order=0, oom_score_adj=0",
+		"memory: usage 524288kB, limit 524288kB, failcnt 42",
+		"Memory cgroup out of memory: Killed process 777 (node) total-vm:900000kB, anon-rss:500000kB, file-rss:100kB, shmem-rss:20kB, UID:0 pgtables:1200kB oom_score_adj:-500",
+		"java invoked oom-killer: gfp_mask=0x24201ca, order=0, oom_score_adj=0",
+		"Out of memory: Kill process 99 (java) score 912 or sacrifice child",
+		"Killed process 99 (java) total-vm:2000kB, anon-rss:1000kB, file-rss:0kB",
+		"Killed /user.slice/user-1000.slice/session-3.scope due to memory pressure for /user.slice being 72.55% > 50.00% for > 20s with reclaim activity",
+	}
+	entries := make([]journalEntry, len(messages))
+	for i, m := range messages {
+		entries[i] = journalEntry{Message: m}
+	}
+	events := parseOOMEvents(entries, 4096)
+	if len(events) != 4 {
+		t.Fatalf("Expected 4 events, got %+v", events)
+	}
+
+	global := events[0]
+	if global.Killer != oomKillerKernel || global.InvokedBy != "python3" || global.Constraint != "CONSTRAINT_NONE" || global.Unit != "worker.service" {
+		t.Errorf("Unexpected global OOM event: %+v", global)
+	}
+	if v := global.Victim; v == nil || v.PID != 4321 || v.Name != "python3" || v.RSSBytes != 787456*1024 || v.UID == nil || *v.UID != 1000 || v.OOMScoreAdj == nil || *v.OOMScoreAdj != 0 {
+		t.Errorf("Unexpected global victim: %+v", global.Victim)
+	}
+	if m := global.Memory; m == nil || m.TotalBytes == nil || *m.TotalBytes != 1<<30 || m.FreeBytes == nil || *m.FreeBytes != 2048*4096 ||
+		m.SwapFreeBytes == nil || *m.SwapFreeBytes != 0 || m.SwapTotalBytes == nil || *m.SwapTotalBytes != 102396*1024 || m.CgroupLimitBytes != nil {
+		t.Errorf("Unexpected memory state: %+v", global.Memory)
+	}
+
+	cgroup := events[1]
+	if cgroup.Killer != oomKillerCgroup || cgroup.Victim.Name != "node" || *cgroup.Victim.OOMScoreAdj != -500 || cgroup.Memory.CgroupLimitBytes == nil || *cgroup.Memory.CgroupLimitBytes != 524288*1024 {
+		t.Errorf("Unexpected cgroup OOM event: %+v", cgroup)
+	}
+
+	legacy := events[2]
+	if legacy.Victim == nil || legacy.Victim.Name != "java" || legacy.Victim.Score == nil || *legacy.Victim.Score != 912 || legacy.Victim.ShmemRSSBytes != 0 {
+		t.Errorf("Unexpected legacy OOM event: %+v", legacy.Victim)
+	}
+
+	oomd := events[3]
+	if oomd.Killer != oomKillerOomd || oomd.Unit != "session-3.scope" || oomd.Victim != nil || oomd.Reason == "" {
+		t.Errorf("Unexpected systemd-oomd event: %+v", oomd)
+	}
+}
+
+func TestUnitFromCgroup(t *testing.T) {
+	for path, want := range map[string]string{
+		"/system.slice/nginx.service": "nginx.service",
+		"/user.slice/user-1000.slice": "",
+		"/":                           "",
+	} {
+		if got := unitFromCgroup(path); got != want {
+			t.Errorf("unitFromCgroup(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Package manager names
+const (
+	pkgManagerApt    = "apt"
+	pkgManagerDnf    = "dnf"
+	pkgManagerPacman = "pacman"
+)
+
+// Package update query limits
+const (
+	defaultPackageLimit = 20
+	maxPackageLimit     = 200
+	packageQueryTimeout = 2 * time.Minute
+	staleMetadataMaxAge = 7 * 24 * time.Hour
+	dnfUpdatesAvailable = 100
+)
+
+// packageMetadataPaths are files and directories whose modification time shows when the
+// package lists were last refreshed, per package manager
+var packageMetadataPaths = map[string][]string{
+	pkgManagerApt:    {"/var/lib/apt/periodic/update-success-stamp", "/var/cache/apt/pkgcache.bin", "/var/lib/apt/lists"},
+	pkgManagerDnf:    {"/var/cache/dnf/last_makecache", "/var/cache/dnf", "/var/cache/libdnf5"},
+	pkgManagerPacman: {"/var/lib/pacman/sync"},
+}
+
+// packageUpdate is one pending package upgrade
+type packageUpdate struct {
+	Name           string `json:"name"`
+	CurrentVersion string `json:"current_version,omitempty"`
+	NewVersion     string `json:"new_version"`
+	Arch           string `json:"arch,omitempty"`
+	Repository     string `json:"repository,omitempty"`
+	Security       bool   `json:"security"`
+}
+
+// HandleGetPackageUpdates reports pending system package updates using the native package
+// manager in query-only mode
+func (h *HandlerManager) HandleGetPackageUpdates(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	limit := defaultPackageLimit
+	securityOnly := false
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if l, ok := args["limit"].(float64); ok && l > 0 {
+			limit = int(l)
+			if limit > maxPackageLimit {
+				limit = maxPackageLimit
+			}
+		}
+		if s, ok := args["security_only"].(bool); ok {
+			securityOnly = s
+		}
+	}
+
+	manager := h.packageManager()
+	if manager == "" {
+		return mcp.NewToolResultError("No supported package manager found (apt, dnf, or pacman)"), nil
+	}
+
+	queryCtx, cancel := context.WithTimeout(ctx, packageQueryTimeout)
+	defer cancel()
+	updates, securityKnown, err := h.queryPackageUpdates(queryCtx, manager)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to query %s for updates: %v", manager, err)), nil
+	}
+
+	securityCount := 0
+	for _, u := range updates {
+		if u.Security {
+			securityCount++
+		}
+	}
+	// Security updates first, then by name
+	sort.SliceStable(updates, func(i, j int) bool {
+		if updates[i].Security != updates[j].Security {
+			return updates[i].Security
+		}
+		return updates[i].Name < updates[j].Name
+	})
+
+	listed := []packageUpdate{}
+	for _, u := range updates {
+		if securityOnly && !u.Security {
+			continue
+		}
+		if len(listed) == limit {
+			break
+		}
+		listed = append(listed, u)
+	}
+
+	status := "up_to_date"
+	switch {
+	case securityCount > 0:
+		status = "security_updates_available"
+	case len(updates) > 0:
+		status = "updates_available"
+	}
+
+	result := map[string]interface{}{
+		"package_manager": manager,
+		"status":          status,
+		"update_count":    len(updates),
+		"packages":        listed,
+	}
+	if securityKnown {
+		result["security_update_count"] = securityCount
+	} else {
+		result["security_update_count"] = nil
+		result["security_note"] = fmt.Sprintf("%s does not classify security updates", manager)
+	}
+	if updated, ok := newestModTime(packageMetadataPaths[manager]); ok {
+		age := time.Since(updated).Truncate(time.Second)
+		result["metadata_updated"] = updated.UTC().Format(time.RFC3339)
+		result["metadata_age_human"] = h.human.Duration(age)
+		if age > staleMetadataMaxAge && manager != pkgManagerPacman {
+			result["note"] = fmt.Sprintf("Package lists are %s old, so recent updates may be missing; refresh them with %s", h.human.Duration(age), refreshCommand(manager))
+		}
+	}
+	if _, err := os.Stat(rebootRequiredPath); err == nil {
+		result["reboot_required"] = true
+		if data, err := os.ReadFile(rebootRequiredPath + ".pkgs"); err == nil {
+			result["reboot_required_packages"] = strings.Fields(string(data))
+		}
+	} else if manager == pkgManagerApt {
+		result["reboot_required"] = false
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// packageManager returns the detected package manager, preferring apt, then dnf, then pacman
+func (h *HandlerManager) packageManager() string {
+	switch {
+	case h.caps.Apt:
+		return pkgManagerApt
+	case h.caps.Dnf:
+		return pkgManagerDnf
+	case h.caps.Pacman:
+		return pkgManagerPacman
+	}
+	return ""
+}
+
+// queryPackageUpdates lists pending updates without changing anything. securityKnown is
+// false when the package manager has no notion of security updates.
+func (h *HandlerManager) queryPackageUpdates(ctx context.Context, manager string) (updates []packageUpdate, securityKnown bool, err error) {
+	switch manager {
+	case pkgManagerApt:
+		out, err := packageCommand(ctx, "apt", "list", "--upgradable").Output()
+		if err != nil {
+			return nil, false, err
+		}
+		return parseAptUpgradable(string(out)), true, nil
+
+	case pkgManagerDnf:
+		// check-update exits 100 when updates are available
+		out, err := packageCommand(ctx, "dnf", "check-update", "-q").Output()
+		var exitErr *exec.ExitError
+		if err != nil && (!errors.As(err, &exitErr) || exitErr.ExitCode() != dnfUpdatesAvailable) {
+			return nil, false, err
+		}
+		updates = parseDnfCheckUpdate(string(out))
+		secOut, err := packageCommand(ctx, "dnf", "updateinfo", "list", "--security", "-q").Output()
+		if err != nil {
+			return updates, false, nil
+		}
+		security := parseDnfSecurityNames(string(secOut))
+		for i := range updates {
+			updates[i].Security = security[updates[i].Name]
+		}
+		return updates, true, nil
+
+	case pkgManagerPacman:
+		// checkupdates syncs a private copy of the database; pacman -Qu relies on the
+		// last pacman -Sy and may be stale
+		var out []byte
+		if _, lookErr := exec.LookPath("checkupdates"); lookErr == nil {
+			out, err = packageCommand(ctx, "checkupdates").Output()
+			// checkupdates exits 2 when there are no updates
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) && exitErr.ExitCode() == 2 {
+				err = nil
+			}
+		} else {
+			out, err = packageCommand(ctx, "pacman", "-Qu").Output()
+			// pacman -Qu exits 1 when there is nothing to upgrade
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && len(out) == 0 {
+				err = nil
+			}
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		return parsePacmanUpdates(string(out)), false, nil
+	}
+	return nil, false, fmt.Errorf("unsupported package manager %q", manager)
+}
+
+// packageCommand runs a package manager query with stable, untranslated output
+func packageCommand(ctx context.Context, name string, args ...string) *exec.Cmd {
+	//nolint:gosec // G204: fixed package manager commands, no user input
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	return cmd
+}
+
+// parseAptUpgradable parses `apt list --upgradable`, e.g.
+// openssl/stable-security 3.0.11-1~deb12u2 arm64 [upgradable from: 3.0.11-1~deb12u1]
+func parseAptUpgradable(out string) []packageUpdate {
+	updates := []packageUpdate{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || !strings.Contains(fields[0], "/") {
+			continue
+		}
+		name, suites, _ := strings.Cut(fields[0], "/")
+		u := packageUpdate{Name: name, NewVersion: fields[1], Arch: fields[2], Repository: suites}
+		if _, from, ok := strings.Cut(line, "upgradable from: "); ok {
+			u.CurrentVersion = strings.TrimSuffix(strings.TrimSpace(from), "]")
+		}
+		for _, suite := range strings.Split(suites, ",") {
+			if strings.HasSuffix(suite, "-security") || suite == "security" {
+				u.Security = true
+			}
+		}
+		updates = append(updates, u)
+	}
+	return updates
+}
+
+// parseDnfCheckUpdate parses `dnf check-update -q`, e.g.
+// openssl-libs.x86_64    1:3.1.1-4.fc39    updates
+// Long package names wrap the remaining columns onto the next line.
+func parseDnfCheckUpdate(out string) []packageUpdate {
+	updates := []packageUpdate{}
+	var pending string
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "Obsoleting") || strings.HasPrefix(line, "Security:") {
+			break
+		}
+		fields := strings.Fields(line)
+		if pending != "" {
+			fields = append([]string{pending}, fields...)
+			pending = ""
+		}
+		if len(fields) == 1 {
+			pending = fields[0]
+			continue
+		}
+		if len(fields) != 3 {
+			continue
+		}
+		name, arch := fields[0], ""
+		if i := strings.LastIndex(name, "."); i > 0 {
+			name, arch = name[:i], name[i+1:]
+		}
+		updates = append(updates, packageUpdate{Name: name, Arch: arch, NewVersion: fields[1], Repository: fields[2]})
+	}
+	return updates
+}
+
+// parseDnfSecurityNames returns the package names in `dnf updateinfo list --security`,
+// whose last column is a NEVRA such as openssl-libs-1:3.1.1-4.fc39.x86_64
+func parseDnfSecurityNames(out string) map[string]bool {
+	names := map[string]bool{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		nevra := fields[len(fields)-1]
+		if i := strings.LastIndex(nevra, "."); i > 0 {
+			nevra = nevra[:i]
+		}
+		// Strip -version-release
+		parts := strings.Split(nevra, "-")
+		if len(parts) < 3 {
+			continue
+		}
+		names[strings.Join(parts[:len(parts)-2], "-")] = true
+	}
+	return names
+}
+
+// parsePacmanUpdates parses checkupdates / pacman -Qu output, e.g.
+// linux 6.5.5.arch1-1 -> 6.5.6.arch1-1
+func parsePacmanUpdates(out string) []packageUpdate {
+	updates := []packageUpdate{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[2] != "->" {
+			continue
+		}
+		updates = append(updates, packageUpdate{Name: fields[0], CurrentVersion: fields[1], NewVersion: fields[3]})
+	}
+	return updates
+}
+
+// refreshCommand is the command that refreshes a package manager's lists
+func refreshCommand(manager string) string {
+	if manager == pkgManagerDnf {
+		return "dnf makecache"
+	}
+	return "apt update"
+}
+
+// newestModTime returns the latest modification time among paths that exist
+func newestModTime(paths []string) (time.Time, bool) {
+	var newest time.Time
+	for _, p := range paths {
+		if info, err := os.Stat(filepath.Clean(p)); err == nil && info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+	}
+	return newest, !newest.IsZero()
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestParseAptUpgradable(t *testing.T) {
+	out := `Listing...
+openssl/stable-security 3.0.11-1~deb12u2 arm64 [upgradable from: 3.0.11-1~deb12u1]
+raspi-firmware/stable 1:1.20240424-1 all [upgradable from: 1:1.20240306-1]
+tzdata/stable-updates,stable-security 2024a-0+deb12u1 all [upgradable from: 2023c-5+deb12u1]
+`
+	updates := parseAptUpgradable(out)
+	if len(updates) != 3 {
+		t.Fatalf("Expected 3 updates, got %+v", updates)
+	}
+	want := []packageUpdate{
+		{Name: "openssl", CurrentVersion: "3.0.11-1~deb12u1", NewVersion: "3.0.11-1~deb12u2", Arch: "arm64", Repository: "stable-security", Security: true},
+		{Name: "raspi-firmware", CurrentVersion: "1:1.20240306-1", NewVersion: "1:1.20240424-1", Arch: "all", Repository: "stable"},
+		{Name: "tzdata", CurrentVersion: "2023c-5+deb12u1", NewVersion: "2024a-0+deb12u1", Arch: "all", Repository: "stable-updates,stable-security", Security: true},
+	}
+	for i, w := range want {
+		if updates[i] != w {
+			t.Errorf("update %d = %+v, want %+v", i, updates[i], w)
+		}
+	}
+}
+
+func TestParseDnfCheckUpdate(t *testing.T) {
+	out := `
+kernel.x86_64                        6.5.6-300.fc39                updates
+openssl-libs.x86_64                  1:3.1.1-4.fc39                updates
+python3-a-very-long-package-name-indeed.noarch
+                                     2.0-1.fc39                    updates
+Obsoleting Packages
+grub2-tools.x86_64                   1:2.06-100.fc39               updates
+`
+	updates := parseDnfCheckUpdate(out)
+	if len(updates) != 3 {
+		t.Fatalf("Expected 3 updates, got %+v", updates)
+	}
+	if updates[1].Name != "openssl-libs" || updates[1].Arch != "x86_64" || updates[1].NewVersion != "1:3.1.1-4.fc39" {
+		t.Errorf("Unexpected update: %+v", updates[1])
+	}
+	if updates[2].Name != "python3-a-very-long-package-name-indeed" || updates[2].Repository != "updates" {
+		t.Errorf("Expected the wrapped line to be joined, got %+v", updates[2])
+	}
+
+	security := parseDnfSecurityNames(`FEDORA-2023-1a2b3c4d5e Important/Sec. openssl-libs-1:3.1.1-4.fc39.x86_64
+FEDORA-2023-6f7a8b9c0d Moderate/Sec.  kernel-6.5.6-300.fc39.x86_64
+`)
+	if !security["openssl-libs"] || !security["kernel"] || len(security) != 2 {
+		t.Errorf("Unexpected security names: %v", security)
+	}
+}
+
+func TestParsePacmanUpdates(t *testing.T) {
+	updates := parsePacmanUpdates("linux 6.5.5.arch1-1 -> 6.5.6.arch1-1\nglibc 2.38-3 -> 2.38-5 [ignored]\n")
+	if len(updates) != 2 || updates[0].Name != "linux" || updates[0].CurrentVersion != "6.5.5.arch1-1" || updates[1].NewVersion != "2.38-5" {
+		t.Errorf("Unexpected updates: %+v", updates)
+	}
+}
+
+func TestHandleGetPackageUpdatesUnsupported(t *testing.T) {
+	h := NewHandlerManager(&config.Config{})
+	h.caps.Apt, h.caps.Dnf, h.caps.Pacman = false, false, false
+	res, err := h.HandleGetPackageUpdates(context.Background(), mcp.CallToolRequest{})
+	if err != nil || !res.IsError {
+		t.Errorf("Expected a tool error without a package manager, got %v, %v", res, err)
+	}
+}
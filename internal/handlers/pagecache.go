@@ -0,0 +1,341 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/host"
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+// vmstatPath exposes the kernel's cumulative memory management counters
+var vmstatPath = "/proc/vmstat"
+
+// sysBlockPath holds per-device queue settings such as read_ahead_kb
+var sysBlockPath = "/sys/block"
+
+// Page cache sampling limits. Sampling is opt-in so a plain memory call stays instant.
+const (
+	defaultPageCacheSampleSeconds = 0
+	maxPageCacheSampleSeconds     = 10
+)
+
+// Page cache assessments answering "would more RAM help"
+const (
+	moreRAMLikely   = "likely"
+	moreRAMPossible = "possible"
+	moreRAMUnlikely = "unlikely"
+)
+
+// Swap activity states
+const (
+	swapIdle      = "idle"
+	swapPagingOut = "paging_out"
+	swapPagingIn  = "paging_in"
+	swapActive    = "active"
+	swapThrashing = "thrashing"
+)
+
+// swapThrashPagesPerSec is the combined swap-in and swap-out rate above which pages
+// moving both ways at once count as thrashing rather than a working set settling
+const swapThrashPagesPerSec = 100
+
+// vmCounters are the /proc/vmstat counters behind the page cache and swap metrics, in
+// pages except pgpgin/pgpgout (KiB)
+type vmCounters struct {
+	scanKswapd    uint64
+	scanDirect    uint64
+	stealKswapd   uint64
+	stealDirect   uint64
+	refault       uint64
+	activate      uint64
+	majorFaults   uint64
+	pagesInKB     uint64
+	pagesOutKB    uint64
+	swapIn        uint64
+	swapOut       uint64
+	oomKills      uint64
+	hasWorkingset bool
+	hasOOMKill    bool
+}
+
+// readVMStat parses /proc/vmstat. Counter names vary across kernel versions: scan and
+// steal counts are summed over kswapd/khugepaged, and file refaults fall back to the
+// pre-5.9 combined counter.
+func readVMStat(path string) (vmCounters, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return vmCounters{}, err
+	}
+	defer f.Close()
+
+	raw := map[string]uint64{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		if v, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+			raw[fields[0]] = v
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return vmCounters{}, err
+	}
+
+	c := vmCounters{
+		scanKswapd:  raw["pgscan_kswapd"] + raw["pgscan_khugepaged"],
+		scanDirect:  raw["pgscan_direct"],
+		stealKswapd: raw["pgsteal_kswapd"] + raw["pgsteal_khugepaged"],
+		stealDirect: raw["pgsteal_direct"],
+		majorFaults: raw["pgmajfault"],
+		pagesInKB:   raw["pgpgin"],
+		pagesOutKB:  raw["pgpgout"],
+		swapIn:      raw["pswpin"],
+		swapOut:     raw["pswpout"],
+	}
+	// oom_kill was added in Linux 4.13
+	c.oomKills, c.hasOOMKill = raw["oom_kill"]
+	if v, ok := raw["workingset_refault_file"]; ok {
+		c.refault, c.activate, c.hasWorkingset = v, raw["workingset_activate_file"], true
+	} else if v, ok := raw["workingset_refault"]; ok {
+		c.refault, c.activate, c.hasWorkingset = v, raw["workingset_activate"], true
+	}
+	return c, nil
+}
+
+// sub returns the counter increase from before to c, treating a decrease as zero
+func (c vmCounters) sub(before vmCounters) vmCounters {
+	d := func(a, b uint64) uint64 {
+		if a < b {
+			return 0
+		}
+		return a - b
+	}
+	return vmCounters{
+		scanKswapd:    d(c.scanKswapd, before.scanKswapd),
+		scanDirect:    d(c.scanDirect, before.scanDirect),
+		stealKswapd:   d(c.stealKswapd, before.stealKswapd),
+		stealDirect:   d(c.stealDirect, before.stealDirect),
+		refault:       d(c.refault, before.refault),
+		activate:      d(c.activate, before.activate),
+		majorFaults:   d(c.majorFaults, before.majorFaults),
+		pagesInKB:     d(c.pagesInKB, before.pagesInKB),
+		pagesOutKB:    d(c.pagesOutKB, before.pagesOutKB),
+		swapIn:        d(c.swapIn, before.swapIn),
+		swapOut:       d(c.swapOut, before.swapOut),
+		oomKills:      d(c.oomKills, before.oomKills),
+		hasWorkingset: c.hasWorkingset,
+		hasOOMKill:    c.hasOOMKill,
+	}
+}
+
+// pageCacheRates converts counter deltas over seconds into the reported rates
+func pageCacheRates(d vmCounters, seconds float64) map[string]interface{} {
+	if seconds <= 0 {
+		seconds = 1
+	}
+	pageSize := float64(os.Getpagesize())
+	scanned := d.scanKswapd + d.scanDirect
+	stolen := d.stealKswapd + d.stealDirect
+	rate := func(v float64) float64 { return round2(v / seconds) }
+
+	rates := map[string]interface{}{
+		"pgscan_per_sec":           rate(float64(scanned)),
+		"pgsteal_per_sec":          rate(float64(stolen)),
+		"pgsteal_direct_per_sec":   rate(float64(d.stealDirect)),
+		"major_faults_per_sec":     rate(float64(d.majorFaults)),
+		"disk_read_bytes_per_sec":  rate(float64(d.pagesInKB) * 1024),
+		"disk_write_bytes_per_sec": rate(float64(d.pagesOutKB) * 1024),
+	}
+	// Share of scanned pages that could be reclaimed; low values mean the kernel is
+	// working hard to find anything to evict
+	if scanned > 0 {
+		rates["reclaim_efficiency_percent"] = round2(float64(stolen) / float64(scanned) * 100)
+	}
+	if d.hasWorkingset {
+		rates["refaults_per_sec"] = rate(float64(d.refault))
+		rates["refault_activations_per_sec"] = rate(float64(d.activate))
+		// Share of disk reads spent re-reading file pages evicted too early: the cost of
+		// a page cache that is too small for the working set
+		if d.pagesInKB > 0 {
+			pct := float64(d.refault) * pageSize / (float64(d.pagesInKB) * 1024) * 100
+			if pct > 100 {
+				pct = 100
+			}
+			rates["refault_read_percent"] = round2(pct)
+		}
+	}
+	return rates
+}
+
+// assessMoreRAM judges from sampled counter deltas whether more RAM would help: refaults
+// and direct reclaim mean the working set does not fit, no reclaim at all means it does
+func assessMoreRAM(d vmCounters) (string, string) {
+	scanned := d.scanKswapd + d.scanDirect
+	refaultShare := 0.0
+	if d.pagesInKB > 0 {
+		refaultShare = float64(d.refault) * float64(os.Getpagesize()) / (float64(d.pagesInKB) * 1024)
+	}
+
+	switch {
+	case d.stealDirect > 0:
+		return moreRAMLikely, "processes are stalling in direct reclaim to free memory"
+	case d.hasWorkingset && d.refault > 0 && refaultShare >= 0.1:
+		return moreRAMLikely, "a significant share of disk reads re-fetch recently evicted file pages (refaults)"
+	case scanned == 0:
+		return moreRAMUnlikely, "no page reclaim during the sample; the page cache is not under pressure"
+	case d.refault == 0:
+		return moreRAMPossible, "background reclaim is active but evicted pages are not being re-read"
+	}
+	return moreRAMPossible, "background reclaim and occasional refaults; watch refaults_per_sec under load"
+}
+
+// swapRates converts swap and major fault counter deltas over seconds into rates
+func swapRates(d vmCounters, seconds float64) map[string]interface{} {
+	if seconds <= 0 {
+		seconds = 1
+	}
+	pageSize := float64(os.Getpagesize())
+	rate := func(v float64) float64 { return round2(v / seconds) }
+	return map[string]interface{}{
+		"swap_in_pages_per_sec":  rate(float64(d.swapIn)),
+		"swap_out_pages_per_sec": rate(float64(d.swapOut)),
+		"swap_in_bytes_per_sec":  rate(float64(d.swapIn) * pageSize),
+		"swap_out_bytes_per_sec": rate(float64(d.swapOut) * pageSize),
+		"major_faults_per_sec":   rate(float64(d.majorFaults)),
+	}
+}
+
+// assessSwapActivity judges from sampled counter deltas whether the system is thrashing:
+// swap in use says nothing on its own, pages moving in both directions at once does
+func assessSwapActivity(d vmCounters, seconds float64) (string, string) {
+	if seconds <= 0 {
+		seconds = 1
+	}
+	perSec := float64(d.swapIn+d.swapOut) / seconds
+	switch {
+	case d.swapIn == 0 && d.swapOut == 0:
+		return swapIdle, "no pages moved to or from swap during the sample"
+	case d.swapIn == 0:
+		return swapPagingOut, "the kernel is moving idle pages to swap; harmless unless they are read back soon"
+	case d.swapOut == 0:
+		return swapPagingIn, "pages are being read back from swap, usually after an earlier memory squeeze"
+	case perSec >= swapThrashPagesPerSec:
+		return swapThrashing, "pages are being swapped out and read back at the same time; the working set does not fit in RAM"
+	}
+	return swapActive, "light swapping in both directions; watch swap_in_pages_per_sec under load"
+}
+
+// readaheadStats reports each block device's readahead window with the average read
+// size since boot; large average reads on a device with readahead show it is effective
+func readaheadStats(ctx context.Context) []map[string]interface{} {
+	counters, _ := disk.IOCountersWithContext(ctx)
+	devices := []map[string]interface{}{}
+	entries, err := os.ReadDir(sysBlockPath)
+	if err != nil {
+		return devices
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasPrefix(name, "loop") || strings.HasPrefix(name, "ram") || strings.HasPrefix(name, "zram") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(sysBlockPath, name, "queue", "read_ahead_kb"))
+		if err != nil {
+			continue
+		}
+		kb, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+		if err != nil {
+			continue
+		}
+		dev := map[string]interface{}{"device": name, "read_ahead_kb": kb}
+		if c, ok := counters[name]; ok && c.ReadCount > 0 {
+			dev["avg_read_kb"] = round2(float64(c.ReadBytes) / float64(c.ReadCount) / 1024)
+		}
+		devices = append(devices, dev)
+	}
+	return devices
+}
+
+// vmSample holds the /proc/vmstat counters read for the memory metrics, so page cache
+// and swap activity share one sampling window
+type vmSample struct {
+	total   vmCounters
+	uptime  float64
+	delta   *vmCounters
+	seconds float64
+}
+
+// swapActivity reports swap-in, swap-out, and major fault rates since boot and over the
+// sample, with a thrashing assessment when a sample was taken
+func swapActivity(s *vmSample) map[string]interface{} {
+	result := map[string]interface{}{}
+	if s.uptime > 0 {
+		result["since_boot"] = swapRates(s.total, s.uptime)
+	}
+	if s.delta != nil {
+		result["sampled"] = swapRates(*s.delta, s.seconds)
+		state, reason := assessSwapActivity(*s.delta, s.seconds)
+		result["state"] = state
+		result["state_reason"] = reason
+	}
+	return result
+}
+
+// getPageCacheMetrics reports page cache size and churn. Rates are sampled over
+// sampleSeconds (since-boot averages are always included) and drive the assessment. The
+// counters read are returned for the swap activity rates, or nil if vmstat is unreadable.
+func (h *HandlerManager) getPageCacheMetrics(ctx context.Context, memInfo *mem.VirtualMemoryStat, sampleSeconds int) (map[string]interface{}, *vmSample) {
+	result := map[string]interface{}{
+		"cached_bytes":    memInfo.Cached,
+		"cached_human":    h.human.Bytes(memInfo.Cached),
+		"buffers_bytes":   memInfo.Buffers,
+		"dirty_bytes":     memInfo.Dirty,
+		"writeback_bytes": memInfo.WriteBack,
+	}
+	if memInfo.Total > 0 {
+		result["cached_percent"] = round2(float64(memInfo.Cached+memInfo.Buffers) / float64(memInfo.Total) * 100)
+	}
+
+	before, err := readVMStat(vmstatPath)
+	if err != nil {
+		result["error"] = fmt.Sprintf("page cache churn is unavailable: %v", err)
+		return result, nil
+	}
+	sample := &vmSample{total: before}
+	if uptime, err := host.UptimeWithContext(ctx); err == nil && uptime > 0 {
+		sample.uptime = float64(uptime)
+		result["since_boot"] = pageCacheRates(before, sample.uptime)
+	}
+
+	if sampleSeconds > 0 {
+		start := time.Now()
+		select {
+		case <-ctx.Done():
+			return result, sample
+		case <-time.After(time.Duration(sampleSeconds) * time.Second):
+		}
+		after, err := readVMStat(vmstatPath)
+		if err == nil {
+			delta := after.sub(before)
+			sample.delta, sample.seconds = &delta, time.Since(start).Seconds()
+			result["sampled"] = pageCacheRates(delta, sample.seconds)
+			result["sample_seconds"] = sampleSeconds
+			assessment, reason := assessMoreRAM(delta)
+			result["more_ram_would_help"] = assessment
+			result["assessment_reason"] = reason
+		}
+	}
+
+	result["readahead"] = readaheadStats(ctx)
+	return result, sample
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestReadVMStat(t *testing.T) {
+	dir := t.TempDir()
+	modern := filepath.Join(dir, "vmstat")
+	legacy := filepath.Join(dir, "vmstat.legacy")
+	if err := os.WriteFile(modern, []byte("nr_free_pages 1000\npgpgin 4096\npgpgout 128\npgmajfault 7\n"+
+		"pswpin 11\npswpout 22\npgscan_kswapd 300\npgscan_direct 20\npgscan_khugepaged 5\npgsteal_kswapd 250\npgsteal_direct 10\n"+
+		"workingset_refault_anon 3\nworkingset_refault_file 40\nworkingset_activate_file 12\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(legacy, []byte("pgpgin 10\nworkingset_refault 9\nworkingset_activate 4\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := readVMStat(modern)
+	if err != nil {
+		t.Fatalf("readVMStat failed: %v", err)
+	}
+	if c.scanKswapd != 305 || c.scanDirect != 20 || c.stealKswapd != 250 || c.stealDirect != 10 ||
+		c.refault != 40 || c.activate != 12 || c.majorFaults != 7 || c.pagesInKB != 4096 || c.swapIn != 11 || c.swapOut != 22 || !c.hasWorkingset {
+		t.Errorf("Unexpected counters: %+v", c)
+	}
+
+	c, err = readVMStat(legacy)
+	if err != nil || c.refault != 9 || c.activate != 4 {
+		t.Errorf("Expected the pre-5.9 refault counters, got %+v (err %v)", c, err)
+	}
+
+	if _, err := readVMStat(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected an error for a missing vmstat file")
+	}
+}
+
+func TestPageCacheRates(t *testing.T) {
+	pageKB := uint64(os.Getpagesize() / 1024)
+	d := vmCounters{scanKswapd: 200, stealKswapd: 100, refault: 50, pagesInKB: 100 * pageKB, hasWorkingset: true}
+	rates := pageCacheRates(d, 2)
+
+	if rates["pgscan_per_sec"] != 100.0 || rates["pgsteal_per_sec"] != 50.0 || rates["refaults_per_sec"] != 25.0 {
+		t.Errorf("Unexpected rates: %v", rates)
+	}
+	if rates["reclaim_efficiency_percent"] != 50.0 {
+		t.Errorf("Expected 50%% reclaim efficiency, got %v", rates["reclaim_efficiency_percent"])
+	}
+	if rates["refault_read_percent"] != 50.0 {
+		t.Errorf("Expected half the reads to be refaults, got %v", rates["refault_read_percent"])
+	}
+}
+
+func TestAssessMoreRAM(t *testing.T) {
+	pageKB := uint64(os.Getpagesize() / 1024)
+	tests := []struct {
+		name     string
+		delta    vmCounters
+		expected string
+	}{
+		{"idle", vmCounters{hasWorkingset: true}, moreRAMUnlikely},
+		{"direct reclaim", vmCounters{scanDirect: 50, stealDirect: 40, hasWorkingset: true}, moreRAMLikely},
+		{"thrashing cache", vmCounters{scanKswapd: 500, stealKswapd: 400, refault: 80, pagesInKB: 100 * pageKB, hasWorkingset: true}, moreRAMLikely},
+		{"reclaim without refaults", vmCounters{scanKswapd: 500, stealKswapd: 400, pagesInKB: 100 * pageKB, hasWorkingset: true}, moreRAMPossible},
+		{"occasional refaults", vmCounters{scanKswapd: 500, stealKswapd: 400, refault: 2, pagesInKB: 100 * pageKB, hasWorkingset: true}, moreRAMPossible},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason := assessMoreRAM(tt.delta)
+			if got != tt.expected || reason == "" {
+				t.Errorf("assessMoreRAM = %s (%q), expected %s", got, reason, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSwapRates(t *testing.T) {
+	pageSize := float64(os.Getpagesize())
+	rates := swapRates(vmCounters{swapIn: 40, swapOut: 10, majorFaults: 6}, 2)
+	if rates["swap_in_pages_per_sec"] != 20.0 || rates["swap_out_pages_per_sec"] != 5.0 || rates["major_faults_per_sec"] != 3.0 {
+		t.Errorf("Unexpected rates: %v", rates)
+	}
+	if rates["swap_in_bytes_per_sec"] != round2(20*pageSize) {
+		t.Errorf("Expected swap-in bytes to be pages times the page size, got %v", rates["swap_in_bytes_per_sec"])
+	}
+}
+
+func TestAssessSwapActivity(t *testing.T) {
+	tests := []struct {
+		name     string
+		delta    vmCounters
+		expected string
+	}{
+		{"idle", vmCounters{}, swapIdle},
+		{"paging out", vmCounters{swapOut: 500}, swapPagingOut},
+		{"paging in", vmCounters{swapIn: 500}, swapPagingIn},
+		{"light", vmCounters{swapIn: 5, swapOut: 5}, swapActive},
+		{"thrashing", vmCounters{swapIn: 300, swapOut: 250}, swapThrashing},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason := assessSwapActivity(tt.delta, 2)
+			if got != tt.expected || reason == "" {
+				t.Errorf("assessSwapActivity = %s (%q), expected %s", got, reason, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSwapActivity(t *testing.T) {
+	delta := vmCounters{swapIn: 300, swapOut: 300}
+	activity := swapActivity(&vmSample{total: vmCounters{swapIn: 1000}, uptime: 100, delta: &delta, seconds: 1})
+	if activity["state"] != swapThrashing {
+		t.Errorf("Expected thrashing, got %v", activity)
+	}
+	if boot, ok := activity["since_boot"].(map[string]interface{}); !ok || boot["swap_in_pages_per_sec"] != 10.0 {
+		t.Errorf("Unexpected since-boot rates: %v", activity["since_boot"])
+	}
+
+	activity = swapActivity(&vmSample{total: vmCounters{}, uptime: 100})
+	if _, ok := activity["state"]; ok {
+		t.Errorf("Expected no assessment without a sample, got %v", activity)
+	}
+}
+
+func TestHandleGetMemoryMetricsPageCache(t *testing.T) {
+	h := NewHandlerManager(&config.Config{})
+	// A plain call returns since-boot averages without waiting for a sample
+	start := time.Now()
+	res, err := h.HandleGetMemoryMetrics(context.Background(), mcp.CallToolRequest{})
+	if elapsed := time.Since(start); elapsed >= 500*time.Millisecond {
+		t.Errorf("Expected no sampling by default, took %s", elapsed)
+	}
+	checkToolResult(t, res, err, []string{"ram", "swap", "page_cache"})
+
+	var result struct {
+		PageCache map[string]interface{} `json:"page_cache"`
+	}
+	if err := json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &result); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+	if _, ok := result.PageCache["cached_bytes"]; !ok {
+		t.Errorf("Expected cached_bytes in page_cache, got %v", result.PageCache)
+	}
+	if _, ok := result.PageCache["sampled"]; ok {
+		t.Error("Expected no sampled rates without sample_seconds")
+	}
+}
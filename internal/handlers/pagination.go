@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// pageArgs are the arguments that select a page rather than the rows it is taken from;
+// they are left out of a cursor's query fingerprint, as are output-only arguments
+var pageArgs = []string{"offset", "cursor", "limit", "fields", "format"}
+
+// maxPageOffset bounds offset so it converts to an int on every platform
+const maxPageOffset = math.MaxInt32
+
+// page is a window into a sorted list, requested with offset or cursor and limit
+type page struct {
+	offset int
+	limit  int
+	query  string
+	// after is the key of the last row of the previous page, for a keyed list
+	after *pageKey
+	// key returns the key of a row of a keyed list; see seek
+	key func(i int) pageKey
+}
+
+// pageKey is a row's position in a list sorted by Value descending, then ID ascending.
+// A list whose values change between calls, such as processes by live CPU usage, is
+// continued after the last row's key rather than at an offset, so rows that moved up
+// or away since the previous page do not shift the next one.
+type pageKey struct {
+	Value float64 `json:"v"`
+	ID    int64   `json:"i"`
+}
+
+// before reports whether k sorts before o
+func (k pageKey) before(o pageKey) bool {
+	if k.Value != o.Value {
+		return k.Value > o.Value
+	}
+	return k.ID < o.ID
+}
+
+// pageCursor is the decoded form of next_cursor
+type pageCursor struct {
+	Offset int      `json:"o"`
+	After  *pageKey `json:"a,omitempty"`
+	Query  string   `json:"q"`
+}
+
+// parsePage reads offset or cursor for a tool's list. A cursor only continues the query
+// that returned it, so it records a fingerprint of the tool's other arguments.
+func parsePage(tool string, args map[string]interface{}, limit int) (page, error) {
+	p := page{limit: limit, query: queryFingerprint(tool, args)}
+	cursor, _ := args["cursor"].(string)
+	offset, hasOffset := args["offset"].(float64)
+	switch {
+	case cursor != "" && hasOffset:
+		return page{}, fmt.Errorf("offset and cursor cannot be combined")
+	case cursor != "":
+		raw, err := base64.RawURLEncoding.DecodeString(cursor)
+		var c pageCursor
+		if err != nil || json.Unmarshal(raw, &c) != nil || c.Offset < 0 || c.Offset > maxPageOffset {
+			return page{}, fmt.Errorf("invalid cursor %q", cursor)
+		}
+		if c.Query != p.query {
+			return page{}, fmt.Errorf("cursor belongs to a query with different arguments; repeat the first request without cursor")
+		}
+		p.offset = c.Offset
+		p.after = c.After
+	case hasOffset:
+		if offset < 0 || offset > maxPageOffset {
+			return page{}, fmt.Errorf("invalid offset: %v (must be 0 to %d)", offset, maxPageOffset)
+		}
+		p.offset = int(offset)
+	}
+	return p, nil
+}
+
+// seek makes the page keyed: key returns the pageKey of row i of the n rows, which must
+// be sorted in pageKey order. A cursor then continues after the last row it returned.
+func (p *page) seek(n int, key func(i int) pageKey) {
+	p.key = key
+	if p.after != nil {
+		p.offset = sort.Search(n, func(i int) bool { return p.after.before(key(i)) })
+	}
+}
+
+// bounds returns the slice bounds of the page in a list of n rows
+func (p page) bounds(n int) (start, end int) {
+	start = min(p.offset, n)
+	return start, min(start+p.limit, n)
+}
+
+// info describes the page for the result. next_cursor is set while rows remain.
+func (p page) info(n int) map[string]interface{} {
+	start, end := p.bounds(n)
+	info := map[string]interface{}{
+		"offset":   start,
+		"limit":    p.limit,
+		"has_more": end < n,
+	}
+	if end < n {
+		cursor := pageCursor{Offset: end, Query: p.query}
+		if p.key != nil && end > 0 {
+			last := p.key(end - 1)
+			cursor.After = &last
+		}
+		raw, _ := json.Marshal(cursor)
+		info["next_cursor"] = base64.RawURLEncoding.EncodeToString(raw)
+	}
+	return info
+}
+
+// queryFingerprint hashes the tool name and its arguments other than pageArgs
+func queryFingerprint(tool string, args map[string]interface{}) string {
+	var b strings.Builder
+	b.WriteString(tool)
+	for _, k := range sortedKeys(args) {
+		if contains(pageArgs, k) {
+			continue
+		}
+		v, _ := json.Marshal(args[k])
+		b.WriteString("\x00" + k + "=" + string(v))
+	}
+	sum := fnv.New64a()
+	sum.Write([]byte(b.String()))
+	return strconv.FormatUint(sum.Sum64(), 36)
+}
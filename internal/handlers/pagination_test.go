@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"testing"
+)
+
+func TestParsePage(t *testing.T) {
+	args := map[string]interface{}{"sort_by": "cpu", "limit": float64(2)}
+	pg, err := parsePage("get_process_list", args, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if start, end := pg.bounds(5); start != 0 || end != 2 {
+		t.Errorf("bounds(5) = %d, %d, want 0, 2", start, end)
+	}
+	info := pg.info(5)
+	cursor, _ := info["next_cursor"].(string)
+	if info["has_more"] != true || cursor == "" {
+		t.Fatalf("Expected a next_cursor, got %v", info)
+	}
+
+	// The cursor continues the same query, whatever the limit and format
+	next, err := parsePage("get_process_list", map[string]interface{}{"sort_by": "cpu", "cursor": cursor, "format": "markdown"}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if start, end := next.bounds(5); start != 2 || end != 4 {
+		t.Errorf("bounds(5) = %d, %d, want 2, 4", start, end)
+	}
+	last, _ := parsePage("get_process_list", map[string]interface{}{"offset": float64(4)}, 2)
+	if info := last.info(5); info["has_more"] != false || info["next_cursor"] != nil {
+		t.Errorf("Expected the last page to have no next_cursor, got %v", info)
+	}
+	if start, end := last.bounds(3); start != 3 || end != 3 {
+		t.Errorf("bounds(3) past the end = %d, %d, want 3, 3", start, end)
+	}
+
+	for name, bad := range map[string]map[string]interface{}{
+		"other query": {"sort_by": "memory", "cursor": cursor},
+		"other tool":  {"sort_by": "cpu", "cursor": cursor},
+		"garbage":     {"cursor": "not-a-cursor"},
+		"both":        {"cursor": cursor, "offset": float64(1)},
+		"negative":    {"offset": float64(-1)},
+		"huge":        {"offset": 1e19},
+	} {
+		tool := "get_process_list"
+		if name == "other tool" {
+			tool = "get_network_connections"
+		}
+		if _, err := parsePage(tool, bad, 2); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestPageSeek(t *testing.T) {
+	keys := []pageKey{{Value: 9, ID: 4}, {Value: 5, ID: 2}, {Value: 5, ID: 7}, {Value: 1, ID: 3}}
+	keyOf := func(list []pageKey) func(i int) pageKey {
+		return func(i int) pageKey { return list[i] }
+	}
+	args := map[string]interface{}{"sort_by": "cpu"}
+	pg, _ := parsePage("get_process_list", args, 2)
+	pg.seek(len(keys), keyOf(keys))
+	cursor, _ := pg.info(len(keys))["next_cursor"].(string)
+	if cursor == "" {
+		t.Fatal("Expected a next_cursor")
+	}
+
+	// PID 4 dropped to the end and PID 5 appeared at the top since the first page. An
+	// offset would skip PID 7; the cursor continues after the last row returned.
+	moved := []pageKey{{Value: 12, ID: 5}, {Value: 5, ID: 2}, {Value: 5, ID: 7}, {Value: 1, ID: 3}, {Value: 0, ID: 4}}
+	next, err := parsePage("get_process_list", map[string]interface{}{"sort_by": "cpu", "cursor": cursor}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next.seek(len(moved), keyOf(moved))
+	if start, end := next.bounds(len(moved)); start != 2 || end != 4 {
+		t.Errorf("bounds = %d, %d, want 2, 4", start, end)
+	}
+
+	// A removed last row still continues at the next key
+	removed := []pageKey{{Value: 9, ID: 4}, {Value: 1, ID: 3}}
+	next, _ = parsePage("get_process_list", map[string]interface{}{"sort_by": "cpu", "cursor": cursor}, 2)
+	next.seek(len(removed), keyOf(removed))
+	if start, _ := next.bounds(len(removed)); start != 1 {
+		t.Errorf("start = %d, want 1", start)
+	}
+}
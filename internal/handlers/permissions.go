@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+
+	"sysmetrics-mcp/internal/capabilities"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// HandleGetPermissionStatus reports which collectors are degraded due to missing privileges
+func (h *HandlerManager) HandleGetPermissionStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	checks := capabilities.CheckPermissions(h.caps)
+
+	var degraded []string
+	for _, c := range checks {
+		if !c.Accessible {
+			degraded = append(degraded, c.Collector)
+		}
+	}
+	if degraded == nil {
+		degraded = []string{}
+	}
+
+	identity := map[string]interface{}{
+		"uid":     os.Getuid(),
+		"euid":    os.Geteuid(),
+		"is_root": capabilities.IsRoot(),
+	}
+	if u, err := user.Current(); err == nil {
+		identity["username"] = u.Username
+		if groupIDs, err := u.GroupIds(); err == nil {
+			var groups []string
+			for _, gid := range groupIDs {
+				if g, err := user.LookupGroupId(gid); err == nil {
+					groups = append(groups, g.Name)
+				}
+			}
+			identity["groups"] = groups
+		}
+	}
+
+	sudoInfo := []map[string]interface{}{}
+	for _, cmd := range h.cfg.SudoAllowlist {
+		sudoInfo = append(sudoInfo, map[string]interface{}{
+			"command":   cmd,
+			"permitted": sudoPermitted(ctx, cmd),
+		})
+	}
+
+	result := map[string]interface{}{
+		"identity":            identity,
+		"checks":              checks,
+		"degraded_collectors": degraded,
+		"sudo_allowlist":      sudoInfo,
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// privilegedCommand builds a command, prefixing it with non-interactive sudo when the
+// server is not root and the command is on the configured sudo allowlist
+func (h *HandlerManager) privilegedCommand(ctx context.Context, name string, args ...string) *exec.Cmd {
+	if !capabilities.IsRoot() && contains(h.cfg.SudoAllowlist, name) {
+		//nolint:gosec // G204: name is restricted to the operator-configured sudo allowlist
+		return exec.CommandContext(ctx, "sudo", append([]string{"-n", name}, args...)...)
+	}
+	//nolint:gosec // G204: callers pass fixed command names
+	return exec.CommandContext(ctx, name, args...)
+}
+
+// sudoPermitted reports whether sudo allows running a command without a password prompt
+func sudoPermitted(ctx context.Context, name string) bool {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return false
+	}
+	//nolint:gosec // G204: path comes from the operator-configured sudo allowlist
+	return exec.CommandContext(ctx, "sudo", "-n", "-l", path).Run() == nil
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleGetPermissionStatus(t *testing.T) {
+	h := NewHandlerManager(&config.Config{SudoAllowlist: []string{"definitely-not-a-real-command-12345"}})
+	req := mcp.CallToolRequest{}
+	res, err := h.HandleGetPermissionStatus(context.Background(), req)
+	checkToolResult(t, res, err, []string{"identity", "checks", "degraded_collectors", "sudo_allowlist"})
+}
+
+func TestPrivilegedCommand(t *testing.T) {
+	h := NewHandlerManager(&config.Config{})
+	cmd := h.privilegedCommand(context.Background(), "docker", "ps")
+	if cmd.Args[0] != "docker" {
+		t.Errorf("Expected command without allowlist to run directly, got %v", cmd.Args)
+	}
+}
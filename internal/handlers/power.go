@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// powerSupplyRoot is the sysfs class directory for batteries, AC adapters, and UPS devices
+const powerSupplyRoot = "/sys/class/power_supply"
+
+// piHwmonGlob matches the Raspberry Pi firmware undervoltage alarm
+const piHwmonGlob = "/sys/devices/platform/soc/soc:firmware/raspberrypi-hwmon/hwmon/hwmon*/in0_lcrit_alarm"
+
+// pmicVoltagePattern matches `vcgencmd pmic_read_adc EXT5V_V` output, e.g. "EXT5V_V volt(24)=5.15350000V"
+var pmicVoltagePattern = regexp.MustCompile(`EXT5V_V volt\(\d+\)=([\d.]+)V`)
+
+// HandleGetPowerMetrics returns battery, AC adapter, and Raspberry Pi supply voltage metrics
+func (h *HandlerManager) HandleGetPowerMetrics(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	supplies := collectPowerSupplies(powerSupplyRoot)
+
+	var batteries, adapters []map[string]interface{}
+	onAC := false
+	for _, s := range supplies {
+		switch s["type"] {
+		case "Battery", "UPS":
+			batteries = append(batteries, s)
+		default:
+			adapters = append(adapters, s)
+			if s["online"] == true {
+				onAC = true
+			}
+		}
+	}
+	if batteries == nil {
+		batteries = []map[string]interface{}{}
+	}
+	if adapters == nil {
+		adapters = []map[string]interface{}{}
+	}
+
+	result := map[string]interface{}{
+		"batteries":     batteries,
+		"adapters":      adapters,
+		"has_battery":   len(batteries) > 0,
+		"on_ac_power":   onAC || (len(adapters) == 0 && len(batteries) == 0),
+		"supplies_seen": len(supplies),
+	}
+
+	if pi := h.readPiPowerStatus(ctx); pi != nil {
+		result["raspberry_pi"] = pi
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// collectPowerSupplies reads every power supply under root, sorted by name
+func collectPowerSupplies(root string) []map[string]interface{} {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+
+	var supplies []map[string]interface{}
+	for _, e := range entries {
+		supplies = append(supplies, readPowerSupply(filepath.Join(root, e.Name())))
+	}
+	sort.Slice(supplies, func(i, j int) bool {
+		return supplies[i]["name"].(string) < supplies[j]["name"].(string)
+	})
+	return supplies
+}
+
+// readPowerSupply reads the sysfs attributes of a single power supply.
+// sysfs reports micro-units (µV, µA, µW, µWh), which are converted to volts, amps, watts, and watt-hours.
+func readPowerSupply(dir string) map[string]interface{} {
+	info := map[string]interface{}{
+		"name": filepath.Base(dir),
+	}
+
+	readStr := func(attr string) (string, bool) {
+		data, err := os.ReadFile(filepath.Join(filepath.Clean(dir), attr))
+		if err != nil {
+			return "", false
+		}
+		return strings.TrimSpace(string(data)), true
+	}
+	readMicro := func(attr string) (float64, bool) {
+		s, ok := readStr(attr)
+		if !ok {
+			return 0, false
+		}
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, false
+		}
+		return v / 1e6, true
+	}
+
+	if t, ok := readStr("type"); ok {
+		info["type"] = t
+	}
+	if online, ok := readStr("online"); ok {
+		info["online"] = online == "1"
+	}
+	if status, ok := readStr("status"); ok {
+		info["status"] = status
+	}
+	if capacity, ok := readStr("capacity"); ok {
+		if v, err := strconv.Atoi(capacity); err == nil {
+			info["capacity_percent"] = v
+		}
+	}
+	if health, ok := readStr("health"); ok {
+		info["health"] = health
+	}
+	if cycles, ok := readStr("cycle_count"); ok {
+		if v, err := strconv.Atoi(cycles); err == nil {
+			info["cycle_count"] = v
+		}
+	}
+
+	voltage, hasVoltage := readMicro("voltage_now")
+	if hasVoltage {
+		info["voltage_v"] = voltage
+	}
+	current, hasCurrent := readMicro("current_now")
+	if hasCurrent {
+		info["current_a"] = current
+	}
+
+	// Charge/discharge rate: prefer power_now, otherwise derive it from current and voltage
+	power, hasPower := readMicro("power_now")
+	if !hasPower && hasCurrent && hasVoltage {
+		power, hasPower = current*voltage, true
+	}
+	if hasPower {
+		info["power_w"] = power
+	}
+
+	// Remaining energy: energy_* in µWh, or charge_* in µAh scaled by voltage
+	energyNow, hasEnergyNow := readMicro("energy_now")
+	energyFull, hasEnergyFull := readMicro("energy_full")
+	if !hasEnergyNow && hasVoltage {
+		if chargeNow, ok := readMicro("charge_now"); ok {
+			energyNow, hasEnergyNow = chargeNow*voltage, true
+		}
+		if chargeFull, ok := readMicro("charge_full"); ok {
+			energyFull, hasEnergyFull = chargeFull*voltage, true
+		}
+	}
+	if hasEnergyNow {
+		info["energy_wh"] = energyNow
+	}
+	if hasEnergyFull {
+		info["energy_full_wh"] = energyFull
+	}
+
+	// Estimate time to empty/full from the current rate
+	if hasPower && power > 0 && hasEnergyNow {
+		switch info["status"] {
+		case "Discharging":
+			info["hours_remaining"] = energyNow / power
+		case "Charging":
+			if hasEnergyFull && energyFull > energyNow {
+				info["hours_to_full"] = (energyFull - energyNow) / power
+			}
+		}
+	}
+
+	return info
+}
+
+// readPiPowerStatus reports Raspberry Pi supply voltage and undervoltage state, or nil on other hardware
+func (h *HandlerManager) readPiPowerStatus(ctx context.Context) map[string]interface{} {
+	pi := map[string]interface{}{}
+
+	if throttled, ok := config.GetThrottledStatus(ctx); ok {
+		pi["under_voltage_now"] = throttled["under_voltage_now"]
+		pi["under_voltage_occurred"] = throttled["under_voltage_occurred"]
+	}
+
+	// The firmware hwmon alarm latches when the supply drops below ~4.63V
+	if matches, err := filepath.Glob(piHwmonGlob); err == nil && len(matches) > 0 {
+		if data, err := os.ReadFile(filepath.Clean(matches[0])); err == nil {
+			pi["undervoltage_alarm"] = strings.TrimSpace(string(data)) == "1"
+		}
+	}
+
+	// Pi 5 PMIC exposes the 5V input rail
+	if h.caps.Vcgencmd {
+		if out, err := exec.CommandContext(ctx, "vcgencmd", "pmic_read_adc", "EXT5V_V").Output(); err == nil {
+			if m := pmicVoltagePattern.FindStringSubmatch(string(out)); m != nil {
+				if v, err := strconv.ParseFloat(m[1], 64); err == nil {
+					pi["input_voltage_v"] = v
+				}
+			}
+		}
+	}
+
+	if len(pi) == 0 {
+		return nil
+	}
+	return pi
+}
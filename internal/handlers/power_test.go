@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// sysfsLink marks a writeSysfsFiles value as a symlink target rather than file content
+const sysfsLink = "-> "
+
+// writeSysfsFiles creates files under dir, with subdirectories for names containing a
+// slash. A value starting with sysfsLink becomes a symlink to the rest of the value.
+func writeSysfsFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		t.Fatalf("Failed to create %s: %v", dir, err)
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatalf("Failed to create %s: %v", filepath.Dir(path), err)
+		}
+		var err error
+		if target, ok := strings.CutPrefix(content, sysfsLink); ok {
+			err = os.Symlink(target, path)
+		} else {
+			err = os.WriteFile(path, []byte(content+"\n"), 0o600)
+		}
+		if err != nil {
+			t.Fatalf("Failed to write fixture %s: %v", name, err)
+		}
+	}
+}
+
+func TestCollectPowerSupplies(t *testing.T) {
+	root := t.TempDir()
+	writeSysfsFiles(t, filepath.Join(root, "BAT0"), map[string]string{
+		"type":        "Battery",
+		"status":      "Discharging",
+		"capacity":    "80",
+		"voltage_now": "12000000",
+		"current_now": "1000000",
+		"charge_now":  "4000000",
+		"charge_full": "5000000",
+	})
+	writeSysfsFiles(t, filepath.Join(root, "AC"), map[string]string{
+		"type":   "Mains",
+		"online": "0",
+	})
+
+	supplies := collectPowerSupplies(root)
+	if len(supplies) != 2 {
+		t.Fatalf("Expected 2 supplies, got %d", len(supplies))
+	}
+
+	ac, bat := supplies[0], supplies[1]
+	if ac["name"] != "AC" || ac["online"] != false {
+		t.Errorf("Unexpected AC adapter: %v", ac)
+	}
+	if bat["capacity_percent"] != 80 {
+		t.Errorf("Expected capacity 80, got %v", bat["capacity_percent"])
+	}
+	// 1A * 12V = 12W; 4Ah * 12V = 48Wh; 48Wh / 12W = 4h
+	if p := bat["power_w"].(float64); math.Abs(p-12) > 1e-9 {
+		t.Errorf("Expected 12W, got %v", p)
+	}
+	if hrs := bat["hours_remaining"].(float64); math.Abs(hrs-4) > 1e-9 {
+		t.Errorf("Expected 4 hours remaining, got %v", hrs)
+	}
+}
+
+func TestHandleGetPowerMetrics(t *testing.T) {
+	h := NewHandlerManager(&config.Config{})
+	req := mcp.CallToolRequest{}
+	res, err := h.HandleGetPowerMetrics(context.Background(), req)
+	checkToolResult(t, res, err, []string{"batteries", "adapters", "has_battery", "on_ac_power"})
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"sysmetrics-mcp/internal/capabilities"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// Process control actions
+const (
+	processActionTerm   = "sigterm"
+	processActionKill   = "sigkill"
+	processActionRenice = "renice"
+)
+
+// processActions are the actions manage_process accepts
+var processActions = []string{processActionTerm, processActionKill, processActionRenice}
+
+// Nice value range
+const (
+	minNice = -20
+	maxNice = 19
+)
+
+// processExitWait is how long manage_process waits for a signalled process to exit
+const processExitWait = 3 * time.Second
+
+// processTarget describes the process a manage_process call would act on
+type processTarget struct {
+	PID     int32  `json:"pid"`
+	PPID    int32  `json:"ppid"`
+	Name    string `json:"name"`
+	User    string `json:"user,omitempty"`
+	UID     int32  `json:"uid"`
+	Cmdline string `json:"cmdline,omitempty"`
+	Nice    int32  `json:"nice"`
+}
+
+// lookupProcessTarget reads the details the safety guards need. A UID of -1 means the owner
+// could not be determined.
+func lookupProcessTarget(ctx context.Context, pid int32) (processTarget, error) {
+	p, err := process.NewProcessWithContext(ctx, pid)
+	if err != nil {
+		return processTarget{}, err
+	}
+	t := processTarget{PID: pid, UID: -1}
+	t.Name, _ = p.NameWithContext(ctx)
+	t.PPID, _ = p.PpidWithContext(ctx)
+	t.User, _ = p.UsernameWithContext(ctx)
+	t.Nice, _ = p.NiceWithContext(ctx)
+	if cmdline, err := p.CmdlineWithContext(ctx); err == nil {
+		t.Cmdline = truncateString(cmdline, 200)
+	}
+	if uids, err := p.UidsWithContext(ctx); err == nil && len(uids) > 1 {
+		t.UID = uids[1]
+	}
+	return t, nil
+}
+
+// processControlDenied returns why manage_process must not act on t, or "" if it may
+func (h *HandlerManager) processControlDenied(t processTarget) string {
+	switch {
+	case t.PID == 1:
+		return "PID 1 (init) is never signalled"
+	case t.PID == int32(os.Getpid()):
+		return "refusing to act on the server itself"
+	case t.PID == int32(os.Getppid()):
+		return "refusing to act on the server's parent (the MCP client)"
+	case t.PID == 2 || t.PPID == 2:
+		return "refusing to act on a kernel thread"
+	case slices.Contains(h.cfg.ProcessControlDenyPIDs, t.PID):
+		return fmt.Sprintf("PID %d is in --process-control-deny-pids", t.PID)
+	case t.User != "" && contains(h.cfg.ProcessControlDenyUsers, t.User):
+		return fmt.Sprintf("processes owned by %s are in --process-control-deny-users", t.User)
+	case t.UID == -1 && !h.cfg.ProcessControlAllowRoot:
+		return "cannot determine the process owner; refusing in case it is root-owned"
+	case t.UID == 0 && !h.cfg.ProcessControlAllowRoot:
+		return "root-owned process (start the server with --process-control-allow-root to allow)"
+	}
+	return ""
+}
+
+// HandleManageProcess sends SIGTERM or SIGKILL to a process or changes its nice value,
+// subject to the safety guards. With dry_run it only reports what would happen.
+func (h *HandlerManager) HandleManageProcess(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var pid int32
+	var action string
+	nice, hasNice := int32(0), false
+	dryRun, confirm := false, false
+
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if p, ok := args["pid"].(float64); ok {
+			pid = int32(p)
+		}
+		if a, ok := args["action"].(string); ok {
+			action = strings.ToLower(strings.TrimSpace(a))
+		}
+		if n, ok := args["nice"].(float64); ok {
+			nice, hasNice = int32(n), true
+		}
+		if d, ok := args["dry_run"].(bool); ok {
+			dryRun = d
+		}
+		if c, ok := args["confirm"].(bool); ok {
+			confirm = c
+		}
+	}
+
+	if pid <= 0 {
+		return mcp.NewToolResultError("pid must be a positive process ID"), nil
+	}
+	if !contains(processActions, action) {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid action: %q (must be sigterm, sigkill, or renice)", action)), nil
+	}
+	if action == processActionRenice && (!hasNice || nice < minNice || nice > maxNice) {
+		return mcp.NewToolResultError(fmt.Sprintf("renice requires nice between %d and %d", minNice, maxNice)), nil
+	}
+
+	target, err := lookupProcessTarget(ctx, pid)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Process %d not found: %v", pid, err)), nil
+	}
+	if reason := h.processControlDenied(target); reason != "" {
+		return mcp.NewToolResultError(fmt.Sprintf("Refusing to %s PID %d (%s): %s", action, pid, target.Name, reason)), nil
+	}
+
+	result := map[string]interface{}{
+		"process": target,
+		"action":  action,
+		"dry_run": dryRun,
+	}
+	if action == processActionRenice {
+		result["nice_before"] = target.Nice
+		result["nice_requested"] = nice
+		if nice < target.Nice && !capabilities.IsRoot() {
+			result["warning"] = "lowering the nice value needs root or CAP_SYS_NICE"
+		}
+	}
+	if !dryRun && !confirm {
+		return mcp.NewToolResultError(fmt.Sprintf("confirm must be true to %s PID %d (or use dry_run)", action, pid)), nil
+	}
+	if !dryRun {
+		if err := h.applyProcessAction(ctx, target, action, nice, result); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to %s PID %d: %v", action, pid, err)), nil
+		}
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// applyProcessAction signals or renices the target and records the outcome in result
+func (h *HandlerManager) applyProcessAction(ctx context.Context, target processTarget, action string, nice int32, result map[string]interface{}) error {
+	pid := target.PID
+	h.logger.Warn("process control", "action", action, "pid", pid, "name", target.Name, "user", target.User)
+	p, err := process.NewProcessWithContext(ctx, pid)
+	if err != nil {
+		return fmt.Errorf("process exited before the action: %w", err)
+	}
+
+	switch action {
+	case processActionTerm, processActionKill:
+		if action == processActionTerm {
+			err = p.TerminateWithContext(ctx)
+		} else {
+			err = p.KillWithContext(ctx)
+		}
+		if err != nil {
+			return err
+		}
+		exited := waitForExit(ctx, p, processExitWait)
+		result["exited"] = exited
+		if !exited && action == processActionTerm {
+			result["note"] = fmt.Sprintf("still running after %s; it may be shutting down or ignoring SIGTERM", processExitWait)
+		}
+	case processActionRenice:
+		if err := setNice(int(pid), int(nice)); err != nil {
+			return err
+		}
+		if after, err := p.NiceWithContext(ctx); err == nil {
+			result["nice_after"] = after
+		}
+	}
+	result["applied"] = true
+	return nil
+}
+
+// waitForExit polls until p is gone or wait elapses
+func waitForExit(ctx context.Context, p *process.Process, wait time.Duration) bool {
+	deadline := time.Now().Add(wait)
+	for time.Now().Before(deadline) {
+		if running, err := p.IsRunningWithContext(ctx); err != nil || !running {
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	return false
+}
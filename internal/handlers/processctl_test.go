@@ -0,0 +1,98 @@
+//go:build !windows
+
+package handlers
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestProcessControlDenied(t *testing.T) {
+	h := NewHandlerManager(&config.Config{ProcessControlDenyUsers: []string{"postgres"}, ProcessControlDenyPIDs: []int32{700}})
+
+	tests := []struct {
+		name   string
+		target processTarget
+		denied bool
+	}{
+		{"init", processTarget{PID: 1, UID: 0}, true},
+		{"server", processTarget{PID: int32(os.Getpid()), UID: 1000}, true},
+		{"client", processTarget{PID: int32(os.Getppid()), UID: 1000}, true},
+		{"kernel thread", processTarget{PID: 40, PPID: 2, UID: 0}, true},
+		{"denied user", processTarget{PID: 500, PPID: 1, UID: 999, User: "postgres"}, true},
+		{"denied pid", processTarget{PID: 700, PPID: 1, UID: 1000, User: "pi"}, true},
+		{"root owned", processTarget{PID: 500, PPID: 1, UID: 0, User: "root"}, true},
+		{"unknown owner", processTarget{PID: 500, PPID: 1, UID: -1}, true},
+		{"user process", processTarget{PID: 500, PPID: 1, UID: 1000, User: "pi"}, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if reason := h.processControlDenied(tc.target); (reason != "") != tc.denied {
+				t.Errorf("processControlDenied() = %q, want denied=%v", reason, tc.denied)
+			}
+		})
+	}
+
+	h.cfg.ProcessControlAllowRoot = true
+	if reason := h.processControlDenied(processTarget{PID: 500, PPID: 1, UID: 0}); reason != "" {
+		t.Errorf("Expected root-owned process to be allowed, got %q", reason)
+	}
+	if reason := h.processControlDenied(processTarget{PID: 1, UID: 0}); reason == "" {
+		t.Error("Expected PID 1 to stay denied with --process-control-allow-root")
+	}
+}
+
+func TestHandleManageProcess(t *testing.T) {
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("cannot start sleep: %v", err)
+	}
+	defer func() { _ = cmd.Process.Kill() }()
+	go func() { _ = cmd.Wait() }()
+	pid := float64(cmd.Process.Pid)
+
+	h := NewHandlerManager(&config.Config{ProcessControlAllowRoot: true})
+	call := func(args map[string]interface{}) *mcp.CallToolResult {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = args
+		res, err := h.HandleManageProcess(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Handler returned error: %v", err)
+		}
+		return res
+	}
+
+	for _, args := range []map[string]interface{}{
+		{"pid": pid, "action": "sigstop", "confirm": true},
+		{"pid": pid, "action": "renice", "nice": 40.0, "confirm": true},
+		{"pid": pid, "action": "sigterm"},
+		{"pid": 1.0, "action": "sigterm", "confirm": true},
+	} {
+		if res := call(args); !res.IsError {
+			t.Errorf("Expected an error for %v, got %s", args, resultText(res))
+		}
+	}
+
+	res := call(map[string]interface{}{"pid": pid, "action": "sigkill", "dry_run": true})
+	checkToolResult(t, res, nil, []string{"process", "action", "dry_run"})
+	if strings.Contains(resultText(res), `"applied"`) {
+		t.Error("Dry run must not apply the action")
+	}
+
+	res = call(map[string]interface{}{"pid": pid, "action": "renice", "nice": 10.0, "confirm": true})
+	checkToolResult(t, res, nil, []string{"nice_before", "nice_after", "applied"})
+
+	res = call(map[string]interface{}{"pid": pid, "action": "sigterm", "confirm": true})
+	checkToolResult(t, res, nil, []string{"applied", "exited"})
+	if !strings.Contains(resultText(res), `"exited":true`) {
+		t.Errorf("Expected the process to exit, got %s", resultText(res))
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+
+package handlers
+
+import "syscall"
+
+// setNice sets the scheduling priority of a process
+func setNice(pid, nice int) error {
+	return syscall.Setpriority(syscall.PRIO_PROCESS, pid, nice)
+}
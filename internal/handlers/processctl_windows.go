@@ -0,0 +1,10 @@
+//go:build windows
+
+package handlers
+
+import "errors"
+
+// setNice is not supported; Windows uses priority classes rather than nice values
+func setNice(pid, nice int) error {
+	return errors.New("renice is not supported on Windows")
+}
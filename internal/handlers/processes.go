@@ -0,0 +1,367 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// Process grouping mode constants.
+const (
+	groupByNone = "none"
+	groupByName = "name"
+)
+
+// processSorts are the orders get_process_list accepts
+var processSorts = []string{"cpu", "memory", "pid"}
+
+// processFields lists the columns get_process_list can return
+var processFields = []string{"pid", "ppid", "name", "username", "cmdline", "num_threads", "cpu_percent", "memory_percent", "rss_bytes", "status", "create_time"}
+
+// processGroupFields lists the columns get_process_list can return when grouping
+var processGroupFields = []string{"name", "count", "pids", "cpu_percent", "memory_percent", "rss_bytes"}
+
+// maxProcessCmdline bounds the command line reported for each process, in bytes
+const maxProcessCmdline = 200
+
+// maxGroupPIDs bounds the PID list reported for each process group
+const maxGroupPIDs = 20
+
+// processInfo holds the collected values for a single process
+type processInfo struct {
+	PID        int32
+	PPID       int32
+	Name       string
+	Username   string
+	Cmdline    string
+	NumThreads int32
+	CPU        float64
+	Memory     float32
+	RSS        uint64
+	Status     []string
+	CreateTime int64
+}
+
+// processGroup aggregates all processes sharing a name
+type processGroup struct {
+	Name   string
+	Count  int
+	PIDs   []int32
+	CPU    float64
+	Memory float32
+	RSS    uint64
+}
+
+// HandleGetProcessList returns process list
+func (h *HandlerManager) HandleGetProcessList(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	limit := h.cfg.MaxProcesses
+	sortBy := "cpu"
+	groupBy := groupByNone
+	var userFilter, namePattern string
+	var minCPU, minMemory float64
+
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if l, ok := args["limit"].(float64); ok && l > 0 {
+			limit = h.cfg.ClampProcessLimit(int(l))
+		}
+		if s, ok := args["sort_by"].(string); ok && s != "" {
+			sortBy = strings.ToLower(s)
+		}
+		if g, ok := args["group_by"].(string); ok && g != "" {
+			groupBy = strings.ToLower(g)
+		}
+		if u, ok := args["user"].(string); ok && u != "" {
+			userFilter = u
+		}
+		if n, ok := args["name_pattern"].(string); ok && n != "" {
+			namePattern = n
+		}
+		if c, ok := args["min_cpu"].(float64); ok && c > 0 {
+			minCPU = c
+		}
+		if m, ok := args["min_memory"].(float64); ok && m > 0 {
+			minMemory = m
+		}
+	}
+
+	args, _ := request.Params.Arguments.(map[string]interface{})
+	pg, err := parsePage("get_process_list", args, limit)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if !contains(processSorts, sortBy) {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid sort_by: %s (must be cpu, memory, or pid)", sortBy)), nil
+	}
+	if groupBy != groupByNone && groupBy != groupByName {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid group_by: %s (must be none or name)", groupBy)), nil
+	}
+	grouped := groupBy == groupByName
+
+	allowedFields := processFields
+	if grouped {
+		allowedFields = processGroupFields
+	}
+	fields, err := parseFieldsArg(request, allowedFields)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var nameRe *regexp.Regexp
+	if namePattern != "" {
+		nameRe, err = regexp.Compile(namePattern)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid name_pattern: %v", err)), nil
+		}
+	}
+
+	processes, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get processes: %v", err)), nil
+	}
+
+	// Skip per-process syscalls for fields that are neither returned nor needed for sorting, filtering, or grouping
+	needName := wantField(fields, "name") || nameRe != nil || grouped
+	needCPU := wantField(fields, "cpu_percent") || sortBy == "cpu" || minCPU > 0
+	needMemory := wantField(fields, "memory_percent") || sortBy == "memory" || minMemory > 0
+	needRSS := wantField(fields, "rss_bytes")
+	needStatus := !grouped && wantField(fields, "status")
+	needCreateTime := !grouped && wantField(fields, "create_time")
+	needPPID := !grouped && wantField(fields, "ppid")
+	needUsername := !grouped && wantField(fields, "username")
+	needCmdline := !grouped && wantField(fields, "cmdline")
+	needThreads := !grouped && wantField(fields, "num_threads")
+
+	procList := []processInfo{}
+	for _, p := range processes {
+		// Stop walking the process table once the client gives up
+		if ctx.Err() != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Process listing cancelled: %v", ctx.Err())), nil
+		}
+		info := processInfo{PID: p.Pid}
+		if userFilter != "" || needUsername {
+			username, err := p.UsernameWithContext(ctx)
+			if userFilter != "" && (err != nil || username != userFilter) {
+				continue
+			}
+			info.Username = username
+		}
+		if needName {
+			info.Name, _ = p.NameWithContext(ctx)
+			if nameRe != nil && !nameRe.MatchString(info.Name) {
+				continue
+			}
+		}
+		if needCPU {
+			info.CPU, _ = p.CPUPercentWithContext(ctx)
+			if info.CPU < minCPU {
+				continue
+			}
+		}
+		if needMemory {
+			info.Memory, _ = p.MemoryPercentWithContext(ctx)
+			if float64(info.Memory) < minMemory {
+				continue
+			}
+		}
+		if needRSS {
+			if memInfo, err := p.MemoryInfoWithContext(ctx); err == nil {
+				info.RSS = memInfo.RSS
+			}
+		}
+		if needStatus {
+			info.Status, _ = p.StatusWithContext(ctx)
+		}
+		if needCreateTime {
+			createTime, _ := p.CreateTimeWithContext(ctx)
+			info.CreateTime = createTime / 1000 // Convert from ms to seconds
+		}
+		if needPPID {
+			info.PPID, _ = p.PpidWithContext(ctx)
+		}
+		if needCmdline {
+			// Kernel threads have no command line; their name is all there is
+			if cmdline, err := p.CmdlineWithContext(ctx); err == nil {
+				info.Cmdline = truncateString(cmdline, maxProcessCmdline)
+			}
+		}
+		if needThreads {
+			info.NumThreads, _ = p.NumThreadsWithContext(ctx)
+		}
+		procList = append(procList, info)
+	}
+
+	var rows []map[string]interface{}
+	var matched int
+	var pageInfo map[string]interface{}
+	if grouped {
+		groups := groupProcessesByName(procList)
+		sortProcessGroups(groups, sortBy)
+
+		matched = len(groups)
+		pg.seek(matched, func(i int) pageKey { return groups[i].key(sortBy) })
+		pageInfo = pg.info(matched)
+		start, end := pg.bounds(matched)
+		groups = groups[start:end]
+
+		rows = make([]map[string]interface{}, 0, len(groups))
+		for _, g := range groups {
+			rows = append(rows, projectFields(map[string]interface{}{
+				"name":           g.Name,
+				"count":          g.Count,
+				"pids":           g.PIDs,
+				"cpu_percent":    g.CPU,
+				"memory_percent": g.Memory,
+				"rss_bytes":      g.RSS,
+			}, fields))
+		}
+	} else {
+		sortProcesses(procList, sortBy)
+
+		matched = len(procList)
+		pg.seek(matched, func(i int) pageKey { return procList[i].key(sortBy) })
+		pageInfo = pg.info(matched)
+		start, end := pg.bounds(matched)
+		procList = procList[start:end]
+
+		rows = make([]map[string]interface{}, 0, len(procList))
+		for _, p := range procList {
+			rows = append(rows, projectFields(map[string]interface{}{
+				"pid":            p.PID,
+				"ppid":           p.PPID,
+				"name":           p.Name,
+				"username":       p.Username,
+				"cmdline":        p.Cmdline,
+				"num_threads":    p.NumThreads,
+				"cpu_percent":    p.CPU,
+				"memory_percent": p.Memory,
+				"rss_bytes":      p.RSS,
+				"status":         p.Status,
+				"create_time":    p.CreateTime,
+			}, fields))
+		}
+	}
+
+	result := map[string]interface{}{
+		"processes": rows,
+		"total":     len(processes),
+		"matched":   matched,
+		"shown":     len(rows),
+		"sort_by":   sortBy,
+		"group_by":  groupBy,
+	}
+	maps.Copy(result, pageInfo)
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// sortProcesses orders processes by cpu (default), memory, or pid. Ties are broken by
+// PID so pages of the list do not overlap.
+func sortProcesses(procList []processInfo, sortBy string) {
+	switch sortBy {
+	case "memory":
+		sort.Slice(procList, func(i, j int) bool {
+			if procList[i].Memory != procList[j].Memory {
+				return procList[i].Memory > procList[j].Memory
+			}
+			return procList[i].PID < procList[j].PID
+		})
+	case "pid":
+		sort.Slice(procList, func(i, j int) bool {
+			return procList[i].PID < procList[j].PID
+		})
+	default: // cpu
+		sort.Slice(procList, func(i, j int) bool {
+			if procList[i].CPU != procList[j].CPU {
+				return procList[i].CPU > procList[j].CPU
+			}
+			return procList[i].PID < procList[j].PID
+		})
+	}
+}
+
+// key returns the process's position in the sortProcesses order
+func (p processInfo) key(sortBy string) pageKey {
+	switch sortBy {
+	case "memory":
+		return pageKey{Value: float64(p.Memory), ID: int64(p.PID)}
+	case "pid":
+		return pageKey{ID: int64(p.PID)}
+	default: // cpu
+		return pageKey{Value: p.CPU, ID: int64(p.PID)}
+	}
+}
+
+// groupProcessesByName aggregates processes sharing a name into one row each.
+// procList is reordered by PID so each group's PID list starts with its lowest PID.
+func groupProcessesByName(procList []processInfo) []processGroup {
+	sortProcesses(procList, "pid")
+
+	index := make(map[string]int)
+	var groups []processGroup
+	for _, p := range procList {
+		i, ok := index[p.Name]
+		if !ok {
+			i = len(groups)
+			index[p.Name] = i
+			groups = append(groups, processGroup{Name: p.Name})
+		}
+		g := &groups[i]
+		g.Count++
+		g.CPU += p.CPU
+		g.Memory += p.Memory
+		g.RSS += p.RSS
+		if len(g.PIDs) < maxGroupPIDs {
+			g.PIDs = append(g.PIDs, p.PID)
+		}
+	}
+	return groups
+}
+
+// sortProcessGroups orders groups by summed cpu (default), summed memory, or lowest pid.
+// Ties are broken by lowest PID, which is unique to each group.
+func sortProcessGroups(groups []processGroup, sortBy string) {
+	switch sortBy {
+	case "memory":
+		sort.Slice(groups, func(i, j int) bool {
+			if groups[i].Memory != groups[j].Memory {
+				return groups[i].Memory > groups[j].Memory
+			}
+			return groups[i].PIDs[0] < groups[j].PIDs[0]
+		})
+	case "pid":
+		sort.Slice(groups, func(i, j int) bool {
+			return groups[i].PIDs[0] < groups[j].PIDs[0]
+		})
+	default: // cpu
+		sort.Slice(groups, func(i, j int) bool {
+			if groups[i].CPU != groups[j].CPU {
+				return groups[i].CPU > groups[j].CPU
+			}
+			return groups[i].PIDs[0] < groups[j].PIDs[0]
+		})
+	}
+}
+
+// key returns the group's position in the sortProcessGroups order
+func (g processGroup) key(sortBy string) pageKey {
+	switch sortBy {
+	case "memory":
+		return pageKey{Value: float64(g.Memory), ID: int64(g.PIDs[0])}
+	case "pid":
+		return pageKey{ID: int64(g.PIDs[0])}
+	default: // cpu
+		return pageKey{Value: g.CPU, ID: int64(g.PIDs[0])}
+	}
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestGroupProcessesByName(t *testing.T) {
+	procList := []processInfo{
+		{PID: 30, Name: "php-fpm", CPU: 1.5, Memory: 2, RSS: 100},
+		{PID: 10, Name: "php-fpm", CPU: 2.5, Memory: 3, RSS: 200},
+		{PID: 20, Name: "nginx", CPU: 0.5, Memory: 1, RSS: 50},
+	}
+
+	groups := groupProcessesByName(procList)
+	sortProcessGroups(groups, "cpu")
+
+	if len(groups) != 2 {
+		t.Fatalf("Expected 2 groups, got %d", len(groups))
+	}
+	php := groups[0]
+	if php.Name != "php-fpm" || php.Count != 2 || php.CPU != 4 || php.RSS != 300 {
+		t.Errorf("Unexpected php-fpm group: %+v", php)
+	}
+	if php.PIDs[0] != 10 || php.PIDs[1] != 30 {
+		t.Errorf("Expected PIDs sorted ascending, got %v", php.PIDs)
+	}
+
+	sortProcessGroups(groups, "pid")
+	if groups[0].Name != "php-fpm" {
+		t.Errorf("Expected php-fpm (lowest PID 10) first when sorting by pid, got %s", groups[0].Name)
+	}
+}
+
+func TestHandleGetProcessListGrouped(t *testing.T) {
+	h := NewHandlerManager(&config.Config{MaxProcesses: 5})
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{
+				"group_by": "name",
+				"fields":   "name,count",
+			},
+		},
+	}
+	res, err := h.HandleGetProcessList(context.Background(), req)
+	checkToolResult(t, res, err, []string{"processes", "matched", "group_by"})
+
+	req.Params.Arguments = map[string]interface{}{"group_by": "user"}
+	res, err = h.HandleGetProcessList(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if !res.IsError {
+		t.Error("Expected error result for unsupported group_by")
+	}
+}
+
+func TestHandleGetProcessListInvalidSort(t *testing.T) {
+	h := NewHandlerManager(&config.Config{MaxProcesses: 5})
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{"sort_by": "rss"},
+		},
+	}
+	res, err := h.HandleGetProcessList(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if !res.IsError {
+		t.Error("Expected error result for unsupported sort_by")
+	}
+}
+
+func TestHandleGetProcessListCancelled(t *testing.T) {
+	h := NewHandlerManager(&config.Config{MaxProcesses: 5})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	res, err := h.HandleGetProcessList(ctx, mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if !res.IsError {
+		t.Error("Expected error result for a cancelled request")
+	}
+}
+
+func TestHandleGetProcessListDetails(t *testing.T) {
+	h := NewHandlerManager(&config.Config{MaxProcesses: 5, MaxProcessesCap: 100000})
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{"sort_by": "pid", "limit": float64(100000)},
+		},
+	}
+	res, err := h.HandleGetProcessList(context.Background(), req)
+	checkToolResult(t, res, err, []string{"processes"})
+
+	var result struct {
+		Processes []struct {
+			PID        int32  `json:"pid"`
+			PPID       int32  `json:"ppid"`
+			Username   string `json:"username"`
+			Cmdline    string `json:"cmdline"`
+			NumThreads int32  `json:"num_threads"`
+		} `json:"processes"`
+	}
+	if err := json.Unmarshal([]byte(resultText(res)), &result); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range result.Processes {
+		if p.PID != int32(os.Getpid()) {
+			continue
+		}
+		if p.PPID != int32(os.Getppid()) || p.Cmdline == "" || p.NumThreads < 1 {
+			t.Errorf("Unexpected details for the test process: %+v", p)
+		}
+		if len(p.Cmdline) > maxProcessCmdline+len("...") {
+			t.Errorf("Expected cmdline truncated to %d bytes, got %d", maxProcessCmdline, len(p.Cmdline))
+		}
+		return
+	}
+	t.Error("Test process not found in the process list")
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// proxmoxQueryTimeout bounds the pvesh calls. pvesh starts a Perl interpreter, which
+// takes a second or two on small hosts.
+const proxmoxQueryTimeout = 20 * time.Second
+
+// Proxmox guest types as reported by the cluster resources API
+const (
+	proxmoxTypeVM = "qemu"
+	proxmoxTypeCT = "lxc"
+)
+
+// proxmoxResource is a guest entry of `pvesh get /cluster/resources --type vm`. Counters
+// are absent for stopped guests.
+type proxmoxResource struct {
+	ID        string  `json:"id"`
+	VMID      int     `json:"vmid"`
+	Name      string  `json:"name"`
+	Node      string  `json:"node"`
+	Type      string  `json:"type"`
+	Status    string  `json:"status"`
+	Template  int     `json:"template"`
+	HAState   string  `json:"hastate"`
+	Pool      string  `json:"pool"`
+	Tags      string  `json:"tags"`
+	CPU       float64 `json:"cpu"`
+	MaxCPU    float64 `json:"maxcpu"`
+	Mem       uint64  `json:"mem"`
+	MaxMem    uint64  `json:"maxmem"`
+	Disk      uint64  `json:"disk"`
+	MaxDisk   uint64  `json:"maxdisk"`
+	NetIn     uint64  `json:"netin"`
+	NetOut    uint64  `json:"netout"`
+	DiskRead  uint64  `json:"diskread"`
+	DiskWrite uint64  `json:"diskwrite"`
+	Uptime    int64   `json:"uptime"`
+}
+
+// proxmoxGuest is the per-guest summary returned by get_proxmox_guests
+type proxmoxGuest struct {
+	VMID            int      `json:"vmid"`
+	Name            string   `json:"name"`
+	Type            string   `json:"type"`
+	Node            string   `json:"node"`
+	Status          string   `json:"status"`
+	Template        bool     `json:"template,omitempty"`
+	HAState         string   `json:"ha_state,omitempty"`
+	Pool            string   `json:"pool,omitempty"`
+	Tags            []string `json:"tags,omitempty"`
+	CPUs            float64  `json:"cpus"`
+	CPUPercent      float64  `json:"cpu_percent"`
+	MemoryBytes     uint64   `json:"memory_bytes"`
+	MemoryMaxBytes  uint64   `json:"memory_max_bytes"`
+	MemoryPercent   float64  `json:"memory_percent"`
+	MemoryHuman     string   `json:"memory_human"`
+	DiskBytes       uint64   `json:"disk_bytes"`
+	DiskMaxBytes    uint64   `json:"disk_max_bytes"`
+	NetInBytes      uint64   `json:"net_in_bytes"`
+	NetOutBytes     uint64   `json:"net_out_bytes"`
+	DiskReadBytes   uint64   `json:"disk_read_bytes"`
+	DiskWriteBytes  uint64   `json:"disk_write_bytes"`
+	UptimeSeconds   int64    `json:"uptime_seconds"`
+	UptimeFormatted string   `json:"uptime_formatted,omitempty"`
+}
+
+// HandleGetProxmoxGuests lists the Proxmox VE virtual machines and containers on this
+// node, or across the cluster, with status and resource usage
+func (h *HandlerManager) HandleGetProxmoxGuests(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var typeFilter, statusFilter string
+	allNodes, includeTemplates := false, false
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if t, ok := args["type"].(string); ok {
+			typeFilter = strings.ToLower(t)
+		}
+		if s, ok := args["status"].(string); ok {
+			statusFilter = strings.ToLower(s)
+		}
+		if a, ok := args["all_nodes"].(bool); ok {
+			allNodes = a
+		}
+		if t, ok := args["include_templates"].(bool); ok {
+			includeTemplates = t
+		}
+	}
+	if typeFilter != "" && typeFilter != proxmoxTypeVM && typeFilter != proxmoxTypeCT {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid type %q: expected %s or %s", typeFilter, proxmoxTypeVM, proxmoxTypeCT)), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, proxmoxQueryTimeout)
+	defer cancel()
+
+	out, err := h.privilegedCommand(ctx, "pvesh", "get", "/cluster/resources", "--type", "vm", "--output-format", "json").Output()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to query Proxmox guests: %v (pvesh must run as root; add it to --sudo-allowlist when the server runs unprivileged)", err)), nil
+	}
+	var resources []proxmoxResource
+	if err := json.Unmarshal(out, &resources); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to parse pvesh output: %v", err)), nil
+	}
+
+	// Proxmox names each node after the host's short hostname
+	node, _ := os.Hostname()
+	node, _, _ = strings.Cut(node, ".")
+
+	guests := []proxmoxGuest{}
+	running := map[string]int{proxmoxTypeVM: 0, proxmoxTypeCT: 0}
+	totals := map[string]int{proxmoxTypeVM: 0, proxmoxTypeCT: 0}
+	for _, r := range resources {
+		if (!allNodes && r.Node != node) || (typeFilter != "" && r.Type != typeFilter) ||
+			(statusFilter != "" && r.Status != statusFilter) || (r.Template == 1 && !includeTemplates) {
+			continue
+		}
+		g := h.proxmoxGuest(r)
+		totals[r.Type]++
+		if r.Status == "running" {
+			running[r.Type]++
+		}
+		guests = append(guests, g)
+	}
+	sort.Slice(guests, func(i, j int) bool { return guests[i].VMID < guests[j].VMID })
+
+	result := map[string]interface{}{
+		"guests":     guests,
+		"total":      len(guests),
+		"vms":        map[string]int{"total": totals[proxmoxTypeVM], "running": running[proxmoxTypeVM]},
+		"containers": map[string]int{"total": totals[proxmoxTypeCT], "running": running[proxmoxTypeCT]},
+	}
+	if !allNodes {
+		result["node"] = node
+	}
+	if out, err := h.privilegedCommand(ctx, "pvesh", "get", "/version", "--output-format", "json").Output(); err == nil {
+		var version struct {
+			Version string `json:"version"`
+		}
+		if json.Unmarshal(out, &version) == nil && version.Version != "" {
+			result["pve_version"] = version.Version
+		}
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// proxmoxGuest summarizes a cluster resource. cpu is the share of the guest's own CPUs in
+// use, as the Proxmox web interface shows it.
+func (h *HandlerManager) proxmoxGuest(r proxmoxResource) proxmoxGuest {
+	g := proxmoxGuest{
+		VMID:           r.VMID,
+		Name:           r.Name,
+		Type:           r.Type,
+		Node:           r.Node,
+		Status:         r.Status,
+		Template:       r.Template == 1,
+		HAState:        r.HAState,
+		Pool:           r.Pool,
+		CPUs:           r.MaxCPU,
+		CPUPercent:     round2(r.CPU * 100),
+		MemoryBytes:    r.Mem,
+		MemoryMaxBytes: r.MaxMem,
+		MemoryHuman:    h.human.Bytes(r.Mem),
+		DiskBytes:      r.Disk,
+		DiskMaxBytes:   r.MaxDisk,
+		NetInBytes:     r.NetIn,
+		NetOutBytes:    r.NetOut,
+		DiskReadBytes:  r.DiskRead,
+		DiskWriteBytes: r.DiskWrite,
+		UptimeSeconds:  r.Uptime,
+	}
+	if r.MaxMem > 0 {
+		g.MemoryPercent = round2(float64(r.Mem) / float64(r.MaxMem) * 100)
+	}
+	if r.Uptime > 0 {
+		g.UptimeFormatted = h.human.Duration(time.Duration(r.Uptime) * time.Second)
+	}
+	// Tags are separated by semicolons, or by commas and spaces in older releases
+	g.Tags = strings.FieldsFunc(r.Tags, func(c rune) bool { return c == ';' || c == ',' || c == ' ' })
+	return g
+}
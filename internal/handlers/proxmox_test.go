@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+)
+
+func TestProxmoxGuest(t *testing.T) {
+	// Abridged `pvesh get /cluster/resources --type vm --output-format json` output
+	out := `[
+		{"id":"qemu/100","vmid":100,"name":"homeassistant","node":"pve","type":"qemu","status":"running",
+		 "cpu":0.0712,"maxcpu":2,"mem":1073741824,"maxmem":4294967296,"disk":0,"maxdisk":34359738368,
+		 "netin":123456,"netout":654321,"diskread":1000,"diskwrite":2000,"uptime":90061,"template":0,
+		 "tags":"prod;iot","hastate":"started"},
+		{"id":"lxc/101","vmid":101,"name":"pihole","node":"pve","type":"lxc","status":"stopped",
+		 "maxcpu":1,"maxmem":536870912,"maxdisk":8589934592,"template":0}
+	]`
+	var resources []proxmoxResource
+	if err := json.Unmarshal([]byte(out), &resources); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewHandlerManager(&config.Config{})
+	vm := h.proxmoxGuest(resources[0])
+	if vm.VMID != 100 || vm.CPUPercent != 7.12 || vm.MemoryPercent != 25 || vm.HAState != "started" || vm.UptimeSeconds != 90061 {
+		t.Errorf("Unexpected VM: %+v", vm)
+	}
+	if !slices.Equal(vm.Tags, []string{"prod", "iot"}) || vm.UptimeFormatted == "" {
+		t.Errorf("Unexpected tags or uptime: %+v", vm)
+	}
+
+	ct := h.proxmoxGuest(resources[1])
+	if ct.Type != proxmoxTypeCT || ct.Status != "stopped" || ct.MemoryBytes != 0 || len(ct.Tags) != 0 || ct.UptimeFormatted != "" {
+		t.Errorf("Unexpected container: %+v", ct)
+	}
+}
@@ -0,0 +1,372 @@
+package handlers
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// mdstatPath lists the Linux software RAID (md) arrays and their sync progress
+var mdstatPath = "/proc/mdstat"
+
+// mdSysfsPath has a md/ directory per array with its mismatch and sync counters
+var mdSysfsPath = "/sys/block"
+
+// Array and pool health, worst last
+const (
+	raidOK         = "ok"
+	raidRebuilding = "rebuilding"
+	raidDegraded   = "degraded"
+	raidInactive   = "inactive"
+	raidFailed     = "failed"
+)
+
+// mdMemberRe matches an array member such as sdb1[1] or sdc1[2](F)
+var mdMemberRe = regexp.MustCompile(`^(\S+?)\[(\d+)\]((?:\([A-Z]\))*)$`)
+
+// mdDisksRe matches the [raid disks/active disks] and [UU_] fields of an array's status line
+var (
+	mdDisksRe  = regexp.MustCompile(`\[(\d+)/(\d+)\]`)
+	mdSlotsRe  = regexp.MustCompile(`\[([U_]+)\]`)
+	mdBlocksRe = regexp.MustCompile(`^(\d+) blocks`)
+)
+
+// mdSyncRe matches a sync progress line, e.g.
+// [=>....]  recovery =  8.5% (83151872/976630272) finish=95.3min speed=156160K/sec
+var mdSyncRe = regexp.MustCompile(`(resync|recovery|check|repair|reshape)\s*=\s*([\d.]+)%\s*\((\d+)/(\d+)\)(?:\s*finish=([\d.]+)min)?(?:\s*speed=(\d+)K/sec)?`)
+
+// mdSyncWaitRe matches a sync that is queued behind another array, e.g. resync=DELAYED
+var mdSyncWaitRe = regexp.MustCompile(`(resync|recovery|check|repair|reshape)\s*=\s*(DELAYED|PENDING)`)
+
+// mdMemberRoles maps the member flags in /proc/mdstat to roles
+var mdMemberRoles = map[string]string{
+	"F": "faulty", "S": "spare", "W": "write_mostly", "J": "journal", "R": "replacement",
+}
+
+// raidMember is one device of an array
+type raidMember struct {
+	Device string `json:"device"`
+	Slot   int    `json:"slot"`
+	Role   string `json:"role"`
+}
+
+// raidSync is a resync, recovery, check, repair, or reshape in progress or queued
+type raidSync struct {
+	Action        string  `json:"action"`
+	Percent       float64 `json:"percent"`
+	DoneBlocks    uint64  `json:"done_blocks,omitempty"`
+	TotalBlocks   uint64  `json:"total_blocks,omitempty"`
+	FinishMinutes float64 `json:"finish_minutes,omitempty"`
+	SpeedKBps     uint64  `json:"speed_kbps,omitempty"`
+	Waiting       string  `json:"waiting,omitempty"`
+}
+
+// mdadmDevice is one row of the device table from mdadm --detail
+type mdadmDevice struct {
+	Device     string `json:"device,omitempty"`
+	RaidDevice string `json:"raid_device"`
+	State      string `json:"state"`
+}
+
+// mdadmDetail is the subset of mdadm --detail output that mdstat lacks
+type mdadmDetail struct {
+	State      string        `json:"state,omitempty"`
+	UUID       string        `json:"uuid,omitempty"`
+	Name       string        `json:"name,omitempty"`
+	Events     string        `json:"events,omitempty"`
+	UpdateTime string        `json:"update_time,omitempty"`
+	Devices    []mdadmDevice `json:"devices,omitempty"`
+}
+
+// raidArray is one md array as reported by /proc/mdstat, sysfs, and optionally mdadm
+type raidArray struct {
+	Name           string       `json:"name"`
+	Health         string       `json:"health"`
+	State          string       `json:"state"`
+	ReadOnly       bool         `json:"read_only,omitempty"`
+	Level          string       `json:"level,omitempty"`
+	SizeBytes      uint64       `json:"size_bytes,omitempty"`
+	RaidDisks      int          `json:"raid_disks,omitempty"`
+	ActiveDisks    int          `json:"active_disks,omitempty"`
+	Degraded       bool         `json:"degraded"`
+	MissingSlots   []int        `json:"missing_slots,omitempty"`
+	FaultyMembers  []string     `json:"faulty_members,omitempty"`
+	Members        []raidMember `json:"members"`
+	Sync           *raidSync    `json:"sync,omitempty"`
+	ArrayState     string       `json:"array_state,omitempty"`
+	MismatchCount  *uint64      `json:"mismatch_count,omitempty"`
+	LastSyncAction string       `json:"last_sync_action,omitempty"`
+	Detail         *mdadmDetail `json:"detail,omitempty"`
+}
+
+// HandleGetRAIDStatus reports the state of Linux software RAID arrays: degraded and faulty
+// members, resync/recovery progress, and mismatch counts from the last check
+func (h *HandlerManager) HandleGetRAIDStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var only string
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if a, ok := args["array"].(string); ok {
+			only = strings.TrimPrefix(strings.TrimSpace(a), "/dev/")
+		}
+	}
+
+	arrays, err := readMdstat(mdstatPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read %s: %v", mdstatPath, err)), nil
+	}
+	if only != "" {
+		var names []string
+		filtered := arrays[:0]
+		for _, a := range arrays {
+			names = append(names, a.Name)
+			if a.Name == only {
+				filtered = append(filtered, a)
+			}
+		}
+		if len(filtered) == 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("No md array named %q (found: %s)", only, strings.Join(names, ", "))), nil
+		}
+		arrays = filtered
+	}
+
+	result := map[string]interface{}{}
+	status := statusHealthy
+	warnings := []string{}
+	for i := range arrays {
+		a := &arrays[i]
+		readMdSysfs(a)
+		if h.caps.Mdadm {
+			if _, failed := result["mdadm_error"]; !failed {
+				// mdadm opens the block device, which needs root or a sudo allowlist entry
+				out, err := h.privilegedCommand(ctx, "mdadm", "--detail", "/dev/"+a.Name).Output()
+				if err != nil {
+					result["mdadm_error"] = fmt.Sprintf("mdadm --detail failed: %v (run as root or add mdadm to --sudo-allowlist)", err)
+				} else {
+					a.Detail = parseMdadmDetail(string(out))
+				}
+			}
+		}
+		a.Health = raidHealth(*a)
+		warnings = append(warnings, raidWarnings(*a)...)
+		switch {
+		case a.Health == raidDegraded || a.Health == raidFailed || a.Health == raidInactive:
+			status = statusCritical
+		case a.Health == raidRebuilding || len(a.FaultyMembers) > 0:
+			if status != statusCritical {
+				status = statusWarning
+			}
+		}
+	}
+	if arrays == nil {
+		arrays = []raidArray{}
+	}
+	result["status"] = status
+	result["arrays"] = arrays
+	result["array_count"] = len(arrays)
+	result["warnings"] = warnings
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// readMdstat parses /proc/mdstat into one entry per array
+func readMdstat(path string) ([]raidArray, error) {
+	data, err := readTrimmed(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseMdstat(data), nil
+}
+
+// parseMdstat parses the contents of /proc/mdstat
+func parseMdstat(data string) []raidArray {
+	var arrays []raidArray
+	var cur *raidArray
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			cur = nil
+		case strings.HasPrefix(line, "md") && strings.Contains(line, " : "):
+			name, rest, _ := strings.Cut(line, " : ")
+			arrays = append(arrays, parseMdHeader(strings.TrimSpace(name), strings.Fields(rest)))
+			cur = &arrays[len(arrays)-1]
+		case cur == nil:
+			// Personalities and unused devices
+		case mdBlocksRe.MatchString(trimmed):
+			m := mdBlocksRe.FindStringSubmatch(trimmed)
+			blocks, _ := strconv.ParseUint(m[1], 10, 64)
+			cur.SizeBytes = blocks * 1024
+			if d := mdDisksRe.FindStringSubmatch(trimmed); d != nil {
+				cur.RaidDisks, _ = strconv.Atoi(d[1])
+				cur.ActiveDisks, _ = strconv.Atoi(d[2])
+				cur.Degraded = cur.ActiveDisks < cur.RaidDisks
+			}
+			if s := mdSlotsRe.FindStringSubmatch(trimmed); s != nil {
+				for i, c := range s[1] {
+					if c == '_' {
+						cur.MissingSlots = append(cur.MissingSlots, i)
+					}
+				}
+			}
+		case mdSyncRe.MatchString(trimmed):
+			m := mdSyncRe.FindStringSubmatch(trimmed)
+			sync := &raidSync{Action: m[1]}
+			sync.Percent, _ = strconv.ParseFloat(m[2], 64)
+			sync.DoneBlocks, _ = strconv.ParseUint(m[3], 10, 64)
+			sync.TotalBlocks, _ = strconv.ParseUint(m[4], 10, 64)
+			sync.FinishMinutes, _ = strconv.ParseFloat(m[5], 64)
+			sync.SpeedKBps, _ = strconv.ParseUint(m[6], 10, 64)
+			cur.Sync = sync
+		case mdSyncWaitRe.MatchString(trimmed):
+			m := mdSyncWaitRe.FindStringSubmatch(trimmed)
+			cur.Sync = &raidSync{Action: m[1], Waiting: strings.ToLower(m[2])}
+		}
+	}
+	return arrays
+}
+
+// parseMdHeader parses the fields after "md0 : ", e.g.
+// active (auto-read-only) raid1 sdb1[1] sda1[0](F)
+func parseMdHeader(name string, fields []string) raidArray {
+	a := raidArray{Name: name, Members: []raidMember{}}
+	for i, f := range fields {
+		switch {
+		case i == 0:
+			a.State = f
+		case f == "(read-only)" || f == "(auto-read-only)":
+			a.ReadOnly = true
+		case mdMemberRe.MatchString(f):
+			m := mdMemberRe.FindStringSubmatch(f)
+			member := raidMember{Device: m[1], Role: "active"}
+			member.Slot, _ = strconv.Atoi(m[2])
+			for _, flag := range strings.Split(strings.Trim(m[3], "()"), ")(") {
+				// A faulty flag outranks the others
+				if role, ok := mdMemberRoles[flag]; ok && member.Role != "faulty" {
+					member.Role = role
+				}
+			}
+			if member.Role == "faulty" {
+				a.FaultyMembers = append(a.FaultyMembers, member.Device)
+			}
+			a.Members = append(a.Members, member)
+		case a.Level == "":
+			a.Level = f
+		}
+	}
+	return a
+}
+
+// readMdSysfs adds the array state and mismatch counters the kernel keeps in sysfs
+func readMdSysfs(a *raidArray) {
+	dir := filepath.Join(mdSysfsPath, a.Name, "md")
+	if v, err := readTrimmed(filepath.Join(dir, "array_state")); err == nil {
+		a.ArrayState = v
+	}
+	if v, err := readTrimmed(filepath.Join(dir, "mismatch_cnt")); err == nil {
+		if n, err := strconv.ParseUint(v, 10, 64); err == nil {
+			a.MismatchCount = &n
+		}
+	}
+	if v, err := readTrimmed(filepath.Join(dir, "last_sync_action")); err == nil && v != "none" {
+		a.LastSyncAction = v
+	}
+}
+
+// parseMdadmDetail parses `mdadm --detail` output
+func parseMdadmDetail(output string) *mdadmDetail {
+	d := &mdadmDetail{}
+	inTable := false
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "Number") && strings.Contains(trimmed, "RaidDevice") {
+			inTable = true
+			continue
+		}
+		if inTable {
+			fields := strings.Fields(trimmed)
+			if len(fields) < 5 {
+				continue
+			}
+			dev := mdadmDevice{RaidDevice: fields[3]}
+			state := fields[4:]
+			if last := state[len(state)-1]; strings.HasPrefix(last, "/dev/") {
+				dev.Device, state = last, state[:len(state)-1]
+			}
+			dev.State = strings.Join(state, " ")
+			d.Devices = append(d.Devices, dev)
+			continue
+		}
+		key, value, ok := strings.Cut(trimmed, " : ")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "State":
+			d.State = value
+		case "UUID":
+			d.UUID = value
+		case "Name":
+			d.Name, _, _ = strings.Cut(value, "  ")
+		case "Events":
+			d.Events = value
+		case "Update Time":
+			d.UpdateTime = value
+		}
+	}
+	return d
+}
+
+// raidHealth summarizes an array: failed when no member is active, degraded when a
+// member is missing, rebuilding while a missing member is being recovered
+func raidHealth(a raidArray) string {
+	switch {
+	case a.State == "inactive":
+		return raidInactive
+	case a.RaidDisks > 0 && a.ActiveDisks == 0:
+		return raidFailed
+	case a.RaidDisks == 0 && len(a.FaultyMembers) > 0:
+		// RAID 0 and linear arrays cannot lose a member
+		return raidFailed
+	case a.Degraded && a.Sync != nil && (a.Sync.Action == "recovery" || a.Sync.Action == "reshape"):
+		return raidRebuilding
+	case a.Degraded:
+		return raidDegraded
+	}
+	return raidOK
+}
+
+// raidWarnings describes what needs attention on an array
+func raidWarnings(a raidArray) []string {
+	var warnings []string
+	switch a.Health {
+	case raidInactive:
+		warnings = append(warnings, fmt.Sprintf("%s is inactive; it was not assembled", a.Name))
+	case raidFailed:
+		warnings = append(warnings, fmt.Sprintf("%s (%s) has failed", a.Name, a.Level))
+	case raidDegraded:
+		warnings = append(warnings, fmt.Sprintf("%s (%s) is degraded: %d of %d devices active", a.Name, a.Level, a.ActiveDisks, a.RaidDisks))
+	case raidRebuilding:
+		warnings = append(warnings, fmt.Sprintf("%s (%s) is rebuilding: %.1f%% done", a.Name, a.Level, a.Sync.Percent))
+	}
+	if len(a.FaultyMembers) > 0 {
+		warnings = append(warnings, fmt.Sprintf("%s has faulty members: %s", a.Name, strings.Join(a.FaultyMembers, ", ")))
+	}
+	if a.MismatchCount != nil && *a.MismatchCount > 0 && a.Sync == nil {
+		warnings = append(warnings, fmt.Sprintf("%s has %d mismatched sectors from the last %s", a.Name, *a.MismatchCount, cmp.Or(a.LastSyncAction, "check")))
+	}
+	return warnings
+}
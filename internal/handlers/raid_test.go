@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const testMdstat = `Personalities : [raid1] [raid6] [raid5] [raid4] [raid0]
+md0 : active raid1 sdb1[1] sda1[0]
+      976630336 blocks super 1.2 [2/2] [UU]
+      bitmap: 0/8 pages [0KB], 65536KB chunk
+
+md1 : active raid5 sde1[3] sdd1[1] sdc1[0](F)
+      1953260544 blocks super 1.2 level 5, 512k chunk, algorithm 2 [3/2] [_UU]
+      [=>...................]  recovery =  8.5% (83151872/976630272) finish=95.3min speed=156160K/sec
+
+md2 : active (auto-read-only) raid1 sdg1[1] sdf1[0](W)
+      1000000 blocks super 1.2 [2/1] [U_]
+      	resync=DELAYED
+
+md3 : inactive sdh1[0](S)
+      976630336 blocks super 1.2
+
+md4 : active raid0 sdj1[1] sdi1[0]
+      1953260544 blocks super 1.2 512k chunks
+
+unused devices: <none>
+`
+
+func TestParseMdstat(t *testing.T) {
+	arrays := parseMdstat(testMdstat)
+	if len(arrays) != 5 {
+		t.Fatalf("Expected 5 arrays, got %+v", arrays)
+	}
+
+	md0 := arrays[0]
+	if md0.Level != "raid1" || md0.RaidDisks != 2 || md0.ActiveDisks != 2 || md0.Degraded || md0.SizeBytes != 976630336*1024 {
+		t.Errorf("Unexpected md0: %+v", md0)
+	}
+	if raidHealth(md0) != raidOK {
+		t.Errorf("Expected md0 to be ok, got %s", raidHealth(md0))
+	}
+
+	md1 := arrays[1]
+	if !md1.Degraded || !reflect.DeepEqual(md1.MissingSlots, []int{0}) || !reflect.DeepEqual(md1.FaultyMembers, []string{"sdc1"}) {
+		t.Errorf("Unexpected md1 members: %+v", md1)
+	}
+	if s := md1.Sync; s == nil || s.Action != "recovery" || s.Percent != 8.5 || s.FinishMinutes != 95.3 || s.SpeedKBps != 156160 || s.TotalBlocks != 976630272 {
+		t.Errorf("Unexpected md1 sync: %+v", md1.Sync)
+	}
+	if raidHealth(md1) != raidRebuilding {
+		t.Errorf("Expected md1 to be rebuilding, got %s", raidHealth(md1))
+	}
+
+	md2 := arrays[2]
+	if !md2.ReadOnly || md2.Level != "raid1" || md2.Members[1].Role != "write_mostly" || md2.Sync == nil || md2.Sync.Waiting != "delayed" {
+		t.Errorf("Unexpected md2: %+v (sync %+v)", md2, md2.Sync)
+	}
+	if raidHealth(md2) != raidDegraded {
+		t.Errorf("Expected md2 to be degraded, got %s", raidHealth(md2))
+	}
+
+	md3 := arrays[3]
+	if md3.State != "inactive" || md3.Level != "" || len(md3.Members) != 1 || md3.Members[0].Role != "spare" || raidHealth(md3) != raidInactive {
+		t.Errorf("Unexpected md3: %+v", md3)
+	}
+
+	md4 := arrays[4]
+	if md4.Level != "raid0" || md4.RaidDisks != 0 || md4.Degraded || raidHealth(md4) != raidOK {
+		t.Errorf("Unexpected md4: %+v", md4)
+	}
+}
+
+func TestParseMdadmDetail(t *testing.T) {
+	out := `/dev/md1:
+           Version : 1.2
+        Raid Level : raid5
+       Update Time : Sat Oct 10 09:12:44 2026
+             State : clean, degraded, recovering
+              Name : nas:1  (local to host nas)
+              UUID : 1a2b3c4d:5e6f7a8b:9c0d1e2f:3a4b5c6d
+            Events : 4821
+
+    Number   Major   Minor   RaidDevice State
+       3       8       65        0      spare rebuilding   /dev/sde1
+       1       8       49        1      active sync   /dev/sdd1
+       -       0        0        2      removed
+
+       0       8       33        -      faulty   /dev/sdc1
+`
+	d := parseMdadmDetail(out)
+	if d.State != "clean, degraded, recovering" || d.Name != "nas:1" || d.Events != "4821" || d.UpdateTime != "Sat Oct 10 09:12:44 2026" {
+		t.Errorf("Unexpected detail: %+v", d)
+	}
+	want := []mdadmDevice{
+		{Device: "/dev/sde1", RaidDevice: "0", State: "spare rebuilding"},
+		{Device: "/dev/sdd1", RaidDevice: "1", State: "active sync"},
+		{RaidDevice: "2", State: "removed"},
+		{Device: "/dev/sdc1", RaidDevice: "-", State: "faulty"},
+	}
+	if !reflect.DeepEqual(d.Devices, want) {
+		t.Errorf("Devices = %+v, want %+v", d.Devices, want)
+	}
+}
+
+func TestHandleGetRAIDStatus(t *testing.T) {
+	dir := t.TempDir()
+	origMdstat, origSysfs := mdstatPath, mdSysfsPath
+	defer func() { mdstatPath, mdSysfsPath = origMdstat, origSysfs }()
+	mdstatPath = filepath.Join(dir, "mdstat")
+	mdSysfsPath = filepath.Join(dir, "block")
+	if err := os.WriteFile(mdstatPath, []byte(testMdstat), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mdDir := filepath.Join(mdSysfsPath, "md0", "md")
+	if err := os.MkdirAll(mdDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, value := range map[string]string{"mismatch_cnt": "128\n", "array_state": "clean\n", "last_sync_action": "check\n"} {
+		if err := os.WriteFile(filepath.Join(mdDir, name), []byte(value), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h := NewHandlerManager(&config.Config{})
+	h.caps.Mdadm = false
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"array": "/dev/md0"}
+	res, err := h.HandleGetRAIDStatus(context.Background(), req)
+	checkToolResult(t, res, err, []string{"status", "arrays", "array_count", "warnings"})
+	var result struct {
+		Status   string      `json:"status"`
+		Arrays   []raidArray `json:"arrays"`
+		Warnings []string    `json:"warnings"`
+	}
+	if err := json.Unmarshal([]byte(resultText(res)), &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Arrays) != 1 || result.Arrays[0].MismatchCount == nil || *result.Arrays[0].MismatchCount != 128 || result.Arrays[0].ArrayState != "clean" {
+		t.Errorf("Expected md0 with sysfs counters, got %+v", result.Arrays)
+	}
+	if result.Status != statusHealthy || len(result.Warnings) != 1 {
+		t.Errorf("Expected a healthy array with a mismatch warning, got %s %v", result.Status, result.Warnings)
+	}
+
+	res, err = h.HandleGetRAIDStatus(context.Background(), mcp.CallToolRequest{})
+	checkToolResult(t, res, err, []string{"status", "arrays"})
+	if err := json.Unmarshal([]byte(resultText(res)), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != statusCritical || len(result.Arrays) != 5 {
+		t.Errorf("Expected critical status for 5 arrays, got %s with %d", result.Status, len(result.Arrays))
+	}
+
+	req.Params.Arguments = map[string]interface{}{"array": "md9"}
+	if res, err := h.HandleGetRAIDStatus(context.Background(), req); err != nil || !res.IsError {
+		t.Error("Expected an error for an unknown array")
+	}
+}
@@ -0,0 +1,318 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/shirou/gopsutil/v3/host"
+)
+
+// rebootRequiredPath is created by Debian-based package hooks when an update needs a reboot;
+// the .pkgs file next to it names the packages
+var rebootRequiredPath = "/var/run/reboot-required"
+
+// kernelModulesDirs hold one directory per installed kernel release
+var kernelModulesDirs = []string{"/lib/modules", "/usr/lib/modules"}
+
+// procPath is the proc filesystem scanned for processes running replaced files
+var procPath = "/proc"
+
+// Limits on the restart report
+const (
+	maxRestartProcesses = 20
+	maxDeletedFiles     = 3
+)
+
+// deletedFilePrefixes are where package updates replace files; deleted files elsewhere
+// (tmpfs, memfd, caches) are not a sign of an outdated process
+var deletedFilePrefixes = []string{"/usr/", "/lib/", "/lib64/", "/bin/", "/sbin/", "/opt/"}
+
+// staleProcess is a process still running an executable or library that an update replaced
+type staleProcess struct {
+	PID          int      `json:"pid"`
+	Name         string   `json:"name"`
+	Unit         string   `json:"unit,omitempty"`
+	DeletedFiles []string `json:"deleted_files"`
+}
+
+// HandleGetRebootStatus reports whether the host needs a reboot or service restarts after
+// updates: the reboot-required marker, the running versus newest installed kernel, and
+// processes still using replaced binaries or libraries
+func (h *HandlerManager) HandleGetRebootStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var reasons []string
+	result := map[string]interface{}{}
+
+	if _, err := os.Stat(rebootRequiredPath); err == nil {
+		result["reboot_required_file"] = true
+		reasons = append(reasons, rebootRequiredPath+" exists")
+		if data, err := os.ReadFile(rebootRequiredPath + ".pkgs"); err == nil {
+			result["reboot_required_packages"] = uniqueFields(string(data))
+		}
+	} else {
+		result["reboot_required_file"] = false
+	}
+
+	running, err := host.KernelVersionWithContext(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read the running kernel version: %v", err)), nil
+	}
+	installed := installedKernels(kernelModulesDirs)
+	result["running_kernel"] = running
+	result["installed_kernels"] = installed
+	if len(installed) > 0 {
+		if !contains(installed, running) {
+			result["running_kernel_modules_missing"] = true
+			reasons = append(reasons, "the running kernel's modules were removed, so new modules cannot be loaded")
+		}
+		if newest := newestKernel(running, installed); newest != "" {
+			result["newest_installed_kernel"] = newest
+			pending := newest != running
+			result["kernel_update_pending"] = pending
+			if pending {
+				reasons = append(reasons, fmt.Sprintf("kernel %s is installed but %s is running", newest, running))
+			}
+		}
+	}
+
+	stale, unreadable := staleProcesses(procPath)
+	services := map[string][]int{}
+	processes := []staleProcess{}
+	for _, p := range stale {
+		if p.Unit != "" {
+			services[p.Unit] = append(services[p.Unit], p.PID)
+		} else if len(processes) < maxRestartProcesses {
+			processes = append(processes, p)
+		}
+	}
+	serviceList := []map[string]interface{}{}
+	for unit, pids := range services {
+		serviceList = append(serviceList, map[string]interface{}{"unit": unit, "pids": pids})
+	}
+	sort.Slice(serviceList, func(i, j int) bool {
+		return serviceList[i]["unit"].(string) < serviceList[j]["unit"].(string)
+	})
+	result["services_needing_restart"] = serviceList
+	result["processes_needing_restart"] = processes
+	if unreadable > 0 {
+		result["processes_unreadable"] = unreadable
+		result["note"] = fmt.Sprintf("%d processes could not be inspected; run the server as root for a complete restart check", unreadable)
+	}
+
+	if reasons == nil {
+		reasons = []string{}
+	}
+	result["reboot_required"] = len(reasons) > 0
+	result["reasons"] = reasons
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// installedKernels lists kernel releases with modules installed, sorted by version
+func installedKernels(dirs []string) []string {
+	seen := map[string]bool{}
+	var releases []string
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if !e.IsDir() || seen[e.Name()] {
+				continue
+			}
+			// Directories left behind by removed kernels (extra or DKMS modules) have no modules.dep
+			if _, err := os.Stat(filepath.Join(dir, e.Name(), "modules.dep")); err != nil {
+				continue
+			}
+			seen[e.Name()] = true
+			releases = append(releases, e.Name())
+		}
+	}
+	sort.Slice(releases, func(i, j int) bool { return compareKernelReleases(releases[i], releases[j]) < 0 })
+	return releases
+}
+
+// newestKernel returns the newest installed release of the same flavor as running, so a
+// Pi with rpi-v8 and rpi-2712 kernels side by side compares like with like
+func newestKernel(running string, installed []string) string {
+	flavor := kernelFlavor(running)
+	newest := ""
+	for _, k := range installed {
+		if kernelFlavor(k) != flavor {
+			continue
+		}
+		if newest == "" || compareKernelReleases(k, newest) > 0 {
+			newest = k
+		}
+	}
+	return newest
+}
+
+// kernelFlavor masks the numbers in a kernel release, e.g. 6.1.0-18-arm64 becomes
+// #.#.#-#-arm#, leaving the parts that distinguish kernel variants
+func kernelFlavor(release string) string {
+	var b strings.Builder
+	inDigits := false
+	for _, r := range release {
+		if unicode.IsDigit(r) {
+			if !inDigits {
+				b.WriteByte('#')
+			}
+			inDigits = true
+			continue
+		}
+		inDigits = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// compareKernelReleases orders releases by their numeric components
+func compareKernelReleases(a, b string) int {
+	na, nb := releaseNumbers(a), releaseNumbers(b)
+	for i := 0; i < len(na) && i < len(nb); i++ {
+		if na[i] != nb[i] {
+			if na[i] < nb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(na) - len(nb)
+}
+
+// releaseNumbers extracts the digit runs of a release string
+func releaseNumbers(s string) []int {
+	var nums []int
+	for _, f := range strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsDigit(r) }) {
+		if n, err := strconv.Atoi(f); err == nil {
+			nums = append(nums, n)
+		}
+	}
+	return nums
+}
+
+// staleProcesses scans procRoot for processes whose executable or mapped libraries were
+// deleted by an update. unreadable counts processes that could not be inspected.
+func staleProcesses(procRoot string) (stale []staleProcess, unreadable int) {
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return nil, 0
+	}
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil || !e.IsDir() {
+			continue
+		}
+		dir := filepath.Join(procRoot, e.Name())
+		deleted, err := deletedMappings(filepath.Join(dir, "maps"))
+		if err != nil {
+			if os.IsPermission(err) {
+				unreadable++
+			}
+			continue
+		}
+		if exe, err := os.Readlink(filepath.Join(dir, "exe")); err == nil && strings.HasSuffix(exe, " (deleted)") {
+			path := strings.TrimSuffix(exe, " (deleted)")
+			if hasAnyPrefix(path, deletedFilePrefixes) && !contains(deleted, path) {
+				deleted = append([]string{path}, deleted...)
+			}
+		}
+		if len(deleted) == 0 {
+			continue
+		}
+		p := staleProcess{PID: pid, Unit: processUnit(filepath.Join(dir, "cgroup"))}
+		if comm, err := os.ReadFile(filepath.Join(dir, "comm")); err == nil {
+			p.Name = strings.TrimSpace(string(comm))
+		}
+		if len(deleted) > maxDeletedFiles {
+			deleted = deleted[:maxDeletedFiles]
+		}
+		p.DeletedFiles = deleted
+		stale = append(stale, p)
+	}
+	return stale, unreadable
+}
+
+// deletedMappings returns the deleted files a process still maps, from /proc/<pid>/maps
+func deletedMappings(mapsPath string) ([]string, error) {
+	f, err := os.Open(filepath.Clean(mapsPath))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var deleted []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasSuffix(line, " (deleted)") {
+			continue
+		}
+		fields := strings.Fields(strings.TrimSuffix(line, " (deleted)"))
+		if len(fields) < 6 {
+			continue
+		}
+		path := strings.Join(fields[5:], " ")
+		if hasAnyPrefix(path, deletedFilePrefixes) && !contains(deleted, path) {
+			deleted = append(deleted, path)
+		}
+	}
+	return deleted, scanner.Err()
+}
+
+// processUnit returns the systemd service a process belongs to from its cgroup path, e.g.
+// 0::/system.slice/nginx.service, or "" for processes outside a service
+func processUnit(cgroupPath string) string {
+	data, err := os.ReadFile(filepath.Clean(cgroupPath))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 || (parts[0] != "0" && !strings.Contains(parts[1], "systemd")) {
+			continue
+		}
+		segments := strings.Split(parts[2], "/")
+		for i := len(segments) - 1; i >= 0; i-- {
+			if strings.HasSuffix(segments[i], ".service") && !strings.HasPrefix(segments[i], "user@") {
+				return segments[i]
+			}
+		}
+	}
+	return ""
+}
+
+// hasAnyPrefix reports whether s starts with any of prefixes
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// uniqueFields splits s on whitespace, dropping duplicates
+func uniqueFields(s string) []string {
+	var out []string
+	for _, f := range strings.Fields(s) {
+		if !contains(out, f) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
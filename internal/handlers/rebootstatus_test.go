@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestKernelReleases(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"6.1.0-18-arm64", "6.1.0-21-arm64", -1},
+		{"6.6.31+rpt-rpi-v8", "6.6.20+rpt-rpi-v8", 1},
+		{"6.10.0", "6.9.12", 1},
+		{"6.1.0", "6.1.0", 0},
+	}
+	for _, tt := range tests {
+		got := compareKernelReleases(tt.a, tt.b)
+		if (got < 0) != (tt.want < 0) || (got > 0) != (tt.want > 0) {
+			t.Errorf("compareKernelReleases(%q, %q) = %d, want sign of %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+
+	installed := []string{"6.6.20+rpt-rpi-2712", "6.6.20+rpt-rpi-v8", "6.6.31+rpt-rpi-2712", "6.6.31+rpt-rpi-v8"}
+	if got := newestKernel("6.6.20+rpt-rpi-v8", installed); got != "6.6.31+rpt-rpi-v8" {
+		t.Errorf("newestKernel() = %q, want the newest rpi-v8 kernel", got)
+	}
+	if got := newestKernel("6.6.20+rpt-rpi-2712", installed[:2]); got != "6.6.20+rpt-rpi-2712" {
+		t.Errorf("newestKernel() = %q, want the running kernel", got)
+	}
+}
+
+func TestInstalledKernels(t *testing.T) {
+	dir := t.TempDir()
+	for _, k := range []string{"6.1.0-21-arm64", "6.1.0-18-arm64"} {
+		if err := os.MkdirAll(filepath.Join(dir, k), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, k, "modules.dep"), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Leftover from a removed kernel
+	if err := os.MkdirAll(filepath.Join(dir, "6.1.0-10-arm64", "updates", "dkms"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	got := installedKernels([]string{dir, filepath.Join(dir, "missing")})
+	if len(got) != 2 || got[0] != "6.1.0-18-arm64" || got[1] != "6.1.0-21-arm64" {
+		t.Errorf("installedKernels() = %v", got)
+	}
+}
+
+func TestStaleProcesses(t *testing.T) {
+	root := t.TempDir()
+	writeProc := func(pid, comm, maps, cgroup string) {
+		t.Helper()
+		dir := filepath.Join(root, pid)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		for name, content := range map[string]string{"comm": comm + "\n", "maps": maps, "cgroup": cgroup} {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	writeProc("100", "nginx",
+		"7f0000000000-7f0000021000 r-xp 00000000 b3:02 1234 /usr/lib/aarch64-linux-gnu/libssl.so.3 (deleted)\n"+
+			"7f0000021000-7f0000022000 r--p 00021000 b3:02 1234 /usr/lib/aarch64-linux-gnu/libssl.so.3 (deleted)\n"+
+			"7f0000030000-7f0000031000 rw-s 00000000 00:05 99 /memfd:wayland (deleted)\n",
+		"0::/system.slice/nginx.service\n")
+	writeProc("200", "bash",
+		"7f0000000000-7f0000021000 r-xp 00000000 b3:02 55 /usr/lib/libc.so.6 (deleted)\n",
+		"0::/user.slice/user-1000.slice/session-3.scope\n")
+	writeProc("300", "cron",
+		"7f0000000000-7f0000021000 r-xp 00000000 b3:02 56 /usr/lib/libc.so.6\n"+
+			"7f0000030000-7f0000031000 rw-s 00000000 00:1a 7 /dev/shm/cache (deleted)\n",
+		"0::/system.slice/cron.service\n")
+	if err := os.MkdirAll(filepath.Join(root, "self"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	stale, unreadable := staleProcesses(root)
+	if unreadable != 0 {
+		t.Errorf("unreadable = %d, want 0", unreadable)
+	}
+	if len(stale) != 2 {
+		t.Fatalf("Expected 2 stale processes, got %+v", stale)
+	}
+	for _, p := range stale {
+		switch p.PID {
+		case 100:
+			if p.Unit != "nginx.service" || p.Name != "nginx" || len(p.DeletedFiles) != 1 {
+				t.Errorf("Unexpected nginx entry: %+v", p)
+			}
+		case 200:
+			if p.Unit != "" {
+				t.Errorf("Expected no unit for a session process, got %q", p.Unit)
+			}
+		default:
+			t.Errorf("Unexpected stale process: %+v", p)
+		}
+	}
+}
+
+func TestProcessUnit(t *testing.T) {
+	tests := []struct {
+		cgroup string
+		want   string
+	}{
+		{"0::/system.slice/ssh.service\n", "ssh.service"},
+		{"0::/user.slice/user-1000.slice/user@1000.service/app.slice/pipewire.service\n", "pipewire.service"},
+		{"0::/user.slice/user-1000.slice/user@1000.service/init.scope\n", ""},
+		{"12:pids:/system.slice/docker.service\n1:name=systemd:/system.slice/docker.service\n", "docker.service"},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "cgroup")
+		if err := os.WriteFile(path, []byte(tt.cgroup), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if got := processUnit(path); got != tt.want {
+			t.Errorf("processUnit(%q) = %q, want %q", tt.cgroup, got, tt.want)
+		}
+	}
+}
+
+func TestHandleGetRebootStatus(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("reboot status is Linux only")
+	}
+	dir := t.TempDir()
+	origMarker, origModules, origProc := rebootRequiredPath, kernelModulesDirs, procPath
+	defer func() { rebootRequiredPath, kernelModulesDirs, procPath = origMarker, origModules, origProc }()
+	rebootRequiredPath = filepath.Join(dir, "reboot-required")
+	kernelModulesDirs = []string{filepath.Join(dir, "modules")}
+	procPath = filepath.Join(dir, "proc")
+
+	h := NewHandlerManager(&config.Config{})
+	res, err := h.HandleGetRebootStatus(context.Background(), mcp.CallToolRequest{})
+	checkToolResult(t, res, err, []string{"reboot_required", "reasons", "running_kernel", "services_needing_restart", "processes_needing_restart"})
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(resultText(res)), &data); err != nil {
+		t.Fatal(err)
+	}
+	if data["reboot_required"] != false {
+		t.Errorf("Expected no reboot without a marker or kernels, got %v", data)
+	}
+
+	if err := os.WriteFile(rebootRequiredPath, []byte("*** System restart required ***\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(rebootRequiredPath+".pkgs", []byte("linux-image-arm64\nlibc6\nlibc6\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	res, err = h.HandleGetRebootStatus(context.Background(), mcp.CallToolRequest{})
+	checkToolResult(t, res, err, []string{"reboot_required", "reboot_required_packages"})
+	if err := json.Unmarshal([]byte(resultText(res)), &data); err != nil {
+		t.Fatal(err)
+	}
+	if data["reboot_required"] != true {
+		t.Errorf("Expected a reboot with the marker present, got %v", data)
+	}
+	if pkgs, _ := data["reboot_required_packages"].([]interface{}); len(pkgs) != 2 {
+		t.Errorf("Expected 2 unique packages, got %v", data["reboot_required_packages"])
+	}
+}
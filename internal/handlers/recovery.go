@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// recoverTool wraps a tool handler so that a panic is logged with its stack trace and
+// returned as a tool error instead of terminating the stdio server
+func (h *HandlerManager) recoverTool(name string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (result *mcp.CallToolResult, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				h.panics.Add(1)
+				h.logger.Error("tool panicked", "tool", name, "panic", fmt.Sprint(r), "stack", string(debug.Stack()))
+				result, err = panicToolResult(name, r), nil
+			}
+		}()
+		return handler(ctx, request)
+	}
+}
+
+// panicToolResult builds the structured error returned for a recovered panic
+func panicToolResult(name string, recovered interface{}) *mcp.CallToolResult {
+	detail := map[string]interface{}{
+		"error":  "internal_error",
+		"tool":   name,
+		"panic":  fmt.Sprint(recovered),
+		"detail": "the tool panicked and the server recovered; the stack trace was written to the server log",
+	}
+	jsonBytes, err := json.Marshal(detail)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Internal error in %s: %v", name, recovered))
+	}
+	return mcp.NewToolResultError(string(jsonBytes))
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+	"sysmetrics-mcp/internal/logging"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestRecoverToolPanic(t *testing.T) {
+	var logs bytes.Buffer
+	h := NewHandlerManager(&config.Config{})
+	h.SetLogger(logging.NewWriter(&logs, slog.LevelInfo))
+
+	handler := h.recoverTool("explode", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var m map[string]int
+		m["boom"] = 1
+		return nil, nil
+	})
+
+	res, err := handler(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("Expected panic to be converted to a tool result, got error: %v", err)
+	}
+	if res == nil || !res.IsError {
+		t.Fatalf("Expected error tool result, got %+v", res)
+	}
+
+	var detail map[string]string
+	if err := json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &detail); err != nil {
+		t.Fatalf("Expected JSON error detail: %v", err)
+	}
+	if detail["error"] != "internal_error" || detail["tool"] != "explode" || !strings.Contains(detail["panic"], "nil map") {
+		t.Errorf("Unexpected error detail: %v", detail)
+	}
+	if !strings.Contains(logs.String(), `msg="tool panicked" tool=explode`) || !strings.Contains(logs.String(), "recovery_test.go") {
+		t.Errorf("Expected stack trace in log, got %q", logs.String())
+	}
+	if h.panics.Load() != 1 {
+		t.Errorf("Expected 1 recovered panic, got %d", h.panics.Load())
+	}
+}
+
+func TestRecoverToolPassthrough(t *testing.T) {
+	h := NewHandlerManager(&config.Config{})
+	handler := h.recoverTool("ok", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(`{"ok":true}`), nil
+	})
+
+	res, err := handler(context.Background(), mcp.CallToolRequest{})
+	checkToolResult(t, res, err, []string{"ok"})
+	if h.panics.Load() != 0 {
+		t.Errorf("Expected no recovered panics, got %d", h.panics.Load())
+	}
+}
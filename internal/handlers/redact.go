@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"os"
+	"regexp"
+	"strings"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Address candidates: whole tokens of letters, digits, colons, and dots are tried as IPs,
+// then IPv4 addresses inside them (e.g. 10.0.0.5:22). net.ParseIP decides, so times such
+// as 12:30:45, MAC addresses, and names such as std::string are left alone.
+var (
+	ipTokenRe = regexp.MustCompile(`[0-9A-Za-z:.]+`)
+	ipv4Re    = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+)
+
+// redactKeys are the result fields whose whole value is redacted for a kind. Addresses and
+// this host's name are found inside any string instead.
+var redactKeys = map[string][]string{
+	config.RedactHostname: {"hostname", "host", "fqdn", "nodename"},
+	config.RedactUser:     {"user", "users", "username", "owner", "login"},
+	config.RedactCmdline:  {"cmdline", "command", "cmd"},
+}
+
+// redactor rewrites tool results so configured identifiers never leave the server
+type redactor struct {
+	kinds     map[string]bool
+	patterns  []*regexp.Regexp
+	hash      bool
+	salt      []byte
+	hostnames []*regexp.Regexp
+}
+
+// newRedactor returns the redactor for --redact and --redact-patterns, or nil when neither is set
+func newRedactor(cfg *config.Config) *redactor {
+	if len(cfg.Redact) == 0 && len(cfg.RedactPatterns) == 0 {
+		return nil
+	}
+	r := &redactor{kinds: map[string]bool{}, patterns: cfg.RedactPatterns, hash: cfg.RedactMode == config.RedactHash}
+	for _, kind := range cfg.Redact {
+		r.kinds[kind] = true
+	}
+	// Hashes are stable for the life of the process only, so they cannot be looked up offline
+	r.salt = make([]byte, 16)
+	_, _ = rand.Read(r.salt)
+	if r.kinds[config.RedactHostname] {
+		if name, err := os.Hostname(); err == nil && name != "" {
+			names := []string{name}
+			if short, _, ok := strings.Cut(name, "."); ok && short != "" {
+				names = append(names, short)
+			}
+			for _, n := range names {
+				r.hostnames = append(r.hostnames, regexp.MustCompile(`\b`+regexp.QuoteMeta(n)+`\b`))
+			}
+		}
+	}
+	return r
+}
+
+// redactTool rewrites the text of successful and failed results alike, since error
+// messages can name hosts and users too
+func (h *HandlerManager) redactTool(tool mcp.Tool, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := next(ctx, request)
+		if err != nil || result == nil {
+			return result, err
+		}
+		for i, content := range result.Content {
+			if text, ok := content.(mcp.TextContent); ok {
+				text.Text = h.redactor.text(text.Text)
+				result.Content[i] = text
+			}
+		}
+		return result, nil
+	}
+}
+
+// text redacts a JSON result field by field, keeping its field order and numbers, or any
+// other text as a whole
+func (r *redactor) text(s string) string {
+	data, err := decodeJSON(s)
+	if err != nil {
+		return r.cmdline(r.inline(s))
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(r.value("", data)); err != nil {
+		return s
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// value redacts v, found under key
+func (r *redactor) value(key string, v interface{}) interface{} {
+	switch v := v.(type) {
+	case *jsonObject:
+		out := &jsonObject{values: make(map[string]interface{}, len(v.keys))}
+		for _, k := range v.keys {
+			out.set(r.inline(k), r.value(k, v.values[k]))
+		}
+		return out
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.value(key, item)
+		}
+		return v
+	case string:
+		return r.field(strings.ToLower(key), v)
+	}
+	return v
+}
+
+// field redacts one string value by its key, then the addresses and names inside it
+func (r *redactor) field(key, s string) string {
+	if s == "" {
+		return s
+	}
+	for _, kind := range []string{config.RedactHostname, config.RedactUser, config.RedactCmdline} {
+		if r.kinds[kind] && contains(redactKeys[kind], key) {
+			return r.replacement(kind, s)
+		}
+	}
+	if contains(redactKeys[config.RedactCmdline], key) {
+		s = r.cmdline(s)
+	}
+	return r.inline(s)
+}
+
+// cmdline redacts the parts of a command line matching --redact-patterns
+func (r *redactor) cmdline(s string) string {
+	for _, re := range r.patterns {
+		s = re.ReplaceAllStringFunc(s, func(m string) string { return r.replacement("pattern", m) })
+	}
+	return s
+}
+
+// inline redacts addresses and this host's name wherever they appear in s
+func (r *redactor) inline(s string) string {
+	if r.kinds[config.RedactIP] {
+		s = ipTokenRe.ReplaceAllStringFunc(s, func(token string) string {
+			if net.ParseIP(token) != nil {
+				return r.address(token)
+			}
+			return ipv4Re.ReplaceAllStringFunc(token, r.address)
+		})
+	}
+	for _, re := range r.hostnames {
+		s = re.ReplaceAllStringFunc(s, func(m string) string { return r.replacement(config.RedactHostname, m) })
+	}
+	return s
+}
+
+// address redacts an IP address. Loopback and wildcard addresses identify nothing and
+// keep listings readable, so they are kept.
+func (r *redactor) address(s string) string {
+	ip := net.ParseIP(s)
+	if ip == nil || ip.IsLoopback() || ip.IsUnspecified() {
+		return s
+	}
+	return r.replacement(config.RedactIP, s)
+}
+
+// replacement is what a redacted value becomes: a fixed marker, or with --redact-mode hash
+// a short salted hash so equal values can still be matched within one server run
+func (r *redactor) replacement(kind, s string) string {
+	if !r.hash {
+		return "[redacted-" + kind + "]"
+	}
+	sum := sha256.Sum256(append(append([]byte{}, r.salt...), s...))
+	return kind + "-" + hex.EncodeToString(sum[:4])
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestRedactorText(t *testing.T) {
+	r := newRedactor(&config.Config{
+		Redact:         []string{config.RedactIP, config.RedactUser},
+		RedactPatterns: []*regexp.Regexp{regexp.MustCompile(`--password[= ]\S+`)},
+		RedactMode:     config.RedactMask,
+	})
+
+	got := r.text(`{"connections": [{"local": "192.168.1.10:22", "remote": "[2001:db8::7]:51234", "user": "alice", "cmdline": "mysqld --password=hunter2 --port 3306"}],` +
+		` "listen": "0.0.0.0:80", "loopback": "::1", "uptime": "12:30:45", "mac": "dc:a6:32:01:02:03", "note": "std::string", "version": "1.25", "count": 12345678901234567890}`)
+	for _, leaked := range []string{"192.168.1.10", "2001:db8::7", "alice", "hunter2"} {
+		if strings.Contains(got, leaked) {
+			t.Errorf("Expected %q to be redacted: %s", leaked, got)
+		}
+	}
+	for _, kept := range []string{`"[redacted-ip]:22"`, `"[[redacted-ip]]:51234"`, `"[redacted-user]"`, `mysqld [redacted-pattern] --port 3306`,
+		`"0.0.0.0:80"`, `"::1"`, `"12:30:45"`, `"dc:a6:32:01:02:03"`, `"std::string"`, `12345678901234567890`} {
+		if !strings.Contains(got, kept) {
+			t.Errorf("Expected %s in %s", kept, got)
+		}
+	}
+
+	// Fields keep the order and numbers the tool wrote
+	if got := r.text(`{"zone": "a<b", "peer": "10.0.0.5", "load": 2.50, "list": [{"b": 1, "a": 2}]}`); got != `{"zone":"a<b","peer":"[redacted-ip]","load":2.50,"list":[{"b":1,"a":2}]}` {
+		t.Errorf("Unexpected field order or numbers: %s", got)
+	}
+
+	// Text that is not JSON, such as a Markdown report, is redacted as a whole
+	if got := r.text("# Report\nPeer 10.0.0.5 was unreachable"); got != "# Report\nPeer [redacted-ip] was unreachable" {
+		t.Errorf("Unexpected Markdown redaction: %q", got)
+	}
+}
+
+func TestRedactorHash(t *testing.T) {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		t.Skip("no hostname")
+	}
+	r := newRedactor(&config.Config{Redact: []string{config.RedactHostname, config.RedactCmdline}, RedactMode: config.RedactHash})
+	got := r.text(`{"hostname": "` + hostname + `", "message": "` + hostname + ` rebooted", "command": "/usr/bin/backup"}`)
+	if strings.Contains(got, hostname) || strings.Contains(got, "/usr/bin/backup") {
+		t.Errorf("Expected the hostname and command to be hashed: %s", got)
+	}
+	// The same value hashes the same way within a run
+	hash := r.replacement(config.RedactHostname, hostname)
+	if !strings.HasPrefix(hash, "hostname-") || strings.Count(got, hash) != 2 {
+		t.Errorf("Expected %s twice in %s", hash, got)
+	}
+
+	if newRedactor(&config.Config{}) != nil {
+		t.Error("Expected no redactor without --redact or --redact-patterns")
+	}
+}
+
+func TestRedactTool(t *testing.T) {
+	h := NewHandlerManager(&config.Config{Redact: []string{config.RedactIP}})
+	handler := chainTool(mcp.NewTool("test"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultError("failed to reach 10.1.2.3"), nil
+	}, h.toolMiddleware())
+	res, err := handler(context.Background(), mcp.CallToolRequest{})
+	if err != nil || !res.IsError || resultText(res) != "failed to reach [redacted-ip]" {
+		t.Errorf("Expected a redacted error, got %q (err %v)", resultText(res), err)
+	}
+}
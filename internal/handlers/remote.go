@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sysmetrics-mcp/internal/history"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// callerKey carries the caller of a tool invoked outside an MCP session, for the audit log
+type callerKey struct{}
+
+// CollectSamples takes one snapshot of the core host metrics, the same set the background
+// sampler records
+func (h *HandlerManager) CollectSamples(ctx context.Context) []history.Sample {
+	return h.collectSamples(ctx, time.Now())
+}
+
+// Tools lists the registered tools. Call it after RegisterTools.
+func (h *HandlerManager) Tools() []string {
+	return append([]string{}, h.registered...)
+}
+
+// ReadOnly reports whether a registered tool leaves the host and server as they were,
+// going by the traits it was registered with
+func (h *HandlerManager) ReadOnly(name string) bool {
+	return h.toolTraits[name].readOnly()
+}
+
+// CallTool runs a registered tool for a non-MCP front end such as the gRPC API. The call
+// passes through the same middleware chain as MCP calls and is audited under caller.
+func (h *HandlerManager) CallTool(ctx context.Context, caller, name string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	handler, ok := h.toolHandlers[name]
+	if !ok {
+		return nil, fmt.Errorf("tool %q is not registered", name)
+	}
+	var request mcp.CallToolRequest
+	request.Params.Name = name
+	request.Params.Arguments = arguments
+	return handler(context.WithValue(ctx, callerKey{}, caller), request)
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"sysmetrics-mcp/internal/audit"
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestCallTool(t *testing.T) {
+	h := NewHandlerManager(&config.Config{ToolProfile: config.ProfileMinimal})
+	h.RegisterTools(server.NewMCPServer("test", "1.0.0"))
+
+	if !contains(h.Tools(), "get_system_info") {
+		t.Fatalf("Expected get_system_info among %v", h.Tools())
+	}
+	res, err := h.CallTool(context.Background(), "grpc/127.0.0.1:5000", "get_system_info", map[string]interface{}{})
+	checkToolResult(t, res, err, []string{"hostname"})
+
+	if _, err := h.CallTool(context.Background(), "grpc/127.0.0.1:5000", "get_process_list", nil); err == nil {
+		t.Error("Expected a tool outside the profile to be refused")
+	}
+
+	// The call went through the middleware chain and is audited under the caller
+	entries, _, err := h.audit.Recent(audit.Filter{Limit: 10})
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("Recent() = %+v, %v", entries, err)
+	}
+	if entries[0].Tool != "get_system_info" || entries[0].Caller != "grpc/127.0.0.1:5000" {
+		t.Errorf("Unexpected audit entry: %+v", entries[0])
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	h := NewHandlerManager(&config.Config{AllowProcessControl: true, AllowSelfUpdate: true})
+	h.RegisterTools(server.NewMCPServer("test", "1.0.0"))
+
+	for name, want := range map[string]bool{
+		"get_system_info":     true,
+		"get_server_info":     true,
+		"manage_process":      false,
+		"apply_update":        false,
+		"run_system_baseline": false,
+	} {
+		if got := h.ReadOnly(name); got != want {
+			t.Errorf("ReadOnly(%s) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,455 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"sysmetrics-mcp/internal/config"
+	"sysmetrics-mcp/internal/history"
+	"sysmetrics-mcp/internal/schedule"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/host"
+	"github.com/shirou/gopsutil/v3/load"
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+// Health report defaults
+const (
+	defaultReportPeriod  = 24 * time.Hour
+	reportFormatJSON     = "json"
+	reportFormatMarkdown = "markdown"
+)
+
+// reportPeakMetrics are the sampled metrics whose peaks a health report lists
+var reportPeakMetrics = []string{"cpu_percent", "load1", "memory_used_percent", "swap_used_percent", "cpu_temperature_celsius", "disk_used_percent"}
+
+// healthSnapshot is the full health state captured by the snapshot scheduler
+type healthSnapshot struct {
+	Time          time.Time      `json:"time"`
+	Status        string         `json:"status"`
+	Warnings      []string       `json:"warnings,omitempty"`
+	CPUPercent    float64        `json:"cpu_percent"`
+	Load1         float64        `json:"load1"`
+	MemoryPercent float64        `json:"memory_used_percent"`
+	SwapPercent   float64        `json:"swap_used_percent"`
+	Temperature   *float64       `json:"cpu_temperature_celsius,omitempty"`
+	Disks         []diskSnapshot `json:"disks"`
+}
+
+// diskSnapshot is the usage of one mount point at snapshot time
+type diskSnapshot struct {
+	Mount       string  `json:"mount"`
+	UsedBytes   uint64  `json:"used_bytes"`
+	TotalBytes  uint64  `json:"total_bytes"`
+	UsedPercent float64 `json:"used_percent"`
+}
+
+// reportPeak is the highest value of one series over the report period
+type reportPeak struct {
+	Metric string    `json:"metric"`
+	Labels string    `json:"labels,omitempty"`
+	Max    float64   `json:"max"`
+	At     time.Time `json:"at"`
+	Avg    float64   `json:"avg"`
+}
+
+// reportAlert counts the snapshots in which one health warning was raised
+type reportAlert struct {
+	Alert     string    `json:"alert"`
+	Snapshots int       `json:"snapshots"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// reportDiskGrowth is the change in used space of one mount over the report period
+type reportDiskGrowth struct {
+	Mount           string   `json:"mount"`
+	UsedBytes       uint64   `json:"used_bytes"`
+	UsedHuman       string   `json:"used_human"`
+	UsedPercent     float64  `json:"used_percent"`
+	GrowthBytes     int64    `json:"growth_bytes"`
+	GrowthHuman     string   `json:"growth_human"`
+	GrowthPerDay    string   `json:"growth_per_day_human"`
+	DaysUntilFull   *float64 `json:"days_until_full,omitempty"`
+	ObservedSeconds int64    `json:"observed_seconds"`
+}
+
+// healthReport is the assembled report returned by get_health_report
+type healthReport struct {
+	Hostname      string             `json:"hostname"`
+	Since         time.Time          `json:"since"`
+	Until         time.Time          `json:"until"`
+	Period        string             `json:"period"`
+	Schedule      string             `json:"schedule,omitempty"`
+	Snapshots     int                `json:"snapshots"`
+	CurrentStatus string             `json:"current_status"`
+	WorstStatus   string             `json:"worst_status"`
+	Peaks         []reportPeak       `json:"peaks"`
+	Alerts        []reportAlert      `json:"alerts"`
+	DiskGrowth    []reportDiskGrowth `json:"disk_growth"`
+	Notes         []string           `json:"notes,omitempty"`
+}
+
+// RunSnapshotScheduler stores a health snapshot in the history database each time the
+// configured schedule fires, until ctx is cancelled. It returns immediately when history
+// or the schedule is disabled.
+func (h *HandlerManager) RunSnapshotScheduler(ctx context.Context) {
+	if h.history == nil || h.cfg.SnapshotSchedule == "" {
+		return
+	}
+	sched, err := schedule.Parse(h.cfg.SnapshotSchedule)
+	if err != nil {
+		h.logger.Warn("snapshot scheduler disabled", "error", err)
+		return
+	}
+
+	for {
+		next := sched.Next(time.Now())
+		if next.IsZero() {
+			h.logger.Warn("snapshot schedule never fires", "schedule", sched.String())
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if err := h.storeSnapshot(ctx, h.takeSnapshot(ctx, time.Now())); err != nil {
+			h.logger.Warn("health snapshot failed", "error", err)
+		}
+	}
+}
+
+// takeSnapshot captures the current health state. Collectors that fail leave their
+// fields zero rather than failing the snapshot.
+func (h *HandlerManager) takeSnapshot(ctx context.Context, now time.Time) healthSnapshot {
+	snap := healthSnapshot{Time: now.UTC(), Disks: []diskSnapshot{}}
+	if percents, err := cpu.PercentWithContext(ctx, 0, false); err == nil && len(percents) > 0 {
+		snap.CPUPercent = round2(percents[0])
+	}
+	if avg, err := load.AvgWithContext(ctx); err == nil {
+		snap.Load1 = avg.Load1
+	}
+	if vm, err := mem.VirtualMemoryWithContext(ctx); err == nil {
+		snap.MemoryPercent = round2(vm.UsedPercent)
+	}
+	if swap, err := mem.SwapMemoryWithContext(ctx); err == nil && swap.Total > 0 {
+		snap.SwapPercent = round2(swap.UsedPercent)
+	}
+	if temp, ok := config.GetRaspberryPiTemp(); ok {
+		snap.Temperature = &temp
+	}
+
+	rootPath := systemRootPath()
+	mounts := h.cfg.MountPoints
+	if len(mounts) == 0 {
+		mounts = []string{rootPath}
+	}
+	rootPercent := 0.0
+	for _, mount := range mounts {
+		usage, err := disk.UsageWithContext(ctx, mount)
+		if err != nil {
+			continue
+		}
+		snap.Disks = append(snap.Disks, diskSnapshot{Mount: mount, UsedBytes: usage.Used, TotalBytes: usage.Total, UsedPercent: round2(usage.UsedPercent)})
+		if mount == rootPath {
+			rootPercent = usage.UsedPercent
+		}
+	}
+
+	snap.Status, snap.Warnings = assessHealth(snap.CPUPercent, snap.MemoryPercent, rootPercent)
+	return snap
+}
+
+// storeSnapshot writes a snapshot to the history database
+func (h *HandlerManager) storeSnapshot(ctx context.Context, snap healthSnapshot) error {
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	return h.history.WriteSnapshot(ctx, snap.Time, data)
+}
+
+// HandleGetHealthReport assembles a health report over a period from stored snapshots and
+// metrics history: metric peaks, alerts fired, and disk growth
+func (h *HandlerManager) HandleGetHealthReport(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if h.history == nil {
+		return mcp.NewToolResultError("Metrics history is disabled (start the server with --history-db)"), nil
+	}
+
+	period := defaultReportPeriod
+	format := reportFormatJSON
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if p, ok := args["period"].(string); ok && p != "" {
+			d, err := parseHistoryDuration(p)
+			if err != nil || d <= 0 {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid period: %q (use a duration such as 12h, 24h, or 7d)", p)), nil
+			}
+			period = d
+		}
+		if f, ok := args["format"].(string); ok && f != "" {
+			format = strings.ToLower(f)
+			if format != reportFormatJSON && format != reportFormatMarkdown {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid format: %q (must be json or markdown)", f)), nil
+			}
+		}
+	}
+
+	until := time.Now()
+	report, err := h.buildHealthReport(ctx, until.Add(-period), until)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to build health report: %v", err)), nil
+	}
+	report.Period = period.String()
+
+	if format == reportFormatMarkdown {
+		return mcp.NewToolResultText(h.renderHealthReport(report)), nil
+	}
+	jsonBytes, err := json.Marshal(report)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// buildHealthReport reads the snapshots and samples in the range. A live snapshot is the
+// report's end point, so growth and current status are meaningful even before the
+// scheduler has run twice.
+func (h *HandlerManager) buildHealthReport(ctx context.Context, since, until time.Time) (healthReport, error) {
+	report := healthReport{
+		Since:      since.UTC(),
+		Until:      until.UTC(),
+		Schedule:   h.cfg.SnapshotSchedule,
+		Peaks:      []reportPeak{},
+		Alerts:     []reportAlert{},
+		DiskGrowth: []reportDiskGrowth{},
+	}
+	if info, err := host.InfoWithContext(ctx); err == nil {
+		report.Hostname = info.Hostname
+	}
+
+	stored, err := h.history.Snapshots(ctx, since, until)
+	if err != nil {
+		return report, err
+	}
+	snapshots := make([]healthSnapshot, 0, len(stored)+1)
+	for _, s := range stored {
+		var snap healthSnapshot
+		if err := json.Unmarshal(s.Data, &snap); err != nil {
+			continue
+		}
+		snapshots = append(snapshots, snap)
+	}
+	report.Snapshots = len(snapshots)
+	if len(snapshots) == 0 {
+		if h.cfg.SnapshotSchedule == "" {
+			report.Notes = append(report.Notes, "Scheduled snapshots are disabled (--snapshot-schedule is empty); alerts and disk growth cover only the current state")
+		} else {
+			report.Notes = append(report.Notes, "No stored snapshots in the period yet; alerts and disk growth cover only the current state")
+		}
+	}
+	current := h.takeSnapshot(ctx, until)
+	snapshots = append(snapshots, current)
+	report.CurrentStatus = current.Status
+
+	report.WorstStatus, report.Alerts = reportAlerts(snapshots)
+	report.DiskGrowth = h.reportDiskGrowth(snapshots)
+
+	for _, metric := range reportPeakMetrics {
+		series, err := h.history.Raw(ctx, metric, since, until)
+		if err != nil {
+			return report, err
+		}
+		for _, s := range series {
+			if peak, ok := seriesPeak(s); ok {
+				report.Peaks = append(report.Peaks, peak)
+			}
+		}
+	}
+	if len(report.Peaks) == 0 {
+		report.Notes = append(report.Notes, "No sampled metrics in the period; peaks are unavailable")
+	}
+	return report, nil
+}
+
+// seriesPeak returns the maximum of a series and when it occurred
+func seriesPeak(s history.RawSeries) (reportPeak, bool) {
+	if len(s.Points) == 0 {
+		return reportPeak{}, false
+	}
+	peak := reportPeak{Metric: s.Metric, Labels: s.Labels, Max: math.Inf(-1)}
+	sum := 0.0
+	for _, p := range s.Points {
+		sum += p.Value
+		if p.Value > peak.Max {
+			peak.Max = p.Value
+			peak.At = p.Time
+		}
+	}
+	peak.Max = round2(peak.Max)
+	peak.Avg = round2(sum / float64(len(s.Points)))
+	return peak, true
+}
+
+// reportAlerts returns the worst status among snapshots and how often each warning fired
+func reportAlerts(snapshots []healthSnapshot) (string, []reportAlert) {
+	worst := statusHealthy
+	alerts := []reportAlert{}
+	index := map[string]int{}
+	for _, snap := range snapshots {
+		if statusRank(snap.Status) > statusRank(worst) {
+			worst = snap.Status
+		}
+		for _, w := range snap.Warnings {
+			i, ok := index[w]
+			if !ok {
+				i = len(alerts)
+				index[w] = i
+				alerts = append(alerts, reportAlert{Alert: w, FirstSeen: snap.Time})
+			}
+			alerts[i].Snapshots++
+			alerts[i].LastSeen = snap.Time
+		}
+	}
+	sort.SliceStable(alerts, func(i, j int) bool { return alerts[i].Snapshots > alerts[j].Snapshots })
+	return worst, alerts
+}
+
+// statusRank orders health statuses by severity
+func statusRank(status string) int {
+	switch status {
+	case statusCritical:
+		return 2
+	case statusWarning:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// reportDiskGrowth compares each mount's usage in the first and last snapshot that
+// include it, projecting when a growing mount fills up
+func (h *HandlerManager) reportDiskGrowth(snapshots []healthSnapshot) []reportDiskGrowth {
+	type span struct {
+		first, last         diskSnapshot
+		firstTime, lastTime time.Time
+	}
+	spans := map[string]*span{}
+	var order []string
+	for _, snap := range snapshots {
+		for _, d := range snap.Disks {
+			s, ok := spans[d.Mount]
+			if !ok {
+				s = &span{first: d, firstTime: snap.Time}
+				spans[d.Mount] = s
+				order = append(order, d.Mount)
+			}
+			s.last, s.lastTime = d, snap.Time
+		}
+	}
+
+	growth := []reportDiskGrowth{}
+	for _, mount := range order {
+		s := spans[mount]
+		//nolint:gosec // G115: disk sizes fit in int64
+		delta := int64(s.last.UsedBytes) - int64(s.first.UsedBytes)
+		observed := s.lastTime.Sub(s.firstTime)
+		g := reportDiskGrowth{
+			Mount:           mount,
+			UsedBytes:       s.last.UsedBytes,
+			UsedHuman:       h.human.Bytes(s.last.UsedBytes),
+			UsedPercent:     s.last.UsedPercent,
+			GrowthBytes:     delta,
+			GrowthHuman:     h.signedBytes(delta),
+			ObservedSeconds: int64(observed.Seconds()),
+		}
+		if observed >= time.Hour {
+			perDay := float64(delta) / observed.Hours() * 24
+			g.GrowthPerDay = h.signedBytes(int64(perDay))
+			if perDay > 0 && s.last.TotalBytes > s.last.UsedBytes {
+				days := round2(float64(s.last.TotalBytes-s.last.UsedBytes) / perDay)
+				g.DaysUntilFull = &days
+			}
+		}
+		growth = append(growth, g)
+	}
+	return growth
+}
+
+// signedBytes formats a byte delta with an explicit sign
+func (h *HandlerManager) signedBytes(n int64) string {
+	if n < 0 {
+		return "-" + h.human.Bytes(uint64(-n))
+	}
+	return "+" + h.human.Bytes(uint64(n))
+}
+
+// renderHealthReport formats a report as Markdown for summarizing or mailing
+func (h *HandlerManager) renderHealthReport(r healthReport) string {
+	var b strings.Builder
+	hostname := r.Hostname
+	if hostname == "" {
+		hostname = "unknown host"
+	}
+	fmt.Fprintf(&b, "# Health report: %s\n\n", hostname)
+	fmt.Fprintf(&b, "_%s to %s (%s), %d stored snapshots_\n\n", r.Since.Format(time.RFC3339), r.Until.Format(time.RFC3339), r.Period, r.Snapshots)
+	fmt.Fprintf(&b, "**Current status:** %s  \n**Worst status in period:** %s\n", r.CurrentStatus, r.WorstStatus)
+
+	b.WriteString("\n## Peaks\n\n")
+	if len(r.Peaks) == 0 {
+		b.WriteString("No sampled metrics in the period.\n")
+	} else {
+		b.WriteString("| Metric | Peak | At | Average |\n|---|---|---|---|\n")
+		for _, p := range r.Peaks {
+			name := p.Metric
+			if p.Labels != "" {
+				name += " (" + p.Labels + ")"
+			}
+			fmt.Fprintf(&b, "| %s | %.2f | %s | %.2f |\n", name, p.Max, p.At.Format(time.RFC3339), p.Avg)
+		}
+	}
+
+	b.WriteString("\n## Alerts fired\n\n")
+	if len(r.Alerts) == 0 {
+		b.WriteString("None.\n")
+	} else {
+		for _, a := range r.Alerts {
+			fmt.Fprintf(&b, "- %s: %d snapshots, first %s, last %s\n", a.Alert, a.Snapshots, a.FirstSeen.Format(time.RFC3339), a.LastSeen.Format(time.RFC3339))
+		}
+	}
+
+	b.WriteString("\n## Disk growth\n\n")
+	if len(r.DiskGrowth) == 0 {
+		b.WriteString("No disk usage recorded.\n")
+	} else {
+		b.WriteString("| Mount | Used | Change | Per day | Full in |\n|---|---|---|---|---|\n")
+		for _, g := range r.DiskGrowth {
+			perDay, fullIn := "-", "-"
+			if g.GrowthPerDay != "" {
+				perDay = g.GrowthPerDay
+			}
+			if g.DaysUntilFull != nil {
+				fullIn = fmt.Sprintf("%.1f days", *g.DaysUntilFull)
+			}
+			fmt.Fprintf(&b, "| %s | %s (%.1f%%) | %s | %s | %s |\n", g.Mount, g.UsedHuman, g.UsedPercent, g.GrowthHuman, perDay, fullIn)
+		}
+	}
+
+	if len(r.Notes) > 0 {
+		b.WriteString("\n## Notes\n\n")
+		for _, n := range r.Notes {
+			fmt.Fprintf(&b, "- %s\n", n)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"sysmetrics-mcp/internal/history"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleGetHealthReport(t *testing.T) {
+	h := newHistoryTestManager(t)
+	h.cfg.SnapshotSchedule = "@hourly"
+	ctx := context.Background()
+	now := time.Now()
+
+	// Two stored snapshots two hours apart: / grew by 2 GiB and memory warned once
+	for i, snap := range []healthSnapshot{
+		{Time: now.Add(-3 * time.Hour), Status: statusWarning, Warnings: []string{"Memory usage is high (>85%)"},
+			Disks: []diskSnapshot{{Mount: "/report-test", UsedBytes: 10 << 30, TotalBytes: 100 << 30}}},
+		{Time: now.Add(-time.Hour), Status: statusHealthy,
+			Disks: []diskSnapshot{{Mount: "/report-test", UsedBytes: 12 << 30, TotalBytes: 100 << 30}}},
+	} {
+		if err := h.storeSnapshot(ctx, snap); err != nil {
+			t.Fatalf("storeSnapshot %d failed: %v", i, err)
+		}
+	}
+	if err := h.history.Write(ctx, []history.Sample{
+		{Time: now.Add(-2 * time.Hour), Metric: "cpu_percent", Value: 20},
+		{Time: now.Add(-90 * time.Minute), Metric: "cpu_percent", Value: 90},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]interface{}{"period": "6h"}}}
+	res, err := h.HandleGetHealthReport(ctx, req)
+	checkToolResult(t, res, err, []string{"hostname", "snapshots", "current_status", "worst_status", "peaks", "alerts", "disk_growth"})
+
+	var report healthReport
+	if err := json.Unmarshal([]byte(resultText(res)), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Snapshots != 2 || report.WorstStatus == statusHealthy {
+		t.Errorf("Expected 2 snapshots with a warning, got %d (%s)", report.Snapshots, report.WorstStatus)
+	}
+	if !containsAlert(report.Alerts, "Memory usage is high (>85%)") {
+		t.Errorf("Expected the memory alert, got %+v", report.Alerts)
+	}
+	if len(report.Peaks) == 0 || report.Peaks[0].Metric != "cpu_percent" || report.Peaks[0].Max != 90 || report.Peaks[0].Avg != 55 {
+		t.Errorf("Unexpected peaks: %+v", report.Peaks)
+	}
+
+	var growth *reportDiskGrowth
+	for i := range report.DiskGrowth {
+		if report.DiskGrowth[i].Mount == "/report-test" {
+			growth = &report.DiskGrowth[i]
+		}
+	}
+	if growth == nil || growth.GrowthBytes != 2<<30 || growth.DaysUntilFull == nil || *growth.DaysUntilFull != 3.67 {
+		t.Errorf("Expected 2 GiB growth at 24 GiB/day with 3.67 days to full, got %+v", growth)
+	}
+
+	req.Params.Arguments = map[string]interface{}{"format": "markdown"}
+	res, err = h.HandleGetHealthReport(ctx, req)
+	if err != nil || res.IsError {
+		t.Fatalf("Markdown report failed: %v", resultText(res))
+	}
+	for _, section := range []string{"# Health report:", "## Peaks", "## Alerts fired", "## Disk growth", "/report-test"} {
+		if !strings.Contains(resultText(res), section) {
+			t.Errorf("Expected %q in the markdown report", section)
+		}
+	}
+
+	for _, args := range []map[string]interface{}{{"period": "soon"}, {"format": "pdf"}} {
+		req.Params.Arguments = args
+		res, _ = h.HandleGetHealthReport(ctx, req)
+		if !res.IsError {
+			t.Errorf("Expected an error for %v", args)
+		}
+	}
+}
+
+func TestReportAlerts(t *testing.T) {
+	base := time.Unix(1700000000, 0).UTC()
+	worst, alerts := reportAlerts([]healthSnapshot{
+		{Time: base, Status: statusWarning, Warnings: []string{"CPU usage is high (>80%)"}},
+		{Time: base.Add(time.Hour), Status: statusCritical, Warnings: []string{"Disk usage is critical (>95%)", "CPU usage is high (>80%)"}},
+		{Time: base.Add(2 * time.Hour), Status: statusHealthy},
+	})
+	if worst != statusCritical {
+		t.Errorf("worst = %s, want critical", worst)
+	}
+	if len(alerts) != 2 || alerts[0].Snapshots != 2 || !alerts[0].LastSeen.Equal(base.Add(time.Hour)) {
+		t.Errorf("Unexpected alerts: %+v", alerts)
+	}
+}
+
+// containsAlert reports whether a report lists the alert
+func containsAlert(alerts []reportAlert, alert string) bool {
+	for _, a := range alerts {
+		if a.Alert == alert {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Root filesystem modes
+const (
+	rootReadWrite = "read_write"
+	rootReadOnly  = "read_only"
+	rootOverlay   = "overlay"
+)
+
+// rootWritePaths are where a system normally writes: logs, state, caches, and temp files
+var rootWritePaths = []string{"/", "/etc", "/home", "/tmp", "/var/tmp", "/var/log", "/var/lib", "/var/cache"}
+
+// ramFilesystems keep their contents in memory only
+var ramFilesystems = map[string]bool{"tmpfs": true, "ramfs": true, "zram": true}
+
+// writeTarget is where writes to one path land
+type writeTarget struct {
+	Path       string `json:"path"`
+	MountPoint string `json:"mount_point"`
+	Fstype     string `json:"fstype"`
+	Writable   bool   `json:"writable"`
+	Persistent bool   `json:"persistent"`
+}
+
+// rootStorage describes a read-only or overlay root, as used by kiosk and appliance
+// images (Raspberry Pi OS overlay file system, Ubuntu overlayroot)
+type rootStorage struct {
+	Mode        string        `json:"mode"`
+	Device      string        `json:"device"`
+	Fstype      string        `json:"fstype"`
+	LowerDir    string        `json:"lower_dir,omitempty"`
+	UpperDir    string        `json:"upper_dir,omitempty"`
+	UpperFstype string        `json:"upper_fstype,omitempty"`
+	Persistent  bool          `json:"persistent"`
+	Writes      []writeTarget `json:"writes"`
+	Notes       []string      `json:"notes"`
+}
+
+// readRootStorage inspects the root mount and reports where writes land. It returns false
+// for an ordinary read-write root, or when the mount table cannot be read (non-Linux hosts).
+func readRootStorage(path string) (*rootStorage, bool) {
+	mounts, err := readMountTable(path)
+	if err != nil {
+		return nil, false
+	}
+	root, ok := mountFor(mounts, "/")
+	if !ok {
+		return nil, false
+	}
+	rs := &rootStorage{Mode: rootReadWrite, Device: root.Device, Fstype: root.Fstype, Persistent: true, Notes: []string{}}
+	switch {
+	case root.Fstype == "overlay":
+		rs.Mode = rootOverlay
+		for _, opt := range root.Options {
+			if v, ok := strings.CutPrefix(opt, "lowerdir="); ok {
+				rs.LowerDir = v
+			} else if v, ok := strings.CutPrefix(opt, "upperdir="); ok {
+				rs.UpperDir = v
+			}
+		}
+		// Without an upper layer the overlay is read-only; otherwise its backing decides
+		if rs.UpperDir != "" {
+			if upper, ok := mountFor(mounts, rs.UpperDir); ok {
+				rs.UpperFstype = upper.Fstype
+				rs.Persistent = !ramFilesystems[upper.Fstype]
+			}
+		}
+		if !rs.Persistent {
+			rs.Notes = append(rs.Notes, "The root filesystem is an overlay on "+rs.UpperFstype+": writes to / are held in RAM and lost at reboot")
+			rs.Notes = append(rs.Notes, "Disk usage for / reports the RAM-backed overlay, not the boot storage; a full overlay means memory is exhausted")
+			if lower, ok := mountFor(mounts, rs.LowerDir); ok && lower.MountPoint != "/" {
+				rs.Notes = append(rs.Notes, "The persistent root is mounted read-only at "+lower.MountPoint)
+			}
+		}
+	case contains(root.Options, "ro"):
+		rs.Mode = rootReadOnly
+		rs.Notes = append(rs.Notes, "The root filesystem is mounted read-only; only the writable mounts listed in writes accept changes")
+	default:
+		return nil, false
+	}
+
+	for _, p := range rootWritePaths {
+		m, ok := mountFor(mounts, p)
+		if !ok {
+			continue
+		}
+		t := writeTarget{Path: p, MountPoint: m.MountPoint, Fstype: m.Fstype, Writable: !contains(m.Options, "ro")}
+		t.Persistent = !ramFilesystems[m.Fstype]
+		if m.MountPoint == "/" {
+			t.Writable = rs.Mode != rootReadOnly
+			t.Persistent = rs.Persistent
+		}
+		rs.Writes = append(rs.Writes, t)
+	}
+	return rs, true
+}
+
+// mountFor returns the mount holding path: the longest matching mount point, and the last
+// one mounted where several are stacked on the same point
+func mountFor(mounts []mountEntry, path string) (mountEntry, bool) {
+	path = filepath.Clean(path)
+	var best mountEntry
+	found := false
+	for _, m := range mounts {
+		mp := filepath.Clean(m.MountPoint)
+		if mp != "/" && path != mp && !strings.HasPrefix(path, mp+"/") {
+			continue
+		}
+		if !found || len(mp) >= len(filepath.Clean(best.MountPoint)) {
+			best, found = m, true
+		}
+	}
+	return best, found
+}
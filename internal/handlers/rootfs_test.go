@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadRootStorage(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content string) string {
+		t.Helper()
+		path := filepath.Join(dir, "mounts")
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	// Raspberry Pi OS with the overlay file system enabled in raspi-config
+	rs, ok := readRootStorage(write(`proc /proc proc rw,relatime 0 0
+/dev/mmcblk0p2 /media/root-ro ext4 ro,relatime 0 0
+tmpfs-root /media/root-rw tmpfs rw,relatime 0 0
+overlayroot / overlay rw,relatime,lowerdir=/media/root-ro,upperdir=/media/root-rw/overlay,workdir=/media/root-rw/overlay-workdir 0 0
+/dev/mmcblk0p1 /boot/firmware vfat ro,relatime 0 0
+/dev/sda1 /var/lib/app ext4 rw,relatime 0 0
+tmpfs /tmp tmpfs rw,nosuid,nodev 0 0
+`))
+	if !ok || rs.Mode != rootOverlay || rs.Persistent || rs.UpperFstype != "tmpfs" || rs.LowerDir != "/media/root-ro" {
+		t.Fatalf("Expected a tmpfs-backed overlay root, got %+v", rs)
+	}
+	if len(rs.Notes) != 3 || !strings.Contains(rs.Notes[2], "/media/root-ro") {
+		t.Errorf("Expected notes naming the read-only lower mount, got %v", rs.Notes)
+	}
+	targets := map[string]writeTarget{}
+	for _, w := range rs.Writes {
+		targets[w.Path] = w
+	}
+	if w := targets["/var/log"]; w.MountPoint != "/" || !w.Writable || w.Persistent {
+		t.Errorf("Expected /var/log writes to land in the overlay, got %+v", w)
+	}
+	if w := targets["/var/lib"]; w.MountPoint != "/" {
+		t.Errorf("Expected /var/lib on the overlay, got %+v", w)
+	}
+	if w := targets["/tmp"]; w.Fstype != "tmpfs" || w.Persistent {
+		t.Errorf("Expected /tmp on tmpfs, got %+v", w)
+	}
+
+	// A plain read-only root with a persistent data partition
+	rs, ok = readRootStorage(write(`/dev/mmcblk0p2 / ext4 ro,relatime 0 0
+/dev/mmcblk0p3 /home ext4 rw,relatime 0 0
+tmpfs /var/log tmpfs rw 0 0
+`))
+	if !ok || rs.Mode != rootReadOnly {
+		t.Fatalf("Expected a read-only root, got %+v", rs)
+	}
+	for _, w := range rs.Writes {
+		switch w.Path {
+		case "/etc":
+			if w.Writable {
+				t.Errorf("Expected /etc to be read-only, got %+v", w)
+			}
+		case "/home":
+			if !w.Writable || !w.Persistent {
+				t.Errorf("Expected /home to be persistent, got %+v", w)
+			}
+		case "/var/log":
+			if !w.Writable || w.Persistent {
+				t.Errorf("Expected /var/log to be volatile, got %+v", w)
+			}
+		}
+	}
+
+	// A remount of the root read-write stacks on top of the original read-only mount
+	if _, ok := readRootStorage(write("/dev/root / ext4 ro 0 0\n/dev/root / ext4 rw,relatime 0 0\n")); ok {
+		t.Error("Expected nothing for a read-write root")
+	}
+	if _, ok := readRootStorage(filepath.Join(dir, "missing")); ok {
+		t.Error("Expected nothing without a mount table")
+	}
+}
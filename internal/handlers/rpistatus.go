@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// deviceTreeModelPath names the board, e.g. "Raspberry Pi 4 Model B Rev 1.4"
+var deviceTreeModelPath = "/proc/device-tree/model"
+
+// rpiClocks are the measure_clock sources reported by get_rpi_status
+var rpiClocks = []string{"arm", "core", "v3d", "emmc"}
+
+// rpiVoltages are the measure_volts rails; the SDRAM rails are absent on the Pi 5
+var rpiVoltages = []string{"core", "sdram_c", "sdram_i", "sdram_p"}
+
+// rpiConfigKeys are the config.txt clock and voltage settings reported from get_config
+var rpiConfigKeys = []string{"arm_freq", "arm_freq_min", "core_freq", "gpu_freq", "sdram_freq", "over_voltage", "force_turbo", "arm_boost"}
+
+// vcgencmdValueRe matches vcgencmd "key=value" output such as "frequency(48)=1800404352",
+// "volt=0.8500V", or "gpu=76M"
+var vcgencmdValueRe = regexp.MustCompile(`^[\w()]+=([-\d.]+)[A-Za-z]*$`)
+
+// eepromTimestampRe matches the epoch rpi-eeprom-update prints after a bootloader date
+var eepromTimestampRe = regexp.MustCompile(`\((\d+)\)\s*$`)
+
+// HandleGetRPiStatus returns Raspberry Pi clocks, voltages, config.txt overclock settings,
+// the memory split, firmware version, and bootloader EEPROM update status
+func (h *HandlerManager) HandleGetRPiStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	result := map[string]interface{}{}
+	warnings := []string{}
+
+	if data, err := os.ReadFile(deviceTreeModelPath); err == nil {
+		result["model"] = strings.Trim(string(data), "\x00 \n")
+	}
+
+	clocks := map[string]int64{}
+	for _, source := range rpiClocks {
+		if v, ok := vcgencmdNumber(ctx, "measure_clock", source); ok && v > 0 {
+			clocks[source+"_mhz"] = int64(v / 1e6)
+		}
+	}
+	result["clocks"] = clocks
+
+	volts := map[string]float64{}
+	for _, rail := range rpiVoltages {
+		if v, ok := vcgencmdNumber(ctx, "measure_volts", rail); ok {
+			volts[rail] = v
+		}
+	}
+	result["voltages"] = volts
+
+	if out, err := exec.CommandContext(ctx, "vcgencmd", "get_config", "int").Output(); err == nil {
+		result["config"] = parseRPiConfig(string(out))
+	}
+
+	memory := map[string]int64{}
+	for _, part := range []string{"arm", "gpu"} {
+		if v, ok := vcgencmdNumber(ctx, "get_mem", part); ok {
+			memory[part+"_mb"] = int64(v)
+		}
+	}
+	result["memory_split"] = memory
+
+	if out, err := exec.CommandContext(ctx, "vcgencmd", "version").Output(); err == nil {
+		result["firmware"] = parseFirmwareVersion(string(out))
+	} else {
+		result["firmware_error"] = fmt.Sprintf("vcgencmd version failed: %v", err)
+	}
+
+	if throttled, ok := config.GetThrottledStatus(ctx); ok {
+		result["throttled"] = throttled
+		if throttled["under_voltage_occurred"] == true {
+			warnings = append(warnings, "Under-voltage has occurred since boot; check the power supply and cable")
+		}
+	}
+
+	// Only the Pi 4, 400, and 5 boot from an SPI EEPROM
+	if h.caps.RpiEeprom {
+		// rpi-eeprom-update exits non-zero when an update is available, so parse any output
+		out, err := exec.CommandContext(ctx, "rpi-eeprom-update").Output()
+		if eeprom := parseEEPROMStatus(string(out)); len(eeprom) > 0 {
+			result["eeprom"] = eeprom
+			for _, c := range []struct{ key, name string }{{"bootloader", "bootloader"}, {"vl805", "VL805 USB controller"}} {
+				if component, ok := eeprom[c.key].(map[string]interface{}); ok && component["update_available"] == true {
+					warnings = append(warnings, fmt.Sprintf("A %s firmware update is available (apply with rpi-eeprom-update -a and reboot)", c.name))
+				}
+			}
+		} else if err != nil {
+			result["eeprom_error"] = fmt.Sprintf("rpi-eeprom-update failed: %v", err)
+		}
+	}
+	result["warnings"] = warnings
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// vcgencmdNumber runs a vcgencmd query and parses its numeric "key=value" answer
+func vcgencmdNumber(ctx context.Context, args ...string) (float64, bool) {
+	out, err := exec.CommandContext(ctx, "vcgencmd", args...).Output()
+	if err != nil {
+		return 0, false
+	}
+	return parseVcgencmdValue(string(out))
+}
+
+// parseVcgencmdValue parses output such as "frequency(48)=1800404352" or "volt=0.8500V"
+func parseVcgencmdValue(out string) (float64, bool) {
+	m := vcgencmdValueRe.FindStringSubmatch(strings.TrimSpace(out))
+	if m == nil {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(m[1], 64)
+	return v, err == nil
+}
+
+// parseRPiConfig picks the clock and voltage settings out of `vcgencmd get_config int`
+func parseRPiConfig(out string) map[string]int64 {
+	settings := map[string]int64{}
+	for _, line := range strings.Split(out, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok || !contains(rpiConfigKeys, key) {
+			continue
+		}
+		if n, err := strconv.ParseInt(value, 0, 64); err == nil {
+			settings[key] = n
+		}
+	}
+	return settings
+}
+
+// parseFirmwareVersion parses `vcgencmd version`:
+//
+//	Mar 17 2023 10:52:42
+//	Copyright (c) 2012 Broadcom
+//	version 82f3750a65fadae9a38077e3c2e217ad158c8d54 (clean) (release) (start)
+func parseFirmwareVersion(out string) map[string]interface{} {
+	fw := map[string]interface{}{}
+	for i, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		line = strings.TrimSpace(line)
+		if i == 0 {
+			if t, err := time.Parse("Jan 2 2006 15:04:05", strings.Join(strings.Fields(line), " ")); err == nil {
+				fw["build_date"] = t.Format(time.RFC3339)
+			}
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "version "); ok {
+			fields := strings.Fields(rest)
+			if len(fields) == 0 {
+				continue
+			}
+			fw["version"] = fields[0]
+			for _, f := range fields[1:] {
+				if f == "(release)" || f == "(start)" || f == "(start_cd)" || f == "(start_x)" || f == "(start_db)" {
+					fw[strings.Trim(f, "()")] = true
+				}
+			}
+		}
+	}
+	return fw
+}
+
+// parseEEPROMStatus parses `rpi-eeprom-update` output into bootloader and, on the Pi 4,
+// VL805 USB controller sections:
+//
+//	BOOTLOADER: update available
+//	   CURRENT: Thu  3 Sep 12:11:43 UTC 2020 (1599135103)
+//	    LATEST: Thu 18 Jan 12:00:00 UTC 2024 (1705579200)
+//	   RELEASE: default (/lib/firmware/raspberrypi/bootloader-2711/default)
+//	     VL805: up to date
+//	   CURRENT: 000138c0
+//	    LATEST: 000138c0
+func parseEEPROMStatus(out string) map[string]interface{} {
+	eeprom := map[string]interface{}{}
+	var section map[string]interface{}
+	for _, line := range strings.Split(out, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "BOOTLOADER", "VL805":
+			section = map[string]interface{}{
+				"status":           value,
+				"update_available": strings.Contains(value, "update available") || strings.Contains(value, "update required"),
+			}
+			eeprom[strings.ToLower(key)] = section
+		case "CURRENT", "LATEST":
+			if section == nil {
+				continue
+			}
+			field := strings.ToLower(key)
+			if m := eepromTimestampRe.FindStringSubmatch(value); m != nil {
+				if epoch, err := strconv.ParseInt(m[1], 10, 64); err == nil {
+					section[field] = time.Unix(epoch, 0).UTC().Format(time.RFC3339)
+					continue
+				}
+			}
+			section[field] = value
+		case "RELEASE":
+			if section != nil {
+				release, _, _ := strings.Cut(value, " ")
+				section["release"] = release
+			}
+		}
+	}
+	return eeprom
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import "testing"
+
+func TestParseVcgencmdValue(t *testing.T) {
+	for out, want := range map[string]float64{
+		"frequency(48)=1800404352\n": 1800404352,
+		"volt=0.8500V\n":             0.85,
+		"gpu=76M\n":                  76,
+	} {
+		if got, ok := parseVcgencmdValue(out); !ok || got != want {
+			t.Errorf("parseVcgencmdValue(%q) = %v, %v; want %v", out, got, ok, want)
+		}
+	}
+	if _, ok := parseVcgencmdValue("error=2 error_msg=\"Command not registered\""); ok {
+		t.Error("Expected an error message not to parse")
+	}
+}
+
+func TestParseRPiConfig(t *testing.T) {
+	settings := parseRPiConfig("arm_freq=1800\narm_freq_min=600\ncore_freq=500\nhdmi_group=0\nover_voltage=2\nsdram_freq=0x0bb8\n")
+	if len(settings) != 5 || settings["arm_freq"] != 1800 || settings["over_voltage"] != 2 || settings["sdram_freq"] != 3000 {
+		t.Errorf("Unexpected settings: %v", settings)
+	}
+}
+
+func TestParseFirmwareVersion(t *testing.T) {
+	fw := parseFirmwareVersion("Mar 17 2023 10:52:42 \nCopyright (c) 2012 Broadcom\nversion 82f3750a65fadae9a38077e3c2e217ad158c8d54 (clean) (release) (start)\n")
+	if fw["build_date"] != "2023-03-17T10:52:42Z" || fw["version"] != "82f3750a65fadae9a38077e3c2e217ad158c8d54" || fw["release"] != true || fw["start"] != true {
+		t.Errorf("Unexpected firmware: %v", fw)
+	}
+}
+
+func TestParseEEPROMStatus(t *testing.T) {
+	out := `BOOTLOADER: update available
+   CURRENT: Thu  3 Sep 12:11:43 UTC 2020 (1599135103)
+    LATEST: Thu 18 Jan 12:00:00 UTC 2024 (1705579200)
+   RELEASE: default (/lib/firmware/raspberrypi/bootloader-2711/default)
+            Use raspi-config to change the release.
+
+  VL805_FW: Using bootloader EEPROM
+     VL805: up to date
+   CURRENT: 000138c0
+    LATEST: 000138c0
+`
+	eeprom := parseEEPROMStatus(out)
+	boot, _ := eeprom["bootloader"].(map[string]interface{})
+	if boot["update_available"] != true || boot["current"] != "2020-09-03T12:11:43Z" || boot["latest"] != "2024-01-18T12:00:00Z" || boot["release"] != "default" {
+		t.Errorf("Unexpected bootloader section: %v", boot)
+	}
+	vl805, _ := eeprom["vl805"].(map[string]interface{})
+	if vl805["update_available"] != false || vl805["current"] != "000138c0" {
+		t.Errorf("Unexpected VL805 section: %v", vl805)
+	}
+	if len(parseEEPROMStatus("")) != 0 {
+		t.Error("Expected no sections for empty output")
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"sysmetrics-mcp/internal/config"
+	"sysmetrics-mcp/internal/exporter"
+	"sysmetrics-mcp/internal/history"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+)
+
+// SetHistory enables persistent metrics history and the query_metrics tool; the store
+// also becomes a destination of the background sampler
+func (h *HandlerManager) SetHistory(store *history.Store) {
+	h.history = store
+	h.AddSampleWriter(store)
+}
+
+// SetExporter makes the background sampler feed a remote-write exporter and reports its
+// delivery counters in get_server_info
+func (h *HandlerManager) SetExporter(exp *exporter.Exporter) {
+	h.exporter = exp
+	h.AddSampleWriter(exp)
+}
+
+// AddSampleWriter adds a destination for samples collected by RunSampler
+func (h *HandlerManager) AddSampleWriter(w history.Writer) {
+	h.sampleWriters = append(h.sampleWriters, w)
+}
+
+// RunSampler collects samples every interval and hands them to each sample writer until
+// ctx is cancelled. It returns immediately when no writer is configured.
+func (h *HandlerManager) RunSampler(ctx context.Context, interval time.Duration) {
+	if len(h.sampleWriters) == 0 {
+		return
+	}
+	if interval <= 0 {
+		interval = config.DefaultSampleInterval
+	}
+
+	// Prime the CPU counters so the first tick reports usage over one interval, not since boot
+	_, _ = cpu.PercentWithContext(ctx, 0, false)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			h.writeSamples(ctx, h.collectSamples(ctx, now))
+		}
+	}
+}
+
+// writeSamples delivers a batch to every writer, logging failures so one broken
+// destination does not stop the others
+func (h *HandlerManager) writeSamples(ctx context.Context, samples []history.Sample) {
+	for _, w := range h.sampleWriters {
+		if err := w.Write(ctx, samples); err != nil {
+			h.logger.Warn("sampler write failed", "error", err)
+		}
+	}
+}
+
+// collectSamples takes one snapshot of every registered collector. Metrics a collector
+// did return are kept when it also reports an error, and collectors that fail are left out
+// of the batch rather than failing it.
+func (h *HandlerManager) collectSamples(ctx context.Context, now time.Time) []history.Sample {
+	var samples []history.Sample
+	for _, r := range h.collectors.Collect(ctx) {
+		if r.Err != nil {
+			h.logger.Debug("collector failed", "collector", r.Collector, "error", r.Err)
+		}
+		for _, m := range r.Metrics {
+			samples = append(samples, history.Sample{Time: now, Metric: m.Name, Labels: m.Labels, Value: m.Value})
+		}
+	}
+	return samples
+}
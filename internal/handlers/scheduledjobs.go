@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// scheduledJobsTimeout bounds the systemctl queries for timers
+const scheduledJobsTimeout = 10 * time.Second
+
+// Cron sources
+var (
+	systemCrontabPath = "/etc/crontab"
+	cronDPath         = "/etc/cron.d"
+	// Per-user crontabs: Debian keeps them under crontabs/, Red Hat and Arch directly
+	cronSpoolPaths = []string{"/var/spool/cron/crontabs", "/var/spool/cron"}
+	// run-parts directories run by /etc/crontab or anacron
+	cronPeriodicRoot = "/etc"
+)
+
+// cronPeriods are the run-parts directories under cronPeriodicRoot
+var cronPeriods = []string{"hourly", "daily", "weekly", "monthly"}
+
+// Properties read for timers and the units they activate
+var (
+	timerProperties   = []string{"Id", "Description", "Unit", "ActiveState", "NextElapseUSecRealtime", "LastTriggerUSec", "TimersCalendar", "TimersMonotonic", "Persistent"}
+	timerUnitProperty = []string{"Id", "ActiveState", "Result", "ExecMainStatus", "ExecMainExitTimestamp"}
+)
+
+// timerSpecRe extracts the schedule from TimersCalendar and TimersMonotonic, e.g.
+// "{ OnCalendar=*-*-* 00:00:00 ; next_elapse=... }" or "{ OnUnitActiveSec=1d ; ... }"
+var timerSpecRe = regexp.MustCompile(`\{ (On\w+)=([^;]+?) ;`)
+
+// cronEnvRe matches crontab environment assignments such as "MAILTO=root"
+var cronEnvRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*\s*=`)
+
+// scheduledTimer is a systemd timer with its schedule and the last run of its unit
+type scheduledTimer struct {
+	Unit           string   `json:"unit"`
+	Description    string   `json:"description,omitempty"`
+	Activates      string   `json:"activates"`
+	Schedule       []string `json:"schedule"`
+	Persistent     bool     `json:"persistent"`
+	ActiveState    string   `json:"active_state"`
+	NextRun        string   `json:"next_run,omitempty"`
+	LastRun        string   `json:"last_run,omitempty"`
+	LastResult     string   `json:"last_result,omitempty"`
+	LastExitStatus *int     `json:"last_exit_status,omitempty"`
+	Failed         bool     `json:"failed"`
+}
+
+// cronEntry is one crontab line
+type cronEntry struct {
+	Source   string `json:"source"`
+	User     string `json:"user"`
+	Schedule string `json:"schedule"`
+	Command  string `json:"command"`
+}
+
+// HandleGetScheduledJobs lists systemd timers with their next and last runs and crontab
+// entries, flagging timers whose last run failed
+func (h *HandlerManager) HandleGetScheduledJobs(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	failedOnly := false
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if f, ok := args["failed_only"].(bool); ok {
+			failedOnly = f
+		}
+	}
+
+	result := map[string]interface{}{}
+	warnings := []string{}
+	errs := map[string]string{}
+
+	if h.caps.Systemd {
+		ctx, cancel := context.WithTimeout(ctx, scheduledJobsTimeout)
+		defer cancel()
+		timers, err := readTimers(ctx)
+		if err != nil {
+			errs["timers"] = err.Error()
+		}
+		failed := []string{}
+		shown := []scheduledTimer{}
+		for _, t := range timers {
+			if t.Failed {
+				failed = append(failed, t.Unit)
+				status := t.LastResult
+				if t.LastExitStatus != nil {
+					status = fmt.Sprintf("%s, exit status %d", status, *t.LastExitStatus)
+				}
+				warnings = append(warnings, fmt.Sprintf("%s last ran %s and failed (%s); see diagnose_service %s", t.Unit, orDash(t.LastRun), status, t.Activates))
+			}
+			if !failedOnly || t.Failed {
+				shown = append(shown, t)
+			}
+		}
+		result["timers"] = shown
+		result["failed_timers"] = failed
+	}
+
+	if !failedOnly {
+		cron, periodic, unreadable := readCronJobs()
+		result["cron"] = cron
+		result["cron_periodic"] = periodic
+		if len(unreadable) > 0 {
+			errs["cron"] = fmt.Sprintf("could not read %s (per-user crontabs need root)", strings.Join(unreadable, ", "))
+		}
+		result["note"] = "cron does not record whether a job succeeded; only timer failures are detected"
+	}
+	if len(errs) > 0 {
+		result["errors"] = errs
+	}
+	result["warnings"] = warnings
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// readTimers returns every loaded timer with the state of the unit it activates
+func readTimers(ctx context.Context) ([]scheduledTimer, error) {
+	out, err := exec.CommandContext(ctx, "systemctl", "list-units", "--type=timer", "--all",
+		"--no-legend", "--plain", "--no-pager").Output()
+	if err != nil {
+		return nil, fmt.Errorf("systemctl list-units failed: %v", err)
+	}
+	var names []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 && strings.HasSuffix(fields[0], ".timer") && systemdUnitRe.MatchString(fields[0]) {
+			names = append(names, fields[0])
+		}
+	}
+	if len(names) == 0 {
+		return []scheduledTimer{}, nil
+	}
+
+	timerProps, err := showUnits(ctx, names, timerProperties)
+	if err != nil {
+		return nil, err
+	}
+	var activated []string
+	for _, p := range timerProps {
+		if u := p["Unit"]; u != "" && systemdUnitRe.MatchString(u) {
+			activated = append(activated, u)
+		}
+	}
+	unitProps := map[string]map[string]string{}
+	if len(activated) > 0 {
+		if list, err := showUnits(ctx, activated, timerUnitProperty); err == nil {
+			for _, p := range list {
+				unitProps[p["Id"]] = p
+			}
+		}
+	}
+
+	timers := make([]scheduledTimer, 0, len(timerProps))
+	for _, p := range timerProps {
+		timers = append(timers, buildTimer(p, unitProps[p["Unit"]]))
+	}
+	sort.Slice(timers, func(i, j int) bool {
+		if timers[i].Failed != timers[j].Failed {
+			return timers[i].Failed
+		}
+		return timers[i].Unit < timers[j].Unit
+	})
+	return timers, nil
+}
+
+// showUnits runs systemctl show for several units, returning one property map per unit
+func showUnits(ctx context.Context, units, props []string) ([]map[string]string, error) {
+	args := append([]string{"show", "--property=" + strings.Join(props, ","), "--no-pager", "--"}, units...)
+	//nolint:gosec // G204: unit names are validated against systemdUnitRe
+	out, err := exec.CommandContext(ctx, "systemctl", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("systemctl show failed: %v", err)
+	}
+	var list []map[string]string
+	for _, block := range strings.Split(string(out), "\n\n") {
+		if p := parseSystemctlShow(block); p["Id"] != "" {
+			list = append(list, p)
+		}
+	}
+	return list, nil
+}
+
+// buildTimer combines a timer's properties with those of the unit it activates. A run
+// failed when the unit ended in any result other than success or is in the failed state.
+func buildTimer(timer, unit map[string]string) scheduledTimer {
+	t := scheduledTimer{
+		Unit:        timer["Id"],
+		Description: timer["Description"],
+		Activates:   timer["Unit"],
+		ActiveState: timer["ActiveState"],
+		Persistent:  timer["Persistent"] == "yes",
+		Schedule:    []string{},
+	}
+	for _, key := range []string{"TimersCalendar", "TimersMonotonic"} {
+		for _, m := range timerSpecRe.FindAllStringSubmatch(timer[key], -1) {
+			t.Schedule = append(t.Schedule, m[1]+"="+strings.TrimSpace(m[2]))
+		}
+	}
+	if next, ok := parseSystemdTimestamp(timer["NextElapseUSecRealtime"]); ok {
+		t.NextRun = next.UTC().Format(time.RFC3339)
+	}
+	last, ran := parseSystemdTimestamp(timer["LastTriggerUSec"])
+	if ran {
+		t.LastRun = last.UTC().Format(time.RFC3339)
+	}
+	if unit != nil && ran {
+		t.LastResult = unit["Result"]
+		if status, ok := parseSystemdUint(unit["ExecMainStatus"]); ok && unit["ExecMainExitTimestamp"] != "" {
+			s := int(status)
+			t.LastExitStatus = &s
+		}
+		t.Failed = (t.LastResult != "" && t.LastResult != "success") || unit["ActiveState"] == "failed"
+	}
+	return t
+}
+
+// readCronJobs reads the system crontab, /etc/cron.d, and per-user crontabs, and lists
+// the scripts in the periodic run-parts directories. Paths that exist but cannot be
+// read are returned as unreadable.
+func readCronJobs() ([]cronEntry, map[string][]string, []string) {
+	entries := []cronEntry{}
+	var unreadable []string
+	read := func(path, user string) {
+		data, err := os.ReadFile(filepath.Clean(path))
+		switch {
+		case err == nil:
+			entries = append(entries, parseCrontab(string(data), path, user)...)
+		case !os.IsNotExist(err):
+			unreadable = append(unreadable, path)
+		}
+	}
+
+	read(systemCrontabPath, "")
+	if files, err := os.ReadDir(cronDPath); err == nil {
+		for _, f := range files {
+			// cron skips editor backups and package manager leftovers
+			if f.IsDir() || strings.ContainsAny(f.Name(), ".~") {
+				continue
+			}
+			read(filepath.Join(cronDPath, f.Name()), "")
+		}
+	}
+	for _, dir := range cronSpoolPaths {
+		files, err := os.ReadDir(dir)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				unreadable = append(unreadable, dir)
+			}
+			continue
+		}
+		for _, f := range files {
+			if f.IsDir() || strings.HasPrefix(f.Name(), ".") {
+				continue
+			}
+			read(filepath.Join(dir, f.Name()), f.Name())
+		}
+	}
+
+	periodic := map[string][]string{}
+	for _, period := range cronPeriods {
+		files, err := os.ReadDir(filepath.Join(cronPeriodicRoot, "cron."+period))
+		if err != nil {
+			continue
+		}
+		scripts := []string{}
+		for _, f := range files {
+			if !f.IsDir() && !strings.HasPrefix(f.Name(), ".") {
+				scripts = append(scripts, f.Name())
+			}
+		}
+		periodic[period] = scripts
+	}
+	return entries, periodic, unreadable
+}
+
+// parseCrontab parses crontab lines. System crontabs have a user column after the
+// schedule; for a user's own crontab user is the owner.
+func parseCrontab(data, source, user string) []cronEntry {
+	entries := []cronEntry{}
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || cronEnvRe.MatchString(line) {
+			continue
+		}
+		fields := strings.Fields(line)
+		scheduleFields := 5
+		if strings.HasPrefix(fields[0], "@") {
+			scheduleFields = 1
+		}
+		need := scheduleFields + 1
+		if user == "" {
+			need++
+		}
+		if len(fields) < need {
+			continue
+		}
+		e := cronEntry{Source: source, User: user, Schedule: strings.Join(fields[:scheduleFields], " ")}
+		rest := fields[scheduleFields:]
+		if user == "" {
+			e.User, rest = rest[0], rest[1:]
+		}
+		e.Command = strings.Join(rest, " ")
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// orDash returns s, or "-" when it is empty
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseCrontab(t *testing.T) {
+	system := `SHELL=/bin/sh
+PATH=/usr/local/sbin:/usr/local/bin:/sbin:/bin
+# m h dom mon dow user	command
+17 *	* * *	root	cd / && run-parts --report /etc/cron.hourly
+@reboot root /usr/local/bin/warmup
+bad line
+`
+	entries := parseCrontab(system, "/etc/crontab", "")
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %+v", entries)
+	}
+	if e := entries[0]; e.Schedule != "17 * * * *" || e.User != "root" || e.Command != "cd / && run-parts --report /etc/cron.hourly" {
+		t.Errorf("Unexpected entry: %+v", e)
+	}
+	if e := entries[1]; e.Schedule != "@reboot" || e.User != "root" || e.Command != "/usr/local/bin/warmup" {
+		t.Errorf("Unexpected @reboot entry: %+v", e)
+	}
+
+	user := parseCrontab("MAILTO=\"\"\n*/5 * * * * /home/alice/bin/sync.sh >/dev/null 2>&1\n", "/var/spool/cron/crontabs/alice", "alice")
+	if len(user) != 1 || user[0].User != "alice" || user[0].Command != "/home/alice/bin/sync.sh >/dev/null 2>&1" {
+		t.Errorf("Unexpected user crontab entries: %+v", user)
+	}
+}
+
+func TestBuildTimer(t *testing.T) {
+	timer := parseSystemctlShow(`Id=backup.timer
+Description=Nightly backup
+Unit=backup.service
+ActiveState=active
+NextElapseUSecRealtime=Tue 2026-10-13 02:00:00 UTC
+LastTriggerUSec=Mon 2026-10-12 02:00:03 UTC
+TimersCalendar={ OnCalendar=*-*-* 02:00:00 ; next_elapse=Tue 2026-10-13 02:00:00 UTC }
+TimersMonotonic=
+Persistent=yes
+`)
+	unit := parseSystemctlShow(`Id=backup.service
+ActiveState=failed
+Result=exit-code
+ExecMainStatus=2
+ExecMainExitTimestamp=Mon 2026-10-12 02:00:09 UTC
+`)
+	got := buildTimer(timer, unit)
+	if !got.Failed || got.LastResult != "exit-code" || got.LastExitStatus == nil || *got.LastExitStatus != 2 {
+		t.Errorf("Expected a failed run with exit status 2, got %+v", got)
+	}
+	if len(got.Schedule) != 1 || got.Schedule[0] != "OnCalendar=*-*-* 02:00:00" || !got.Persistent {
+		t.Errorf("Unexpected schedule: %+v", got)
+	}
+	if got.NextRun == "" || got.LastRun == "" {
+		t.Errorf("Expected next and last run times, got %+v", got)
+	}
+
+	unit["ActiveState"], unit["Result"] = "inactive", "success"
+	if got := buildTimer(timer, unit); got.Failed {
+		t.Errorf("Expected a successful run, got %+v", got)
+	}
+
+	// A timer that never fired is not failed, whatever its unit's state
+	timer["LastTriggerUSec"] = "n/a"
+	unit["ActiveState"], unit["Result"] = "failed", "exit-code"
+	if got := buildTimer(timer, unit); got.Failed || got.LastRun != "" {
+		t.Errorf("Expected a timer that never ran to not be failed, got %+v", got)
+	}
+
+	monotonic := parseSystemctlShow("Id=fstrim.timer\nTimersMonotonic={ OnBootUSec=15min ; next_elapse=0 } { OnUnitActiveUSec=1d ; next_elapse=0 }\n")
+	if got := buildTimer(monotonic, nil); len(got.Schedule) != 2 || got.Schedule[1] != "OnUnitActiveUSec=1d" {
+		t.Errorf("Unexpected monotonic schedule: %v", got.Schedule)
+	}
+}
+
+func TestReadCronJobs(t *testing.T) {
+	dir := t.TempDir()
+	oldCrontab, oldCronD, oldSpool, oldRoot := systemCrontabPath, cronDPath, cronSpoolPaths, cronPeriodicRoot
+	t.Cleanup(func() {
+		systemCrontabPath, cronDPath, cronSpoolPaths, cronPeriodicRoot = oldCrontab, oldCronD, oldSpool, oldRoot
+	})
+	systemCrontabPath = filepath.Join(dir, "crontab")
+	cronDPath = filepath.Join(dir, "cron.d")
+	cronSpoolPaths = []string{filepath.Join(dir, "spool")}
+	cronPeriodicRoot = dir
+
+	writeSysfsFiles(t, dir, map[string]string{"crontab": "0 4 * * * root /usr/bin/updatedb"})
+	writeSysfsFiles(t, cronDPath, map[string]string{
+		"certbot":          "0 */12 * * * root certbot -q renew",
+		"certbot.dpkg-old": "0 0 * * * root old",
+	})
+	writeSysfsFiles(t, cronSpoolPaths[0], map[string]string{"alice": "@daily /home/alice/backup.sh"})
+	writeSysfsFiles(t, filepath.Join(dir, "cron.daily"), map[string]string{"logrotate": "#!/bin/sh", ".placeholder": ""})
+	writeSysfsFiles(t, filepath.Join(dir, "cron.weekly"), map[string]string{"man-db": "#!/bin/sh"})
+
+	entries, periodic, unreadable := readCronJobs()
+	if len(entries) != 3 || len(unreadable) != 0 {
+		t.Fatalf("Expected 3 cron entries, got %+v (unreadable %v)", entries, unreadable)
+	}
+	if entries[2].User != "alice" || entries[2].Schedule != "@daily" {
+		t.Errorf("Unexpected user entry: %+v", entries[2])
+	}
+	if len(periodic["daily"]) != 1 || periodic["daily"][0] != "logrotate" || len(periodic["weekly"]) != 1 {
+		t.Errorf("Unexpected periodic scripts: %v", periodic)
+	}
+	if _, ok := periodic["hourly"]; ok {
+		t.Errorf("Expected missing directories to be left out, got %v", periodic)
+	}
+
+	if os.Geteuid() != 0 {
+		if err := os.Chmod(cronSpoolPaths[0], 0); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = os.Chmod(cronSpoolPaths[0], 0o755) })
+		if _, _, unreadable := readCronJobs(); len(unreadable) != 1 {
+			t.Errorf("Expected the spool directory to be unreadable, got %v", unreadable)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"sysmetrics-mcp/internal/capabilities"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Self-test limits
+const (
+	defaultSelfTestTimeout = 10 * time.Second
+	maxSelfTestTimeout     = 60 * time.Second
+	selfTestSlowThreshold  = 2 * time.Second
+)
+
+// Self-test outcomes for a single tool
+const (
+	selfTestOK       = "ok"
+	selfTestDegraded = "degraded"
+	selfTestError    = "error"
+	selfTestTimeout  = "timeout"
+	selfTestSkipped  = "skipped"
+)
+
+// permissionMarkers are substrings of tool output that point at missing privileges
+var permissionMarkers = []string{"permission denied", "not permitted", "access denied", "requires root"}
+
+// selfTestTarget is a registered tool that self_test can call
+type selfTestTarget struct {
+	tool    mcp.Tool
+	handler server.ToolHandlerFunc
+}
+
+// selfTestResult is the outcome of calling one tool
+type selfTestResult struct {
+	Tool              string `json:"tool"`
+	Status            string `json:"status"`
+	DurationMS        int64  `json:"duration_ms"`
+	Error             string `json:"error,omitempty"`
+	PermissionProblem bool   `json:"permission_problem,omitempty"`
+	Reason            string `json:"reason,omitempty"`
+}
+
+// HandleSelfTest calls every registered tool with default arguments and a timeout, and
+// reports which work on this host, how long each takes, and any permission problems
+func (h *HandlerManager) HandleSelfTest(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	timeout := defaultSelfTestTimeout
+	var only []string
+
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if t, ok := args["timeout_seconds"].(float64); ok && t > 0 {
+			timeout = time.Duration(t * float64(time.Second))
+			if timeout > maxSelfTestTimeout {
+				timeout = maxSelfTestTimeout
+			}
+		}
+		if s, ok := args["tools"].(string); ok && s != "" {
+			for _, name := range strings.Split(s, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					only = append(only, name)
+				}
+			}
+		}
+	}
+
+	for _, name := range only {
+		if _, ok := h.selfTests[name]; !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("Tool %q is not registered", name)), nil
+		}
+	}
+
+	names := make([]string, 0, len(h.selfTests))
+	for name := range h.selfTests {
+		if len(only) == 0 || contains(only, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	start := time.Now()
+	results := make([]selfTestResult, 0, len(names))
+	counts := map[string]int{}
+	var slow, permissionProblems []string
+	for _, name := range names {
+		if ctx.Err() != nil {
+			break
+		}
+		r := h.runSelfTest(ctx, name, timeout)
+		results = append(results, r)
+		counts[r.Status]++
+		if r.PermissionProblem {
+			permissionProblems = append(permissionProblems, name)
+		}
+		if r.Status != selfTestSkipped && time.Duration(r.DurationMS)*time.Millisecond >= selfTestSlowThreshold {
+			slow = append(slow, name)
+		}
+	}
+
+	// Collectors that need privileges can still succeed with partial data, so report the
+	// permission probes alongside the tool results
+	degraded := []capabilities.PermissionCheck{}
+	for _, c := range capabilities.CheckPermissions(h.caps) {
+		if !c.Accessible {
+			degraded = append(degraded, c)
+		}
+	}
+
+	result := map[string]interface{}{
+		"tested":              len(results) - counts[selfTestSkipped],
+		"passed":              counts[selfTestOK],
+		"degraded":            counts[selfTestDegraded],
+		"failed":              counts[selfTestError] + counts[selfTestTimeout],
+		"skipped":             counts[selfTestSkipped],
+		"timeout_seconds":     timeout.Seconds(),
+		"total_duration_ms":   time.Since(start).Milliseconds(),
+		"results":             results,
+		"permission_problems": degraded,
+		"unregistered_tools":  h.skippedTools,
+	}
+	if len(permissionProblems) > 0 {
+		result["tools_with_permission_problems"] = permissionProblems
+	}
+	if len(slow) > 0 {
+		result["slow_tools"] = slow
+	}
+	if ctx.Err() != nil {
+		result["note"] = "Self-test was cancelled before all tools ran"
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// runSelfTest calls one tool with no arguments. A handler that ignores its context is
+// abandoned at the timeout rather than holding up the rest of the run.
+func (h *HandlerManager) runSelfTest(ctx context.Context, name string, timeout time.Duration) selfTestResult {
+	r := selfTestResult{Tool: name}
+	target := h.selfTests[name]
+	if reason := h.toolTraits[name].selfTestSkipReason(); reason != "" {
+		r.Status, r.Reason = selfTestSkipped, reason
+		return r
+	}
+	if len(target.tool.InputSchema.Required) > 0 {
+		r.Status = selfTestSkipped
+		r.Reason = "requires arguments: " + strings.Join(target.tool.InputSchema.Required, ", ")
+		return r
+	}
+
+	type outcome struct {
+		res *mcp.CallToolResult
+		err error
+	}
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	done := make(chan outcome, 1)
+	start := time.Now()
+	go func() {
+		req := mcp.CallToolRequest{}
+		req.Params.Name = name
+		req.Params.Arguments = map[string]interface{}{}
+		res, err := target.handler(callCtx, req)
+		done <- outcome{res, err}
+	}()
+
+	var out outcome
+	select {
+	case out = <-done:
+	case <-callCtx.Done():
+		r.DurationMS = time.Since(start).Milliseconds()
+		r.Status = selfTestTimeout
+		r.Error = fmt.Sprintf("no result within %s", timeout)
+		return r
+	}
+	r.DurationMS = time.Since(start).Milliseconds()
+
+	switch {
+	case out.err != nil:
+		r.Status, r.Error = selfTestError, out.err.Error()
+	case out.res == nil:
+		r.Status, r.Error = selfTestError, "empty result"
+	case out.res.IsError:
+		r.Status, r.Error = selfTestError, truncateString(resultText(out.res), 300)
+	default:
+		r.Status = selfTestOK
+	}
+
+	text := strings.ToLower(resultText(out.res))
+	if out.err != nil {
+		text = strings.ToLower(out.err.Error())
+	}
+	if containsAny(text, permissionMarkers) {
+		r.PermissionProblem = true
+		if r.Status == selfTestOK {
+			r.Status = selfTestDegraded
+		}
+	}
+	return r
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestHandleSelfTest(t *testing.T) {
+	h := NewHandlerManager(&config.Config{})
+	s := server.NewMCPServer("test", "0.0.0")
+	ok := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(`{"value":1}`), nil
+	}
+	h.addTool(s, mcp.NewTool("t_ok"), ok)
+	h.addTool(s, mcp.NewTool("t_denied"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(`{"error":"open /proc/1/fd: permission denied"}`), nil
+	})
+	h.addTool(s, mcp.NewTool("t_error"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return nil, errors.New("boom")
+	})
+	h.addTool(s, mcp.NewTool("t_hang"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		time.Sleep(2 * time.Second)
+		return mcp.NewToolResultText(`{}`), nil
+	})
+	h.addTool(s, mcp.NewTool("t_args", mcp.WithString("urls", mcp.Required())), ok)
+	h.addTool(s, mcp.NewTool("t_action"), ok, hostAction("changes the host"))
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"timeout_seconds": 0.2}
+	res, err := h.HandleSelfTest(context.Background(), req)
+	checkToolResult(t, res, err, []string{"tested", "passed", "failed", "skipped", "results", "permission_problems", "unregistered_tools"})
+
+	var data struct {
+		Tested  int              `json:"tested"`
+		Skipped int              `json:"skipped"`
+		Results []selfTestResult `json:"results"`
+	}
+	if err := json.Unmarshal([]byte(resultText(res)), &data); err != nil {
+		t.Fatalf("Failed to decode result: %v", err)
+	}
+	if data.Tested != 4 || data.Skipped != 2 {
+		t.Errorf("Expected 4 tested and 2 skipped, got %d and %d", data.Tested, data.Skipped)
+	}
+
+	want := map[string]string{
+		"t_ok":     selfTestOK,
+		"t_denied": selfTestDegraded,
+		"t_error":  selfTestError,
+		"t_hang":   selfTestTimeout,
+		"t_args":   selfTestSkipped,
+		"t_action": selfTestSkipped,
+	}
+	for _, r := range data.Results {
+		if want[r.Tool] != r.Status {
+			t.Errorf("%s: status = %q, want %q", r.Tool, r.Status, want[r.Tool])
+		}
+		if r.Tool == "t_denied" && !r.PermissionProblem {
+			t.Errorf("Expected t_denied to report a permission problem")
+		}
+	}
+	if len(data.Results) != len(want) {
+		t.Errorf("Expected %d results, got %d", len(want), len(data.Results))
+	}
+}
+
+func TestHandleSelfTestUnknownTool(t *testing.T) {
+	h := NewHandlerManager(&config.Config{})
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"tools": "get_bogus"}
+	res, err := h.HandleSelfTest(context.Background(), req)
+	if err != nil || !res.IsError {
+		t.Fatalf("Expected a tool error for an unregistered tool, got %v, %v", res, err)
+	}
+}
+
+func TestHandleSelfTestLeavesNoFiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+	h := newHistoryTestManager(t)
+	h.RegisterTools(server.NewMCPServer("test", "0.0.0"))
+	dirs := []string{home, filepath.Dir(h.history.Path())}
+
+	files := func() map[string]bool {
+		t.Helper()
+		found := map[string]bool{}
+		for _, dir := range dirs {
+			err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+				if err == nil && !d.IsDir() {
+					found[path] = true
+				}
+				return err
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		return found
+	}
+	before := files()
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"timeout_seconds": float64(2)}
+	res, err := h.HandleSelfTest(context.Background(), req)
+	checkToolResult(t, res, err, []string{"tested", "results"})
+	for path := range files() {
+		if !before[path] {
+			t.Errorf("self_test created %s", path)
+		}
+	}
+
+	var data struct {
+		Results []selfTestResult `json:"results"`
+	}
+	if err := json.Unmarshal([]byte(resultText(res)), &data); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range data.Results {
+		if (r.Tool == "export_history" || r.Tool == "import_history") && r.Status != selfTestSkipped {
+			t.Errorf("%s: status = %q, want %q", r.Tool, r.Status, selfTestSkipped)
+		}
+	}
+}
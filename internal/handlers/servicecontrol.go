@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// serviceControlTimeout bounds how long systemctl may wait for a unit to change state
+const serviceControlTimeout = 90 * time.Second
+
+// serviceActions are the systemctl verbs control_service accepts
+var serviceActions = []string{"start", "stop", "restart"}
+
+// serviceUnitName adds the .service suffix to bare names, so "nginx" and
+// "nginx.service" refer to the same unit
+func serviceUnitName(name string) string {
+	if strings.Contains(name, ".") {
+		return name
+	}
+	return name + ".service"
+}
+
+// serviceControlAllowed reports whether unit is in --service-control-allowlist
+func (h *HandlerManager) serviceControlAllowed(unit string) bool {
+	for _, allowed := range h.cfg.ServiceControlAllowlist {
+		if serviceUnitName(allowed) == unit {
+			return true
+		}
+	}
+	return false
+}
+
+// HandleControlService starts, stops, or restarts an allowlisted systemd unit and reports
+// its state before and after
+func (h *HandlerManager) HandleControlService(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var service, action string
+	confirm := false
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if s, ok := args["service"].(string); ok {
+			service = strings.TrimSpace(s)
+		}
+		if a, ok := args["action"].(string); ok {
+			action = strings.ToLower(strings.TrimSpace(a))
+		}
+		if c, ok := args["confirm"].(bool); ok {
+			confirm = c
+		}
+	}
+
+	if service == "" {
+		return mcp.NewToolResultError("service is required"), nil
+	}
+	if !contains(serviceActions, action) {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid action: %q (must be start, stop, or restart)", action)), nil
+	}
+	unit := serviceUnitName(service)
+	if !h.serviceControlAllowed(unit) {
+		return mcp.NewToolResultError(fmt.Sprintf("Service %q is not in --service-control-allowlist (allowed: %s)",
+			service, strings.Join(h.cfg.ServiceControlAllowlist, ", "))), nil
+	}
+	if !confirm {
+		return mcp.NewToolResultError(fmt.Sprintf("confirm must be true to %s %s", action, unit)), nil
+	}
+
+	before := getServiceInfo(ctx, unit)
+
+	cmdCtx, cancel := context.WithTimeout(ctx, serviceControlTimeout)
+	defer cancel()
+	h.logger.Warn("service control", "action", action, "unit", unit)
+	start := time.Now()
+	out, err := h.privilegedCommand(cmdCtx, "systemctl", action, "--no-ask-password", "--", unit).CombinedOutput()
+	elapsed := time.Since(start)
+
+	result := map[string]interface{}{
+		"service":     unit,
+		"action":      action,
+		"success":     err == nil,
+		"duration_ms": elapsed.Milliseconds(),
+		"before":      before,
+		"after":       getServiceInfo(ctx, unit),
+	}
+	if output := strings.TrimSpace(string(out)); output != "" {
+		result["output"] = truncateString(output, 2000)
+	}
+	if err != nil {
+		result["error"] = fmt.Sprintf("systemctl %s failed: %v", action, err)
+		if containsAny(strings.ToLower(string(out)), permissionMarkers) || strings.Contains(string(out), "Interactive authentication required") {
+			result["hint"] = "The server lacks permission to manage units. Run it as root, add systemctl to --sudo-allowlist, or grant a polkit rule for org.freedesktop.systemd1.manage-units."
+		}
+		h.logger.Error("service control failed", "action", action, "unit", unit, "error", err)
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
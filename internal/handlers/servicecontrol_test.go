@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestServiceUnitName(t *testing.T) {
+	tests := map[string]string{
+		"nginx":         "nginx.service",
+		"nginx.service": "nginx.service",
+		"backup.timer":  "backup.timer",
+		"getty@tty1":    "getty@tty1.service",
+		"docker.socket": "docker.socket",
+	}
+	for in, want := range tests {
+		if got := serviceUnitName(in); got != want {
+			t.Errorf("serviceUnitName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestHandleControlServiceRejects(t *testing.T) {
+	h := NewHandlerManager(&config.Config{ServiceControlAllowlist: []string{"nginx", "backup.timer"}})
+
+	tests := []struct {
+		name    string
+		args    map[string]interface{}
+		wantErr string
+	}{
+		{"missing service", map[string]interface{}{"action": "restart", "confirm": true}, "service is required"},
+		{"unknown action", map[string]interface{}{"service": "nginx", "action": "mask", "confirm": true}, "Invalid action"},
+		{"not allowlisted", map[string]interface{}{"service": "sshd", "action": "stop", "confirm": true}, "not in --service-control-allowlist"},
+		{"different unit type", map[string]interface{}{"service": "backup.service", "action": "start", "confirm": true}, "not in --service-control-allowlist"},
+		{"not confirmed", map[string]interface{}{"service": "nginx.service", "action": "restart"}, "confirm must be true"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := mcp.CallToolRequest{}
+			req.Params.Arguments = tc.args
+			res, err := h.HandleControlService(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Handler returned error: %v", err)
+			}
+			if !res.IsError || !strings.Contains(resultText(res), tc.wantErr) {
+				t.Errorf("Expected error containing %q, got %q", tc.wantErr, resultText(res))
+			}
+		})
+	}
+}
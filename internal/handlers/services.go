@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"sysmetrics-mcp/internal/capabilities"
+)
+
+// serviceQueryTimeout bounds one service status query
+const serviceQueryTimeout = 10 * time.Second
+
+// serviceNameRe is a service name that is safe to pass to an init script or service
+// manager and to join to a service directory
+var serviceNameRe = regexp.MustCompile(`^[A-Za-z0-9@_:][A-Za-z0-9@_:.+-]*$`)
+
+// serviceManager reads service status from the host's init system. Every result has
+// name, available, and backend, and the systemd-style load_state, active_state,
+// sub_state, description, and main_pid where the init system knows them.
+type serviceManager interface {
+	// Name is the backend reported with each service
+	Name() string
+	// Status returns the status of each named service. user selects per-user services
+	// where the init system has them.
+	Status(ctx context.Context, names []string, user bool) []map[string]interface{}
+}
+
+// newServiceManager returns the service manager detected at startup, preferring systemd
+// when several init systems are installed, or nil when there is none
+func newServiceManager(caps capabilities.Capabilities) serviceManager {
+	switch {
+	case caps.Systemd, caps.WindowsSCM, caps.Launchd:
+		return nativeServices{}
+	case caps.OpenRC:
+		return openrcServices{}
+	case caps.Runit:
+		return runitServices{dir: runitServiceDir()}
+	case caps.InitScripts:
+		return sysvServices{dir: initScriptsDir}
+	}
+	return nil
+}
+
+// nativeServices queries the platform's own service manager: systemd on Linux, the
+// Service Control Manager on Windows, or launchd on macOS
+type nativeServices struct{}
+
+// Name returns the platform's service manager
+func (nativeServices) Name() string {
+	switch runtime.GOOS {
+	case "windows":
+		return "scm"
+	case "darwin":
+		return "launchd"
+	}
+	return "systemd"
+}
+
+// Status returns getServicesInfo for the platform
+func (nativeServices) Status(ctx context.Context, names []string, user bool) []map[string]interface{} {
+	return getServicesInfo(ctx, names, user)
+}
+
+// newServiceResult starts a service's result, rejecting names that could escape a
+// service directory or be taken as an option
+func newServiceResult(name, backend string) (map[string]interface{}, bool) {
+	result := map[string]interface{}{
+		"name":      name,
+		"backend":   backend,
+		"available": false,
+	}
+	if !serviceNameRe.MatchString(name) || strings.Contains(name, "..") {
+		result["error"] = "Invalid service name"
+		return result, false
+	}
+	return result, true
+}
+
+// setServiceState fills the systemd-style state fields of a result
+func setServiceState(result map[string]interface{}, load, active, sub string) {
+	result["available"] = true
+	result["load_state"] = load
+	result["active_state"] = active
+	result["sub_state"] = sub
+	if _, ok := result["main_pid"]; !ok {
+		result["main_pid"] = "0"
+	}
+}
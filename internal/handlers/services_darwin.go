@@ -0,0 +1,60 @@
+//go:build darwin
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+)
+
+// getServiceInfo queries launchd for service information
+func getServiceInfo(ctx context.Context, serviceName string) map[string]interface{} {
+	result := map[string]interface{}{
+		"name": serviceName,
+	}
+
+	//nolint:gosec // G204: launchctl receives the label as a single argument, not via a shell
+	output, err := exec.CommandContext(ctx, "launchctl", "list", serviceName).Output()
+	if err != nil {
+		result["error"] = fmt.Sprintf("Failed to query service: %v", err)
+		result["available"] = false
+		return result
+	}
+
+	values := parseLaunchctlList(string(output))
+	result["available"] = true
+	result["backend"] = "launchd"
+	result["load_state"] = "loaded"
+	result["description"] = values["Label"]
+
+	if pid, ok := values["PID"]; ok {
+		result["active_state"] = "active"
+		result["sub_state"] = "running"
+		result["main_pid"] = pid
+	} else {
+		result["active_state"] = "inactive"
+		result["sub_state"] = "waiting"
+		result["main_pid"] = "0"
+	}
+	if status, ok := values["LastExitStatus"]; ok {
+		result["last_exit_status"] = status
+	}
+	if program, ok := values["Program"]; ok {
+		result["program"] = program
+	}
+
+	return result
+}
+
+// getServicesInfo returns the status of each service. User services are a systemd
+// concept, so user is ignored.
+func getServicesInfo(ctx context.Context, names []string, _ bool) []map[string]interface{} {
+	ctx, cancel := context.WithTimeout(ctx, serviceQueryTimeout)
+	defer cancel()
+	results := make([]map[string]interface{}, 0, len(names))
+	for _, name := range names {
+		results = append(results, getServiceInfo(ctx, name))
+	}
+	return results
+}
@@ -0,0 +1,92 @@
+//go:build linux
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	sdbus "github.com/coreos/go-systemd/v22/dbus"
+)
+
+// systemdBus holds the D-Bus connections to the system and user service managers. They
+// are opened on first use and reopened after the bus drops them.
+var systemdBus struct {
+	mu           sync.Mutex
+	system, user *sdbus.Conn
+}
+
+// systemdConn returns a connection to the system or the server user's service manager
+func systemdConn(ctx context.Context, user bool) (serviceBus, error) {
+	systemdBus.mu.Lock()
+	defer systemdBus.mu.Unlock()
+	conn := &systemdBus.system
+	dial := sdbus.NewSystemConnectionContext
+	if user {
+		conn, dial = &systemdBus.user, sdbus.NewUserConnectionContext
+	}
+	if *conn != nil && (*conn).Connected() {
+		return dbusServiceBus{*conn}, nil
+	}
+	c, err := dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	*conn = c
+	return dbusServiceBus{c}, nil
+}
+
+// dbusServiceBus is a serviceBus on a go-systemd D-Bus connection
+type dbusServiceBus struct {
+	conn *sdbus.Conn
+}
+
+// matchUnits returns the loaded units matching a glob pattern
+func (b dbusServiceBus) matchUnits(ctx context.Context, pattern string) ([]string, error) {
+	list, err := b.conn.ListUnitsByPatternsContext(ctx, nil, []string{pattern})
+	if err != nil {
+		return nil, err
+	}
+	units := make([]string, 0, len(list))
+	for _, u := range list {
+		units = append(units, u.Name)
+	}
+	sort.Strings(units)
+	return units, nil
+}
+
+// unitProperties reads a unit's properties. The main process start time is returned
+// separately, as D-Bus reports it in microseconds since the epoch.
+func (b dbusServiceBus) unitProperties(ctx context.Context, unit string) (map[string]string, time.Time, error) {
+	unitProps, err := b.conn.GetUnitPropertiesContext(ctx, unit)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	props := map[string]string{}
+	for _, key := range serviceUnitProperties {
+		if v, ok := unitProps[key]; ok {
+			props[key] = fmt.Sprint(v)
+		}
+	}
+	var started time.Time
+	// Service properties only exist for loaded .service units
+	if strings.HasSuffix(unit, ".service") && props["LoadState"] == "loaded" {
+		svcProps, err := b.conn.GetUnitTypePropertiesContext(ctx, unit, "Service")
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		for _, key := range serviceUnitProperties {
+			if v, ok := svcProps[key]; ok {
+				props[key] = fmt.Sprint(v)
+			}
+		}
+		if usec, ok := svcProps["ExecMainStartTimestamp"].(uint64); ok && usec > 0 {
+			started = time.UnixMicro(int64(usec))
+		}
+	}
+	return props, started, nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !windows && !darwin
+
+package handlers
+
+import (
+	"context"
+	"errors"
+)
+
+// systemdConn reports that D-Bus is not used here; services are read with systemctl
+func systemdConn(ctx context.Context, user bool) (serviceBus, error) {
+	return nil, errors.New("the systemd D-Bus API is only used on Linux")
+}
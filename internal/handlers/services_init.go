@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Init script locations, shared by OpenRC and SysV init
+var (
+	initScriptsDir = "/etc/init.d"
+	initRCRoot     = "/etc"
+	runPIDDir      = "/run"
+)
+
+// runitServiceDirs are where runit looks for supervised services: Void links them into
+// /var/service, other distributions and container images use /etc/service
+var runitServiceDirs = []string{"/var/service", "/etc/service"}
+
+var (
+	// openrcStatusRe matches rc-service's " * status: started" line
+	openrcStatusRe = regexp.MustCompile(`status:\s*(\w+)`)
+	// openrcDescriptionRe matches an OpenRC script's description="..." line
+	openrcDescriptionRe = regexp.MustCompile(`(?m)^description=["']?([^"'\n]*)`)
+	// svStatusRe matches the first part of `sv status`, e.g.
+	// "run: /var/service/sshd: (pid 812) 3600s; run: log: (pid 810) 3600s"
+	svStatusRe = regexp.MustCompile(`^(run|down|finish): [^:]+: (?:\(pid (\d+)\) )?(\d+)s([^;]*)`)
+	// lsbDescriptionRe matches the LSB header's Short-Description, or a chkconfig description
+	lsbDescriptionRe = regexp.MustCompile(`(?mi)^#\s*(?:Short-Description|description):\s*(.+)$`)
+)
+
+// openrcServices queries OpenRC with rc-service, as on Alpine
+type openrcServices struct{}
+
+// Name returns "openrc"
+func (openrcServices) Name() string { return "openrc" }
+
+// Status runs `rc-service <name> status` for each service
+func (o openrcServices) Status(ctx context.Context, names []string, _ bool) []map[string]interface{} {
+	ctx, cancel := context.WithTimeout(ctx, serviceQueryTimeout)
+	defer cancel()
+
+	runlevels := map[string][]string{}
+	if out, err := exec.CommandContext(ctx, "rc-update", "show").Output(); err == nil {
+		runlevels = parseRCUpdateShow(string(out))
+	}
+
+	results := make([]map[string]interface{}, 0, len(names))
+	for _, name := range names {
+		result, ok := newServiceResult(name, o.Name())
+		if !ok {
+			results = append(results, result)
+			continue
+		}
+		script := filepath.Join(initScriptsDir, name)
+		if _, err := os.Stat(script); err != nil {
+			setServiceState(result, "not-found", "inactive", "dead")
+			results = append(results, result)
+			continue
+		}
+
+		//nolint:gosec // G204: name is validated against serviceNameRe
+		out, _ := exec.CommandContext(ctx, "rc-service", name, "status").CombinedOutput()
+		status := ""
+		if m := openrcStatusRe.FindStringSubmatch(string(out)); m != nil {
+			status = m[1]
+		}
+		active, sub := openrcState(status)
+		if pid := readPIDFile(filepath.Join(runPIDDir, name+".pid")); pid != "" && active == "active" {
+			result["main_pid"] = pid
+		}
+		setServiceState(result, "loaded", active, sub)
+		result["description"] = scriptDescription(script, openrcDescriptionRe)
+		result["runlevels"] = runlevels[name]
+		if result["runlevels"] == nil {
+			result["runlevels"] = []string{}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// openrcState maps an OpenRC status to systemd-style active and sub states
+func openrcState(status string) (active, sub string) {
+	switch status {
+	case "started":
+		return "active", "running"
+	case "starting":
+		return "activating", "starting"
+	case "stopping":
+		return "deactivating", "stopping"
+	case "stopped", "inactive":
+		return "inactive", status
+	case "crashed":
+		return "failed", "crashed"
+	case "":
+		return "unknown", "unknown"
+	}
+	return "inactive", status
+}
+
+// parseRCUpdateShow parses `rc-update show` lines such as "  sshd | default boot" into
+// each service's runlevels
+func parseRCUpdateShow(out string) map[string][]string {
+	runlevels := map[string][]string{}
+	for _, line := range strings.Split(out, "\n") {
+		name, levels, ok := strings.Cut(line, "|")
+		if !ok {
+			continue
+		}
+		runlevels[strings.TrimSpace(name)] = strings.Fields(levels)
+	}
+	return runlevels
+}
+
+// runitServices queries runit with sv, as on Void and in runit-based container images
+type runitServices struct {
+	dir string
+}
+
+// runitServiceDir returns the first runit service directory that exists
+func runitServiceDir() string {
+	if dir := os.Getenv("SVDIR"); dir != "" {
+		return dir
+	}
+	for _, dir := range runitServiceDirs {
+		if _, err := os.Stat(dir); err == nil {
+			return dir
+		}
+	}
+	return runitServiceDirs[0]
+}
+
+// Name returns "runit"
+func (runitServices) Name() string { return "runit" }
+
+// Status runs `sv status` for each service
+func (r runitServices) Status(ctx context.Context, names []string, _ bool) []map[string]interface{} {
+	ctx, cancel := context.WithTimeout(ctx, serviceQueryTimeout)
+	defer cancel()
+
+	results := make([]map[string]interface{}, 0, len(names))
+	for _, name := range names {
+		result, ok := newServiceResult(name, r.Name())
+		if !ok {
+			results = append(results, result)
+			continue
+		}
+		dir := filepath.Join(r.dir, name)
+		if _, err := os.Stat(dir); err != nil {
+			setServiceState(result, "not-found", "inactive", "dead")
+			results = append(results, result)
+			continue
+		}
+
+		//nolint:gosec // G204: name is validated against serviceNameRe
+		out, err := exec.CommandContext(ctx, "sv", "status", dir).CombinedOutput()
+		st, ok := parseSvStatus(string(out))
+		if !ok {
+			result["error"] = fmt.Sprintf("sv status failed: %s", strings.TrimSpace(string(out)))
+			if err != nil && strings.TrimSpace(string(out)) == "" {
+				result["error"] = fmt.Sprintf("sv status failed: %v", err)
+			}
+			results = append(results, result)
+			continue
+		}
+		if st.pid != "" {
+			result["main_pid"] = st.pid
+		}
+		setServiceState(result, "loaded", st.active, st.sub)
+		if st.active == "active" {
+			result["uptime_seconds"] = st.seconds
+			result["started_at"] = time.Now().Add(-time.Duration(st.seconds) * time.Second).UTC().Format(time.RFC3339)
+		}
+		// A down file keeps the service from starting at boot
+		_, downErr := os.Stat(filepath.Join(dir, "down"))
+		result["enabled"] = errors.Is(downErr, os.ErrNotExist)
+		results = append(results, result)
+	}
+	return results
+}
+
+// svStatus is the parsed first part of `sv status` output
+type svStatus struct {
+	active, sub string
+	pid         string
+	seconds     int64
+}
+
+// parseSvStatus parses `sv status` output. A service that is down although it is
+// normally up has stopped on its own and runsv has not brought it back, so it is failed.
+func parseSvStatus(out string) (svStatus, bool) {
+	m := svStatusRe.FindStringSubmatch(strings.TrimSpace(out))
+	if m == nil {
+		return svStatus{}, false
+	}
+	st := svStatus{pid: m[2]}
+	st.seconds, _ = strconv.ParseInt(m[3], 10, 64)
+	switch m[1] {
+	case "run":
+		st.active, st.sub = "active", "running"
+	case "finish":
+		st.active, st.sub = "deactivating", "finish"
+	default:
+		st.active, st.sub = "inactive", "down"
+		if strings.Contains(m[4], "normally up") {
+			st.active = "failed"
+		}
+	}
+	return st, true
+}
+
+// sysvServices runs SysV init scripts' status action
+type sysvServices struct {
+	dir string
+}
+
+// Name returns "sysv"
+func (sysvServices) Name() string { return "sysv" }
+
+// Status runs `/etc/init.d/<name> status` for each service and maps its LSB exit status
+func (s sysvServices) Status(ctx context.Context, names []string, _ bool) []map[string]interface{} {
+	ctx, cancel := context.WithTimeout(ctx, serviceQueryTimeout)
+	defer cancel()
+
+	results := make([]map[string]interface{}, 0, len(names))
+	for _, name := range names {
+		result, ok := newServiceResult(name, s.Name())
+		if !ok {
+			results = append(results, result)
+			continue
+		}
+		script := filepath.Join(s.dir, name)
+		if _, err := os.Stat(script); err != nil {
+			setServiceState(result, "not-found", "inactive", "dead")
+			results = append(results, result)
+			continue
+		}
+
+		//nolint:gosec // G204: name is validated against serviceNameRe and joined to the init script directory
+		err := exec.CommandContext(ctx, script, "status").Run()
+		code := 0
+		var exitErr *exec.ExitError
+		switch {
+		case errors.As(err, &exitErr):
+			code = exitErr.ExitCode()
+		case err != nil:
+			result["error"] = fmt.Sprintf("Failed to run %s status: %v", script, err)
+			results = append(results, result)
+			continue
+		}
+		active, sub := lsbStatusState(code)
+		setServiceState(result, "loaded", active, sub)
+		result["status_code"] = code
+		result["description"] = scriptDescription(script, lsbDescriptionRe)
+		result["enabled"] = sysvEnabled(initRCRoot, name)
+		results = append(results, result)
+	}
+	return results
+}
+
+// lsbStatusState maps an LSB init script status exit code to systemd-style states: 0 is
+// running, 1 and 2 are dead with a stale pid or lock file, 3 is not running
+func lsbStatusState(code int) (active, sub string) {
+	switch code {
+	case 0:
+		return "active", "running"
+	case 1, 2:
+		return "failed", "dead"
+	case 3:
+		return "inactive", "dead"
+	}
+	return "unknown", "unknown"
+}
+
+// sysvEnabled reports whether a service starts in any multi-user runlevel
+func sysvEnabled(root, name string) bool {
+	matches, _ := filepath.Glob(filepath.Join(root, "rc[2345].d", "S[0-9][0-9]"+name))
+	return len(matches) > 0
+}
+
+// scriptDescription returns the description an init script declares, if any
+func scriptDescription(path string, re *regexp.Regexp) string {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return ""
+	}
+	if m := re.FindSubmatch(data); m != nil {
+		return strings.TrimSpace(string(m[1]))
+	}
+	return ""
+}
+
+// readPIDFile returns the PID in a pid file, or "" when there is none
+func readPIDFile(path string) string {
+	s, err := readTrimmed(path)
+	if err != nil {
+		return ""
+	}
+	if _, err := strconv.Atoi(s); err != nil {
+		return ""
+	}
+	return s
+}
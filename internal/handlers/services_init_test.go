@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+
+	"sysmetrics-mcp/internal/capabilities"
+)
+
+func TestNewServiceManager(t *testing.T) {
+	tests := []struct {
+		caps     capabilities.Capabilities
+		expected string
+	}{
+		{capabilities.Capabilities{Systemd: true, InitScripts: true}, "systemd"},
+		{capabilities.Capabilities{OpenRC: true, InitScripts: true}, "openrc"},
+		{capabilities.Capabilities{Runit: true}, "runit"},
+		{capabilities.Capabilities{InitScripts: true}, "sysv"},
+	}
+	for _, tt := range tests {
+		m := newServiceManager(tt.caps)
+		if m == nil || (tt.expected != "systemd" && m.Name() != tt.expected) {
+			t.Errorf("newServiceManager(%+v) = %v, expected %s", tt.caps, m, tt.expected)
+		}
+	}
+	if m := newServiceManager(capabilities.Capabilities{}); m != nil {
+		t.Errorf("Expected no service manager, got %v", m)
+	}
+}
+
+func TestParseSvStatus(t *testing.T) {
+	tests := []struct {
+		out     string
+		active  string
+		pid     string
+		seconds int64
+	}{
+		{"run: /var/service/sshd: (pid 812) 3600s; run: log: (pid 810) 3600s\n", "active", "812", 3600},
+		{"down: /var/service/ntpd: 12s, normally up, want up\n", "failed", "", 12},
+		{"down: /etc/service/backup: 86400s\n", "inactive", "", 86400},
+		{"finish: /var/service/app: (pid 99) 1s\n", "deactivating", "99", 1},
+	}
+	for _, tt := range tests {
+		st, ok := parseSvStatus(tt.out)
+		if !ok || st.active != tt.active || st.pid != tt.pid || st.seconds != tt.seconds {
+			t.Errorf("parseSvStatus(%q) = %+v, %v", tt.out, st, ok)
+		}
+	}
+	if _, ok := parseSvStatus("warning: /var/service/x: unable to open supervise/ok: access denied"); ok {
+		t.Error("Expected a warning not to parse")
+	}
+}
+
+func TestParseRCUpdateShow(t *testing.T) {
+	got := parseRCUpdateShow("            chronyd |      default\n               sshd | boot default\n")
+	if !slices.Equal(got["sshd"], []string{"boot", "default"}) || !slices.Equal(got["chronyd"], []string{"default"}) {
+		t.Errorf("Unexpected runlevels: %v", got)
+	}
+	if active, sub := openrcState("crashed"); active != "failed" || sub != "crashed" {
+		t.Errorf("Expected crashed to be failed, got %s/%s", active, sub)
+	}
+}
+
+func TestSysvServicesStatus(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("init scripts are shell scripts")
+	}
+	root := t.TempDir()
+	oldRoot := initRCRoot
+	t.Cleanup(func() { initRCRoot = oldRoot })
+	initRCRoot = root
+
+	dir := filepath.Join(root, "init.d")
+	scripts := map[string]string{
+		"nginx": "#!/bin/sh\n### BEGIN INIT INFO\n# Short-Description: nginx web server\n### END INIT INFO\nexit 0\n",
+		"ntp":   "#!/bin/sh\nexit 3\n",
+		"stale": "#!/bin/sh\nexit 1\n",
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	for name, body := range scripts {
+		//nolint:gosec // G306: the test scripts must be executable
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(root, "rc2.d"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("../init.d/nginx", filepath.Join(root, "rc2.d", "S01nginx")); err != nil {
+		t.Fatal(err)
+	}
+
+	results := sysvServices{dir: dir}.Status(context.Background(), []string{"nginx", "ntp", "stale", "missing", "../etc"}, false)
+	expected := []struct{ load, active string }{
+		{"loaded", "active"}, {"loaded", "inactive"}, {"loaded", "failed"}, {"not-found", "inactive"}, {"", ""},
+	}
+	for i, e := range expected {
+		r := results[i]
+		if e.load == "" {
+			if r["available"] != false || r["error"] == nil {
+				t.Errorf("Expected %v to be rejected, got %v", r["name"], r)
+			}
+			continue
+		}
+		if r["load_state"] != e.load || r["active_state"] != e.active || r["backend"] != "sysv" {
+			t.Errorf("%v: got %v, expected %s/%s", r["name"], r, e.load, e.active)
+		}
+	}
+	if results[0]["description"] != "nginx web server" || results[0]["enabled"] != true || results[1]["enabled"] != false {
+		t.Errorf("Unexpected nginx or ntp details: %v, %v", results[0], results[1])
+	}
+}
@@ -0,0 +1,162 @@
+//go:build !windows && !darwin
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+// serviceUnitProperties are read from each unit for get_service_status
+var serviceUnitProperties = []string{"Id", "LoadState", "ActiveState", "SubState", "Description", "MainPID",
+	"MemoryCurrent", "CPUUsageNSec", "TasksCurrent", "NRestarts", "ExecMainStartTimestamp"}
+
+// serviceBus reads units from a systemd service manager over D-Bus
+type serviceBus interface {
+	// matchUnits returns the loaded units matching a glob pattern
+	matchUnits(ctx context.Context, pattern string) ([]string, error)
+	// unitProperties reads a unit's properties, formatted as systemctl show prints
+	// them, and its main process start time
+	unitProperties(ctx context.Context, unit string) (map[string]string, time.Time, error)
+}
+
+// getServiceInfo returns a system service's status
+func getServiceInfo(ctx context.Context, serviceName string) map[string]interface{} {
+	return getServicesInfo(ctx, []string{serviceName}, false)[0]
+}
+
+// getServicesInfo returns the status of each service. Names containing *, ?, or [ are
+// expanded to the matching loaded units. Units are read over D-Bus, falling back to
+// systemctl when the bus is unreachable. With user set, the server user's own service
+// manager is queried instead of the system one.
+func getServicesInfo(ctx context.Context, names []string, user bool) []map[string]interface{} {
+	ctx, cancel := context.WithTimeout(ctx, serviceQueryTimeout)
+	defer cancel()
+
+	bus, err := systemdConn(ctx, user)
+	if err != nil {
+		bus = nil
+	}
+	results := []map[string]interface{}{}
+	for _, name := range names {
+		if !strings.ContainsAny(name, "*?[") {
+			results = append(results, queryService(ctx, bus, name, serviceUnitName(name), user))
+			continue
+		}
+		units, err := matchServiceUnits(ctx, bus, serviceUnitName(name), user)
+		if err != nil {
+			results = append(results, map[string]interface{}{
+				"name":      name,
+				"available": false,
+				"error":     fmt.Sprintf("Failed to list units: %v", err),
+			})
+			continue
+		}
+		for _, unit := range units {
+			results = append(results, queryService(ctx, bus, unit, unit, user))
+		}
+	}
+	return results
+}
+
+// matchServiceUnits returns the loaded units matching a glob pattern
+func matchServiceUnits(ctx context.Context, bus serviceBus, pattern string, user bool) ([]string, error) {
+	if bus != nil {
+		if units, err := bus.matchUnits(ctx, pattern); err == nil {
+			return units, nil
+		}
+	}
+
+	args := []string{"list-units", "--all", "--plain", "--no-legend", "--full", "--no-pager", "--", pattern}
+	if user {
+		args = append([]string{"--user"}, args...)
+	}
+	//nolint:gosec // G204: the pattern follows "--" and cannot be taken as an option
+	out, err := exec.CommandContext(ctx, "systemctl", args...).Output()
+	if err != nil {
+		return nil, err
+	}
+	var units []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			units = append(units, fields[0])
+		}
+	}
+	sort.Strings(units)
+	return units, nil
+}
+
+// queryService reads one unit over D-Bus, or with systemctl show when bus is nil or the
+// D-Bus call fails
+func queryService(ctx context.Context, bus serviceBus, name, unit string, user bool) map[string]interface{} {
+	result := map[string]interface{}{
+		"name": name,
+	}
+	if user {
+		result["scope"] = "user"
+	}
+
+	if bus != nil {
+		if props, started, err := bus.unitProperties(ctx, unit); err == nil {
+			result["transport"] = "dbus"
+			return systemdServiceInfo(result, unit, props, started)
+		}
+	}
+
+	args := []string{"show", "--property=" + strings.Join(serviceUnitProperties, ","), "--no-pager", "--", unit}
+	if user {
+		args = append([]string{"--user"}, args...)
+	}
+	//nolint:gosec // G204: the unit name follows "--" and cannot be taken as an option
+	output, err := exec.CommandContext(ctx, "systemctl", args...).Output()
+	if err != nil {
+		result["error"] = fmt.Sprintf("Failed to query service: %v", err)
+		result["available"] = false
+		return result
+	}
+	props := parseSystemctlShow(string(output))
+	started, _ := parseSystemdTimestamp(props["ExecMainStartTimestamp"])
+	result["transport"] = "systemctl"
+	return systemdServiceInfo(result, unit, props, started)
+}
+
+// systemdServiceInfo fills result from a unit's properties
+func systemdServiceInfo(result map[string]interface{}, unit string, props map[string]string, started time.Time) map[string]interface{} {
+	result["available"] = true
+	result["backend"] = "systemd"
+	result["unit"] = unit
+	result["load_state"] = props["LoadState"]
+	result["active_state"] = props["ActiveState"]
+	result["sub_state"] = props["SubState"]
+	result["description"] = props["Description"]
+	result["main_pid"] = props["MainPID"]
+	if result["main_pid"] == "" {
+		result["main_pid"] = "0"
+	}
+
+	// Resource accounting is only reported for running units with accounting enabled
+	if v, ok := parseSystemdUint(props["MemoryCurrent"]); ok {
+		result["memory_bytes"] = v
+	}
+	if v, ok := parseSystemdUint(props["CPUUsageNSec"]); ok {
+		result["cpu_seconds"] = round2(float64(v) / 1e9)
+	}
+	if v, ok := parseSystemdUint(props["TasksCurrent"]); ok {
+		result["tasks"] = v
+	}
+	if v, ok := parseSystemdUint(props["NRestarts"]); ok {
+		result["restarts"] = v
+	}
+	if !started.IsZero() {
+		result["started_at"] = started.UTC().Format(time.RFC3339)
+		if up := time.Since(started); up >= 0 {
+			result["uptime_seconds"] = int64(up / time.Second)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,43 @@
+//go:build !windows && !darwin
+
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSystemdServiceInfo(t *testing.T) {
+	props := parseSystemctlShow(`Id=nginx.service
+LoadState=loaded
+ActiveState=active
+SubState=running
+Description=A high performance web server
+MainPID=812
+MemoryCurrent=12582912
+CPUUsageNSec=2500000000
+TasksCurrent=3
+NRestarts=2
+`)
+	started := time.Now().Add(-time.Hour)
+	got := systemdServiceInfo(map[string]interface{}{"name": "nginx"}, "nginx.service", props, started)
+	if got["active_state"] != "active" || got["main_pid"] != "812" || got["unit"] != "nginx.service" {
+		t.Errorf("Unexpected state: %v", got)
+	}
+	if got["memory_bytes"] != uint64(12582912) || got["cpu_seconds"] != 2.5 || got["tasks"] != uint64(3) || got["restarts"] != uint64(2) {
+		t.Errorf("Unexpected resources: %v", got)
+	}
+	if up, ok := got["uptime_seconds"].(int64); !ok || up < 3599 {
+		t.Errorf("Expected about an hour of uptime, got %v", got["uptime_seconds"])
+	}
+
+	// D-Bus reports unset accounting as the all-ones sentinel
+	stopped := map[string]string{"LoadState": "loaded", "ActiveState": "inactive", "MemoryCurrent": "18446744073709551615"}
+	got = systemdServiceInfo(map[string]interface{}{}, "nginx.service", stopped, time.Time{})
+	if _, ok := got["memory_bytes"]; ok {
+		t.Errorf("Expected no memory for a stopped unit, got %v", got)
+	}
+	if _, ok := got["started_at"]; ok || got["main_pid"] != "0" {
+		t.Errorf("Expected no start time or PID for a stopped unit, got %v", got)
+	}
+}
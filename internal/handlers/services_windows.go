@@ -0,0 +1,108 @@
+//go:build windows
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// getServiceInfo queries the Windows Service Control Manager for service information. The
+// SCM calls cannot be cancelled, so ctx is unused.
+func getServiceInfo(_ context.Context, serviceName string) map[string]interface{} {
+	result := map[string]interface{}{
+		"name": serviceName,
+	}
+
+	m, err := mgr.Connect()
+	if err != nil {
+		result["error"] = fmt.Sprintf("Failed to connect to service manager: %v", err)
+		result["available"] = false
+		return result
+	}
+	defer func() { _ = m.Disconnect() }()
+
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		result["error"] = fmt.Sprintf("Failed to open service: %v", err)
+		result["available"] = false
+		return result
+	}
+	defer s.Close()
+
+	status, err := s.Query()
+	if err != nil {
+		result["error"] = fmt.Sprintf("Failed to query service: %v", err)
+		result["available"] = false
+		return result
+	}
+
+	result["available"] = true
+	result["backend"] = "scm"
+	result["load_state"] = "loaded"
+	result["active_state"], result["sub_state"] = scmStateNames(status.State)
+	result["main_pid"] = fmt.Sprintf("%d", status.ProcessId)
+	result["exit_code"] = status.Win32ExitCode
+
+	if cfg, err := s.Config(); err == nil {
+		result["description"] = cfg.Description
+		result["display_name"] = cfg.DisplayName
+		result["start_type"] = scmStartTypeName(cfg.StartType, cfg.DelayedAutoStart)
+		result["binary_path"] = cfg.BinaryPathName
+		result["service_account"] = cfg.ServiceStartName
+	}
+
+	return result
+}
+
+// scmStateNames maps an SCM state to systemd-style active and sub states
+func scmStateNames(state svc.State) (active, sub string) {
+	switch state {
+	case svc.Running:
+		return "active", "running"
+	case svc.Stopped:
+		return "inactive", "stopped"
+	case svc.StartPending:
+		return "activating", "start_pending"
+	case svc.StopPending:
+		return "deactivating", "stop_pending"
+	case svc.Paused:
+		return "inactive", "paused"
+	case svc.PausePending:
+		return "deactivating", "pause_pending"
+	case svc.ContinuePending:
+		return "activating", "continue_pending"
+	default:
+		return "unknown", fmt.Sprintf("unknown(%d)", state)
+	}
+}
+
+// scmStartTypeName converts an SCM start type to a readable name
+func scmStartTypeName(startType uint32, delayed bool) string {
+	switch startType {
+	case mgr.StartAutomatic:
+		if delayed {
+			return "automatic_delayed"
+		}
+		return "automatic"
+	case mgr.StartManual:
+		return "manual"
+	case mgr.StartDisabled:
+		return "disabled"
+	default:
+		return fmt.Sprintf("unknown(%d)", startType)
+	}
+}
+
+// getServicesInfo returns the status of each service. User services are a systemd
+// concept, so user is ignored.
+func getServicesInfo(ctx context.Context, names []string, _ bool) []map[string]interface{} {
+	results := make([]map[string]interface{}, 0, len(names))
+	for _, name := range names {
+		results = append(results, getServiceInfo(ctx, name))
+	}
+	return results
+}
@@ -0,0 +1,38 @@
+//go:build windows
+
+package handlers
+
+import (
+	"testing"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+func TestSCMStateNames(t *testing.T) {
+	tests := []struct {
+		state      svc.State
+		wantActive string
+		wantSub    string
+	}{
+		{svc.Running, "active", "running"},
+		{svc.Stopped, "inactive", "stopped"},
+		{svc.StartPending, "activating", "start_pending"},
+	}
+
+	for _, tc := range tests {
+		active, sub := scmStateNames(tc.state)
+		if active != tc.wantActive || sub != tc.wantSub {
+			t.Errorf("scmStateNames(%d) = %s/%s; want %s/%s", tc.state, active, sub, tc.wantActive, tc.wantSub)
+		}
+	}
+}
+
+func TestSCMStartTypeName(t *testing.T) {
+	if got := scmStartTypeName(mgr.StartAutomatic, true); got != "automatic_delayed" {
+		t.Errorf("scmStartTypeName(automatic, delayed) = %s; want automatic_delayed", got)
+	}
+	if got := scmStartTypeName(mgr.StartDisabled, false); got != "disabled" {
+		t.Errorf("scmStartTypeName(disabled) = %s; want disabled", got)
+	}
+}
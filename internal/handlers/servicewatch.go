@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"sysmetrics-mcp/internal/history"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// serviceWatchInterval is how often RunServiceWatch reads the watched units. Restarts
+// between reads are still counted through NRestarts.
+const serviceWatchInterval = 15 * time.Second
+
+// get_service_flaps defaults
+const (
+	defaultFlapRange     = time.Hour
+	defaultFlapThreshold = 3
+	maxFlapEvents        = 20
+)
+
+// Kinds of service_state events, stored in their "kind" detail
+const (
+	serviceEventRestart = "restart"
+	serviceEventState   = "state"
+)
+
+// watchedServiceState is what RunServiceWatch remembers about a unit between reads
+type watchedServiceState struct {
+	active, sub string
+	restarts    uint64
+	hasRestarts bool
+	started     string
+}
+
+// serviceWatch holds the last state read of each watched unit. The first read of a unit
+// only records it, so states present at startup are not journaled.
+type serviceWatch struct {
+	units map[string]watchedServiceState
+}
+
+// RunServiceWatch records state changes and restarts of the --watch-services units in
+// the event journal until ctx is cancelled. It returns immediately when no units are
+// watched, history is disabled, or systemd is not running.
+func (h *HandlerManager) RunServiceWatch(ctx context.Context) {
+	if len(h.cfg.WatchServices) == 0 || h.history == nil || !h.caps.Systemd {
+		return
+	}
+	w := &serviceWatch{units: map[string]watchedServiceState{}}
+	ticker := time.NewTicker(serviceWatchInterval)
+	defer ticker.Stop()
+	for {
+		states := readWatchedServices(getServicesInfo(ctx, h.cfg.WatchServices, false))
+		for _, e := range w.update(time.Now(), states) {
+			h.recordEvent(ctx, e)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// readWatchedServices keys get_service_status results by unit, leaving out units that
+// could not be read
+func readWatchedServices(infos []map[string]interface{}) map[string]watchedServiceState {
+	states := map[string]watchedServiceState{}
+	for _, info := range infos {
+		unit, _ := info["unit"].(string)
+		if available, _ := info["available"].(bool); !available || unit == "" {
+			continue
+		}
+		s := watchedServiceState{}
+		s.active, _ = info["active_state"].(string)
+		s.sub, _ = info["sub_state"].(string)
+		s.restarts, s.hasRestarts = info["restarts"].(uint64)
+		s.started, _ = info["started_at"].(string)
+		states[unit] = s
+	}
+	return states
+}
+
+// update compares one read with the previous one and returns the events in between. A
+// rise in NRestarts counts systemd's automatic restarts; a new main process start time
+// without one, while the unit had been running, is a restart by hand.
+func (w *serviceWatch) update(now time.Time, states map[string]watchedServiceState) []history.Event {
+	events := []history.Event{}
+	for _, unit := range sortedKeys(states) {
+		cur := states[unit]
+		prev, seen := w.units[unit]
+		w.units[unit] = cur
+		if !seen {
+			continue
+		}
+		add := func(severity, message string, details map[string]string) {
+			events = append(events, history.Event{Time: now, Category: history.EventServiceState, Severity: severity, Subject: unit, Message: message, Details: details})
+		}
+
+		switch {
+		case cur.hasRestarts && prev.hasRestarts && cur.restarts > prev.restarts:
+			n := cur.restarts - prev.restarts
+			message := unit + " was restarted by systemd"
+			if n > 1 {
+				message = fmt.Sprintf("%s was restarted by systemd %d times", unit, n)
+			}
+			add(history.SeverityWarning, message, map[string]string{
+				"kind": serviceEventRestart, "restarts": strconv.FormatUint(n, 10), "total_restarts": strconv.FormatUint(cur.restarts, 10),
+			})
+		case cur.started != "" && prev.started != "" && cur.started != prev.started && prev.active != "inactive" && prev.active != "failed":
+			add(history.SeverityInfo, unit+" was restarted", map[string]string{
+				"kind": serviceEventRestart, "restarts": "1", "started_at": cur.started,
+			})
+		}
+
+		if cur.active != prev.active {
+			severity := history.SeverityInfo
+			if cur.active == "failed" {
+				severity = history.SeverityWarning
+			}
+			add(severity, fmt.Sprintf("%s is %s (was %s)", unit, cur.active, prev.active), map[string]string{
+				"kind": serviceEventState, "from": prev.active, "to": cur.active, "sub_state": cur.sub,
+			})
+		}
+	}
+	return events
+}
+
+// serviceFlaps summarizes one unit's service_state events
+type serviceFlaps struct {
+	restarts     int
+	stateChanges int
+	failures     int
+	recent       []history.Event
+}
+
+// summarizeServiceFlaps groups service_state events by unit. Events are newest first, so
+// recent keeps the newest maxFlapEvents.
+func summarizeServiceFlaps(events []history.Event) map[string]*serviceFlaps {
+	byUnit := map[string]*serviceFlaps{}
+	for _, e := range events {
+		f := byUnit[e.Subject]
+		if f == nil {
+			f = &serviceFlaps{recent: []history.Event{}}
+			byUnit[e.Subject] = f
+		}
+		switch e.Details["kind"] {
+		case serviceEventRestart:
+			n, err := strconv.Atoi(e.Details["restarts"])
+			if err != nil || n < 1 {
+				n = 1
+			}
+			f.restarts += n
+		case serviceEventState:
+			f.stateChanges++
+			if e.Details["to"] == "failed" {
+				f.failures++
+			}
+		}
+		if len(f.recent) < maxFlapEvents {
+			f.recent = append(f.recent, e)
+		}
+	}
+	return byUnit
+}
+
+// HandleGetServiceFlaps reports how often each watched unit restarted or changed state
+// over a range, flagging units that are crash-looping
+func (h *HandlerManager) HandleGetServiceFlaps(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if h.history == nil {
+		return mcp.NewToolResultError("Metrics history is disabled (start the server with --history-db)"), nil
+	}
+	rangeDur := defaultFlapRange
+	threshold := defaultFlapThreshold
+	var only string
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if r, ok := args["range"].(string); ok && r != "" {
+			d, err := parseHistoryDuration(r)
+			if err != nil || d <= 0 {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid range: %q (use a duration such as 30m, 6h, or 7d)", r)), nil
+			}
+			rangeDur = d
+		}
+		if t, ok := args["threshold"].(float64); ok && t >= 1 {
+			threshold = int(t)
+		}
+		if s, ok := args["service"].(string); ok && strings.TrimSpace(s) != "" {
+			only = serviceUnitName(strings.TrimSpace(s))
+		}
+	}
+
+	units := h.cfg.WatchServices
+	if only != "" {
+		if !contains(units, only) {
+			return mcp.NewToolResultError(fmt.Sprintf("%s is not watched (--watch-services: %s)", only, strings.Join(units, ", "))), nil
+		}
+		units = []string{only}
+	}
+
+	now := time.Now()
+	events, err := h.history.Events(ctx, history.EventQuery{
+		Since: now.Add(-rangeDur), Until: now, Categories: []string{history.EventServiceState},
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read events: %v", err)), nil
+	}
+	flaps := summarizeServiceFlaps(events)
+
+	current := map[string]map[string]interface{}{}
+	for _, info := range getServicesInfo(ctx, units, false) {
+		if unit, ok := info["unit"].(string); ok {
+			current[unit] = info
+		}
+	}
+
+	services := []map[string]interface{}{}
+	flapping := []string{}
+	warnings := []string{}
+	hours := rangeDur.Hours()
+	for _, unit := range units {
+		f := flaps[unit]
+		if f == nil {
+			f = &serviceFlaps{recent: []history.Event{}}
+		}
+		entry := map[string]interface{}{
+			"service":           unit,
+			"restarts":          f.restarts,
+			"state_changes":     f.stateChanges,
+			"failures":          f.failures,
+			"restarts_per_hour": round2(float64(f.restarts) / hours),
+			"recent_events":     f.recent,
+		}
+		if info, ok := current[unit]; ok {
+			entry["active_state"] = info["active_state"]
+			entry["sub_state"] = info["sub_state"]
+			if v, ok := info["started_at"]; ok {
+				entry["started_at"] = v
+			}
+		}
+		// A unit that restarts on its own shows up as restarts; one that is started again
+		// by a timer or watchdog after each crash shows up as failures
+		isFlapping := f.restarts+f.failures >= threshold
+		entry["flapping"] = isFlapping
+		if isFlapping {
+			flapping = append(flapping, unit)
+			warnings = append(warnings, fmt.Sprintf("%s restarted %d times and failed %d times in the last %s; it looks crash-looping (see diagnose_service %s)",
+				unit, f.restarts, f.failures, h.human.Duration(rangeDur), unit))
+		}
+		services = append(services, entry)
+	}
+
+	jsonBytes, err := json.Marshal(map[string]interface{}{
+		"services":  services,
+		"flapping":  flapping,
+		"warnings":  warnings,
+		"range":     rangeDur.String(),
+		"threshold": threshold,
+		"interval":  serviceWatchInterval.String(),
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"sysmetrics-mcp/internal/history"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestServiceWatchUpdate(t *testing.T) {
+	w := &serviceWatch{units: map[string]watchedServiceState{}}
+	now := time.Now()
+	running := watchedServiceState{active: "active", sub: "running", restarts: 4, hasRestarts: true, started: "2026-10-16T08:00:00Z"}
+
+	if events := w.update(now, map[string]watchedServiceState{"app.service": running}); len(events) != 0 {
+		t.Fatalf("Expected the first read to only record state, got %+v", events)
+	}
+
+	// systemd restarted the unit twice between reads
+	looped := running
+	looped.restarts, looped.started = 6, "2026-10-16T08:00:30Z"
+	events := w.update(now, map[string]watchedServiceState{"app.service": looped})
+	if len(events) != 1 || events[0].Details["kind"] != serviceEventRestart || events[0].Details["restarts"] != "2" || events[0].Severity != history.SeverityWarning {
+		t.Fatalf("Expected one event for 2 automatic restarts, got %+v", events)
+	}
+
+	// A restart by hand only changes the start time
+	manual := looped
+	manual.started = "2026-10-16T08:01:00Z"
+	events = w.update(now, map[string]watchedServiceState{"app.service": manual})
+	if len(events) != 1 || events[0].Details["restarts"] != "1" || events[0].Severity != history.SeverityInfo {
+		t.Fatalf("Expected one manual restart, got %+v", events)
+	}
+
+	failed := manual
+	failed.active, failed.sub = "failed", "failed"
+	events = w.update(now, map[string]watchedServiceState{"app.service": failed})
+	if len(events) != 1 || events[0].Details["to"] != "failed" || events[0].Severity != history.SeverityWarning {
+		t.Fatalf("Expected a failure, got %+v", events)
+	}
+
+	// Starting a failed unit again is a state change, not a restart
+	started := failed
+	started.active, started.sub, started.started = "active", "running", "2026-10-16T08:05:00Z"
+	events = w.update(now, map[string]watchedServiceState{"app.service": started})
+	if len(events) != 1 || events[0].Details["kind"] != serviceEventState {
+		t.Fatalf("Expected only a state change, got %+v", events)
+	}
+
+	// A unit that could not be read keeps its last state
+	if events := w.update(now, map[string]watchedServiceState{}); len(events) != 0 {
+		t.Errorf("Expected no events for an unreadable unit, got %+v", events)
+	}
+}
+
+func TestHandleGetServiceFlaps(t *testing.T) {
+	h := newHistoryTestManager(t)
+	h.cfg.WatchServices = []string{"app.service", "db.service"}
+	ctx := context.Background()
+	now := time.Now()
+	for _, e := range []history.Event{
+		{Time: now.Add(-50 * time.Minute), Category: history.EventServiceState, Severity: history.SeverityWarning, Subject: "app.service",
+			Message: "app.service was restarted by systemd 2 times", Details: map[string]string{"kind": serviceEventRestart, "restarts": "2"}},
+		{Time: now.Add(-20 * time.Minute), Category: history.EventServiceState, Severity: history.SeverityWarning, Subject: "app.service",
+			Message: "app.service is failed (was active)", Details: map[string]string{"kind": serviceEventState, "from": "active", "to": "failed"}},
+		{Time: now.Add(-3 * time.Hour), Category: history.EventServiceState, Severity: history.SeverityWarning, Subject: "db.service",
+			Message: "db.service was restarted by systemd 5 times", Details: map[string]string{"kind": serviceEventRestart, "restarts": "5"}},
+	} {
+		h.recordEvent(ctx, e)
+	}
+
+	res, err := h.HandleGetServiceFlaps(ctx, mcp.CallToolRequest{})
+	checkToolResult(t, res, err, []string{"services", "flapping", "warnings", "range", "threshold"})
+	var result struct {
+		Services []struct {
+			Service  string `json:"service"`
+			Restarts int    `json:"restarts"`
+			Failures int    `json:"failures"`
+			Flapping bool   `json:"flapping"`
+		} `json:"services"`
+		Flapping []string `json:"flapping"`
+	}
+	if err := json.Unmarshal([]byte(resultText(res)), &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Flapping) != 1 || result.Flapping[0] != "app.service" {
+		t.Fatalf("Expected only app.service to be flapping in the last hour, got %+v", result)
+	}
+	if s := result.Services[0]; s.Restarts != 2 || s.Failures != 1 || !s.Flapping {
+		t.Errorf("Unexpected app.service summary: %+v", s)
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"service": "db", "range": "6h"}
+	res, err = h.HandleGetServiceFlaps(ctx, req)
+	checkToolResult(t, res, err, []string{"services"})
+	if err := json.Unmarshal([]byte(resultText(res)), &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Services) != 1 || result.Services[0].Restarts != 5 || !result.Services[0].Flapping {
+		t.Errorf("Expected db.service to be flapping over 6h, got %+v", result.Services)
+	}
+
+	req.Params.Arguments = map[string]interface{}{"service": "web"}
+	if res, err := h.HandleGetServiceFlaps(ctx, req); err != nil || !res.IsError {
+		t.Error("Expected an error for an unwatched unit")
+	}
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"sysmetrics-mcp/internal/smarthome"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// HandleGetSmartHomeStatus returns Zigbee2MQTT and Z-Wave JS coordinator health
+func (h *HandlerManager) HandleGetSmartHomeStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	backend := kindAll
+
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if b, ok := args["backend"].(string); ok && b != "" {
+			backend = strings.ToLower(b)
+		}
+	}
+
+	if backend != kindAll && backend != smarthome.BackendZigbee2MQTT && backend != smarthome.BackendZWaveJS {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid backend: %s (must be zigbee2mqtt, zwave_js, or all)", backend)), nil
+	}
+
+	result := map[string]interface{}{}
+
+	// Query parameters can carry the frontend auth token, so addresses are redacted
+	if h.cfg.Zigbee2MQTTURL != "" && (backend == kindAll || backend == smarthome.BackendZigbee2MQTT) {
+		zigbee := map[string]interface{}{
+			"available": false,
+			"address":   smarthome.RedactURL(h.cfg.Zigbee2MQTTURL),
+		}
+		if status, err := smarthome.QueryZigbee2MQTT(ctx, h.cfg.Zigbee2MQTTURL); err != nil {
+			zigbee["error"] = err.Error()
+		} else {
+			zigbee["available"] = true
+			zigbee["bridge"] = status
+		}
+		result[smarthome.BackendZigbee2MQTT] = zigbee
+	}
+
+	if h.cfg.ZWaveJSURL != "" && (backend == kindAll || backend == smarthome.BackendZWaveJS) {
+		zwave := map[string]interface{}{
+			"available": false,
+			"address":   smarthome.RedactURL(h.cfg.ZWaveJSURL),
+		}
+		if status, err := smarthome.QueryZWaveJS(ctx, h.cfg.ZWaveJSURL); err != nil {
+			zwave["error"] = err.Error()
+		} else {
+			zwave["available"] = true
+			zwave["controller"] = status
+		}
+		result[smarthome.BackendZWaveJS] = zwave
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
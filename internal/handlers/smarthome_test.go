@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleGetSmartHomeStatusUnreachable(t *testing.T) {
+	// Reserve a loopback port and close it so both coordinators are unreachable
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	h := NewHandlerManager(&config.Config{
+		Zigbee2MQTTURL: "ws://" + addr + "/api?token=secret",
+		ZWaveJSURL:     "ws://" + addr,
+	})
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{},
+		},
+	}
+	res, err := h.HandleGetSmartHomeStatus(context.Background(), req)
+	checkToolResult(t, res, err, []string{"zigbee2mqtt", "zwave_js"})
+
+	text := res.Content[0].(mcp.TextContent).Text
+	if strings.Contains(text, "secret") {
+		t.Error("Expected the Zigbee2MQTT token to be redacted")
+	}
+	var result map[string]map[string]interface{}
+	if err := json.Unmarshal([]byte(text), &result); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+	for name, backend := range result {
+		if available, _ := backend["available"].(bool); available || backend["error"] == nil {
+			t.Errorf("Expected backend %s to be unavailable with an error, got %v", name, backend)
+		}
+	}
+
+	req.Params.Arguments = map[string]interface{}{"backend": "x10"}
+	res, err = h.HandleGetSmartHomeStatus(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if !res.IsError {
+		t.Error("Expected error result for unsupported backend")
+	}
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// snapshotCreateTimeout bounds a snapshot command; creation is near-instant unless the
+// pool or volume group is busy
+const snapshotCreateTimeout = 60 * time.Second
+
+// defaultLVMSnapshotPercent sizes a classic LVM snapshot's copy-on-write space as a share
+// of its origin
+const defaultLVMSnapshotPercent = 10
+
+// snapshotNameRe is a snapshot name valid for all three backends
+var snapshotNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// zfsDatasetRe is a ZFS dataset name such as tank/home
+var zfsDatasetRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.:/-]*$`)
+
+// lvmTargetRe is an LVM logical volume as vg/lv
+var lvmTargetRe = regexp.MustCompile(`^[A-Za-z0-9+_.][A-Za-z0-9+_.-]*/[A-Za-z0-9+_.][A-Za-z0-9+_.-]*$`)
+
+// snapshotPlan is the command that creates one snapshot
+type snapshotPlan struct {
+	Type     string   `json:"type"`
+	Target   string   `json:"target"`
+	Name     string   `json:"name"`
+	Snapshot string   `json:"snapshot"`
+	Command  []string `json:"command"`
+	// mkdir is the Btrfs directory the snapshot is created in, made if missing
+	mkdir string
+}
+
+// HandleCreateFSSnapshot snapshots a ZFS dataset, Btrfs subvolume, or LVM logical volume,
+// so there is a restore point before a risky change. With dry_run it only reports the command.
+func (h *HandlerManager) HandleCreateFSSnapshot(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var kind, target, name string
+	sizePercent := defaultLVMSnapshotPercent
+	dryRun, confirm := false, false
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if t, ok := args["type"].(string); ok {
+			kind = strings.ToLower(strings.TrimSpace(t))
+		}
+		if t, ok := args["target"].(string); ok {
+			target = strings.TrimSpace(t)
+		}
+		if n, ok := args["name"].(string); ok {
+			name = strings.TrimSpace(n)
+		}
+		if p, ok := args["size_percent"].(float64); ok {
+			sizePercent = int(p)
+		}
+		if d, ok := args["dry_run"].(bool); ok {
+			dryRun = d
+		}
+		if c, ok := args["confirm"].(bool); ok {
+			confirm = c
+		}
+	}
+
+	if target == "" {
+		return mcp.NewToolResultError("target is required"), nil
+	}
+	if name == "" {
+		name = "sysmetrics-" + time.Now().UTC().Format("20060102-150405")
+	}
+	if !snapshotNameRe.MatchString(name) {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid name %q: use up to 64 letters, digits, dots, dashes, and underscores", name)), nil
+	}
+	if sizePercent < 1 || sizePercent > 100 {
+		return mcp.NewToolResultError("size_percent must be between 1 and 100"), nil
+	}
+
+	var plan snapshotPlan
+	var err error
+	switch kind {
+	case snapshotZFS:
+		if !h.caps.Zpool {
+			return mcp.NewToolResultError("ZFS is not available on this host"), nil
+		}
+		plan, err = planZFSSnapshot(target, name)
+	case snapshotBtrfs:
+		if !h.caps.Btrfs {
+			return mcp.NewToolResultError("btrfs-progs is not installed on this host"), nil
+		}
+		plan, err = planBtrfsSnapshot(target, name)
+	case snapshotLVM:
+		if !h.caps.LVM {
+			return mcp.NewToolResultError("LVM is not installed on this host"), nil
+		}
+		if !lvmTargetRe.MatchString(target) {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid logical volume %q: expected vg/lv such as vg0/root", target)), nil
+		}
+		var thin bool
+		thin, err = h.lvmThinVolume(ctx, target)
+		if err == nil {
+			plan, err = planLVMSnapshot(target, name, sizePercent, thin)
+		}
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid type %q: use zfs, btrfs, or lvm", kind)), nil
+	}
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	result := map[string]interface{}{
+		"plan":    plan,
+		"dry_run": dryRun,
+	}
+	if !dryRun && !confirm {
+		return mcp.NewToolResultError(fmt.Sprintf("confirm must be true to create %s (or use dry_run)", plan.Snapshot)), nil
+	}
+	if !dryRun {
+		h.applySnapshotPlan(ctx, plan, result)
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// applySnapshotPlan runs the snapshot command and records the outcome in result
+func (h *HandlerManager) applySnapshotPlan(ctx context.Context, plan snapshotPlan, result map[string]interface{}) {
+	h.logger.Warn("snapshot create", "type", plan.Type, "target", plan.Target, "snapshot", plan.Snapshot)
+	if plan.mkdir != "" {
+		if err := os.MkdirAll(plan.mkdir, 0o750); err != nil {
+			result["success"] = false
+			result["error"] = fmt.Sprintf("Failed to create %s: %v", plan.mkdir, err)
+			result["hint"] = "Create the directory as root, or run the server as root."
+			return
+		}
+	}
+
+	cmdCtx, cancel := context.WithTimeout(ctx, snapshotCreateTimeout)
+	defer cancel()
+	start := time.Now()
+	out, err := h.privilegedCommand(cmdCtx, plan.Command[0], plan.Command[1:]...).CombinedOutput()
+	result["success"] = err == nil
+	result["duration_ms"] = time.Since(start).Milliseconds()
+	if output := strings.TrimSpace(string(out)); output != "" {
+		result["output"] = truncateString(output, 2000)
+	}
+	if err != nil {
+		result["error"] = fmt.Sprintf("%s failed: %v", plan.Command[0], err)
+		if containsAny(strings.ToLower(string(out)), permissionMarkers) {
+			result["hint"] = fmt.Sprintf("Creating snapshots needs root. Run the server as root or add %s to --sudo-allowlist.", plan.Command[0])
+		}
+		h.logger.Error("snapshot create failed", "type", plan.Type, "snapshot", plan.Snapshot, "error", err)
+	}
+}
+
+// planZFSSnapshot snapshots a dataset as dataset@name
+func planZFSSnapshot(dataset, name string) (snapshotPlan, error) {
+	if !zfsDatasetRe.MatchString(dataset) || strings.Contains(dataset, "//") {
+		return snapshotPlan{}, fmt.Errorf("invalid ZFS dataset %q: expected a name such as tank/home", dataset)
+	}
+	snap := dataset + "@" + name
+	return snapshotPlan{Type: snapshotZFS, Target: dataset, Name: name, Snapshot: snap, Command: []string{"zfs", "snapshot", snap}}, nil
+}
+
+// planBtrfsSnapshot takes a read-only snapshot of a subvolume into its .snapshots directory
+func planBtrfsSnapshot(subvolume, name string) (snapshotPlan, error) {
+	if !filepath.IsAbs(subvolume) {
+		return snapshotPlan{}, fmt.Errorf("invalid Btrfs subvolume %q: expected an absolute path such as /home", subvolume)
+	}
+	subvolume = filepath.Clean(subvolume)
+	mounts, err := readMountTable(mountsPath)
+	if err != nil {
+		return snapshotPlan{}, fmt.Errorf("failed to read %s: %v", mountsPath, err)
+	}
+	if m, ok := mountFor(mounts, subvolume); !ok || m.Fstype != poolBtrfs {
+		return snapshotPlan{}, fmt.Errorf("%s is not on a Btrfs filesystem", subvolume)
+	}
+	dir := filepath.Join(subvolume, ".snapshots")
+	dest := filepath.Join(dir, name)
+	if _, err := os.Lstat(dest); err == nil {
+		return snapshotPlan{}, fmt.Errorf("%s already exists", dest)
+	}
+	return snapshotPlan{
+		Type: snapshotBtrfs, Target: subvolume, Name: name, Snapshot: dest,
+		Command: []string{"btrfs", "subvolume", "snapshot", "-r", subvolume, dest},
+		mkdir:   dir,
+	}, nil
+}
+
+// lvmThinVolume reports whether vg/lv is a thin volume, and fails when it does not exist
+func (h *HandlerManager) lvmThinVolume(ctx context.Context, lv string) (bool, error) {
+	rows, err := h.lvmReport(ctx, "lvs", "lv", []string{"lv_full_name", "segtype"})
+	if err != nil {
+		return false, fmt.Errorf("lvm failed: %v (run as root or add lvm to --sudo-allowlist)", err)
+	}
+	for _, row := range rows {
+		if row["lv_full_name"] == lv {
+			return row["segtype"] == "thin", nil
+		}
+	}
+	return false, fmt.Errorf("logical volume %s not found", lv)
+}
+
+// planLVMSnapshot snapshots vg/lv. A thin volume's snapshot shares its pool; a classic
+// volume's gets sizePercent of the origin as copy-on-write space.
+func planLVMSnapshot(lv, name string, sizePercent int, thin bool) (snapshotPlan, error) {
+	if !lvmTargetRe.MatchString(lv) {
+		return snapshotPlan{}, fmt.Errorf("invalid logical volume %q: expected vg/lv such as vg0/root", lv)
+	}
+	vg, _, _ := strings.Cut(lv, "/")
+	command := []string{"lvm", "lvcreate", "--snapshot", "--name", name}
+	if !thin {
+		command = append(command, "--extents", fmt.Sprintf("%d%%ORIGIN", sizePercent))
+	}
+	return snapshotPlan{Type: snapshotLVM, Target: lv, Name: name, Snapshot: vg + "/" + name, Command: append(command, lv)}, nil
+}
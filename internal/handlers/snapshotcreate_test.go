@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestPlanSnapshots(t *testing.T) {
+	plan, err := planZFSSnapshot("tank/home", "pre-upgrade")
+	if err != nil || plan.Snapshot != "tank/home@pre-upgrade" || strings.Join(plan.Command, " ") != "zfs snapshot tank/home@pre-upgrade" {
+		t.Errorf("Unexpected ZFS plan: %+v, %v", plan, err)
+	}
+	for _, bad := range []string{"tank/home@x", "-o", "tank//home", "tank home"} {
+		if _, err := planZFSSnapshot(bad, "x"); err == nil {
+			t.Errorf("Expected %q to be rejected", bad)
+		}
+	}
+
+	plan, _ = planLVMSnapshot("vg0/root", "pre-upgrade", 15, false)
+	if plan.Snapshot != "vg0/pre-upgrade" || strings.Join(plan.Command, " ") != "lvm lvcreate --snapshot --name pre-upgrade --extents 15%ORIGIN vg0/root" {
+		t.Errorf("Unexpected classic LVM plan: %+v", plan)
+	}
+	plan, _ = planLVMSnapshot("vg0/data", "pre-upgrade", 15, true)
+	if strings.Join(plan.Command, " ") != "lvm lvcreate --snapshot --name pre-upgrade vg0/data" {
+		t.Errorf("Expected no size for a thin snapshot, got %v", plan.Command)
+	}
+	if _, err := planLVMSnapshot("/dev/vg0/root", "x", 10, false); err == nil {
+		t.Error("Expected a device path to be rejected")
+	}
+}
+
+func TestPlanBtrfsSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	orig := mountsPath
+	defer func() { mountsPath = orig }()
+	mountsPath = filepath.Join(dir, "mounts")
+	table := "/dev/sda2 / ext4 rw 0 0\n/dev/sdb1 " + dir + " btrfs rw,subvol=/ 0 0\n"
+	if err := os.WriteFile(mountsPath, []byte(table), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := planBtrfsSnapshot(dir+"/", "before")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dest := filepath.Join(dir, ".snapshots", "before")
+	if plan.Snapshot != dest || plan.mkdir != filepath.Join(dir, ".snapshots") || strings.Join(plan.Command, " ") != "btrfs subvolume snapshot -r "+dir+" "+dest {
+		t.Errorf("Unexpected Btrfs plan: %+v", plan)
+	}
+	if _, err := planBtrfsSnapshot("/", "before"); err == nil || !strings.Contains(err.Error(), "not on a Btrfs") {
+		t.Errorf("Expected ext4 to be rejected, got %v", err)
+	}
+	if _, err := planBtrfsSnapshot("home", "before"); err == nil {
+		t.Error("Expected a relative path to be rejected")
+	}
+	if err := os.MkdirAll(dest, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := planBtrfsSnapshot(dir, "before"); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected an existing snapshot to be rejected, got %v", err)
+	}
+}
+
+func TestHandleCreateFSSnapshot(t *testing.T) {
+	h := NewHandlerManager(&config.Config{AllowFSSnapshots: true})
+	h.caps.Zpool, h.caps.LVM = true, false
+	call := func(args map[string]interface{}) *mcp.CallToolResult {
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = args
+		res, err := h.HandleCreateFSSnapshot(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	res := call(map[string]interface{}{"type": "zfs", "target": "tank/home", "dry_run": true})
+	checkToolResult(t, res, nil, []string{"plan", "dry_run"})
+	var result struct {
+		Plan snapshotPlan `json:"plan"`
+	}
+	if err := json.Unmarshal([]byte(resultText(res)), &result); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(result.Plan.Snapshot, "tank/home@sysmetrics-") {
+		t.Errorf("Expected a default timestamped name, got %q", result.Plan.Snapshot)
+	}
+
+	for name, args := range map[string]map[string]interface{}{
+		"no confirm":       {"type": "zfs", "target": "tank/home"},
+		"no target":        {"type": "zfs", "dry_run": true},
+		"bad name":         {"type": "zfs", "target": "tank/home", "name": "../x", "dry_run": true},
+		"unknown type":     {"type": "xfs", "target": "/", "dry_run": true},
+		"missing backend":  {"type": "lvm", "target": "vg0/root", "dry_run": true},
+		"bad size_percent": {"type": "zfs", "target": "tank/home", "size_percent": float64(0), "dry_run": true},
+	} {
+		if res := call(args); !res.IsError {
+			t.Errorf("%s: expected an error, got %s", name, resultText(res))
+		}
+	}
+}
@@ -0,0 +1,378 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+// Snapshot sources
+const (
+	snapshotZFS   = "zfs"
+	snapshotBtrfs = "btrfs"
+	snapshotLVM   = "lvm"
+)
+
+// Snapshot space thresholds
+const (
+	// snapshotShareWarnPercent is the share of a pool held only by snapshots that is
+	// worth pruning
+	snapshotShareWarnPercent = 20.0
+	// lvmSnapshotFullWarnPercent is how full a classic LVM snapshot may get before it is
+	// invalidated for running out of copy-on-write space
+	lvmSnapshotFullWarnPercent = 80.0
+)
+
+// lvmSnapshotFields are the lvs columns read for snapshots
+var lvmSnapshotFields = []string{"lv_name", "vg_name", "lv_attr", "origin", "lv_size", "data_percent", "lv_time", "vg_size"}
+
+// btrfsSnapshotRe matches a `btrfs subvolume list -s` line:
+// ID 258 gen 12 cgen 12 top level 5 otime 2024-05-01 12:00:00 path .snapshots/1/snapshot
+var btrfsSnapshotRe = regexp.MustCompile(`^ID (\d+) .*otime (\S+ \S+) path (.+)$`)
+
+// snapshot is one filesystem or volume snapshot. SizeBytes is the space only the snapshot
+// holds, which is what deleting it frees; it is unknown for Btrfs without quotas.
+type snapshot struct {
+	Type        string   `json:"type"`
+	Pool        string   `json:"pool"`
+	Name        string   `json:"name"`
+	Origin      string   `json:"origin,omitempty"`
+	SizeBytes   *uint64  `json:"size_bytes,omitempty"`
+	SizeHuman   string   `json:"size_human,omitempty"`
+	UsedPercent *float64 `json:"used_percent,omitempty"`
+	Created     string   `json:"created,omitempty"`
+	AgeSeconds  int64    `json:"age_seconds,omitempty"`
+	AgeHuman    string   `json:"age_human,omitempty"`
+	created     time.Time
+	qgroup      string
+}
+
+// snapshotPool totals the snapshots of one ZFS pool, Btrfs filesystem, or volume group
+type snapshotPool struct {
+	Type          string   `json:"type"`
+	Pool          string   `json:"pool"`
+	Count         int      `json:"count"`
+	SnapshotBytes uint64   `json:"snapshot_bytes"`
+	SnapshotHuman string   `json:"snapshot_human"`
+	PoolBytes     uint64   `json:"pool_bytes,omitempty"`
+	SharePercent  *float64 `json:"share_percent,omitempty"`
+	OldestHuman   string   `json:"oldest_human,omitempty"`
+}
+
+// HandleGetSnapshots lists ZFS, Btrfs, and LVM snapshots with their sizes and ages, and
+// how much of each pool they hold
+func (h *HandlerManager) HandleGetSnapshots(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	only := "all"
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if t, ok := args["type"].(string); ok && t != "" {
+			only = strings.ToLower(t)
+		}
+	}
+	switch only {
+	case "all", snapshotZFS, snapshotBtrfs, snapshotLVM:
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid type %q: use zfs, btrfs, lvm, or all", only)), nil
+	}
+
+	result := map[string]interface{}{}
+	snapshots := []snapshot{}
+	poolSizes := map[string]uint64{}
+	if h.caps.Zpool && (only == "all" || only == snapshotZFS) {
+		snaps, sizes, err := h.collectZFSSnapshots(ctx)
+		if err != nil {
+			result["zfs_error"] = err.Error()
+		}
+		snapshots = append(snapshots, snaps...)
+		mergeSizes(poolSizes, sizes)
+	}
+	if h.caps.Btrfs && (only == "all" || only == snapshotBtrfs) {
+		snaps, sizes, err := h.collectBtrfsSnapshots(ctx)
+		if err != nil {
+			result["btrfs_error"] = err.Error()
+		}
+		snapshots = append(snapshots, snaps...)
+		mergeSizes(poolSizes, sizes)
+	}
+	if h.caps.LVM && (only == "all" || only == snapshotLVM) {
+		snaps, sizes, err := h.collectLVMSnapshots(ctx)
+		if err != nil {
+			result["lvm_error"] = fmt.Sprintf("lvm failed: %v (run as root or add lvm to --sudo-allowlist)", err)
+		}
+		snapshots = append(snapshots, snaps...)
+		mergeSizes(poolSizes, sizes)
+	}
+
+	now := time.Now()
+	for i := range snapshots {
+		s := &snapshots[i]
+		if s.SizeBytes != nil {
+			s.SizeHuman = h.human.Bytes(*s.SizeBytes)
+		}
+		if !s.created.IsZero() {
+			age := now.Sub(s.created)
+			s.Created = s.created.Format(time.RFC3339)
+			s.AgeSeconds = int64(age.Seconds())
+			s.AgeHuman = h.human.Duration(age)
+		}
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		a, b := snapshots[i], snapshots[j]
+		if a.Type != b.Type || a.Pool != b.Pool {
+			return a.Type+a.Pool < b.Type+b.Pool
+		}
+		return a.created.Before(b.created)
+	})
+
+	pools := h.summarizeSnapshots(snapshots, poolSizes, now)
+	warnings := []string{}
+	for _, p := range pools {
+		if p.SharePercent != nil && *p.SharePercent >= snapshotShareWarnPercent {
+			warnings = append(warnings, fmt.Sprintf("Snapshots hold %s (%.1f%%) of %s %s; prune old snapshots to reclaim space", p.SnapshotHuman, *p.SharePercent, p.Type, p.Pool))
+		}
+	}
+	for _, s := range snapshots {
+		if s.Type == snapshotLVM && s.UsedPercent != nil && *s.UsedPercent >= lvmSnapshotFullWarnPercent {
+			warnings = append(warnings, fmt.Sprintf("LVM snapshot %s/%s is %.1f%% full; it becomes invalid when its copy-on-write space runs out", s.Pool, s.Name, *s.UsedPercent))
+		}
+	}
+
+	result["snapshots"] = snapshots
+	result["snapshot_count"] = len(snapshots)
+	result["pools"] = pools
+	result["warnings"] = warnings
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// mergeSizes copies pool sizes keyed by type and pool name
+func mergeSizes(dst, src map[string]uint64) {
+	for k, v := range src {
+		dst[k] = v
+	}
+}
+
+// poolKey identifies a pool across snapshot sources
+func poolKey(kind, pool string) string {
+	return kind + ":" + pool
+}
+
+// summarizeSnapshots totals snapshot space and age per pool
+func (h *HandlerManager) summarizeSnapshots(snapshots []snapshot, poolSizes map[string]uint64, now time.Time) []snapshotPool {
+	byPool := map[string]*snapshotPool{}
+	oldest := map[string]time.Time{}
+	var order []string
+	for _, s := range snapshots {
+		key := poolKey(s.Type, s.Pool)
+		p, ok := byPool[key]
+		if !ok {
+			p = &snapshotPool{Type: s.Type, Pool: s.Pool, PoolBytes: poolSizes[key]}
+			byPool[key] = p
+			order = append(order, key)
+		}
+		p.Count++
+		if s.SizeBytes != nil {
+			p.SnapshotBytes += *s.SizeBytes
+		}
+		if !s.created.IsZero() && (oldest[key].IsZero() || s.created.Before(oldest[key])) {
+			oldest[key] = s.created
+		}
+	}
+	pools := []snapshotPool{}
+	for _, key := range order {
+		p := byPool[key]
+		p.SnapshotHuman = h.human.Bytes(p.SnapshotBytes)
+		if p.PoolBytes > 0 {
+			share := round2(float64(p.SnapshotBytes) / float64(p.PoolBytes) * 100)
+			p.SharePercent = &share
+		}
+		if t := oldest[key]; !t.IsZero() {
+			p.OldestHuman = h.human.Duration(now.Sub(t))
+		}
+		pools = append(pools, *p)
+	}
+	return pools
+}
+
+// collectZFSSnapshots lists ZFS snapshots with the space each holds uniquely and the pool sizes
+func (h *HandlerManager) collectZFSSnapshots(ctx context.Context) ([]snapshot, map[string]uint64, error) {
+	out, err := h.privilegedCommand(ctx, "zfs", "list", "-H", "-p", "-t", "snapshot", "-o", "name,used,creation").Output()
+	if err != nil {
+		return nil, nil, fmt.Errorf("zfs list failed: %v", err)
+	}
+	snaps := parseZFSSnapshots(string(out))
+	sizes := map[string]uint64{}
+	if out, err := h.privilegedCommand(ctx, "zpool", "list", "-H", "-p", "-o", "name,size").Output(); err == nil {
+		for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+			fields := strings.Split(line, "\t")
+			if len(fields) != 2 {
+				continue
+			}
+			if size, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+				sizes[poolKey(snapshotZFS, fields[0])] = size
+			}
+		}
+	}
+	return snaps, sizes, nil
+}
+
+// parseZFSSnapshots parses `zfs list -H -p -t snapshot -o name,used,creation`, which is
+// tab-separated with sizes in bytes and creation times as Unix seconds:
+//
+//	tank/home@auto-2024-05-01	1048576	1714564800
+func parseZFSSnapshots(out string) []snapshot {
+	var snaps []snapshot
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			continue
+		}
+		dataset, name, ok := strings.Cut(fields[0], "@")
+		if !ok {
+			continue
+		}
+		pool, _, _ := strings.Cut(dataset, "/")
+		s := snapshot{Type: snapshotZFS, Pool: pool, Name: name, Origin: dataset}
+		if used, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+			s.SizeBytes = &used
+		}
+		if epoch, err := strconv.ParseInt(fields[2], 10, 64); err == nil {
+			s.created = time.Unix(epoch, 0)
+		}
+		snaps = append(snaps, s)
+	}
+	return snaps
+}
+
+// collectBtrfsSnapshots lists the snapshots of each mounted Btrfs filesystem. Sizes come
+// from quota groups, so they are only known where quotas are enabled.
+func (h *HandlerManager) collectBtrfsSnapshots(ctx context.Context) ([]snapshot, map[string]uint64, error) {
+	mounts, err := readMountTable(mountsPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", mountsPath, err)
+	}
+	var snaps []snapshot
+	sizes := map[string]uint64{}
+	var failures []string
+	seen := map[string]bool{}
+	for _, m := range mounts {
+		if m.Fstype != poolBtrfs || seen[m.Device] {
+			continue
+		}
+		// Subvolumes of one filesystem share its device
+		seen[m.Device] = true
+		out, err := h.privilegedCommand(ctx, "btrfs", "subvolume", "list", "-s", m.MountPoint).Output()
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("btrfs subvolume list %s: %v", m.MountPoint, err))
+			continue
+		}
+		fsSnaps := parseBtrfsSnapshots(string(out), m.MountPoint, time.Local)
+		// qgroup show fails when quotas are disabled, leaving the sizes unknown
+		if out, err := h.privilegedCommand(ctx, "btrfs", "qgroup", "show", "--raw", m.MountPoint).Output(); err == nil {
+			exclusive := parseBtrfsQgroups(string(out))
+			for i := range fsSnaps {
+				if excl, ok := exclusive[fsSnaps[i].qgroup]; ok {
+					fsSnaps[i].SizeBytes = &excl
+				}
+			}
+		}
+		if usage, err := disk.UsageWithContext(ctx, m.MountPoint); err == nil {
+			sizes[poolKey(snapshotBtrfs, m.MountPoint)] = usage.Total
+		}
+		snaps = append(snaps, fsSnaps...)
+	}
+	if len(failures) > 0 {
+		return snaps, sizes, fmt.Errorf("%s (run as root or add btrfs to --sudo-allowlist)", strings.Join(failures, "; "))
+	}
+	return snaps, sizes, nil
+}
+
+// parseBtrfsSnapshots parses `btrfs subvolume list -s`, with times in the host's zone
+func parseBtrfsSnapshots(out, mountPoint string, loc *time.Location) []snapshot {
+	var snaps []snapshot
+	for _, line := range strings.Split(out, "\n") {
+		m := btrfsSnapshotRe.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		s := snapshot{Type: snapshotBtrfs, Pool: mountPoint, Name: m[3], qgroup: "0/" + m[1]}
+		if t, err := time.ParseInLocation("2006-01-02 15:04:05", m[2], loc); err == nil {
+			s.created = t
+		}
+		snaps = append(snaps, s)
+	}
+	return snaps
+}
+
+// parseBtrfsQgroups maps level-0 quota groups to their exclusive bytes from
+// `btrfs qgroup show --raw`:
+//
+//	qgroupid         rfer         excl
+//	--------         ----         ----
+//	0/258        16777216      1048576
+func parseBtrfsQgroups(out string) map[string]uint64 {
+	exclusive := map[string]uint64{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || !strings.HasPrefix(fields[0], "0/") {
+			continue
+		}
+		if excl, err := strconv.ParseUint(fields[2], 10, 64); err == nil {
+			exclusive[fields[0]] = excl
+		}
+	}
+	return exclusive
+}
+
+// collectLVMSnapshots lists classic and thin LVM snapshots with the volume group sizes
+func (h *HandlerManager) collectLVMSnapshots(ctx context.Context) ([]snapshot, map[string]uint64, error) {
+	rows, err := h.lvmReport(ctx, "lvs", "lv", lvmSnapshotFields)
+	if err != nil {
+		return nil, nil, err
+	}
+	snaps, sizes := parseLVMSnapshots(rows)
+	return snaps, sizes, nil
+}
+
+// parseLVMSnapshots picks the snapshots out of lvs rows. A classic snapshot ('s' attribute)
+// holds data_percent of its copy-on-write size; a thin snapshot is a thin volume with an
+// origin, whose unique usage LVM does not report.
+func parseLVMSnapshots(rows []map[string]string) ([]snapshot, map[string]uint64) {
+	var snaps []snapshot
+	sizes := map[string]uint64{}
+	for _, row := range rows {
+		attr := row["lv_attr"]
+		classic := attrAt(attr, 0) == 's' || attrAt(attr, 0) == 'S'
+		thin := attrAt(attr, 0) == 'V' && row["origin"] != ""
+		if !classic && !thin {
+			continue
+		}
+		s := snapshot{Type: snapshotLVM, Pool: row["vg_name"], Name: row["lv_name"], Origin: row["origin"]}
+		if classic {
+			s.UsedPercent = parseLVMPercent(row["data_percent"])
+			if s.UsedPercent != nil {
+				used := uint64(float64(parseLVMSize(row["lv_size"])) * *s.UsedPercent / 100)
+				s.SizeBytes = &used
+			}
+		}
+		if t, err := time.Parse("2006-01-02 15:04:05 -0700", row["lv_time"]); err == nil {
+			s.created = t
+		}
+		if size := parseLVMSize(row["vg_size"]); size > 0 {
+			sizes[poolKey(snapshotLVM, s.Pool)] = size
+		}
+		snaps = append(snaps, s)
+	}
+	return snaps, sizes
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"sysmetrics-mcp/internal/config"
+)
+
+func TestParseZFSSnapshots(t *testing.T) {
+	out := "tank/home@auto-2024-05-01\t1048576\t1714564800\ntank@base\t0\t1704067200\nbroken line\n"
+	snaps := parseZFSSnapshots(out)
+	if len(snaps) != 2 {
+		t.Fatalf("Expected 2 snapshots, got %+v", snaps)
+	}
+	if s := snaps[0]; s.Pool != "tank" || s.Name != "auto-2024-05-01" || s.Origin != "tank/home" || s.SizeBytes == nil || *s.SizeBytes != 1048576 || s.created.Unix() != 1714564800 {
+		t.Errorf("Unexpected snapshot: %+v", s)
+	}
+	if s := snaps[1]; s.Origin != "tank" || *s.SizeBytes != 0 {
+		t.Errorf("Unexpected pool root snapshot: %+v", s)
+	}
+}
+
+func TestParseBtrfsSnapshots(t *testing.T) {
+	list := "ID 258 gen 12 cgen 12 top level 5 otime 2024-05-01 12:00:00 path .snapshots/1/snapshot\nID 259 gen 20 cgen 20 top level 5 otime 2024-05-02 12:00:00 path .snapshots/2/snapshot\n"
+	snaps := parseBtrfsSnapshots(list, "/", time.UTC)
+	if len(snaps) != 2 || snaps[0].Name != ".snapshots/1/snapshot" || snaps[0].qgroup != "0/258" || snaps[1].created.Day() != 2 {
+		t.Fatalf("Unexpected snapshots: %+v", snaps)
+	}
+
+	qgroups := parseBtrfsQgroups("qgroupid         rfer         excl \n--------         ----         ---- \n0/5          16384        16384 \n0/258        16777216      1048576 \n1/100  0 0\n")
+	if len(qgroups) != 2 || qgroups["0/258"] != 1048576 {
+		t.Errorf("Unexpected quota groups: %v", qgroups)
+	}
+}
+
+func TestParseLVMSnapshots(t *testing.T) {
+	rows := []map[string]string{
+		{"lv_name": "root", "vg_name": "vg0", "lv_attr": "owi-aos---", "lv_size": "21474836480", "vg_size": "107374182400"},
+		{"lv_name": "root-snap", "vg_name": "vg0", "lv_attr": "swi-a-s---", "origin": "root", "lv_size": "4294967296", "data_percent": "90.00", "lv_time": "2024-05-01 12:00:00 +0000", "vg_size": "107374182400"},
+		{"lv_name": "data-snap", "vg_name": "vg0", "lv_attr": "Vwi---tz-k", "origin": "data", "lv_size": "10737418240", "lv_time": "2024-05-02 12:00:00 +0000", "vg_size": "107374182400"},
+		{"lv_name": "data", "vg_name": "vg0", "lv_attr": "Vwi-aotz--", "lv_size": "10737418240", "vg_size": "107374182400"},
+	}
+	snaps, sizes := parseLVMSnapshots(rows)
+	if len(snaps) != 2 || sizes[poolKey(snapshotLVM, "vg0")] != 107374182400 {
+		t.Fatalf("Expected a classic and a thin snapshot, got %+v", snaps)
+	}
+	if s := snaps[0]; s.Origin != "root" || s.SizeBytes == nil || *s.SizeBytes != 3865470566 || s.created.Year() != 2024 {
+		t.Errorf("Unexpected classic snapshot: %+v", s)
+	}
+	if s := snaps[1]; s.SizeBytes != nil || s.UsedPercent != nil {
+		t.Errorf("Expected no size for a thin snapshot, got %+v", s)
+	}
+}
+
+func TestSummarizeSnapshots(t *testing.T) {
+	h := NewHandlerManager(&config.Config{})
+	now := time.Now()
+	gib := uint64(1 << 30)
+	snaps := []snapshot{
+		{Type: snapshotZFS, Pool: "tank", SizeBytes: &gib, created: now.Add(-48 * time.Hour)},
+		{Type: snapshotZFS, Pool: "tank", SizeBytes: &gib, created: now.Add(-time.Hour)},
+		{Type: snapshotBtrfs, Pool: "/"},
+	}
+	pools := h.summarizeSnapshots(snaps, map[string]uint64{poolKey(snapshotZFS, "tank"): 8 * gib}, now)
+	if len(pools) != 2 || pools[0].Count != 2 || pools[0].SnapshotBytes != 2*gib || pools[0].SharePercent == nil || *pools[0].SharePercent != 25 {
+		t.Fatalf("Unexpected pool totals: %+v", pools)
+	}
+	if !strings.Contains(pools[0].OldestHuman, "2") || pools[1].SharePercent != nil {
+		t.Errorf("Unexpected oldest age or share: %+v", pools)
+	}
+}
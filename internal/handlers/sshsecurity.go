@@ -0,0 +1,599 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// sshTimeout bounds reading the auth log, sshd's configuration, and fail2ban
+const sshTimeout = 15 * time.Second
+
+// get_ssh_security defaults
+const (
+	defaultSSHSince = 24 * time.Hour
+	defaultSSHLimit = 10
+	maxSSHLimit     = 100
+)
+
+// sshBruteForceThreshold is how many failures from one address in the window are worth a warning
+const sshBruteForceThreshold = 20
+
+// SSH security sources
+var (
+	sshdConfigPath  = "/etc/ssh/sshd_config"
+	sshAuthLogPaths = []string{"/var/log/auth.log", "/var/log/secure"}
+)
+
+// SSH auth event kinds
+const (
+	sshFailed      = "failed"
+	sshInvalidUser = "invalid_user"
+	sshAccepted    = "accepted"
+)
+
+// Red flag severities
+const (
+	sshSeverityCritical = "critical"
+	sshSeverityWarning  = "warning"
+)
+
+// sshd log lines
+var (
+	// "Failed password for invalid user admin from 203.0.113.7 port 52144 ssh2"
+	sshFailedRe = regexp.MustCompile(`^Failed (\S+) for (invalid user )?(.*?) from (\S+) port \d+`)
+	// "Invalid user admin from 203.0.113.7 port 52144"
+	sshInvalidUserRe = regexp.MustCompile(`^Invalid user (.*?) from (\S+)`)
+	// "Accepted publickey for pi from 192.168.1.20 port 50022 ssh2: ED25519 SHA256:..."
+	sshAcceptedRe = regexp.MustCompile(`^Accepted (\S+) for (\S+) from (\S+) port \d+`)
+	// rsyslog folds duplicates: "message repeated 5 times: [ Failed password for root ...]"
+	sshRepeatedRe = regexp.MustCompile(`^message repeated (\d+) times: \[\s*(.*?)\s*\]$`)
+	// auth.log lines in RFC 3339 (Debian 12+) or classic syslog format
+	authLogLineRe = regexp.MustCompile(`^(?:(\d{4}-\d\d-\d\dT\S+)|(\w{3} [ \d]\d \d\d:\d\d:\d\d)) \S+ sshd(?:-session)?\[\d+\]: (.*)$`)
+)
+
+// sshdReportedSettings are the sshd options get_ssh_security returns
+var sshdReportedSettings = []string{
+	"port", "permitrootlogin", "passwordauthentication", "kbdinteractiveauthentication",
+	"permitemptypasswords", "pubkeyauthentication", "usepam", "maxauthtries", "allowusers", "allowgroups",
+}
+
+// sshdDefaults are OpenSSH's built-in values for options missing from sshd_config
+var sshdDefaults = map[string]string{
+	"port":                         "22",
+	"permitrootlogin":              "prohibit-password",
+	"passwordauthentication":       "yes",
+	"kbdinteractiveauthentication": "yes",
+	"permitemptypasswords":         "no",
+	"pubkeyauthentication":         "yes",
+	"usepam":                       "no",
+	"maxauthtries":                 "6",
+}
+
+// sshAuthEvent is one authentication result from sshd's log
+type sshAuthEvent struct {
+	Time     string
+	Kind     string
+	Method   string
+	Username string
+	IP       string
+	Count    int
+}
+
+// sshSource is one address that failed to log in
+type sshSource struct {
+	IP        string `json:"ip"`
+	Failures  int    `json:"failures"`
+	Usernames int    `json:"usernames"`
+	LastSeen  string `json:"last_seen,omitempty"`
+	Banned    bool   `json:"banned"`
+	LoggedIn  bool   `json:"logged_in"`
+}
+
+// sshUsername is one account name tried by failed logins
+type sshUsername struct {
+	Username string `json:"username"`
+	Failures int    `json:"failures"`
+	Invalid  bool   `json:"invalid"`
+}
+
+// sshRedFlag is a risky sshd setting
+type sshRedFlag struct {
+	Setting  string `json:"setting"`
+	Value    string `json:"value"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// fail2banJail is the state of one fail2ban jail
+type fail2banJail struct {
+	Jail            string   `json:"jail"`
+	CurrentlyFailed int      `json:"currently_failed"`
+	TotalFailed     int      `json:"total_failed"`
+	CurrentlyBanned int      `json:"currently_banned"`
+	TotalBanned     int      `json:"total_banned"`
+	BannedIPs       []string `json:"banned_ips"`
+}
+
+// HandleGetSSHSecurity summarizes failed SSH logins by address and username, addresses
+// banned by fail2ban, and risky sshd settings
+func (h *HandlerManager) HandleGetSSHSecurity(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	since := defaultSSHSince
+	limit := defaultSSHLimit
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if s, ok := args["since"].(string); ok && s != "" {
+			d, err := parseHistoryDuration(s)
+			if err != nil || d <= 0 {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid since: %q (use a duration such as 30m, 6h, or 7d)", s)), nil
+			}
+			since = d
+		}
+		if l, ok := args["limit"].(float64); ok && l > 0 {
+			limit = min(int(l), maxSSHLimit)
+		}
+	}
+	now := time.Now()
+	start := now.Add(-since)
+
+	ctx, cancel := context.WithTimeout(ctx, sshTimeout)
+	defer cancel()
+
+	result := map[string]interface{}{"since": start.UTC().Format(time.RFC3339)}
+	warnings := []string{}
+
+	var banned map[string]bool
+	if h.caps.Fail2ban {
+		jails, err := h.readFail2ban(ctx)
+		f2b := map[string]interface{}{"installed": true}
+		if err != nil {
+			f2b["error"] = fmt.Sprintf("%v (fail2ban-client needs root or an entry in --sudo-allowlist)", err)
+		} else {
+			banned = map[string]bool{}
+			total := 0
+			for _, j := range jails {
+				total += j.CurrentlyBanned
+				for _, ip := range j.BannedIPs {
+					banned[ip] = true
+				}
+			}
+			f2b["jails"] = jails
+			f2b["currently_banned"] = total
+		}
+		result["fail2ban"] = f2b
+	} else {
+		result["fail2ban"] = map[string]interface{}{"installed": false}
+	}
+
+	settings, configSource, err := h.readSSHDConfig(ctx)
+	var flags []sshRedFlag
+	if err != nil {
+		result["config"] = map[string]interface{}{"error": fmt.Sprintf("Failed to read the sshd configuration: %v", err)}
+	} else {
+		flags = sshdRedFlags(settings)
+		reported := map[string]string{}
+		for _, k := range sshdReportedSettings {
+			if v, ok := settings[k]; ok {
+				reported[k] = v
+			}
+		}
+		result["config"] = map[string]interface{}{
+			"source":    configSource,
+			"settings":  reported,
+			"red_flags": flags,
+		}
+	}
+
+	entries, logSource, err := h.readSSHAuthLog(ctx, start, now)
+	if err != nil {
+		result["logins_error"] = fmt.Sprintf("Failed to read the auth log: %v (run the server as root, in the systemd-journal or adm group)", err)
+	} else {
+		events := []sshAuthEvent{}
+		for _, e := range parseSSHAuthEvents(entries) {
+			if t, err := time.Parse(time.RFC3339, e.Time); err == nil && t.Before(start) {
+				continue
+			}
+			events = append(events, e)
+		}
+		logins := summarizeSSHEvents(events, banned, limit)
+		logins["source"] = logSource
+		result["logins"] = logins
+		warnings = append(warnings, sshWarnings(events, flags, h.caps.Fail2ban)...)
+	}
+	result["warnings"] = warnings
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// readSSHAuthLog returns sshd's messages since start from the journal, or from the
+// syslog auth log on hosts without a readable journal
+func (h *HandlerManager) readSSHAuthLog(ctx context.Context, start, now time.Time) ([]journalEntry, string, error) {
+	journalOK := false
+	if h.caps.Systemd {
+		// OpenSSH 9.8+ logs authentication from the sshd-session binary
+		out, err := h.privilegedCommand(ctx, "journalctl", fmt.Sprintf("--since=@%d", start.Unix()), "--no-pager", "-o", "json",
+			"SYSLOG_IDENTIFIER=sshd", "+", "SYSLOG_IDENTIFIER=sshd-session").Output()
+		if err == nil {
+			journalOK = true
+			if entries := parseJournalEntries(out); len(entries) > 0 {
+				return entries, "journal", nil
+			}
+		}
+	}
+
+	var lastErr error
+	for _, path := range sshAuthLogPaths {
+		data, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return parseAuthLog(string(data), now), path, nil
+	}
+	if journalOK {
+		// A readable journal with no sshd messages is an answer, not an error
+		return []journalEntry{}, "journal", nil
+	}
+	return nil, "", lastErr
+}
+
+// parseAuthLog parses sshd lines from a syslog auth log. Classic syslog timestamps carry
+// no year, so the most recent year that does not put the line in the future is assumed.
+func parseAuthLog(data string, now time.Time) []journalEntry {
+	entries := []journalEntry{}
+	for _, line := range strings.Split(data, "\n") {
+		m := authLogLineRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		e := journalEntry{Priority: 6, Message: truncateString(strings.TrimSpace(m[3]), journalMessageLimit)}
+		switch {
+		case m[1] != "":
+			if t, err := time.Parse(time.RFC3339Nano, m[1]); err == nil {
+				e.Time = t.UTC().Format(time.RFC3339)
+			}
+		case m[2] != "":
+			if t, err := time.ParseInLocation("Jan _2 15:04:05", m[2], now.Location()); err == nil {
+				t = t.AddDate(now.Year(), 0, 0)
+				if t.After(now.Add(24 * time.Hour)) {
+					t = t.AddDate(-1, 0, 0)
+				}
+				e.Time = t.UTC().Format(time.RFC3339)
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// parseSSHAuthEvents extracts failed, invalid-user, and accepted logins from sshd
+// messages. A connection for an account that does not exist is counted once, from its
+// "Invalid user" line, rather than once per password it tries.
+func parseSSHAuthEvents(entries []journalEntry) []sshAuthEvent {
+	events := []sshAuthEvent{}
+	for _, e := range entries {
+		msg, count := strings.TrimSpace(e.Message), 1
+		if m := sshRepeatedRe.FindStringSubmatch(msg); m != nil {
+			count, _ = strconv.Atoi(m[1])
+			msg = m[2]
+		}
+		if m := sshFailedRe.FindStringSubmatch(msg); m != nil {
+			if m[2] == "" {
+				events = append(events, sshAuthEvent{Time: e.Time, Kind: sshFailed, Method: m[1], Username: m[3], IP: m[4], Count: count})
+			}
+			continue
+		}
+		if m := sshInvalidUserRe.FindStringSubmatch(msg); m != nil {
+			events = append(events, sshAuthEvent{Time: e.Time, Kind: sshInvalidUser, Username: m[1], IP: m[2], Count: count})
+			continue
+		}
+		if m := sshAcceptedRe.FindStringSubmatch(msg); m != nil {
+			events = append(events, sshAuthEvent{Time: e.Time, Kind: sshAccepted, Method: m[1], Username: m[2], IP: m[3], Count: count})
+		}
+	}
+	return events
+}
+
+// summarizeSSHEvents counts failures per source address and username, keeping the limit
+// most frequent of each, and successful logins per method
+func summarizeSSHEvents(events []sshAuthEvent, banned map[string]bool, limit int) map[string]interface{} {
+	sources := map[string]*sshSource{}
+	sourceUsers := map[string]map[string]bool{}
+	users := map[string]*sshUsername{}
+	accepted := map[string]int{}
+	loggedIn := map[string]bool{}
+	failures, acceptedTotal := 0, 0
+	for _, e := range events {
+		if e.Kind == sshAccepted {
+			accepted[e.Method] += e.Count
+			acceptedTotal += e.Count
+			loggedIn[e.IP] = true
+			continue
+		}
+		failures += e.Count
+		s, ok := sources[e.IP]
+		if !ok {
+			s = &sshSource{IP: e.IP, Banned: banned[e.IP]}
+			sources[e.IP] = s
+			sourceUsers[e.IP] = map[string]bool{}
+		}
+		s.Failures += e.Count
+		s.LastSeen = e.Time
+		sourceUsers[e.IP][e.Username] = true
+		s.Usernames = len(sourceUsers[e.IP])
+
+		u, ok := users[e.Username]
+		if !ok {
+			u = &sshUsername{Username: e.Username}
+			users[e.Username] = u
+		}
+		u.Failures += e.Count
+		u.Invalid = u.Invalid || e.Kind == sshInvalidUser
+	}
+
+	byIP := make([]sshSource, 0, len(sources))
+	for _, s := range sources {
+		s.LoggedIn = loggedIn[s.IP]
+		byIP = append(byIP, *s)
+	}
+	sort.Slice(byIP, func(i, j int) bool {
+		if byIP[i].Failures != byIP[j].Failures {
+			return byIP[i].Failures > byIP[j].Failures
+		}
+		return byIP[i].IP < byIP[j].IP
+	})
+	byUser := make([]sshUsername, 0, len(users))
+	for _, u := range users {
+		byUser = append(byUser, *u)
+	}
+	sort.Slice(byUser, func(i, j int) bool {
+		if byUser[i].Failures != byUser[j].Failures {
+			return byUser[i].Failures > byUser[j].Failures
+		}
+		return byUser[i].Username < byUser[j].Username
+	})
+	if len(byIP) > limit {
+		byIP = byIP[:limit]
+	}
+	if len(byUser) > limit {
+		byUser = byUser[:limit]
+	}
+
+	return map[string]interface{}{
+		"failed_attempts": failures,
+		"unique_sources":  len(sources),
+		"by_ip":           byIP,
+		"by_username":     byUser,
+		"accepted": map[string]interface{}{
+			"total":     acceptedTotal,
+			"by_method": accepted,
+		},
+	}
+}
+
+// sshWarnings flags brute-force sources, successful logins from addresses that had been
+// failing, and password logins left open to them
+func sshWarnings(events []sshAuthEvent, flags []sshRedFlag, hasFail2ban bool) []string {
+	warnings := []string{}
+	failures := map[string]int{}
+	for _, e := range events {
+		if e.Kind != sshAccepted {
+			failures[e.IP] += e.Count
+		}
+	}
+	seen := map[string]bool{}
+	for _, e := range events {
+		if e.Kind != sshAccepted || failures[e.IP] == 0 || seen[e.IP+"|"+e.Username] {
+			continue
+		}
+		seen[e.IP+"|"+e.Username] = true
+		warnings = append(warnings, fmt.Sprintf("%s logged in as %s with %s after %d failed attempts from the same address; confirm this was expected", e.IP, e.Username, e.Method, failures[e.IP]))
+	}
+
+	var noisy []string
+	for ip, n := range failures {
+		if n >= sshBruteForceThreshold {
+			noisy = append(noisy, ip)
+		}
+	}
+	sort.Strings(noisy)
+	for _, ip := range noisy {
+		warnings = append(warnings, fmt.Sprintf("%s failed to log in %d times", ip, failures[ip]))
+	}
+
+	passwords := false
+	for _, f := range flags {
+		if f.Setting == "passwordauthentication" || f.Setting == "kbdinteractiveauthentication" {
+			passwords = true
+		}
+	}
+	if passwords && len(noisy) > 0 && !hasFail2ban {
+		warnings = append(warnings, "password logins are enabled while under brute-force attempts and fail2ban is not installed")
+	}
+	return warnings
+}
+
+// readSSHDConfig returns sshd's effective settings from "sshd -T", which needs root to
+// load the host keys, or parses sshd_config and its includes otherwise
+func (h *HandlerManager) readSSHDConfig(ctx context.Context) (map[string]string, string, error) {
+	if out, err := h.privilegedCommand(ctx, "sshd", "-T").Output(); err == nil {
+		settings := map[string]string{}
+		for _, line := range strings.Split(string(out), "\n") {
+			if k, v, ok := strings.Cut(strings.TrimSpace(line), " "); ok {
+				settings[normalizeSSHDKey(k)] = v
+			}
+		}
+		if len(settings) > 0 {
+			return settings, "sshd -T", nil
+		}
+	}
+
+	settings := map[string]string{}
+	if err := parseSSHDConfig(sshdConfigPath, settings, 0); err != nil {
+		return nil, "", err
+	}
+	for k, v := range sshdDefaults {
+		if _, ok := settings[k]; !ok {
+			settings[k] = v
+		}
+	}
+	return settings, sshdConfigPath, nil
+}
+
+// parseSSHDConfig reads an sshd_config file into settings. As in sshd, the first value
+// of an option wins and Include is expanded in place; Match blocks are skipped since they
+// only apply to some connections.
+func parseSSHDConfig(path string, settings map[string]string, depth int) error {
+	if depth > 8 {
+		return fmt.Errorf("%s: includes nested too deeply", path)
+	}
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(strings.Replace(line, "=", " ", 1))
+		if len(fields) < 2 {
+			continue
+		}
+		key := normalizeSSHDKey(fields[0])
+		switch key {
+		case "match":
+			return nil
+		case "include":
+			for _, pattern := range fields[1:] {
+				if !filepath.IsAbs(pattern) {
+					pattern = filepath.Join(filepath.Dir(sshdConfigPath), pattern)
+				}
+				matches, _ := filepath.Glob(pattern)
+				sort.Strings(matches)
+				for _, m := range matches {
+					if err := parseSSHDConfig(m, settings, depth+1); err != nil {
+						return err
+					}
+				}
+			}
+			continue
+		}
+		if _, ok := settings[key]; !ok {
+			settings[key] = strings.Join(fields[1:], " ")
+		}
+	}
+	return nil
+}
+
+// normalizeSSHDKey lowercases an option name and maps the deprecated
+// ChallengeResponseAuthentication to its replacement
+func normalizeSSHDKey(k string) string {
+	k = strings.ToLower(k)
+	if k == "challengeresponseauthentication" {
+		return "kbdinteractiveauthentication"
+	}
+	return k
+}
+
+// sshdRedFlags lists settings that let attackers guess their way in
+func sshdRedFlags(settings map[string]string) []sshRedFlag {
+	flags := []sshRedFlag{}
+	settings = maps.Clone(settings)
+	for k, v := range settings {
+		settings[k] = strings.ToLower(v)
+	}
+	if v := settings["permitrootlogin"]; v == "yes" {
+		flags = append(flags, sshRedFlag{"permitrootlogin", v, sshSeverityCritical,
+			"root can log in with a password; set PermitRootLogin prohibit-password or no"})
+	}
+	if v := settings["permitemptypasswords"]; v == "yes" {
+		flags = append(flags, sshRedFlag{"permitemptypasswords", v, sshSeverityCritical,
+			"accounts with empty passwords can log in; set PermitEmptyPasswords no"})
+	}
+	if v := settings["passwordauthentication"]; v == "yes" {
+		flags = append(flags, sshRedFlag{"passwordauthentication", v, sshSeverityWarning,
+			"password logins are allowed and can be brute-forced; use keys and set PasswordAuthentication no"})
+	}
+	// Keyboard-interactive goes through PAM, which asks for the same password
+	if v := settings["kbdinteractiveauthentication"]; v == "yes" && settings["usepam"] == "yes" {
+		flags = append(flags, sshRedFlag{"kbdinteractiveauthentication", v, sshSeverityWarning,
+			"PAM keyboard-interactive logins accept passwords even with PasswordAuthentication no; set KbdInteractiveAuthentication no"})
+	}
+	return flags
+}
+
+// readFail2ban returns each fail2ban jail with its failure and ban counts
+func (h *HandlerManager) readFail2ban(ctx context.Context) ([]fail2banJail, error) {
+	out, err := h.privilegedCommand(ctx, "fail2ban-client", "status").CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("fail2ban-client status failed: %v: %s", err, truncateString(strings.TrimSpace(string(out)), 200))
+	}
+	jails := []fail2banJail{}
+	for _, name := range fail2banJailList(string(out)) {
+		out, err := h.privilegedCommand(ctx, "fail2ban-client", "status", name).Output()
+		if err != nil {
+			continue
+		}
+		jails = append(jails, parseFail2banJail(name, string(out)))
+	}
+	return jails, nil
+}
+
+// fail2banFields parses fail2ban-client's tree output ("|- Currently banned:\t2") into
+// its labels and values
+func fail2banFields(out string) map[string]string {
+	fields := map[string]string{}
+	for _, line := range strings.Split(out, "\n") {
+		k, v, ok := strings.Cut(strings.TrimLeft(line, "|`- \t"), ":")
+		if ok {
+			fields[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	return fields
+}
+
+// fail2banJailList returns the jail names from "fail2ban-client status"
+func fail2banJailList(out string) []string {
+	names := []string{}
+	for _, n := range strings.Split(fail2banFields(out)["Jail list"], ",") {
+		if n = strings.TrimSpace(n); n != "" {
+			names = append(names, n)
+		}
+	}
+	return names
+}
+
+// parseFail2banJail parses "fail2ban-client status <jail>"
+func parseFail2banJail(name, out string) fail2banJail {
+	f := fail2banFields(out)
+	atoi := func(k string) int {
+		n, _ := strconv.Atoi(f[k])
+		return n
+	}
+	ips := strings.Fields(f["Banned IP list"])
+	if ips == nil {
+		ips = []string{}
+	}
+	return fail2banJail{
+		Jail:            name,
+		CurrentlyFailed: atoi("Currently failed"),
+		TotalFailed:     atoi("Total failed"),
+		CurrentlyBanned: atoi("Currently banned"),
+		TotalBanned:     atoi("Total banned"),
+		BannedIPs:       ips,
+	}
+}
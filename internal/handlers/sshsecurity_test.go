@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseSSHAuthEvents(t *testing.T) {
+	messages := []string{
+		"Invalid user admin from 203.0.113.7 port 52144",
+		"Failed password for invalid user admin from 203.0.113.7 port 52144 ssh2",
+		"Failed password for root from 203.0.113.7 port 52150 ssh2",
+		"message repeated 4 times: [ Failed password for root from 203.0.113.7 port 52150 ssh2]",
+		"Failed publickey for pi from 198.51.100.2 port 40000 ssh2: ED25519 SHA256:abc",
+		"Accepted password for pi from 198.51.100.2 port 40001 ssh2",
+		"Connection closed by authenticating user root 203.0.113.7 port 52150 [preauth]",
+	}
+	entries := make([]journalEntry, len(messages))
+	for i, m := range messages {
+		entries[i] = journalEntry{Message: m}
+	}
+	events := parseSSHAuthEvents(entries)
+	if len(events) != 5 {
+		t.Fatalf("Expected 5 events, got %+v", events)
+	}
+	if e := events[0]; e.Kind != sshInvalidUser || e.Username != "admin" || e.IP != "203.0.113.7" {
+		t.Errorf("Unexpected invalid user event: %+v", e)
+	}
+	if e := events[2]; e.Kind != sshFailed || e.Username != "root" || e.Count != 4 {
+		t.Errorf("Expected the repeated failure counted 4 times, got %+v", e)
+	}
+	if e := events[4]; e.Kind != sshAccepted || e.Method != "password" || e.Username != "pi" {
+		t.Errorf("Unexpected accepted event: %+v", e)
+	}
+
+	logins := summarizeSSHEvents(events, map[string]bool{"203.0.113.7": true}, 10)
+	if logins["failed_attempts"] != 7 || logins["unique_sources"] != 2 {
+		t.Errorf("Unexpected totals: %v", logins)
+	}
+	byIP := logins["by_ip"].([]sshSource)
+	if byIP[0].IP != "203.0.113.7" || byIP[0].Failures != 6 || byIP[0].Usernames != 2 || !byIP[0].Banned || byIP[0].LoggedIn {
+		t.Errorf("Unexpected top source: %+v", byIP[0])
+	}
+	if !byIP[1].LoggedIn {
+		t.Errorf("Expected 198.51.100.2 to be marked as logged in, got %+v", byIP[1])
+	}
+	byUser := logins["by_username"].([]sshUsername)
+	if byUser[0].Username != "root" || byUser[0].Failures != 5 || byUser[1].Username != "admin" || !byUser[1].Invalid {
+		t.Errorf("Unexpected usernames: %+v", byUser)
+	}
+
+	warnings := sshWarnings(events, nil, false)
+	if len(warnings) != 1 {
+		t.Errorf("Expected a warning for the login after failures, got %v", warnings)
+	}
+}
+
+func TestParseAuthLog(t *testing.T) {
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	data := "2026-01-02T10:00:00.123456+00:00 pi sshd[812]: Failed password for root from 203.0.113.7 port 1 ssh2\n" +
+		"Dec 31 23:59:59 pi sshd[800]: Invalid user test from 203.0.113.9 port 2\n" +
+		"Jan  2 11:00:00 pi sshd-session[900]: Accepted publickey for pi from 198.51.100.2 port 3 ssh2\n" +
+		"Jan  2 11:00:01 pi sudo[901]: pi : TTY=pts/0 ; COMMAND=/bin/true\n"
+	entries := parseAuthLog(data, now)
+	if len(entries) != 3 {
+		t.Fatalf("Expected the 3 sshd lines, got %+v", entries)
+	}
+	if entries[0].Time != "2026-01-02T10:00:00Z" || entries[1].Time != "2025-12-31T23:59:59Z" || entries[2].Time != "2026-01-02T11:00:00Z" {
+		t.Errorf("Unexpected timestamps: %+v", entries)
+	}
+}
+
+func TestParseSSHDConfig(t *testing.T) {
+	dir := t.TempDir()
+	old := sshdConfigPath
+	t.Cleanup(func() { sshdConfigPath = old })
+	sshdConfigPath = filepath.Join(dir, "sshd_config")
+	writeSysfsFiles(t, filepath.Join(dir, "sshd_config.d"), map[string]string{
+		"50-cloud-init.conf": "PasswordAuthentication yes",
+	})
+	if err := os.WriteFile(sshdConfigPath, []byte("Include sshd_config.d/*.conf\n# PermitRootLogin no\nPermitRootLogin yes\n"+
+		"PasswordAuthentication no\nChallengeResponseAuthentication no\nUsePAM yes\nMatch User backup\n  PermitEmptyPasswords yes\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	settings := map[string]string{}
+	if err := parseSSHDConfig(sshdConfigPath, settings, 0); err != nil {
+		t.Fatalf("parseSSHDConfig failed: %v", err)
+	}
+	if settings["passwordauthentication"] != "yes" || settings["permitrootlogin"] != "yes" ||
+		settings["kbdinteractiveauthentication"] != "no" || settings["permitemptypasswords"] != "" {
+		t.Errorf("Unexpected settings: %v", settings)
+	}
+
+	flags := sshdRedFlags(settings)
+	if len(flags) != 2 || flags[0].Setting != "permitrootlogin" || flags[0].Severity != sshSeverityCritical || flags[1].Setting != "passwordauthentication" {
+		t.Errorf("Unexpected red flags: %+v", flags)
+	}
+	if flags := sshdRedFlags(map[string]string{"kbdinteractiveauthentication": "yes", "usepam": "yes"}); len(flags) != 1 {
+		t.Errorf("Expected keyboard-interactive through PAM to be flagged, got %+v", flags)
+	}
+}
+
+func TestParseFail2ban(t *testing.T) {
+	status := "Status\n|- Number of jail:\t2\n`- Jail list:\tsshd, recidive\n"
+	if jails := fail2banJailList(status); len(jails) != 2 || jails[0] != "sshd" || jails[1] != "recidive" {
+		t.Errorf("Unexpected jail list: %v", jails)
+	}
+
+	jail := parseFail2banJail("sshd", "Status for the jail: sshd\n|- Filter\n|  |- Currently failed:\t3\n|  |- Total failed:\t120\n"+
+		"|  `- Journal matches:\t_SYSTEMD_UNIT=sshd.service + _COMM=sshd\n`- Actions\n   |- Currently banned:\t2\n"+
+		"   |- Total banned:\t15\n   `- Banned IP list:\t203.0.113.7 203.0.113.9\n")
+	if jail.CurrentlyFailed != 3 || jail.TotalFailed != 120 || jail.CurrentlyBanned != 2 || jail.TotalBanned != 15 || len(jail.BannedIPs) != 2 {
+		t.Errorf("Unexpected jail: %+v", jail)
+	}
+}
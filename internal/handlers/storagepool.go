@@ -0,0 +1,500 @@
+package handlers
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+// Pool types
+const (
+	poolZFS   = "zfs"
+	poolBtrfs = "btrfs"
+)
+
+// poolErrors is the health of a pool that is online but has recorded I/O or checksum errors
+const poolErrors = "errors"
+
+// zpoolCapacityWarn is the allocation above which ZFS slows down and fragments
+const zpoolCapacityWarn = 80.0
+
+// zpoolHealthy are the pool and vdev states that need no attention
+var zpoolHealthy = map[string]bool{"ONLINE": true, "HEALTHY": true, "AVAIL": true, "INUSE": true}
+
+// btrfsStatsRe matches a `btrfs device stats` line, e.g. [/dev/sda1].write_io_errs    0
+var btrfsStatsRe = regexp.MustCompile(`^\[(.+)\]\.(\w+)\s+(\d+)$`)
+
+// btrfsPercentRe matches the completion of a running scrub, e.g. 512.00GiB (41.27%)
+var btrfsPercentRe = regexp.MustCompile(`\(([\d.]+)%\)`)
+
+// btrfsErrorRe matches one error count of a scrub error summary, e.g. csum=3
+var btrfsErrorRe = regexp.MustCompile(`(\w+)=(\d+)`)
+
+// poolCapacity is the space of a pool or filesystem
+type poolCapacity struct {
+	SizeBytes   uint64  `json:"size_bytes"`
+	UsedBytes   uint64  `json:"used_bytes"`
+	UsedPercent float64 `json:"used_percent"`
+}
+
+// poolScrub is the last or running scrub (or ZFS resilver) of a pool
+type poolScrub struct {
+	Function      string  `json:"function,omitempty"`
+	State         string  `json:"state"`
+	Percent       float64 `json:"percent,omitempty"`
+	Started       string  `json:"started,omitempty"`
+	Ended         string  `json:"ended,omitempty"`
+	Duration      string  `json:"duration,omitempty"`
+	TimeLeft      string  `json:"time_left,omitempty"`
+	Errors        uint64  `json:"errors"`
+	Uncorrectable uint64  `json:"uncorrectable,omitempty"`
+}
+
+// poolDevice is a member device of a pool with its error counters
+type poolDevice struct {
+	Name             string `json:"name"`
+	Type             string `json:"type,omitempty"`
+	State            string `json:"state,omitempty"`
+	ReadErrors       uint64 `json:"read_errors"`
+	WriteErrors      uint64 `json:"write_errors"`
+	ChecksumErrors   uint64 `json:"checksum_errors"`
+	FlushErrors      uint64 `json:"flush_errors,omitempty"`
+	GenerationErrors uint64 `json:"generation_errors,omitempty"`
+}
+
+// errorCount is the sum of a device's error counters
+func (d poolDevice) errorCount() uint64 {
+	return d.ReadErrors + d.WriteErrors + d.ChecksumErrors + d.FlushErrors + d.GenerationErrors
+}
+
+// storagePool is a ZFS pool or a Btrfs filesystem
+type storagePool struct {
+	Type       string        `json:"type"`
+	Name       string        `json:"name"`
+	Health     string        `json:"health"`
+	State      string        `json:"state,omitempty"`
+	MountPoint string        `json:"mount_point,omitempty"`
+	Device     string        `json:"device,omitempty"`
+	Message    string        `json:"message,omitempty"`
+	Action     string        `json:"action,omitempty"`
+	Capacity   *poolCapacity `json:"capacity,omitempty"`
+	DataErrors uint64        `json:"data_errors,omitempty"`
+	Devices    []poolDevice  `json:"devices"`
+	Scrub      *poolScrub    `json:"scrub,omitempty"`
+}
+
+// HandleGetStoragePoolStatus reports ZFS pool and Btrfs filesystem health: device errors,
+// degraded members, scrub or resilver progress, and capacity
+func (h *HandlerManager) HandleGetStoragePoolStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var only string
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if p, ok := args["pool"].(string); ok {
+			only = strings.TrimSpace(p)
+		}
+	}
+
+	result := map[string]interface{}{}
+	var pools []storagePool
+	if h.caps.Zpool {
+		// -j needs OpenZFS 2.3 or later
+		out, err := h.privilegedCommand(ctx, "zpool", "status", "-j", "--json-int").Output()
+		if err == nil {
+			pools, err = parseZpoolStatus(out)
+		}
+		if err != nil {
+			result["zfs_error"] = fmt.Sprintf("zpool status -j failed: %v (OpenZFS 2.3 or later is required)", err)
+		}
+	}
+	if h.caps.Btrfs {
+		btrfs, err := h.collectBtrfs(ctx)
+		if err != nil {
+			result["btrfs_error"] = err.Error()
+		}
+		pools = append(pools, btrfs...)
+	}
+
+	if only != "" {
+		var names []string
+		filtered := pools[:0]
+		for _, p := range pools {
+			names = append(names, p.Name)
+			if p.Name == only || (p.MountPoint != "" && p.MountPoint == only) {
+				filtered = append(filtered, p)
+			}
+		}
+		if len(filtered) == 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("No ZFS pool or Btrfs filesystem named %q (found: %s)", only, strings.Join(names, ", "))), nil
+		}
+		pools = filtered
+	}
+
+	status := statusHealthy
+	warnings := []string{}
+	for _, p := range pools {
+		warnings = append(warnings, poolWarnings(p)...)
+		switch {
+		case p.Health == raidDegraded || p.Health == raidFailed:
+			status = statusCritical
+		case p.Health != raidOK || (p.Capacity != nil && p.Type == poolZFS && p.Capacity.UsedPercent >= zpoolCapacityWarn):
+			if status != statusCritical {
+				status = statusWarning
+			}
+		}
+	}
+	if pools == nil {
+		pools = []storagePool{}
+	}
+	result["status"] = status
+	result["pools"] = pools
+	result["pool_count"] = len(pools)
+	result["warnings"] = warnings
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// zpoolValue is a `zpool status -j` field, which is a string or, with --json-int, a number
+type zpoolValue string
+
+// UnmarshalJSON accepts a string or a number
+func (v *zpoolValue) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*v = zpoolValue(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*v = zpoolValue(n)
+	return nil
+}
+
+// uint returns the value as a count, or 0 when it is not one (e.g. "-")
+func (v zpoolValue) uint() uint64 {
+	n, _ := strconv.ParseUint(string(v), 10, 64)
+	return n
+}
+
+// zpoolVdev is a vdev in `zpool status -j` output
+type zpoolVdev struct {
+	Name           string               `json:"name"`
+	VdevType       string               `json:"vdev_type"`
+	State          string               `json:"state"`
+	AllocSpace     zpoolValue           `json:"alloc_space"`
+	TotalSpace     zpoolValue           `json:"total_space"`
+	ReadErrors     zpoolValue           `json:"read_errors"`
+	WriteErrors    zpoolValue           `json:"write_errors"`
+	ChecksumErrors zpoolValue           `json:"checksum_errors"`
+	Vdevs          map[string]zpoolVdev `json:"vdevs"`
+}
+
+// zpoolStatus is the part of `zpool status -j --json-int` output the tool reads
+type zpoolStatus struct {
+	Pools map[string]struct {
+		Name       string               `json:"name"`
+		State      string               `json:"state"`
+		Status     string               `json:"status"`
+		Action     string               `json:"action"`
+		ErrorCount zpoolValue           `json:"error_count"`
+		Vdevs      map[string]zpoolVdev `json:"vdevs"`
+		Logs       map[string]zpoolVdev `json:"logs"`
+		L2Cache    map[string]zpoolVdev `json:"l2cache"`
+		Spares     map[string]zpoolVdev `json:"spares"`
+		ScanStats  *struct {
+			Function  string     `json:"function"`
+			State     string     `json:"state"`
+			StartTime zpoolValue `json:"start_time"`
+			EndTime   zpoolValue `json:"end_time"`
+			ToExamine zpoolValue `json:"to_examine"`
+			Examined  zpoolValue `json:"examined"`
+			Issued    zpoolValue `json:"issued"`
+			Errors    zpoolValue `json:"errors"`
+		} `json:"scan_stats"`
+	} `json:"pools"`
+}
+
+// parseZpoolStatus parses `zpool status -j --json-int` output into one entry per pool
+func parseZpoolStatus(data []byte) ([]storagePool, error) {
+	var status zpoolStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return nil, err
+	}
+	pools := make([]storagePool, 0, len(status.Pools))
+	for name, p := range status.Pools {
+		pool := storagePool{
+			Type:       poolZFS,
+			Name:       name,
+			State:      p.State,
+			Message:    strings.TrimSpace(p.Status),
+			Action:     strings.TrimSpace(p.Action),
+			DataErrors: p.ErrorCount.uint(),
+			Devices:    []poolDevice{},
+		}
+		if root, ok := p.Vdevs[name]; ok {
+			if total := root.TotalSpace.uint(); total > 0 {
+				used := root.AllocSpace.uint()
+				pool.Capacity = &poolCapacity{SizeBytes: total, UsedBytes: used, UsedPercent: round2(float64(used) / float64(total) * 100)}
+			}
+			pool.Devices = appendZpoolDevices(pool.Devices, root.Vdevs)
+		}
+		for _, group := range []map[string]zpoolVdev{p.Logs, p.L2Cache, p.Spares} {
+			pool.Devices = appendZpoolDevices(pool.Devices, group)
+		}
+		if s := p.ScanStats; s != nil && s.Function != "" {
+			scrub := &poolScrub{
+				Function: strings.ToLower(s.Function),
+				State:    strings.ToLower(s.State),
+				Started:  string(s.StartTime),
+				Errors:   s.Errors.uint(),
+			}
+			if scrub.State == "scanning" {
+				// zpool status reports progress by bytes issued; examined runs ahead of it
+				done := cmp.Or(s.Issued.uint(), s.Examined.uint())
+				if total := s.ToExamine.uint(); total > 0 {
+					scrub.Percent = round2(float64(done) / float64(total) * 100)
+				}
+			} else {
+				scrub.Ended = string(s.EndTime)
+			}
+			pool.Scrub = scrub
+		}
+		pool.Health = zpoolHealth(pool)
+		pools = append(pools, pool)
+	}
+	sort.Slice(pools, func(i, j int) bool { return pools[i].Name < pools[j].Name })
+	return pools, nil
+}
+
+// appendZpoolDevices flattens a vdev tree into its top-level vdevs and leaf devices,
+// sorted by name within each level
+func appendZpoolDevices(devices []poolDevice, vdevs map[string]zpoolVdev) []poolDevice {
+	names := make([]string, 0, len(vdevs))
+	for name := range vdevs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		v := vdevs[name]
+		devices = append(devices, poolDevice{
+			Name:           name,
+			Type:           v.VdevType,
+			State:          v.State,
+			ReadErrors:     v.ReadErrors.uint(),
+			WriteErrors:    v.WriteErrors.uint(),
+			ChecksumErrors: v.ChecksumErrors.uint(),
+		})
+		devices = appendZpoolDevices(devices, v.Vdevs)
+	}
+	return devices
+}
+
+// zpoolHealth summarizes a ZFS pool: failed when it is faulted, unavailable, or suspended,
+// rebuilding while a degraded pool resilvers
+func zpoolHealth(p storagePool) string {
+	switch p.State {
+	case "ONLINE":
+	case "DEGRADED":
+		if p.Scrub != nil && p.Scrub.Function == "resilver" && p.Scrub.State == "scanning" {
+			return raidRebuilding
+		}
+		return raidDegraded
+	default:
+		return raidFailed
+	}
+	if p.DataErrors > 0 {
+		return poolErrors
+	}
+	for _, d := range p.Devices {
+		if d.errorCount() > 0 || !zpoolHealthy[d.State] {
+			return poolErrors
+		}
+	}
+	return raidOK
+}
+
+// collectBtrfs reports each mounted Btrfs filesystem once, at its first mount point
+func (h *HandlerManager) collectBtrfs(ctx context.Context) ([]storagePool, error) {
+	mounts, err := readMountTable(mountsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", mountsPath, err)
+	}
+	var pools []storagePool
+	var failures []string
+	seen := map[string]bool{}
+	for _, m := range mounts {
+		if m.Fstype != poolBtrfs || seen[m.Device] {
+			continue
+		}
+		// Subvolumes of one filesystem share its device
+		seen[m.Device] = true
+		pool := storagePool{Type: poolBtrfs, Name: m.MountPoint, MountPoint: m.MountPoint, Device: m.Device, Devices: []poolDevice{}}
+		if contains(m.Options, "degraded") {
+			pool.State = "degraded"
+		}
+		if usage, err := disk.UsageWithContext(ctx, m.MountPoint); err == nil {
+			pool.Capacity = &poolCapacity{SizeBytes: usage.Total, UsedBytes: usage.Used, UsedPercent: round2(usage.UsedPercent)}
+		}
+		// Both subcommands use ioctls that need CAP_SYS_ADMIN
+		out, err := h.privilegedCommand(ctx, "btrfs", "device", "stats", m.MountPoint).Output()
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("btrfs device stats %s: %v", m.MountPoint, err))
+		} else {
+			pool.Devices = parseBtrfsDeviceStats(string(out))
+		}
+		if out, err := h.privilegedCommand(ctx, "btrfs", "scrub", "status", m.MountPoint).Output(); err == nil {
+			pool.Scrub = parseBtrfsScrubStatus(string(out))
+		}
+		pool.Health = btrfsHealth(pool)
+		pools = append(pools, pool)
+	}
+	if len(failures) > 0 {
+		return pools, fmt.Errorf("%s (run as root or add btrfs to --sudo-allowlist)", strings.Join(failures, "; "))
+	}
+	return pools, nil
+}
+
+// parseBtrfsDeviceStats parses `btrfs device stats` output into one entry per device
+func parseBtrfsDeviceStats(output string) []poolDevice {
+	devices := []poolDevice{}
+	index := map[string]int{}
+	for _, line := range strings.Split(output, "\n") {
+		m := btrfsStatsRe.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		i, ok := index[m[1]]
+		if !ok {
+			i = len(devices)
+			index[m[1]] = i
+			devices = append(devices, poolDevice{Name: m[1]})
+		}
+		n, _ := strconv.ParseUint(m[3], 10, 64)
+		d := &devices[i]
+		switch m[2] {
+		case "write_io_errs":
+			d.WriteErrors = n
+		case "read_io_errs":
+			d.ReadErrors = n
+		case "flush_io_errs":
+			d.FlushErrors = n
+		case "corruption_errs":
+			d.ChecksumErrors = n
+		case "generation_errs":
+			d.GenerationErrors = n
+		}
+	}
+	return devices
+}
+
+// parseBtrfsScrubStatus parses `btrfs scrub status` output (btrfs-progs 5.x and later)
+func parseBtrfsScrubStatus(output string) *poolScrub {
+	if strings.Contains(output, "no stats available") {
+		return &poolScrub{State: "never"}
+	}
+	scrub := &poolScrub{Function: "scrub"}
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "Scrub started", "Scrub resumed":
+			scrub.Started = value
+		case "Status":
+			scrub.State = value
+		case "Duration":
+			scrub.Duration = value
+		case "Time left":
+			scrub.TimeLeft = value
+		case "Bytes scrubbed":
+			if m := btrfsPercentRe.FindStringSubmatch(value); m != nil {
+				scrub.Percent, _ = strconv.ParseFloat(m[1], 64)
+			}
+		case "Error summary":
+			for _, m := range btrfsErrorRe.FindAllStringSubmatch(value, -1) {
+				n, _ := strconv.ParseUint(m[2], 10, 64)
+				scrub.Errors += n
+			}
+		case "Uncorrectable":
+			scrub.Uncorrectable, _ = strconv.ParseUint(value, 10, 64)
+		}
+	}
+	if scrub.State == "" {
+		return nil
+	}
+	return scrub
+}
+
+// btrfsHealth summarizes a Btrfs filesystem: degraded when mounted without all its
+// devices, failed when a scrub found damage it could not repair
+func btrfsHealth(p storagePool) string {
+	switch {
+	case p.State == "degraded":
+		return raidDegraded
+	case p.Scrub != nil && p.Scrub.Uncorrectable > 0:
+		return raidFailed
+	case p.Scrub != nil && p.Scrub.Errors > 0:
+		return poolErrors
+	}
+	for _, d := range p.Devices {
+		if d.errorCount() > 0 {
+			return poolErrors
+		}
+	}
+	return raidOK
+}
+
+// poolWarnings describes what needs attention on a pool
+func poolWarnings(p storagePool) []string {
+	var warnings []string
+	switch p.Health {
+	case raidFailed:
+		if p.Type == poolBtrfs {
+			warnings = append(warnings, fmt.Sprintf("%s has %d uncorrectable errors from the last scrub; restore the affected files from backup", p.Name, p.Scrub.Uncorrectable))
+		} else {
+			warnings = append(warnings, fmt.Sprintf("ZFS pool %s is %s", p.Name, p.State))
+		}
+	case raidDegraded:
+		if p.Type == poolBtrfs {
+			warnings = append(warnings, fmt.Sprintf("Btrfs filesystem %s is mounted degraded; a device is missing", p.Name))
+		} else {
+			warnings = append(warnings, fmt.Sprintf("ZFS pool %s is degraded", p.Name))
+		}
+	case raidRebuilding:
+		warnings = append(warnings, fmt.Sprintf("ZFS pool %s is resilvering: %.1f%% done", p.Name, p.Scrub.Percent))
+	}
+	// Mirror and raidz vdevs take their state from their devices, so only leaves are named
+	for _, d := range p.Devices {
+		if n := d.errorCount(); n > 0 {
+			warnings = append(warnings, fmt.Sprintf("%s device %s has %d I/O or checksum errors", p.Name, d.Name, n))
+		} else if p.Type == poolZFS && (d.Type == "disk" || d.Type == "file") && !zpoolHealthy[d.State] {
+			warnings = append(warnings, fmt.Sprintf("%s device %s is %s", p.Name, d.Name, d.State))
+		}
+	}
+	if p.DataErrors > 0 {
+		warnings = append(warnings, fmt.Sprintf("ZFS pool %s has %d files with permanent errors (zpool status -v lists them)", p.Name, p.DataErrors))
+	}
+	if p.Scrub != nil && p.Scrub.Errors > 0 && p.Health != raidFailed {
+		warnings = append(warnings, fmt.Sprintf("%s: the last %s found %d errors", p.Name, p.Scrub.Function, p.Scrub.Errors))
+	}
+	if p.Type == poolZFS && p.Capacity != nil && p.Capacity.UsedPercent >= zpoolCapacityWarn {
+		warnings = append(warnings, fmt.Sprintf("ZFS pool %s is %.1f%% full; performance drops above %.0f%%", p.Name, p.Capacity.UsedPercent, zpoolCapacityWarn))
+	}
+	return warnings
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const testZpoolStatus = `{
+  "output_version": {"command": "zpool status", "vers_major": 0, "vers_minor": 1},
+  "pools": {
+    "tank": {
+      "name": "tank",
+      "state": "DEGRADED",
+      "pool_guid": 3920273586464696295,
+      "status": "One or more devices could not be used because the label is missing or invalid.",
+      "action": "Replace the device using 'zpool replace'.",
+      "vdevs": {
+        "tank": {
+          "name": "tank",
+          "alloc_space": 900,
+          "total_space": 1000,
+          "read_errors": 0,
+          "write_errors": 0,
+          "checksum_errors": 0,
+          "vdevs": {
+            "raidz1-0": {
+              "name": "raidz1-0",
+              "vdev_type": "raidz",
+              "state": "DEGRADED",
+              "read_errors": 0,
+              "write_errors": 0,
+              "checksum_errors": 0,
+              "vdevs": {
+                "sda": {"name": "sda", "vdev_type": "disk", "state": "ONLINE", "read_errors": 0, "write_errors": 0, "checksum_errors": 2},
+                "sdb": {"name": "sdb", "vdev_type": "disk", "state": "UNAVAIL", "read_errors": 0, "write_errors": 0, "checksum_errors": 0}
+              }
+            }
+          }
+        }
+      },
+      "error_count": "0",
+      "scan_stats": {
+        "function": "RESILVER",
+        "state": "SCANNING",
+        "start_time": "Sat Oct 10 03:00:01 2026",
+        "to_examine": 1000,
+        "examined": 600,
+        "issued": 250,
+        "errors": 0
+      }
+    },
+    "boot": {
+      "name": "boot",
+      "state": "ONLINE",
+      "vdevs": {
+        "boot": {"name": "boot", "alloc_space": "10", "total_space": "100", "vdevs": {
+          "nvme0n1p2": {"name": "nvme0n1p2", "vdev_type": "disk", "state": "ONLINE", "read_errors": "0", "write_errors": "0", "checksum_errors": "0"}
+        }}
+      },
+      "error_count": "0",
+      "scan_stats": {
+        "function": "SCRUB",
+        "state": "FINISHED",
+        "start_time": "Sun Oct 11 00:24:01 2026",
+        "end_time": "Sun Oct 11 00:25:13 2026",
+        "errors": "0"
+      }
+    }
+  }
+}`
+
+func TestParseZpoolStatus(t *testing.T) {
+	pools, err := parseZpoolStatus([]byte(testZpoolStatus))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pools) != 2 || pools[0].Name != "boot" || pools[1].Name != "tank" {
+		t.Fatalf("Expected boot and tank, got %+v", pools)
+	}
+
+	boot := pools[0]
+	if boot.Health != raidOK || boot.Capacity == nil || boot.Capacity.UsedPercent != 10 || len(boot.Devices) != 1 {
+		t.Errorf("Unexpected boot pool: %+v", boot)
+	}
+	if s := boot.Scrub; s == nil || s.Function != "scrub" || s.State != "finished" || s.Ended != "Sun Oct 11 00:25:13 2026" {
+		t.Errorf("Unexpected boot scrub: %+v", boot.Scrub)
+	}
+
+	tank := pools[1]
+	if tank.Health != raidRebuilding || tank.Message == "" || tank.Capacity.UsedBytes != 900 {
+		t.Errorf("Unexpected tank pool: %+v", tank)
+	}
+	var names []string
+	for _, d := range tank.Devices {
+		names = append(names, d.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"raidz1-0", "sda", "sdb"}) || tank.Devices[1].ChecksumErrors != 2 {
+		t.Errorf("Unexpected tank devices: %+v", tank.Devices)
+	}
+	if s := tank.Scrub; s == nil || s.Function != "resilver" || s.Percent != 25 || s.Ended != "" {
+		t.Errorf("Unexpected tank resilver: %+v", tank.Scrub)
+	}
+
+	warnings := poolWarnings(tank)
+	if len(warnings) != 4 {
+		t.Errorf("Expected resilver, checksum, unavailable device, and capacity warnings, got %v", warnings)
+	}
+
+	if _, err := parseZpoolStatus([]byte("not json")); err == nil {
+		t.Error("Expected an error for invalid output")
+	}
+}
+
+func TestParseBtrfsDeviceStats(t *testing.T) {
+	out := `[/dev/sda1].write_io_errs    0
+[/dev/sda1].read_io_errs     3
+[/dev/sda1].flush_io_errs    0
+[/dev/sda1].corruption_errs  1
+[/dev/sda1].generation_errs  0
+[/dev/sdb1].write_io_errs    0
+[/dev/sdb1].read_io_errs     0
+[/dev/sdb1].flush_io_errs    0
+[/dev/sdb1].corruption_errs  0
+[/dev/sdb1].generation_errs  0
+`
+	want := []poolDevice{
+		{Name: "/dev/sda1", ReadErrors: 3, ChecksumErrors: 1},
+		{Name: "/dev/sdb1"},
+	}
+	if got := parseBtrfsDeviceStats(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseBtrfsDeviceStats = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseBtrfsScrubStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   *poolScrub
+	}{
+		{
+			name: "running",
+			output: `UUID:             3c9e2f1a-6b0d-4f5e-9a8c-7d1e2f3a4b5c
+Scrub resumed:    Sat Oct 10 03:00:01 2026
+Status:           running
+Duration:         0:41:12
+Time left:        0:58:48
+ETA:              Sat Oct 10 04:40:01 2026
+Total to scrub:   1.24TiB
+Bytes scrubbed:   512.00GiB  (41.27%)
+Rate:             212.13MiB/s
+Error summary:    no errors found
+`,
+			want: &poolScrub{Function: "scrub", State: "running", Percent: 41.27, Started: "Sat Oct 10 03:00:01 2026", Duration: "0:41:12", TimeLeft: "0:58:48"},
+		},
+		{
+			name: "finished with errors",
+			output: `UUID:             3c9e2f1a-6b0d-4f5e-9a8c-7d1e2f3a4b5c
+Scrub started:    Sun Oct  4 03:00:01 2026
+Status:           finished
+Duration:         1:39:50
+Total to scrub:   1.24TiB
+Rate:             216.48MiB/s
+Error summary:    read=2 csum=3
+  Corrected:      4
+  Uncorrectable:  1
+  Unverified:     0
+`,
+			want: &poolScrub{Function: "scrub", State: "finished", Started: "Sun Oct  4 03:00:01 2026", Duration: "1:39:50", Errors: 5, Uncorrectable: 1},
+		},
+		{
+			name:   "never run",
+			output: "UUID:             3c9e2f1a-6b0d-4f5e-9a8c-7d1e2f3a4b5c\n\tno stats available\n",
+			want:   &poolScrub{State: "never"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseBtrfsScrubStatus(tt.output); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseBtrfsScrubStatus = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBtrfsHealth(t *testing.T) {
+	clean := storagePool{Devices: []poolDevice{{Name: "/dev/sda1"}}}
+	if got := btrfsHealth(clean); got != raidOK {
+		t.Errorf("Expected ok, got %s", got)
+	}
+	if got := btrfsHealth(storagePool{State: "degraded"}); got != raidDegraded {
+		t.Errorf("Expected degraded, got %s", got)
+	}
+	if got := btrfsHealth(storagePool{Devices: []poolDevice{{Name: "/dev/sda1", FlushErrors: 1}}}); got != poolErrors {
+		t.Errorf("Expected errors, got %s", got)
+	}
+	if got := btrfsHealth(storagePool{Scrub: &poolScrub{Errors: 2, Uncorrectable: 1}}); got != raidFailed {
+		t.Errorf("Expected failed, got %s", got)
+	}
+}
+
+func TestHandleGetStoragePoolStatus(t *testing.T) {
+	h := NewHandlerManager(&config.Config{})
+	h.caps.Zpool, h.caps.Btrfs = false, false
+
+	res, err := h.HandleGetStoragePoolStatus(context.Background(), mcp.CallToolRequest{})
+	checkToolResult(t, res, err, []string{"status", "pools", "pool_count", "warnings"})
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"pool": "tank"}
+	if res, err := h.HandleGetStoragePoolStatus(context.Background(), req); err != nil || !res.IsError {
+		t.Error("Expected an error for an unknown pool")
+	}
+}
@@ -0,0 +1,335 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"sysmetrics-mcp/internal/config"
+	"sysmetrics-mcp/internal/history"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Synthetic check metrics; each series carries check and type labels
+const (
+	syntheticUpMetric      = "synthetic_up"
+	syntheticLatencyMetric = "synthetic_latency_ms"
+)
+
+// defaultSyntheticRange is how far back get_synthetic_results lists outages
+const defaultSyntheticRange = 24 * time.Hour
+
+// maxSyntheticIncidents bounds the outages listed per check, newest kept
+const maxSyntheticIncidents = 20
+
+// syntheticWindows are the uptime percentages reported per check
+var syntheticWindows = []struct {
+	label  string
+	period time.Duration
+}{
+	{"24h", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+	{"30d", 30 * 24 * time.Hour},
+}
+
+// syntheticOutcome is the result of one run of a synthetic check
+type syntheticOutcome struct {
+	Time       time.Time `json:"time"`
+	Up         bool      `json:"up"`
+	LatencyMs  float64   `json:"latency_ms,omitempty"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// syntheticState is what the scheduler remembers about a check between runs
+type syntheticState struct {
+	last  syntheticOutcome
+	since time.Time
+}
+
+// syntheticIncident is a run of failed checks
+type syntheticIncident struct {
+	Start           time.Time  `json:"start"`
+	End             *time.Time `json:"end,omitempty"`
+	DurationSeconds int64      `json:"duration_seconds"`
+	Ongoing         bool       `json:"ongoing,omitempty"`
+}
+
+// syntheticLabels are the history labels of a check's series
+func syntheticLabels(check config.SyntheticCheck) map[string]string {
+	return map[string]string{"check": check.Name(), "type": check.Type}
+}
+
+// RunSyntheticChecks runs each --synthetic-checks probe on its interval and stores the
+// results through the sample writers until ctx is cancelled. It returns immediately when
+// no checks are configured or history is disabled.
+func (h *HandlerManager) RunSyntheticChecks(ctx context.Context) {
+	if len(h.cfg.SyntheticChecks) == 0 || h.history == nil {
+		return
+	}
+	var wg sync.WaitGroup
+	for _, check := range h.cfg.SyntheticChecks {
+		wg.Go(func() {
+			ticker := time.NewTicker(check.Interval)
+			defer ticker.Stop()
+			for {
+				h.recordSynthetic(ctx, check, runSyntheticCheck(ctx, check))
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		})
+	}
+	wg.Wait()
+}
+
+// runSyntheticCheck probes a check's target once with the same probes as
+// check_http_endpoints and check_connectivity
+func runSyntheticCheck(ctx context.Context, check config.SyntheticCheck) syntheticOutcome {
+	out := syntheticOutcome{Time: time.Now()}
+	var res probeResult
+	switch check.Type {
+	case config.SyntheticHTTP:
+		r := checkHTTPEndpoint(ctx, check.Target, httpCheckOptions{
+			method:          http.MethodGet,
+			timeout:         time.Duration(defaultHTTPTimeoutMs) * time.Millisecond,
+			followRedirects: true,
+		})
+		out.Up, out.LatencyMs, out.StatusCode, out.Error = r.Success, r.LatencyMs, r.StatusCode, r.Error
+		if !r.Success && r.Error == "" {
+			out.Error = fmt.Sprintf("HTTP status %d", r.StatusCode)
+		}
+		return out
+	case config.SyntheticTCP:
+		res = probeHost(ctx, check.Target, probeTCP, defaultProbeTimeoutMs*time.Millisecond)
+	case config.SyntheticPing:
+		res = probeHost(ctx, check.Target, probeICMP, defaultProbeTimeoutMs*time.Millisecond)
+	case config.SyntheticDNS:
+		res = probeDNS(ctx, check.Target, defaultProbeTimeoutMs*time.Millisecond)
+	}
+	out.Up, out.LatencyMs, out.Error = res.Success, res.LatencyMs, res.Error
+	return out
+}
+
+// recordSynthetic writes a check's outcome to history (and the exporter, when configured),
+// then remembers it and logs and journals state changes
+func (h *HandlerManager) recordSynthetic(ctx context.Context, check config.SyntheticCheck, out syntheticOutcome) {
+	if ctx.Err() != nil {
+		// Probes cut short by shutdown are not failures
+		return
+	}
+	labels := syntheticLabels(check)
+	up := 0.0
+	samples := []history.Sample{}
+	if out.Up {
+		up = 1
+		samples = append(samples, history.Sample{Time: out.Time, Metric: syntheticLatencyMetric, Labels: labels, Value: out.LatencyMs})
+	}
+	samples = append(samples, history.Sample{Time: out.Time, Metric: syntheticUpMetric, Labels: labels, Value: up})
+	h.writeSamples(ctx, samples)
+
+	name := check.Name()
+	changed := false
+	h.syntheticMu.Lock()
+	if h.synthetic == nil {
+		h.synthetic = map[string]*syntheticState{}
+	}
+	state, seen := h.synthetic[name]
+	switch {
+	case !seen:
+		state = &syntheticState{since: out.Time}
+		h.synthetic[name] = state
+	case state.last.Up != out.Up:
+		state.since = out.Time
+		if out.Up {
+			h.logger.Info("synthetic check recovered", "check", name)
+		} else {
+			h.logger.Warn("synthetic check failing", "check", name, "error", out.Error)
+		}
+		changed = true
+	}
+	state.last = out
+	h.syntheticMu.Unlock()
+
+	if changed {
+		e := history.Event{Time: out.Time, Category: history.EventSynthetic, Severity: history.SeverityInfo, Subject: name, Message: name + " recovered"}
+		if !out.Up {
+			e.Severity, e.Message = history.SeverityWarning, name+" is failing"
+			e.Details = map[string]string{"error": out.Error}
+		}
+		h.recordEvent(ctx, e)
+	}
+}
+
+// HandleGetSyntheticResults reports each synthetic check's latest result, uptime over the
+// last day, week, and month, average latency, and recent outages
+func (h *HandlerManager) HandleGetSyntheticResults(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if h.history == nil {
+		return mcp.NewToolResultError("Metrics history is disabled (start the server with --history-db)"), nil
+	}
+	var only string
+	rangeDur := defaultSyntheticRange
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if c, ok := args["check"].(string); ok {
+			only = strings.TrimSpace(c)
+		}
+		if r, ok := args["range"].(string); ok && r != "" {
+			d, err := parseHistoryDuration(r)
+			if err != nil || d <= 0 {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid range: %q (use a duration such as 6h, 24h, or 7d)", r)), nil
+			}
+			rangeDur = d
+		}
+	}
+
+	now := time.Now()
+	checks := []map[string]interface{}{}
+	up, down := 0, 0
+	for _, check := range h.cfg.SyntheticChecks {
+		name := check.Name()
+		if only != "" && name != only && check.Target != only {
+			continue
+		}
+		entry, err := h.syntheticResult(ctx, check, now, rangeDur)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to read synthetic results: %v", err)), nil
+		}
+		switch entry["state"] {
+		case "up":
+			up++
+		case "down":
+			down++
+		}
+		checks = append(checks, entry)
+	}
+	if only != "" && len(checks) == 0 {
+		names := make([]string, 0, len(h.cfg.SyntheticChecks))
+		for _, check := range h.cfg.SyntheticChecks {
+			names = append(names, check.Name())
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("No synthetic check %q (configured: %s)", only, strings.Join(names, ", "))), nil
+	}
+
+	jsonBytes, err := json.Marshal(map[string]interface{}{
+		"checks":      checks,
+		"checks_up":   up,
+		"checks_down": down,
+		"range":       rangeDur.String(),
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// syntheticResult assembles one check's entry from the scheduler state and history
+func (h *HandlerManager) syntheticResult(ctx context.Context, check config.SyntheticCheck, now time.Time, rangeDur time.Duration) (map[string]interface{}, error) {
+	labels := history.FormatLabels(syntheticLabels(check))
+	entry := map[string]interface{}{
+		"check":    check.Name(),
+		"type":     check.Type,
+		"target":   check.Target,
+		"interval": check.Interval.String(),
+		"state":    "pending",
+	}
+
+	h.syntheticMu.Lock()
+	if state, ok := h.synthetic[check.Name()]; ok {
+		entry["last"] = state.last
+		entry["state"] = "down"
+		if state.last.Up {
+			entry["state"] = "up"
+		}
+		entry["since"] = state.since.UTC()
+		entry["for"] = h.human.Duration(now.Sub(state.since).Truncate(time.Second))
+	}
+	h.syntheticMu.Unlock()
+
+	uptime := map[string]interface{}{}
+	for _, w := range syntheticWindows {
+		avg, count, err := h.syntheticAverage(ctx, syntheticUpMetric, labels, now.Add(-w.period), now)
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			uptime[w.label] = round2(avg * 100)
+		}
+	}
+	entry["uptime_percent"] = uptime
+
+	latency, count, err := h.syntheticAverage(ctx, syntheticLatencyMetric, labels, now.Add(-defaultSyntheticRange), now)
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		entry["avg_latency_ms_24h"] = round2(latency)
+	}
+
+	raw, err := h.history.Raw(ctx, syntheticUpMetric, now.Add(-rangeDur), now)
+	if err != nil {
+		return nil, err
+	}
+	incidents := []syntheticIncident{}
+	for _, series := range raw {
+		if series.Labels == labels {
+			incidents = syntheticIncidents(series.Points, now)
+		}
+	}
+	entry["incidents"] = incidents
+	return entry, nil
+}
+
+// syntheticAverage returns the mean and sample count of a check's series over a range,
+// across all the rollup tiers it spans
+func (h *HandlerManager) syntheticAverage(ctx context.Context, metric, labels string, since, until time.Time) (float64, int, error) {
+	series, err := h.history.Aggregate(ctx, history.Query{Metric: metric, Labels: labels, Since: since, Until: until, Bucket: until.Sub(since)})
+	if err != nil {
+		return 0, 0, err
+	}
+	sum, count := 0.0, 0
+	for _, s := range series {
+		for _, b := range s.Buckets {
+			sum += b.Avg * float64(b.Count)
+			count += b.Count
+		}
+	}
+	if count == 0 {
+		return 0, 0, nil
+	}
+	return sum / float64(count), count, nil
+}
+
+// syntheticIncidents groups consecutive failed points into outages. Points from rollups
+// count as failed when most checks in them failed.
+func syntheticIncidents(points []history.Point, now time.Time) []syntheticIncident {
+	incidents := []syntheticIncident{}
+	var cur *syntheticIncident
+	for _, p := range points {
+		failed := p.Value < 0.5
+		switch {
+		case failed && cur == nil:
+			incidents = append(incidents, syntheticIncident{Start: p.Time})
+			cur = &incidents[len(incidents)-1]
+		case !failed && cur != nil:
+			end := p.Time
+			cur.End = &end
+			cur.DurationSeconds = int64(end.Sub(cur.Start).Seconds())
+			cur = nil
+		}
+	}
+	if cur != nil {
+		cur.Ongoing = true
+		cur.DurationSeconds = int64(now.Sub(cur.Start).Seconds())
+	}
+	if len(incidents) > maxSyntheticIncidents {
+		incidents = incidents[len(incidents)-maxSyntheticIncidents:]
+	}
+	return incidents
+}
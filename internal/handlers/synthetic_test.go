@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"sysmetrics-mcp/internal/config"
+	"sysmetrics-mcp/internal/history"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestRunSyntheticChecks(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	// A port that was just closed refuses connections
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedAddr := closed.Addr().String()
+	_ = closed.Close()
+
+	h := newHistoryTestManager(t)
+	h.cfg.SyntheticChecks = []config.SyntheticCheck{
+		{Type: config.SyntheticTCP, Target: ln.Addr().String(), Interval: time.Hour},
+		{Type: config.SyntheticTCP, Target: closedAddr, Interval: time.Hour},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		h.RunSyntheticChecks(ctx)
+		close(done)
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		h.syntheticMu.Lock()
+		n := len(h.synthetic)
+		h.syntheticMu.Unlock()
+		if n == 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	res, err := h.HandleGetSyntheticResults(context.Background(), mcp.CallToolRequest{})
+	checkToolResult(t, res, err, []string{"checks", "checks_up", "checks_down", "range"})
+	var result struct {
+		Checks []struct {
+			Check         string              `json:"check"`
+			State         string              `json:"state"`
+			Last          syntheticOutcome    `json:"last"`
+			UptimePercent map[string]float64  `json:"uptime_percent"`
+			Incidents     []syntheticIncident `json:"incidents"`
+		} `json:"checks"`
+		Up   int `json:"checks_up"`
+		Down int `json:"checks_down"`
+	}
+	if err := json.Unmarshal([]byte(resultText(res)), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Up != 1 || result.Down != 1 || len(result.Checks) != 2 {
+		t.Fatalf("Expected one check up and one down, got %+v", result)
+	}
+	if c := result.Checks[0]; c.State != "up" || c.UptimePercent["24h"] != 100 || len(c.Incidents) != 0 {
+		t.Errorf("Unexpected result for the open port: %+v", c)
+	}
+	if c := result.Checks[1]; c.State != "down" || c.Last.Error == "" || c.UptimePercent["30d"] != 0 || len(c.Incidents) != 1 || !c.Incidents[0].Ongoing {
+		t.Errorf("Unexpected result for the closed port: %+v", c)
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"check": closedAddr}
+	res, err = h.HandleGetSyntheticResults(context.Background(), req)
+	checkToolResult(t, res, err, []string{"checks"})
+	if err := json.Unmarshal([]byte(resultText(res)), &result); err != nil || len(result.Checks) != 1 {
+		t.Errorf("Expected the check filtered by target, got %+v (err %v)", result.Checks, err)
+	}
+	req.Params.Arguments = map[string]interface{}{"check": "dns:nowhere"}
+	if res, err := h.HandleGetSyntheticResults(context.Background(), req); err != nil || !res.IsError {
+		t.Error("Expected an error for an unknown check")
+	}
+}
+
+func TestSyntheticIncidents(t *testing.T) {
+	base := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	at := func(min int, v float64) history.Point {
+		return history.Point{Time: base.Add(time.Duration(min) * time.Minute), Value: v}
+	}
+	points := []history.Point{at(0, 1), at(1, 0), at(2, 0), at(3, 1), at(4, 0.8), at(5, 0.2)}
+
+	incidents := syntheticIncidents(points, base.Add(10*time.Minute))
+	if len(incidents) != 2 {
+		t.Fatalf("Expected 2 incidents, got %+v", incidents)
+	}
+	if i := incidents[0]; !i.Start.Equal(base.Add(time.Minute)) || i.End == nil || i.DurationSeconds != 120 || i.Ongoing {
+		t.Errorf("Unexpected first incident: %+v", i)
+	}
+	if i := incidents[1]; i.End != nil || !i.Ongoing || i.DurationSeconds != 300 {
+		t.Errorf("Unexpected ongoing incident: %+v", i)
+	}
+}
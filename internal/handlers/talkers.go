@@ -0,0 +1,365 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	stdnet "net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/shirou/gopsutil/v3/net"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// Bandwidth sampling bounds for get_network_top_processes. Bandwidth is only sampled on
+// request, or when sorting by it.
+const (
+	defaultTalkerSampleSeconds     = 0
+	defaultTalkerSortSampleSeconds = 1
+	maxTalkerSampleSeconds         = 10
+)
+
+// ssUsersRe extracts the first owning process from an ss users:(("name",pid=N,fd=M)) column
+var ssUsersRe = regexp.MustCompile(`users:\(\("((?:[^"\\]|\\.)*)",pid=(\d+),`)
+
+// ssSocket is the per-socket byte counters reported by `ss -tinp`
+type ssSocket struct {
+	PID      int32
+	Name     string
+	Sent     uint64
+	Received uint64
+}
+
+// talkerInfo aggregates the sockets owned by a single process
+type talkerInfo struct {
+	PID         int32
+	Name        string
+	Connections int
+	Listening   int
+	States      map[string]int
+	RemoteHosts map[string]bool
+	Countries   map[string]int
+	TxRate      float64
+	RxRate      float64
+}
+
+// HandleGetNetworkTopProcesses returns per-process connection counts and estimated TCP bandwidth
+func (h *HandlerManager) HandleGetNetworkTopProcesses(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	limit := h.cfg.MaxProcesses
+	sortBy := "connections"
+	sampleSeconds := -1
+
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if l, ok := args["limit"].(float64); ok && l > 0 {
+			limit = h.cfg.ClampProcessLimit(int(l))
+		}
+		if s, ok := args["sort_by"].(string); ok && s != "" {
+			sortBy = strings.ToLower(s)
+		}
+		if s, ok := args["sample_seconds"].(float64); ok && s >= 0 {
+			sampleSeconds = int(s)
+			if sampleSeconds > maxTalkerSampleSeconds {
+				sampleSeconds = maxTalkerSampleSeconds
+			}
+		}
+	}
+
+	if sortBy != "connections" && sortBy != "bandwidth" {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid sort_by: %s (must be connections or bandwidth)", sortBy)), nil
+	}
+	if sampleSeconds < 0 {
+		sampleSeconds = defaultTalkerSampleSeconds
+		if sortBy == "bandwidth" {
+			sampleSeconds = defaultTalkerSortSampleSeconds
+		}
+	}
+
+	// Sample per-socket TCP byte counters before and after the interval
+	bandwidth := map[string]interface{}{
+		"available": false,
+	}
+	var rates map[string]ssSocket
+	switch {
+	case !h.caps.SS:
+		bandwidth["error"] = "ss (iproute2) not found; bandwidth estimates are Linux only"
+	case sampleSeconds == 0:
+		bandwidth["error"] = "not sampled; set sample_seconds or sort_by=bandwidth to estimate bandwidth"
+	default:
+		var err error
+		rates, err = h.sampleSocketRates(ctx, time.Duration(sampleSeconds)*time.Second)
+		if err != nil {
+			bandwidth["error"] = err.Error()
+		} else {
+			bandwidth["available"] = true
+			bandwidth["method"] = "ss tcp_info byte counters (TCP only)"
+			bandwidth["sample_seconds"] = sampleSeconds
+		}
+	}
+
+	connections, err := net.ConnectionsWithContext(ctx, "inet")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get network connections: %v", err)), nil
+	}
+
+	talkers, unattributed := aggregateTalkers(connections, rates, h.geoCountry)
+	sortTalkers(talkers, sortBy)
+
+	matched := len(talkers)
+	if len(talkers) > limit {
+		talkers = talkers[:limit]
+	}
+
+	rows := make([]map[string]interface{}, 0, len(talkers))
+	for _, t := range talkers {
+		name := t.Name
+		if name == "" {
+			if p, err := process.NewProcessWithContext(ctx, t.PID); err == nil {
+				name, _ = p.NameWithContext(ctx)
+			}
+		}
+		row := map[string]interface{}{
+			"pid":          t.PID,
+			"name":         name,
+			"connections":  t.Connections,
+			"listening":    t.Listening,
+			"states":       t.States,
+			"remote_hosts": len(t.RemoteHosts),
+		}
+		if len(t.Countries) > 0 {
+			row["remote_countries"] = t.Countries
+		}
+		if rates != nil {
+			row["tx_bytes_per_sec"] = t.TxRate
+			row["rx_bytes_per_sec"] = t.RxRate
+		}
+		rows = append(rows, row)
+	}
+
+	result := map[string]interface{}{
+		"processes":    rows,
+		"matched":      matched,
+		"shown":        len(rows),
+		"unattributed": unattributed,
+		"sort_by":      sortBy,
+		"bandwidth":    bandwidth,
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// geoCountry returns the country code for a remote address when GeoIP is configured
+func (h *HandlerManager) geoCountry(addr string) string {
+	info, ok := h.geo.Lookup(addr)
+	if !ok {
+		return ""
+	}
+	return info.CountryCode
+}
+
+// sampleSocketRates runs `ss -tinp` twice and returns per-socket byte rates keyed by
+// socket address pair. Sent and Received hold bytes per second.
+func (h *HandlerManager) sampleSocketRates(ctx context.Context, interval time.Duration) (map[string]ssSocket, error) {
+	first, err := h.readSSSockets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(interval):
+	}
+
+	second, err := h.readSSSockets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	elapsed := time.Since(start).Seconds()
+
+	rates := make(map[string]ssSocket, len(second))
+	for key, after := range second {
+		before, ok := first[key]
+		if !ok || after.Sent < before.Sent || after.Received < before.Received {
+			// Sockets opened during the interval have no baseline
+			continue
+		}
+		rates[key] = ssSocket{
+			PID:      after.PID,
+			Name:     after.Name,
+			Sent:     uint64(float64(after.Sent-before.Sent) / elapsed),
+			Received: uint64(float64(after.Received-before.Received) / elapsed),
+		}
+	}
+	return rates, nil
+}
+
+// readSSSockets runs `ss -tinp` and parses its output
+func (h *HandlerManager) readSSSockets(ctx context.Context) (map[string]ssSocket, error) {
+	out, err := h.privilegedCommand(ctx, "ss", "-tinpH").Output()
+	if err != nil {
+		return nil, fmt.Errorf("ss failed: %w", err)
+	}
+	return parseSSOutput(string(out)), nil
+}
+
+// parseSSOutput parses `ss -tinpH` output, where each socket line is followed by an
+// indented tcp_info line, into byte counters keyed by socketKey
+func parseSSOutput(output string) map[string]ssSocket {
+	sockets := make(map[string]ssSocket)
+	var key string
+	var current ssSocket
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" {
+			continue
+		}
+
+		// Socket line: State Recv-Q Send-Q Local:Port Peer:Port [users:(...)]
+		if line[0] != ' ' && line[0] != '\t' {
+			key = ""
+			fields := strings.Fields(line)
+			if len(fields) < 5 {
+				continue
+			}
+			key = socketKey(fields[3], fields[4])
+			current = ssSocket{}
+			if m := ssUsersRe.FindStringSubmatch(line); m != nil {
+				current.Name = m[1]
+				if pid, err := strconv.ParseInt(m[2], 10, 32); err == nil {
+					current.PID = int32(pid)
+				}
+			}
+			continue
+		}
+
+		// tcp_info line: prefer bytes_acked (excludes retransmits) over bytes_sent
+		if key == "" {
+			continue
+		}
+		var acked, sent uint64
+		for _, f := range strings.Fields(line) {
+			name, value, ok := strings.Cut(f, ":")
+			if !ok {
+				continue
+			}
+			v, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				continue
+			}
+			switch name {
+			case "bytes_acked":
+				acked = v
+			case "bytes_sent":
+				sent = v
+			case "bytes_received":
+				current.Received = v
+			}
+		}
+		current.Sent = sent
+		if acked > 0 {
+			current.Sent = acked
+		}
+		sockets[key] = current
+		key = ""
+	}
+	return sockets
+}
+
+// socketKey builds a lookup key from local and remote "host:port" strings, normalizing
+// bracketed IPv6, zone suffixes, and IPv4-mapped addresses
+func socketKey(local, remote string) string {
+	return normalizeHostPort(local) + "|" + normalizeHostPort(remote)
+}
+
+// normalizeHostPort canonicalizes an address so ss and /proc/net output compare equal
+func normalizeHostPort(addr string) string {
+	host, port, err := stdnet.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	host, _, _ = strings.Cut(host, "%")
+	if ip := stdnet.ParseIP(host); ip != nil {
+		if v4 := ip.To4(); v4 != nil {
+			host = v4.String()
+		} else {
+			host = ip.String()
+		}
+	}
+	return stdnet.JoinHostPort(host, port)
+}
+
+// aggregateTalkers groups connections by owning PID, attaching per-socket byte rates.
+// Connections whose owner could not be resolved are only counted.
+func aggregateTalkers(connections []net.ConnectionStat, rates map[string]ssSocket, country func(string) string) (talkers []*talkerInfo, unattributed int) {
+	byPID := make(map[int32]*talkerInfo)
+	for _, c := range connections {
+		local := stdnet.JoinHostPort(c.Laddr.IP, strconv.FormatUint(uint64(c.Laddr.Port), 10))
+		remote := stdnet.JoinHostPort(c.Raddr.IP, strconv.FormatUint(uint64(c.Raddr.Port), 10))
+		rate, hasRate := rates[socketKey(local, remote)]
+
+		pid := c.Pid
+		if pid == 0 && hasRate {
+			pid = rate.PID
+		}
+		if pid == 0 {
+			unattributed++
+			continue
+		}
+
+		t, ok := byPID[pid]
+		if !ok {
+			t = &talkerInfo{PID: pid, States: make(map[string]int), RemoteHosts: make(map[string]bool), Countries: make(map[string]int)}
+			byPID[pid] = t
+			talkers = append(talkers, t)
+		}
+		if t.Name == "" && hasRate {
+			t.Name = rate.Name
+		}
+
+		t.Connections++
+		status := c.Status
+		if status == "" || status == "NONE" {
+			status = "UNKNOWN"
+		}
+		t.States[status]++
+		if c.Status == "LISTEN" {
+			t.Listening++
+		}
+		if c.Raddr.IP != "" {
+			t.RemoteHosts[c.Raddr.IP] = true
+			if cc := country(c.Raddr.IP); cc != "" {
+				t.Countries[cc]++
+			}
+		}
+		if hasRate {
+			t.TxRate += float64(rate.Sent)
+			t.RxRate += float64(rate.Received)
+		}
+	}
+	return talkers, unattributed
+}
+
+// sortTalkers orders processes by connection count (default) or combined bandwidth
+func sortTalkers(talkers []*talkerInfo, sortBy string) {
+	sort.SliceStable(talkers, func(i, j int) bool {
+		if sortBy == "bandwidth" {
+			bi, bj := talkers[i].TxRate+talkers[i].RxRate, talkers[j].TxRate+talkers[j].RxRate
+			if bi != bj {
+				return bi > bj
+			}
+		}
+		if talkers[i].Connections != talkers[j].Connections {
+			return talkers[i].Connections > talkers[j].Connections
+		}
+		return talkers[i].PID < talkers[j].PID
+	})
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+func TestParseSSOutput(t *testing.T) {
+	output := "ESTAB 0      0      192.168.1.5:22 192.168.1.10:51234 users:((\"sshd\",pid=1234,fd=4))\n" +
+		"\t cubic wscale:7,7 rto:204 bytes_sent:5000 bytes_acked:4990 bytes_received:700 segs_out:10\n" +
+		"ESTAB 0      0      [::ffff:192.168.1.5]:8080 [::ffff:10.0.0.2]:40000\n" +
+		"\t cubic rto:204 bytes_sent:300 bytes_received:100\n" +
+		"ESTAB 0      0      [fe80::1%eth0]:443 [fe80::2%eth0]:50000 users:((\"caddy\",pid=99,fd=7),(\"caddy\",pid=99,fd=8))\n" +
+		"\t cubic rto:204 bytes_received:42\n"
+
+	sockets := parseSSOutput(output)
+	if len(sockets) != 3 {
+		t.Fatalf("Expected 3 sockets, got %d: %v", len(sockets), sockets)
+	}
+
+	tests := []struct {
+		local, remote string
+		expected      ssSocket
+	}{
+		{"192.168.1.5:22", "192.168.1.10:51234", ssSocket{PID: 1234, Name: "sshd", Sent: 4990, Received: 700}},
+		{"192.168.1.5:8080", "10.0.0.2:40000", ssSocket{Sent: 300, Received: 100}},
+		{"[fe80::1]:443", "[fe80::2]:50000", ssSocket{PID: 99, Name: "caddy", Received: 42}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.local, func(t *testing.T) {
+			got, ok := sockets[socketKey(tt.local, tt.remote)]
+			if !ok {
+				t.Fatalf("Socket %s -> %s not found", tt.local, tt.remote)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestAggregateTalkers(t *testing.T) {
+	conn := func(pid int32, status, lip string, lport uint32, rip string, rport uint32) net.ConnectionStat {
+		return net.ConnectionStat{
+			Pid:    pid,
+			Status: status,
+			Laddr:  net.Addr{IP: lip, Port: lport},
+			Raddr:  net.Addr{IP: rip, Port: rport},
+		}
+	}
+	connections := []net.ConnectionStat{
+		conn(10, "ESTABLISHED", "192.168.1.5", 40000, "1.1.1.1", 443),
+		conn(10, "ESTABLISHED", "192.168.1.5", 40001, "1.1.1.1", 443),
+		conn(20, "LISTEN", "0.0.0.0", 22, "", 0),
+		conn(20, "ESTABLISHED", "192.168.1.5", 22, "192.168.1.10", 51234),
+		conn(0, "ESTABLISHED", "192.168.1.5", 8080, "10.0.0.2", 40000),
+		conn(0, "TIME_WAIT", "192.168.1.5", 9000, "10.0.0.3", 1),
+	}
+	rates := map[string]ssSocket{
+		socketKey("192.168.1.5:22", "192.168.1.10:51234"): {PID: 20, Sent: 5000, Received: 100},
+		socketKey("192.168.1.5:8080", "10.0.0.2:40000"):   {PID: 30, Name: "web", Sent: 10, Received: 20},
+		socketKey("192.168.1.5:40000", "1.1.1.1:443"):     {PID: 10, Sent: 1, Received: 2},
+	}
+	country := func(addr string) string {
+		if addr == "1.1.1.1" {
+			return "AU"
+		}
+		return ""
+	}
+
+	talkers, unattributed := aggregateTalkers(connections, rates, country)
+	if unattributed != 1 {
+		t.Errorf("Expected 1 unattributed connection, got %d", unattributed)
+	}
+	if len(talkers) != 3 {
+		t.Fatalf("Expected 3 processes, got %d", len(talkers))
+	}
+
+	sortTalkers(talkers, "connections")
+	if talkers[0].PID != 10 || talkers[0].Connections != 2 || len(talkers[0].RemoteHosts) != 1 || talkers[0].Countries["AU"] != 2 {
+		t.Errorf("Unexpected top talker by connections: %+v", talkers[0])
+	}
+
+	sortTalkers(talkers, "bandwidth")
+	if talkers[0].PID != 20 || talkers[0].TxRate != 5000 || talkers[0].Listening != 1 {
+		t.Errorf("Unexpected top talker by bandwidth: %+v", talkers[0])
+	}
+	for _, talker := range talkers {
+		if talker.PID == 30 && talker.Name != "web" {
+			t.Errorf("Expected PID resolved from ss to carry its name, got %+v", talker)
+		}
+	}
+}
+
+func TestHandleGetNetworkTopProcesses(t *testing.T) {
+	h := NewHandlerManager(&config.Config{MaxProcesses: 5})
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{"sample_seconds": float64(0)},
+		},
+	}
+	res, err := h.HandleGetNetworkTopProcesses(context.Background(), req)
+	checkToolResult(t, res, err, []string{"processes", "matched", "shown", "unattributed", "bandwidth"})
+
+	req.Params.Arguments = map[string]interface{}{"sort_by": "pid"}
+	res, err = h.HandleGetNetworkTopProcesses(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if !res.IsError {
+		t.Error("Expected error result for unsupported sort_by")
+	}
+}
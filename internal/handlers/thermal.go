@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Thermal sysfs roots: every thermal zone and every hwmon chip, not just thermal_zone0
+var (
+	thermalClassPath = "/sys/class/thermal"
+	hwmonClassPath   = "/sys/class/hwmon"
+)
+
+// tripPoint is one thermal zone trip point, e.g. where passive cooling or shutdown starts
+type tripPoint struct {
+	Type    string  `json:"type"`
+	Celsius float64 `json:"celsius"`
+}
+
+// thermalZone is one kernel thermal zone with its current temperature and trip points
+type thermalZone struct {
+	Zone       string      `json:"zone"`
+	Type       string      `json:"type"`
+	Celsius    float64     `json:"celsius"`
+	TripPoints []tripPoint `json:"trip_points,omitempty"`
+}
+
+// hwmonSensor is one hwmon temperature input, such as a CPU core or an NVMe composite
+type hwmonSensor struct {
+	Chip        string   `json:"chip"`
+	Sensor      string   `json:"sensor"`
+	Label       string   `json:"label,omitempty"`
+	Celsius     float64  `json:"celsius"`
+	MaxCelsius  *float64 `json:"max_celsius,omitempty"`
+	CritCelsius *float64 `json:"crit_celsius,omitempty"`
+}
+
+// hwmonFan is one hwmon fan: its tachometer speed and the PWM duty cycle driving it. The
+// Pi 5 active cooler appears as the "pwmfan" chip.
+type hwmonFan struct {
+	Chip        string   `json:"chip"`
+	Fan         string   `json:"fan"`
+	Label       string   `json:"label,omitempty"`
+	RPM         *int64   `json:"rpm,omitempty"`
+	PWM         *int64   `json:"pwm,omitempty"`
+	DutyPercent *float64 `json:"duty_percent,omitempty"`
+	Mode        string   `json:"mode,omitempty"`
+}
+
+// pwmModes names the values of hwmon pwmN_enable
+var pwmModes = map[string]string{"0": "full_speed", "1": "manual", "2": "automatic"}
+
+// readMilliCelsius reads a sysfs temperature in millidegrees and converts it to °C
+func readMilliCelsius(path string) (float64, bool) {
+	s, err := readTrimmed(path)
+	if err != nil {
+		return 0, false
+	}
+	milli, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return round2(milli / 1000), true
+}
+
+// readThermalZones reads every thermal_zoneN under root. Zones whose sensor cannot be read,
+// which some drivers report while the device is powered down, are skipped.
+func readThermalZones(root string) []thermalZone {
+	dirs, _ := filepath.Glob(filepath.Join(root, "thermal_zone[0-9]*"))
+	zones := []thermalZone{}
+	for _, dir := range dirs {
+		celsius, ok := readMilliCelsius(filepath.Join(dir, "temp"))
+		if !ok {
+			continue
+		}
+		z := thermalZone{Zone: filepath.Base(dir), Celsius: celsius}
+		z.Type, _ = readTrimmed(filepath.Join(dir, "type"))
+		types, _ := filepath.Glob(filepath.Join(dir, "trip_point_*_type"))
+		for _, typePath := range types {
+			kind, err := readTrimmed(typePath)
+			if err != nil {
+				continue
+			}
+			if t, ok := readMilliCelsius(strings.TrimSuffix(typePath, "_type") + "_temp"); ok && t > 0 {
+				z.TripPoints = append(z.TripPoints, tripPoint{Type: kind, Celsius: t})
+			}
+		}
+		sort.Slice(z.TripPoints, func(i, j int) bool { return z.TripPoints[i].Celsius < z.TripPoints[j].Celsius })
+		zones = append(zones, z)
+	}
+	sort.Slice(zones, func(i, j int) bool { return zoneNumber(zones[i].Zone) < zoneNumber(zones[j].Zone) })
+	return zones
+}
+
+// zoneNumber orders thermal_zone10 after thermal_zone9
+func zoneNumber(zone string) int {
+	n, _ := strconv.Atoi(strings.TrimPrefix(zone, "thermal_zone"))
+	return n
+}
+
+// readHwmonSensors reads every tempN_input of every hwmon chip under root, with the
+// chip's name, the sensor label (e.g. "Core 3"), and its max and critical limits
+func readHwmonSensors(root string) []hwmonSensor {
+	inputs, _ := filepath.Glob(filepath.Join(root, "hwmon[0-9]*", "temp[0-9]*_input"))
+	sensors := []hwmonSensor{}
+	for _, input := range inputs {
+		celsius, ok := readMilliCelsius(input)
+		if !ok {
+			continue
+		}
+		dir := filepath.Dir(input)
+		prefix := strings.TrimSuffix(input, "_input")
+		s := hwmonSensor{Chip: filepath.Base(dir), Sensor: filepath.Base(prefix), Celsius: celsius}
+		if name, err := readTrimmed(filepath.Join(dir, "name")); err == nil {
+			s.Chip = name
+		}
+		s.Label, _ = readTrimmed(prefix + "_label")
+		if t, ok := readMilliCelsius(prefix + "_max"); ok && t > 0 {
+			s.MaxCelsius = &t
+		}
+		if t, ok := readMilliCelsius(prefix + "_crit"); ok && t > 0 {
+			s.CritCelsius = &t
+		}
+		sensors = append(sensors, s)
+	}
+	sort.SliceStable(sensors, func(i, j int) bool {
+		if sensors[i].Chip != sensors[j].Chip {
+			return sensors[i].Chip < sensors[j].Chip
+		}
+		return sensorIndex(sensors[i].Sensor, "temp") < sensorIndex(sensors[j].Sensor, "temp")
+	})
+	return sensors
+}
+
+// hotSpot returns the hottest reading across zones and hwmon sensors, with where it came from
+func hotSpot(zones []thermalZone, sensors []hwmonSensor) (map[string]interface{}, bool) {
+	var spot map[string]interface{}
+	var hottest float64
+	for _, z := range zones {
+		if spot == nil || z.Celsius > hottest {
+			hottest = z.Celsius
+			spot = map[string]interface{}{"source": "thermal_zone", "name": z.Zone, "label": z.Type, "celsius": z.Celsius}
+		}
+	}
+	for _, s := range sensors {
+		if spot == nil || s.Celsius > hottest {
+			hottest = s.Celsius
+			label := s.Label
+			if label == "" {
+				label = s.Sensor
+			}
+			spot = map[string]interface{}{"source": "hwmon", "name": s.Chip, "label": label, "celsius": s.Celsius}
+		}
+	}
+	return spot, spot != nil
+}
+
+// readHwmonFans reads every fanN_input and pwmN of every hwmon chip under root. A fan
+// without a tachometer reports only its duty cycle; a tachometer without PWM control
+// reports only its speed.
+func readHwmonFans(root string) []hwmonFan {
+	dirs, _ := filepath.Glob(filepath.Join(root, "hwmon[0-9]*"))
+	fans := []hwmonFan{}
+	for _, dir := range dirs {
+		chip := filepath.Base(dir)
+		if name, err := readTrimmed(filepath.Join(dir, "name")); err == nil {
+			chip = name
+		}
+		inputs, _ := filepath.Glob(filepath.Join(dir, "fan[0-9]*_input"))
+		pwms, _ := filepath.Glob(filepath.Join(dir, "pwm[0-9]*"))
+		indexes := map[int]bool{}
+		for _, path := range inputs {
+			indexes[sensorIndex(strings.TrimSuffix(filepath.Base(path), "_input"), "fan")] = true
+		}
+		for _, path := range pwms {
+			// pwm1_enable and friends share the glob; only the bare pwmN holds the duty cycle
+			if n := sensorIndex(filepath.Base(path), "pwm"); n > 0 {
+				indexes[n] = true
+			}
+		}
+		for n := range indexes {
+			if n <= 0 {
+				continue
+			}
+			fan := hwmonFan{Chip: chip, Fan: "fan" + strconv.Itoa(n)}
+			prefix := filepath.Join(dir, fan.Fan)
+			fan.Label, _ = readTrimmed(prefix + "_label")
+			if s, err := readTrimmed(prefix + "_input"); err == nil {
+				if rpm, err := strconv.ParseInt(s, 10, 64); err == nil {
+					fan.RPM = &rpm
+				}
+			}
+			pwm := filepath.Join(dir, "pwm"+strconv.Itoa(n))
+			if s, err := readTrimmed(pwm); err == nil {
+				if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+					duty := round2(float64(v) * 100 / 255)
+					fan.PWM, fan.DutyPercent = &v, &duty
+				}
+			}
+			if s, err := readTrimmed(pwm + "_enable"); err == nil {
+				fan.Mode = pwmModes[s]
+			}
+			if fan.RPM == nil && fan.PWM == nil {
+				continue
+			}
+			fans = append(fans, fan)
+		}
+	}
+	sort.Slice(fans, func(i, j int) bool {
+		if fans[i].Chip != fans[j].Chip {
+			return fans[i].Chip < fans[j].Chip
+		}
+		return sensorIndex(fans[i].Fan, "fan") < sensorIndex(fans[j].Fan, "fan")
+	})
+	return fans
+}
+
+// sensorIndex returns N for a hwmon attribute named prefix+N, or 0 when the name has a suffix
+func sensorIndex(name, prefix string) int {
+	n, err := strconv.Atoi(strings.TrimPrefix(name, prefix))
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// fanWarnings flags fans that are driven but not turning, which usually means a stalled or
+// disconnected fan
+func fanWarnings(fans []hwmonFan) []string {
+	var warnings []string
+	for _, f := range fans {
+		if f.RPM != nil && *f.RPM == 0 && f.PWM != nil && *f.PWM > 0 {
+			warnings = append(warnings, fmt.Sprintf("Fan %s/%s is driven at %.0f%% duty but reports 0 RPM; it may be stalled or disconnected", f.Chip, f.Fan, *f.DutyPercent))
+		}
+	}
+	return warnings
+}
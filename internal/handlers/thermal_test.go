@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadThermalZonesAndHwmon(t *testing.T) {
+	root := t.TempDir()
+	thermal := filepath.Join(root, "thermal")
+	writeSysfsFiles(t, filepath.Join(thermal, "thermal_zone0"), map[string]string{
+		"type": "x86_pkg_temp", "temp": "52000",
+		"trip_point_0_type": "critical", "trip_point_0_temp": "105000",
+		"trip_point_1_type": "passive", "trip_point_1_temp": "95000",
+	})
+	writeSysfsFiles(t, filepath.Join(thermal, "thermal_zone10"), map[string]string{"type": "acpitz", "temp": "27800"})
+	writeSysfsFiles(t, filepath.Join(thermal, "thermal_zone2"), map[string]string{"type": "iwlwifi_1", "temp": "invalid"})
+	writeSysfsFiles(t, filepath.Join(thermal, "cooling_device0"), map[string]string{"type": "Processor"})
+
+	zones := readThermalZones(thermal)
+	if len(zones) != 2 || zones[0].Zone != "thermal_zone0" || zones[1].Zone != "thermal_zone10" {
+		t.Fatalf("Expected zones 0 and 10 in numeric order, got %+v", zones)
+	}
+	if z := zones[0]; z.Type != "x86_pkg_temp" || z.Celsius != 52 || len(z.TripPoints) != 2 || z.TripPoints[0].Type != "passive" || z.TripPoints[1].Celsius != 105 {
+		t.Errorf("Unexpected package zone: %+v", z)
+	}
+
+	hwmon := filepath.Join(root, "hwmon")
+	writeSysfsFiles(t, filepath.Join(hwmon, "hwmon3"), map[string]string{
+		"name":        "coretemp",
+		"temp1_input": "55000", "temp1_label": "Package id 0", "temp1_max": "100000", "temp1_crit": "100000",
+		"temp2_input": "61000", "temp2_label": "Core 0",
+		"temp10_input": "49000", "temp10_label": "Core 8",
+	})
+	writeSysfsFiles(t, filepath.Join(hwmon, "hwmon1"), map[string]string{"name": "nvme", "temp1_input": "38850"})
+
+	sensors := readHwmonSensors(hwmon)
+	if len(sensors) != 4 || sensors[0].Chip != "coretemp" || sensors[2].Sensor != "temp10" || sensors[3].Chip != "nvme" {
+		t.Fatalf("Expected sensors by chip and number, got %+v", sensors)
+	}
+	if s := sensors[0]; s.Label != "Package id 0" || s.MaxCelsius == nil || *s.MaxCelsius != 100 || s.CritCelsius == nil {
+		t.Errorf("Unexpected package sensor: %+v", s)
+	}
+	if s := sensors[3]; s.Label != "" || s.Celsius != 38.85 || s.MaxCelsius != nil {
+		t.Errorf("Unexpected NVMe sensor: %+v", s)
+	}
+
+	spot, ok := hotSpot(zones, sensors)
+	if !ok || spot["source"] != "hwmon" || spot["label"] != "Core 0" || spot["celsius"] != 61.0 {
+		t.Errorf("Expected Core 0 as the hot spot, got %v", spot)
+	}
+	if _, ok := hotSpot(nil, nil); ok {
+		t.Error("Expected no hot spot without sensors")
+	}
+}
+
+func TestReadHwmonFans(t *testing.T) {
+	root := t.TempDir()
+	// The Pi 5 active cooler, and a motherboard chip with a stalled fan and a PWM-only header
+	writeSysfsFiles(t, filepath.Join(root, "hwmon2"), map[string]string{
+		"name": "pwmfan", "fan1_input": "3012", "pwm1": "128", "pwm1_enable": "2",
+	})
+	writeSysfsFiles(t, filepath.Join(root, "hwmon4"), map[string]string{
+		"name": "nct6775", "fan2_input": "0", "fan2_label": "CPU_FAN", "pwm2": "255", "pwm2_enable": "1",
+		"pwm3": "77", "temp1_input": "40000",
+	})
+	writeSysfsFiles(t, filepath.Join(root, "hwmon0"), map[string]string{"name": "acpitz", "temp1_input": "27800"})
+
+	fans := readHwmonFans(root)
+	if len(fans) != 3 || fans[0].Chip != "nct6775" || fans[0].Fan != "fan2" || fans[1].Fan != "fan3" || fans[2].Chip != "pwmfan" {
+		t.Fatalf("Expected three fans by chip and number, got %+v", fans)
+	}
+	if f := fans[2]; f.RPM == nil || *f.RPM != 3012 || f.DutyPercent == nil || *f.DutyPercent != 50.2 || f.Mode != "automatic" {
+		t.Errorf("Unexpected Pi 5 fan: %+v", f)
+	}
+	if f := fans[1]; f.RPM != nil || f.PWM == nil || *f.PWM != 77 {
+		t.Errorf("Expected a PWM-only fan, got %+v", f)
+	}
+
+	warnings := fanWarnings(fans)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "nct6775/fan2 is driven at 100% duty") {
+		t.Errorf("Expected a stalled fan warning, got %v", warnings)
+	}
+}
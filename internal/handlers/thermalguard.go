@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// thermalZonePath is the CPU thermal zone watched while load tests run
+var thermalZonePath = "/sys/class/thermal/thermal_zone0"
+
+// Thermal guard settings
+const (
+	// thermalGuardMargin is how far below the critical trip point a load test is aborted
+	thermalGuardMargin = 10.0
+	// thermalGuardDefaultLimit applies when the zone reports no critical trip point; it is
+	// where Raspberry Pi firmware starts throttling
+	thermalGuardDefaultLimit = 85.0
+	thermalGuardInterval     = 500 * time.Millisecond
+)
+
+// thermalGuard watches the CPU temperature during a load test and cancels the run when it
+// reaches the limit, so passively cooled boards are not driven to a thermal shutdown
+type thermalGuard struct {
+	mu            sync.Mutex
+	zone          string
+	available     bool
+	limitC        float64
+	criticalTripC float64
+	startC        float64
+	peakC         float64
+	aborted       bool
+	reason        string
+}
+
+// newThermalGuard reads the zone's critical trip point and current temperature. A positive
+// maxTempC lowers the limit; it can never be raised above the critical trip point minus
+// the margin.
+func newThermalGuard(zone string, maxTempC float64) *thermalGuard {
+	g := &thermalGuard{zone: zone, limitC: thermalGuardDefaultLimit}
+	if crit, ok := criticalTripTemp(zone); ok {
+		g.criticalTripC = crit
+		g.limitC = crit - thermalGuardMargin
+	}
+	if maxTempC > 0 {
+		g.limitC = math.Min(g.limitC, maxTempC)
+	}
+	if t, err := readZoneTemp(zone); err == nil {
+		g.available = true
+		g.startC, g.peakC = t, t
+	}
+	return g
+}
+
+// tooHot returns an error when the CPU is already at or above the limit
+func (g *thermalGuard) tooHot() error {
+	if g.available && g.startC >= g.limitC {
+		return fmt.Errorf("CPU is at %.1f°C, at or above the %.1f°C limit; let it cool down first", g.startC, g.limitC)
+	}
+	return nil
+}
+
+// watch polls the temperature until stop is called, cancelling the run with the reason
+// when the limit is reached
+func (g *thermalGuard) watch(ctx context.Context, cancel context.CancelCauseFunc) (stop func()) {
+	if !g.available {
+		return func() {}
+	}
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(thermalGuardInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			t, err := readZoneTemp(g.zone)
+			if err != nil {
+				continue
+			}
+			g.mu.Lock()
+			g.peakC = math.Max(g.peakC, t)
+			if t >= g.limitC && !g.aborted {
+				g.aborted = true
+				g.reason = fmt.Sprintf("CPU temperature reached %.1f°C (limit %.1f°C)", t, g.limitC)
+				cancel(fmt.Errorf("aborted: %s", g.reason))
+			}
+			g.mu.Unlock()
+		}
+	}()
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// report summarizes the guard for a tool result
+func (g *thermalGuard) report() map[string]interface{} {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	r := map[string]interface{}{
+		"available":     g.available,
+		"limit_celsius": g.limitC,
+		"aborted":       g.aborted,
+	}
+	if g.criticalTripC > 0 {
+		r["critical_trip_celsius"] = g.criticalTripC
+	}
+	if g.available {
+		r["start_celsius"] = round2(g.startC)
+		r["peak_celsius"] = round2(g.peakC)
+	} else {
+		r["note"] = "No CPU temperature sensor; the run was not temperature-guarded"
+	}
+	if g.aborted {
+		r["reason"] = g.reason
+	}
+	return r
+}
+
+// wasAborted reports whether the guard cancelled the run, and why
+func (g *thermalGuard) wasAborted() (bool, string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.aborted, g.reason
+}
+
+// readZoneTemp reads a thermal zone temperature in °C
+func readZoneTemp(zone string) (float64, error) {
+	data, err := os.ReadFile(filepath.Join(zone, "temp"))
+	if err != nil {
+		return 0, err
+	}
+	milli, err := strconv.ParseFloat(strings.TrimSpace(string(data)), 64)
+	if err != nil {
+		return 0, err
+	}
+	return milli / 1000, nil
+}
+
+// criticalTripTemp returns the zone's critical trip point in °C
+func criticalTripTemp(zone string) (float64, bool) {
+	types, _ := filepath.Glob(filepath.Join(zone, "trip_point_*_type"))
+	for _, typePath := range types {
+		data, err := os.ReadFile(filepath.Clean(typePath))
+		if err != nil || strings.TrimSpace(string(data)) != "critical" {
+			continue
+		}
+		tempPath := strings.TrimSuffix(typePath, "_type") + "_temp"
+		data, err = os.ReadFile(filepath.Clean(tempPath))
+		if err != nil {
+			continue
+		}
+		if milli, err := strconv.ParseFloat(strings.TrimSpace(string(data)), 64); err == nil && milli > 0 {
+			return milli / 1000, true
+		}
+	}
+	return 0, false
+}
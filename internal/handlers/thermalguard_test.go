@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"sysmetrics-mcp/internal/bench"
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// fakeThermalZone creates a thermal zone at tempC with a critical trip point at critC
+func fakeThermalZone(t *testing.T, tempC, critC float64) string {
+	t.Helper()
+	zone := t.TempDir()
+	files := map[string]string{
+		"trip_point_0_type": "passive\n",
+		"trip_point_0_temp": "60000\n",
+		"trip_point_1_type": "critical\n",
+		"trip_point_1_temp": milliCelsius(critC),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(zone, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setZoneTemp(t, zone, tempC)
+	return zone
+}
+
+func setZoneTemp(t *testing.T, zone string, tempC float64) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(zone, "temp"), []byte(milliCelsius(tempC)), 0o600); err != nil {
+		t.Error(err)
+	}
+}
+
+func milliCelsius(c float64) string {
+	return strconv.Itoa(int(c*1000)) + "\n"
+}
+
+func TestNewThermalGuard(t *testing.T) {
+	zone := fakeThermalZone(t, 50, 90)
+
+	tests := []struct {
+		maxTemp float64
+		want    float64
+	}{
+		{0, 80},
+		{95, 80},
+		{70, 70},
+	}
+	for _, tc := range tests {
+		g := newThermalGuard(zone, tc.maxTemp)
+		if !g.available || g.limitC != tc.want || g.criticalTripC != 90 || g.startC != 50 {
+			t.Errorf("newThermalGuard(max=%v) = limit %v, critical %v, start %v; want limit %v", tc.maxTemp, g.limitC, g.criticalTripC, g.startC, tc.want)
+		}
+	}
+
+	if g := newThermalGuard(t.TempDir(), 0); g.available || g.limitC != thermalGuardDefaultLimit {
+		t.Errorf("Expected an unavailable guard with the default limit, got %+v", g.report())
+	}
+	if err := newThermalGuard(fakeThermalZone(t, 85, 90), 0).tooHot(); err == nil {
+		t.Error("Expected tooHot() to refuse a CPU already above the limit")
+	}
+}
+
+func TestThermalGuardAborts(t *testing.T) {
+	zone := fakeThermalZone(t, 50, 90)
+	g := newThermalGuard(zone, 0)
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	stop := g.watch(ctx, cancel)
+	defer stop()
+
+	setZoneTemp(t, zone, 82)
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the guard to cancel the run")
+	}
+	if aborted, reason := g.wasAborted(); !aborted || !strings.Contains(reason, "82.0") {
+		t.Errorf("wasAborted() = %v, %q", aborted, reason)
+	}
+	if cause := context.Cause(ctx); cause == nil || errors.Is(cause, context.Canceled) {
+		t.Errorf("Expected the abort reason as the cancel cause, got %v", cause)
+	}
+}
+
+func TestHandleRunSystemBaselineThermalAbort(t *testing.T) {
+	zone := fakeThermalZone(t, 50, 90)
+	orig := thermalZonePath
+	thermalZonePath = zone
+	defer func() { thermalZonePath = orig }()
+
+	h := NewHandlerManager(&config.Config{})
+	h.baselines = bench.NewStore(filepath.Join(t.TempDir(), "baselines.json"))
+	time.AfterFunc(200*time.Millisecond, func() { setZoneTemp(t, zone, 88) })
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"name": "hot", "tests": "cpu,memory", "duration_seconds": 10.0}
+	res, err := h.HandleRunSystemBaseline(context.Background(), req)
+	checkToolResult(t, res, err, []string{"aborted", "abort_reason", "thermal_guard", "errors", "report"})
+	text := resultText(res)
+	if !strings.Contains(text, `"partial":true`) || strings.Contains(text, `"memory"`) {
+		t.Errorf("Expected a partial CPU result and no memory result, got %s", text)
+	}
+	if !strings.Contains(text, `"saved":false`) {
+		t.Errorf("Expected an aborted run not to be saved, got %s", text)
+	}
+}
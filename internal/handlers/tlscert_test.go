@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// writeTestCertPEM writes a PEM file holding a private key followed by a self-signed
+// certificate that expires after validFor
+func writeTestCertPEM(t *testing.T, dir, name string, validFor time.Duration) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "pi.lan"},
+		DNSNames:     []string{"pi.lan", "grafana.pi.lan"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(validFor),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	data = append(data, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	writeSysfsFiles(t, dir, map[string]string{name: string(data)})
+	return filepath.Join(dir, name)
+}
+
+func TestReadPEMCertificates(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTestCertPEM(t, dir, "cert.pem", 90*24*time.Hour)
+
+	certs, err := readPEMCertificates(path)
+	if err != nil {
+		t.Fatalf("readPEMCertificates failed: %v", err)
+	}
+	if len(certs) != 1 || certs[0].Subject.CommonName != "pi.lan" {
+		t.Errorf("Unexpected certificates: %+v", certs)
+	}
+
+	writeSysfsFiles(t, dir, map[string]string{"empty.pem": "not a certificate"})
+	if _, err := readPEMCertificates(filepath.Join(dir, "empty.pem")); err == nil {
+		t.Error("Expected error for file without certificates")
+	}
+}
+
+func TestInspectPEMFile(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTestCertPEM(t, dir, "cert.pem", 10*24*time.Hour+time.Hour)
+
+	report := inspectPEMFile(path, time.Now())
+	if report.Error != "" || report.certInfo == nil {
+		t.Fatalf("Unexpected error: %+v", report)
+	}
+	if report.DaysRemaining != 10 || report.Issuer != "pi.lan" || len(report.DNSNames) != 2 || report.Verified != nil {
+		t.Errorf("Unexpected report: %+v %+v", report, report.certInfo)
+	}
+
+	report = inspectPEMFile(filepath.Join(dir, "missing.pem"), time.Now())
+	if report.Error == "" || report.certInfo != nil {
+		t.Errorf("Expected error for missing file, got %+v", report)
+	}
+}
+
+func TestInspectTLSEndpoint(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	report := inspectTLSEndpoint(context.Background(), srv.Listener.Addr().String(), 2*time.Second, time.Now())
+	if report.Error != "" || report.certInfo == nil {
+		t.Fatalf("Unexpected error: %+v", report)
+	}
+	if report.Verified == nil || *report.Verified || report.VerifyError == "" {
+		t.Errorf("Expected unverified self-signed certificate, got %+v", report)
+	}
+	if report.DaysRemaining <= 0 {
+		t.Errorf("Expected positive days remaining, got %d", report.DaysRemaining)
+	}
+}
+
+func TestDaysUntil(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		t        time.Time
+		expected int
+	}{
+		{"future", now.Add(30*24*time.Hour + time.Hour), 30},
+		{"partial_day", now.Add(12 * time.Hour), 0},
+		{"expired", now.Add(-time.Hour), -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := daysUntil(tt.t, now); got != tt.expected {
+				t.Errorf("daysUntil() = %d, expected %d", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHandleGetTLSCertInfo(t *testing.T) {
+	dir := t.TempDir()
+	soon := writeTestCertPEM(t, dir, "soon.pem", 5*24*time.Hour)
+	later := writeTestCertPEM(t, dir, "later.pem", 200*24*time.Hour)
+
+	h := NewHandlerManager(&config.Config{})
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{"files": later + "," + soon + "," + filepath.Join(dir, "missing.pem")},
+		},
+	}
+	res, err := h.HandleGetTLSCertInfo(context.Background(), req)
+	checkToolResult(t, res, err, []string{"certificates", "total", "expiring_count", "warn_days"})
+
+	var result struct {
+		Certificates []map[string]interface{} `json:"certificates"`
+		Expiring     int                      `json:"expiring_count"`
+	}
+	if err := json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &result); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+	if result.Expiring != 1 || len(result.Certificates) != 3 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+	if result.Certificates[0]["source"] != soon || result.Certificates[0]["expiring"] != true {
+		t.Errorf("Expected soonest expiry first, got %v", result.Certificates[0])
+	}
+	if result.Certificates[2]["error"] == nil {
+		t.Errorf("Expected missing file last with an error, got %v", result.Certificates[2])
+	}
+
+	for _, args := range []map[string]interface{}{
+		{},
+		{"endpoints": "-oProxyCommand:443"},
+	} {
+		req.Params.Arguments = args
+		res, err := h.HandleGetTLSCertInfo(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Handler returned error: %v", err)
+		}
+		if !res.IsError {
+			t.Errorf("Expected error result for %v", args)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"sysmetrics-mcp/internal/config"
+	"sysmetrics-mcp/internal/update"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// restartNote tells the assistant how to finish an update or rollback
+const restartNote = "restart the MCP server (usually by restarting its client) to run the new binary"
+
+// HandleCheckServerUpdate compares the running version with the latest GitHub release
+func (h *HandlerManager) HandleCheckServerUpdate(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	force := false
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if f, ok := args["force"].(bool); ok {
+			force = f
+		}
+	}
+
+	result := map[string]interface{}{
+		"current_version": config.ServerVersion,
+		"offline":         h.cfg.UpdateOffline,
+		"apply_enabled":   h.cfg.AllowSelfUpdate,
+	}
+
+	// A failed lookup is reported in the result rather than failing the tool
+	check, err := h.updater.Latest(ctx, force, h.cfg.UpdateOffline)
+	if err != nil {
+		result["update_available"] = false
+		result["error"] = err.Error()
+	} else {
+		release := check.Release
+		assetName := update.AssetName(runtime.GOOS, runtime.GOARCH)
+		_, hasAsset := update.FindAsset(release, assetName)
+
+		result["latest_version"] = release.TagName
+		result["update_available"] = update.CompareVersions(release.TagName, config.ServerVersion) > 0
+		result["release_name"] = release.Name
+		result["release_url"] = release.HTMLURL
+		result["published_at"] = release.PublishedAt
+		result["checked_at"] = check.CheckedAt.Format(time.RFC3339)
+		result["from_cache"] = check.FromCache
+		result["asset"] = assetName
+		result["asset_available"] = hasAsset
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// HandleApplyUpdate replaces the server binary with the latest release, or restores the
+// previous binary when rollback is set
+func (h *HandlerManager) HandleApplyUpdate(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	confirm, rollback := false, false
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if c, ok := args["confirm"].(bool); ok {
+			confirm = c
+		}
+		if r, ok := args["rollback"].(bool); ok {
+			rollback = r
+		}
+	}
+
+	if !confirm {
+		return mcp.NewToolResultError("confirm must be true to replace the server binary"), nil
+	}
+
+	exePath, err := currentExecutable()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to locate server binary: %v", err)), nil
+	}
+
+	var result map[string]interface{}
+	if rollback {
+		if err := update.Rollback(exePath); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Rollback failed: %v", err)), nil
+		}
+		result = map[string]interface{}{
+			"rolled_back":      true,
+			"path":             exePath,
+			"restart_required": true,
+			"note":             restartNote,
+		}
+	} else {
+		if h.cfg.UpdateOffline {
+			return mcp.NewToolResultError("Updates cannot be applied with --update-offline"), nil
+		}
+		check, err := h.updater.Latest(ctx, true, false)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to check for updates: %v", err)), nil
+		}
+		release := check.Release
+		if update.CompareVersions(release.TagName, config.ServerVersion) <= 0 {
+			result = map[string]interface{}{
+				"updated":         false,
+				"current_version": config.ServerVersion,
+				"latest_version":  release.TagName,
+				"note":            "already running the latest release",
+			}
+		} else {
+			assetName := update.AssetName(runtime.GOOS, runtime.GOARCH)
+			asset, ok := update.FindAsset(release, assetName)
+			if !ok {
+				return mcp.NewToolResultError(fmt.Sprintf("Release %s has no binary for this platform (%s)", release.TagName, assetName)), nil
+			}
+
+			applied, err := update.Apply(ctx, release, asset, update.ApplyOptions{
+				ExePath: exePath,
+				Verify:  update.VerifyVersion(release.TagName),
+			})
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Update failed: %v", err)), nil
+			}
+			result = map[string]interface{}{
+				"updated":          true,
+				"previous_version": config.ServerVersion,
+				"new_version":      release.TagName,
+				"path":             applied.Path,
+				"backup_path":      applied.BackupPath,
+				"sha256":           applied.SHA256,
+				"restart_required": true,
+				"note":             restartNote + "; call apply_update with rollback=true to restore the previous binary",
+			}
+		}
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// currentExecutable returns the resolved path of the running binary
+func currentExecutable() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(exe)
+}
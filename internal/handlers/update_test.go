@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+	"sysmetrics-mcp/internal/update"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleCheckServerUpdateOffline(t *testing.T) {
+	h := NewHandlerManager(&config.Config{UpdateOffline: true})
+	h.updater = update.NewChecker("http://127.0.0.1:0/unreachable", filepath.Join(t.TempDir(), "cache.json"))
+
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{"force": true},
+		},
+	}
+	res, err := h.HandleCheckServerUpdate(context.Background(), req)
+	checkToolResult(t, res, err, []string{"current_version", "offline", "update_available", "error"})
+
+	var result map[string]interface{}
+	if err := json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &result); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+	if result["current_version"] != config.ServerVersion || result["update_available"] != false {
+		t.Errorf("Unexpected result: %v", result)
+	}
+}
+
+func TestHandleApplyUpdateRequiresConfirm(t *testing.T) {
+	h := NewHandlerManager(&config.Config{AllowSelfUpdate: true, UpdateOffline: true})
+
+	for _, args := range []map[string]interface{}{
+		{},
+		{"confirm": false, "rollback": true},
+		{"confirm": true},
+	} {
+		req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}}
+		res, err := h.HandleApplyUpdate(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Handler returned error: %v", err)
+		}
+		if !res.IsError {
+			t.Errorf("Expected error result for %v", args)
+		}
+	}
+}
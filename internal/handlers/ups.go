@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"sysmetrics-mcp/internal/ups"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// HandleGetUPSStatus returns UPS state from the configured NUT and apcupsd daemons
+func (h *HandlerManager) HandleGetUPSStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	backend := kindAll
+	var upsName string
+
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if b, ok := args["backend"].(string); ok && b != "" {
+			backend = strings.ToLower(b)
+		}
+		if n, ok := args["ups"].(string); ok && n != "" {
+			upsName = n
+		}
+	}
+
+	if backend != kindAll && backend != ups.BackendNUT && backend != ups.BackendApcupsd {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid backend: %s (must be nut, apcupsd, or all)", backend)), nil
+	}
+
+	devices := []ups.Status{}
+	backendStatus := map[string]interface{}{}
+
+	// A daemon that is not running is reported without hiding the other one
+	if h.cfg.NUTAddr != "" && (backend == kindAll || backend == ups.BackendNUT) {
+		statuses, err := ups.QueryNUT(ctx, h.cfg.NUTAddr, upsName)
+		if err != nil {
+			backendStatus[ups.BackendNUT] = map[string]interface{}{"available": false, "address": h.cfg.NUTAddr, "error": err.Error()}
+		} else {
+			backendStatus[ups.BackendNUT] = map[string]interface{}{"available": true, "address": h.cfg.NUTAddr, "ups": len(statuses)}
+			devices = append(devices, statuses...)
+		}
+	}
+
+	if h.cfg.ApcupsdAddr != "" && (backend == kindAll || backend == ups.BackendApcupsd) {
+		status, err := ups.QueryApcupsd(ctx, h.cfg.ApcupsdAddr)
+		switch {
+		case err != nil:
+			backendStatus[ups.BackendApcupsd] = map[string]interface{}{"available": false, "address": h.cfg.ApcupsdAddr, "error": err.Error()}
+		case upsName != "" && status.Name != upsName:
+			backendStatus[ups.BackendApcupsd] = map[string]interface{}{"available": true, "address": h.cfg.ApcupsdAddr, "ups": 0}
+		default:
+			backendStatus[ups.BackendApcupsd] = map[string]interface{}{"available": true, "address": h.cfg.ApcupsdAddr, "ups": 1}
+			devices = append(devices, status)
+		}
+	}
+
+	onBattery := false
+	for _, d := range devices {
+		onBattery = onBattery || d.OnBattery
+	}
+
+	result := map[string]interface{}{
+		"ups":        devices,
+		"total":      len(devices),
+		"on_battery": onBattery,
+		"backends":   backendStatus,
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
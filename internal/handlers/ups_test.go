@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleGetUPSStatusUnreachable(t *testing.T) {
+	// Reserve a loopback port and close it so both daemons are unreachable
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	h := NewHandlerManager(&config.Config{NUTAddr: addr, ApcupsdAddr: addr})
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{},
+		},
+	}
+	res, err := h.HandleGetUPSStatus(context.Background(), req)
+	checkToolResult(t, res, err, []string{"ups", "total", "on_battery", "backends"})
+
+	var result struct {
+		Total    int                               `json:"total"`
+		Backends map[string]map[string]interface{} `json:"backends"`
+	}
+	if err := json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &result); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+	if result.Total != 0 {
+		t.Errorf("Expected no UPS devices, got %d", result.Total)
+	}
+	for _, name := range []string{"nut", "apcupsd"} {
+		if available, _ := result.Backends[name]["available"].(bool); available {
+			t.Errorf("Expected backend %s to be unavailable", name)
+		}
+	}
+
+	req.Params.Arguments = map[string]interface{}{"backend": "snmp"}
+	res, err = h.HandleGetUPSStatus(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if !res.IsError {
+		t.Error("Expected error result for unsupported backend")
+	}
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"sysmetrics-mcp/internal/usage"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// usageFlushInterval is how often recorded tool calls are written to the statistics file
+const usageFlushInterval = time.Minute
+
+// Thresholds for get_usage_stats warnings; tools with fewer than usageMinCalls calls are
+// not judged
+const (
+	usageMinCalls     = 5
+	usageFlakyPercent = 20.0
+	usageSlowP90MS    = 5000.0
+)
+
+// get_usage_stats orders
+const (
+	usageSortCalls   = "calls"
+	usageSortErrors  = "errors"
+	usageSortLatency = "latency"
+)
+
+// usageSorts are the orders get_usage_stats accepts
+var usageSorts = []string{usageSortCalls, usageSortErrors, usageSortLatency}
+
+// usageEnabled reports whether tool calls are recorded, which needs a statistics file
+// and get_usage_stats to be registered
+func (h *HandlerManager) usageEnabled() bool {
+	if h.usageStats.Path() == "" {
+		return false
+	}
+	ok, _ := h.cfg.ToolEnabled("get_usage_stats")
+	return ok
+}
+
+// usageTool records every call that reaches the collector with its latency and outcome
+func (h *HandlerManager) usageTool(tool mcp.Tool, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		result, err := next(ctx, request)
+		outcome, msg := usage.OK, ""
+		switch {
+		case err != nil:
+			outcome, msg = usage.Failure, err.Error()
+		case result != nil && result.IsError:
+			outcome, msg = usage.Error, resultText(result)
+		}
+		h.usageStats.Record(tool.Name, time.Since(start), outcome, msg, time.Now())
+		return result, err
+	}
+}
+
+// RunUsageTracker writes the tool usage statistics every minute and once more when ctx is
+// cancelled. It returns immediately when calls are not recorded.
+func (h *HandlerManager) RunUsageTracker(ctx context.Context) {
+	if !h.usageEnabled() {
+		return
+	}
+	ticker := time.NewTicker(usageFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if err := h.usageStats.Flush(); err != nil {
+				h.logger.Warn("usage statistics flush failed", "error", err)
+			}
+			return
+		case <-ticker.C:
+			if err := h.usageStats.Flush(); err != nil {
+				h.logger.Warn("usage statistics flush failed", "error", err)
+			}
+		}
+	}
+}
+
+// toolUsage is one tool's row in get_usage_stats
+type toolUsage struct {
+	Tool             string  `json:"tool"`
+	Calls            uint64  `json:"calls"`
+	Errors           uint64  `json:"errors"`
+	Failures         uint64  `json:"failures"`
+	ErrorRatePercent float64 `json:"error_rate_percent"`
+	AvgMS            float64 `json:"avg_ms"`
+	P50MS            float64 `json:"p50_ms"`
+	P90MS            float64 `json:"p90_ms"`
+	P99MS            float64 `json:"p99_ms"`
+	MaxMS            float64 `json:"max_ms"`
+	LastCall         string  `json:"last_call"`
+	LastError        string  `json:"last_error,omitempty"`
+	LastErrorAt      string  `json:"last_error_at,omitempty"`
+}
+
+// HandleGetUsageStats returns per-tool call counts, error rates, and latency percentiles
+// recorded across restarts
+func (h *HandlerManager) HandleGetUsageStats(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var filter string
+	sortBy := usageSortCalls
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if t, ok := args["tool"].(string); ok {
+			filter = strings.TrimSpace(t)
+		}
+		if s, ok := args["sort_by"].(string); ok && s != "" {
+			sortBy = strings.ToLower(strings.TrimSpace(s))
+		}
+	}
+	if !contains(usageSorts, sortBy) {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid sort_by: %q (must be calls, errors, or latency)", sortBy)), nil
+	}
+
+	since, stats := h.usageStats.Snapshot(time.Now())
+	rows := []toolUsage{}
+	var calls, errs, failures uint64
+	for name, s := range stats {
+		if filter != "" && name != filter {
+			continue
+		}
+		row := toolUsage{
+			Tool: name, Calls: s.Calls, Errors: s.Errors, Failures: s.Failures,
+			ErrorRatePercent: round2(s.ErrorRate()),
+			AvgMS:            round2(s.AvgMS()),
+			P50MS:            round2(s.Percentile(0.5)),
+			P90MS:            round2(s.Percentile(0.9)),
+			P99MS:            round2(s.Percentile(0.99)),
+			MaxMS:            round2(s.MaxMS),
+			LastCall:         s.LastCall.UTC().Format(time.RFC3339),
+			LastError:        s.LastError,
+		}
+		if s.LastErrorAt != nil {
+			row.LastErrorAt = s.LastErrorAt.UTC().Format(time.RFC3339)
+		}
+		rows = append(rows, row)
+		calls, errs, failures = calls+s.Calls, errs+s.Errors, failures+s.Failures
+	}
+	if filter != "" && len(rows) == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("No calls of %s have been recorded", filter)), nil
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		switch {
+		case sortBy == usageSortErrors && a.ErrorRatePercent != b.ErrorRatePercent:
+			return a.ErrorRatePercent > b.ErrorRatePercent
+		case sortBy == usageSortLatency && a.P90MS != b.P90MS:
+			return a.P90MS > b.P90MS
+		case a.Calls != b.Calls:
+			return a.Calls > b.Calls
+		}
+		return a.Tool < b.Tool
+	})
+
+	warnings := []string{}
+	for _, r := range rows {
+		if r.Calls < usageMinCalls {
+			continue
+		}
+		if r.ErrorRatePercent >= usageFlakyPercent {
+			warnings = append(warnings, fmt.Sprintf("%s failed %.0f%% of %d calls; last error: %s", r.Tool, r.ErrorRatePercent, r.Calls, truncateString(r.LastError, 120)))
+		}
+		if r.P90MS >= usageSlowP90MS {
+			warnings = append(warnings, fmt.Sprintf("%s takes %.1fs at p90; consider --cache-ttl or a longer client timeout", r.Tool, r.P90MS/1000))
+		}
+	}
+
+	result := map[string]interface{}{
+		"tracked_since": since.UTC().Format(time.RFC3339),
+		"path":          h.usageStats.Path(),
+		"tools":         rows,
+		"totals": map[string]interface{}{
+			"tools":    len(rows),
+			"calls":    calls,
+			"errors":   errs,
+			"failures": failures,
+		},
+		"warnings": warnings,
+		"note":     "Counts calls that reached the collector; results served from the cache and calls refused by the rate limit are not included",
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sysmetrics-mcp/internal/config"
+	"sysmetrics-mcp/internal/usage"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestUsageTool(t *testing.T) {
+	h := NewHandlerManager(&config.Config{})
+	h.usageStats = usage.NewTracker(filepath.Join(t.TempDir(), "usage.json"))
+	outcomes := []func() (*mcp.CallToolResult, error){
+		func() (*mcp.CallToolResult, error) { return mcp.NewToolResultText("{}"), nil },
+		func() (*mcp.CallToolResult, error) { return mcp.NewToolResultError("smartctl not found"), nil },
+		func() (*mcp.CallToolResult, error) { return nil, errors.New("boom") },
+	}
+	for i := 0; i < 10; i++ {
+		next := outcomes[0]
+		if i < len(outcomes) {
+			next = outcomes[i]
+		}
+		wrapped := h.usageTool(mcp.NewTool("get_disk_metrics"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return next()
+		})
+		_, _ = wrapped(context.Background(), mcp.CallToolRequest{})
+	}
+	h.usageStats.Record("get_cpu_metrics", 6*time.Second, usage.OK, "", time.Now())
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"sort_by": "errors"}
+	res, err := h.HandleGetUsageStats(context.Background(), req)
+	checkToolResult(t, res, err, []string{"tracked_since", "tools", "totals", "warnings"})
+	var result struct {
+		Tools    []toolUsage `json:"tools"`
+		Warnings []string    `json:"warnings"`
+	}
+	if err := json.Unmarshal([]byte(resultText(res)), &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Tools) != 2 || result.Tools[0].Tool != "get_disk_metrics" {
+		t.Fatalf("Expected get_disk_metrics first by error rate, got %+v", result.Tools)
+	}
+	if d := result.Tools[0]; d.Calls != 10 || d.Errors != 1 || d.Failures != 1 || d.ErrorRatePercent != 20 || d.LastError != "boom" {
+		t.Errorf("Unexpected usage row: %+v", d)
+	}
+	if len(result.Warnings) != 1 {
+		t.Errorf("Expected only the flaky tool to be flagged (get_cpu_metrics has too few calls), got %v", result.Warnings)
+	}
+
+	req.Params.Arguments = map[string]interface{}{"sort_by": "slowest"}
+	if res, err := h.HandleGetUsageStats(context.Background(), req); err != nil || !res.IsError {
+		t.Error("Expected an invalid sort_by to be rejected")
+	}
+	req.Params.Arguments = map[string]interface{}{"tool": "get_gpu_metrics"}
+	if res, err := h.HandleGetUsageStats(context.Background(), req); err != nil || !res.IsError {
+		t.Error("Expected an unknown tool to be rejected")
+	}
+}
@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// vmSampleInterval separates the two domstats samples CPU usage and I/O rates come from
+var vmSampleInterval = time.Second
+
+// vmQueryTimeout bounds both virsh calls
+const vmQueryTimeout = 15 * time.Second
+
+// defaultLibvirtURI is the system hypervisor connection. virsh defaults to the user's own
+// session when not run as root, which has none of the host's domains.
+const defaultLibvirtURI = "qemu:///system"
+
+// vmStates maps libvirt's virDomainState values to their names
+var vmStates = map[string]string{
+	"0": "nostate", "1": "running", "2": "blocked", "3": "paused",
+	"4": "shutdown", "5": "shutoff", "6": "crashed", "7": "pmsuspended",
+}
+
+// domainStats is one domain's block of `virsh domstats --raw` output
+type domainStats struct {
+	name  string
+	stats map[string]string
+}
+
+// HandleGetVMMetrics lists libvirt domains with state, vCPU usage, memory balloon, and
+// disk and network I/O
+func (h *HandlerManager) HandleGetVMMetrics(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var domainFilter string
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if d, ok := args["domain"].(string); ok {
+			domainFilter = d
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, vmQueryTimeout)
+	defer cancel()
+
+	uri := libvirtURI()
+	first, err := h.readDomainStats(ctx, uri)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read libvirt domain stats from %s: %v (the server user needs access to the libvirt socket, usually through the libvirt group)", uri, err)), nil
+	}
+	start := time.Now()
+	select {
+	case <-ctx.Done():
+		return mcp.NewToolResultError("Timed out sampling libvirt domains"), nil
+	case <-time.After(vmSampleInterval):
+	}
+	second, err := h.readDomainStats(ctx, uri)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read libvirt domain stats from %s: %v", uri, err)), nil
+	}
+	elapsed := time.Since(start).Seconds()
+
+	previous := make(map[string]map[string]string, len(first))
+	for _, d := range first {
+		previous[d.name] = d.stats
+	}
+
+	domains := []map[string]interface{}{}
+	running, totalVCPUs := 0, 0
+	var totalMemory uint64
+	for _, d := range second {
+		if domainFilter != "" && d.name != domainFilter {
+			continue
+		}
+		vm := h.vmInfo(d, previous[d.name], elapsed)
+		if vm["state"] == "running" {
+			running++
+			if n, ok := vm["vcpus"].(int); ok {
+				totalVCPUs += n
+			}
+			if b, ok := vm["memory_bytes"].(uint64); ok {
+				totalMemory += b
+			}
+		}
+		domains = append(domains, vm)
+	}
+	if domainFilter != "" && len(domains) == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("Domain %q not found", domainFilter)), nil
+	}
+
+	result := map[string]interface{}{
+		"uri":                  uri,
+		"domains":              domains,
+		"total":                len(domains),
+		"running":              running,
+		"running_vcpus":        totalVCPUs,
+		"running_memory_bytes": totalMemory,
+		"running_memory_human": h.human.Bytes(totalMemory),
+		"sample_interval_secs": round2(elapsed),
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// libvirtURI returns the hypervisor connection, honoring LIBVIRT_DEFAULT_URI as virsh does
+func libvirtURI() string {
+	if uri := os.Getenv("LIBVIRT_DEFAULT_URI"); uri != "" {
+		return uri
+	}
+	return defaultLibvirtURI
+}
+
+// readDomainStats runs `virsh domstats --raw` for every defined domain
+func (h *HandlerManager) readDomainStats(ctx context.Context, uri string) ([]domainStats, error) {
+	out, err := h.privilegedCommand(ctx, "virsh", "-c", uri, "domstats", "--raw").Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseDomainStats(string(out)), nil
+}
+
+// parseDomainStats parses `virsh domstats --raw` output, which is a "Domain: 'name'" line
+// followed by indented key=value lines for each domain
+func parseDomainStats(out string) []domainStats {
+	var domains []domainStats
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if name, ok := strings.CutPrefix(line, "Domain: "); ok {
+			domains = append(domains, domainStats{name: strings.Trim(name, "'"), stats: map[string]string{}})
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || len(domains) == 0 {
+			continue
+		}
+		domains[len(domains)-1].stats[key] = value
+	}
+	return domains
+}
+
+// vmInfo summarizes one domain. CPU usage is the share of the domain's vCPUs used over
+// the sample, and I/O rates are per second over the sample; both need the domain to have
+// been running in the previous sample.
+func (h *HandlerManager) vmInfo(d domainStats, prev map[string]string, elapsed float64) map[string]interface{} {
+	s := d.stats
+	state, ok := vmStates[s["state.state"]]
+	if !ok {
+		state = "unknown"
+	}
+	vm := map[string]interface{}{
+		"name":  d.name,
+		"state": state,
+	}
+
+	if n, err := strconv.Atoi(s["vcpu.current"]); err == nil {
+		vm["vcpus"] = n
+	}
+	if n, err := strconv.Atoi(s["vcpu.maximum"]); err == nil {
+		vm["vcpus_max"] = n
+	}
+	if ns, ok := parseStatUint(s, "cpu.time"); ok {
+		vm["cpu_time_seconds"] = round2(float64(ns) / 1e9)
+		if prevNS, ok := parseStatUint(prev, "cpu.time"); ok && ns >= prevNS && elapsed > 0 {
+			vcpus, _ := vm["vcpus"].(int)
+			vm["cpu_percent"] = round2(float64(ns-prevNS) / 1e9 / elapsed / float64(max(vcpus, 1)) * 100)
+		}
+	}
+
+	// Balloon sizes are in KiB. available and unused come from the guest's balloon
+	// driver and are missing when it does not report stats.
+	memory := map[string]interface{}{}
+	for _, key := range []string{"current", "maximum", "rss", "available", "unused", "usable"} {
+		if kib, ok := parseStatUint(s, "balloon."+key); ok {
+			memory[key+"_bytes"] = kib * 1024
+		}
+	}
+	if cur, ok := memory["current_bytes"].(uint64); ok {
+		vm["memory_bytes"] = cur
+		vm["memory_human"] = h.human.Bytes(cur)
+	}
+	avail, okAvail := memory["available_bytes"].(uint64)
+	unused, okUnused := memory["unused_bytes"].(uint64)
+	if okAvail && okUnused && avail > 0 && unused <= avail {
+		memory["guest_used_percent"] = round2(float64(avail-unused) / float64(avail) * 100)
+	}
+	if len(memory) > 0 {
+		vm["memory"] = memory
+	}
+
+	vm["disks"] = indexedDomainStats(s, prev, "block", elapsed,
+		[]string{"rd.bytes", "wr.bytes", "rd.reqs", "wr.reqs", "capacity", "allocation"},
+		map[string]string{"rd.bytes": "read_bytes_per_sec", "wr.bytes": "write_bytes_per_sec"})
+	vm["interfaces"] = indexedDomainStats(s, prev, "net", elapsed,
+		[]string{"rx.bytes", "tx.bytes", "rx.pkts", "tx.pkts", "rx.errs", "tx.errs", "rx.drop", "tx.drop"},
+		map[string]string{"rx.bytes": "rx_bytes_per_sec", "tx.bytes": "tx_bytes_per_sec"})
+	return vm
+}
+
+// indexedDomainStats reads numbered stats such as block.0.rd.bytes into one entry per
+// device. Counters listed in rates also get a per-second rate, matched to the previous
+// sample by device name as indexes can shift when devices are hotplugged.
+func indexedDomainStats(s, prev map[string]string, prefix string, elapsed float64, counters []string, rates map[string]string) []map[string]interface{} {
+	prevIndex := map[string]string{}
+	if n, ok := parseStatUint(prev, prefix+".count"); ok {
+		for i := uint64(0); i < n; i++ {
+			idx := strconv.FormatUint(i, 10)
+			prevIndex[prev[prefix+"."+idx+".name"]] = idx
+		}
+	}
+
+	devices := []map[string]interface{}{}
+	count, _ := parseStatUint(s, prefix+".count")
+	for i := uint64(0); i < count; i++ {
+		idx := strconv.FormatUint(i, 10)
+		name := s[prefix+"."+idx+".name"]
+		device := map[string]interface{}{"name": name}
+		if path := s[prefix+"."+idx+".path"]; path != "" {
+			device["path"] = path
+		}
+		for _, c := range counters {
+			v, ok := parseStatUint(s, prefix+"."+idx+"."+c)
+			if !ok {
+				continue
+			}
+			device[strings.ReplaceAll(c, ".", "_")] = v
+			rateKey, wantRate := rates[c]
+			prevIdx, seen := prevIndex[name]
+			if !wantRate || !seen || elapsed <= 0 {
+				continue
+			}
+			if p, ok := parseStatUint(prev, prefix+"."+prevIdx+"."+c); ok && v >= p {
+				device[rateKey] = round2(float64(v-p) / elapsed)
+			}
+		}
+		devices = append(devices, device)
+	}
+	return devices
+}
+
+// parseStatUint reads an unsigned domstats value
+func parseStatUint(s map[string]string, key string) (uint64, bool) {
+	v, ok := s[key]
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(v, 10, 64)
+	return n, err == nil
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+)
+
+// domstatsSample is `virsh domstats --raw` output for a running and a stopped domain
+const domstatsSample = `Domain: 'web'
+  state.state=1
+  state.reason=1
+  cpu.time=$CPU
+  vcpu.current=2
+  vcpu.maximum=4
+  balloon.current=2097152
+  balloon.maximum=4194304
+  balloon.available=2000000
+  balloon.unused=500000
+  net.count=1
+  net.0.name=vnet0
+  net.0.rx.bytes=$RX
+  net.0.tx.bytes=2000
+  block.count=1
+  block.0.name=vda
+  block.0.path=/var/lib/libvirt/images/web.qcow2
+  block.0.rd.bytes=4096
+  block.0.wr.bytes=$WR
+  block.0.capacity=21474836480
+
+Domain: 'backup'
+  state.state=5
+  state.reason=1
+  balloon.current=1048576
+  vcpu.current=1
+  net.count=0
+  block.count=0
+`
+
+func TestParseDomainStats(t *testing.T) {
+	domains := parseDomainStats(strings.NewReplacer("$CPU", "1", "$RX", "1", "$WR", "1").Replace(domstatsSample))
+	if len(domains) != 2 || domains[0].name != "web" || domains[1].name != "backup" {
+		t.Fatalf("Unexpected domains: %+v", domains)
+	}
+	if domains[0].stats["block.0.path"] != "/var/lib/libvirt/images/web.qcow2" || domains[1].stats["state.state"] != "5" {
+		t.Errorf("Unexpected stats: %+v", domains)
+	}
+}
+
+func TestVMInfo(t *testing.T) {
+	h := NewHandlerManager(&config.Config{})
+	first := parseDomainStats(strings.NewReplacer("$CPU", "10000000000", "$RX", "1000", "$WR", "0").Replace(domstatsSample))
+	second := parseDomainStats(strings.NewReplacer("$CPU", "11000000000", "$RX", "3000", "$WR", "8192").Replace(domstatsSample))
+
+	// One second of CPU time over two seconds on two vCPUs
+	vm := h.vmInfo(second[0], first[0].stats, 2)
+	if vm["state"] != "running" || vm["vcpus"] != 2 || vm["cpu_percent"] != 25.0 || vm["memory_bytes"] != uint64(2147483648) {
+		t.Errorf("Unexpected VM summary: %v", vm)
+	}
+	memory := vm["memory"].(map[string]interface{})
+	if memory["maximum_bytes"] != uint64(4294967296) || memory["guest_used_percent"] != 75.0 {
+		t.Errorf("Unexpected balloon stats: %v", memory)
+	}
+	nic := vm["interfaces"].([]map[string]interface{})[0]
+	if nic["name"] != "vnet0" || nic["rx_bytes"] != uint64(3000) || nic["rx_bytes_per_sec"] != 1000.0 {
+		t.Errorf("Unexpected interface: %v", nic)
+	}
+	disk := vm["disks"].([]map[string]interface{})[0]
+	if disk["write_bytes_per_sec"] != 4096.0 || disk["capacity"] != uint64(21474836480) {
+		t.Errorf("Unexpected disk: %v", disk)
+	}
+
+	stopped := h.vmInfo(second[1], nil, 2)
+	if stopped["state"] != "shutoff" || stopped["cpu_percent"] != nil || len(stopped["disks"].([]map[string]interface{})) != 0 {
+		t.Errorf("Unexpected stopped VM: %v", stopped)
+	}
+}
@@ -0,0 +1,421 @@
+package handlers
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// blockClassPath has one entry per block device and partition, with device-mapper
+// details under dm/ for mapped devices
+var blockClassPath = "/sys/class/block"
+
+// udevDataPath holds the properties udev probed for each block device, including the
+// filesystem type that marks a LUKS container which is not open
+var udevDataPath = "/run/udev/data"
+
+// Thin pool usage thresholds; a full thin pool fails writes in every volume it backs
+const (
+	thinPoolWarnPercent     = 80.0
+	thinPoolCriticalPercent = 90.0
+)
+
+// encryptionTypes are the dm-crypt UUID prefixes (CRYPT-<type>-...) of encrypted mappings
+var encryptionTypes = map[string]bool{"LUKS1": true, "LUKS2": true, "PLAIN": true, "BITLK": true, "TCRYPT": true}
+
+// LVM report fields for each object type
+var (
+	lvmPVFields = []string{"pv_name", "vg_name", "pv_size", "pv_free", "pv_attr"}
+	lvmVGFields = []string{"vg_name", "vg_size", "vg_free"}
+	lvmLVFields = []string{"lv_name", "vg_name", "lv_size", "lv_attr", "segtype", "pool_lv", "origin", "data_percent", "metadata_percent"}
+)
+
+// physicalVolume is an LVM PV
+type physicalVolume struct {
+	Name        string `json:"name"`
+	SizeBytes   uint64 `json:"size_bytes"`
+	FreeBytes   uint64 `json:"free_bytes"`
+	Missing     bool   `json:"missing,omitempty"`
+	EncryptedOn string `json:"encrypted_on,omitempty"`
+}
+
+// logicalVolume is an LVM LV; thin pools also report what they back
+type logicalVolume struct {
+	Name             string   `json:"name"`
+	Path             string   `json:"path"`
+	Type             string   `json:"type"`
+	SizeBytes        uint64   `json:"size_bytes"`
+	Active           bool     `json:"active"`
+	MountPoint       string   `json:"mount_point,omitempty"`
+	Pool             string   `json:"pool,omitempty"`
+	Origin           string   `json:"origin,omitempty"`
+	DataPercent      *float64 `json:"data_percent,omitempty"`
+	MetadataPercent  *float64 `json:"metadata_percent,omitempty"`
+	ThinVolumes      int      `json:"thin_volumes,omitempty"`
+	ProvisionedBytes uint64   `json:"provisioned_bytes,omitempty"`
+}
+
+// volumeGroup is an LVM VG with its PVs and LVs
+type volumeGroup struct {
+	Name            string           `json:"name"`
+	SizeBytes       uint64           `json:"size_bytes"`
+	FreeBytes       uint64           `json:"free_bytes"`
+	UsedPercent     float64          `json:"used_percent"`
+	PhysicalVolumes []physicalVolume `json:"physical_volumes"`
+	LogicalVolumes  []logicalVolume  `json:"logical_volumes"`
+}
+
+// encryptedDevice is a LUKS (or other dm-crypt) container, open or not
+type encryptedDevice struct {
+	Device        string `json:"device"`
+	Type          string `json:"type"`
+	Open          bool   `json:"open"`
+	Mapping       string `json:"mapping,omitempty"`
+	MappingDevice string `json:"mapping_device,omitempty"`
+	SizeBytes     uint64 `json:"size_bytes,omitempty"`
+	MountPoint    string `json:"mount_point,omitempty"`
+	VolumeGroup   string `json:"volume_group,omitempty"`
+}
+
+// blockDevice is the sysfs view of a block device or partition
+type blockDevice struct {
+	Name      string
+	SizeBytes uint64
+	DMName    string
+	DMUUID    string
+	Slaves    []string
+	FsType    string
+}
+
+// HandleGetVolumeLayout reports how storage is stacked: LVM physical volumes, volume
+// groups, and logical volumes with thin pool usage, and the LUKS containers beneath them
+func (h *HandlerManager) HandleGetVolumeLayout(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	result := map[string]interface{}{}
+	mounted := map[string]string{}
+	if mounts, err := readMountTable(mountsPath); err == nil {
+		for _, m := range mounts {
+			if _, ok := mounted[m.Device]; !ok {
+				mounted[m.Device] = m.MountPoint
+			}
+		}
+	}
+
+	devices, err := readBlockDevices()
+	if err != nil {
+		result["encryption_error"] = fmt.Sprintf("Failed to read %s: %v", blockClassPath, err)
+	}
+	encrypted := encryptedDevices(devices, mounted)
+
+	groups := []volumeGroup{}
+	if h.caps.LVM {
+		groups, err = h.collectLVM(ctx, mounted)
+		if err != nil {
+			// lvm reads every block device and its lock directory
+			result["lvm_error"] = fmt.Sprintf("lvm failed: %v (run as root or add lvm to --sudo-allowlist)", err)
+		}
+	}
+	linkEncryptedPVs(groups, encrypted, devices)
+
+	status := statusHealthy
+	warnings := []string{}
+	raise := func(s string) {
+		if s == statusCritical || status == statusHealthy {
+			status = s
+		}
+	}
+	for _, vg := range groups {
+		for _, pv := range vg.PhysicalVolumes {
+			if pv.Missing {
+				raise(statusCritical)
+				warnings = append(warnings, fmt.Sprintf("Volume group %s is missing physical volume %s", vg.Name, pv.Name))
+			}
+		}
+		for _, lv := range vg.LogicalVolumes {
+			if lv.Type != "thin-pool" {
+				continue
+			}
+			for _, usage := range []struct {
+				kind    string
+				percent *float64
+			}{{"data", lv.DataPercent}, {"metadata", lv.MetadataPercent}} {
+				if usage.percent == nil || *usage.percent < thinPoolWarnPercent {
+					continue
+				}
+				if *usage.percent >= thinPoolCriticalPercent {
+					raise(statusCritical)
+				} else {
+					raise(statusWarning)
+				}
+				warnings = append(warnings, fmt.Sprintf("Thin pool %s/%s %s is %.1f%% full; writes to its volumes fail when it fills", vg.Name, lv.Name, usage.kind, *usage.percent))
+			}
+			if lv.ProvisionedBytes > lv.SizeBytes {
+				warnings = append(warnings, fmt.Sprintf("Thin pool %s/%s is overcommitted: its volumes can grow to %.0f%% of its size", vg.Name, lv.Name, float64(lv.ProvisionedBytes)/float64(lv.SizeBytes)*100))
+			}
+		}
+	}
+
+	result["status"] = status
+	result["volume_groups"] = groups
+	result["encrypted_devices"] = encrypted
+	result["warnings"] = warnings
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// collectLVM builds the volume groups from the pvs, vgs, and lvs reports
+func (h *HandlerManager) collectLVM(ctx context.Context, mounted map[string]string) ([]volumeGroup, error) {
+	pvs, err := h.lvmReport(ctx, "pvs", "pv", lvmPVFields)
+	if err != nil {
+		return []volumeGroup{}, err
+	}
+	vgs, err := h.lvmReport(ctx, "vgs", "vg", lvmVGFields)
+	if err != nil {
+		return []volumeGroup{}, err
+	}
+	lvs, err := h.lvmReport(ctx, "lvs", "lv", lvmLVFields)
+	if err != nil {
+		return []volumeGroup{}, err
+	}
+	return buildVolumeGroups(pvs, vgs, lvs, mounted), nil
+}
+
+// lvmReport runs an lvm reporting command with byte units and returns its rows
+func (h *HandlerManager) lvmReport(ctx context.Context, command, object string, fields []string) ([]map[string]string, error) {
+	out, err := h.privilegedCommand(ctx, "lvm", command, "--reportformat", "json", "--units", "b", "--nosuffix",
+		"-o", strings.Join(fields, ",")).Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", command, err)
+	}
+	return parseLVMReport(out, object)
+}
+
+// parseLVMReport parses `--reportformat json` output, e.g. {"report": [{"pv": [{...}]}]}
+func parseLVMReport(data []byte, object string) ([]map[string]string, error) {
+	var report struct {
+		Report []map[string][]map[string]string `json:"report"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+	var rows []map[string]string
+	for _, r := range report.Report {
+		rows = append(rows, r[object]...)
+	}
+	return rows, nil
+}
+
+// buildVolumeGroups nests the PV and LV rows under their VGs. PVs without a VG are
+// listed under a group with an empty name.
+func buildVolumeGroups(pvs, vgs, lvs []map[string]string, mounted map[string]string) []volumeGroup {
+	groups := []volumeGroup{}
+	index := map[string]int{}
+	group := func(name string) *volumeGroup {
+		i, ok := index[name]
+		if !ok {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, volumeGroup{Name: name, PhysicalVolumes: []physicalVolume{}, LogicalVolumes: []logicalVolume{}})
+		}
+		return &groups[i]
+	}
+
+	for _, row := range vgs {
+		vg := group(row["vg_name"])
+		vg.SizeBytes = parseLVMSize(row["vg_size"])
+		vg.FreeBytes = parseLVMSize(row["vg_free"])
+		if vg.SizeBytes > 0 {
+			vg.UsedPercent = round2(float64(vg.SizeBytes-vg.FreeBytes) / float64(vg.SizeBytes) * 100)
+		}
+	}
+	for _, row := range pvs {
+		vg := group(row["vg_name"])
+		vg.PhysicalVolumes = append(vg.PhysicalVolumes, physicalVolume{
+			Name:      row["pv_name"],
+			SizeBytes: parseLVMSize(row["pv_size"]),
+			FreeBytes: parseLVMSize(row["pv_free"]),
+			// The third attribute is m for a PV that is missing
+			Missing: attrAt(row["pv_attr"], 2) == 'm',
+		})
+	}
+	for _, row := range lvs {
+		vgName, name := row["vg_name"], row["lv_name"]
+		lv := logicalVolume{
+			Name:      name,
+			Path:      "/dev/" + vgName + "/" + name,
+			Type:      row["segtype"],
+			SizeBytes: parseLVMSize(row["lv_size"]),
+			// The fifth attribute is a for an active LV
+			Active:          attrAt(row["lv_attr"], 4) == 'a',
+			Pool:            row["pool_lv"],
+			Origin:          row["origin"],
+			DataPercent:     parseLVMPercent(row["data_percent"]),
+			MetadataPercent: parseLVMPercent(row["metadata_percent"]),
+		}
+		for _, dev := range []string{lvMapperPath(vgName, name), lv.Path} {
+			if mp, ok := mounted[dev]; ok {
+				lv.MountPoint = mp
+				break
+			}
+		}
+		vg := group(vgName)
+		vg.LogicalVolumes = append(vg.LogicalVolumes, lv)
+	}
+
+	// Sum what each thin pool has promised to its volumes
+	for g := range groups {
+		lvs := groups[g].LogicalVolumes
+		for i := range lvs {
+			if lvs[i].Type != "thin-pool" {
+				continue
+			}
+			for _, thin := range lvs {
+				if thin.Pool == lvs[i].Name && thin.Type == "thin" {
+					lvs[i].ThinVolumes++
+					lvs[i].ProvisionedBytes += thin.SizeBytes
+				}
+			}
+		}
+	}
+	return groups
+}
+
+// lvMapperPath is the device-mapper node of an LV; dashes in names are doubled
+func lvMapperPath(vg, lv string) string {
+	return "/dev/mapper/" + strings.ReplaceAll(vg, "-", "--") + "-" + strings.ReplaceAll(lv, "-", "--")
+}
+
+// attrAt returns one character of an LVM attribute string, or 0 when it is too short
+func attrAt(attr string, i int) byte {
+	if len(attr) <= i {
+		return 0
+	}
+	return attr[i]
+}
+
+// parseLVMSize parses a size reported with --units b --nosuffix
+func parseLVMSize(s string) uint64 {
+	n, _ := strconv.ParseUint(strings.TrimSpace(s), 10, 64)
+	return n
+}
+
+// parseLVMPercent parses a data_percent or metadata_percent field, which is empty for
+// LVs that are not thin pools or snapshots
+func parseLVMPercent(s string) *float64 {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return nil
+	}
+	return &v
+}
+
+// readBlockDevices lists the block devices and partitions in sysfs with their
+// device-mapper names, backing devices, and udev filesystem type
+func readBlockDevices() ([]blockDevice, error) {
+	entries, err := os.ReadDir(blockClassPath)
+	if err != nil {
+		return nil, err
+	}
+	var devices []blockDevice
+	for _, e := range entries {
+		dir := filepath.Join(blockClassPath, e.Name())
+		d := blockDevice{Name: e.Name()}
+		if v, err := readTrimmed(filepath.Join(dir, "size")); err == nil {
+			sectors, _ := strconv.ParseUint(v, 10, 64)
+			d.SizeBytes = sectors * 512
+		}
+		d.DMName, _ = readTrimmed(filepath.Join(dir, "dm", "name"))
+		d.DMUUID, _ = readTrimmed(filepath.Join(dir, "dm", "uuid"))
+		if slaves, err := os.ReadDir(filepath.Join(dir, "slaves")); err == nil {
+			for _, s := range slaves {
+				d.Slaves = append(d.Slaves, s.Name())
+			}
+		}
+		if dev, err := readTrimmed(filepath.Join(dir, "dev")); err == nil {
+			d.FsType = udevFsType(filepath.Join(udevDataPath, "b"+dev))
+		}
+		devices = append(devices, d)
+	}
+	return devices, nil
+}
+
+// udevFsType returns ID_FS_TYPE from a udev database entry
+func udevFsType(path string) string {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if v, ok := strings.CutPrefix(line, "E:ID_FS_TYPE="); ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// encryptedDevices lists open dm-crypt mappings with their backing devices, then LUKS
+// containers that udev found but nobody has opened
+func encryptedDevices(devices []blockDevice, mounted map[string]string) []encryptedDevice {
+	encrypted := []encryptedDevice{}
+	opened := map[string]bool{}
+	for _, d := range devices {
+		kind, _, _ := strings.Cut(strings.TrimPrefix(d.DMUUID, "CRYPT-"), "-")
+		if !strings.HasPrefix(d.DMUUID, "CRYPT-") || !encryptionTypes[kind] {
+			continue
+		}
+		e := encryptedDevice{
+			Type:          strings.ToLower(kind),
+			Open:          true,
+			Mapping:       d.DMName,
+			MappingDevice: "/dev/mapper/" + d.DMName,
+			SizeBytes:     d.SizeBytes,
+			MountPoint:    mounted["/dev/mapper/"+d.DMName],
+		}
+		if len(d.Slaves) > 0 {
+			e.Device = "/dev/" + d.Slaves[0]
+			opened[d.Slaves[0]] = true
+		}
+		encrypted = append(encrypted, e)
+	}
+	for _, d := range devices {
+		if d.FsType == "crypto_LUKS" && !opened[d.Name] {
+			encrypted = append(encrypted, encryptedDevice{Device: "/dev/" + d.Name, Type: "luks", SizeBytes: d.SizeBytes})
+		}
+	}
+	sort.Slice(encrypted, func(i, j int) bool { return encrypted[i].Device < encrypted[j].Device })
+	return encrypted
+}
+
+// linkEncryptedPVs records which PVs sit on an open encrypted mapping, on either side.
+// lvm names a PV by its mapper path or its dm-N kernel name.
+func linkEncryptedPVs(groups []volumeGroup, encrypted []encryptedDevice, devices []blockDevice) {
+	kernelNames := map[string]string{}
+	for _, d := range devices {
+		if d.DMName != "" {
+			kernelNames["/dev/"+d.Name] = "/dev/mapper/" + d.DMName
+		}
+	}
+	for g := range groups {
+		for p := range groups[g].PhysicalVolumes {
+			pv := &groups[g].PhysicalVolumes[p]
+			path := cmp.Or(kernelNames[pv.Name], pv.Name)
+			for e := range encrypted {
+				if encrypted[e].Open && encrypted[e].MappingDevice == path {
+					pv.EncryptedOn = encrypted[e].Device
+					encrypted[e].VolumeGroup = groups[g].Name
+				}
+			}
+		}
+	}
+}
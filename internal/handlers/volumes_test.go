@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestBuildVolumeGroups(t *testing.T) {
+	pvs, err := parseLVMReport([]byte(`{"report": [{"pv": [
+		{"pv_name": "/dev/mapper/cryptdata", "vg_name": "data-vg", "pv_size": "1000", "pv_free": "100", "pv_attr": "a--"},
+		{"pv_name": "[unknown]", "vg_name": "data-vg", "pv_size": "1000", "pv_free": "1000", "pv_attr": "a-m"},
+		{"pv_name": "/dev/sdc", "vg_name": "", "pv_size": "500", "pv_free": "500", "pv_attr": "---"}
+	]}]}`), "pv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	vgs, _ := parseLVMReport([]byte(`{"report": [{"vg": [{"vg_name": "data-vg", "vg_size": "2000", "vg_free": "500"}]}]}`), "vg")
+	lvs, _ := parseLVMReport([]byte(`{"report": [{"lv": [
+		{"lv_name": "pool", "vg_name": "data-vg", "lv_size": "1000", "lv_attr": "twi-aotz--", "segtype": "thin-pool", "pool_lv": "", "origin": "", "data_percent": "85.50", "metadata_percent": "12.00"},
+		{"lv_name": "vm-disk", "vg_name": "data-vg", "lv_size": "800", "lv_attr": "Vwi-aotz--", "segtype": "thin", "pool_lv": "pool", "origin": "", "data_percent": "40.00", "metadata_percent": ""},
+		{"lv_name": "backup", "vg_name": "data-vg", "lv_size": "600", "lv_attr": "Vwi---tz--", "segtype": "thin", "pool_lv": "pool", "origin": "", "data_percent": "", "metadata_percent": ""},
+		{"lv_name": "home", "vg_name": "data-vg", "lv_size": "500", "lv_attr": "-wi-ao----", "segtype": "linear", "pool_lv": "", "origin": "", "data_percent": "", "metadata_percent": ""}
+	]}]}`), "lv")
+
+	groups := buildVolumeGroups(pvs, vgs, lvs, map[string]string{"/dev/mapper/data--vg-home": "/home"})
+	if len(groups) != 2 || groups[0].Name != "data-vg" || groups[1].Name != "" {
+		t.Fatalf("Expected data-vg and unassigned PVs, got %+v", groups)
+	}
+	vg := groups[0]
+	if vg.UsedPercent != 75 || len(vg.PhysicalVolumes) != 2 || !vg.PhysicalVolumes[1].Missing || vg.PhysicalVolumes[0].Missing {
+		t.Errorf("Unexpected volume group: %+v", vg)
+	}
+	pool := vg.LogicalVolumes[0]
+	if pool.ThinVolumes != 2 || pool.ProvisionedBytes != 1400 || pool.DataPercent == nil || *pool.DataPercent != 85.5 {
+		t.Errorf("Unexpected thin pool: %+v", pool)
+	}
+	if backup := vg.LogicalVolumes[2]; backup.Active || backup.DataPercent != nil {
+		t.Errorf("Expected an inactive thin volume without usage, got %+v", backup)
+	}
+	if home := vg.LogicalVolumes[3]; home.MountPoint != "/home" || home.Path != "/dev/data-vg/home" || !home.Active {
+		t.Errorf("Unexpected home volume: %+v", home)
+	}
+
+	if _, err := parseLVMReport([]byte("  No volume groups found"), "vg"); err == nil {
+		t.Error("Expected an error for non-JSON output")
+	}
+}
+
+// writeBlockDevice creates a fake /sys/class/block entry
+func writeBlockDevice(t *testing.T, name string, files map[string]string, slaves ...string) {
+	t.Helper()
+	dir := filepath.Join(blockClassPath, name)
+	for _, sub := range []string{"dm", "slaves"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, s := range slaves {
+		if err := os.MkdirAll(filepath.Join(dir, "slaves", s), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for path, value := range files {
+		if err := os.WriteFile(filepath.Join(dir, path), []byte(value+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestHandleGetVolumeLayout(t *testing.T) {
+	dir := t.TempDir()
+	origBlock, origUdev, origMounts := blockClassPath, udevDataPath, mountsPath
+	defer func() { blockClassPath, udevDataPath, mountsPath = origBlock, origUdev, origMounts }()
+	blockClassPath = filepath.Join(dir, "block")
+	udevDataPath = filepath.Join(dir, "udev")
+	mountsPath = filepath.Join(dir, "mounts")
+
+	writeBlockDevice(t, "sda2", map[string]string{"size": "2048", "dev": "8:2"})
+	writeBlockDevice(t, "sdb1", map[string]string{"size": "4096", "dev": "8:17"})
+	writeBlockDevice(t, "dm-0", map[string]string{"size": "2000", "dev": "253:0", "dm/name": "cryptroot", "dm/uuid": "CRYPT-LUKS2-0a1b2c3d-cryptroot"}, "sda2")
+	writeBlockDevice(t, "dm-1", map[string]string{"size": "1000", "dev": "253:1", "dm/name": "vg0-root", "dm/uuid": "LVM-abcdef"}, "dm-0")
+	if err := os.MkdirAll(udevDataPath, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for dev, fstype := range map[string]string{"b8:2": "crypto_LUKS", "b8:17": "crypto_LUKS", "b253:1": "ext4"} {
+		if err := os.WriteFile(filepath.Join(udevDataPath, dev), []byte("S:disk/by-uuid/x\nE:ID_FS_TYPE="+fstype+"\nE:ID_FS_USAGE=crypto\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(mountsPath, []byte("/dev/mapper/vg0-root / ext4 rw,relatime 0 0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewHandlerManager(&config.Config{})
+	h.caps.LVM = false
+	res, err := h.HandleGetVolumeLayout(context.Background(), mcp.CallToolRequest{})
+	checkToolResult(t, res, err, []string{"status", "volume_groups", "encrypted_devices", "warnings"})
+	var result struct {
+		Status    string            `json:"status"`
+		Encrypted []encryptedDevice `json:"encrypted_devices"`
+	}
+	if err := json.Unmarshal([]byte(resultText(res)), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != statusHealthy || len(result.Encrypted) != 2 {
+		t.Fatalf("Expected two encrypted devices, got %+v", result)
+	}
+	if e := result.Encrypted[0]; e.Device != "/dev/sda2" || !e.Open || e.Type != "luks2" || e.MappingDevice != "/dev/mapper/cryptroot" || e.SizeBytes != 2000*512 {
+		t.Errorf("Unexpected open container: %+v", e)
+	}
+	if e := result.Encrypted[1]; e.Device != "/dev/sdb1" || e.Open || e.Mapping != "" {
+		t.Errorf("Unexpected locked container: %+v", e)
+	}
+
+	devices, err := readBlockDevices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	groups := []volumeGroup{{Name: "vg0", PhysicalVolumes: []physicalVolume{{Name: "/dev/dm-0"}}}}
+	encrypted := encryptedDevices(devices, nil)
+	linkEncryptedPVs(groups, encrypted, devices)
+	if groups[0].PhysicalVolumes[0].EncryptedOn != "/dev/sda2" || encrypted[0].VolumeGroup != "vg0" {
+		t.Errorf("Expected the PV linked to its LUKS container, got %+v and %+v", groups[0].PhysicalVolumes[0], encrypted[0])
+	}
+}
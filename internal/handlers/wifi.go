@@ -0,0 +1,376 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// procNetWireless is the kernel's wireless extension statistics file
+const procNetWireless = "/proc/net/wireless"
+
+// maxLinkQuality is the link quality scale cfg80211 drivers report in /proc/net/wireless
+const maxLinkQuality = 70
+
+// Signal rating constants.
+const (
+	signalExcellent = "excellent"
+	signalGood      = "good"
+	signalFair      = "fair"
+	signalPoor      = "poor"
+)
+
+// wifiStatus is the link state of a single wireless interface. Pointer fields are nil
+// when no source reports the value.
+type wifiStatus struct {
+	Interface          string   `json:"interface"`
+	Connected          bool     `json:"connected"`
+	SSID               string   `json:"ssid,omitempty"`
+	BSSID              string   `json:"bssid,omitempty"`
+	FrequencyMHz       int      `json:"frequency_mhz,omitempty"`
+	Channel            int      `json:"channel,omitempty"`
+	Band               string   `json:"band,omitempty"`
+	ChannelWidth       string   `json:"channel_width,omitempty"`
+	SignalDBm          *float64 `json:"signal_dbm,omitempty"`
+	SignalRating       string   `json:"signal_rating,omitempty"`
+	NoiseDBm           *float64 `json:"noise_dbm,omitempty"`
+	LinkQuality        *float64 `json:"link_quality,omitempty"`
+	LinkQualityPercent *float64 `json:"link_quality_percent,omitempty"`
+	RxBitrateMbps      *float64 `json:"rx_bitrate_mbps,omitempty"`
+	TxBitrateMbps      *float64 `json:"tx_bitrate_mbps,omitempty"`
+	TxPowerDBm         *float64 `json:"tx_power_dbm,omitempty"`
+	TxPackets          *uint64  `json:"tx_packets,omitempty"`
+	TxRetries          *uint64  `json:"tx_retries,omitempty"`
+	TxFailed           *uint64  `json:"tx_failed,omitempty"`
+	TxRetryPercent     *float64 `json:"tx_retry_percent,omitempty"`
+	BeaconLoss         *uint64  `json:"beacon_loss,omitempty"`
+	ConnectedSeconds   *uint64  `json:"connected_seconds,omitempty"`
+}
+
+// procWirelessStats is a row of /proc/net/wireless
+type procWirelessStats struct {
+	Quality       float64
+	Level         float64
+	Noise         float64
+	Retries       uint64
+	MissedBeacons uint64
+}
+
+// HandleGetWifiStatus returns SSID, signal, link quality, bitrate, channel, and retries
+// for wireless interfaces
+func (h *HandlerManager) HandleGetWifiStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var filter string
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if i, ok := args["interface"].(string); ok && i != "" {
+			filter = i
+		}
+	}
+
+	procStats := map[string]procWirelessStats{}
+	if data, err := os.ReadFile(procNetWireless); err == nil {
+		procStats = parseProcNetWireless(string(data))
+	}
+
+	// iw lists interfaces that are down or disconnected, which /proc/net/wireless omits
+	statuses := map[string]*wifiStatus{}
+	if h.caps.Iw {
+		if out, err := runIw(ctx, "dev"); err == nil {
+			for _, s := range parseIwDev(out) {
+				statuses[s.Interface] = s
+			}
+		}
+	}
+	for name := range procStats {
+		if _, ok := statuses[name]; !ok {
+			statuses[name] = &wifiStatus{Interface: name}
+		}
+	}
+	for _, name := range listSysfsWireless("/sys/class/net") {
+		if _, ok := statuses[name]; !ok {
+			statuses[name] = &wifiStatus{Interface: name}
+		}
+	}
+
+	interfaces := []*wifiStatus{}
+	for name, s := range statuses {
+		if filter != "" && name != filter {
+			continue
+		}
+		if h.caps.Iw {
+			if out, err := runIw(ctx, "dev", name, "link"); err == nil {
+				applyIwLink(s, out)
+			}
+			if s.Connected {
+				if out, err := runIw(ctx, "dev", name, "station", "dump"); err == nil {
+					applyIwStationDump(s, out)
+				}
+			}
+		}
+		if ps, ok := procStats[name]; ok {
+			applyProcWireless(s, ps)
+		}
+		finishWifiStatus(s)
+		interfaces = append(interfaces, s)
+	}
+	sort.Slice(interfaces, func(i, j int) bool { return interfaces[i].Interface < interfaces[j].Interface })
+
+	if filter != "" && len(interfaces) == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("Wireless interface not found: %s", filter)), nil
+	}
+
+	result := map[string]interface{}{
+		"interfaces":   interfaces,
+		"total":        len(interfaces),
+		"iw_available": h.caps.Iw,
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// parseProcNetWireless parses /proc/net/wireless rows such as
+// "wlan0: 0000   57.  -53.  -256        0      0      0      0     12        0"
+func parseProcNetWireless(data string) map[string]procWirelessStats {
+	stats := make(map[string]procWirelessStats)
+	for _, line := range strings.Split(data, "\n") {
+		name, rest, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(rest)
+		// status, quality, level, noise, nwid, crypt, frag, retry, misc, missed beacon
+		if len(fields) < 10 {
+			continue
+		}
+		quality, err := strconv.ParseFloat(strings.TrimSuffix(fields[1], "."), 64)
+		if err != nil {
+			continue
+		}
+		s := procWirelessStats{Quality: quality}
+		s.Level, _ = strconv.ParseFloat(strings.TrimSuffix(fields[2], "."), 64)
+		s.Noise, _ = strconv.ParseFloat(strings.TrimSuffix(fields[3], "."), 64)
+		s.Retries, _ = strconv.ParseUint(fields[7], 10, 64)
+		s.MissedBeacons, _ = strconv.ParseUint(fields[9], 10, 64)
+		stats[strings.TrimSpace(name)] = s
+	}
+	return stats
+}
+
+// parseIwDev parses `iw dev` output into one status per managed-mode interface
+func parseIwDev(output string) []*wifiStatus {
+	var statuses []*wifiStatus
+	var current *wifiStatus
+	managed := false
+	flush := func() {
+		if current != nil && managed {
+			statuses = append(statuses, current)
+		}
+	}
+	for _, line := range strings.Split(output, "\n") {
+		field := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(field, "Interface "):
+			flush()
+			current = &wifiStatus{Interface: strings.TrimPrefix(field, "Interface ")}
+			managed = false
+		case current == nil:
+			continue
+		case field == "type managed":
+			managed = true
+		case strings.HasPrefix(field, "ssid "):
+			current.SSID = strings.TrimPrefix(field, "ssid ")
+		case strings.HasPrefix(field, "channel "):
+			// channel 36 (5180 MHz), width: 80 MHz, center1: 5210 MHz
+			if _, width, ok := strings.Cut(field, "width: "); ok {
+				current.ChannelWidth, _, _ = strings.Cut(width, ",")
+			}
+		case strings.HasPrefix(field, "txpower "):
+			current.TxPowerDBm = leadingFloat(strings.TrimPrefix(field, "txpower "))
+		}
+	}
+	flush()
+	return statuses
+}
+
+// applyIwLink fills connection details from `iw dev <if> link`
+func applyIwLink(s *wifiStatus, output string) {
+	for _, line := range strings.Split(output, "\n") {
+		field := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(field, "Connected to "):
+			s.Connected = true
+			s.BSSID, _, _ = strings.Cut(strings.TrimPrefix(field, "Connected to "), " ")
+		case strings.HasPrefix(field, "SSID: "):
+			s.SSID = strings.TrimPrefix(field, "SSID: ")
+		case strings.HasPrefix(field, "freq: "):
+			if f := leadingFloat(strings.TrimPrefix(field, "freq: ")); f != nil {
+				s.FrequencyMHz = int(*f)
+			}
+		case strings.HasPrefix(field, "signal: "):
+			s.SignalDBm = leadingFloat(strings.TrimPrefix(field, "signal: "))
+		case strings.HasPrefix(field, "rx bitrate: "):
+			s.RxBitrateMbps = leadingFloat(strings.TrimPrefix(field, "rx bitrate: "))
+		case strings.HasPrefix(field, "tx bitrate: "):
+			s.TxBitrateMbps = leadingFloat(strings.TrimPrefix(field, "tx bitrate: "))
+		}
+	}
+	if !s.Connected {
+		s.SSID = ""
+	}
+}
+
+// applyIwStationDump fills retry and beacon counters from `iw dev <if> station dump`
+func applyIwStationDump(s *wifiStatus, output string) {
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "tx packets":
+			s.TxPackets = leadingUint(value)
+		case "tx retries":
+			s.TxRetries = leadingUint(value)
+		case "tx failed":
+			s.TxFailed = leadingUint(value)
+		case "beacon loss":
+			s.BeaconLoss = leadingUint(value)
+		case "connected time":
+			s.ConnectedSeconds = leadingUint(value)
+		}
+	}
+}
+
+// applyProcWireless fills link quality and fallback signal values from /proc/net/wireless
+func applyProcWireless(s *wifiStatus, ps procWirelessStats) {
+	quality := ps.Quality
+	percent := quality / maxLinkQuality * 100
+	if percent > 100 {
+		percent = 100
+	}
+	s.LinkQuality = &quality
+	s.LinkQualityPercent = &percent
+
+	// A level of 0 means the driver does not report it; -256 is an unset noise floor
+	if s.SignalDBm == nil && ps.Level < 0 {
+		level := ps.Level
+		s.SignalDBm = &level
+	}
+	if ps.Noise < 0 && ps.Noise > -256 {
+		noise := ps.Noise
+		s.NoiseDBm = &noise
+	}
+	if s.TxRetries == nil {
+		retries := ps.Retries
+		s.TxRetries = &retries
+	}
+	if s.BeaconLoss == nil {
+		missed := ps.MissedBeacons
+		s.BeaconLoss = &missed
+	}
+	if quality > 0 {
+		s.Connected = true
+	}
+}
+
+// finishWifiStatus derives the channel, band, signal rating, and retry rate
+func finishWifiStatus(s *wifiStatus) {
+	if s.FrequencyMHz > 0 {
+		s.Channel, s.Band = frequencyToChannel(s.FrequencyMHz)
+	}
+	if s.SignalDBm != nil {
+		s.SignalRating = rateSignal(*s.SignalDBm)
+	}
+	if s.TxPackets != nil && s.TxRetries != nil && *s.TxPackets > 0 {
+		pct := float64(*s.TxRetries) / float64(*s.TxPackets) * 100
+		s.TxRetryPercent = &pct
+	}
+}
+
+// frequencyToChannel converts a center frequency in MHz to an IEEE 802.11 channel and band
+func frequencyToChannel(freq int) (int, string) {
+	switch {
+	case freq == 2484:
+		return 14, "2.4GHz"
+	case freq >= 2412 && freq < 2484:
+		return (freq - 2407) / 5, "2.4GHz"
+	case freq == 5935:
+		return 2, "6GHz"
+	case freq > 5950 && freq <= 7125:
+		return (freq - 5950) / 5, "6GHz"
+	case freq >= 5000 && freq < 5950:
+		return (freq - 5000) / 5, "5GHz"
+	default:
+		return 0, ""
+	}
+}
+
+// rateSignal buckets a received signal strength in dBm
+func rateSignal(dbm float64) string {
+	switch {
+	case dbm >= -50:
+		return signalExcellent
+	case dbm >= -60:
+		return signalGood
+	case dbm >= -70:
+		return signalFair
+	default:
+		return signalPoor
+	}
+}
+
+// listSysfsWireless returns interfaces under a sysfs net root that have a wireless directory
+func listSysfsWireless(root string) []string {
+	matches, _ := filepath.Glob(filepath.Join(root, "*", "wireless"))
+	names := make([]string, 0, len(matches))
+	for _, m := range matches {
+		names = append(names, filepath.Base(filepath.Dir(m)))
+	}
+	return names
+}
+
+// leadingFloat parses the first whitespace-separated token of a value such as "-53 dBm"
+func leadingFloat(value string) *float64 {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return nil
+	}
+	v, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return nil
+	}
+	return &v
+}
+
+// leadingUint parses the first whitespace-separated token of a value such as "3600 seconds"
+func leadingUint(value string) *uint64 {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return nil
+	}
+	v, err := strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &v
+}
+
+// runIw runs iw with a C locale so its output can be parsed
+func runIw(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "iw", args...) //nolint:gosec // G204: callers pass fixed subcommands and kernel-reported interface names
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	out, err := cmd.Output()
+	return string(out), err
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"sysmetrics-mcp/internal/config"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestParseProcNetWireless(t *testing.T) {
+	data := "Inter-| sta-|   Quality        |   Discarded packets               | Missed | WE\n" +
+		" face | tus | link level noise |  nwid  crypt   frag  retry   misc | beacon | 22\n" +
+		"wlan0: 0000   57.  -53.  -256        0      0      0     14      0        3\n"
+
+	stats := parseProcNetWireless(data)
+	s, ok := stats["wlan0"]
+	if !ok || len(stats) != 1 {
+		t.Fatalf("Expected wlan0 stats, got %v", stats)
+	}
+	if s.Quality != 57 || s.Level != -53 || s.Noise != -256 || s.Retries != 14 || s.MissedBeacons != 3 {
+		t.Errorf("Unexpected stats: %+v", s)
+	}
+}
+
+func TestParseIwDev(t *testing.T) {
+	output := `phy#0
+	Unnamed/non-netdev interface
+		wdev 0x2
+		type P2P-device
+	Interface wlan0
+		ifindex 3
+		wdev 0x1
+		addr dc:a6:32:00:00:01
+		ssid HomeNet
+		type managed
+		channel 36 (5180 MHz), width: 80 MHz, center1: 5210 MHz
+		txpower 31.00 dBm
+	Interface ap0
+		ifindex 4
+		type AP
+`
+	statuses := parseIwDev(output)
+	if len(statuses) != 1 {
+		t.Fatalf("Expected 1 managed interface, got %d", len(statuses))
+	}
+	s := statuses[0]
+	if s.Interface != "wlan0" || s.SSID != "HomeNet" || s.ChannelWidth != "80 MHz" || s.TxPowerDBm == nil || *s.TxPowerDBm != 31 {
+		t.Errorf("Unexpected interface: %+v", s)
+	}
+}
+
+func TestApplyIwLinkAndStationDump(t *testing.T) {
+	s := &wifiStatus{Interface: "wlan0"}
+	applyIwLink(s, `Connected to aa:bb:cc:dd:ee:ff (on wlan0)
+	SSID: HomeNet
+	freq: 5180
+	RX: 1234567 bytes (8910 packets)
+	TX: 234567 bytes (1234 packets)
+	signal: -63 dBm
+	rx bitrate: 433.3 MBit/s VHT-MCS 9 80MHz short GI VHT-NSS 1
+	tx bitrate: 390.0 MBit/s VHT-MCS 8 80MHz short GI VHT-NSS 1
+`)
+	applyIwStationDump(s, `Station aa:bb:cc:dd:ee:ff (on wlan0)
+	inactive time:	40 ms
+	tx packets:	1000
+	tx retries:	150
+	tx failed:	2
+	beacon loss:	1
+	connected time:	3600 seconds
+`)
+	finishWifiStatus(s)
+
+	if !s.Connected || s.BSSID != "aa:bb:cc:dd:ee:ff" || s.SSID != "HomeNet" || s.FrequencyMHz != 5180 {
+		t.Errorf("Unexpected link: %+v", s)
+	}
+	if s.Channel != 36 || s.Band != "5GHz" || s.SignalRating != signalFair {
+		t.Errorf("Unexpected derived values: channel=%d band=%s rating=%s", s.Channel, s.Band, s.SignalRating)
+	}
+	if *s.RxBitrateMbps != 433.3 || *s.TxBitrateMbps != 390 || *s.TxRetries != 150 || *s.TxFailed != 2 || *s.ConnectedSeconds != 3600 {
+		t.Errorf("Unexpected counters: %+v", s)
+	}
+	if s.TxRetryPercent == nil || *s.TxRetryPercent != 15 {
+		t.Errorf("Expected 15%% retries, got %v", s.TxRetryPercent)
+	}
+
+	disconnected := &wifiStatus{Interface: "wlan1", SSID: "stale"}
+	applyIwLink(disconnected, "Not connected.\n")
+	if disconnected.Connected || disconnected.SSID != "" {
+		t.Errorf("Expected disconnected interface, got %+v", disconnected)
+	}
+}
+
+func TestApplyProcWireless(t *testing.T) {
+	s := &wifiStatus{Interface: "wlan0"}
+	applyProcWireless(s, procWirelessStats{Quality: 35, Level: -75, Noise: -256, Retries: 4})
+	if !s.Connected || *s.LinkQualityPercent != 50 || *s.SignalDBm != -75 || s.NoiseDBm != nil || *s.TxRetries != 4 {
+		t.Errorf("Unexpected status: %+v", s)
+	}
+
+	// Values from iw take precedence
+	iwSignal := -60.0
+	s = &wifiStatus{Interface: "wlan0", SignalDBm: &iwSignal}
+	applyProcWireless(s, procWirelessStats{Quality: 50, Level: -62, Noise: -90})
+	if *s.SignalDBm != -60 || s.NoiseDBm == nil || *s.NoiseDBm != -90 {
+		t.Errorf("Unexpected status: %+v", s)
+	}
+}
+
+func TestFrequencyToChannel(t *testing.T) {
+	tests := []struct {
+		freq    int
+		channel int
+		band    string
+	}{
+		{2412, 1, "2.4GHz"},
+		{2437, 6, "2.4GHz"},
+		{2484, 14, "2.4GHz"},
+		{5180, 36, "5GHz"},
+		{5825, 165, "5GHz"},
+		{5955, 1, "6GHz"},
+		{900, 0, ""},
+	}
+
+	for _, tt := range tests {
+		channel, band := frequencyToChannel(tt.freq)
+		if channel != tt.channel || band != tt.band {
+			t.Errorf("frequencyToChannel(%d) = %d, %s; expected %d, %s", tt.freq, channel, band, tt.channel, tt.band)
+		}
+	}
+}
+
+func TestRateSignal(t *testing.T) {
+	tests := []struct {
+		dbm      float64
+		expected string
+	}{
+		{-45, signalExcellent},
+		{-55, signalGood},
+		{-67, signalFair},
+		{-80, signalPoor},
+	}
+
+	for _, tt := range tests {
+		if got := rateSignal(tt.dbm); got != tt.expected {
+			t.Errorf("rateSignal(%v) = %s, expected %s", tt.dbm, got, tt.expected)
+		}
+	}
+}
+
+func TestListSysfsWireless(t *testing.T) {
+	root := t.TempDir()
+	writeSysfsFiles(t, filepath.Join(root, "wlan0", "wireless"), map[string]string{"status": "0x0"})
+	writeSysfsFiles(t, filepath.Join(root, "eth0"), map[string]string{"operstate": "up"})
+
+	names := listSysfsWireless(root)
+	sort.Strings(names)
+	if len(names) != 1 || names[0] != "wlan0" {
+		t.Errorf("Expected [wlan0], got %v", names)
+	}
+}
+
+func TestHandleGetWifiStatus(t *testing.T) {
+	h := NewHandlerManager(&config.Config{})
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{},
+		},
+	}
+	res, err := h.HandleGetWifiStatus(context.Background(), req)
+	checkToolResult(t, res, err, []string{"interfaces", "total", "iw_available"})
+
+	req.Params.Arguments = map[string]interface{}{"interface": "nonexistent-wlan"}
+	res, err = h.HandleGetWifiStatus(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if !res.IsError {
+		t.Error("Expected error result for unknown interface")
+	}
+}
@@ -0,0 +1,98 @@
+package history
+
+import (
+	"math"
+	"sort"
+)
+
+// Baseline summarizes the distribution of a series over a reference window
+type Baseline struct {
+	Samples int     `json:"samples"`
+	Mean    float64 `json:"mean"`
+	StdDev  float64 `json:"stddev"`
+	Min     float64 `json:"min"`
+	Max     float64 `json:"max"`
+
+	sorted []float64
+}
+
+// NewBaseline computes the distribution of points
+func NewBaseline(points []Point) Baseline {
+	b := Baseline{Samples: len(points)}
+	if len(points) == 0 {
+		return b
+	}
+
+	b.sorted = make([]float64, len(points))
+	var sum float64
+	for i, p := range points {
+		b.sorted[i] = p.Value
+		sum += p.Value
+	}
+	sort.Float64s(b.sorted)
+	b.Min = b.sorted[0]
+	b.Max = b.sorted[len(b.sorted)-1]
+	b.Mean = sum / float64(len(points))
+
+	var sq float64
+	for _, v := range b.sorted {
+		sq += (v - b.Mean) * (v - b.Mean)
+	}
+	b.StdDev = math.Sqrt(sq / float64(len(points)))
+	return b
+}
+
+// ZScore returns how many standard deviations v lies from the mean. minStdDev floors the
+// deviation so a flat baseline does not turn tiny changes into huge scores.
+func (b Baseline) ZScore(v, minStdDev float64) float64 {
+	sd := b.StdDev
+	if sd < minStdDev {
+		sd = minStdDev
+	}
+	if sd == 0 {
+		return 0
+	}
+	return (v - b.Mean) / sd
+}
+
+// Percentile returns the p-th percentile (0-100) of the baseline using linear interpolation
+func (b Baseline) Percentile(p float64) float64 {
+	n := len(b.sorted)
+	if n == 0 {
+		return 0
+	}
+	rank := p / 100 * float64(n-1)
+	lo := int(math.Floor(rank))
+	hi := int(math.Ceil(rank))
+	if lo < 0 {
+		lo = 0
+	}
+	if hi >= n {
+		hi = n - 1
+	}
+	return b.sorted[lo] + (b.sorted[hi]-b.sorted[lo])*(rank-float64(lo))
+}
+
+// PercentileRank returns the percentage (0-100) of baseline values at or below v
+func (b Baseline) PercentileRank(v float64) float64 {
+	n := len(b.sorted)
+	if n == 0 {
+		return 0
+	}
+	return float64(sort.Search(n, func(i int) bool { return b.sorted[i] > v })) / float64(n) * 100
+}
+
+// Rates converts a cumulative counter into per-second rates between consecutive points,
+// skipping intervals where the counter reset
+func Rates(points []Point) []Point {
+	rates := make([]Point, 0, len(points))
+	for i := 1; i < len(points); i++ {
+		dt := points[i].Time.Sub(points[i-1].Time).Seconds()
+		dv := points[i].Value - points[i-1].Value
+		if dt <= 0 || dv < 0 {
+			continue
+		}
+		rates = append(rates, Point{Time: points[i].Time, Value: dv / dt})
+	}
+	return rates
+}
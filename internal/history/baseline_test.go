@@ -0,0 +1,57 @@
+package history
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBaseline(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	points := make([]Point, 0, 10)
+	for i := 1; i <= 10; i++ {
+		points = append(points, Point{Time: start.Add(time.Duration(i) * time.Minute), Value: float64(i)})
+	}
+	b := NewBaseline(points)
+
+	if b.Samples != 10 || b.Mean != 5.5 || b.Min != 1 || b.Max != 10 {
+		t.Errorf("Unexpected baseline: %+v", b)
+	}
+	if math.Abs(b.StdDev-2.8723) > 0.001 {
+		t.Errorf("Expected stddev 2.872, got %v", b.StdDev)
+	}
+	if got := b.Percentile(50); got != 5.5 {
+		t.Errorf("Percentile(50) = %v, expected 5.5", got)
+	}
+	if got := b.Percentile(100); got != 10 {
+		t.Errorf("Percentile(100) = %v, expected 10", got)
+	}
+	if got := b.PercentileRank(3); got != 30 {
+		t.Errorf("PercentileRank(3) = %v, expected 30", got)
+	}
+	if got := b.ZScore(5.5, 0); got != 0 {
+		t.Errorf("ZScore(mean) = %v, expected 0", got)
+	}
+
+	flat := NewBaseline([]Point{{Value: 2}, {Value: 2}})
+	if got := flat.ZScore(3, 0.5); got != 2 {
+		t.Errorf("Expected the stddev floor to apply to a flat baseline, got z=%v", got)
+	}
+	if got := NewBaseline(nil).Percentile(50); got != 0 {
+		t.Errorf("Expected 0 for an empty baseline, got %v", got)
+	}
+}
+
+func TestRates(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	counter := []Point{
+		{Time: start, Value: 1000},
+		{Time: start.Add(10 * time.Second), Value: 2000},
+		{Time: start.Add(20 * time.Second), Value: 500}, // counter reset
+		{Time: start.Add(30 * time.Second), Value: 1500},
+	}
+	rates := Rates(counter)
+	if len(rates) != 2 || rates[0].Value != 100 || rates[1].Value != 100 {
+		t.Errorf("Unexpected rates: %+v", rates)
+	}
+}
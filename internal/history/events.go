@@ -0,0 +1,172 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Event categories
+const (
+	EventAlert          = "alert"
+	EventThrottle       = "throttle"
+	EventServiceFailure = "service_failure"
+	EventOOMKill        = "oom_kill"
+	EventReboot         = "reboot"
+	EventProcessRestart = "process_restart"
+	EventAction         = "action"
+	EventSynthetic      = "synthetic"
+	EventServiceState   = "service_state"
+)
+
+// EventCategories lists every category, in the order tools document them
+var EventCategories = []string{
+	EventAlert, EventThrottle, EventServiceFailure, EventOOMKill,
+	EventReboot, EventProcessRestart, EventAction, EventSynthetic, EventServiceState,
+}
+
+// Event severities, least severe first
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// severityRank orders severities for minimum-severity filters
+var severityRank = map[string]int{SeverityInfo: 0, SeverityWarning: 1, SeverityCritical: 2}
+
+// Event is one notable occurrence in the event journal. Subject names what it happened to,
+// e.g. a unit, process, or tool.
+type Event struct {
+	Time     time.Time         `json:"time"`
+	Category string            `json:"category"`
+	Severity string            `json:"severity"`
+	Subject  string            `json:"subject,omitempty"`
+	Message  string            `json:"message"`
+	Details  map[string]string `json:"details,omitempty"`
+}
+
+// EventQuery selects events in a time range, optionally limited to some categories and
+// a minimum severity. A positive Limit keeps only the newest events.
+type EventQuery struct {
+	Since       time.Time
+	Until       time.Time
+	Categories  []string
+	MinSeverity string
+	Limit       int
+}
+
+const eventSchema = `
+CREATE TABLE IF NOT EXISTS events (
+	ts       INTEGER NOT NULL,
+	category TEXT    NOT NULL,
+	severity TEXT    NOT NULL,
+	subject  TEXT    NOT NULL DEFAULT '',
+	message  TEXT    NOT NULL,
+	details  TEXT    NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS events_ts ON events (ts);
+`
+
+// WriteEvent appends an event to the journal. Events share the samples' retention.
+func (s *Store) WriteEvent(ctx context.Context, e Event) error {
+	details := ""
+	if len(e.Details) > 0 {
+		data, err := json.Marshal(e.Details)
+		if err != nil {
+			return fmt.Errorf("failed to encode event details: %w", err)
+		}
+		details = string(data)
+	}
+	if _, err := s.db.ExecContext(ctx, "INSERT INTO events (ts, category, severity, subject, message, details) VALUES (?, ?, ?, ?, ?, ?)",
+		e.Time.Unix(), e.Category, e.Severity, e.Subject, e.Message, details); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+	return nil
+}
+
+// Events returns the events matching q, newest first
+func (s *Store) Events(ctx context.Context, q EventQuery) ([]Event, error) {
+	where, args := eventFilter(q)
+	query := "SELECT ts, category, severity, subject, message, details FROM events WHERE " + where + " ORDER BY ts DESC, rowid DESC"
+	if q.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", q.Limit)
+	}
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []Event{}
+	for rows.Next() {
+		var ts int64
+		var e Event
+		var details string
+		if err := rows.Scan(&ts, &e.Category, &e.Severity, &e.Subject, &e.Message, &details); err != nil {
+			return nil, fmt.Errorf("failed to read events: %w", err)
+		}
+		e.Time = time.Unix(ts, 0).UTC()
+		if details != "" {
+			if err := json.Unmarshal([]byte(details), &e.Details); err != nil {
+				return nil, fmt.Errorf("failed to decode event details: %w", err)
+			}
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// EventCounts returns how many events matching q (ignoring its limit) each category has
+func (s *Store) EventCounts(ctx context.Context, q EventQuery) (map[string]int, error) {
+	where, args := eventFilter(q)
+	rows, err := s.db.QueryContext(ctx, "SELECT category, COUNT(*) FROM events WHERE "+where+" GROUP BY category", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count events: %w", err)
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var category string
+		var n int
+		if err := rows.Scan(&category, &n); err != nil {
+			return nil, fmt.Errorf("failed to count events: %w", err)
+		}
+		counts[category] = n
+	}
+	return counts, rows.Err()
+}
+
+// eventFilter builds the WHERE clause and arguments for q
+func eventFilter(q EventQuery) (string, []interface{}) {
+	clauses := []string{"ts >= ?", "ts <= ?"}
+	args := []interface{}{q.Since.Unix(), q.Until.Unix()}
+	if len(q.Categories) > 0 {
+		clauses = append(clauses, "category IN (?"+strings.Repeat(", ?", len(q.Categories)-1)+")")
+		for _, c := range q.Categories {
+			args = append(args, c)
+		}
+	}
+	if floor, ok := severityRank[q.MinSeverity]; ok && floor > 0 {
+		var severities []string
+		for sev, rank := range severityRank {
+			if rank >= floor {
+				severities = append(severities, sev)
+			}
+		}
+		clauses = append(clauses, "severity IN (?"+strings.Repeat(", ?", len(severities)-1)+")")
+		for _, sev := range severities {
+			args = append(args, sev)
+		}
+	}
+	return strings.Join(clauses, " AND "), args
+}
+
+// ValidSeverity reports whether s is one of the event severities
+func ValidSeverity(s string) bool {
+	_, ok := severityRank[s]
+	return ok
+}
@@ -0,0 +1,64 @@
+package history
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStoreEvents(t *testing.T) {
+	s := openTestStore(t, 24*time.Hour)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second).UTC()
+
+	events := []Event{
+		{Time: now.Add(-3 * time.Hour), Category: EventReboot, Severity: SeverityInfo, Message: "System booted"},
+		{Time: now.Add(-2 * time.Hour), Category: EventAlert, Severity: SeverityWarning, Subject: "memory", Message: "High memory usage", Details: map[string]string{"value": "91.2"}},
+		{Time: now.Add(-time.Hour), Category: EventOOMKill, Severity: SeverityCritical, Message: "1 process killed"},
+		{Time: now, Category: EventAlert, Severity: SeverityInfo, Subject: "memory", Message: "Resolved"},
+	}
+	for _, e := range events {
+		if err := s.WriteEvent(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := s.Events(ctx, EventQuery{Since: now.Add(-24 * time.Hour), Until: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 4 || !got[0].Time.Equal(now) || !reflect.DeepEqual(got[2].Details, map[string]string{"value": "91.2"}) {
+		t.Errorf("Expected all events newest first, got %+v", got)
+	}
+
+	got, err = s.Events(ctx, EventQuery{Since: now.Add(-24 * time.Hour), Until: now, Categories: []string{EventAlert}, MinSeverity: SeverityWarning})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Subject != "memory" || got[0].Severity != SeverityWarning {
+		t.Errorf("Expected the warning alert, got %+v", got)
+	}
+
+	got, err = s.Events(ctx, EventQuery{Since: now.Add(-24 * time.Hour), Until: now, Limit: 2})
+	if err != nil || len(got) != 2 || got[1].Category != EventOOMKill {
+		t.Errorf("Expected the 2 newest events, got %+v (err %v)", got, err)
+	}
+
+	counts, err := s.EventCounts(ctx, EventQuery{Since: now.Add(-24 * time.Hour), Until: now, Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]int{EventAlert: 2, EventReboot: 1, EventOOMKill: 1}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("EventCounts = %v, want %v", counts, want)
+	}
+
+	// Events expire with the rest of the history
+	if _, err := s.Prune(ctx, now.Add(23*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	got, err = s.Events(ctx, EventQuery{Since: now.Add(-24 * time.Hour), Until: now})
+	if err != nil || len(got) != 2 {
+		t.Errorf("Expected 2 events after pruning, got %+v (err %v)", got, err)
+	}
+}
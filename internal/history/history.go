@@ -0,0 +1,408 @@
+// Package history persists sampled metrics in an embedded SQLite database and answers
+// bucketed aggregation queries over them. Older samples are downsampled into coarser
+// rollup tiers (see Tier) so the file stays small on SD cards.
+package history
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	// Pure-Go SQLite driver, so CGO_ENABLED=0 builds keep working
+	_ "modernc.org/sqlite"
+)
+
+// DefaultRetention is how long history is kept when no retention is configured
+const DefaultRetention = 90 * 24 * time.Hour
+
+// pruneInterval bounds how often samples are downsampled and expired during writes
+const pruneInterval = time.Hour
+
+// Sample is a single metric observation. Labels distinguish series of the same metric,
+// e.g. {"mount": "/"} for disk usage.
+type Sample struct {
+	Time   time.Time
+	Metric string
+	Labels map[string]string
+	Value  float64
+}
+
+// Writer receives batches of samples from the background sampler
+type Writer interface {
+	Write(ctx context.Context, samples []Sample) error
+}
+
+// Bucket is the aggregate of one series over one time bucket
+type Bucket struct {
+	Start time.Time `json:"start"`
+	Min   float64   `json:"min"`
+	Max   float64   `json:"max"`
+	Avg   float64   `json:"avg"`
+	Count int       `json:"count"`
+}
+
+// Series is the bucketed history of one metric and label set
+type Series struct {
+	Metric  string   `json:"metric"`
+	Labels  string   `json:"labels"`
+	Buckets []Bucket `json:"buckets"`
+}
+
+// MetricInfo summarizes one stored series
+type MetricInfo struct {
+	Metric  string    `json:"metric"`
+	Labels  string    `json:"labels"`
+	Samples int       `json:"samples"`
+	First   time.Time `json:"first"`
+	Last    time.Time `json:"last"`
+}
+
+// Query selects a metric, an optional label set, a time range, and a bucket width
+type Query struct {
+	Metric string
+	Labels string
+	Since  time.Time
+	Until  time.Time
+	Bucket time.Duration
+}
+
+// Snapshot is one stored health snapshot; Data is opaque JSON owned by the caller
+type Snapshot struct {
+	Time time.Time
+	Data []byte
+}
+
+// Store is a SQLite-backed sample store with tiered retention
+type Store struct {
+	db        *sql.DB
+	path      string
+	retention time.Duration
+	tiers     []Tier
+
+	mu        sync.Mutex
+	lastPrune time.Time
+}
+
+const schema = `
+CREATE TABLE IF NOT EXISTS samples (
+	ts     INTEGER NOT NULL,
+	metric TEXT    NOT NULL,
+	labels TEXT    NOT NULL DEFAULT '',
+	value  REAL    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS samples_metric_ts ON samples (metric, ts);
+CREATE INDEX IF NOT EXISTS samples_ts ON samples (ts);
+CREATE TABLE IF NOT EXISTS rollups (
+	resolution INTEGER NOT NULL,
+	ts         INTEGER NOT NULL,
+	metric     TEXT    NOT NULL,
+	labels     TEXT    NOT NULL DEFAULT '',
+	min        REAL    NOT NULL,
+	max        REAL    NOT NULL,
+	sum        REAL    NOT NULL,
+	count      INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS rollups_metric_ts ON rollups (metric, ts);
+CREATE INDEX IF NOT EXISTS rollups_resolution_ts ON rollups (resolution, ts);
+CREATE TABLE IF NOT EXISTS snapshots (
+	ts   INTEGER NOT NULL,
+	data TEXT    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS snapshots_ts ON snapshots (ts);
+`
+
+// Open opens (creating if needed) the database at path. retention is how long any history
+// is kept, split into tiers as TiersFor describes; a non-positive retention selects
+// DefaultRetention.
+func Open(path string, retention time.Duration) (*Store, error) {
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+
+	// WAL keeps readers (query_metrics) from blocking the sampler and survives crashes.
+	// Incremental auto-vacuum lets pruning give pages back; it only takes effect on new files.
+	dsn := "file:" + path + "?_pragma=auto_vacuum(INCREMENTAL)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=synchronous(NORMAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database %s: %w", path, err)
+	}
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(schema + eventSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to initialize history database %s: %w", path, err)
+	}
+
+	s := &Store{db: db, path: path, retention: retention, tiers: TiersFor(retention)}
+	if _, err := s.Prune(context.Background(), time.Now()); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Path returns the database file path
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Retention returns how long history is kept
+func (s *Store) Retention() time.Duration {
+	return s.retention
+}
+
+// Tiers returns the resolutions history is kept at, finest first
+func (s *Store) Tiers() []Tier {
+	return append([]Tier{}, s.tiers...)
+}
+
+// Write stores a batch of samples in one transaction and prunes expired samples at
+// most once per pruneInterval
+func (s *Store) Write(ctx context.Context, samples []Sample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin history write: %w", err)
+	}
+	stmt, err := tx.PrepareContext(ctx, "INSERT INTO samples (ts, metric, labels, value) VALUES (?, ?, ?, ?)")
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to prepare history write: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, sample := range samples {
+		if _, err := stmt.ExecContext(ctx, sample.Time.Unix(), sample.Metric, FormatLabels(sample.Labels), sample.Value); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to write sample %s: %w", sample.Metric, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit history write: %w", err)
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	due := now.Sub(s.lastPrune) >= pruneInterval
+	s.mu.Unlock()
+	if due {
+		if _, err := s.Prune(ctx, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Prune downsamples each tier's expired rows into the next tier, deletes history,
+// snapshots, and events older than the retention period, and returns how many rows
+// were removed (samples and rollups folded into coarser rollups count as removed)
+func (s *Store) Prune(ctx context.Context, now time.Time) (int64, error) {
+	removed, err := s.downsample(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM snapshots WHERE ts < ?", now.Add(-s.retention).Unix()); err != nil {
+		return 0, fmt.Errorf("failed to prune snapshots: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM events WHERE ts < ?", now.Add(-s.retention).Unix()); err != nil {
+		return 0, fmt.Errorf("failed to prune events: %w", err)
+	}
+	// Hand freed pages back to the file system; a no-op on files created without auto-vacuum
+	if _, err := s.db.ExecContext(ctx, "PRAGMA incremental_vacuum"); err != nil {
+		return 0, fmt.Errorf("failed to vacuum history: %w", err)
+	}
+
+	s.mu.Lock()
+	s.lastPrune = now
+	s.mu.Unlock()
+	return removed, nil
+}
+
+// Metrics lists the stored series with their sample counts (including the samples folded
+// into rollups) and time span
+func (s *Store) Metrics(ctx context.Context) ([]MetricInfo, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT metric, labels, SUM(count), MIN(ts), MAX(ts) FROM "+allRows+" GROUP BY metric, labels ORDER BY metric, labels")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list history metrics: %w", err)
+	}
+	defer rows.Close()
+
+	metrics := []MetricInfo{}
+	for rows.Next() {
+		var info MetricInfo
+		var first, last int64
+		if err := rows.Scan(&info.Metric, &info.Labels, &info.Samples, &first, &last); err != nil {
+			return nil, fmt.Errorf("failed to read history metrics: %w", err)
+		}
+		info.First = time.Unix(first, 0).UTC()
+		info.Last = time.Unix(last, 0).UTC()
+		metrics = append(metrics, info)
+	}
+	return metrics, rows.Err()
+}
+
+// Aggregate returns min/max/avg per bucket for every series of the queried metric. Buckets
+// over downsampled history are no finer than the rollups they are built from.
+func (s *Store) Aggregate(ctx context.Context, q Query) ([]Series, error) {
+	bucket := int64(q.Bucket / time.Second)
+	if bucket < 1 {
+		bucket = 1
+	}
+
+	sqlQuery := "SELECT labels, (ts / ?) * ? AS bucket, MIN(min), MAX(max), SUM(sum) / SUM(count), SUM(count) " +
+		"FROM " + allRows + " WHERE metric = ? AND ts >= ? AND ts <= ?"
+	args := []interface{}{bucket, bucket, q.Metric, q.Since.Unix(), q.Until.Unix()}
+	if q.Labels != "" {
+		sqlQuery += " AND labels = ?"
+		args = append(args, q.Labels)
+	}
+	sqlQuery += " GROUP BY labels, bucket ORDER BY labels, bucket"
+
+	rows, err := s.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history for %s: %w", q.Metric, err)
+	}
+	defer rows.Close()
+
+	series := []Series{}
+	for rows.Next() {
+		var labels string
+		var start int64
+		var b Bucket
+		if err := rows.Scan(&labels, &start, &b.Min, &b.Max, &b.Avg, &b.Count); err != nil {
+			return nil, fmt.Errorf("failed to read history for %s: %w", q.Metric, err)
+		}
+		b.Start = time.Unix(start, 0).UTC()
+
+		if len(series) == 0 || series[len(series)-1].Labels != labels {
+			series = append(series, Series{Metric: q.Metric, Labels: labels})
+		}
+		last := &series[len(series)-1]
+		last.Buckets = append(last.Buckets, b)
+	}
+	return series, rows.Err()
+}
+
+// FormatLabels renders labels in a canonical, sorted "key=value,key=value" form
+func FormatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+"="+labels[k])
+	}
+	return strings.Join(parts, ",")
+}
+
+// ParseLabels parses the FormatLabels form (or any ordering of it) into a map
+func ParseLabels(s string) (map[string]string, error) {
+	labels := map[string]string{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(part, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			return nil, fmt.Errorf("invalid label %q (must be key=value)", part)
+		}
+		labels[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return labels, nil
+}
+
+// Point is one stored sample of a series
+type Point struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// RawSeries is the unaggregated history of one metric and label set
+type RawSeries struct {
+	Metric string
+	Labels string
+	Points []Point
+}
+
+// Raw returns every sample in the time range, grouped by series and ordered by time.
+// Downsampled history contributes one point per rollup, its average at the rollup's start.
+// An empty metric selects all metrics.
+func (s *Store) Raw(ctx context.Context, metric string, since, until time.Time) ([]RawSeries, error) {
+	sqlQuery := "SELECT metric, labels, ts, sum / count FROM " + allRows + " WHERE ts >= ? AND ts <= ?"
+	args := []interface{}{since.Unix(), until.Unix()}
+	if metric != "" {
+		sqlQuery += " AND metric = ?"
+		args = append(args, metric)
+	}
+	sqlQuery += " ORDER BY metric, labels, ts"
+
+	rows, err := s.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	defer rows.Close()
+
+	series := []RawSeries{}
+	for rows.Next() {
+		var m, labels string
+		var ts int64
+		var value float64
+		if err := rows.Scan(&m, &labels, &ts, &value); err != nil {
+			return nil, fmt.Errorf("failed to read history: %w", err)
+		}
+		if len(series) == 0 || series[len(series)-1].Metric != m || series[len(series)-1].Labels != labels {
+			series = append(series, RawSeries{Metric: m, Labels: labels})
+		}
+		last := &series[len(series)-1]
+		last.Points = append(last.Points, Point{Time: time.Unix(ts, 0).UTC(), Value: value})
+	}
+	return series, rows.Err()
+}
+
+// WriteSnapshot stores one health snapshot. Snapshots share the samples' retention.
+func (s *Store) WriteSnapshot(ctx context.Context, t time.Time, data []byte) error {
+	if _, err := s.db.ExecContext(ctx, "INSERT INTO snapshots (ts, data) VALUES (?, ?)", t.Unix(), string(data)); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// Snapshots returns the snapshots in the time range, oldest first
+func (s *Store) Snapshots(ctx context.Context, since, until time.Time) ([]Snapshot, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT ts, data FROM snapshots WHERE ts >= ? AND ts <= ? ORDER BY ts", since.Unix(), until.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	snapshots := []Snapshot{}
+	for rows.Next() {
+		var ts int64
+		var data string
+		if err := rows.Scan(&ts, &data); err != nil {
+			return nil, fmt.Errorf("failed to read snapshots: %w", err)
+		}
+		snapshots = append(snapshots, Snapshot{Time: time.Unix(ts, 0).UTC(), Data: []byte(data)})
+	}
+	return snapshots, rows.Err()
+}
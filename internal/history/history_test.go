@@ -0,0 +1,153 @@
+package history
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func openTestStore(t *testing.T, retention time.Duration) *Store {
+	t.Helper()
+	s, err := Open(filepath.Join(t.TempDir(), "history.db"), retention)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func TestStoreAggregate(t *testing.T) {
+	s := openTestStore(t, 0)
+	ctx := context.Background()
+	base := time.Now().Truncate(time.Hour)
+
+	samples := []Sample{
+		{Time: base, Metric: "cpu_percent", Value: 10},
+		{Time: base.Add(30 * time.Second), Metric: "cpu_percent", Value: 30},
+		{Time: base.Add(5 * time.Minute), Metric: "cpu_percent", Value: 50},
+		{Time: base, Metric: "disk_used_percent", Labels: map[string]string{"mount": "/"}, Value: 40},
+		{Time: base, Metric: "disk_used_percent", Labels: map[string]string{"mount": "/home"}, Value: 70},
+	}
+	if err := s.Write(ctx, samples); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	series, err := s.Aggregate(ctx, Query{Metric: "cpu_percent", Since: base.Add(-time.Minute), Until: base.Add(time.Hour), Bucket: time.Minute})
+	if err != nil {
+		t.Fatalf("Aggregate failed: %v", err)
+	}
+	if len(series) != 1 || len(series[0].Buckets) != 2 {
+		t.Fatalf("Expected 1 series with 2 buckets, got %+v", series)
+	}
+	first := series[0].Buckets[0]
+	if first.Min != 10 || first.Max != 30 || first.Avg != 20 || first.Count != 2 || !first.Start.Equal(base) {
+		t.Errorf("Unexpected first bucket: %+v", first)
+	}
+
+	series, err = s.Aggregate(ctx, Query{Metric: "disk_used_percent", Labels: "mount=/home", Since: base.Add(-time.Minute), Until: base.Add(time.Hour), Bucket: time.Hour})
+	if err != nil {
+		t.Fatalf("Aggregate failed: %v", err)
+	}
+	if len(series) != 1 || series[0].Labels != "mount=/home" || series[0].Buckets[0].Avg != 70 {
+		t.Errorf("Expected only the /home series, got %+v", series)
+	}
+
+	metrics, err := s.Metrics(ctx)
+	if err != nil {
+		t.Fatalf("Metrics failed: %v", err)
+	}
+	if len(metrics) != 3 || metrics[0].Metric != "cpu_percent" || metrics[0].Samples != 3 {
+		t.Errorf("Unexpected metrics listing: %+v", metrics)
+	}
+}
+
+func TestStorePrune(t *testing.T) {
+	s := openTestStore(t, time.Hour)
+	ctx := context.Background()
+	now := time.Now()
+
+	if err := s.Write(ctx, []Sample{
+		{Time: now.Add(-2 * time.Hour), Metric: "load1", Value: 1},
+		{Time: now, Metric: "load1", Value: 2},
+	}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	removed, err := s.Prune(ctx, now)
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("Expected 1 expired sample removed, got %d", removed)
+	}
+}
+
+func TestStoreSnapshots(t *testing.T) {
+	s := openTestStore(t, time.Hour)
+	ctx := context.Background()
+	now := time.Now()
+
+	for i, data := range []string{`{"n":1}`, `{"n":2}`, `{"n":3}`} {
+		if err := s.WriteSnapshot(ctx, now.Add(time.Duration(i-2)*time.Hour), []byte(data)); err != nil {
+			t.Fatalf("WriteSnapshot failed: %v", err)
+		}
+	}
+
+	snapshots, err := s.Snapshots(ctx, now.Add(-90*time.Minute), now)
+	if err != nil {
+		t.Fatalf("Snapshots failed: %v", err)
+	}
+	if len(snapshots) != 2 || string(snapshots[0].Data) != `{"n":2}` || string(snapshots[1].Data) != `{"n":3}` {
+		t.Errorf("Expected the last two snapshots oldest first, got %+v", snapshots)
+	}
+
+	if _, err := s.Prune(ctx, now); err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	snapshots, err = s.Snapshots(ctx, now.Add(-3*time.Hour), now)
+	if err != nil || len(snapshots) != 2 {
+		t.Errorf("Expected the expired snapshot pruned, got %+v (err %v)", snapshots, err)
+	}
+}
+
+func TestStoreSurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	ctx := context.Background()
+
+	s, err := Open(path, 0)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if err := s.Write(ctx, []Sample{{Time: time.Now(), Metric: "memory_used_percent", Value: 42}}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	_ = s.Close()
+
+	s, err = Open(path, 0)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	defer s.Close()
+	metrics, err := s.Metrics(ctx)
+	if err != nil || len(metrics) != 1 {
+		t.Errorf("Expected the sample to survive a reopen, got %+v (err %v)", metrics, err)
+	}
+}
+
+func TestLabels(t *testing.T) {
+	if got := FormatLabels(map[string]string{"mount": "/", "device": "sda1"}); got != "device=sda1,mount=/" {
+		t.Errorf("FormatLabels = %q", got)
+	}
+	parsed, err := ParseLabels(" mount=/ , device=sda1")
+	if err != nil {
+		t.Fatalf("ParseLabels failed: %v", err)
+	}
+	if !reflect.DeepEqual(parsed, map[string]string{"mount": "/", "device": "sda1"}) {
+		t.Errorf("ParseLabels = %v", parsed)
+	}
+	if _, err := ParseLabels("mount"); err == nil {
+		t.Error("Expected an error for a label without '='")
+	}
+}
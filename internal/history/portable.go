@@ -0,0 +1,123 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Row is one stored row in portable form: a raw sample (Resolution 0, with Value) or a
+// rollup of Resolution seconds (with Min, Max, Sum, and Count)
+type Row struct {
+	Resolution int64   `json:"resolution,omitempty"`
+	Time       int64   `json:"ts"`
+	Metric     string  `json:"metric"`
+	Labels     string  `json:"labels,omitempty"`
+	Value      float64 `json:"value,omitempty"`
+	Min        float64 `json:"min,omitempty"`
+	Max        float64 `json:"max,omitempty"`
+	Sum        float64 `json:"sum,omitempty"`
+	Count      int64   `json:"count,omitempty"`
+}
+
+// ExportRows calls fn for every raw sample and rollup, oldest first within each table
+func (s *Store) ExportRows(ctx context.Context, fn func(Row) error) error {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT 0, ts, metric, labels, value, 0, 0, 0, 0 FROM samples "+
+			"UNION ALL SELECT resolution, ts, metric, labels, 0, min, max, sum, count FROM rollups ORDER BY 1, 2")
+	if err != nil {
+		return fmt.Errorf("failed to export history: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var r Row
+		if err := rows.Scan(&r.Resolution, &r.Time, &r.Metric, &r.Labels, &r.Value, &r.Min, &r.Max, &r.Sum, &r.Count); err != nil {
+			return fmt.Errorf("failed to export history: %w", err)
+		}
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// ImportRows stores rows exported from another store and returns how many were added.
+// Rows already covered by a stored row of the same series at the same or a coarser
+// resolution are skipped, so importing an archive twice changes nothing. Old rows are
+// then downsampled or expired as if they had been written here.
+func (s *Store) ImportRows(ctx context.Context, rows []Row) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin history import: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// A rollup covers [ts, ts+resolution); a raw sample covers only its own second
+	const covered = "SELECT 1 FROM rollups WHERE metric = ?1 AND labels = ?2 AND resolution >= ?3 AND ts <= ?4 AND ts > ?4 - resolution"
+	insertSample, err := tx.PrepareContext(ctx,
+		"INSERT INTO samples (ts, metric, labels, value) SELECT ?4, ?1, ?2, ?5 "+
+			"WHERE NOT EXISTS (SELECT 1 FROM samples WHERE metric = ?1 AND labels = ?2 AND ts = ?4) AND NOT EXISTS ("+covered+")")
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare history import: %w", err)
+	}
+	defer insertSample.Close()
+	insertRollup, err := tx.PrepareContext(ctx,
+		"INSERT INTO rollups (resolution, ts, metric, labels, min, max, sum, count) SELECT ?3, ?4, ?1, ?2, ?5, ?6, ?7, ?8 "+
+			"WHERE NOT EXISTS ("+covered+")")
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare history import: %w", err)
+	}
+	defer insertRollup.Close()
+
+	imported := 0
+	for _, r := range rows {
+		if r.Metric == "" || r.Resolution < 0 || (r.Resolution > 0 && r.Count <= 0) || math.IsNaN(r.Value) {
+			return 0, fmt.Errorf("invalid history row for %q at %d", r.Metric, r.Time)
+		}
+		var n int64
+		if r.Resolution == 0 {
+			res, err := insertSample.ExecContext(ctx, r.Metric, r.Labels, 0, r.Time, r.Value)
+			if err != nil {
+				return 0, fmt.Errorf("failed to import history: %w", err)
+			}
+			n, _ = res.RowsAffected()
+		} else {
+			res, err := insertRollup.ExecContext(ctx, r.Metric, r.Labels, r.Resolution, r.Time, r.Min, r.Max, r.Sum, r.Count)
+			if err != nil {
+				return 0, fmt.Errorf("failed to import history: %w", err)
+			}
+			n, _ = res.RowsAffected()
+		}
+		imported += int(n)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit history import: %w", err)
+	}
+	if _, err := s.Prune(ctx, time.Now()); err != nil {
+		return imported, err
+	}
+	return imported, nil
+}
+
+// ImportSnapshots stores snapshots exported from another store, skipping any taken at the
+// same second as a stored one, and returns how many were added
+func (s *Store) ImportSnapshots(ctx context.Context, snapshots []Snapshot) (int, error) {
+	imported := 0
+	for _, snap := range snapshots {
+		res, err := s.db.ExecContext(ctx,
+			"INSERT INTO snapshots (ts, data) SELECT ?1, ?2 WHERE NOT EXISTS (SELECT 1 FROM snapshots WHERE ts = ?1)",
+			snap.Time.Unix(), string(snap.Data))
+		if err != nil {
+			return imported, fmt.Errorf("failed to import snapshot: %w", err)
+		}
+		n, _ := res.RowsAffected()
+		imported += int(n)
+	}
+	return imported, nil
+}
+
+// AllSnapshots returns every stored snapshot, oldest first
+func (s *Store) AllSnapshots(ctx context.Context) ([]Snapshot, error) {
+	return s.Snapshots(ctx, time.Unix(0, 0), time.Now().AddDate(100, 0, 0))
+}
@@ -0,0 +1,83 @@
+package history
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStoreExportImportRows(t *testing.T) {
+	src := openTestStore(t, 0)
+	ctx := context.Background()
+	now := time.Now().Truncate(5 * time.Minute)
+	dayAgo := now.Add(-30 * time.Hour)
+
+	if err := src.Write(ctx, []Sample{
+		{Time: now, Metric: "cpu_percent", Value: 5},
+		{Time: now, Metric: "disk_percent", Labels: map[string]string{"mount": "/"}, Value: 40},
+		{Time: dayAgo, Metric: "cpu_percent", Value: 10},
+		{Time: dayAgo.Add(30 * time.Second), Metric: "cpu_percent", Value: 20},
+	}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if _, err := src.Prune(ctx, now); err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if err := src.WriteSnapshot(ctx, now, []byte(`{"status":"ok"}`)); err != nil {
+		t.Fatalf("WriteSnapshot failed: %v", err)
+	}
+
+	var rows []Row
+	if err := src.ExportRows(ctx, func(r Row) error {
+		rows = append(rows, r)
+		return nil
+	}); err != nil {
+		t.Fatalf("ExportRows failed: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("Expected 2 raw samples and 1 rollup, got %+v", rows)
+	}
+
+	dst := openTestStore(t, 0)
+	// A sample the destination already has is not duplicated
+	if err := dst.Write(ctx, []Sample{{Time: now, Metric: "cpu_percent", Value: 5}}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	n, err := dst.ImportRows(ctx, rows)
+	if err != nil || n != 2 {
+		t.Fatalf("ImportRows() = %d, %v; want 2 new rows", n, err)
+	}
+	if n, err := dst.ImportRows(ctx, rows); err != nil || n != 0 {
+		t.Errorf("Importing twice added %d rows (err %v), want 0", n, err)
+	}
+	// Raw samples inside a rollup bucket are already covered
+	if n, err := dst.ImportRows(ctx, []Row{{Time: dayAgo.Unix() + 10, Metric: "cpu_percent", Value: 15}}); err != nil || n != 0 {
+		t.Errorf("Importing a sample covered by a rollup added %d rows (err %v), want 0", n, err)
+	}
+	if _, err := dst.ImportRows(ctx, []Row{{Resolution: 60, Time: now.Unix(), Metric: "cpu_percent"}}); err == nil {
+		t.Error("Expected a rollup without samples to be rejected")
+	}
+
+	metrics, err := dst.Metrics(ctx)
+	if err != nil || len(metrics) != 2 {
+		t.Fatalf("Expected 2 metrics after import, got %+v (err %v)", metrics, err)
+	}
+	for _, m := range metrics {
+		if m.Metric == "cpu_percent" && m.Samples != 3 {
+			t.Errorf("Expected 3 cpu_percent samples across tiers, got %d", m.Samples)
+		}
+	}
+
+	snaps, err := src.AllSnapshots(ctx)
+	if err != nil || len(snaps) != 1 {
+		t.Fatalf("AllSnapshots() = %+v, %v", snaps, err)
+	}
+	for range 2 {
+		if _, err := dst.ImportSnapshots(ctx, snaps); err != nil {
+			t.Fatalf("ImportSnapshots failed: %v", err)
+		}
+	}
+	if got, _ := dst.AllSnapshots(ctx); len(got) != 1 || string(got[0].Data) != `{"status":"ok"}` {
+		t.Errorf("Expected the snapshot imported once, got %+v", got)
+	}
+}
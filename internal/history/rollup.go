@@ -0,0 +1,110 @@
+package history
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Tier is one resolution of stored history. The first tier holds raw samples
+// (Resolution 0); each later tier holds rollups of the one before it.
+type Tier struct {
+	Resolution time.Duration
+	Retention  time.Duration
+}
+
+// DefaultTiers keep raw samples for a day, 1-minute rollups for a week, and 5-minute
+// rollups for 90 days
+var DefaultTiers = []Tier{
+	{Resolution: 0, Retention: 24 * time.Hour},
+	{Resolution: time.Minute, Retention: 7 * 24 * time.Hour},
+	{Resolution: 5 * time.Minute, Retention: 90 * 24 * time.Hour},
+}
+
+// allRows reads raw samples and rollups as one relation; a raw sample is a rollup of one
+const allRows = `(SELECT ts, metric, labels, value AS min, value AS max, value AS sum, 1 AS count FROM samples
+	UNION ALL SELECT ts, metric, labels, min, max, sum, count FROM rollups)`
+
+// TiersFor fits DefaultTiers to an overall retention: tiers that would start after it are
+// dropped, and the coarsest remaining tier is kept for the rest of it
+func TiersFor(retention time.Duration) []Tier {
+	var tiers []Tier
+	for _, t := range DefaultTiers {
+		tiers = append(tiers, t)
+		if t.Retention >= retention {
+			break
+		}
+	}
+	tiers[len(tiers)-1].Retention = retention
+	return tiers
+}
+
+// downsample folds each tier's rows past its retention into the next tier and deletes
+// what the last tier no longer keeps, in one transaction. It returns the rows removed.
+func (s *Store) downsample(ctx context.Context, now time.Time) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin history downsampling: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var removed int64
+	for i, tier := range s.tiers {
+		cutoff := now.Add(-tier.Retention).Unix()
+		if i+1 < len(s.tiers) {
+			// Roll up whole buckets only, so no bucket is split between two tiers
+			res := int64(s.tiers[i+1].Resolution / time.Second)
+			cutoff -= cutoff % res
+			if err := rollUp(ctx, tx, tier, res, cutoff); err != nil {
+				return 0, err
+			}
+		}
+		var res sql.Result
+		if tier.Resolution == 0 {
+			res, err = tx.ExecContext(ctx, "DELETE FROM samples WHERE ts < ?", cutoff)
+		} else {
+			res, err = tx.ExecContext(ctx, "DELETE FROM rollups WHERE resolution = ? AND ts < ?", int64(tier.Resolution/time.Second), cutoff)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to prune history: %w", err)
+		}
+		n, _ := res.RowsAffected()
+		removed += n
+	}
+
+	// Rollups left by an earlier, longer retention expire with everything else
+	res, err := tx.ExecContext(ctx, "DELETE FROM rollups WHERE ts < ?", now.Add(-s.retention).Unix())
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune history: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	removed += n
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit history downsampling: %w", err)
+	}
+	return removed, nil
+}
+
+// rollUp aggregates a tier's rows older than cutoff into rollups of res seconds
+func rollUp(ctx context.Context, tx *sql.Tx, tier Tier, res, cutoff int64) error {
+	var err error
+	if tier.Resolution == 0 {
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO rollups (resolution, ts, metric, labels, min, max, sum, count) "+
+				"SELECT ?, (ts / ?) * ?, metric, labels, MIN(value), MAX(value), SUM(value), COUNT(*) "+
+				"FROM samples WHERE ts < ? GROUP BY metric, labels, ts / ?",
+			res, res, res, cutoff, res)
+	} else {
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO rollups (resolution, ts, metric, labels, min, max, sum, count) "+
+				"SELECT ?, (ts / ?) * ?, metric, labels, MIN(min), MAX(max), SUM(sum), SUM(count) "+
+				"FROM rollups WHERE resolution = ? AND ts < ? GROUP BY metric, labels, ts / ?",
+			res, res, res, int64(tier.Resolution/time.Second), cutoff, res)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to downsample history: %w", err)
+	}
+	return nil
+}
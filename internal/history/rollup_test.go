@@ -0,0 +1,89 @@
+package history
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTiersFor(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		retention time.Duration
+		want      []Tier
+	}{
+		{90 * day, DefaultTiers},
+		{12 * time.Hour, []Tier{{0, 12 * time.Hour}}},
+		{3 * day, []Tier{{0, day}, {time.Minute, 3 * day}}},
+		{365 * day, []Tier{{0, day}, {time.Minute, 7 * day}, {5 * time.Minute, 365 * day}}},
+	}
+	for _, tt := range tests {
+		if got := TiersFor(tt.retention); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("TiersFor(%s) = %v, want %v", tt.retention, got, tt.want)
+		}
+	}
+}
+
+func TestStoreDownsample(t *testing.T) {
+	s := openTestStore(t, 0)
+	ctx := context.Background()
+	now := time.Now().Truncate(5 * time.Minute)
+	dayAgo := now.Add(-30 * time.Hour)
+	weekAgo := now.Add(-10 * 24 * time.Hour)
+
+	if err := s.Write(ctx, []Sample{
+		{Time: now, Metric: "cpu_percent", Value: 5},
+		// Past the raw tier: two samples in one minute and one in the next
+		{Time: dayAgo, Metric: "cpu_percent", Value: 10},
+		{Time: dayAgo.Add(30 * time.Second), Metric: "cpu_percent", Value: 20},
+		{Time: dayAgo.Add(time.Minute), Metric: "cpu_percent", Value: 30},
+		// Past the 1-minute tier: three minutes of one 5-minute bucket
+		{Time: weekAgo, Metric: "cpu_percent", Value: 40},
+		{Time: weekAgo.Add(time.Minute), Metric: "cpu_percent", Value: 50},
+		{Time: weekAgo.Add(2 * time.Minute), Metric: "cpu_percent", Value: 60},
+		// Past the retention
+		{Time: now.Add(-100 * 24 * time.Hour), Metric: "cpu_percent", Value: 99},
+	}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if _, err := s.Prune(ctx, now); err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+
+	counts := map[int64]int{}
+	rows, err := s.db.QueryContext(ctx, "SELECT resolution, COUNT(*) FROM rollups GROUP BY resolution")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+		var res int64
+		var n int
+		if err := rows.Scan(&res, &n); err != nil {
+			t.Fatal(err)
+		}
+		counts[res] = n
+	}
+	_ = rows.Close()
+	if !reflect.DeepEqual(counts, map[int64]int{60: 2, 300: 1}) {
+		t.Errorf("Expected two 1-minute rollups and one 5-minute rollup, got %v", counts)
+	}
+
+	series, err := s.Aggregate(ctx, Query{Metric: "cpu_percent", Since: dayAgo.Add(-time.Minute), Until: dayAgo.Add(time.Hour), Bucket: time.Minute})
+	if err != nil || len(series) != 1 || len(series[0].Buckets) != 2 {
+		t.Fatalf("Expected 2 one-minute buckets, got %+v (err %v)", series, err)
+	}
+	if b := series[0].Buckets[0]; b.Min != 10 || b.Max != 20 || b.Avg != 15 || b.Count != 2 {
+		t.Errorf("Unexpected rolled-up bucket: %+v", b)
+	}
+
+	raw, err := s.Raw(ctx, "cpu_percent", weekAgo.Add(-time.Hour), weekAgo.Add(time.Hour))
+	if err != nil || len(raw) != 1 || len(raw[0].Points) != 1 || raw[0].Points[0].Value != 50 {
+		t.Errorf("Expected one averaged point from the 5-minute rollup, got %+v (err %v)", raw, err)
+	}
+
+	metrics, err := s.Metrics(ctx)
+	if err != nil || len(metrics) != 1 || metrics[0].Samples != 7 {
+		t.Errorf("Expected 7 samples counted across tiers, got %+v (err %v)", metrics, err)
+	}
+}
@@ -0,0 +1,122 @@
+package history
+
+import (
+	"math"
+	"time"
+)
+
+// Trend models
+const (
+	TrendLinear      = "linear"
+	TrendExponential = "exponential"
+)
+
+// Trend is a least-squares fit of a series against time. For a linear trend the value at
+// t is Intercept + Slope*s; for an exponential trend it is exp(Intercept + Slope*s), where
+// s is seconds since Origin.
+type Trend struct {
+	Model     string    `json:"model"`
+	Slope     float64   `json:"slope"`
+	Intercept float64   `json:"intercept"`
+	RSquared  float64   `json:"r_squared"`
+	Origin    time.Time `json:"origin"`
+}
+
+// FitLinear fits a straight line to points. It needs at least two points at distinct times.
+func FitLinear(points []Point) (Trend, bool) {
+	return fit(points, TrendLinear)
+}
+
+// FitExponential fits exponential growth to points by a linear fit of the logarithm. It
+// needs at least two points at distinct times and every value positive.
+func FitExponential(points []Point) (Trend, bool) {
+	for _, p := range points {
+		if p.Value <= 0 {
+			return Trend{}, false
+		}
+	}
+	return fit(points, TrendExponential)
+}
+
+// fit is an ordinary least-squares fit of the model's transformed values against time
+func fit(points []Point, model string) (Trend, bool) {
+	if len(points) < 2 {
+		return Trend{}, false
+	}
+	t := Trend{Model: model, Origin: points[0].Time}
+
+	n := float64(len(points))
+	var sx, sy, sxx, sxy float64
+	for _, p := range points {
+		x, y := p.Time.Sub(t.Origin).Seconds(), t.transform(p.Value)
+		sx += x
+		sy += y
+		sxx += x * x
+		sxy += x * y
+	}
+	den := n*sxx - sx*sx
+	if den == 0 {
+		return Trend{}, false
+	}
+	t.Slope = (n*sxy - sx*sy) / den
+	t.Intercept = (sy - t.Slope*sx) / n
+
+	// R² is measured on the original values so linear and exponential fits compare fairly
+	mean := 0.0
+	for _, p := range points {
+		mean += p.Value
+	}
+	mean /= n
+	var ssRes, ssTot float64
+	for _, p := range points {
+		d := p.Value - t.At(p.Time)
+		ssRes += d * d
+		ssTot += (p.Value - mean) * (p.Value - mean)
+	}
+	if ssTot == 0 {
+		// A constant series is fitted exactly
+		t.RSquared = 1
+	} else {
+		t.RSquared = 1 - ssRes/ssTot
+	}
+	return t, true
+}
+
+// transform maps a value onto the scale the model is linear in
+func (t Trend) transform(v float64) float64 {
+	if t.Model == TrendExponential {
+		return math.Log(v)
+	}
+	return v
+}
+
+// At returns the fitted value at ts
+func (t Trend) At(ts time.Time) float64 {
+	y := t.Intercept + t.Slope*ts.Sub(t.Origin).Seconds()
+	if t.Model == TrendExponential {
+		return math.Exp(y)
+	}
+	return y
+}
+
+// RatePerDay returns the fitted change per day at ts
+func (t Trend) RatePerDay(ts time.Time) float64 {
+	const day = 24 * time.Hour
+	return t.At(ts.Add(day)) - t.At(ts)
+}
+
+// When returns the time the trend reaches target. ok is false when the trend is flat or
+// moving away from target.
+func (t Trend) When(target float64) (time.Time, bool) {
+	if t.Slope <= 0 {
+		return time.Time{}, false
+	}
+	if t.Model == TrendExponential && target <= 0 {
+		return time.Time{}, false
+	}
+	secs := (t.transform(target) - t.Intercept) / t.Slope
+	if math.IsInf(secs, 0) || math.IsNaN(secs) || secs > math.MaxInt64/float64(time.Second) {
+		return time.Time{}, false
+	}
+	return t.Origin.Add(time.Duration(secs * float64(time.Second))), true
+}
@@ -0,0 +1,60 @@
+package history
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFitLinear(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	var points []Point
+	for i := 0; i < 10; i++ {
+		points = append(points, Point{Time: start.Add(time.Duration(i) * 24 * time.Hour), Value: 50 + 2*float64(i)})
+	}
+	trend, ok := FitLinear(points)
+	if !ok || math.Abs(trend.RSquared-1) > 1e-9 {
+		t.Fatalf("Expected an exact linear fit, got %+v", trend)
+	}
+	if got := trend.RatePerDay(start); math.Abs(got-2) > 1e-6 {
+		t.Errorf("RatePerDay() = %v, want 2", got)
+	}
+	// 50 + 2/day reaches 90 after 20 days
+	when, ok := trend.When(90)
+	if !ok || math.Abs(when.Sub(start).Hours()-480) > 0.01 {
+		t.Errorf("When(90) = %v, %v; want 20 days after start", when, ok)
+	}
+
+	shrinking, _ := FitLinear([]Point{{Time: start, Value: 60}, {Time: start.Add(time.Hour), Value: 59}})
+	if _, ok := shrinking.When(90); ok {
+		t.Error("Expected a shrinking trend never to reach 90")
+	}
+	if _, ok := FitLinear([]Point{{Time: start, Value: 1}, {Time: start, Value: 2}}); ok {
+		t.Error("Expected points at one instant not to fit")
+	}
+}
+
+func TestFitExponential(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	var points []Point
+	for i := 0; i < 10; i++ {
+		// Doubles every 10 days from 10
+		points = append(points, Point{Time: start.Add(time.Duration(i) * 24 * time.Hour), Value: 10 * math.Pow(2, float64(i)/10)})
+	}
+	exp, ok := FitExponential(points)
+	if !ok || exp.RSquared < 0.9999 {
+		t.Fatalf("Expected an exact exponential fit, got %+v", exp)
+	}
+	when, ok := exp.When(40)
+	if !ok || math.Abs(when.Sub(start).Hours()-480) > 0.01 {
+		t.Errorf("When(40) = %v, %v; want 20 days after start", when, ok)
+	}
+	lin, _ := FitLinear(points)
+	if lin.RSquared >= exp.RSquared {
+		t.Errorf("Expected the exponential fit to beat the linear one: %v vs %v", exp.RSquared, lin.RSquared)
+	}
+
+	if _, ok := FitExponential([]Point{{Time: start, Value: 0}, {Time: start.Add(time.Hour), Value: 1}}); ok {
+		t.Error("Expected a zero value to prevent an exponential fit")
+	}
+}
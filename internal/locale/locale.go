@@ -0,0 +1,194 @@
+// Package locale formats human-readable numbers, byte sizes, and durations for the
+// configured language.
+package locale
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Special locale names.
+const (
+	// Default is used when no locale is configured
+	Default = "en"
+	// Raw keeps Go's formats ("76h12m9s", "1.5 KB") without digit grouping
+	Raw = "raw"
+	// Auto picks the locale from LC_ALL, LC_MESSAGES, or LANG
+	Auto = "auto"
+)
+
+// unitNames holds the singular and plural forms of the duration units, largest first
+type unitNames [4][2]string
+
+// spec describes the formatting conventions of one language
+type spec struct {
+	decimal string
+	group   string
+	byteSym string
+	units   unitNames
+}
+
+var specs = map[string]spec{
+	"en": {".", ",", "B", unitNames{{"day", "days"}, {"hour", "hours"}, {"minute", "minutes"}, {"second", "seconds"}}},
+	"de": {",", ".", "B", unitNames{{"Tag", "Tage"}, {"Stunde", "Stunden"}, {"Minute", "Minuten"}, {"Sekunde", "Sekunden"}}},
+	"es": {",", ".", "B", unitNames{{"día", "días"}, {"hora", "horas"}, {"minuto", "minutos"}, {"segundo", "segundos"}}},
+	"fr": {",", "\u202f", "o", unitNames{{"jour", "jours"}, {"heure", "heures"}, {"minute", "minutes"}, {"seconde", "secondes"}}},
+	"it": {",", ".", "B", unitNames{{"giorno", "giorni"}, {"ora", "ore"}, {"minuto", "minuti"}, {"secondo", "secondi"}}},
+	"nl": {",", ".", "B", unitNames{{"dag", "dagen"}, {"uur", "uur"}, {"minuut", "minuten"}, {"seconde", "seconden"}}},
+	"pt": {",", ".", "B", unitNames{{"dia", "dias"}, {"hora", "horas"}, {"minuto", "minutos"}, {"segundo", "segundos"}}},
+}
+
+// durationUnits are the unit lengths matching unitNames
+var durationUnits = [4]time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second}
+
+// Formatter renders values for one locale. The zero value is not usable; use New.
+type Formatter struct {
+	name string
+	raw  bool
+	spec spec
+}
+
+// Names returns the supported locale names, including Raw and Auto
+func Names() []string {
+	names := make([]string, 0, len(specs)+2)
+	for name := range specs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return append(names, Raw, Auto)
+}
+
+// New returns a Formatter for a locale name. Region and encoding suffixes are ignored
+// ("de_DE.UTF-8" and "de-AT" select "de"), and an empty name selects Default.
+func New(name string) (*Formatter, error) {
+	lang := normalize(name)
+	switch lang {
+	case "":
+		lang = Default
+	case Auto:
+		lang = normalize(envLocale())
+		if _, ok := specs[lang]; !ok {
+			lang = Default
+		}
+	case Raw:
+		return &Formatter{name: Raw, raw: true, spec: specs[Default]}, nil
+	}
+
+	s, ok := specs[lang]
+	if !ok {
+		return nil, fmt.Errorf("unsupported locale: %s (must be one of %s)", name, strings.Join(Names(), ", "))
+	}
+	return &Formatter{name: lang, spec: s}, nil
+}
+
+// normalize reduces a locale identifier to its lowercase language code
+func normalize(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if i := strings.IndexAny(name, "_-.@"); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// envLocale returns the first locale set in the POSIX locale environment variables
+func envLocale() string {
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(key); v != "" && v != "C" && v != "POSIX" {
+			return v
+		}
+	}
+	return ""
+}
+
+// Name returns the resolved locale name
+func (f *Formatter) Name() string {
+	return f.name
+}
+
+// Number formats n with the given number of decimals, grouping thousands
+func (f *Formatter) Number(n float64, decimals int) string {
+	s := strconv.FormatFloat(n, 'f', decimals, 64)
+	if f.raw {
+		return s
+	}
+
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	intPart, fracPart, _ := strings.Cut(s, ".")
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, digit := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteString(f.spec.group)
+		}
+		b.WriteRune(digit)
+	}
+	if fracPart != "" {
+		b.WriteString(f.spec.decimal)
+		b.WriteString(fracPart)
+	}
+	return b.String()
+}
+
+// Bytes formats a byte count with binary (1024) multiples, e.g. "1.5 GB" or "1,5 Go"
+func (f *Formatter) Bytes(bytes uint64) string {
+	const unit = 1024
+	sym := f.spec.byteSym
+	if bytes < unit {
+		return f.Number(float64(bytes), 0) + " " + sym
+	}
+	div, exp := uint64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return f.Number(float64(bytes)/float64(div), 1) + " " + string("KMGTPE"[exp]) + sym
+}
+
+// Duration formats d with its two most significant units, e.g. "3 days 4 hours", or in
+// Go's format for the raw locale. Durations under a second read as "0 seconds".
+func (f *Formatter) Duration(d time.Duration) string {
+	if f.raw {
+		return d.String()
+	}
+	if d < 0 {
+		d = -d
+	}
+
+	var parts []string
+	for i, size := range durationUnits {
+		n := d / size
+		if n == 0 {
+			// Stop at the first gap once a unit has been emitted ("2 days", not "2 days 5 seconds")
+			if len(parts) > 0 {
+				break
+			}
+			continue
+		}
+		d -= n * size
+		parts = append(parts, f.unit(int64(n), i))
+		if len(parts) == 2 {
+			break
+		}
+	}
+	if len(parts) == 0 {
+		return f.unit(0, len(durationUnits)-1)
+	}
+	return strings.Join(parts, " ")
+}
+
+// unit renders a count with the singular or plural name of unit i
+func (f *Formatter) unit(n int64, i int) string {
+	name := f.spec.units[i][1]
+	if n == 1 {
+		name = f.spec.units[i][0]
+	}
+	return f.Number(float64(n), 0) + " " + name
+}
@@ -0,0 +1,112 @@
+package locale
+
+import (
+	"testing"
+	"time"
+)
+
+func mustNew(t *testing.T, name string) *Formatter {
+	t.Helper()
+	f, err := New(name)
+	if err != nil {
+		t.Fatalf("New(%q) failed: %v", name, err)
+	}
+	return f
+}
+
+func TestDuration(t *testing.T) {
+	uptime := 76*time.Hour + 12*time.Minute + 9*time.Second
+	tests := []struct {
+		locale   string
+		d        time.Duration
+		expected string
+	}{
+		{"en", uptime, "3 days 4 hours"},
+		{"en", 25 * time.Hour, "1 day 1 hour"},
+		{"en", 48*time.Hour + 5*time.Second, "2 days"},
+		{"en", 90 * time.Second, "1 minute 30 seconds"},
+		{"en", 300 * time.Millisecond, "0 seconds"},
+		{"en", 1500 * 24 * time.Hour, "1,500 days"},
+		{"de", uptime, "3 Tage 4 Stunden"},
+		{"fr", time.Hour, "1 heure"},
+		{"nl", 2 * time.Hour, "2 uur"},
+		{"raw", uptime, "76h12m9s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale+"_"+tt.expected, func(t *testing.T) {
+			if got := mustNew(t, tt.locale).Duration(tt.d); got != tt.expected {
+				t.Errorf("Duration(%s) = %q, expected %q", tt.d, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNumberAndBytes(t *testing.T) {
+	tests := []struct {
+		locale        string
+		number        float64
+		decimals      int
+		expectedNum   string
+		bytes         uint64
+		expectedBytes string
+	}{
+		{"en", 1234567.891, 2, "1,234,567.89", 1536, "1.5 KB"},
+		{"en", -1000, 0, "-1,000", 500, "500 B"},
+		{"de", 1234567.891, 1, "1.234.567,9", 1536, "1,5 KB"},
+		{"fr", 1234.5, 1, "1\u202f234,5", 3 * 1024 * 1024 * 1024, "3,0 Go"},
+		{"raw", 1234567.891, 2, "1234567.89", 1536, "1.5 KB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale+"_"+tt.expectedNum, func(t *testing.T) {
+			f := mustNew(t, tt.locale)
+			if got := f.Number(tt.number, tt.decimals); got != tt.expectedNum {
+				t.Errorf("Number(%v) = %q, expected %q", tt.number, got, tt.expectedNum)
+			}
+			if got := f.Bytes(tt.bytes); got != tt.expectedBytes {
+				t.Errorf("Bytes(%d) = %q, expected %q", tt.bytes, got, tt.expectedBytes)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		wantErr  bool
+	}{
+		{"", "en", false},
+		{"de_DE.UTF-8", "de", false},
+		{"pt-BR", "pt", false},
+		{"RAW", "raw", false},
+		{"klingon", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := New(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if err == nil && f.Name() != tt.expected {
+				t.Errorf("New(%q).Name() = %q, expected %q", tt.name, f.Name(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestNewAuto(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "it_IT.UTF-8")
+	if got := mustNew(t, Auto).Name(); got != "it" {
+		t.Errorf("Expected auto to pick it from LANG, got %q", got)
+	}
+
+	t.Setenv("LANG", "C")
+	if got := mustNew(t, Auto).Name(); got != Default {
+		t.Errorf("Expected auto to fall back to %s, got %q", Default, got)
+	}
+}
@@ -0,0 +1,89 @@
+// Package logging builds the server's structured (slog) logger. Stdout carries the MCP
+// protocol, so logs go to stderr or to a log file.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// DefaultLevel is used when no level is configured
+const DefaultLevel = "info"
+
+// Options configures the logger
+type Options struct {
+	// Level is one of debug, info, warn, or error
+	Level string
+	// File, if set, receives JSON logs (appended) instead of stderr receiving text logs
+	File string
+	// Attrs are attached to every record, e.g. the server version
+	Attrs []slog.Attr
+}
+
+// Logger is a slog.Logger that owns its output file
+type Logger struct {
+	*slog.Logger
+	file *os.File
+}
+
+// ParseLevel parses a level name; an empty name selects DefaultLevel
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("unknown log level: %s (must be debug, info, warn, or error)", name)
+}
+
+// New creates a logger that writes text to stderr, or JSON to opts.File when set
+func New(opts Options) (*Logger, error) {
+	level, err := ParseLevel(opts.Level)
+	if err != nil {
+		return nil, err
+	}
+	handlerOpts := &slog.HandlerOptions{Level: level}
+
+	l := &Logger{}
+	var handler slog.Handler
+	if opts.File != "" {
+		l.file, err = os.OpenFile(opts.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file %s: %w", opts.File, err)
+		}
+		handler = slog.NewJSONHandler(l.file, handlerOpts)
+	} else {
+		handler = slog.NewTextHandler(os.Stderr, handlerOpts)
+	}
+	if len(opts.Attrs) > 0 {
+		handler = handler.WithAttrs(opts.Attrs)
+	}
+	l.Logger = slog.New(handler)
+	return l, nil
+}
+
+// NewWriter creates a text logger for w at the given level, for tests and embedding
+func NewWriter(w io.Writer, level slog.Level) *slog.Logger {
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: level}))
+}
+
+// Discard returns a logger that drops every record
+func Discard() *slog.Logger {
+	return slog.New(slog.DiscardHandler)
+}
+
+// Close closes the log file, if any
+func (l *Logger) Close() error {
+	if l.file == nil {
+		return nil
+	}
+	return l.file.Close()
+}
@@ -0,0 +1,83 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected slog.Level
+		wantErr  bool
+	}{
+		{"", slog.LevelInfo, false},
+		{"DEBUG", slog.LevelDebug, false},
+		{"warning", slog.LevelWarn, false},
+		{"error", slog.LevelError, false},
+		{"verbose", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLevel(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLevel(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if err == nil && got != tt.expected {
+				t.Errorf("ParseLevel(%q) = %v, expected %v", tt.name, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNewFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.log")
+	l, err := New(Options{Level: "warn", File: path, Attrs: []slog.Attr{slog.String("version", "1.2.3")}})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	l.Info("dropped below level")
+	l.Warn("tool failed", "tool", "get_cpu_metrics")
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected one JSON record, got %q", data)
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("Expected a JSON record: %v", err)
+	}
+	if record["msg"] != "tool failed" || record["tool"] != "get_cpu_metrics" || record["version"] != "1.2.3" {
+		t.Errorf("Unexpected record: %v", record)
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	if _, err := New(Options{Level: "loud"}); err == nil {
+		t.Error("Expected an error for an unknown level")
+	}
+	if _, err := New(Options{File: filepath.Join(t.TempDir(), "missing", "server.log")}); err == nil {
+		t.Error("Expected an error for an unwritable log file")
+	}
+}
+
+func TestNewWriter(t *testing.T) {
+	var buf bytes.Buffer
+	NewWriter(&buf, slog.LevelDebug).Debug("tool call", "tool", "get_system_info")
+	if !strings.Contains(buf.String(), "tool=get_system_info") {
+		t.Errorf("Expected a text record, got %q", buf.String())
+	}
+}
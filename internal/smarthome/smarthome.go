@@ -0,0 +1,50 @@
+// Package smarthome polls Zigbee2MQTT and Z-Wave JS coordinators over their WebSocket APIs.
+package smarthome
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Backend name constants.
+const (
+	BackendZigbee2MQTT = "zigbee2mqtt"
+	BackendZWaveJS     = "zwave_js"
+)
+
+// queryTimeout bounds a single conversation with a coordinator
+const queryTimeout = 10 * time.Second
+
+// dial opens a WebSocket connection with a read/write deadline covering the whole exchange
+func dial(ctx context.Context, rawURL string) (*websocket.Conn, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, rawURL, nil)
+	if resp != nil && resp.Body != nil {
+		_ = resp.Body.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	deadline, _ := ctx.Deadline()
+	_ = conn.SetReadDeadline(deadline)
+	_ = conn.SetWriteDeadline(deadline)
+	return conn, nil
+}
+
+// RedactURL hides query parameters such as the Zigbee2MQTT frontend auth token
+func RedactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	if u.RawQuery != "" {
+		u.RawQuery = "redacted"
+	}
+	u.User = nil
+	return u.String()
+}
@@ -0,0 +1,154 @@
+package smarthome
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Zigbee2MQTT bridge topics sent by the frontend WebSocket API on connect.
+const (
+	z2mTopicState   = "bridge/state"
+	z2mTopicInfo    = "bridge/info"
+	z2mTopicDevices = "bridge/devices"
+)
+
+// ZigbeeStatus is the health of a Zigbee2MQTT bridge and its coordinator
+type ZigbeeStatus struct {
+	BridgeState      string `json:"bridge_state"`
+	Version          string `json:"version,omitempty"`
+	CoordinatorType  string `json:"coordinator_type,omitempty"`
+	CoordinatorIEEE  string `json:"coordinator_ieee,omitempty"`
+	Channel          int    `json:"channel,omitempty"`
+	PanID            int    `json:"pan_id,omitempty"`
+	PermitJoin       bool   `json:"permit_join"`
+	DeviceCount      int    `json:"device_count"`
+	RouterCount      int    `json:"router_count"`
+	EndDeviceCount   int    `json:"end_device_count"`
+	InterviewPending int    `json:"interview_pending"`
+	UnsupportedCount int    `json:"unsupported_count"`
+	DisabledCount    int    `json:"disabled_count"`
+	receivedState    bool
+	receivedInfo     bool
+	receivedDevices  bool
+}
+
+// z2mMessage is a single frontend WebSocket message
+type z2mMessage struct {
+	Topic   string          `json:"topic"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// z2mInfo is the subset of bridge/info used for health reporting
+type z2mInfo struct {
+	Version     string `json:"version"`
+	PermitJoin  bool   `json:"permit_join"`
+	Coordinator struct {
+		Type        string `json:"type"`
+		IEEEAddress string `json:"ieee_address"`
+	} `json:"coordinator"`
+	Network struct {
+		Channel int `json:"channel"`
+		PanID   int `json:"pan_id"`
+	} `json:"network"`
+}
+
+// z2mDevice is a single entry of bridge/devices
+type z2mDevice struct {
+	Type               string `json:"type"`
+	InterviewCompleted bool   `json:"interview_completed"`
+	Supported          bool   `json:"supported"`
+	Disabled           bool   `json:"disabled"`
+}
+
+// QueryZigbee2MQTT connects to the Zigbee2MQTT frontend WebSocket API (e.g. ws://host:8080/api)
+// and reads the retained bridge state, info, and device list it sends on connect
+func QueryZigbee2MQTT(ctx context.Context, rawURL string) (ZigbeeStatus, error) {
+	var status ZigbeeStatus
+
+	conn, err := dial(ctx, rawURL)
+	if err != nil {
+		return status, fmt.Errorf("failed to connect to Zigbee2MQTT: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	for !status.complete() {
+		var msg z2mMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			return status, fmt.Errorf("failed to read Zigbee2MQTT bridge state: %w", err)
+		}
+		if err := status.apply(msg); err != nil {
+			return status, err
+		}
+	}
+	return status, nil
+}
+
+// complete reports whether every bridge topic needed for the summary has arrived
+func (s *ZigbeeStatus) complete() bool {
+	return s.receivedState && s.receivedInfo && s.receivedDevices
+}
+
+// apply folds a frontend message into the status, ignoring device state topics
+func (s *ZigbeeStatus) apply(msg z2mMessage) error {
+	switch msg.Topic {
+	case z2mTopicState:
+		// Zigbee2MQTT 1.30+ sends {"state":"online"}; older releases send the bare string
+		var state struct {
+			State string `json:"state"`
+		}
+		if err := json.Unmarshal(msg.Payload, &state); err != nil {
+			if err := json.Unmarshal(msg.Payload, &state.State); err != nil {
+				return fmt.Errorf("malformed %s payload: %w", msg.Topic, err)
+			}
+		}
+		s.BridgeState = state.State
+		s.receivedState = true
+	case z2mTopicInfo:
+		var info z2mInfo
+		if err := json.Unmarshal(msg.Payload, &info); err != nil {
+			return fmt.Errorf("malformed %s payload: %w", msg.Topic, err)
+		}
+		s.Version = info.Version
+		s.PermitJoin = info.PermitJoin
+		s.CoordinatorType = info.Coordinator.Type
+		s.CoordinatorIEEE = info.Coordinator.IEEEAddress
+		s.Channel = info.Network.Channel
+		s.PanID = info.Network.PanID
+		s.receivedInfo = true
+	case z2mTopicDevices:
+		var devices []z2mDevice
+		if err := json.Unmarshal(msg.Payload, &devices); err != nil {
+			return fmt.Errorf("malformed %s payload: %w", msg.Topic, err)
+		}
+		s.countDevices(devices)
+		s.receivedDevices = true
+	}
+	return nil
+}
+
+// countDevices tallies paired devices, excluding the coordinator itself
+func (s *ZigbeeStatus) countDevices(devices []z2mDevice) {
+	s.DeviceCount, s.RouterCount, s.EndDeviceCount = 0, 0, 0
+	s.InterviewPending, s.UnsupportedCount, s.DisabledCount = 0, 0, 0
+	for _, d := range devices {
+		switch d.Type {
+		case "Coordinator":
+			continue
+		case "Router":
+			s.RouterCount++
+		case "EndDevice":
+			s.EndDeviceCount++
+		}
+		s.DeviceCount++
+		if !d.InterviewCompleted {
+			s.InterviewPending++
+		}
+		if !d.Supported {
+			s.UnsupportedCount++
+		}
+		if d.Disabled {
+			s.DisabledCount++
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package smarthome
+
+import (
+	"context"
+	"fmt"
+)
+
+// startListeningID is the message ID of the state request sent to zwave-js-server
+const startListeningID = "sysmetrics-start"
+
+// Z-Wave JS NodeStatus values.
+const (
+	zwaveNodeAsleep = 1
+	zwaveNodeAwake  = 2
+	zwaveNodeDead   = 3
+	zwaveNodeAlive  = 4
+)
+
+// zwaveInclusionIncluding is the InclusionState value while the controller accepts new nodes
+const zwaveInclusionIncluding = 1
+
+// ZWaveStatus is the health of a Z-Wave JS driver and its controller
+type ZWaveStatus struct {
+	DriverVersion    string `json:"driver_version,omitempty"`
+	ServerVersion    string `json:"server_version,omitempty"`
+	HomeID           uint32 `json:"home_id,omitempty"`
+	ControllerNodeID int    `json:"controller_node_id,omitempty"`
+	InclusionActive  bool   `json:"inclusion_active"`
+	NodeCount        int    `json:"node_count"`
+	ReadyCount       int    `json:"ready_count"`
+	AliveCount       int    `json:"alive_count"`
+	AsleepCount      int    `json:"asleep_count"`
+	DeadCount        int    `json:"dead_count"`
+}
+
+// zwaveVersion is the greeting zwave-js-server sends on connect
+type zwaveVersion struct {
+	Type          string `json:"type"`
+	DriverVersion string `json:"driverVersion"`
+	ServerVersion string `json:"serverVersion"`
+	HomeID        uint32 `json:"homeId"`
+}
+
+// zwaveResult is a command response from zwave-js-server
+type zwaveResult struct {
+	Type      string `json:"type"`
+	MessageID string `json:"messageId"`
+	Success   bool   `json:"success"`
+	ErrorCode string `json:"errorCode"`
+	Result    struct {
+		State zwaveState `json:"state"`
+	} `json:"result"`
+}
+
+// zwaveState is the subset of the driver state dump used for health reporting
+type zwaveState struct {
+	Controller struct {
+		HomeID         uint32 `json:"homeId"`
+		OwnNodeID      int    `json:"ownNodeId"`
+		InclusionState *int   `json:"inclusionState"`
+	} `json:"controller"`
+	Nodes []struct {
+		NodeID int  `json:"nodeId"`
+		Status int  `json:"status"`
+		Ready  bool `json:"ready"`
+	} `json:"nodes"`
+}
+
+// QueryZWaveJS connects to a zwave-js-server WebSocket (e.g. ws://host:3000, also served by
+// Z-Wave JS UI) and summarizes the controller and node state
+func QueryZWaveJS(ctx context.Context, rawURL string) (ZWaveStatus, error) {
+	var status ZWaveStatus
+
+	conn, err := dial(ctx, rawURL)
+	if err != nil {
+		return status, fmt.Errorf("failed to connect to Z-Wave JS: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	var version zwaveVersion
+	if err := conn.ReadJSON(&version); err != nil {
+		return status, fmt.Errorf("failed to read Z-Wave JS version: %w", err)
+	}
+	if version.Type != "version" {
+		return status, fmt.Errorf("unexpected Z-Wave JS greeting: %q", version.Type)
+	}
+	status.DriverVersion = version.DriverVersion
+	status.ServerVersion = version.ServerVersion
+	status.HomeID = version.HomeID
+
+	if err := conn.WriteJSON(map[string]string{"messageId": startListeningID, "command": "start_listening"}); err != nil {
+		return status, fmt.Errorf("failed to request Z-Wave JS state: %w", err)
+	}
+
+	// Events may arrive before the result once listening starts
+	for {
+		var res zwaveResult
+		if err := conn.ReadJSON(&res); err != nil {
+			return status, fmt.Errorf("failed to read Z-Wave JS state: %w", err)
+		}
+		if res.Type != "result" || res.MessageID != startListeningID {
+			continue
+		}
+		if !res.Success {
+			return status, fmt.Errorf("start_listening failed: %s", res.ErrorCode)
+		}
+		status.summarize(res.Result.State)
+		return status, nil
+	}
+}
+
+// summarize counts nodes by status, excluding the controller's own node
+func (s *ZWaveStatus) summarize(state zwaveState) {
+	if state.Controller.HomeID != 0 {
+		s.HomeID = state.Controller.HomeID
+	}
+	s.ControllerNodeID = state.Controller.OwnNodeID
+	s.InclusionActive = state.Controller.InclusionState != nil && *state.Controller.InclusionState == zwaveInclusionIncluding
+
+	for _, n := range state.Nodes {
+		if n.NodeID == state.Controller.OwnNodeID {
+			continue
+		}
+		s.NodeCount++
+		if n.Ready {
+			s.ReadyCount++
+		}
+		switch n.Status {
+		case zwaveNodeAlive, zwaveNodeAwake:
+			s.AliveCount++
+		case zwaveNodeAsleep:
+			s.AsleepCount++
+		case zwaveNodeDead:
+			s.DeadCount++
+		}
+	}
+}
@@ -0,0 +1,354 @@
+// Package systemd renders a hardened systemd unit for running the server as a background
+// service and installs or removes it.
+package systemd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"sysmetrics-mcp/internal/capabilities"
+	"sysmetrics-mcp/internal/config"
+)
+
+// Installation defaults
+const (
+	DefaultName     = "sysmetrics-mcp"
+	DefaultUnitDir  = "/etc/systemd/system"
+	DefaultEnvDir   = "/etc/default"
+	DefaultGRPCAddr = "127.0.0.1:50051"
+)
+
+// stateDir is where the service keeps its history, ledgers, and baselines; systemd creates
+// it under /var/lib via StateDirectory=
+const stateDir = "/var/lib/" + DefaultName
+
+// runSystemctl runs systemctl; tests replace it
+var runSystemctl = func(ctx context.Context, args ...string) error {
+	out, err := exec.CommandContext(ctx, "systemctl", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("systemctl %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Options describe the service to install
+type Options struct {
+	// Name is the unit name without the .service suffix
+	Name    string
+	UnitDir string
+	EnvDir  string
+	// User runs the service as an existing account instead of a transient DynamicUser
+	User   string
+	Binary string
+	// Args are the server flags for ExecStart; --grpc-only is always added
+	Args []string
+	// Env holds secrets written to a root-only EnvironmentFile instead of the unit
+	Env    map[string]string
+	Config *config.Config
+	Caps   capabilities.Capabilities
+	// NoEnable writes the unit without enabling and starting it
+	NoEnable bool
+}
+
+// Unit is a rendered service unit
+type Unit struct {
+	Text         string
+	EnvFile      string
+	Capabilities []string
+	Warnings     []string
+}
+
+// capabilityNeed grants capabilities to collectors that read other users' or the
+// kernel's data when any of the tools is enabled
+type capabilityNeed struct {
+	tools []string
+	caps  []string
+}
+
+var capabilityNeeds = []capabilityNeed{
+	// Other users' /proc/<pid>/fd, maps, and net entries
+	{[]string{"get_process_list", "get_network_connections", "get_fd_usage", "get_network_top_processes", "get_listening_ports", "get_reboot_status", "audit_exposed_services"},
+		[]string{"CAP_DAC_READ_SEARCH", "CAP_SYS_PTRACE"}},
+	// Kernel ring buffer and root-only pstore records
+	{[]string{"get_crash_logs", "get_boot_diagnostics", "get_oom_events"}, []string{"CAP_SYSLOG", "CAP_DAC_READ_SEARCH"}},
+	// Root-only auth.log and sshd host keys for sshd -T
+	{[]string{"get_ssh_security"}, []string{"CAP_DAC_READ_SEARCH"}},
+	// Per-user crontabs under /var/spool/cron
+	{[]string{"get_scheduled_jobs"}, []string{"CAP_DAC_READ_SEARCH"}},
+	// ICMP ping from an unprivileged user
+	{[]string{"check_connectivity"}, []string{"CAP_NET_RAW"}},
+	// Signalling and renicing other users' processes
+	{[]string{"manage_process"}, []string{"CAP_KILL", "CAP_SYS_NICE"}},
+}
+
+// toolEnabled reports whether the configuration would register a tool
+func toolEnabled(c *config.Config, name string) bool {
+	ok, _ := c.ToolEnabled(name)
+	return ok
+}
+
+// Render builds the unit for opts. The unit runs the gRPC API without stdio, with a
+// capability bounding set limited to what the enabled collectors need.
+func Render(opts Options) Unit {
+	cfg := opts.Config
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	var u Unit
+
+	capSet := map[string]bool{}
+	for _, need := range capabilityNeeds {
+		for _, tool := range need.tools {
+			if toolEnabled(cfg, tool) {
+				for _, c := range need.caps {
+					capSet[c] = true
+				}
+				break
+			}
+		}
+	}
+	// Ping synthetic checks run in the background whichever tools are enabled
+	for _, check := range cfg.SyntheticChecks {
+		if check.Type == config.SyntheticPing {
+			capSet["CAP_NET_RAW"] = true
+		}
+	}
+	for c := range capSet {
+		u.Capabilities = append(u.Capabilities, c)
+	}
+	sort.Strings(u.Capabilities)
+
+	var groups []string
+	if opts.Caps.Systemd {
+		// journalctl for boot history, availability, and service logs
+		groups = append(groups, "systemd-journal")
+	}
+	if opts.Caps.DockerSocket && (toolEnabled(cfg, "get_docker_metrics") || toolEnabled(cfg, "get_container_metrics") ||
+		toolEnabled(cfg, "get_docker_disk_usage")) {
+		groups = append(groups, "docker")
+	}
+	if opts.Caps.Libvirt && toolEnabled(cfg, "get_vm_metrics") {
+		groups = append(groups, "libvirt")
+	}
+	needVideo := opts.Caps.Vcgencmd
+	if needVideo {
+		groups = append(groups, "video")
+	}
+
+	var writable []string
+	for _, path := range []string{cfg.HistoryDB, cfg.AuditLog, cfg.LogFile} {
+		if path == "" || strings.HasPrefix(path, stateDir+"/") {
+			continue
+		}
+		dir := filepath.Dir(path)
+		if !slices.Contains(writable, dir) {
+			writable = append(writable, dir)
+			u.Warnings = append(u.Warnings, fmt.Sprintf("%s is outside %s; make sure the service user can write to it", dir, stateDir))
+		}
+	}
+
+	if len(cfg.SudoAllowlist) > 0 {
+		u.Warnings = append(u.Warnings, "--sudo-allowlist has no effect under NoNewPrivileges=yes; collectors rely on the granted capabilities instead")
+	}
+	if toolEnabled(cfg, "control_service") && opts.User == "" {
+		u.Warnings = append(u.Warnings, "control_service cannot manage units as a dynamic user; install with --service-user root")
+	}
+	if toolEnabled(cfg, "apply_update") {
+		writable = append(writable, filepath.Dir(opts.Binary))
+		if opts.User == "" {
+			u.Warnings = append(u.Warnings, "apply_update cannot replace the binary as a dynamic user; install with --service-user root")
+		}
+	}
+	if strings.HasPrefix(opts.Binary, "/home/") || strings.HasPrefix(opts.Binary, "/root/") {
+		u.Warnings = append(u.Warnings, fmt.Sprintf("%s is hidden by ProtectHome=yes; install the binary under /usr/local/bin first", opts.Binary))
+	}
+
+	args := append([]string{opts.Binary, "--grpc-only"}, opts.Args...)
+	if !hasFlag(opts.Args, "grpc-addr") {
+		args = append(args, "--grpc-addr="+DefaultGRPCAddr)
+	}
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = quoteArg(a)
+	}
+
+	var b strings.Builder
+	b.WriteString("# Installed by sysmetrics-mcp install-service; remove with sysmetrics-mcp uninstall-service\n")
+	b.WriteString("[Unit]\n")
+	b.WriteString("Description=sysmetrics-mcp system metrics server\n")
+	b.WriteString("Documentation=https://github.com/raythurman2386/sysmetrics-mcp\n")
+	b.WriteString("Wants=network-online.target\nAfter=network-online.target\n\n")
+
+	b.WriteString("[Service]\nType=simple\n")
+	fmt.Fprintf(&b, "ExecStart=%s\n", strings.Join(quoted, " "))
+	b.WriteString("Restart=on-failure\nRestartSec=5s\n")
+	if len(opts.Env) > 0 {
+		fmt.Fprintf(&b, "EnvironmentFile=%s\n", envPath(opts))
+	}
+	if opts.User != "" {
+		fmt.Fprintf(&b, "User=%s\n", opts.User)
+	} else {
+		b.WriteString("DynamicUser=yes\n")
+	}
+	if len(groups) > 0 {
+		fmt.Fprintf(&b, "SupplementaryGroups=%s\n", strings.Join(groups, " "))
+	}
+	// Ledgers and baselines live under the user config directory and the update check
+	// under the user cache directory; a dynamic user has no writable home for either
+	fmt.Fprintf(&b, "StateDirectory=%s\nEnvironment=XDG_CONFIG_HOME=%s\nEnvironment=XDG_CACHE_HOME=%s/cache\n", DefaultName, stateDir, stateDir)
+
+	b.WriteString("\n# Privileges: only the capabilities the enabled collectors need\n")
+	fmt.Fprintf(&b, "CapabilityBoundingSet=%s\n", strings.Join(u.Capabilities, " "))
+	if len(u.Capabilities) > 0 {
+		fmt.Fprintf(&b, "AmbientCapabilities=%s\n", strings.Join(u.Capabilities, " "))
+	}
+	b.WriteString("NoNewPrivileges=yes\n")
+
+	b.WriteString("\n# Filesystem: read-only except the state directory\n")
+	b.WriteString("ProtectSystem=strict\nProtectHome=yes\nPrivateTmp=yes\n")
+	if len(writable) > 0 {
+		fmt.Fprintf(&b, "ReadWritePaths=%s\n", strings.Join(writable, " "))
+	}
+	if needVideo {
+		// vcgencmd talks to the VideoCore through /dev/vchiq
+		b.WriteString("DeviceAllow=/dev/vchiq rw\nDevicePolicy=closed\n")
+	} else {
+		b.WriteString("PrivateDevices=yes\n")
+	}
+	b.WriteString("UMask=0077\n")
+
+	b.WriteString("\n# Kernel and process sandboxing\n")
+	b.WriteString("ProtectKernelTunables=yes\nProtectKernelModules=yes\n")
+	if !capSet["CAP_SYSLOG"] {
+		b.WriteString("ProtectKernelLogs=yes\n")
+	}
+	b.WriteString("ProtectControlGroups=yes\nProtectClock=yes\nProtectHostname=yes\n")
+	b.WriteString("RestrictNamespaces=yes\nRestrictRealtime=yes\nRestrictSUIDSGID=yes\n")
+	b.WriteString("LockPersonality=yes\nMemoryDenyWriteExecute=yes\nSystemCallArchitectures=native\n")
+	b.WriteString("RestrictAddressFamilies=AF_UNIX AF_INET AF_INET6 AF_NETLINK\n")
+
+	b.WriteString("\n[Install]\nWantedBy=multi-user.target\n")
+	u.Text = b.String()
+
+	if len(opts.Env) > 0 {
+		keys := make([]string, 0, len(opts.Env))
+		for k := range opts.Env {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var env strings.Builder
+		for _, k := range keys {
+			fmt.Fprintf(&env, "%s=%s\n", k, opts.Env[k])
+		}
+		u.EnvFile = env.String()
+	}
+	return u
+}
+
+// Install writes the unit (and its secrets file, if any), reloads systemd, and unless
+// NoEnable is set enables and starts the service
+func Install(ctx context.Context, opts Options) (Unit, error) {
+	if !validName(opts.Name) {
+		return Unit{}, fmt.Errorf("invalid service name: %q", opts.Name)
+	}
+	u := Render(opts)
+
+	if u.EnvFile != "" {
+		if err := os.MkdirAll(opts.EnvDir, 0o755); err != nil { //nolint:gosec // G301: /etc/default is world-readable; the file itself is 0600
+			return u, fmt.Errorf("failed to create %s: %w", opts.EnvDir, err)
+		}
+		if err := os.WriteFile(envPath(opts), []byte(u.EnvFile), 0o600); err != nil {
+			return u, fmt.Errorf("failed to write environment file: %w", err)
+		}
+	}
+	//nolint:gosec // G306: unit files are world-readable; secrets live in the environment file
+	if err := os.WriteFile(unitPath(opts), []byte(u.Text), 0o644); err != nil {
+		return u, fmt.Errorf("failed to write unit: %w", err)
+	}
+
+	if err := runSystemctl(ctx, "daemon-reload"); err != nil {
+		return u, err
+	}
+	if !opts.NoEnable {
+		if err := runSystemctl(ctx, "enable", "--now", opts.Name+".service"); err != nil {
+			return u, err
+		}
+	}
+	return u, nil
+}
+
+// Uninstall stops and disables the service, then removes its unit and environment file.
+// Files that do not exist are not an error.
+func Uninstall(ctx context.Context, opts Options) error {
+	if !validName(opts.Name) {
+		return fmt.Errorf("invalid service name: %q", opts.Name)
+	}
+	if _, err := os.Stat(unitPath(opts)); err != nil {
+		return fmt.Errorf("%s is not installed: %w", opts.Name, err)
+	}
+	if err := runSystemctl(ctx, "disable", "--now", opts.Name+".service"); err != nil {
+		return err
+	}
+	for _, path := range []string{unitPath(opts), envPath(opts)} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	}
+	return runSystemctl(ctx, "daemon-reload")
+}
+
+// unitPath is where the unit file is written
+func unitPath(opts Options) string {
+	return filepath.Join(opts.UnitDir, opts.Name+".service")
+}
+
+// envPath is where secrets for the unit are written
+func envPath(opts Options) string {
+	return filepath.Join(opts.EnvDir, opts.Name)
+}
+
+// quoteArg quotes an ExecStart argument for systemd, escaping its specifiers (%) and
+// variable expansion ($)
+func quoteArg(s string) string {
+	s = strings.ReplaceAll(s, "%", "%%")
+	s = strings.ReplaceAll(s, "$", "$$")
+	if s != "" && !strings.ContainsAny(s, " \t\"'\\;") {
+		return s
+	}
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+// hasFlag reports whether args set the named flag
+func hasFlag(args []string, name string) bool {
+	for _, a := range args {
+		a = strings.TrimLeft(a, "-")
+		if a == name || strings.HasPrefix(a, name+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+// validName reports whether name is a plain unit name without a suffix or path
+func validName(name string) bool {
+	if name == "" || strings.HasPrefix(name, "-") || strings.HasPrefix(name, ".") {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case strings.ContainsRune("_.@-", r):
+		default:
+			return false
+		}
+	}
+	return true
+}
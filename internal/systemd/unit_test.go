@@ -0,0 +1,177 @@
+package systemd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"sysmetrics-mcp/internal/capabilities"
+	"sysmetrics-mcp/internal/config"
+)
+
+// unitLine returns the value of the first key= line in a unit, and whether it exists
+func unitLine(unit, key string) (string, bool) {
+	for _, line := range strings.Split(unit, "\n") {
+		if v, ok := strings.CutPrefix(line, key+"="); ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+func TestRenderCapabilities(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Config
+		want []string
+	}{
+		{"minimal profile needs none", config.Config{ToolProfile: config.ProfileMinimal}, nil},
+		{"full profile", config.Config{ToolProfile: config.ProfileFull},
+			[]string{"CAP_DAC_READ_SEARCH", "CAP_NET_RAW", "CAP_SYSLOG", "CAP_SYS_PTRACE"}},
+		{"process control", config.Config{ToolProfile: config.ProfileMinimal, AllowProcessControl: true, EnableTools: []string{"manage_process"}},
+			[]string{"CAP_KILL", "CAP_SYS_NICE"}},
+		{"enabled without its flag", config.Config{ToolProfile: config.ProfileMinimal, EnableTools: []string{"manage_process"}}, nil},
+		{"connectivity only", config.Config{ToolProfile: config.ProfileMinimal, EnableTools: []string{"check_connectivity"}},
+			[]string{"CAP_NET_RAW"}},
+		{"ping synthetic check", config.Config{ToolProfile: config.ProfileMinimal, SyntheticChecks: []config.SyntheticCheck{{Type: config.SyntheticPing, Target: "192.168.1.1"}}},
+			[]string{"CAP_NET_RAW"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := Render(Options{Binary: "/usr/local/bin/sysmetrics-mcp", Config: &tt.cfg})
+			if !reflect.DeepEqual(u.Capabilities, tt.want) {
+				t.Errorf("Capabilities = %v, want %v", u.Capabilities, tt.want)
+			}
+			bounding, ok := unitLine(u.Text, "CapabilityBoundingSet")
+			if !ok || bounding != strings.Join(tt.want, " ") {
+				t.Errorf("CapabilityBoundingSet=%q, want %q", bounding, strings.Join(tt.want, " "))
+			}
+			_, ambient := unitLine(u.Text, "AmbientCapabilities")
+			if ambient != (len(tt.want) > 0) {
+				t.Errorf("AmbientCapabilities present = %v with capabilities %v", ambient, tt.want)
+			}
+			_, protectLogs := unitLine(u.Text, "ProtectKernelLogs")
+			if protectLogs == slices.Contains(tt.want, "CAP_SYSLOG") {
+				t.Errorf("ProtectKernelLogs present = %v with capabilities %v", protectLogs, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderUnit(t *testing.T) {
+	cfg := config.Config{ToolProfile: config.ProfileMinimal, HistoryDB: "/srv/metrics/history.db"}
+	u := Render(Options{
+		Binary: "/usr/local/bin/sysmetrics-mcp",
+		Args:   []string{"--history-db=/srv/metrics/history.db", "--disable-tools=get_system_health,self_test", "--locale=50% off"},
+		Env:    map[string]string{"SYSMETRICS_GRPC_TOKEN": "s3cret"},
+		Config: &cfg,
+		Caps:   capabilities.Capabilities{Systemd: true, Vcgencmd: true},
+		Name:   "pi",
+		EnvDir: "/etc/default",
+	})
+
+	exec, _ := unitLine(u.Text, "ExecStart")
+	want := `/usr/local/bin/sysmetrics-mcp --grpc-only --history-db=/srv/metrics/history.db --disable-tools=get_system_health,self_test "--locale=50%% off" --grpc-addr=127.0.0.1:50051`
+	if exec != want {
+		t.Errorf("ExecStart=%s\nwant      %s", exec, want)
+	}
+	if strings.Contains(u.Text, "s3cret") || u.EnvFile != "SYSMETRICS_GRPC_TOKEN=s3cret\n" {
+		t.Errorf("Expected the token only in the environment file, got env %q", u.EnvFile)
+	}
+	for key, value := range map[string]string{
+		"EnvironmentFile":     "/etc/default/pi",
+		"DynamicUser":         "yes",
+		"SupplementaryGroups": "systemd-journal video",
+		"ReadWritePaths":      "/srv/metrics",
+		"DeviceAllow":         "/dev/vchiq rw",
+		"ProtectSystem":       "strict",
+		"NoNewPrivileges":     "yes",
+	} {
+		if got, _ := unitLine(u.Text, key); got != value {
+			t.Errorf("%s=%q, want %q", key, got, value)
+		}
+	}
+	if _, ok := unitLine(u.Text, "PrivateDevices"); ok {
+		t.Error("Expected PrivateDevices to be off when /dev/vchiq is needed")
+	}
+	for _, env := range []string{"XDG_CONFIG_HOME=/var/lib/sysmetrics-mcp", "XDG_CACHE_HOME=/var/lib/sysmetrics-mcp/cache"} {
+		if !strings.Contains(u.Text, "\nEnvironment="+env+"\n") {
+			t.Errorf("Expected Environment=%s in the unit", env)
+		}
+	}
+	if len(u.Warnings) != 1 || !strings.Contains(u.Warnings[0], "/srv/metrics") {
+		t.Errorf("Expected a warning about /srv/metrics, got %v", u.Warnings)
+	}
+
+	// An explicit address and a fixed user are kept
+	u = Render(Options{Binary: "/usr/bin/sysmetrics-mcp", Args: []string{"--grpc-addr=0.0.0.0:9000"}, User: "metrics",
+		Config: &config.Config{ToolProfile: config.ProfileMinimal, AllowServiceControl: true, EnableTools: []string{"control_service"}}})
+	if exec, _ := unitLine(u.Text, "ExecStart"); strings.Count(exec, "--grpc-addr") != 1 {
+		t.Errorf("Expected the given --grpc-addr only, got %s", exec)
+	}
+	if user, _ := unitLine(u.Text, "User"); user != "metrics" || strings.Contains(u.Text, "DynamicUser") {
+		t.Errorf("Expected User=metrics without DynamicUser, got:\n%s", u.Text)
+	}
+	if len(u.Warnings) != 0 {
+		t.Errorf("Expected no dynamic user warning with --service-user, got %v", u.Warnings)
+	}
+}
+
+func TestInstallUninstall(t *testing.T) {
+	var calls []string
+	orig := runSystemctl
+	runSystemctl = func(ctx context.Context, args ...string) error {
+		calls = append(calls, strings.Join(args, " "))
+		return nil
+	}
+	defer func() { runSystemctl = orig }()
+
+	dir := t.TempDir()
+	opts := Options{
+		Name:    "sysmetrics-mcp",
+		UnitDir: filepath.Join(dir, "system"),
+		EnvDir:  filepath.Join(dir, "default"),
+		Binary:  "/usr/local/bin/sysmetrics-mcp",
+		Env:     map[string]string{"SYSMETRICS_GRPC_TOKEN": "s3cret"},
+		Config:  &config.Config{ToolProfile: config.ProfileMinimal},
+	}
+	if err := os.MkdirAll(opts.UnitDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Install(context.Background(), opts); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if want := []string{"daemon-reload", "enable --now sysmetrics-mcp.service"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("systemctl calls = %v, want %v", calls, want)
+	}
+	info, err := os.Stat(filepath.Join(opts.EnvDir, "sysmetrics-mcp"))
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected a 0600 environment file, got %v, %v", info, err)
+	}
+
+	calls = nil
+	if err := Uninstall(context.Background(), opts); err != nil {
+		t.Fatalf("Uninstall() error = %v", err)
+	}
+	if want := []string{"disable --now sysmetrics-mcp.service", "daemon-reload"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("systemctl calls = %v, want %v", calls, want)
+	}
+	for _, path := range []string{filepath.Join(opts.UnitDir, "sysmetrics-mcp.service"), filepath.Join(opts.EnvDir, "sysmetrics-mcp")} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed, got %v", path, err)
+		}
+	}
+
+	if err := Uninstall(context.Background(), opts); err == nil {
+		t.Error("Expected uninstalling a missing service to fail")
+	}
+	opts.Name = "../evil"
+	if _, err := Install(context.Background(), opts); err == nil {
+		t.Error("Expected a path in the service name to be rejected")
+	}
+}
@@ -0,0 +1,190 @@
+package update
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Download limits.
+const (
+	maxBinarySize    = 200 << 20
+	maxChecksumsSize = 1 << 20
+	verifyTimeout    = 10 * time.Second
+)
+
+// backupSuffix is appended to the previous binary kept for rollback
+const backupSuffix = ".old"
+
+// ApplyOptions configures a binary replacement
+type ApplyOptions struct {
+	// ExePath is the binary to replace
+	ExePath string
+	// Client downloads release assets; http.DefaultClient is used when nil
+	Client *http.Client
+	// Verify checks the downloaded binary before it is swapped in
+	Verify func(ctx context.Context, path string) error
+}
+
+// ApplyResult describes a completed binary replacement
+type ApplyResult struct {
+	Path       string `json:"path"`
+	BackupPath string `json:"backup_path"`
+	SHA256     string `json:"sha256"`
+}
+
+// Apply downloads a release asset, checks it against the release checksums, verifies
+// that it runs, and swaps it in place of the current binary. The previous binary is kept
+// next to it for Rollback, and is restored if the swap fails.
+func Apply(ctx context.Context, release Release, asset Asset, opts ApplyOptions) (ApplyResult, error) {
+	client := opts.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	result := ApplyResult{Path: opts.ExePath, BackupPath: opts.ExePath + backupSuffix}
+
+	// Refuse unverifiable downloads
+	sumsAsset, ok := FindAsset(release, ChecksumsAsset)
+	if !ok {
+		return result, fmt.Errorf("release %s has no %s; refusing to install an unverified binary", release.TagName, ChecksumsAsset)
+	}
+	var sums bytes.Buffer
+	if err := download(ctx, client, sumsAsset.DownloadURL, &sums, maxChecksumsSize); err != nil {
+		return result, fmt.Errorf("failed to download %s: %w", ChecksumsAsset, err)
+	}
+	expected, ok := parseChecksums(sums.String())[asset.Name]
+	if !ok {
+		return result, fmt.Errorf("%s has no entry for %s", ChecksumsAsset, asset.Name)
+	}
+
+	// Download next to the binary so the final rename stays on one filesystem
+	tmp, err := os.CreateTemp(filepath.Dir(opts.ExePath), ".sysmetrics-mcp-update-*")
+	if err != nil {
+		return result, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	hash := sha256.New()
+	err = download(ctx, client, asset.DownloadURL, io.MultiWriter(tmp, hash), maxBinarySize)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return result, fmt.Errorf("failed to download %s: %w", asset.Name, err)
+	}
+	result.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	if !strings.EqualFold(result.SHA256, expected) {
+		return result, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", asset.Name, expected, result.SHA256)
+	}
+
+	if err := os.Chmod(tmpPath, 0o755); err != nil { //nolint:gosec // G302: the file is an executable
+		return result, fmt.Errorf("failed to make binary executable: %w", err)
+	}
+	if opts.Verify != nil {
+		if err := opts.Verify(ctx, tmpPath); err != nil {
+			return result, fmt.Errorf("downloaded binary failed verification: %w", err)
+		}
+	}
+
+	// Renaming a running executable is allowed on Linux, macOS, and Windows
+	_ = os.Remove(result.BackupPath)
+	if err := os.Rename(opts.ExePath, result.BackupPath); err != nil {
+		return result, fmt.Errorf("failed to back up current binary: %w", err)
+	}
+	if err := os.Rename(tmpPath, opts.ExePath); err != nil {
+		if restoreErr := os.Rename(result.BackupPath, opts.ExePath); restoreErr != nil {
+			return result, fmt.Errorf("failed to install new binary (%w) and to restore the backup: %v", err, restoreErr)
+		}
+		return result, fmt.Errorf("failed to install new binary, previous binary restored: %w", err)
+	}
+	return result, nil
+}
+
+// Rollback restores the binary saved by the last Apply
+func Rollback(exePath string) error {
+	backup := exePath + backupSuffix
+	if _, err := os.Stat(backup); err != nil {
+		return fmt.Errorf("no previous binary to restore: %w", err)
+	}
+
+	// Move the current binary aside first so the swap works while it is running
+	aside := exePath + ".rollback"
+	_ = os.Remove(aside)
+	if err := os.Rename(exePath, aside); err != nil {
+		return fmt.Errorf("failed to move current binary aside: %w", err)
+	}
+	if err := os.Rename(backup, exePath); err != nil {
+		_ = os.Rename(aside, exePath)
+		return fmt.Errorf("failed to restore previous binary: %w", err)
+	}
+	_ = os.Remove(aside)
+	return nil
+}
+
+// VerifyVersion returns a verifier that runs a binary with --version and checks that it
+// reports the expected version
+func VerifyVersion(version string) func(ctx context.Context, path string) error {
+	want := strings.TrimPrefix(version, "v")
+	return func(ctx context.Context, path string) error {
+		ctx, cancel := context.WithTimeout(ctx, verifyTimeout)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, path, "--version").CombinedOutput() //nolint:gosec // G204: path is the checksum-verified download
+		if err != nil {
+			return fmt.Errorf("%s --version failed: %w", filepath.Base(path), err)
+		}
+		if !strings.Contains(string(out), want) {
+			return fmt.Errorf("binary reports %q, expected version %s", strings.TrimSpace(string(out)), want)
+		}
+		return nil
+	}
+}
+
+// download streams a URL into w, failing if the body exceeds limit bytes
+func download(ctx context.Context, client *http.Client, url string, w io.Writer, limit int64) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server returned %s", resp.Status)
+	}
+	n, err := io.Copy(w, io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return err
+	}
+	if n > limit {
+		return fmt.Errorf("download exceeds %d bytes", limit)
+	}
+	return nil
+}
+
+// parseChecksums parses sha256sum-style lines ("<hex>  <name>") into a name-to-hash map
+func parseChecksums(data string) map[string]string {
+	sums := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		// sha256sum marks binary mode with a leading '*'
+		sums[strings.TrimPrefix(fields[1], "*")] = fields[0]
+	}
+	return sums
+}
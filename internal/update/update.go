@@ -0,0 +1,225 @@
+// Package update checks GitHub for newer server releases and replaces the running binary.
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultReleasesURL is the GitHub API endpoint for the latest release of this server
+const DefaultReleasesURL = "https://api.github.com/repos/raythurman2386/sysmetrics-mcp/releases/latest"
+
+// Cache and request limits.
+const (
+	cacheTTL       = time.Hour
+	requestTimeout = 15 * time.Second
+)
+
+// ChecksumsAsset is the release asset listing SHA-256 sums of the binaries
+const ChecksumsAsset = "checksums.txt"
+
+// Release is the subset of a GitHub release used for update checks
+type Release struct {
+	TagName     string  `json:"tag_name"`
+	Name        string  `json:"name"`
+	HTMLURL     string  `json:"html_url"`
+	PublishedAt string  `json:"published_at"`
+	Assets      []Asset `json:"assets"`
+}
+
+// Asset is a downloadable file attached to a release
+type Asset struct {
+	Name        string `json:"name"`
+	DownloadURL string `json:"browser_download_url"`
+	Size        int64  `json:"size"`
+}
+
+// CheckResult is a release lookup, possibly served from cache
+type CheckResult struct {
+	Release   Release
+	CheckedAt time.Time
+	FromCache bool
+}
+
+// cacheEntry is the on-disk form of the last successful lookup
+type cacheEntry struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Release   Release   `json:"release"`
+}
+
+// Checker looks up the latest release, caching results in memory and on disk
+type Checker struct {
+	url       string
+	cachePath string
+	client    *http.Client
+
+	mu    sync.Mutex
+	entry *cacheEntry
+}
+
+// NewChecker creates a Checker for a releases API URL. An empty cachePath disables the
+// on-disk cache.
+func NewChecker(url, cachePath string) *Checker {
+	return &Checker{
+		url:       url,
+		cachePath: cachePath,
+		client:    &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// DefaultCachePath returns the on-disk cache location under the user cache directory,
+// or "" if it cannot be determined
+func DefaultCachePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "sysmetrics-mcp", "latest-release.json")
+}
+
+// Latest returns the latest release. Cached results younger than an hour are reused
+// unless force is set; in offline mode only cached results are returned.
+func (c *Checker) Latest(ctx context.Context, force, offline bool) (CheckResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entry == nil {
+		c.entry = c.loadCache()
+	}
+	if c.entry != nil && (offline || (!force && time.Since(c.entry.CheckedAt) < cacheTTL)) {
+		return CheckResult{Release: c.entry.Release, CheckedAt: c.entry.CheckedAt, FromCache: true}, nil
+	}
+	if offline {
+		return CheckResult{}, fmt.Errorf("offline mode is enabled and no release has been cached yet")
+	}
+
+	release, err := c.fetch(ctx)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	c.entry = &cacheEntry{CheckedAt: time.Now().UTC(), Release: release}
+	c.saveCache()
+	return CheckResult{Release: release, CheckedAt: c.entry.CheckedAt}, nil
+}
+
+// fetch queries the releases API
+func (c *Checker) fetch(ctx context.Context) (Release, error) {
+	var release Release
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return release, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return release, fmt.Errorf("failed to query releases: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return release, fmt.Errorf("releases API returned %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return release, fmt.Errorf("failed to decode release: %w", err)
+	}
+	if release.TagName == "" {
+		return release, fmt.Errorf("release has no tag")
+	}
+	return release, nil
+}
+
+// loadCache reads the on-disk cache, returning nil if it is missing or unreadable
+func (c *Checker) loadCache() *cacheEntry {
+	if c.cachePath == "" {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Clean(c.cachePath))
+	if err != nil {
+		return nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Release.TagName == "" {
+		return nil
+	}
+	return &entry
+}
+
+// saveCache writes the current entry to disk; failures only cost a refetch later
+func (c *Checker) saveCache() {
+	if c.cachePath == "" || c.entry == nil {
+		return
+	}
+	data, err := json.Marshal(c.entry)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(c.cachePath), 0o750); err != nil {
+		return
+	}
+	_ = os.WriteFile(c.cachePath, data, 0o600)
+}
+
+// CompareVersions compares two dotted versions such as "v1.2.3" and "1.10.0", returning
+// -1, 0, or 1. A pre-release ("1.2.0-rc1") sorts before its release.
+func CompareVersions(a, b string) int {
+	aCore, aPre, _ := strings.Cut(strings.TrimPrefix(a, "v"), "-")
+	bCore, bPre, _ := strings.Cut(strings.TrimPrefix(b, "v"), "-")
+
+	aParts, bParts := strings.Split(aCore, "."), strings.Split(bCore, ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var x, y int
+		if i < len(aParts) {
+			x, _ = strconv.Atoi(aParts[i])
+		}
+		if i < len(bParts) {
+			y, _ = strconv.Atoi(bParts[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	case aPre < bPre:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// AssetName returns the expected binary asset name for a platform, e.g.
+// "sysmetrics-mcp_linux_arm64" or "sysmetrics-mcp_windows_amd64.exe"
+func AssetName(goos, goarch string) string {
+	name := "sysmetrics-mcp_" + goos + "_" + goarch
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// FindAsset returns the release asset with the given name
+func FindAsset(release Release, name string) (Asset, bool) {
+	for _, a := range release.Assets {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Asset{}, false
+}
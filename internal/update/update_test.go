@@ -0,0 +1,198 @@
+package update
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"v1.2.0", "1.2.0", 0},
+		{"v1.10.0", "v1.9.3", 1},
+		{"1.0.0", "v1.0.1", -1},
+		{"v2", "1.99.99", 1},
+		{"v1.2.0-rc1", "v1.2.0", -1},
+		{"v1.2.0", "v1.2.0-rc1", 1},
+		{"v1.2.0-rc2", "v1.2.0-rc1", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			if got := CompareVersions(tt.a, tt.b); got != tt.expected {
+				t.Errorf("CompareVersions(%s, %s) = %d, expected %d", tt.a, tt.b, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAssetName(t *testing.T) {
+	if got := AssetName("linux", "arm64"); got != "sysmetrics-mcp_linux_arm64" {
+		t.Errorf("Unexpected asset name: %s", got)
+	}
+	if got := AssetName("windows", "amd64"); got != "sysmetrics-mcp_windows_amd64.exe" {
+		t.Errorf("Unexpected asset name: %s", got)
+	}
+}
+
+func TestParseChecksums(t *testing.T) {
+	sums := parseChecksums("abc123  sysmetrics-mcp_linux_arm64\ndef456 *sysmetrics-mcp_windows_amd64.exe\n\nmalformed\n")
+	if sums["sysmetrics-mcp_linux_arm64"] != "abc123" || sums["sysmetrics-mcp_windows_amd64.exe"] != "def456" || len(sums) != 2 {
+		t.Errorf("Unexpected checksums: %v", sums)
+	}
+}
+
+func TestCheckerLatest(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_ = json.NewEncoder(w).Encode(Release{TagName: "v1.4.0", HTMLURL: "https://example.invalid/v1.4.0"})
+	}))
+	defer srv.Close()
+
+	cachePath := filepath.Join(t.TempDir(), "cache", "latest-release.json")
+	c := NewChecker(srv.URL, cachePath)
+
+	if _, err := c.Latest(context.Background(), false, true); err == nil {
+		t.Error("Expected error in offline mode with an empty cache")
+	}
+
+	res, err := c.Latest(context.Background(), false, false)
+	if err != nil {
+		t.Fatalf("Latest failed: %v", err)
+	}
+	if res.Release.TagName != "v1.4.0" || res.FromCache {
+		t.Errorf("Unexpected result: %+v", res)
+	}
+
+	res, err = c.Latest(context.Background(), false, false)
+	if err != nil || !res.FromCache || requests.Load() != 1 {
+		t.Errorf("Expected cached result without a second request, got %+v (%d requests, err %v)", res, requests.Load(), err)
+	}
+
+	if _, err := c.Latest(context.Background(), true, false); err != nil || requests.Load() != 2 {
+		t.Errorf("Expected force to refetch, got %d requests (err %v)", requests.Load(), err)
+	}
+
+	// A new checker picks up the on-disk cache, which is all offline mode can use
+	offline := NewChecker("http://127.0.0.1:0/unreachable", cachePath)
+	res, err = offline.Latest(context.Background(), true, true)
+	if err != nil || res.Release.TagName != "v1.4.0" || !res.FromCache {
+		t.Errorf("Expected cached release in offline mode, got %+v (err %v)", res, err)
+	}
+}
+
+func TestCheckerLatestHTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "rate limited", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	if _, err := NewChecker(srv.URL, "").Latest(context.Background(), false, false); err == nil {
+		t.Error("Expected error for non-200 response")
+	}
+}
+
+// startReleaseServer serves a binary and a checksums file listing sum for it
+func startReleaseServer(t *testing.T, binary []byte, sum string) (Release, Asset) {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/bin", func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write(binary) })
+	mux.HandleFunc("/sums", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(sum + "  sysmetrics-mcp_linux_arm64\n"))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	asset := Asset{Name: "sysmetrics-mcp_linux_arm64", DownloadURL: srv.URL + "/bin"}
+	release := Release{TagName: "v1.1.0", Assets: []Asset{asset, {Name: ChecksumsAsset, DownloadURL: srv.URL + "/sums"}}}
+	return release, asset
+}
+
+func TestApplyAndRollback(t *testing.T) {
+	newBinary := []byte("new binary")
+	digest := sha256.Sum256(newBinary)
+	release, asset := startReleaseServer(t, newBinary, hex.EncodeToString(digest[:]))
+
+	exe := filepath.Join(t.TempDir(), "sysmetrics-mcp")
+	if err := os.WriteFile(exe, []byte("old binary"), 0o600); err != nil {
+		t.Fatalf("Failed to write binary: %v", err)
+	}
+
+	res, err := Apply(context.Background(), release, asset, ApplyOptions{
+		ExePath: exe,
+		Verify:  func(ctx context.Context, path string) error { return nil },
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if got, _ := os.ReadFile(exe); string(got) != "new binary" {
+		t.Errorf("Expected new binary installed, got %q", got)
+	}
+	if got, _ := os.ReadFile(res.BackupPath); string(got) != "old binary" {
+		t.Errorf("Expected old binary backed up, got %q", got)
+	}
+
+	if err := Rollback(exe); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	if got, _ := os.ReadFile(exe); string(got) != "old binary" {
+		t.Errorf("Expected old binary restored, got %q", got)
+	}
+	if err := Rollback(exe); err == nil {
+		t.Error("Expected error when no backup remains")
+	}
+}
+
+func TestApplyRejectsBadBinary(t *testing.T) {
+	newBinary := []byte("new binary")
+	digest := sha256.Sum256(newBinary)
+
+	tests := []struct {
+		name   string
+		sum    string
+		verify func(ctx context.Context, path string) error
+	}{
+		{"checksum_mismatch", "0000", nil},
+		{"verification_failure", hex.EncodeToString(digest[:]), func(ctx context.Context, path string) error { return errors.New("exec format error") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release, asset := startReleaseServer(t, newBinary, tt.sum)
+			dir := t.TempDir()
+			exe := filepath.Join(dir, "sysmetrics-mcp")
+			if err := os.WriteFile(exe, []byte("old binary"), 0o600); err != nil {
+				t.Fatalf("Failed to write binary: %v", err)
+			}
+
+			if _, err := Apply(context.Background(), release, asset, ApplyOptions{ExePath: exe, Verify: tt.verify}); err == nil {
+				t.Fatal("Expected Apply to fail")
+			}
+			if got, _ := os.ReadFile(exe); string(got) != "old binary" {
+				t.Errorf("Expected current binary untouched, got %q", got)
+			}
+			if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+				t.Errorf("Expected temporary files cleaned up, found %d entries", len(entries))
+			}
+		})
+	}
+}
+
+func TestApplyRequiresChecksums(t *testing.T) {
+	release := Release{TagName: "v1.1.0"}
+	if _, err := Apply(context.Background(), release, Asset{Name: "x"}, ApplyOptions{ExePath: filepath.Join(t.TempDir(), "x")}); err == nil {
+		t.Error("Expected error for release without checksums")
+	}
+}
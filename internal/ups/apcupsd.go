@@ -0,0 +1,111 @@
+package ups
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// nisStatusCommand asks apcupsd for the same report `apcaccess status` prints
+const nisStatusCommand = "status"
+
+// maxNISRecord bounds a single apcupsd NIS record to guard against a misbehaving peer
+const maxNISRecord = 4096
+
+// QueryApcupsd reads the status of the UPS managed by the apcupsd daemon at addr
+func QueryApcupsd(ctx context.Context, addr string) (Status, error) {
+	conn, err := dial(ctx, addr)
+	if err != nil {
+		return Status{}, fmt.Errorf("failed to connect to apcupsd at %s: %w", addr, err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	// NIS frames every message with a 2-byte big-endian length
+	frame := make([]byte, 2+len(nisStatusCommand))
+	binary.BigEndian.PutUint16(frame, uint16(len(nisStatusCommand)))
+	copy(frame[2:], nisStatusCommand)
+	if _, err := conn.Write(frame); err != nil {
+		return Status{}, fmt.Errorf("failed to send status request to apcupsd: %w", err)
+	}
+
+	records, err := readNISRecords(bufio.NewReader(conn))
+	if err != nil {
+		return Status{}, fmt.Errorf("failed to read apcupsd status: %w", err)
+	}
+	return normalizeApcupsd(parseApcupsdStatus(records)), nil
+}
+
+// readNISRecords reads length-prefixed records until the zero-length terminator
+func readNISRecords(r io.Reader) ([]string, error) {
+	var records []string
+	var lenBuf [2]byte
+	for {
+		if _, err := io.ReadFull(r, lenBuf[:]); err != nil {
+			return nil, err
+		}
+		n := binary.BigEndian.Uint16(lenBuf[:])
+		if n == 0 {
+			return records, nil
+		}
+		if n > maxNISRecord {
+			return nil, fmt.Errorf("NIS record too large (%d bytes)", n)
+		}
+		buf := make([]byte, n)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		records = append(records, string(buf))
+	}
+}
+
+// parseApcupsdStatus parses `KEY      : value` status lines into a map
+func parseApcupsdStatus(lines []string) map[string]string {
+	vars := make(map[string]string)
+	for _, line := range lines {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		vars[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return vars
+}
+
+// normalizeApcupsd maps apcupsd status keys onto a Status
+func normalizeApcupsd(vars map[string]string) Status {
+	st := Status{
+		Name:           vars["UPSNAME"],
+		Backend:        BackendApcupsd,
+		Model:          vars["MODEL"],
+		Status:         vars["STATUS"],
+		ChargePercent:  numberPtr(vars["BCHARGE"]),
+		LoadPercent:    numberPtr(vars["LOADPCT"]),
+		InputVoltage:   numberPtr(vars["LINEV"]),
+		OutputVoltage:  numberPtr(vars["OUTPUTV"]),
+		BatteryVoltage: numberPtr(vars["BATTV"]),
+		Raw:            vars,
+	}
+	if st.Name == "" {
+		st.Name = vars["HOSTNAME"]
+	}
+
+	// TIMELEFT is reported in minutes
+	if minutes, ok := parseNumber(vars["TIMELEFT"]); ok {
+		seconds := minutes * 60
+		st.RuntimeSeconds = &seconds
+	}
+
+	// STATUS is a space-separated flag list such as "ONLINE" or "ONBATT LOWBATT"
+	for _, flag := range strings.Fields(st.Status) {
+		switch flag {
+		case "ONBATT":
+			st.OnBattery = true
+		case "LOWBATT":
+			st.LowBattery = true
+		}
+	}
+	return st
+}
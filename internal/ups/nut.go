@@ -0,0 +1,152 @@
+package ups
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// QueryNUT lists every UPS known to the upsd daemon at addr and reads its variables.
+// A non-empty name restricts the query to that UPS.
+func QueryNUT(ctx context.Context, addr, name string) ([]Status, error) {
+	conn, err := dial(ctx, addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to upsd at %s: %w", addr, err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+
+	names := []string{name}
+	if name == "" {
+		lines, err := nutList(rw, "UPS")
+		if err != nil {
+			return nil, err
+		}
+		names = names[:0]
+		for _, line := range lines {
+			// UPS <upsname> "<description>"
+			if fields := strings.Fields(line); len(fields) >= 2 && fields[0] == "UPS" {
+				names = append(names, fields[1])
+			}
+		}
+	}
+
+	var statuses []Status
+	for _, n := range names {
+		lines, err := nutList(rw, "VAR "+n)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, normalizeNUT(n, parseNUTVars(lines)))
+	}
+
+	_, _ = rw.WriteString("LOGOUT\n")
+	_ = rw.Flush()
+	return statuses, nil
+}
+
+// nutList sends a LIST command and returns the lines between BEGIN LIST and END LIST
+func nutList(rw *bufio.ReadWriter, query string) ([]string, error) {
+	if _, err := rw.WriteString("LIST " + query + "\n"); err != nil {
+		return nil, err
+	}
+	if err := rw.Flush(); err != nil {
+		return nil, err
+	}
+	return readNUTList(rw.Reader, query)
+}
+
+// readNUTList reads a LIST response, translating upsd ERR replies into errors
+func readNUTList(r *bufio.Reader, query string) ([]string, error) {
+	var lines []string
+	begun := false
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			if err == io.EOF {
+				return nil, fmt.Errorf("upsd closed the connection during LIST %s", query)
+			}
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case strings.HasPrefix(line, "ERR "):
+			return nil, fmt.Errorf("upsd LIST %s: %s", query, strings.TrimPrefix(line, "ERR "))
+		case line == "BEGIN LIST "+query:
+			begun = true
+		case line == "END LIST "+query:
+			return lines, nil
+		case begun:
+			lines = append(lines, line)
+		}
+	}
+}
+
+// parseNUTVars parses `VAR <ups> <name> "<value>"` lines into a map
+func parseNUTVars(lines []string) map[string]string {
+	vars := make(map[string]string)
+	for _, line := range lines {
+		fields := strings.SplitN(line, " ", 4)
+		if len(fields) != 4 || fields[0] != "VAR" {
+			continue
+		}
+		vars[fields[2]] = unquoteNUT(fields[3])
+	}
+	return vars
+}
+
+// unquoteNUT strips the surrounding quotes and backslash escapes from a upsd value
+func unquoteNUT(s string) string {
+	s = strings.TrimPrefix(strings.TrimSuffix(s, `"`), `"`)
+	var b strings.Builder
+	escaped := false
+	for _, r := range s {
+		if r == '\\' && !escaped {
+			escaped = true
+			continue
+		}
+		escaped = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// normalizeNUT maps standard NUT variable names onto a Status
+func normalizeNUT(name string, vars map[string]string) Status {
+	st := Status{
+		Name:           name,
+		Backend:        BackendNUT,
+		Status:         vars["ups.status"],
+		ChargePercent:  numberPtr(vars["battery.charge"]),
+		RuntimeSeconds: numberPtr(vars["battery.runtime"]),
+		LoadPercent:    numberPtr(vars["ups.load"]),
+		InputVoltage:   numberPtr(vars["input.voltage"]),
+		OutputVoltage:  numberPtr(vars["output.voltage"]),
+		BatteryVoltage: numberPtr(vars["battery.voltage"]),
+		Raw:            vars,
+	}
+
+	model := vars["device.model"]
+	if model == "" {
+		model = vars["ups.model"]
+	}
+	if mfr := vars["device.mfr"]; mfr != "" && !strings.HasPrefix(model, mfr) {
+		model = strings.TrimSpace(mfr + " " + model)
+	}
+	st.Model = model
+
+	// ups.status is a space-separated flag list such as "OL CHRG" or "OB LB"
+	for _, flag := range strings.Fields(st.Status) {
+		switch flag {
+		case "OB":
+			st.OnBattery = true
+		case "LB":
+			st.LowBattery = true
+		}
+	}
+	return st
+}
@@ -0,0 +1,73 @@
+// Package ups queries UPS state from Network UPS Tools (upsd) and apcupsd (NIS) daemons.
+package ups
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Backend name constants.
+const (
+	BackendNUT     = "nut"
+	BackendApcupsd = "apcupsd"
+)
+
+// queryTimeout bounds a single conversation with a UPS daemon
+const queryTimeout = 5 * time.Second
+
+// Status is the normalized state of a single UPS. Pointer fields are nil when the
+// daemon does not report the value.
+type Status struct {
+	Name           string            `json:"name"`
+	Backend        string            `json:"backend"`
+	Model          string            `json:"model,omitempty"`
+	Status         string            `json:"status,omitempty"`
+	OnBattery      bool              `json:"on_battery"`
+	LowBattery     bool              `json:"low_battery"`
+	ChargePercent  *float64          `json:"charge_percent,omitempty"`
+	RuntimeSeconds *float64          `json:"runtime_seconds,omitempty"`
+	LoadPercent    *float64          `json:"load_percent,omitempty"`
+	InputVoltage   *float64          `json:"input_voltage,omitempty"`
+	OutputVoltage  *float64          `json:"output_voltage,omitempty"`
+	BatteryVoltage *float64          `json:"battery_voltage,omitempty"`
+	Raw            map[string]string `json:"raw,omitempty"`
+}
+
+// dial opens a TCP connection to a UPS daemon with a deadline covering the whole exchange
+func dial(ctx context.Context, addr string) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	deadline, _ := ctx.Deadline()
+	_ = conn.SetDeadline(deadline)
+	return conn, nil
+}
+
+// parseNumber extracts the leading number from values such as "230.0" or "45.0 Minutes"
+func parseNumber(value string) (float64, bool) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// numberPtr returns a pointer to the parsed value, or nil if it is missing or malformed
+func numberPtr(value string) *float64 {
+	if v, ok := parseNumber(value); ok {
+		return &v
+	}
+	return nil
+}
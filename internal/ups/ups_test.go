@@ -0,0 +1,171 @@
+package ups
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+// serveOnce accepts a single connection on a loopback listener and hands it to handle
+func serveOnce(t *testing.T, handle func(conn net.Conn)) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		handle(conn)
+	}()
+	return ln.Addr().String()
+}
+
+func TestQueryNUT(t *testing.T) {
+	addr := serveOnce(t, func(conn net.Conn) {
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch strings.TrimSpace(line) {
+			case "LIST UPS":
+				_, _ = io.WriteString(conn, "BEGIN LIST UPS\nUPS rack \"Rack UPS\"\nEND LIST UPS\n")
+			case "LIST VAR rack":
+				_, _ = io.WriteString(conn, "BEGIN LIST VAR rack\n"+
+					"VAR rack battery.charge \"87\"\n"+
+					"VAR rack battery.runtime \"1260\"\n"+
+					"VAR rack ups.load \"23\"\n"+
+					"VAR rack input.voltage \"121.0\"\n"+
+					"VAR rack ups.status \"OB DISCHRG\"\n"+
+					"VAR rack device.mfr \"CPS\"\n"+
+					"VAR rack device.model \"CP1500 \\\"AVR\\\"\"\n"+
+					"END LIST VAR rack\n")
+			case "LOGOUT":
+				return
+			default:
+				_, _ = io.WriteString(conn, "ERR UNKNOWN-COMMAND\n")
+			}
+		}
+	})
+
+	statuses, err := QueryNUT(context.Background(), addr, "")
+	if err != nil {
+		t.Fatalf("QueryNUT failed: %v", err)
+	}
+	if len(statuses) != 1 {
+		t.Fatalf("Expected 1 UPS, got %d", len(statuses))
+	}
+	st := statuses[0]
+	if st.Name != "rack" || st.Backend != BackendNUT || !st.OnBattery || st.LowBattery {
+		t.Errorf("Unexpected status: %+v", st)
+	}
+	if st.ChargePercent == nil || *st.ChargePercent != 87 || st.RuntimeSeconds == nil || *st.RuntimeSeconds != 1260 {
+		t.Errorf("Unexpected battery values: %+v", st)
+	}
+	if st.Model != `CPS CP1500 "AVR"` {
+		t.Errorf("Unexpected model %q", st.Model)
+	}
+	if st.OutputVoltage != nil {
+		t.Errorf("Expected missing output voltage to be nil, got %v", *st.OutputVoltage)
+	}
+}
+
+func TestQueryNUTError(t *testing.T) {
+	addr := serveOnce(t, func(conn net.Conn) {
+		_, _ = bufio.NewReader(conn).ReadString('\n')
+		_, _ = io.WriteString(conn, "ERR UNKNOWN-UPS\n")
+	})
+
+	if _, err := QueryNUT(context.Background(), addr, "missing"); err == nil || !strings.Contains(err.Error(), "UNKNOWN-UPS") {
+		t.Errorf("Expected UNKNOWN-UPS error, got %v", err)
+	}
+}
+
+func TestQueryApcupsd(t *testing.T) {
+	addr := serveOnce(t, func(conn net.Conn) {
+		var lenBuf [2]byte
+		if _, err := io.ReadFull(conn, lenBuf[:]); err != nil {
+			return
+		}
+		cmd := make([]byte, binary.BigEndian.Uint16(lenBuf[:]))
+		if _, err := io.ReadFull(conn, cmd); err != nil || string(cmd) != "status" {
+			return
+		}
+		for _, rec := range []string{
+			"UPSNAME  : office\n",
+			"MODEL    : Back-UPS ES 700G \n",
+			"STATUS   : ONLINE \n",
+			"LINEV    : 230.0 Volts\n",
+			"LOADPCT  : 12.0 Percent\n",
+			"BCHARGE  : 100.0 Percent\n",
+			"TIMELEFT : 45.5 Minutes\n",
+			"",
+		} {
+			binary.BigEndian.PutUint16(lenBuf[:], uint16(len(rec))) //nolint:gosec // G115: test records are short
+			_, _ = conn.Write(append(lenBuf[:], rec...))
+		}
+	})
+
+	st, err := QueryApcupsd(context.Background(), addr)
+	if err != nil {
+		t.Fatalf("QueryApcupsd failed: %v", err)
+	}
+	if st.Name != "office" || st.Model != "Back-UPS ES 700G" || st.Status != "ONLINE" || st.OnBattery {
+		t.Errorf("Unexpected status: %+v", st)
+	}
+	if st.RuntimeSeconds == nil || *st.RuntimeSeconds != 2730 {
+		t.Errorf("Expected 2730s runtime, got %v", st.RuntimeSeconds)
+	}
+	if st.InputVoltage == nil || *st.InputVoltage != 230 {
+		t.Errorf("Expected 230V line voltage, got %v", st.InputVoltage)
+	}
+}
+
+func TestQueryUnreachable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	if _, err := QueryNUT(context.Background(), addr, ""); err == nil {
+		t.Error("Expected error for closed upsd port")
+	}
+	if _, err := QueryApcupsd(context.Background(), addr); err == nil {
+		t.Error("Expected error for closed apcupsd port")
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+		ok       bool
+	}{
+		{"230.0 Volts", 230, true},
+		{"87", 87, true},
+		{"", 0, false},
+		{"N/A", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := parseNumber(tt.input)
+			if ok != tt.ok || got != tt.expected {
+				t.Errorf("parseNumber(%q) = %v, %v; expected %v, %v", tt.input, got, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
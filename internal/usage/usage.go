@@ -0,0 +1,253 @@
+// Package usage keeps per-tool call counts, error rates, and latency histograms in a JSON
+// file so they survive restarts.
+package usage
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// maxErrorLength bounds the last error message kept per tool
+const maxErrorLength = 200
+
+// BucketBoundsMS are the upper bounds of the latency histogram in milliseconds; a final
+// bucket holds slower calls
+var BucketBoundsMS = []float64{1, 2, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000, 60000}
+
+// Outcome is how a tool call ended
+type Outcome int
+
+// Tool call outcomes
+const (
+	// OK is a successful result
+	OK Outcome = iota
+	// Error is a tool error result, such as a missing command or bad arguments
+	Error
+	// Failure is a handler that returned a Go error instead of a result
+	Failure
+)
+
+// ToolStats are the cumulative statistics of one tool
+type ToolStats struct {
+	Calls       uint64     `json:"calls"`
+	Errors      uint64     `json:"errors"`
+	Failures    uint64     `json:"failures"`
+	TotalMS     float64    `json:"total_ms"`
+	MaxMS       float64    `json:"max_ms"`
+	Buckets     []uint64   `json:"buckets"`
+	FirstCall   time.Time  `json:"first_call"`
+	LastCall    time.Time  `json:"last_call"`
+	LastError   string     `json:"last_error,omitempty"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+}
+
+// ErrorRate returns the share of calls that ended in an error or failure, in percent
+func (s ToolStats) ErrorRate() float64 {
+	if s.Calls == 0 {
+		return 0
+	}
+	return float64(s.Errors+s.Failures) / float64(s.Calls) * 100
+}
+
+// AvgMS returns the mean latency
+func (s ToolStats) AvgMS() float64 {
+	if s.Calls == 0 {
+		return 0
+	}
+	return s.TotalMS / float64(s.Calls)
+}
+
+// Percentile estimates the latency below which p (0-1) of calls completed, interpolating
+// within the histogram bucket; it never exceeds the slowest call seen
+func (s ToolStats) Percentile(p float64) float64 {
+	var total uint64
+	for _, n := range s.Buckets {
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+	rank := p * float64(total)
+	var cumulative uint64
+	for i, n := range s.Buckets {
+		if n == 0 || float64(cumulative+n) < rank {
+			cumulative += n
+			continue
+		}
+		if i >= len(BucketBoundsMS) {
+			return s.MaxMS
+		}
+		lower := 0.0
+		if i > 0 {
+			lower = BucketBoundsMS[i-1]
+		}
+		v := lower + (BucketBoundsMS[i]-lower)*(rank-float64(cumulative))/float64(n)
+		return min(v, s.MaxMS)
+	}
+	return s.MaxMS
+}
+
+// stored is the file layout. The bucket bounds are kept so a histogram recorded with
+// different bounds is discarded rather than misread.
+type stored struct {
+	Since        time.Time             `json:"since"`
+	BucketBounds []float64             `json:"bucket_bounds_ms"`
+	Tools        map[string]*ToolStats `json:"tools"`
+}
+
+// Tracker aggregates tool calls in memory and writes them to a JSON file on Flush. The
+// file is read on first use; an unreadable or corrupt file starts the statistics afresh.
+type Tracker struct {
+	path   string
+	mu     sync.Mutex
+	loaded bool
+	dirty  bool
+	since  time.Time
+	tools  map[string]*ToolStats
+}
+
+// NewTracker returns a Tracker backed by path; the file is created on the first Flush
+func NewTracker(path string) *Tracker {
+	return &Tracker{path: path}
+}
+
+// DefaultPath returns the statistics file under the user config directory, or "" if it
+// cannot be determined
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "sysmetrics-mcp", "usage.json")
+}
+
+// Path returns the backing file
+func (t *Tracker) Path() string {
+	return t.path
+}
+
+// Record adds one call of tool that took d and ended with outcome; errMsg is kept as the
+// tool's last error when the call did not succeed
+func (t *Tracker) Record(tool string, d time.Duration, outcome Outcome, errMsg string, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.load(now)
+
+	s, ok := t.tools[tool]
+	if !ok {
+		s = &ToolStats{Buckets: make([]uint64, len(BucketBoundsMS)+1), FirstCall: now}
+		t.tools[tool] = s
+	}
+	ms := float64(d) / float64(time.Millisecond)
+	s.Calls++
+	s.TotalMS += ms
+	s.MaxMS = max(s.MaxMS, ms)
+	s.Buckets[bucketIndex(ms)]++
+	s.LastCall = now
+	switch outcome {
+	case Error:
+		s.Errors++
+	case Failure:
+		s.Failures++
+	}
+	if outcome != OK {
+		if len(errMsg) > maxErrorLength {
+			errMsg = errMsg[:maxErrorLength] + "..."
+		}
+		at := now
+		s.LastError, s.LastErrorAt = errMsg, &at
+	}
+	t.dirty = true
+}
+
+// Snapshot returns a copy of the statistics and when tracking started
+func (t *Tracker) Snapshot(now time.Time) (time.Time, map[string]ToolStats) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.load(now)
+	out := make(map[string]ToolStats, len(t.tools))
+	for name, s := range t.tools {
+		c := *s
+		c.Buckets = slices.Clone(s.Buckets)
+		out[name] = c
+	}
+	return t.since, out
+}
+
+// Flush writes the statistics atomically if anything was recorded since the last flush
+func (t *Tracker) Flush() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.dirty {
+		return nil
+	}
+	if t.path == "" {
+		return errors.New("no usage statistics location is available")
+	}
+	data, err := json.MarshalIndent(stored{Since: t.since, BucketBounds: BucketBoundsMS, Tools: t.tools}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(t.path), 0o700); err != nil {
+		return err
+	}
+	tmp := t.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, t.path); err != nil {
+		return err
+	}
+	t.dirty = false
+	return nil
+}
+
+// load reads the file once. Histograms recorded with other bucket bounds are dropped
+// while their counts are kept.
+func (t *Tracker) load(now time.Time) {
+	if t.loaded {
+		return
+	}
+	t.loaded = true
+	t.since, t.tools = now, map[string]*ToolStats{}
+	if t.path == "" {
+		return
+	}
+	data, err := os.ReadFile(filepath.Clean(t.path))
+	if err != nil {
+		return
+	}
+	var s stored
+	if err := json.Unmarshal(data, &s); err != nil || s.Tools == nil {
+		return
+	}
+	sameBounds := slices.Equal(s.BucketBounds, BucketBoundsMS)
+	for name, stats := range s.Tools {
+		if stats == nil {
+			delete(s.Tools, name)
+			continue
+		}
+		if !sameBounds || len(stats.Buckets) != len(BucketBoundsMS)+1 {
+			stats.Buckets = make([]uint64, len(BucketBoundsMS)+1)
+		}
+	}
+	t.tools = s.Tools
+	if !s.Since.IsZero() {
+		t.since = s.Since
+	}
+}
+
+// bucketIndex returns the histogram bucket for a latency
+func bucketIndex(ms float64) int {
+	for i, bound := range BucketBoundsMS {
+		if ms <= bound {
+			return i
+		}
+	}
+	return len(BucketBoundsMS)
+}
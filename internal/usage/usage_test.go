@@ -0,0 +1,75 @@
+package usage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTrackerRecordAndFlush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "usage.json")
+	now := time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)
+	tr := NewTracker(path)
+	for i := 0; i < 8; i++ {
+		tr.Record("get_cpu_metrics", 20*time.Millisecond, OK, "", now)
+	}
+	tr.Record("get_cpu_metrics", 2*time.Second, Error, "timed out", now.Add(time.Minute))
+	tr.Record("get_cpu_metrics", 40*time.Millisecond, Failure, "boom", now.Add(2*time.Minute))
+
+	if err := tr.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("Expected the statistics file to be written: %v", err)
+	}
+
+	// A new tracker picks up where the last one stopped
+	since, stats := NewTracker(path).Snapshot(now.Add(time.Hour))
+	s, ok := stats["get_cpu_metrics"]
+	if !ok || !since.Equal(now) {
+		t.Fatalf("Expected stored statistics since %s, got %v %+v", now, since, stats)
+	}
+	if s.Calls != 10 || s.Errors != 1 || s.Failures != 1 || s.ErrorRate() != 20 || s.MaxMS != 2000 || s.LastError != "boom" {
+		t.Errorf("Unexpected statistics: %+v", s)
+	}
+	if p50 := s.Percentile(0.5); p50 <= 10 || p50 > 25 {
+		t.Errorf("Expected p50 in the 10-25ms bucket, got %v", p50)
+	}
+	if p99 := s.Percentile(0.99); p99 <= 1000 || p99 > 2000 {
+		t.Errorf("Expected p99 near the slow call, got %v", p99)
+	}
+}
+
+func TestTrackerLoadMismatchedBuckets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+	data := `{"since":"2024-01-01T00:00:00Z","bucket_bounds_ms":[1,10],"tools":{"get_disk_metrics":{"calls":3,"buckets":[1,1,1]},"bad":null}}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, stats := NewTracker(path).Snapshot(time.Now())
+	s := stats["get_disk_metrics"]
+	if s.Calls != 3 || len(s.Buckets) != len(BucketBoundsMS)+1 || s.Percentile(0.5) != 0 {
+		t.Errorf("Expected counts kept and the histogram reset, got %+v", s)
+	}
+	if _, ok := stats["bad"]; ok {
+		t.Error("Expected a null entry to be dropped")
+	}
+
+	if err := os.WriteFile(path, []byte("not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, stats := NewTracker(path).Snapshot(time.Now()); len(stats) != 0 {
+		t.Errorf("Expected a corrupt file to start afresh, got %+v", stats)
+	}
+}
+
+func TestFlushWithoutCalls(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+	if err := NewTracker(path).Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Expected no file without recorded calls")
+	}
+}